@@ -0,0 +1,224 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT-0
+
+package v1alpha1
+
+import (
+	"time"
+
+	"github.com/fluxcd/pkg/apis/meta"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	CloudFormationStackGeneratorKind = "CloudFormationStackGenerator"
+
+	// GeneratorNameLabel is the label set on the CloudFormationStack objects generated by a CloudFormationStackGenerator,
+	// with the name of the generator as value.
+	GeneratorNameLabel = "cloudformation.contrib.fluxcd.io/generator"
+)
+
+// CloudFormationStackGeneratorSpec defines the desired state of a CloudFormation stack generator
+type CloudFormationStackGeneratorSpec struct {
+	// Template is the CloudFormationStack object that is generated for each element.
+	// Variable references like ${tenant} in the template's metadata and spec are replaced
+	// with the values of the element, for example to give each generated stack its own stack name.
+	// References to variables that are not defined by the element are left untouched,
+	// and references can be escaped with $${var}.
+	// +required
+	Template CloudFormationStackTemplate `json:"template"`
+
+	// Elements is an inline list of elements, each element is a map of variable names to values.
+	// +optional
+	Elements []map[string]string `json:"elements,omitempty"`
+
+	// ElementsFrom is a list of references to ConfigMaps or source artifact files
+	// that contain a JSON or YAML list of elements. The elements are appended to the inline elements,
+	// in the order of the list.
+	// +optional
+	ElementsFrom []GeneratorElementsReference `json:"elementsFrom,omitempty"`
+
+	// The interval at which to reconcile the generated CloudFormationStack objects.
+	// +required
+	Interval metav1.Duration `json:"interval"`
+
+	// The interval at which to retry a previously failed reconciliation.
+	// When not specified, the controller uses the CloudFormationStackGeneratorSpec.Interval
+	// value to retry failures.
+	// +optional
+	RetryInterval *metav1.Duration `json:"retryInterval,omitempty"`
+
+	// Prune deletes the generated CloudFormationStack objects that no longer match an element.
+	// Defaults to true.
+	// +kubebuilder:default:=true
+	// +optional
+	Prune bool `json:"prune"`
+
+	// This flag tells the controller to suspend subsequent generation of CloudFormationStack objects,
+	// it does not apply to already started reconciliations. Defaults to false.
+	// +optional
+	Suspend bool `json:"suspend,omitempty"`
+}
+
+// CloudFormationStackTemplate is the template of the CloudFormationStack objects generated by a CloudFormationStackGenerator
+type CloudFormationStackTemplate struct {
+	// Metadata of the generated CloudFormationStack objects.
+	// +optional
+	Metadata CloudFormationStackTemplateMetadata `json:"metadata,omitempty"`
+
+	// Spec of the generated CloudFormationStack objects.
+	// +required
+	Spec CloudFormationStackSpec `json:"spec"`
+}
+
+// CloudFormationStackTemplateMetadata is the metadata of the CloudFormationStack objects generated by a CloudFormationStackGenerator
+type CloudFormationStackTemplateMetadata struct {
+	// Name of the generated CloudFormationStack objects.
+	// The name must reference element variables so that it is unique for each element.
+	// Defaults to the name of the generator followed by the lowercase stack name.
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// Labels to set on the generated CloudFormationStack objects.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Annotations to set on the generated CloudFormationStack objects.
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// GeneratorElementsReference is a reference to a ConfigMap or to a file in a source artifact
+// that contains a JSON or YAML list of elements.
+type GeneratorElementsReference struct {
+	// Kind of the referent.
+	// +kubebuilder:validation:Enum=ConfigMap;GitRepository;Bucket;OCIRepository
+	// +required
+	Kind string `json:"kind"`
+
+	// Name of the referent.
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=253
+	// +required
+	Name string `json:"name"`
+
+	// Namespace of the source object, defaults to the namespace of the generator.
+	// ConfigMaps are always read from the namespace of the generator.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Key of the ConfigMap data entry that contains the elements, for ConfigMap references.
+	// +optional
+	Key string `json:"key,omitempty"`
+
+	// Path of the file that contains the elements in the source artifact, for source references.
+	// +optional
+	Path string `json:"path,omitempty"`
+
+	// Optional indicates whether the referenced ConfigMap, data entry or file must exist,
+	// or whether to ignore it if it does not exist.
+	// +optional
+	Optional bool `json:"optional,omitempty"`
+}
+
+// CloudFormationStackGeneratorStatus defines the observed state of a CloudFormation stack generator
+type CloudFormationStackGeneratorStatus struct {
+	// ObservedGeneration is the last observed generation.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	meta.ReconcileRequestStatus `json:",inline"`
+
+	// Conditions holds the conditions for the CloudFormationStackGenerator.
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// GeneratedStacks is the list of names of the CloudFormationStack objects generated by the controller.
+	// +optional
+	GeneratedStacks []string `json:"generatedStacks,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:shortName=cfnstackgen
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status",description=""
+// +kubebuilder:printcolumn:name="Status",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].message",description=""
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp",description=""
+
+// CloudFormationStackGenerator is the Schema for the CloudFormation stack generator API
+type CloudFormationStackGenerator struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   CloudFormationStackGeneratorSpec   `json:"spec,omitempty"`
+	Status CloudFormationStackGeneratorStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// CloudFormationStackGeneratorList contains a list of CloudFormation stack generators
+type CloudFormationStackGeneratorList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []CloudFormationStackGenerator `json:"items"`
+}
+
+// The potential reasons that are associated with the condition types of stack generators
+const (
+	ElementsFailedReason = "ElementsFailed"
+	GenerateFailedReason = "GenerateFailed"
+)
+
+// SetCloudFormationStackGeneratorReadiness sets the ReadyCondition and ObservedGeneration on the CloudFormation stack generator.
+func SetCloudFormationStackGeneratorReadiness(generator *CloudFormationStackGenerator, status metav1.ConditionStatus, reason, message string) {
+	newCondition := metav1.Condition{
+		Type:               meta.ReadyCondition,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: generator.Generation,
+	}
+
+	apimeta.SetStatusCondition(&generator.Status.Conditions, newCondition)
+	generator.Status.ObservedGeneration = generator.Generation
+}
+
+// CloudFormationStackGeneratorProgressing resets the conditions of the given CloudFormation
+// stack generator to a single ReadyCondition with status ConditionUnknown.
+func CloudFormationStackGeneratorProgressing(generator CloudFormationStackGenerator, message string) CloudFormationStackGenerator {
+	SetCloudFormationStackGeneratorReadiness(&generator, metav1.ConditionUnknown, meta.ProgressingReason, message)
+	return generator
+}
+
+// CloudFormationStackGeneratorNotReady registers a failed reconciliation attempt of the given CloudFormation stack generator.
+func CloudFormationStackGeneratorNotReady(generator CloudFormationStackGenerator, reason, message string) CloudFormationStackGenerator {
+	SetCloudFormationStackGeneratorReadiness(&generator, metav1.ConditionFalse, reason, message)
+	return generator
+}
+
+// CloudFormationStackGeneratorReady registers a successful reconciliation of the given CloudFormation stack generator.
+func CloudFormationStackGeneratorReady(generator CloudFormationStackGenerator, message string) CloudFormationStackGenerator {
+	SetCloudFormationStackGeneratorReadiness(&generator, metav1.ConditionTrue, meta.SucceededReason, message)
+	return generator
+}
+
+// GetRetryInterval returns the retry interval
+func (in CloudFormationStackGenerator) GetRetryInterval() time.Duration {
+	if in.Spec.RetryInterval != nil {
+		return in.Spec.RetryInterval.Duration
+	}
+	return in.Spec.Interval.Duration
+}
+
+func (in CloudFormationStackGenerator) GetConditions() []metav1.Condition {
+	return in.Status.Conditions
+}
+
+func (in *CloudFormationStackGenerator) SetConditions(conditions []metav1.Condition) {
+	in.Status.Conditions = conditions
+}
+
+func init() {
+	SchemeBuilder.Register(&CloudFormationStackGenerator{}, &CloudFormationStackGeneratorList{})
+}
@@ -0,0 +1,351 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT-0
+
+package v1alpha1
+
+import (
+	"time"
+
+	"github.com/fluxcd/pkg/apis/meta"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	CloudFormationStackSetKind = "CloudFormationStackSet"
+)
+
+// CloudFormationStackSetSpec defines the desired state of a CloudFormation stack set
+type CloudFormationStackSetSpec struct {
+	// Name of the CloudFormation stack set.
+	// Note that if this value is changed after creation, the controller will NOT
+	// destroy the old stack set and the old stack set will no longer be updated by the controller.
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=128
+	// +required
+	StackSetName string `json:"stackSetName,omitempty"`
+
+	// Path to the CloudFormation template file.
+	// Defaults to the root path of the SourceRef and filename 'template.yaml'.
+	// Nested stack templates (AWS::CloudFormation::Stack TemplateURL) and AWS::Include snippets (Location)
+	// that are referenced by a relative path are loaded from the same source, relative to the referencing template.
+	// +optional
+	// +kubebuilder:default="template.yaml"
+	TemplatePath string `json:"templatePath,omitempty"`
+
+	// SourceRef is the reference of the source where the CloudFormation template is stored.
+	// +required
+	SourceRef SourceReference `json:"sourceRef"`
+
+	// The interval at which to reconcile the CloudFormation stack set.
+	// +required
+	Interval metav1.Duration `json:"interval"`
+
+	// The interval at which to poll CloudFormation for the status of the stack set operation
+	// that is in progress, like an update of the stack set or the creation of stack instances.
+	// Defaults to thirty seconds.
+	// +optional
+	// +kubebuilder:default="30s"
+	PollInterval metav1.Duration `json:"pollInterval,omitempty"`
+
+	// The interval at which to retry a previously failed reconciliation.
+	// When not specified, the controller uses the CloudFormationStackSetSpec.Interval
+	// value to retry failures.
+	// +optional
+	RetryInterval *metav1.Duration `json:"retryInterval,omitempty"`
+
+	// The parameter keys and values to set on the stack set
+	// +optional
+	StackParameters []StackParameter `json:"stackParameters,omitempty"`
+
+	// The tag keys and values to set on the stack set, which are propagated to the stack instances.
+	// Default tags will be added:
+	// cfn-flux-controller/version, cfn-flux-controller/name, cfn-flux-controller/namespace.
+	// +optional
+	StackTags []StackTag `json:"stackTags,omitempty"`
+
+	// PermissionModel describes how the IAM roles that are required to deploy the stack instances are created.
+	// With 'SELF_MANAGED', the roles must already exist in the administrator and target accounts.
+	// With 'SERVICE_MANAGED', the stack instances are deployed to the accounts of AWS Organizations
+	// organizational units, with roles that are created by CloudFormation.
+	// Defaults to 'SELF_MANAGED'.
+	// +kubebuilder:validation:Enum=SELF_MANAGED;SERVICE_MANAGED
+	// +kubebuilder:default="SELF_MANAGED"
+	// +optional
+	PermissionModel string `json:"permissionModel,omitempty"`
+
+	// ARN of the IAM role used to create or update the stack set, for the 'SELF_MANAGED' permission model.
+	// Defaults to the AWSCloudFormationStackSetAdministrationRole role in the controller's account.
+	// +optional
+	AdministrationRoleARN string `json:"administrationRoleARN,omitempty"`
+
+	// Name of the IAM role in the target accounts that CloudFormation assumes to deploy the stack instances,
+	// for the 'SELF_MANAGED' permission model. Defaults to AWSCloudFormationStackSetExecutionRole.
+	// +optional
+	ExecutionRoleName string `json:"executionRoleName,omitempty"`
+
+	// AutoDeployment deploys stack instances to accounts that are added to the target organizational units,
+	// and deletes the stack instances of accounts that are removed, for the 'SERVICE_MANAGED' permission model.
+	// +optional
+	AutoDeployment *StackSetAutoDeployment `json:"autoDeployment,omitempty"`
+
+	// StackInstances are the accounts or organizational units, and the regions, to deploy stack instances to.
+	// A stack instance is deployed in each of the regions for each of the targets.
+	// Stack instances that are not in this list are deleted.
+	// +required
+	StackInstances StackSetInstances `json:"stackInstances"`
+
+	// OperationPreferences control how CloudFormation deploys the stack instances for stack set operations.
+	// +optional
+	OperationPreferences *StackSetOperationPreferences `json:"operationPreferences,omitempty"`
+
+	// Suspend tells the controller to suspend reconciliation for this CloudFormation stack set,
+	// it does not apply to already started reconciliations. Defaults to false.
+	// +optional
+	// +kubebuilder:default:=false
+	Suspend bool `json:"suspend,omitempty"`
+
+	// Delete the CloudFormation stack set, its stack instances and their underlying resources
+	// upon deletion of this object. Defaults to false.
+	// +optional
+	// +kubebuilder:default:=false
+	DestroyStackSetOnDeletion bool `json:"destroyStackSetOnDeletion,omitempty"`
+}
+
+// StackSetInstances are the targets and regions of the stack instances of a stack set.
+type StackSetInstances struct {
+	// Accounts are the IDs of the AWS accounts to deploy stack instances to,
+	// for the 'SELF_MANAGED' permission model.
+	// +optional
+	Accounts []string `json:"accounts,omitempty"`
+
+	// OrganizationalUnitIDs are the IDs of the AWS Organizations organizational units to deploy stack instances to,
+	// for the 'SERVICE_MANAGED' permission model.
+	// +optional
+	OrganizationalUnitIDs []string `json:"organizationalUnitIDs,omitempty"`
+
+	// Regions to deploy stack instances to.
+	// +kubebuilder:validation:MinItems=1
+	// +required
+	Regions []string `json:"regions"`
+}
+
+// StackSetAutoDeployment describes whether stack instances are automatically deployed
+// to accounts that are added to the target organizational units.
+type StackSetAutoDeployment struct {
+	// Enabled deploys stack instances to accounts that are added to the target organizational units.
+	// +required
+	Enabled bool `json:"enabled"`
+
+	// RetainStacksOnAccountRemoval retains the stacks of the stack instances in accounts that are removed
+	// from the target organizational units, instead of deleting them.
+	// +optional
+	RetainStacksOnAccountRemoval bool `json:"retainStacksOnAccountRemoval,omitempty"`
+}
+
+// StackSetOperationPreferences control how CloudFormation performs stack set operations.
+// Only one of the concurrency settings and one of the failure tolerance settings can be specified.
+type StackSetOperationPreferences struct {
+	// The maximum number of accounts in which to perform the operation at one time.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxConcurrentCount *int32 `json:"maxConcurrentCount,omitempty"`
+
+	// The maximum percentage of accounts in which to perform the operation at one time.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	MaxConcurrentPercentage *int32 `json:"maxConcurrentPercentage,omitempty"`
+
+	// The number of accounts, per region, for which the operation can fail before CloudFormation
+	// stops the operation in that region.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	FailureToleranceCount *int32 `json:"failureToleranceCount,omitempty"`
+
+	// The percentage of accounts, per region, for which the operation can fail before CloudFormation
+	// stops the operation in that region.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	FailureTolerancePercentage *int32 `json:"failureTolerancePercentage,omitempty"`
+
+	// Whether the operation is performed in one region at a time ('SEQUENTIAL') or in all regions at once ('PARALLEL').
+	// +kubebuilder:validation:Enum=SEQUENTIAL;PARALLEL
+	// +optional
+	RegionConcurrencyType string `json:"regionConcurrencyType,omitempty"`
+
+	// The order of the regions in which the operation is performed.
+	// +optional
+	RegionOrder []string `json:"regionOrder,omitempty"`
+}
+
+// CloudFormationStackSetStatus defines the observed state of a CloudFormation stack set
+type CloudFormationStackSetStatus struct {
+	// ObservedGeneration is the last observed generation.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	meta.ReconcileRequestStatus `json:",inline"`
+
+	// Conditions holds the conditions for the CloudFormationStackSet.
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// LastAppliedRevision is the revision of the last successfully applied source.
+	// The revision format for Git sources is <branch|tag>@sha1:<commit-sha>.
+	// +optional
+	LastAppliedRevision string `json:"lastAppliedRevision,omitempty"`
+
+	// LastAttemptedRevision is the revision of the last reconciliation attempt.
+	// The revision format for Git sources is <branch|tag>@sha1:<commit-sha>.
+	// +optional
+	LastAttemptedRevision string `json:"lastAttemptedRevision,omitempty"`
+
+	// LastOperationID is the ID of the last stack set operation started by the controller.
+	// +optional
+	LastOperationID string `json:"lastOperationId,omitempty"`
+
+	// StackSetName is the name of the CloudFormation stack set created by
+	// the controller for the CloudFormationStackSet resource.
+	// +optional
+	StackSetName string `json:"stackSetName,omitempty"`
+
+	// StackInstances holds the status of the stack set's stack instances.
+	// +optional
+	StackInstances []StackInstanceStatus `json:"stackInstances,omitempty"`
+}
+
+// StackInstanceStatus is the status of a stack instance of a stack set.
+type StackInstanceStatus struct {
+	// Account of the stack instance.
+	Account string `json:"account"`
+
+	// Region of the stack instance.
+	Region string `json:"region"`
+
+	// OrganizationalUnitID is the organizational unit that the stack instance was deployed for,
+	// for the 'SERVICE_MANAGED' permission model.
+	// +optional
+	OrganizationalUnitID string `json:"organizationalUnitId,omitempty"`
+
+	// Status of the stack instance: CURRENT, OUTDATED or INOPERABLE.
+	Status string `json:"status"`
+
+	// DetailedStatus of the stack instance, like SUCCEEDED, FAILED or RUNNING.
+	// +optional
+	DetailedStatus string `json:"detailedStatus,omitempty"`
+
+	// StatusReason explains the status of the stack instance.
+	// +optional
+	StatusReason string `json:"statusReason,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:shortName=cfnstackset
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status",description=""
+// +kubebuilder:printcolumn:name="Status",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].message",description=""
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp",description=""
+
+// CloudFormationStackSet is the Schema for the CloudFormation stack set API
+type CloudFormationStackSet struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   CloudFormationStackSetSpec   `json:"spec,omitempty"`
+	Status CloudFormationStackSetStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// CloudFormationStackSetList contains a list of CloudFormation stack sets
+type CloudFormationStackSetList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []CloudFormationStackSet `json:"items"`
+}
+
+// The potential reasons that are associated with the condition types of stack sets
+const (
+	StackSetOperationFailedReason = "StackSetOperationFailed"
+)
+
+// StackSetReadinessUpdate describes a change to the readiness of a stack set
+type StackSetReadinessUpdate struct {
+	Reason         string
+	Message        string
+	SourceRevision string
+	OperationID    string
+}
+
+// SetCloudFormationStackSetReadiness sets the ReadyCondition, ObservedGeneration, LastOperationID, and LastAttemptedRevision
+// on the CloudFormation stack set.
+func SetCloudFormationStackSetReadiness(cfnStackSet *CloudFormationStackSet, status metav1.ConditionStatus, update StackSetReadinessUpdate) {
+	newCondition := metav1.Condition{
+		Type:               meta.ReadyCondition,
+		Status:             status,
+		Reason:             update.Reason,
+		Message:            update.Message,
+		ObservedGeneration: cfnStackSet.Generation,
+	}
+
+	apimeta.SetStatusCondition(&cfnStackSet.Status.Conditions, newCondition)
+	cfnStackSet.Status.ObservedGeneration = cfnStackSet.Generation
+	cfnStackSet.Status.StackSetName = cfnStackSet.Spec.StackSetName
+	if update.SourceRevision != "" {
+		cfnStackSet.Status.LastAttemptedRevision = update.SourceRevision
+	}
+	if update.OperationID != "" {
+		cfnStackSet.Status.LastOperationID = update.OperationID
+	}
+}
+
+// CloudFormationStackSetProgressing resets the conditions of the given CloudFormation
+// stack set to a single ReadyCondition with status ConditionUnknown.
+func CloudFormationStackSetProgressing(cfnStackSet CloudFormationStackSet, update StackSetReadinessUpdate) CloudFormationStackSet {
+	update.Reason = meta.ProgressingReason
+	SetCloudFormationStackSetReadiness(&cfnStackSet, metav1.ConditionUnknown, update)
+	return cfnStackSet
+}
+
+// CloudFormationStackSetNotReady registers a failed reconciliation attempt of the given CloudFormation stack set.
+func CloudFormationStackSetNotReady(cfnStackSet CloudFormationStackSet, update StackSetReadinessUpdate) CloudFormationStackSet {
+	SetCloudFormationStackSetReadiness(&cfnStackSet, metav1.ConditionFalse, update)
+	return cfnStackSet
+}
+
+// CloudFormationStackSetReady registers a successful reconciliation of the given CloudFormation stack set.
+func CloudFormationStackSetReady(cfnStackSet CloudFormationStackSet) CloudFormationStackSet {
+	SetCloudFormationStackSetReadiness(
+		&cfnStackSet,
+		metav1.ConditionTrue,
+		StackSetReadinessUpdate{
+			Reason:         meta.SucceededReason,
+			Message:        "Stack set reconciliation succeeded",
+			SourceRevision: cfnStackSet.Status.LastAttemptedRevision,
+		},
+	)
+	cfnStackSet.Status.LastAppliedRevision = cfnStackSet.Status.LastAttemptedRevision
+	return cfnStackSet
+}
+
+// GetRetryInterval returns the retry interval
+func (in CloudFormationStackSet) GetRetryInterval() time.Duration {
+	if in.Spec.RetryInterval != nil {
+		return in.Spec.RetryInterval.Duration
+	}
+	return in.Spec.Interval.Duration
+}
+
+func (in CloudFormationStackSet) GetConditions() []metav1.Condition {
+	return in.Status.Conditions
+}
+
+func (in *CloudFormationStackSet) SetConditions(conditions []metav1.Condition) {
+	in.Status.Conditions = conditions
+}
+
+func init() {
+	SchemeBuilder.Register(&CloudFormationStackSet{}, &CloudFormationStackSetList{})
+}
@@ -18,6 +18,28 @@ const (
 	GitRepositoryIndexKey        = ".metadata.gitRepository"
 	BucketIndexKey               = ".metadata.bucket"
 	OCIRepositoryIndexKey        = ".metadata.ociRepository"
+	StackNameIndexKey            = ".spec.stackName"
+	StackIDIndexKey              = ".status.stackId"
+
+	// PlannedCondition indicates that the stack's changes were planned in a change set, without being deployed.
+	PlannedCondition = "Planned"
+
+	// ForceChangeSetAnnotation, when set to 'true', makes the controller create a change set at every reconciliation,
+	// even if the stack's template, parameters, tags and options did not change since its last applied change set,
+	// for example to detect and correct drift of the stack.
+	ForceChangeSetAnnotation = "cloudformation.contrib.fluxcd.io/force-change-set"
+)
+
+// The modes in which the controller reconciles a CloudFormation stack
+const (
+	// DeployMode creates and executes change sets to deploy the stack.
+	DeployMode = "Deploy"
+	// PlanMode creates and describes change sets to preview the stack's changes,
+	// then deletes the change sets without executing them.
+	PlanMode = "Plan"
+	// ObserveMode mirrors the status, outputs and resources of a stack that is managed outside the controller,
+	// without creating, updating or deleting the stack.
+	ObserveMode = "Observe"
 )
 
 // CloudFormationStackSpec defines the desired state of a CloudFormation stack
@@ -30,15 +52,37 @@ type CloudFormationStackSpec struct {
 	// +required
 	StackName string `json:"stackName,omitempty"`
 
+	// Mode is how the controller reconciles the stack.
+	// 'Deploy' creates and executes change sets to deploy the stack.
+	// 'Plan' creates and describes change sets to preview the stack's changes in the status,
+	// then deletes the change sets without executing them.
+	// 'Observe' mirrors the status, outputs and resources of an existing stack that is managed outside
+	// the controller into the status, without creating, updating or deleting the stack.
+	// Stacks in Plan or Observe mode are never destroyed on deletion. The deletion of a stack in Plan mode
+	// that was previously deployed and is destroyed on deletion is blocked until the stack is no longer planned.
+	// Defaults to 'Deploy'.
+	// +kubebuilder:validation:Enum=Deploy;Plan;Observe
+	// +kubebuilder:default=Deploy
+	// +optional
+	Mode string `json:"mode,omitempty"`
+
 	// Path to the CloudFormation template file.
 	// Defaults to the root path of the SourceRef and filename 'template.yaml'.
+	// Nested stack templates (AWS::CloudFormation::Stack TemplateURL) and AWS::Include snippets (Location)
+	// that are referenced by a relative path are loaded from the same source, relative to the referencing template.
 	// +optional
 	// +kubebuilder:default="template.yaml"
 	TemplatePath string `json:"templatePath,omitempty"`
 
+	// CDKAssembly deploys a stack synthesized by the AWS CDK from a cloud assembly (cdk.out) in the source,
+	// instead of the template file at TemplatePath.
+	// +optional
+	CDKAssembly *CDKAssembly `json:"cdkAssembly,omitempty"`
+
 	// SourceRef is the reference of the source where the CloudFormation template is stored.
-	// +required
-	SourceRef SourceReference `json:"sourceRef"`
+	// Required unless the stack is in Observe mode.
+	// +optional
+	SourceRef *SourceReference `json:"sourceRef,omitempty"`
 
 	// The interval at which to reconcile the CloudFormation stack.
 	// +required
@@ -84,6 +128,28 @@ type CloudFormationStackSpec struct {
 	// can be reconciled.
 	// +optional
 	DependsOn []meta.NamespacedObjectReference `json:"dependsOn,omitempty"`
+
+	// PostBuild describes the variable substitutions to apply to the stack's templates,
+	// stack parameter values and stack tag values before the stack is deployed.
+	// +optional
+	PostBuild *PostBuild `json:"postBuild,omitempty"`
+
+	// Patches is a list of patches to apply to the stack's root template after variable substitution,
+	// before the template is deployed. Patches are applied in order.
+	// +optional
+	Patches []TemplatePatch `json:"patches,omitempty"`
+
+	// PolicyRef is a reference to the Open Policy Agent (OPA) Rego policies that the stack must comply with.
+	// The policies are evaluated against the stack's template, parameters and tags before the change set is created,
+	// and again with the change set's changes before the change set is executed.
+	// Violations prevent the stack from being deployed.
+	// +optional
+	PolicyRef *PolicyReference `json:"policyRef,omitempty"`
+
+	// TemplateUpload overrides the controller's settings for uploading this stack's templates
+	// to the template bucket. Settings that are not specified default to the controller's settings.
+	// +optional
+	TemplateUpload *TemplateUploadSettings `json:"templateUpload,omitempty"`
 }
 
 // Key and value for a CloudFormation stack parameter.
@@ -108,6 +174,175 @@ type StackTag struct {
 	Value string `json:"value"`
 }
 
+// PostBuild describes the variable substitutions to apply to the stack's templates,
+// stack parameter values and stack tag values.
+// Variables are referenced as ${VAR}, and can have a default value: ${VAR:=default}.
+// References to variables that are not defined are left unchanged, so that references in
+// CloudFormation Fn::Sub functions like ${AWS::Region} or ${MyResource} are passed to CloudFormation.
+// A reference can be escaped as $${VAR} to pass the literal ${VAR} to CloudFormation.
+type PostBuild struct {
+	// Substitute holds a map of key/value pairs.
+	// The variables defined in your templates, parameter values and tag values
+	// with ${var} or ${var:=default} are substituted with the values in this map.
+	// Values in this map take precedence over values from SubstituteFrom.
+	// +optional
+	Substitute map[string]string `json:"substitute,omitempty"`
+
+	// SubstituteFrom holds references to ConfigMaps and Secrets in the stack's namespace
+	// containing the variables and their values to be substituted.
+	// If a variable is defined in multiple ConfigMaps and Secrets, the value from the last reference takes precedence.
+	// The ConfigMaps and Secrets are not watched: changes to their values are deployed in a new change set
+	// at the next reconciliation of the stack, at the latest after the stack's interval.
+	// +optional
+	SubstituteFrom []SubstituteReference `json:"substituteFrom,omitempty"`
+
+	// Strict fails the reconciliation if a variable without a default value is not defined,
+	// instead of leaving the reference unchanged. In strict mode, references in CloudFormation
+	// Fn::Sub functions to resource and parameter names must be escaped as $${Name}.
+	// Defaults to false.
+	// +optional
+	Strict bool `json:"strict,omitempty"`
+}
+
+// SubstituteReference contains a reference to a resource containing
+// the variables name and value.
+type SubstituteReference struct {
+	// Kind of the values referent, valid values are ('Secret', 'ConfigMap').
+	// +kubebuilder:validation:Enum=Secret;ConfigMap
+	// +required
+	Kind string `json:"kind"`
+
+	// Name of the values referent. Should reside in the same namespace as the
+	// referring resource.
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=253
+	// +required
+	Name string `json:"name"`
+
+	// Optional indicates whether the referenced resource must exist, or whether to
+	// tolerate its absence. If true and the referenced resource is absent, proceed
+	// as if the resource was present but empty, without any variables defined.
+	// +kubebuilder:default:=false
+	// +optional
+	Optional bool `json:"optional,omitempty"`
+}
+
+// CDKAssembly selects a stack in an AWS CDK cloud assembly.
+// The stack's synthesized template and parameters are deployed, and the file assets listed in the stack's
+// asset manifests are uploaded to the template bucket at the object keys that the template references.
+// Stacks must be synthesized with their file assets bucket set to the template bucket, for example
+// with the fileAssetsBucketName property of the DefaultStackSynthesizer.
+// Container image assets are not supported.
+type CDKAssembly struct {
+	// Path to the cloud assembly directory in the source, which contains the manifest.json file.
+	// Defaults to 'cdk.out'.
+	// +optional
+	// +kubebuilder:default="cdk.out"
+	Path string `json:"path,omitempty"`
+
+	// StackID is the artifact ID of the stack in the cloud assembly manifest, which is usually
+	// the stack's construct ID, for example 'MyStack'.
+	// +kubebuilder:validation:MinLength=1
+	// +required
+	StackID string `json:"stackId"`
+}
+
+// PolicyReference is a reference to Rego policy modules in a ConfigMap or in a source artifact.
+// The policies add violation messages to the 'deny' set of the 'cloudformation' package,
+// for example 'deny contains msg if { ... }'. The input document contains the stack's 'template',
+// 'parameters', 'tags' and change set 'changes'.
+type PolicyReference struct {
+	// Kind of the referent. The policy modules of a ConfigMap are its data entries with the '.rego' suffix.
+	// +kubebuilder:validation:Enum=ConfigMap;GitRepository;Bucket;OCIRepository
+	// +required
+	Kind string `json:"kind"`
+
+	// Name of the referent.
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=253
+	// +required
+	Name string `json:"name"`
+
+	// Namespace of the source object, defaults to the namespace of the CloudFormation stack object.
+	// ConfigMaps are always read from the namespace of the CloudFormation stack object.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Path to the policy file, or to the directory of policy files, in the source artifact.
+	// Files in the directory and its subdirectories with the '.rego' suffix are loaded,
+	// except test files with the '_test.rego' suffix. Defaults to the root of the source artifact.
+	// +optional
+	Path string `json:"path,omitempty"`
+}
+
+// TemplatePatch is a patch to apply to a CloudFormation template.
+type TemplatePatch struct {
+	// Patch contains a JSON6902 patch (a list of operations) or a strategic-merge-style patch (a mapping).
+	// A merge patch is merged recursively into the target: lists and values are replaced, null values
+	// remove the key, and '$patch: delete' removes the target resource.
+	// The patch can be written in YAML or JSON, and can use CloudFormation short-form functions like !Ref.
+	// +required
+	Patch string `json:"patch"`
+
+	// Target selects the template resources to patch.
+	// Paths in JSON6902 patches are relative to each selected resource.
+	// When not specified, the patch is applied to the whole template.
+	// +optional
+	Target *PatchTarget `json:"target,omitempty"`
+}
+
+// PatchTarget selects the resources in a CloudFormation template to patch.
+// When both fields are specified, resources must match both.
+type PatchTarget struct {
+	// LogicalID is a regular expression that matches the logical IDs of the resources to patch.
+	// +optional
+	LogicalID string `json:"logicalId,omitempty"`
+
+	// Type is a regular expression that matches the types of the resources to patch, for example 'AWS::SNS::.*'.
+	// +optional
+	Type string `json:"type,omitempty"`
+}
+
+// Settings for uploading CloudFormation templates to the template bucket.
+type TemplateUploadSettings struct {
+	// ID, ARN or alias of the AWS KMS key used to encrypt uploaded templates with SSE-KMS.
+	// Defaults to the default encryption of the template bucket.
+	// +optional
+	KMSKeyID string `json:"kmsKeyId,omitempty"`
+
+	// Prefix for the object keys of uploaded templates, for example 'my-cluster/'.
+	// +kubebuilder:validation:MaxLength=512
+	// +optional
+	KeyPrefix string `json:"keyPrefix,omitempty"`
+
+	// The tag keys and values to set on uploaded templates, in addition to the controller's object tags.
+	// +optional
+	ObjectTags []TemplateObjectTag `json:"objectTags,omitempty"`
+
+	// Canned ACL to apply to uploaded templates, or 'none' to not apply an ACL,
+	// for buckets that have S3 Object Ownership set to 'bucket owner enforced'.
+	// +kubebuilder:validation:Enum=none;private;bucket-owner-full-control;bucket-owner-read;authenticated-read;aws-exec-read
+	// +optional
+	ACL string `json:"acl,omitempty"`
+
+	// ID of the AWS account that is expected to own the template bucket.
+	// Defaults to the account of the controller's AWS credentials.
+	// +kubebuilder:validation:Pattern=`^[0-9]{12}$`
+	// +optional
+	ExpectedBucketOwner string `json:"expectedBucketOwner,omitempty"`
+}
+
+// Key and value for a template object tag.
+type TemplateObjectTag struct {
+	// Name of the object tag.
+	// +required
+	Key string `json:"key"`
+
+	// Value of the object tag.
+	// +required
+	Value string `json:"value"`
+}
+
 // Reference to a Flux source object.
 type SourceReference struct {
 	// API version of the source object.
@@ -163,19 +398,154 @@ type CloudFormationStackStatus struct {
 	LastAttemptedRevision string `json:"lastAttemptedRevision,omitempty"`
 
 	// LastAppliedChangeSet is the ARN of the last successfully applied CloudFormation change set.
-	// The change set name format is flux-<generation>-<source-revision>.
+	// The change set name format is flux-<generation>-<digest>, where the digest covers the stack's source
+	// contents, deployment inputs and last applied stack update time.
 	// +optional
 	LastAppliedChangeSet string `json:"lastAppliedChangeSet,omitempty"`
 
 	// LastAttemptedChangeSet is the ARN of the CloudFormation change set for the last reconciliation attempt.
-	// The change set name format is flux-<generation>-<source-revision>.
+	// The change set name format is flux-<generation>-<digest>, where the digest covers the stack's source
+	// contents, deployment inputs and last applied stack update time.
 	// +optional
 	LastAttemptedChangeSet string `json:"lastAttemptedChangeSet,omitempty"`
 
+	// LastAttemptedChangeSetTraceParent is the W3C traceparent of the reconciliation that created the
+	// LastAttemptedChangeSet, when tracing is enabled. The reconciliations that poll the change set link to it.
+	// +optional
+	LastAttemptedChangeSetTraceParent string `json:"lastAttemptedChangeSetTraceParent,omitempty"`
+
+	// LastAttemptedSourceDigest is the digest of the template files, nested templates, generated parameters
+	// and assets that the stack consumed from its source at the LastAttemptedRevision.
+	// While the digest does not change, new source revisions are recorded without creating a new change set.
+	// +optional
+	LastAttemptedSourceDigest string `json:"lastAttemptedSourceDigest,omitempty"`
+
+	// LastAppliedConfigDigest is the digest of the template, parameters, tags and options
+	// of the last successfully applied change set.
+	// +optional
+	LastAppliedConfigDigest string `json:"lastAppliedConfigDigest,omitempty"`
+
+	// LastAppliedStackUpdateTime is the last update time of the CloudFormation stack
+	// when the last change set was successfully applied.
+	// While the stack's update time and the digest of its configuration do not change,
+	// the controller marks the stack as ready without creating a new change set.
+	// +optional
+	LastAppliedStackUpdateTime *metav1.MicroTime `json:"lastAppliedStackUpdateTime,omitempty"`
+
+	// TemplateObjectKeyPrefixes are the prefixes of the template bucket object keys that templates
+	// were uploaded under for the stack. When the key prefix of the template bucket changes, the expired
+	// templates under the previous key prefixes are deleted like the templates under the current key prefix,
+	// and the previous key prefixes are removed once no templates are left under them.
+	// +optional
+	TemplateObjectKeyPrefixes []string `json:"templateObjectKeyPrefixes,omitempty"`
+
 	// StackName is the name of the CloudFormation stack created by
 	// the controller for the CloudFormationStack resource.
 	// +optional
 	StackName string `json:"stackName,omitempty"`
+
+	// StackID is the ID of the CloudFormation stack, recorded when the controller describes the stack
+	// after it was created.
+	// +optional
+	StackID string `json:"stackId,omitempty"`
+
+	// StackStatus is the status of the CloudFormation stack, like CREATE_COMPLETE.
+	// Only set in Observe mode.
+	// +optional
+	StackStatus string `json:"stackStatus,omitempty"`
+
+	// Outputs are the outputs of the CloudFormation stack.
+	// Only set in Observe mode.
+	// +optional
+	Outputs []StackOutput `json:"outputs,omitempty"`
+
+	// Resources are the resources of the CloudFormation stack, up to the first 100 resources.
+	// Only set in Observe mode.
+	// +optional
+	Resources []StackResource `json:"resources,omitempty"`
+
+	// LastPlan summarizes the changes of the last change set planned for the stack in Plan mode.
+	// +optional
+	LastPlan *StackPlan `json:"lastPlan,omitempty"`
+}
+
+// StackOutput is an output of a CloudFormation stack.
+type StackOutput struct {
+	// Key is the output's logical ID in the stack template.
+	Key string `json:"key"`
+
+	// Value is the output's value.
+	Value string `json:"value"`
+
+	// ExportName is the name of the output's cross-stack export, if any.
+	// +optional
+	ExportName string `json:"exportName,omitempty"`
+}
+
+// StackResource is a resource of a CloudFormation stack.
+type StackResource struct {
+	// LogicalID is the resource's logical ID in the stack template.
+	LogicalID string `json:"logicalId"`
+
+	// PhysicalID is the resource's physical ID.
+	// +optional
+	PhysicalID string `json:"physicalId,omitempty"`
+
+	// ResourceType is the resource's CloudFormation type, like AWS::S3::Bucket.
+	ResourceType string `json:"resourceType"`
+
+	// Status is the resource's status, like CREATE_COMPLETE.
+	Status string `json:"status"`
+}
+
+// StackPlan summarizes the changes of a change set that was created, but not executed, in Plan mode.
+type StackPlan struct {
+	// ChangeSet is the ARN of the planned change set.
+	// The change set is deleted once it is planned.
+	ChangeSet string `json:"changeSet"`
+
+	// Revision is the source revision of the planned change set.
+	// +optional
+	Revision string `json:"revision,omitempty"`
+
+	// Add is the number of resources that the change set adds.
+	Add int `json:"add"`
+
+	// Modify is the number of resources that the change set modifies.
+	Modify int `json:"modify"`
+
+	// Remove is the number of resources that the change set removes.
+	Remove int `json:"remove"`
+
+	// Changes are the resource changes of the change set.
+	// +optional
+	Changes []PlannedResourceChange `json:"changes,omitempty"`
+}
+
+// PlannedResourceChange is a resource change of a planned change set.
+type PlannedResourceChange struct {
+	// Action is the action that CloudFormation takes on the resource: Add, Modify, Remove, Import or Dynamic.
+	Action string `json:"action"`
+
+	// LogicalID is the resource's logical ID in the stack template.
+	LogicalID string `json:"logicalId"`
+
+	// PhysicalID is the resource's physical ID, if the resource exists.
+	// +optional
+	PhysicalID string `json:"physicalId,omitempty"`
+
+	// ResourceType is the resource's CloudFormation type, like AWS::S3::Bucket.
+	// +optional
+	ResourceType string `json:"resourceType,omitempty"`
+
+	// Replacement indicates whether CloudFormation replaces the resource to modify it: True, False or Conditional.
+	// +optional
+	Replacement string `json:"replacement,omitempty"`
+}
+
+// Summary returns a short description of the numbers of planned changes.
+func (p StackPlan) Summary() string {
+	return fmt.Sprintf("%d to add, %d to modify, %d to remove", p.Add, p.Modify, p.Remove)
 }
 
 // +kubebuilder:object:root=true
@@ -190,7 +560,8 @@ type CloudFormationStack struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 
-	Spec   CloudFormationStackSpec   `json:"spec,omitempty"`
+	Spec CloudFormationStackSpec `json:"spec,omitempty"`
+	// +kubebuilder:default={"observedGeneration":-1}
 	Status CloudFormationStackStatus `json:"status,omitempty"`
 }
 
@@ -208,11 +579,19 @@ const (
 	ArtifactFailedReason              = "ArtifactFailed"
 	ChangeSetFailedReason             = "ChangeSetFailed"
 	TemplateUploadFailedReason        = "TemplateUploadFailed"
+	TemplateBucketMissingReason       = "TemplateBucketMissing"
+	SubstitutionFailedReason          = "SubstitutionFailed"
+	PatchFailedReason                 = "PatchFailed"
+	PolicyViolationReason             = "PolicyViolation"
+	PolicyFailedReason                = "PolicyFailed"
 	CloudFormationApiCallFailedReason = "CloudFormationApiCallFailed"
+	ThrottledReason                   = "Throttled"
 	UnrecoverableStackFailureReason   = "UnrecoverableStackFailure"
 	StackRollbackFailureReason        = "StackRollbackFailed"
 	DependencyNotReadyReason          = "DependencyNotReady"
 	UnexpectedStatusReason            = "UnexpectedStatus"
+	StackNotFoundReason               = "StackNotFound"
+	DeletionBlockedReason             = "DeletionBlocked"
 )
 
 type ReadinessUpdate struct {
@@ -220,10 +599,15 @@ type ReadinessUpdate struct {
 	Message        string
 	SourceRevision string
 	ChangeSetArn   string
+	// Stalled marks a failure that retrying does not resolve until the stack's spec or source changes,
+	// like a template file that does not exist or a policy violation.
+	Stalled bool
 }
 
 // SetCloudFormationStackReadiness sets the ReadyCondition, ObservedGeneration, LastAttemptedChangeSet, and LastAttemptedRevision
 // on the CloudFormation stack.
+// Following the kstatus conventions, the ReconcilingCondition is set while the stack is progressing or a failed
+// reconciliation is retried, and the StalledCondition is set when the reconciliation failed with a stalled update.
 func SetCloudFormationStackReadiness(cfnStack *CloudFormationStack, status metav1.ConditionStatus, update ReadinessUpdate) {
 	newCondition := metav1.Condition{
 		Type:               meta.ReadyCondition,
@@ -234,6 +618,33 @@ func SetCloudFormationStackReadiness(cfnStack *CloudFormationStack, status metav
 	}
 
 	apimeta.SetStatusCondition(cfnStack.GetStatusConditions(), newCondition)
+	switch {
+	case status == metav1.ConditionTrue:
+		apimeta.RemoveStatusCondition(cfnStack.GetStatusConditions(), meta.ReconcilingCondition)
+		apimeta.RemoveStatusCondition(cfnStack.GetStatusConditions(), meta.StalledCondition)
+	case status == metav1.ConditionFalse && update.Stalled:
+		apimeta.RemoveStatusCondition(cfnStack.GetStatusConditions(), meta.ReconcilingCondition)
+		apimeta.SetStatusCondition(cfnStack.GetStatusConditions(), metav1.Condition{
+			Type:               meta.StalledCondition,
+			Status:             metav1.ConditionTrue,
+			Reason:             update.Reason,
+			Message:            update.Message,
+			ObservedGeneration: cfnStack.Generation,
+		})
+	default:
+		reason := meta.ProgressingReason
+		if status == metav1.ConditionFalse {
+			reason = meta.ProgressingWithRetryReason
+		}
+		apimeta.RemoveStatusCondition(cfnStack.GetStatusConditions(), meta.StalledCondition)
+		apimeta.SetStatusCondition(cfnStack.GetStatusConditions(), metav1.Condition{
+			Type:               meta.ReconcilingCondition,
+			Status:             metav1.ConditionTrue,
+			Reason:             reason,
+			Message:            update.Message,
+			ObservedGeneration: cfnStack.Generation,
+		})
+	}
 	cfnStack.Status.ObservedGeneration = cfnStack.Generation
 	cfnStack.Status.StackName = cfnStack.Spec.StackName
 	if update.SourceRevision != "" {
@@ -244,8 +655,8 @@ func SetCloudFormationStackReadiness(cfnStack *CloudFormationStack, status metav
 	}
 }
 
-// CloudFormationStackProgressing resets the conditions of the given CloudFormation
-// stack to a single ReadyCondition with status ConditionUnknown.
+// CloudFormationStackProgressing sets the ReadyCondition of the given CloudFormation stack to ConditionUnknown,
+// and marks the stack as reconciling.
 func CloudFormationStackProgressing(cfnStack CloudFormationStack, update ReadinessUpdate) CloudFormationStack {
 	update.Reason = meta.ProgressingReason
 	SetCloudFormationStackReadiness(&cfnStack, metav1.ConditionUnknown, update)
@@ -276,6 +687,27 @@ func CloudFormationStackReady(cfnStack CloudFormationStack, changeSetArn string)
 	return cfnStack
 }
 
+// CloudFormationStackPlanned registers a successful plan of the given CloudFormation stack,
+// without changing the last applied revision and change set.
+func CloudFormationStackPlanned(cfnStack CloudFormationStack, plan StackPlan) CloudFormationStack {
+	msg := fmt.Sprintf("Stack plan succeeded: %s", plan.Summary())
+	apimeta.SetStatusCondition(cfnStack.GetStatusConditions(), metav1.Condition{
+		Type:               PlannedCondition,
+		Status:             metav1.ConditionTrue,
+		Reason:             meta.SucceededReason,
+		Message:            msg,
+		ObservedGeneration: cfnStack.Generation,
+	})
+	SetCloudFormationStackReadiness(&cfnStack, metav1.ConditionTrue, ReadinessUpdate{
+		Reason:         meta.SucceededReason,
+		Message:        msg,
+		SourceRevision: plan.Revision,
+		ChangeSetArn:   plan.ChangeSet,
+	})
+	cfnStack.Status.LastPlan = &plan
+	return cfnStack
+}
+
 // GetDependsOn returns the list of dependencies, namespace scoped.
 func (in CloudFormationStack) GetDependsOn() []meta.NamespacedObjectReference {
 	return in.Spec.DependsOn
@@ -14,6 +14,21 @@ import (
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CDKAssembly) DeepCopyInto(out *CDKAssembly) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CDKAssembly.
+func (in *CDKAssembly) DeepCopy() *CDKAssembly {
+	if in == nil {
+		return nil
+	}
+	out := new(CDKAssembly)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CloudFormationStack) DeepCopyInto(out *CloudFormationStack) {
 	*out = *in
@@ -41,6 +56,133 @@ func (in *CloudFormationStack) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CloudFormationStackGenerator) DeepCopyInto(out *CloudFormationStackGenerator) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudFormationStackGenerator.
+func (in *CloudFormationStackGenerator) DeepCopy() *CloudFormationStackGenerator {
+	if in == nil {
+		return nil
+	}
+	out := new(CloudFormationStackGenerator)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CloudFormationStackGenerator) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CloudFormationStackGeneratorList) DeepCopyInto(out *CloudFormationStackGeneratorList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]CloudFormationStackGenerator, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudFormationStackGeneratorList.
+func (in *CloudFormationStackGeneratorList) DeepCopy() *CloudFormationStackGeneratorList {
+	if in == nil {
+		return nil
+	}
+	out := new(CloudFormationStackGeneratorList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CloudFormationStackGeneratorList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CloudFormationStackGeneratorSpec) DeepCopyInto(out *CloudFormationStackGeneratorSpec) {
+	*out = *in
+	in.Template.DeepCopyInto(&out.Template)
+	if in.Elements != nil {
+		in, out := &in.Elements, &out.Elements
+		*out = make([]map[string]string, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = make(map[string]string, len(*in))
+				for key, val := range *in {
+					(*out)[key] = val
+				}
+			}
+		}
+	}
+	if in.ElementsFrom != nil {
+		in, out := &in.ElementsFrom, &out.ElementsFrom
+		*out = make([]GeneratorElementsReference, len(*in))
+		copy(*out, *in)
+	}
+	out.Interval = in.Interval
+	if in.RetryInterval != nil {
+		in, out := &in.RetryInterval, &out.RetryInterval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudFormationStackGeneratorSpec.
+func (in *CloudFormationStackGeneratorSpec) DeepCopy() *CloudFormationStackGeneratorSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CloudFormationStackGeneratorSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CloudFormationStackGeneratorStatus) DeepCopyInto(out *CloudFormationStackGeneratorStatus) {
+	*out = *in
+	out.ReconcileRequestStatus = in.ReconcileRequestStatus
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.GeneratedStacks != nil {
+		in, out := &in.GeneratedStacks, &out.GeneratedStacks
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudFormationStackGeneratorStatus.
+func (in *CloudFormationStackGeneratorStatus) DeepCopy() *CloudFormationStackGeneratorStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CloudFormationStackGeneratorStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CloudFormationStackList) DeepCopyInto(out *CloudFormationStackList) {
 	*out = *in
@@ -74,7 +216,66 @@ func (in *CloudFormationStackList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *CloudFormationStackSpec) DeepCopyInto(out *CloudFormationStackSpec) {
+func (in *CloudFormationStackSet) DeepCopyInto(out *CloudFormationStackSet) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudFormationStackSet.
+func (in *CloudFormationStackSet) DeepCopy() *CloudFormationStackSet {
+	if in == nil {
+		return nil
+	}
+	out := new(CloudFormationStackSet)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CloudFormationStackSet) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CloudFormationStackSetList) DeepCopyInto(out *CloudFormationStackSetList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]CloudFormationStackSet, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudFormationStackSetList.
+func (in *CloudFormationStackSetList) DeepCopy() *CloudFormationStackSetList {
+	if in == nil {
+		return nil
+	}
+	out := new(CloudFormationStackSetList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CloudFormationStackSetList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CloudFormationStackSetSpec) DeepCopyInto(out *CloudFormationStackSetSpec) {
 	*out = *in
 	out.SourceRef = in.SourceRef
 	out.Interval = in.Interval
@@ -94,11 +295,114 @@ func (in *CloudFormationStackSpec) DeepCopyInto(out *CloudFormationStackSpec) {
 		*out = make([]StackTag, len(*in))
 		copy(*out, *in)
 	}
+	if in.AutoDeployment != nil {
+		in, out := &in.AutoDeployment, &out.AutoDeployment
+		*out = new(StackSetAutoDeployment)
+		**out = **in
+	}
+	in.StackInstances.DeepCopyInto(&out.StackInstances)
+	if in.OperationPreferences != nil {
+		in, out := &in.OperationPreferences, &out.OperationPreferences
+		*out = new(StackSetOperationPreferences)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudFormationStackSetSpec.
+func (in *CloudFormationStackSetSpec) DeepCopy() *CloudFormationStackSetSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CloudFormationStackSetSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CloudFormationStackSetStatus) DeepCopyInto(out *CloudFormationStackSetStatus) {
+	*out = *in
+	out.ReconcileRequestStatus = in.ReconcileRequestStatus
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.StackInstances != nil {
+		in, out := &in.StackInstances, &out.StackInstances
+		*out = make([]StackInstanceStatus, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudFormationStackSetStatus.
+func (in *CloudFormationStackSetStatus) DeepCopy() *CloudFormationStackSetStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CloudFormationStackSetStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CloudFormationStackSpec) DeepCopyInto(out *CloudFormationStackSpec) {
+	*out = *in
+	if in.CDKAssembly != nil {
+		in, out := &in.CDKAssembly, &out.CDKAssembly
+		*out = new(CDKAssembly)
+		**out = **in
+	}
+	if in.SourceRef != nil {
+		in, out := &in.SourceRef, &out.SourceRef
+		*out = new(SourceReference)
+		**out = **in
+	}
+	out.Interval = in.Interval
+	out.PollInterval = in.PollInterval
+	if in.RetryInterval != nil {
+		in, out := &in.RetryInterval, &out.RetryInterval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.StackParameters != nil {
+		in, out := &in.StackParameters, &out.StackParameters
+		*out = make([]StackParameter, len(*in))
+		copy(*out, *in)
+	}
+	if in.StackTags != nil {
+		in, out := &in.StackTags, &out.StackTags
+		*out = make([]StackTag, len(*in))
+		copy(*out, *in)
+	}
 	if in.DependsOn != nil {
 		in, out := &in.DependsOn, &out.DependsOn
 		*out = make([]meta.NamespacedObjectReference, len(*in))
 		copy(*out, *in)
 	}
+	if in.PostBuild != nil {
+		in, out := &in.PostBuild, &out.PostBuild
+		*out = new(PostBuild)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Patches != nil {
+		in, out := &in.Patches, &out.Patches
+		*out = make([]TemplatePatch, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PolicyRef != nil {
+		in, out := &in.PolicyRef, &out.PolicyRef
+		*out = new(PolicyReference)
+		**out = **in
+	}
+	if in.TemplateUpload != nil {
+		in, out := &in.TemplateUpload, &out.TemplateUpload
+		*out = new(TemplateUploadSettings)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudFormationStackSpec.
@@ -122,6 +426,30 @@ func (in *CloudFormationStackStatus) DeepCopyInto(out *CloudFormationStackStatus
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.LastAppliedStackUpdateTime != nil {
+		in, out := &in.LastAppliedStackUpdateTime, &out.LastAppliedStackUpdateTime
+		*out = (*in).DeepCopy()
+	}
+	if in.TemplateObjectKeyPrefixes != nil {
+		in, out := &in.TemplateObjectKeyPrefixes, &out.TemplateObjectKeyPrefixes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Outputs != nil {
+		in, out := &in.Outputs, &out.Outputs
+		*out = make([]StackOutput, len(*in))
+		copy(*out, *in)
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make([]StackResource, len(*in))
+		copy(*out, *in)
+	}
+	if in.LastPlan != nil {
+		in, out := &in.LastPlan, &out.LastPlan
+		*out = new(StackPlan)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudFormationStackStatus.
@@ -134,6 +462,139 @@ func (in *CloudFormationStackStatus) DeepCopy() *CloudFormationStackStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CloudFormationStackTemplate) DeepCopyInto(out *CloudFormationStackTemplate) {
+	*out = *in
+	in.Metadata.DeepCopyInto(&out.Metadata)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudFormationStackTemplate.
+func (in *CloudFormationStackTemplate) DeepCopy() *CloudFormationStackTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(CloudFormationStackTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CloudFormationStackTemplateMetadata) DeepCopyInto(out *CloudFormationStackTemplateMetadata) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudFormationStackTemplateMetadata.
+func (in *CloudFormationStackTemplateMetadata) DeepCopy() *CloudFormationStackTemplateMetadata {
+	if in == nil {
+		return nil
+	}
+	out := new(CloudFormationStackTemplateMetadata)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GeneratorElementsReference) DeepCopyInto(out *GeneratorElementsReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GeneratorElementsReference.
+func (in *GeneratorElementsReference) DeepCopy() *GeneratorElementsReference {
+	if in == nil {
+		return nil
+	}
+	out := new(GeneratorElementsReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PatchTarget) DeepCopyInto(out *PatchTarget) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PatchTarget.
+func (in *PatchTarget) DeepCopy() *PatchTarget {
+	if in == nil {
+		return nil
+	}
+	out := new(PatchTarget)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PlannedResourceChange) DeepCopyInto(out *PlannedResourceChange) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PlannedResourceChange.
+func (in *PlannedResourceChange) DeepCopy() *PlannedResourceChange {
+	if in == nil {
+		return nil
+	}
+	out := new(PlannedResourceChange)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PolicyReference) DeepCopyInto(out *PolicyReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PolicyReference.
+func (in *PolicyReference) DeepCopy() *PolicyReference {
+	if in == nil {
+		return nil
+	}
+	out := new(PolicyReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostBuild) DeepCopyInto(out *PostBuild) {
+	*out = *in
+	if in.Substitute != nil {
+		in, out := &in.Substitute, &out.Substitute
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.SubstituteFrom != nil {
+		in, out := &in.SubstituteFrom, &out.SubstituteFrom
+		*out = make([]SubstituteReference, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostBuild.
+func (in *PostBuild) DeepCopy() *PostBuild {
+	if in == nil {
+		return nil
+	}
+	out := new(PostBuild)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ReadinessUpdate) DeepCopyInto(out *ReadinessUpdate) {
 	*out = *in
@@ -164,6 +625,36 @@ func (in *SourceReference) DeepCopy() *SourceReference {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StackInstanceStatus) DeepCopyInto(out *StackInstanceStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StackInstanceStatus.
+func (in *StackInstanceStatus) DeepCopy() *StackInstanceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(StackInstanceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StackOutput) DeepCopyInto(out *StackOutput) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StackOutput.
+func (in *StackOutput) DeepCopy() *StackOutput {
+	if in == nil {
+		return nil
+	}
+	out := new(StackOutput)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *StackParameter) DeepCopyInto(out *StackParameter) {
 	*out = *in
@@ -179,6 +670,141 @@ func (in *StackParameter) DeepCopy() *StackParameter {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StackPlan) DeepCopyInto(out *StackPlan) {
+	*out = *in
+	if in.Changes != nil {
+		in, out := &in.Changes, &out.Changes
+		*out = make([]PlannedResourceChange, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StackPlan.
+func (in *StackPlan) DeepCopy() *StackPlan {
+	if in == nil {
+		return nil
+	}
+	out := new(StackPlan)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StackResource) DeepCopyInto(out *StackResource) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StackResource.
+func (in *StackResource) DeepCopy() *StackResource {
+	if in == nil {
+		return nil
+	}
+	out := new(StackResource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StackSetAutoDeployment) DeepCopyInto(out *StackSetAutoDeployment) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StackSetAutoDeployment.
+func (in *StackSetAutoDeployment) DeepCopy() *StackSetAutoDeployment {
+	if in == nil {
+		return nil
+	}
+	out := new(StackSetAutoDeployment)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StackSetInstances) DeepCopyInto(out *StackSetInstances) {
+	*out = *in
+	if in.Accounts != nil {
+		in, out := &in.Accounts, &out.Accounts
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.OrganizationalUnitIDs != nil {
+		in, out := &in.OrganizationalUnitIDs, &out.OrganizationalUnitIDs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Regions != nil {
+		in, out := &in.Regions, &out.Regions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StackSetInstances.
+func (in *StackSetInstances) DeepCopy() *StackSetInstances {
+	if in == nil {
+		return nil
+	}
+	out := new(StackSetInstances)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StackSetOperationPreferences) DeepCopyInto(out *StackSetOperationPreferences) {
+	*out = *in
+	if in.MaxConcurrentCount != nil {
+		in, out := &in.MaxConcurrentCount, &out.MaxConcurrentCount
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxConcurrentPercentage != nil {
+		in, out := &in.MaxConcurrentPercentage, &out.MaxConcurrentPercentage
+		*out = new(int32)
+		**out = **in
+	}
+	if in.FailureToleranceCount != nil {
+		in, out := &in.FailureToleranceCount, &out.FailureToleranceCount
+		*out = new(int32)
+		**out = **in
+	}
+	if in.FailureTolerancePercentage != nil {
+		in, out := &in.FailureTolerancePercentage, &out.FailureTolerancePercentage
+		*out = new(int32)
+		**out = **in
+	}
+	if in.RegionOrder != nil {
+		in, out := &in.RegionOrder, &out.RegionOrder
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StackSetOperationPreferences.
+func (in *StackSetOperationPreferences) DeepCopy() *StackSetOperationPreferences {
+	if in == nil {
+		return nil
+	}
+	out := new(StackSetOperationPreferences)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StackSetReadinessUpdate) DeepCopyInto(out *StackSetReadinessUpdate) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StackSetReadinessUpdate.
+func (in *StackSetReadinessUpdate) DeepCopy() *StackSetReadinessUpdate {
+	if in == nil {
+		return nil
+	}
+	out := new(StackSetReadinessUpdate)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *StackTag) DeepCopyInto(out *StackTag) {
 	*out = *in
@@ -193,3 +819,73 @@ func (in *StackTag) DeepCopy() *StackTag {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SubstituteReference) DeepCopyInto(out *SubstituteReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubstituteReference.
+func (in *SubstituteReference) DeepCopy() *SubstituteReference {
+	if in == nil {
+		return nil
+	}
+	out := new(SubstituteReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TemplateObjectTag) DeepCopyInto(out *TemplateObjectTag) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TemplateObjectTag.
+func (in *TemplateObjectTag) DeepCopy() *TemplateObjectTag {
+	if in == nil {
+		return nil
+	}
+	out := new(TemplateObjectTag)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TemplatePatch) DeepCopyInto(out *TemplatePatch) {
+	*out = *in
+	if in.Target != nil {
+		in, out := &in.Target, &out.Target
+		*out = new(PatchTarget)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TemplatePatch.
+func (in *TemplatePatch) DeepCopy() *TemplatePatch {
+	if in == nil {
+		return nil
+	}
+	out := new(TemplatePatch)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TemplateUploadSettings) DeepCopyInto(out *TemplateUploadSettings) {
+	*out = *in
+	if in.ObjectTags != nil {
+		in, out := &in.ObjectTags, &out.ObjectTags
+		*out = make([]TemplateObjectTag, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TemplateUploadSettings.
+func (in *TemplateUploadSettings) DeepCopy() *TemplateUploadSettings {
+	if in == nil {
+		return nil
+	}
+	out := new(TemplateUploadSettings)
+	in.DeepCopyInto(out)
+	return out
+}
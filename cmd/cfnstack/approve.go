@@ -0,0 +1,100 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT-0
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	cfnv1 "github.com/awslabs/aws-cloudformation-controller-for-flux/api/v1alpha1"
+)
+
+var approveCmd = &cobra.Command{
+	Use:   "approve NAME",
+	Short: "Approve the last plan of a CloudFormationStack in Plan mode and deploy it",
+	Long: `The approve command switches a CloudFormationStack from Plan mode to Deploy mode,
+so that the controller deploys the changes that were previewed in the stack's last plan.
+
+The plan is only approved if it is up to date with the stack's spec and
+if the stack's source still has the revision that was planned.`,
+	Example: `  # Review the planned changes of a stack
+  kubectl get cfnstack my-stack -o jsonpath='{.status.lastPlan}'
+
+  # Deploy the planned changes
+  cfnstack approve my-stack`,
+	Args: cobra.ExactArgs(1),
+	RunE: approveCmdRun,
+}
+
+func init() {
+	rootCmd.AddCommand(approveCmd)
+}
+
+func approveCmdRun(cmd *cobra.Command, args []string) error {
+	kubeClient, namespace, err := newKubeClient()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(cmd.Context(), rootArgs.timeout)
+	defer cancel()
+
+	key := types.NamespacedName{Namespace: namespace, Name: args[0]}
+	return approveStack(ctx, cmd.OutOrStdout(), kubeClient, key)
+}
+
+// approveStack switches the stack from Plan mode to Deploy mode,
+// if the stack's last plan is up to date with the stack's spec and source.
+func approveStack(ctx context.Context, out io.Writer, kubeClient client.Client, key types.NamespacedName) error {
+	cfnStack := &cfnv1.CloudFormationStack{}
+	if err := kubeClient.Get(ctx, key, cfnStack); err != nil {
+		return fmt.Errorf("unable to get CloudFormationStack '%s': %w", key, err)
+	}
+	if cfnStack.Spec.Mode != cfnv1.PlanMode {
+		return fmt.Errorf("CloudFormationStack '%s' is not in Plan mode, there is no plan to approve", key)
+	}
+
+	plan := cfnStack.Status.LastPlan
+	planned := apimeta.FindStatusCondition(cfnStack.Status.Conditions, cfnv1.PlannedCondition)
+	if plan == nil || planned == nil || planned.Status != metav1.ConditionTrue || planned.ObservedGeneration != cfnStack.Generation {
+		return fmt.Errorf("CloudFormationStack '%s' does not have an up-to-date plan, wait for the stack to be planned", key)
+	}
+
+	revision, err := currentSourceRevision(ctx, kubeClient, cfnStack)
+	if err != nil {
+		return err
+	}
+	if revision != plan.Revision {
+		return fmt.Errorf("the source of CloudFormationStack '%s' changed since the stack was planned (planned revision %s, current revision %s), wait for the stack to be planned again",
+			key, plan.Revision, revision)
+	}
+
+	logAction(out, "approving plan of CloudFormationStack %s in %s namespace for revision %s: %s", key.Name, key.Namespace, plan.Revision, plan.Summary())
+	patch := client.MergeFrom(cfnStack.DeepCopy())
+	cfnStack.Spec.Mode = cfnv1.DeployMode
+	if err := kubeClient.Patch(ctx, cfnStack, patch); err != nil {
+		return fmt.Errorf("unable to patch CloudFormationStack '%s': %w", key, err)
+	}
+	logSuccess(out, "CloudFormationStack switched to Deploy mode, the controller will deploy the planned changes")
+	return nil
+}
+
+// currentSourceRevision returns the revision of the artifact of the stack's source.
+func currentSourceRevision(ctx context.Context, kubeClient client.Client, cfnStack *cfnv1.CloudFormationStack) (string, error) {
+	source, key, err := getStackSource(ctx, kubeClient, cfnStack)
+	if err != nil {
+		return "", err
+	}
+	artifact := source.GetArtifact()
+	if artifact == nil {
+		return "", fmt.Errorf("%s '%s' is not ready, artifact not found", cfnStack.Spec.SourceRef.Kind, key)
+	}
+	return artifact.Revision, nil
+}
@@ -0,0 +1,95 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT-0
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	cfnv1 "github.com/awslabs/aws-cloudformation-controller-for-flux/api/v1alpha1"
+)
+
+func generateMockPlannedCfnStack() *cfnv1.CloudFormationStack {
+	cfnStack := generateMockCfnStack()
+	cfnStack.Spec.Mode = cfnv1.PlanMode
+	*cfnStack = cfnv1.CloudFormationStackPlanned(*cfnStack, cfnv1.StackPlan{
+		ChangeSet: mockChangeSetArn,
+		Revision:  mockSourceRevision,
+		Add:       1,
+		Changes: []cfnv1.PlannedResourceChange{
+			{Action: "Add", LogicalID: "Bucket", ResourceType: "AWS::S3::Bucket"},
+		},
+	})
+	return cfnStack
+}
+
+func TestApproveStack(t *testing.T) {
+	key := types.NamespacedName{Namespace: mockNamespace, Name: mockStackName}
+
+	testCases := map[string]struct {
+		cfnStack       func() *cfnv1.CloudFormationStack
+		sourceRevision string
+		wantedErr      string
+	}{
+		"approve an up-to-date plan": {
+			cfnStack:       generateMockPlannedCfnStack,
+			sourceRevision: mockSourceRevision,
+		},
+		"refuse stacks that are not in Plan mode": {
+			cfnStack:       generateMockCfnStack,
+			sourceRevision: mockSourceRevision,
+			wantedErr:      "CloudFormationStack 'mock-namespace/mock-stack' is not in Plan mode, there is no plan to approve",
+		},
+		"refuse stacks that were not planned yet": {
+			cfnStack: func() *cfnv1.CloudFormationStack {
+				cfnStack := generateMockCfnStack()
+				cfnStack.Spec.Mode = cfnv1.PlanMode
+				return cfnStack
+			},
+			sourceRevision: mockSourceRevision,
+			wantedErr:      "CloudFormationStack 'mock-namespace/mock-stack' does not have an up-to-date plan, wait for the stack to be planned",
+		},
+		"refuse plans of a previous generation": {
+			cfnStack: func() *cfnv1.CloudFormationStack {
+				cfnStack := generateMockPlannedCfnStack()
+				cfnStack.Generation = 2
+				return cfnStack
+			},
+			sourceRevision: mockSourceRevision,
+			wantedErr:      "CloudFormationStack 'mock-namespace/mock-stack' does not have an up-to-date plan, wait for the stack to be planned",
+		},
+		"refuse plans of a previous source revision": {
+			cfnStack:       generateMockPlannedCfnStack,
+			sourceRevision: "main@sha1:5394cb7f48332b2de7c17dd8b8384bbc84b7e738",
+			wantedErr: "the source of CloudFormationStack 'mock-namespace/mock-stack' changed since the stack was planned " +
+				"(planned revision main@sha1:132f4e719209eb10b9485302f8593fc0e680f4fc, current revision main@sha1:5394cb7f48332b2de7c17dd8b8384bbc84b7e738), " +
+				"wait for the stack to be planned again",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			kubeClient := newFakeClient(t, metav1.ConditionTrue, tc.cfnStack(), generateMockGitRepo(tc.sourceRevision))
+			out := &bytes.Buffer{}
+
+			err := approveStack(context.Background(), out, kubeClient, key)
+
+			cfnStack := &cfnv1.CloudFormationStack{}
+			require.NoError(t, kubeClient.Get(context.Background(), key, cfnStack))
+			if tc.wantedErr != "" {
+				require.EqualError(t, err, tc.wantedErr)
+				require.NotEqual(t, cfnv1.DeployMode, cfnStack.Spec.Mode)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, cfnv1.DeployMode, cfnStack.Spec.Mode)
+			require.Contains(t, out.String(), "for revision "+mockSourceRevision+": 1 to add, 0 to modify, 0 to remove")
+		})
+	}
+}
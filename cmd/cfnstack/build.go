@@ -0,0 +1,312 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT-0
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/fluxcd/pkg/untar"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/yaml"
+
+	cfnv1 "github.com/awslabs/aws-cloudformation-controller-for-flux/api/v1alpha1"
+	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/build"
+	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/clients/cloudformation"
+	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/template"
+)
+
+const (
+	defaultControllerName = "cfn-flux-controller"
+	defaultTemplatePath   = "template.yaml"
+)
+
+var buildCmd = &cobra.Command{
+	Use:   "build [NAME]",
+	Short: "Render the deployment inputs of a CloudFormationStack offline",
+	Long: `The build command renders the template, parameters, tags, capabilities and change set name that the controller
+would submit to CloudFormation for a CloudFormationStack manifest, using a local directory or artifact tarball
+as the stack's source. It does not connect to AWS or to the Kubernetes cluster.
+
+Post-build variables are substituted from the manifest's 'spec.postBuild.substitute' values.
+The values of the ConfigMaps and Secrets in 'spec.postBuild.substituteFrom' can be given with '--var'.`,
+	Example: `  # Render a stack from a local checkout of its Git repository
+  cfnstack build -f my-stack.yaml --path ./my-cfn-templates-repo
+
+  # Render a stack from a source artifact tarball
+  cfnstack build -f my-stack.yaml --path ./artifact.tar.gz
+
+  # Render one of the stacks in a multi-document manifest, with a variable from a ConfigMap
+  cfnstack build my-stack -f stacks.yaml --path . --var cluster_env=prod`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: buildCmdRun,
+}
+
+type buildFlags struct {
+	manifest          string
+	path              string
+	generation        int64
+	vars              map[string]string
+	controllerVersion string
+	stackTags         map[string]string
+	output            string
+}
+
+var buildArgs buildFlags
+
+func init() {
+	buildCmd.Flags().StringVarP(&buildArgs.manifest, "file", "f", "", "path to the CloudFormationStack manifest")
+	buildCmd.Flags().StringVar(&buildArgs.path, "path", "", "path to the local source directory or artifact tarball (.tar.gz)")
+	buildCmd.Flags().Int64Var(&buildArgs.generation, "generation", 0, "stack generation that the change set name is computed with (defaults to the manifest's generation, or 1)")
+	buildCmd.Flags().StringToStringVar(&buildArgs.vars, "var", nil, "post-build variables that replace the values of 'spec.postBuild.substituteFrom'")
+	buildCmd.Flags().StringVar(&buildArgs.controllerVersion, "controller-version", "unknown-version", "controller version for the default version tag")
+	buildCmd.Flags().StringToStringVar(&buildArgs.stackTags, "stack-tags", nil, "tags that the controller applies to all stacks, like the controller's --stack-tags flag")
+	buildCmd.Flags().StringVarP(&buildArgs.output, "output", "o", "yaml", "output format, one of yaml or json")
+	_ = buildCmd.MarkFlagRequired("file")
+	_ = buildCmd.MarkFlagRequired("path")
+	rootCmd.AddCommand(buildCmd)
+}
+
+// buildResult holds the deployment inputs of a stack.
+type buildResult struct {
+	StackName       string          `json:"stackName"`
+	ChangeSetName   string          `json:"changeSetName"`
+	Capabilities    []string        `json:"capabilities"`
+	Parameters      []buildKeyValue `json:"parameters,omitempty"`
+	Tags            []buildKeyValue `json:"tags"`
+	Template        string          `json:"template"`
+	NestedTemplates []buildTemplate `json:"nestedTemplates,omitempty"`
+	Assets          []string        `json:"assets,omitempty"`
+}
+
+type buildKeyValue struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+type buildTemplate struct {
+	Path     string `json:"path"`
+	Template string `json:"template"`
+}
+
+func buildCmdRun(cmd *cobra.Command, args []string) error {
+	name := ""
+	if len(args) == 1 {
+		name = args[0]
+	}
+	cfnStack, err := readStackManifest(buildArgs.manifest, name)
+	if err != nil {
+		return err
+	}
+	if cfnStack.Namespace == "" {
+		cfnStack.Namespace = "default"
+		if kubeconfig.Namespace != nil && *kubeconfig.Namespace != "" {
+			cfnStack.Namespace = *kubeconfig.Namespace
+		}
+	}
+
+	result, err := buildStack(*cfnStack, buildArgs)
+	if err != nil {
+		return err
+	}
+	return printBuildResult(cmd.OutOrStdout(), result, buildArgs.output)
+}
+
+// readStackManifest returns the CloudFormationStack in the manifest file. If the file contains multiple
+// CloudFormationStacks, name selects one of them.
+func readStackManifest(manifestPath string, name string) (*cfnv1.CloudFormationStack, error) {
+	file, err := os.Open(manifestPath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read manifest: %w", err)
+	}
+	defer file.Close()
+
+	var stacks []*cfnv1.CloudFormationStack
+	decoder := utilyaml.NewYAMLOrJSONDecoder(file, 4096)
+	for {
+		var obj map[string]interface{}
+		if err := decoder.Decode(&obj); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("unable to parse manifest '%s': %w", manifestPath, err)
+		}
+		if obj["kind"] != cfnv1.CloudFormationStackKind {
+			continue
+		}
+		cfnStack := &cfnv1.CloudFormationStack{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj, cfnStack); err != nil {
+			return nil, fmt.Errorf("unable to parse CloudFormationStack in manifest '%s': %w", manifestPath, err)
+		}
+		if name == "" || cfnStack.Name == name {
+			stacks = append(stacks, cfnStack)
+		}
+	}
+
+	switch {
+	case len(stacks) == 0 && name != "":
+		return nil, fmt.Errorf("CloudFormationStack '%s' not found in manifest '%s'", name, manifestPath)
+	case len(stacks) == 0:
+		return nil, fmt.Errorf("no CloudFormationStack found in manifest '%s'", manifestPath)
+	case len(stacks) > 1:
+		return nil, fmt.Errorf("manifest '%s' contains %d CloudFormationStacks, select one by name", manifestPath, len(stacks))
+	}
+	return stacks[0], nil
+}
+
+// buildStack computes the stack's deployment inputs like the controller does, from the given source directory or tarball.
+func buildStack(cfnStack cfnv1.CloudFormationStack, args buildFlags) (*buildResult, error) {
+	if cfnStack.Spec.TemplatePath == "" && cfnStack.Spec.CDKAssembly == nil {
+		cfnStack.Spec.TemplatePath = defaultTemplatePath
+	}
+	switch {
+	case args.generation > 0:
+		cfnStack.Generation = args.generation
+	case cfnStack.Generation == 0:
+		cfnStack.Generation = 1
+	}
+
+	sourceDir, cleanup, err := openSource(args.path)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	// Substitute the post-build variables in the stack parameters, tags, patches and template files
+	vars, err := postBuildVariables(cfnStack, args.vars)
+	if err != nil {
+		return nil, err
+	}
+	stackToBuild := cfnStack.DeepCopy()
+	if err := build.SubstituteStackSpec(stackToBuild, vars); err != nil {
+		return nil, err
+	}
+
+	tmpl, err := build.LoadTemplate(sourceDir, cfnStack.Spec.TemplatePath, cfnStack.Spec.CDKAssembly, build.SubstituteTemplateTransform(cfnStack, vars))
+	if err != nil {
+		return nil, err
+	}
+	sourceDigest := build.SourceDigest(tmpl)
+	tmpl, err = tmpl.ApplyPatches(build.TemplatePatches(stackToBuild))
+	if err != nil {
+		return nil, err
+	}
+
+	clientStack := build.Stack(*stackToBuild, tmpl, build.Options{
+		ControllerName:    defaultControllerName,
+		ControllerVersion: args.controllerVersion,
+		StackTags:         args.stackTags,
+	})
+
+	result := &buildResult{
+		StackName:     clientStack.Name,
+		ChangeSetName: build.ChangeSetName(*stackToBuild, sourceDigest, build.ConfigDigest(clientStack, tmpl)),
+		Template:      clientStack.TemplateBody,
+	}
+	for _, capability := range cloudformation.ChangeSetCapabilities {
+		result.Capabilities = append(result.Capabilities, string(capability))
+	}
+	for _, param := range clientStack.Parameters {
+		result.Parameters = append(result.Parameters, buildKeyValue{Key: aws.ToString(param.ParameterKey), Value: aws.ToString(param.ParameterValue)})
+	}
+	for _, tag := range clientStack.Tags {
+		result.Tags = append(result.Tags, buildKeyValue{Key: aws.ToString(tag.Key), Value: aws.ToString(tag.Value)})
+	}
+	result.NestedTemplates = nestedTemplates(tmpl, map[string]bool{})
+	for _, asset := range tmpl.Assets {
+		result.Assets = append(result.Assets, asset.Keys...)
+	}
+	return result, nil
+}
+
+// openSource returns the directory of the local source. Tarballs are extracted into a temporary directory,
+// which is removed by the returned cleanup function.
+func openSource(path string) (string, func(), error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", nil, fmt.Errorf("unable to read source: %w", err)
+	}
+	if info.IsDir() {
+		return path, func() {}, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return "", nil, fmt.Errorf("unable to read source: %w", err)
+	}
+	defer file.Close()
+
+	tmpDir, err := os.MkdirTemp("", "cfnstack-build")
+	if err != nil {
+		return "", nil, err
+	}
+	if _, err := untar.Untar(file, tmpDir); err != nil {
+		os.RemoveAll(tmpDir)
+		return "", nil, fmt.Errorf("unable to extract source tarball '%s': %w", path, err)
+	}
+	return tmpDir, func() { os.RemoveAll(tmpDir) }, nil
+}
+
+// postBuildVariables returns the stack's post-build variables like the controller loads them,
+// with the given variables in place of the values of the referenced ConfigMaps and Secrets.
+// Returns nil if the stack does not specify any post-build substitutions.
+func postBuildVariables(cfnStack cfnv1.CloudFormationStack, substituteFrom map[string]string) (map[string]string, error) {
+	postBuild := cfnStack.Spec.PostBuild
+	if postBuild == nil {
+		return nil, nil
+	}
+
+	vars := make(map[string]string)
+	for name, value := range substituteFrom {
+		vars[name] = value
+	}
+	for name, value := range postBuild.Substitute {
+		vars[name] = value
+	}
+	if err := build.ValidateVariables(vars); err != nil {
+		return nil, err
+	}
+	return vars, nil
+}
+
+// nestedTemplates returns the template files that the template references, recursively, in the order they are referenced.
+func nestedTemplates(tmpl *template.Template, seen map[string]bool) []buildTemplate {
+	var templates []buildTemplate
+	for _, ref := range tmpl.Nested {
+		if seen[ref.Template.Path] {
+			continue
+		}
+		seen[ref.Template.Path] = true
+		templates = append(templates, buildTemplate{Path: ref.Template.Path, Template: ref.Template.Body})
+		templates = append(templates, nestedTemplates(ref.Template, seen)...)
+	}
+	return templates
+}
+
+// printBuildResult writes the deployment inputs in the given format.
+func printBuildResult(out io.Writer, result *buildResult, format string) error {
+	var data []byte
+	var err error
+	switch strings.ToLower(format) {
+	case "yaml":
+		data, err = yaml.Marshal(result)
+	case "json":
+		data, err = json.MarshalIndent(result, "", "  ")
+		data = append(data, '\n')
+	default:
+		return fmt.Errorf("unsupported output format '%s', must be one of yaml or json", format)
+	}
+	if err != nil {
+		return err
+	}
+	_, err = out.Write(data)
+	return err
+}
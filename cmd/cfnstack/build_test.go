@@ -0,0 +1,155 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT-0
+
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	mockBuildTemplate = `Parameters:
+  BucketName:
+    Type: String
+Resources:
+  Bucket:
+    Type: AWS::S3::Bucket
+    Properties:
+      BucketName: !Ref BucketName
+      Tags:
+        - Key: env
+          Value: ${env}
+`
+
+	mockBuildManifest = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: vars
+---
+apiVersion: cloudformation.contrib.fluxcd.io/v1alpha1
+kind: CloudFormationStack
+metadata:
+  name: mock-stack
+  namespace: mock-namespace
+spec:
+  stackName: mock-real-stack
+  sourceRef:
+    kind: GitRepository
+    name: mock-repo
+  stackParameters:
+    - key: BucketName
+      value: ${env}-bucket
+  stackTags:
+    - key: team
+      value: payments
+  postBuild:
+    substituteFrom:
+      - kind: ConfigMap
+        name: vars
+  patches:
+    - patch: |
+        Resources:
+          Bucket:
+            DeletionPolicy: Retain
+---
+apiVersion: cloudformation.contrib.fluxcd.io/v1alpha1
+kind: CloudFormationStack
+metadata:
+  name: other-stack
+spec:
+  stackName: other-real-stack
+  templatePath: other.yaml
+`
+)
+
+func writeFile(t *testing.T, path string, content string) {
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+}
+
+func TestReadStackManifest(t *testing.T) {
+	manifest := filepath.Join(t.TempDir(), "stacks.yaml")
+	writeFile(t, manifest, mockBuildManifest)
+
+	cfnStack, err := readStackManifest(manifest, "mock-stack")
+	require.NoError(t, err)
+	require.Equal(t, "mock-real-stack", cfnStack.Spec.StackName)
+
+	_, err = readStackManifest(manifest, "")
+	require.EqualError(t, err, "manifest '"+manifest+"' contains 2 CloudFormationStacks, select one by name")
+
+	_, err = readStackManifest(manifest, "missing-stack")
+	require.EqualError(t, err, "CloudFormationStack 'missing-stack' not found in manifest '"+manifest+"'")
+}
+
+func TestBuildStack(t *testing.T) {
+	sourceDir := t.TempDir()
+	writeFile(t, filepath.Join(sourceDir, "template.yaml"), mockBuildTemplate)
+
+	// Package the source directory like a source artifact
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "template.yaml", Mode: 0o600, Size: int64(len(mockBuildTemplate))}))
+	_, err := tw.Write([]byte(mockBuildTemplate))
+	require.NoError(t, err)
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+	tarball := filepath.Join(t.TempDir(), "artifact.tar.gz")
+	writeFile(t, tarball, buf.String())
+
+	manifest := filepath.Join(t.TempDir(), "stacks.yaml")
+	writeFile(t, manifest, mockBuildManifest)
+	cfnStack, err := readStackManifest(manifest, "mock-stack")
+	require.NoError(t, err)
+
+	directoryResult, err := buildStack(*cfnStack, buildFlags{
+		path:              sourceDir,
+		vars:              map[string]string{"env": "prod"},
+		controllerVersion: "v1.2.3",
+		stackTags:         map[string]string{"owner": "platform"},
+	})
+	require.NoError(t, err)
+	changeSetName := directoryResult.ChangeSetName
+	require.Regexp(t, "^flux-1-[0-9a-f]{16}$", changeSetName)
+
+	for name, path := range map[string]string{"directory": sourceDir, "tarball": tarball} {
+		t.Run(name, func(t *testing.T) {
+			result, err := buildStack(*cfnStack, buildFlags{
+				path:              path,
+				vars:              map[string]string{"env": "prod"},
+				controllerVersion: "v1.2.3",
+				stackTags:         map[string]string{"owner": "platform"},
+			})
+			require.NoError(t, err)
+
+			require.Equal(t, "mock-real-stack", result.StackName)
+			// The change set name only depends on the stack's generation and deployment inputs
+			require.Equal(t, changeSetName, result.ChangeSetName)
+			require.Equal(t, []string{"CAPABILITY_IAM", "CAPABILITY_NAMED_IAM", "CAPABILITY_AUTO_EXPAND"}, result.Capabilities)
+			require.Equal(t, []buildKeyValue{{Key: "BucketName", Value: "prod-bucket"}}, result.Parameters)
+			require.Equal(t, []buildKeyValue{
+				{Key: "cfn-flux-controller/version", Value: "v1.2.3"},
+				{Key: "cfn-flux-controller/name", Value: "mock-stack"},
+				{Key: "cfn-flux-controller/namespace", Value: "mock-namespace"},
+				{Key: "owner", Value: "platform"},
+				{Key: "team", Value: "payments"},
+			}, result.Tags)
+			require.Contains(t, result.Template, "Value: prod")
+			require.Contains(t, result.Template, "DeletionPolicy: Retain")
+
+			out := &bytes.Buffer{}
+			require.NoError(t, printBuildResult(out, result, "yaml"))
+			require.Contains(t, out.String(), "changeSetName: "+changeSetName+"\n")
+		})
+	}
+
+	_, err = buildStack(*cfnStack, buildFlags{path: sourceDir, generation: 3, vars: map[string]string{"not-valid": "value"}})
+	require.ErrorContains(t, err, "variable substitution failed")
+}
@@ -0,0 +1,105 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT-0
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	cfnv1 "github.com/awslabs/aws-cloudformation-controller-for-flux/api/v1alpha1"
+)
+
+var eventsCmd = &cobra.Command{
+	Use:   "events NAME",
+	Short: "Display the Kubernetes events of a CloudFormationStack",
+	Long: `The events command displays the events that the controller recorded for a CloudFormationStack,
+such as change set creation and execution, policy violations and failures, oldest first.`,
+	Example: `  # Display the events of a stack
+  cfnstack events my-stack`,
+	Args: cobra.ExactArgs(1),
+	RunE: eventsCmdRun,
+}
+
+func init() {
+	rootCmd.AddCommand(eventsCmd)
+}
+
+func eventsCmdRun(cmd *cobra.Command, args []string) error {
+	kubeClient, namespace, err := newKubeClient()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(cmd.Context(), rootArgs.timeout)
+	defer cancel()
+
+	key := types.NamespacedName{Namespace: namespace, Name: args[0]}
+	events, err := listStackEvents(ctx, kubeClient, key)
+	if err != nil {
+		return err
+	}
+	if len(events) == 0 {
+		fmt.Fprintf(cmd.ErrOrStderr(), "no events found for CloudFormationStack '%s'\n", key)
+		return nil
+	}
+	return printEvents(cmd.OutOrStdout(), events, time.Now())
+}
+
+// listStackEvents returns the events involving the stack, sorted from oldest to newest.
+func listStackEvents(ctx context.Context, kubeClient client.Client, key types.NamespacedName) ([]corev1.Event, error) {
+	eventList := &corev1.EventList{}
+	err := kubeClient.List(ctx, eventList,
+		client.InNamespace(key.Namespace),
+		client.MatchingFields{"involvedObject.name": key.Name})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list events for CloudFormationStack '%s': %w", key, err)
+	}
+
+	var events []corev1.Event
+	for _, event := range eventList.Items {
+		if event.InvolvedObject.Kind == cfnv1.CloudFormationStackKind {
+			events = append(events, event)
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return lastSeen(events[i]).Before(lastSeen(events[j]))
+	})
+	return events, nil
+}
+
+// printEvents writes a table of the events.
+func printEvents(out io.Writer, events []corev1.Event, now time.Time) error {
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "LAST SEEN\tTYPE\tREASON\tMESSAGE")
+	for _, event := range events {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n",
+			duration.HumanDuration(now.Sub(lastSeen(event))),
+			event.Type,
+			event.Reason,
+			event.Message,
+		)
+	}
+	return w.Flush()
+}
+
+// lastSeen returns the time that the event was last recorded.
+func lastSeen(event corev1.Event) time.Time {
+	switch {
+	case !event.LastTimestamp.IsZero():
+		return event.LastTimestamp.Time
+	case !event.EventTime.IsZero():
+		return event.EventTime.Time
+	default:
+		return event.CreationTimestamp.Time
+	}
+}
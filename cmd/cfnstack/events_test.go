@@ -0,0 +1,56 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT-0
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	cfnv1 "github.com/awslabs/aws-cloudformation-controller-for-flux/api/v1alpha1"
+)
+
+func TestStackEvents(t *testing.T) {
+	now := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+	newEvent := func(name string, kind string, objName string, lastSeen time.Time, message string) *corev1.Event {
+		return &corev1.Event{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: mockNamespace},
+			InvolvedObject: corev1.ObjectReference{
+				Kind:      kind,
+				Name:      objName,
+				Namespace: mockNamespace,
+			},
+			Type:          corev1.EventTypeNormal,
+			Reason:        "info",
+			Message:       message,
+			LastTimestamp: metav1.NewTime(lastSeen),
+		}
+	}
+
+	kubeClient := newFakeClient(t, metav1.ConditionTrue,
+		newEvent("event-2", cfnv1.CloudFormationStackKind, mockStackName, now.Add(-2*time.Minute), "Stack update complete"),
+		newEvent("event-1", cfnv1.CloudFormationStackKind, mockStackName, now.Add(-5*time.Minute), "Creation of change set in progress"),
+		newEvent("event-3", cfnv1.CloudFormationStackKind, "other-stack", now, "Other stack event"),
+		newEvent("event-4", "GitRepository", mockStackName, now, "Repository event"),
+	)
+
+	events, err := listStackEvents(context.Background(), kubeClient, types.NamespacedName{Namespace: mockNamespace, Name: mockStackName})
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+
+	out := &bytes.Buffer{}
+	require.NoError(t, printEvents(out, events, now))
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 3)
+	require.Equal(t, []string{"LAST", "SEEN", "TYPE", "REASON", "MESSAGE"}, strings.Fields(lines[0]))
+	require.Equal(t, []string{"5m", "Normal", "info", "Creation", "of", "change", "set", "in", "progress"}, strings.Fields(lines[1]))
+	require.Equal(t, []string{"2m", "Normal", "info", "Stack", "update", "complete"}, strings.Fields(lines[2]))
+}
@@ -0,0 +1,283 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT-0
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	sourcev1 "github.com/fluxcd/source-controller/api/v1"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/yaml"
+
+	cfnv1 "github.com/awslabs/aws-cloudformation-controller-for-flux/api/v1alpha1"
+	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/clients"
+	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/clients/cloudformation"
+	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/clients/types"
+)
+
+const (
+	defaultExportNamespace = "flux-system"
+	noEchoParameterValue   = "****"
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export existing CloudFormation stacks as CloudFormationStack manifests",
+	Long: `The export command lists the CloudFormation stacks in an AWS account and region and writes the template
+of each stack, along with a CloudFormationStack manifest with the stack's current parameters and tags,
+into a directory that can be committed to the stacks' source repository.
+
+The manifests are generated in 'Plan' mode, so that the controller takes ownership of the existing stacks
+without deploying them: review each stack's plan in 'status.lastPlan', then approve it with 'cfnstack approve'.
+Nested stacks, stacks that are already managed by the controller and stacks in an unrecoverable state are skipped.`,
+	Example: `  # Export all the stacks in us-west-2 into the ./stacks directory
+  cfnstack export --region us-west-2 --output-dir ./stacks
+
+  # Export the stacks whose names start with 'prod-' and that are tagged with team=payments
+  cfnstack export --region us-west-2 --output-dir ./stacks --prefix prod- --tag team=payments
+
+  # Reference the templates from a GitRepository with the exported directory at clusters/prod/stacks
+  cfnstack export --output-dir ./stacks --source-name my-cfn-templates-repo --source-path clusters/prod/stacks`,
+	Args: cobra.NoArgs,
+	RunE: exportCmdRun,
+}
+
+type exportFlags struct {
+	region          string
+	outputDir       string
+	prefix          string
+	tags            map[string]string
+	mode            string
+	sourceKind      string
+	sourceName      string
+	sourceNamespace string
+	sourcePath      string
+	interval        time.Duration
+	namespace       string
+}
+
+var exportArgs exportFlags
+
+func init() {
+	exportCmd.Flags().StringVar(&exportArgs.region, "region", "", "AWS region of the stacks (defaults to the region of the AWS configuration)")
+	exportCmd.Flags().StringVar(&exportArgs.outputDir, "output-dir", "", "directory that the templates and manifests are written to")
+	exportCmd.Flags().StringVar(&exportArgs.prefix, "prefix", "", "only export the stacks whose names start with this prefix")
+	exportCmd.Flags().StringToStringVar(&exportArgs.tags, "tag", nil, "only export the stacks with all of these tags")
+	exportCmd.Flags().StringVar(&exportArgs.mode, "mode", cfnv1.PlanMode, "mode of the generated CloudFormationStacks, one of Plan, Observe or Deploy")
+	exportCmd.Flags().StringVar(&exportArgs.sourceKind, "source-kind", sourcev1.GitRepositoryKind, "kind of the source that the templates are committed to")
+	exportCmd.Flags().StringVar(&exportArgs.sourceName, "source-name", "flux-system", "name of the source that the templates are committed to")
+	exportCmd.Flags().StringVar(&exportArgs.sourceNamespace, "source-namespace", "", "namespace of the source (defaults to the namespace of the CloudFormationStacks)")
+	exportCmd.Flags().StringVar(&exportArgs.sourcePath, "source-path", "", "path of the output directory in the source (defaults to --output-dir)")
+	exportCmd.Flags().DurationVar(&exportArgs.interval, "interval", time.Hour, "reconciliation interval of the generated CloudFormationStacks")
+	_ = exportCmd.MarkFlagRequired("output-dir")
+	rootCmd.AddCommand(exportCmd)
+}
+
+func exportCmdRun(cmd *cobra.Command, args []string) error {
+	switch exportArgs.mode {
+	case cfnv1.PlanMode, cfnv1.ObserveMode, cfnv1.DeployMode:
+	default:
+		return fmt.Errorf("unsupported mode '%s', must be one of Plan, Observe or Deploy", exportArgs.mode)
+	}
+
+	exportArgs.namespace = defaultExportNamespace
+	if kubeconfig.Namespace != nil && *kubeconfig.Namespace != "" {
+		exportArgs.namespace = *kubeconfig.Namespace
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), rootArgs.timeout)
+	defer cancel()
+
+	cfnClient, err := cloudformation.NewExportClient(ctx, exportArgs.region)
+	if err != nil {
+		return fmt.Errorf("unable to create the CloudFormation client: %w", err)
+	}
+	return exportStacks(ctx, cmd.OutOrStdout(), cfnClient, exportArgs)
+}
+
+// exportStacks writes the template and a CloudFormationStack manifest of each of the selected stacks into the output directory.
+func exportStacks(ctx context.Context, out io.Writer, cfnClient clients.CloudFormationExportClient, args exportFlags) error {
+	stacks, err := cfnClient.ListStacks(ctx)
+	if err != nil {
+		return err
+	}
+	sort.Slice(stacks, func(i, j int) bool {
+		return aws.ToString(stacks[i].StackName) < aws.ToString(stacks[j].StackName)
+	})
+
+	if err := os.MkdirAll(args.outputDir, 0o755); err != nil {
+		return fmt.Errorf("unable to create output directory: %w", err)
+	}
+	sourcePath := args.sourcePath
+	if sourcePath == "" {
+		sourcePath = filepath.ToSlash(filepath.Clean(args.outputDir))
+	}
+
+	exported := map[string]string{}
+	for _, stack := range stacks {
+		stackName := aws.ToString(stack.StackName)
+		if !exportStackSelected(stack, args) {
+			continue
+		}
+		switch {
+		case stack.ParentId != nil:
+			logWarning(out, "skipping nested stack %s, export its root stack instead", stackName)
+			continue
+		case stackTagValue(stack, "cfn-flux-controller/name") != "":
+			logWarning(out, "skipping stack %s, already managed by CloudFormationStack %s/%s",
+				stackName, stackTagValue(stack, "cfn-flux-controller/namespace"), stackTagValue(stack, "cfn-flux-controller/name"))
+			continue
+		case stack.RequiresCleanup():
+			logWarning(out, "skipping stack %s in status %s, the controller would delete and recreate it", stackName, stack.StackStatus)
+			continue
+		}
+
+		name := exportObjectName(stackName)
+		if other, found := exported[name]; found {
+			return fmt.Errorf("stacks %s and %s would both be exported as CloudFormationStack %s", other, stackName, name)
+		}
+		exported[name] = stackName
+
+		logAction(out, "exporting stack %s", stackName)
+		body, err := cfnClient.GetTemplate(ctx, &types.Stack{Name: stackName})
+		if err != nil {
+			return err
+		}
+		templateFile := name + ".template.yaml"
+		if strings.HasPrefix(strings.TrimSpace(body), "{") {
+			templateFile = name + ".template.json"
+		}
+		if err := os.WriteFile(filepath.Join(args.outputDir, templateFile), []byte(body), 0o644); err != nil {
+			return fmt.Errorf("unable to write template of stack %s: %w", stackName, err)
+		}
+
+		cfnStack := exportCfnStack(out, stack, name, path.Join(sourcePath, templateFile), args)
+		manifest, err := marshalCfnStack(cfnStack)
+		if err != nil {
+			return fmt.Errorf("unable to generate manifest of stack %s: %w", stackName, err)
+		}
+		manifestFile := filepath.Join(args.outputDir, name+".yaml")
+		if err := os.WriteFile(manifestFile, manifest, 0o644); err != nil {
+			return fmt.Errorf("unable to write manifest of stack %s: %w", stackName, err)
+		}
+		logSuccess(out, "exported stack %s to %s", stackName, manifestFile)
+	}
+
+	if len(exported) == 0 {
+		logWarning(out, "no stacks exported")
+	}
+	return nil
+}
+
+// exportStackSelected returns true if the stack matches the name prefix and all the tags of the export.
+func exportStackSelected(stack *types.StackDescription, args exportFlags) bool {
+	if !strings.HasPrefix(aws.ToString(stack.StackName), args.prefix) {
+		return false
+	}
+	for key, value := range args.tags {
+		found := false
+		for _, tag := range stack.Tags {
+			if aws.ToString(tag.Key) == key && aws.ToString(tag.Value) == value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func stackTagValue(stack *types.StackDescription, key string) string {
+	for _, tag := range stack.Tags {
+		if aws.ToString(tag.Key) == key {
+			return aws.ToString(tag.Value)
+		}
+	}
+	return ""
+}
+
+var invalidObjectNameChars = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// exportObjectName returns a valid Kubernetes object name for the stack name.
+// Example: MyApp_Prod -> myapp-prod
+func exportObjectName(stackName string) string {
+	name := invalidObjectNameChars.ReplaceAllString(strings.ToLower(stackName), "-")
+	return strings.Trim(name, "-")
+}
+
+// exportCfnStack returns the CloudFormationStack that reconciles the existing stack with its current template, parameters and tags.
+func exportCfnStack(out io.Writer, stack *types.StackDescription, name string, templatePath string, args exportFlags) *cfnv1.CloudFormationStack {
+	cfnStack := &cfnv1.CloudFormationStack{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: cfnv1.GroupVersion.String(),
+			Kind:       cfnv1.CloudFormationStackKind,
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: args.namespace,
+		},
+		Spec: cfnv1.CloudFormationStackSpec{
+			StackName:    aws.ToString(stack.StackName),
+			Mode:         args.mode,
+			TemplatePath: templatePath,
+			SourceRef: &cfnv1.SourceReference{
+				Kind:      args.sourceKind,
+				Name:      args.sourceName,
+				Namespace: args.sourceNamespace,
+			},
+			Interval: metav1.Duration{Duration: args.interval},
+		},
+	}
+
+	for _, param := range stack.Parameters {
+		key := aws.ToString(param.ParameterKey)
+		value := aws.ToString(param.ParameterValue)
+		if value == noEchoParameterValue {
+			logWarning(out, "skipping NoEcho parameter %s of stack %s, set its value in the manifest", key, aws.ToString(stack.StackName))
+			continue
+		}
+		cfnStack.Spec.StackParameters = append(cfnStack.Spec.StackParameters, cfnv1.StackParameter{Key: key, Value: value})
+	}
+
+	for _, tag := range stack.Tags {
+		key := aws.ToString(tag.Key)
+		// AWS tags are managed by AWS, and the controller's tags are added by the controller
+		if strings.HasPrefix(key, "aws:") || strings.HasPrefix(key, "cfn-flux-controller/") {
+			continue
+		}
+		cfnStack.Spec.StackTags = append(cfnStack.Spec.StackTags, cfnv1.StackTag{Key: key, Value: aws.ToString(tag.Value)})
+	}
+	return cfnStack
+}
+
+// marshalCfnStack returns the YAML manifest of the CloudFormationStack, without its status, server-side metadata
+// and unset poll interval, so that the API server applies the defaults.
+func marshalCfnStack(cfnStack *cfnv1.CloudFormationStack) ([]byte, error) {
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(cfnStack)
+	if err != nil {
+		return nil, err
+	}
+	delete(obj, "status")
+	if metadata, ok := obj["metadata"].(map[string]interface{}); ok {
+		delete(metadata, "creationTimestamp")
+	}
+	if spec, ok := obj["spec"].(map[string]interface{}); ok && cfnStack.Spec.PollInterval.Duration == 0 {
+		delete(spec, "pollInterval")
+	}
+	return yaml.Marshal(obj)
+}
@@ -0,0 +1,141 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT-0
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	sdktypes "github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	cfnv1 "github.com/awslabs/aws-cloudformation-controller-for-flux/api/v1alpha1"
+	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/clients/mocks"
+	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/clients/types"
+)
+
+func TestExportStacks(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfnClient := mocks.NewMockCloudFormationExportClient(ctrl)
+	cfnClient.EXPECT().ListStacks(gomock.Any()).Return([]*types.StackDescription{
+		{
+			StackName:   aws.String("prod_Network"),
+			StackStatus: sdktypes.StackStatusUpdateComplete,
+			Parameters: []sdktypes.Parameter{
+				{ParameterKey: aws.String("Cidr"), ParameterValue: aws.String("10.0.0.0/16")},
+				{ParameterKey: aws.String("Password"), ParameterValue: aws.String("****")},
+			},
+			Tags: []sdktypes.Tag{
+				{Key: aws.String("team"), Value: aws.String("payments")},
+				{Key: aws.String("aws:cloudformation:stack-name"), Value: aws.String("prod_Network")},
+			},
+		},
+		{
+			StackName:   aws.String("prod_Network-Subnets-1234"),
+			StackStatus: sdktypes.StackStatusCreateComplete,
+			ParentId:    aws.String("arn:aws:cloudformation:us-west-2:123456789012:stack/prod_Network/uuid"),
+			Tags:        []sdktypes.Tag{{Key: aws.String("team"), Value: aws.String("payments")}},
+		},
+		{
+			StackName:   aws.String("prod-managed"),
+			StackStatus: sdktypes.StackStatusCreateComplete,
+			Tags: []sdktypes.Tag{
+				{Key: aws.String("team"), Value: aws.String("payments")},
+				{Key: aws.String("cfn-flux-controller/name"), Value: aws.String(mockStackName)},
+				{Key: aws.String("cfn-flux-controller/namespace"), Value: aws.String(mockNamespace)},
+			},
+		},
+		{
+			StackName:   aws.String("prod-failed"),
+			StackStatus: sdktypes.StackStatusRollbackComplete,
+			Tags:        []sdktypes.Tag{{Key: aws.String("team"), Value: aws.String("payments")}},
+		},
+		{
+			StackName:   aws.String("prod-queue"),
+			StackStatus: sdktypes.StackStatusCreateComplete,
+			Tags:        []sdktypes.Tag{{Key: aws.String("team"), Value: aws.String("payments")}},
+		},
+		{
+			StackName:   aws.String("prod-other-team"),
+			StackStatus: sdktypes.StackStatusCreateComplete,
+			Tags:        []sdktypes.Tag{{Key: aws.String("team"), Value: aws.String("orders")}},
+		},
+		{
+			StackName:   aws.String("dev-queue"),
+			StackStatus: sdktypes.StackStatusCreateComplete,
+			Tags:        []sdktypes.Tag{{Key: aws.String("team"), Value: aws.String("payments")}},
+		},
+	}, nil)
+	cfnClient.EXPECT().GetTemplate(gomock.Any(), &types.Stack{Name: "prod_Network"}).Return("Resources:\n  Vpc:\n    Type: AWS::EC2::VPC\n", nil)
+	cfnClient.EXPECT().GetTemplate(gomock.Any(), &types.Stack{Name: "prod-queue"}).Return(`{"Resources": {"Queue": {"Type": "AWS::SQS::Queue"}}}`, nil)
+
+	outputDir := filepath.Join(t.TempDir(), "stacks")
+	out := &bytes.Buffer{}
+	err := exportStacks(context.Background(), out, cfnClient, exportFlags{
+		outputDir:  outputDir,
+		prefix:     "prod",
+		tags:       map[string]string{"team": "payments"},
+		mode:       cfnv1.PlanMode,
+		sourceKind: "GitRepository",
+		sourceName: mockGitRepoName,
+		sourcePath: "clusters/prod/stacks",
+		interval:   time.Hour,
+		namespace:  mockNamespace,
+	})
+	require.NoError(t, err)
+	require.Contains(t, out.String(), "skipping NoEcho parameter Password of stack prod_Network")
+	require.Contains(t, out.String(), "skipping nested stack prod_Network-Subnets-1234")
+	require.Contains(t, out.String(), "skipping stack prod-managed, already managed by CloudFormationStack mock-namespace/mock-stack")
+	require.Contains(t, out.String(), "skipping stack prod-failed in status ROLLBACK_COMPLETE")
+
+	entries, err := os.ReadDir(outputDir)
+	require.NoError(t, err)
+	var files []string
+	for _, entry := range entries {
+		files = append(files, entry.Name())
+	}
+	require.Equal(t, []string{"prod-network.template.yaml", "prod-network.yaml", "prod-queue.template.json", "prod-queue.yaml"}, files)
+
+	manifest, err := os.ReadFile(filepath.Join(outputDir, "prod-network.yaml"))
+	require.NoError(t, err)
+	require.Equal(t, `apiVersion: cloudformation.contrib.fluxcd.io/v1alpha1
+kind: CloudFormationStack
+metadata:
+  name: prod-network
+  namespace: mock-namespace
+spec:
+  interval: 1h0m0s
+  mode: Plan
+  sourceRef:
+    kind: GitRepository
+    name: mock-repo
+  stackName: prod_Network
+  stackParameters:
+  - key: Cidr
+    value: 10.0.0.0/16
+  stackTags:
+  - key: team
+    value: payments
+  templatePath: clusters/prod/stacks/prod-network.template.yaml
+`, string(manifest))
+
+	// The exported manifest can be read back, like by the build command
+	cfnStack, err := readStackManifest(filepath.Join(outputDir, "prod-queue.yaml"), "")
+	require.NoError(t, err)
+	require.Equal(t, "prod-queue", cfnStack.Spec.StackName)
+	require.Equal(t, "clusters/prod/stacks/prod-queue.template.json", cfnStack.Spec.TemplatePath)
+}
+
+func TestExportObjectName(t *testing.T) {
+	require.Equal(t, "myapp-prod", exportObjectName("MyApp_Prod"))
+	require.Equal(t, "my-stack-1", exportObjectName("my-stack-1"))
+}
@@ -0,0 +1,132 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT-0
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/fluxcd/pkg/apis/meta"
+	"github.com/spf13/cobra"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	cfnv1 "github.com/awslabs/aws-cloudformation-controller-for-flux/api/v1alpha1"
+	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/clients/cloudformation"
+)
+
+var getCmd = &cobra.Command{
+	Use:   "get [NAME]",
+	Short: "Display the status of CloudFormationStacks",
+	Long: `The get command displays the readiness, CloudFormation stack status and
+last attempted change set of CloudFormationStacks.`,
+	Example: `  # List the stacks in the current namespace
+  cfnstack get
+
+  # List the stacks in all namespaces
+  cfnstack get -A
+
+  # Display a single stack
+  cfnstack get my-stack`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: getCmdRun,
+}
+
+type getFlags struct {
+	allNamespaces bool
+}
+
+var getArgs getFlags
+
+func init() {
+	getCmd.Flags().BoolVarP(&getArgs.allNamespaces, "all-namespaces", "A", false, "list the stacks in all namespaces")
+	rootCmd.AddCommand(getCmd)
+}
+
+func getCmdRun(cmd *cobra.Command, args []string) error {
+	kubeClient, namespace, err := newKubeClient()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(cmd.Context(), rootArgs.timeout)
+	defer cancel()
+
+	var stacks []cfnv1.CloudFormationStack
+	if len(args) == 1 {
+		stacks, err = getStack(ctx, kubeClient, types.NamespacedName{Namespace: namespace, Name: args[0]})
+	} else {
+		if getArgs.allNamespaces {
+			namespace = ""
+		}
+		stacks, err = listStacks(ctx, kubeClient, namespace)
+	}
+	if err != nil {
+		return err
+	}
+	if len(stacks) == 0 {
+		fmt.Fprintln(cmd.ErrOrStderr(), "no CloudFormationStack objects found")
+		return nil
+	}
+	return printStacks(cmd.OutOrStdout(), stacks, getArgs.allNamespaces)
+}
+
+func getStack(ctx context.Context, kubeClient client.Client, key types.NamespacedName) ([]cfnv1.CloudFormationStack, error) {
+	cfnStack := cfnv1.CloudFormationStack{}
+	if err := kubeClient.Get(ctx, key, &cfnStack); err != nil {
+		return nil, fmt.Errorf("unable to get CloudFormationStack '%s': %w", key, err)
+	}
+	return []cfnv1.CloudFormationStack{cfnStack}, nil
+}
+
+func listStacks(ctx context.Context, kubeClient client.Client, namespace string) ([]cfnv1.CloudFormationStack, error) {
+	stackList := &cfnv1.CloudFormationStackList{}
+	if err := kubeClient.List(ctx, stackList, client.InNamespace(namespace)); err != nil {
+		return nil, fmt.Errorf("unable to list CloudFormationStacks: %w", err)
+	}
+	return stackList.Items, nil
+}
+
+// printStacks writes a table of the stacks' status.
+func printStacks(out io.Writer, stacks []cfnv1.CloudFormationStack, withNamespace bool) error {
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	header := "NAME\tMODE\tREADY\tSUSPENDED\tSTACK\tSTACK STATUS\tCHANGE SET\tMESSAGE"
+	if withNamespace {
+		header = "NAMESPACE\t" + header
+	}
+	fmt.Fprintln(w, header)
+
+	for _, cfnStack := range stacks {
+		ready, message := "Unknown", ""
+		if condition := apimeta.FindStatusCondition(cfnStack.Status.Conditions, meta.ReadyCondition); condition != nil {
+			ready, message = string(condition.Status), condition.Message
+		}
+		mode := cfnStack.Spec.Mode
+		if mode == "" {
+			mode = cfnv1.DeployMode
+		}
+		stackName := cfnStack.Status.StackName
+		if stackName == "" {
+			stackName = cfnStack.Spec.StackName
+		}
+		row := fmt.Sprintf("%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s",
+			cfnStack.Name,
+			mode,
+			ready,
+			strconv.FormatBool(cfnStack.Spec.Suspend),
+			stackName,
+			cfnStack.Status.StackStatus,
+			cloudformation.ExtractChangeSetName(cfnStack.Status.LastAttemptedChangeSet),
+			message,
+		)
+		if withNamespace {
+			row = cfnStack.Namespace + "\t" + row
+		}
+		fmt.Fprintln(w, row)
+	}
+	return w.Flush()
+}
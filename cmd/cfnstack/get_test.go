@@ -0,0 +1,52 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT-0
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/fluxcd/pkg/apis/meta"
+	"github.com/stretchr/testify/require"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cfnv1 "github.com/awslabs/aws-cloudformation-controller-for-flux/api/v1alpha1"
+)
+
+func TestPrintStacks(t *testing.T) {
+	readyStack := generateMockCfnStack()
+	readyStack.Status.StackName = mockRealStackName
+	readyStack.Status.StackStatus = "UPDATE_COMPLETE"
+	readyStack.Status.LastAttemptedChangeSet = mockChangeSetArn
+	apimeta.SetStatusCondition(&readyStack.Status.Conditions, metav1.Condition{
+		Type:    meta.ReadyCondition,
+		Status:  metav1.ConditionTrue,
+		Reason:  meta.SucceededReason,
+		Message: "Stack reconciliation succeeded",
+	})
+
+	newStack := generateMockCfnStack()
+	newStack.Name = "new-stack"
+	newStack.Namespace = "other-namespace"
+	newStack.Spec.StackName = "new-real-stack"
+	newStack.Spec.Mode = cfnv1.PlanMode
+	newStack.Spec.Suspend = true
+
+	kubeClient := newFakeClient(t, metav1.ConditionTrue, readyStack, newStack)
+	stacks, err := listStacks(context.Background(), kubeClient, "")
+	require.NoError(t, err)
+
+	out := &bytes.Buffer{}
+	require.NoError(t, printStacks(out, stacks, true))
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 3)
+	require.Equal(t, []string{"NAMESPACE", "NAME", "MODE", "READY", "SUSPENDED", "STACK", "STACK", "STATUS", "CHANGE", "SET", "MESSAGE"}, strings.Fields(lines[0]))
+	require.Equal(t, []string{"mock-namespace", "mock-stack", "Deploy", "True", "false", "mock-real-stack", "UPDATE_COMPLETE",
+		"flux-1-main-sha1-132f4e719209eb10b9485302f8593fc0e680f4fc", "Stack", "reconciliation", "succeeded"}, strings.Fields(lines[1]))
+	require.Equal(t, []string{"other-namespace", "new-stack", "Plan", "Unknown", "true", "new-real-stack"}, strings.Fields(lines[2]))
+}
@@ -0,0 +1,118 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT-0
+
+// cfnstack is a command line tool for operating CloudFormationStack objects.
+// It can be used standalone, or as a kubectl or flux plugin by installing it
+// on the PATH as 'kubectl-cfnstack' or 'flux-cfnstack'.
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	sourcev1 "github.com/fluxcd/source-controller/api/v1"
+	sourcev1b2 "github.com/fluxcd/source-controller/api/v1beta2"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	cfnv1 "github.com/awslabs/aws-cloudformation-controller-for-flux/api/v1alpha1"
+)
+
+var scheme = runtime.NewScheme()
+
+func init() {
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(sourcev1.AddToScheme(scheme))
+	utilruntime.Must(sourcev1b2.AddToScheme(scheme))
+	utilruntime.Must(cfnv1.AddToScheme(scheme))
+}
+
+type rootFlags struct {
+	timeout time.Duration
+}
+
+var (
+	rootArgs     rootFlags
+	kubeconfig   = genericclioptions.NewConfigFlags(true)
+	pollInterval = 2 * time.Second
+)
+
+var rootCmd = &cobra.Command{
+	Use:           "cfnstack",
+	Short:         "Operate the CloudFormationStack objects of the AWS CloudFormation Template Sync Controller for Flux",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+}
+
+func init() {
+	kubeconfig.AddFlags(rootCmd.PersistentFlags())
+	rootCmd.PersistentFlags().DurationVar(&rootArgs.timeout, "timeout", 5*time.Minute, "timeout for this operation")
+}
+
+func main() {
+	if name := pluginName(os.Args[0]); name != "" {
+		rootCmd.Annotations = map[string]string{cobra.CommandDisplayNameAnnotation: name}
+	}
+	if err := rootCmd.Execute(); err != nil {
+		logError(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// pluginName returns the name the command is invoked as when the binary is installed as a kubectl or flux plugin.
+// Example: /usr/local/bin/kubectl-cfnstack -> kubectl cfnstack
+func pluginName(arg0 string) string {
+	base := filepath.Base(arg0)
+	for _, host := range []string{"kubectl", "flux"} {
+		if name, found := strings.CutPrefix(base, host+"-"); found && name != "" {
+			return host + " " + name
+		}
+	}
+	return ""
+}
+
+// newKubeClient returns a client for the cluster selected by the command line flags,
+// and the namespace selected by the command line flags or the current kubeconfig context.
+func newKubeClient() (client.Client, string, error) {
+	cfg, err := kubeconfig.ToRESTConfig()
+	if err != nil {
+		return nil, "", fmt.Errorf("unable to load kubeconfig: %w", err)
+	}
+	namespace, _, err := kubeconfig.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return nil, "", fmt.Errorf("unable to determine the namespace: %w", err)
+	}
+	kubeClient, err := client.New(cfg, client.Options{Scheme: scheme})
+	if err != nil {
+		return nil, "", fmt.Errorf("unable to create the Kubernetes client: %w", err)
+	}
+	return kubeClient, namespace, nil
+}
+
+func logAction(out io.Writer, format string, a ...interface{}) {
+	fmt.Fprintf(out, "► "+format+"\n", a...)
+}
+
+func logWaiting(out io.Writer, format string, a ...interface{}) {
+	fmt.Fprintf(out, "◎ "+format+"\n", a...)
+}
+
+func logSuccess(out io.Writer, format string, a ...interface{}) {
+	fmt.Fprintf(out, "✔ "+format+"\n", a...)
+}
+
+func logWarning(out io.Writer, format string, a ...interface{}) {
+	fmt.Fprintf(out, "⚠ "+format+"\n", a...)
+}
+
+func logError(out io.Writer, err error) {
+	fmt.Fprintf(out, "✗ %s\n", err)
+}
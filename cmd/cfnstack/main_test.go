@@ -0,0 +1,122 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT-0
+
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fluxcd/pkg/apis/meta"
+	sourcev1 "github.com/fluxcd/source-controller/api/v1"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	cfnv1 "github.com/awslabs/aws-cloudformation-controller-for-flux/api/v1alpha1"
+)
+
+const (
+	mockStackName      = "mock-stack"
+	mockRealStackName  = "mock-real-stack"
+	mockNamespace      = "mock-namespace"
+	mockGitRepoName    = "mock-repo"
+	mockSourceRevision = "main@sha1:132f4e719209eb10b9485302f8593fc0e680f4fc"
+	mockChangeSetArn   = "arn:aws:cloudformation:us-west-2:123456789012:changeSet/flux-1-main-sha1-132f4e719209eb10b9485302f8593fc0e680f4fc/uuid"
+)
+
+func init() {
+	pollInterval = 10 * time.Millisecond
+}
+
+func generateMockCfnStack() *cfnv1.CloudFormationStack {
+	return &cfnv1.CloudFormationStack{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       mockStackName,
+			Namespace:  mockNamespace,
+			Generation: 1,
+		},
+		Spec: cfnv1.CloudFormationStackSpec{
+			StackName: mockRealStackName,
+			SourceRef: &cfnv1.SourceReference{
+				Kind: sourcev1.GitRepositoryKind,
+				Name: mockGitRepoName,
+			},
+		},
+	}
+}
+
+func generateMockGitRepo(revision string) *sourcev1.GitRepository {
+	return &sourcev1.GitRepository{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       mockGitRepoName,
+			Namespace:  mockNamespace,
+			Generation: 1,
+		},
+		Status: sourcev1.GitRepositoryStatus{
+			Artifact: &sourcev1.Artifact{Revision: revision},
+		},
+	}
+}
+
+// newFakeClient returns a fake client that acts like the controllers: when the reconcile request annotation
+// of an object is set, the object's status records the handled request and the given readiness.
+func newFakeClient(t *testing.T, ready metav1.ConditionStatus, objs ...client.Object) client.Client {
+	return fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(objs...).
+		WithStatusSubresource(&cfnv1.CloudFormationStack{}, &sourcev1.GitRepository{}).
+		WithIndex(&corev1.Event{}, "involvedObject.name", func(obj client.Object) []string {
+			return []string{obj.(*corev1.Event).InvolvedObject.Name}
+		}).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+				if err := c.Patch(ctx, obj, patch, opts...); err != nil {
+					return err
+				}
+				requestedAt, ok := obj.GetAnnotations()[meta.ReconcileRequestAnnotation]
+				if !ok {
+					return nil
+				}
+				condition := metav1.Condition{
+					Type:               meta.ReadyCondition,
+					Status:             ready,
+					Reason:             meta.SucceededReason,
+					Message:            "mock message",
+					ObservedGeneration: obj.GetGeneration(),
+				}
+				switch o := obj.(type) {
+				case *cfnv1.CloudFormationStack:
+					o.Status.LastHandledReconcileAt = requestedAt
+					o.Status.LastAppliedRevision = mockSourceRevision
+					apimeta.SetStatusCondition(&o.Status.Conditions, condition)
+				case *sourcev1.GitRepository:
+					o.Status.LastHandledReconcileAt = requestedAt
+					apimeta.SetStatusCondition(&o.Status.Conditions, condition)
+				}
+				require.NoError(t, c.Status().Update(ctx, obj))
+				return nil
+			},
+		}).
+		Build()
+}
+
+func TestPluginName(t *testing.T) {
+	testCases := map[string]string{
+		"cfnstack":                        "",
+		"/usr/local/bin/cfnstack":         "",
+		"/usr/local/bin/kubectl-cfnstack": "kubectl cfnstack",
+		"flux-cfnstack":                   "flux cfnstack",
+		"kubectl-":                        "",
+	}
+	for arg0, wanted := range testCases {
+		t.Run(arg0, func(t *testing.T) {
+			require.Equal(t, wanted, pluginName(arg0))
+		})
+	}
+}
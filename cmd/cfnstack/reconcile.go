@@ -0,0 +1,240 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT-0
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/fluxcd/pkg/apis/meta"
+	sourcev1 "github.com/fluxcd/source-controller/api/v1"
+	sourcev1b2 "github.com/fluxcd/source-controller/api/v1beta2"
+	"github.com/spf13/cobra"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	cfnv1 "github.com/awslabs/aws-cloudformation-controller-for-flux/api/v1alpha1"
+)
+
+var reconcileCmd = &cobra.Command{
+	Use:   "reconcile NAME",
+	Short: "Trigger a reconciliation of a CloudFormationStack and wait for it to complete",
+	Long: `The reconcile command sets the 'reconcile.fluxcd.io/requestedAt' annotation on a CloudFormationStack,
+then waits until the controller has handled the request and the stack is ready or failed.`,
+	Example: `  # Reconcile the stack with the current source revision
+  cfnstack reconcile my-stack
+
+  # Fetch the latest revision of the stack's source, then reconcile the stack
+  cfnstack reconcile my-stack --with-source`,
+	Args: cobra.ExactArgs(1),
+	RunE: reconcileCmdRun,
+}
+
+type reconcileFlags struct {
+	withSource bool
+}
+
+var reconcileArgs reconcileFlags
+
+func init() {
+	reconcileCmd.Flags().BoolVar(&reconcileArgs.withSource, "with-source", false, "reconcile the stack's source before reconciling the stack")
+	rootCmd.AddCommand(reconcileCmd)
+}
+
+func reconcileCmdRun(cmd *cobra.Command, args []string) error {
+	kubeClient, namespace, err := newKubeClient()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(cmd.Context(), rootArgs.timeout)
+	defer cancel()
+
+	key := types.NamespacedName{Namespace: namespace, Name: args[0]}
+	return reconcileStack(ctx, cmd.OutOrStdout(), kubeClient, key, reconcileArgs.withSource)
+}
+
+// reconcileStack requests a reconciliation of the stack, optionally after reconciling its source,
+// and waits for the controller to complete the reconciliation.
+func reconcileStack(ctx context.Context, out io.Writer, kubeClient client.Client, key types.NamespacedName, withSource bool) error {
+	cfnStack := &cfnv1.CloudFormationStack{}
+	if err := kubeClient.Get(ctx, key, cfnStack); err != nil {
+		return fmt.Errorf("unable to get CloudFormationStack '%s': %w", key, err)
+	}
+	if cfnStack.Spec.Suspend {
+		return fmt.Errorf("CloudFormationStack '%s' is suspended, run 'resume' instead", key)
+	}
+
+	if withSource {
+		if err := reconcileSource(ctx, out, kubeClient, cfnStack); err != nil {
+			return err
+		}
+	}
+
+	logAction(out, "annotating CloudFormationStack %s in %s namespace", key.Name, key.Namespace)
+	requestedAt, err := requestReconcile(ctx, kubeClient, cfnStack)
+	if err != nil {
+		return fmt.Errorf("unable to annotate CloudFormationStack '%s': %w", key, err)
+	}
+	logSuccess(out, "CloudFormationStack annotated")
+
+	logWaiting(out, "waiting for CloudFormationStack reconciliation")
+	if err := waitForReconcile(ctx, kubeClient, cfnStack, requestedAt); err != nil {
+		return fmt.Errorf("CloudFormationStack '%s' reconciliation failed: %w", key, err)
+	}
+	logSuccess(out, "CloudFormationStack reconciliation completed")
+
+	switch {
+	case cfnStack.Spec.Mode == cfnv1.PlanMode && cfnStack.Status.LastPlan != nil:
+		logSuccess(out, "planned revision %s: %s", cfnStack.Status.LastPlan.Revision, cfnStack.Status.LastPlan.Summary())
+	case cfnStack.Spec.Mode == cfnv1.ObserveMode:
+		logSuccess(out, "observed stack %s with status %s", cfnStack.Status.StackName, cfnStack.Status.StackStatus)
+	default:
+		logSuccess(out, "applied revision %s", cfnStack.Status.LastAppliedRevision)
+	}
+	return nil
+}
+
+// reconcileSource requests a reconciliation of the stack's source and waits for the
+// source controller to fetch the latest revision.
+func reconcileSource(ctx context.Context, out io.Writer, kubeClient client.Client, cfnStack *cfnv1.CloudFormationStack) error {
+	source, key, err := getStackSource(ctx, kubeClient, cfnStack)
+	if err != nil {
+		return err
+	}
+	kind := cfnStack.Spec.SourceRef.Kind
+
+	logAction(out, "annotating %s %s in %s namespace", kind, key.Name, key.Namespace)
+	requestedAt, err := requestReconcile(ctx, kubeClient, source)
+	if err != nil {
+		return fmt.Errorf("unable to annotate %s '%s': %w", kind, key, err)
+	}
+	logSuccess(out, "%s annotated", kind)
+
+	logWaiting(out, "waiting for %s reconciliation", kind)
+	if err := waitForReconcile(ctx, kubeClient, source, requestedAt); err != nil {
+		return fmt.Errorf("%s '%s' reconciliation failed: %w", kind, key, err)
+	}
+	if artifact := source.GetArtifact(); artifact != nil {
+		logSuccess(out, "fetched revision %s", artifact.Revision)
+	}
+	return nil
+}
+
+// getStackSource returns the source object referenced by the stack, and its namespaced name.
+func getStackSource(ctx context.Context, kubeClient client.Client, cfnStack *cfnv1.CloudFormationStack) (sourceObject, types.NamespacedName, error) {
+	sourceRef := cfnStack.Spec.SourceRef
+	if sourceRef == nil {
+		return nil, types.NamespacedName{}, fmt.Errorf("CloudFormationStack '%s/%s' does not have a source reference", cfnStack.Namespace, cfnStack.Name)
+	}
+	key := types.NamespacedName{Namespace: cfnStack.Namespace, Name: sourceRef.Name}
+	if sourceRef.Namespace != "" {
+		key.Namespace = sourceRef.Namespace
+	}
+
+	source, err := newSourceObject(sourceRef.Kind)
+	if err != nil {
+		return nil, key, err
+	}
+	if err := kubeClient.Get(ctx, key, source); err != nil {
+		return nil, key, fmt.Errorf("unable to get %s '%s': %w", sourceRef.Kind, key, err)
+	}
+	return source, key, nil
+}
+
+// sourceObject is a Flux source object that can be fetched with the Kubernetes client.
+type sourceObject interface {
+	client.Object
+	sourcev1.Source
+}
+
+// newSourceObject returns an empty source object of the given kind.
+func newSourceObject(kind string) (sourceObject, error) {
+	switch kind {
+	case sourcev1.GitRepositoryKind:
+		return &sourcev1.GitRepository{}, nil
+	case sourcev1b2.BucketKind:
+		return &sourcev1b2.Bucket{}, nil
+	case sourcev1b2.OCIRepositoryKind:
+		return &sourcev1b2.OCIRepository{}, nil
+	default:
+		return nil, fmt.Errorf("source kind '%s' not supported", kind)
+	}
+}
+
+// requestReconcile sets the reconcile request annotation on the object to the current time.
+// Returns the value of the annotation.
+func requestReconcile(ctx context.Context, kubeClient client.Client, obj client.Object) (string, error) {
+	patch := client.MergeFrom(obj.DeepCopyObject().(client.Object))
+	requestedAt := time.Now().Format(time.RFC3339Nano)
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[meta.ReconcileRequestAnnotation] = requestedAt
+	obj.SetAnnotations(annotations)
+	if err := kubeClient.Patch(ctx, obj, patch); err != nil {
+		return "", err
+	}
+	return requestedAt, nil
+}
+
+// reconcileStatus holds the status fields that Flux objects share.
+type reconcileStatus struct {
+	meta.ReconcileRequestStatus `json:",inline"`
+	Conditions                  []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// waitForReconcile polls the object until its controller has handled the given reconcile request
+// and the object is ready or failed. The object is updated with the latest state from the cluster.
+func waitForReconcile(ctx context.Context, kubeClient client.Client, obj client.Object, requestedAt string) error {
+	key := client.ObjectKeyFromObject(obj)
+	var ready *metav1.Condition
+	err := wait.PollUntilContextCancel(ctx, pollInterval, true, func(ctx context.Context) (bool, error) {
+		if err := kubeClient.Get(ctx, key, obj); err != nil {
+			return false, err
+		}
+		status, err := getReconcileStatus(obj)
+		if err != nil {
+			return false, err
+		}
+		if status.LastHandledReconcileAt != requestedAt {
+			return false, nil
+		}
+		ready = apimeta.FindStatusCondition(status.Conditions, meta.ReadyCondition)
+		if ready == nil || ready.ObservedGeneration != obj.GetGeneration() {
+			return false, nil
+		}
+		return ready.Status != metav1.ConditionUnknown, nil
+	})
+	if err != nil {
+		return err
+	}
+	if ready.Status == metav1.ConditionFalse {
+		return fmt.Errorf("%s", ready.Message)
+	}
+	return nil
+}
+
+// getReconcileStatus returns the reconcile request status and conditions of the object.
+func getReconcileStatus(obj client.Object) (*reconcileStatus, error) {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+	status := &reconcileStatus{}
+	statusContent, ok := content["status"].(map[string]interface{})
+	if !ok {
+		return status, nil
+	}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(statusContent, status); err != nil {
+		return nil, err
+	}
+	return status, nil
+}
@@ -0,0 +1,102 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT-0
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/fluxcd/pkg/apis/meta"
+	sourcev1 "github.com/fluxcd/source-controller/api/v1"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	cfnv1 "github.com/awslabs/aws-cloudformation-controller-for-flux/api/v1alpha1"
+)
+
+func TestReconcileStack(t *testing.T) {
+	key := types.NamespacedName{Namespace: mockNamespace, Name: mockStackName}
+
+	testCases := map[string]struct {
+		cfnStack        func(*cfnv1.CloudFormationStack)
+		withSource      bool
+		ready           metav1.ConditionStatus
+		wantedErr       string
+		wantedOutput    []string
+		wantedAnnotated []client.Object
+	}{
+		"reconcile the stack": {
+			ready: metav1.ConditionTrue,
+			wantedOutput: []string{
+				"► annotating CloudFormationStack mock-stack in mock-namespace namespace",
+				"✔ CloudFormationStack reconciliation completed",
+				"✔ applied revision " + mockSourceRevision,
+			},
+			wantedAnnotated: []client.Object{&cfnv1.CloudFormationStack{}},
+		},
+		"reconcile the source and the stack": {
+			withSource: true,
+			ready:      metav1.ConditionTrue,
+			wantedOutput: []string{
+				"► annotating GitRepository mock-repo in mock-namespace namespace",
+				"✔ fetched revision " + mockSourceRevision,
+				"► annotating CloudFormationStack mock-stack in mock-namespace namespace",
+				"✔ CloudFormationStack reconciliation completed",
+			},
+			wantedAnnotated: []client.Object{&cfnv1.CloudFormationStack{}, &sourcev1.GitRepository{}},
+		},
+		"fail when the stack is not ready after the reconciliation": {
+			ready:     metav1.ConditionFalse,
+			wantedErr: "CloudFormationStack 'mock-namespace/mock-stack' reconciliation failed: mock message",
+		},
+		"fail when the stack is suspended": {
+			cfnStack: func(cfnStack *cfnv1.CloudFormationStack) {
+				cfnStack.Spec.Suspend = true
+			},
+			ready:     metav1.ConditionTrue,
+			wantedErr: "CloudFormationStack 'mock-namespace/mock-stack' is suspended, run 'resume' instead",
+		},
+		"fail when the source kind is not supported": {
+			cfnStack: func(cfnStack *cfnv1.CloudFormationStack) {
+				cfnStack.Spec.SourceRef.Kind = "HelmRepository"
+			},
+			withSource: true,
+			ready:      metav1.ConditionTrue,
+			wantedErr:  "source kind 'HelmRepository' not supported",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			cfnStack := generateMockCfnStack()
+			if tc.cfnStack != nil {
+				tc.cfnStack(cfnStack)
+			}
+			kubeClient := newFakeClient(t, tc.ready, cfnStack, generateMockGitRepo(mockSourceRevision))
+			out := &bytes.Buffer{}
+
+			err := reconcileStack(context.Background(), out, kubeClient, key, tc.withSource)
+
+			if tc.wantedErr != "" {
+				require.EqualError(t, err, tc.wantedErr)
+				return
+			}
+			require.NoError(t, err)
+			for _, line := range tc.wantedOutput {
+				require.Contains(t, out.String(), line)
+			}
+			for _, obj := range tc.wantedAnnotated {
+				objKey := key
+				if _, ok := obj.(*sourcev1.GitRepository); ok {
+					objKey.Name = mockGitRepoName
+				}
+				require.NoError(t, kubeClient.Get(context.Background(), objKey, obj))
+				require.Contains(t, obj.GetAnnotations(), meta.ReconcileRequestAnnotation)
+			}
+		})
+	}
+}
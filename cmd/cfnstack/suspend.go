@@ -0,0 +1,123 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT-0
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	cfnv1 "github.com/awslabs/aws-cloudformation-controller-for-flux/api/v1alpha1"
+)
+
+var suspendCmd = &cobra.Command{
+	Use:   "suspend NAME...",
+	Short: "Suspend the reconciliation of CloudFormationStacks",
+	Long: `The suspend command sets 'spec.suspend' on CloudFormationStacks.
+The controller stops creating change sets for suspended stacks, and leaves the CloudFormation stacks as they are.`,
+	Example: `  # Suspend the reconciliation of a stack
+  cfnstack suspend my-stack`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: suspendCmdRun,
+}
+
+var resumeCmd = &cobra.Command{
+	Use:   "resume NAME...",
+	Short: "Resume the reconciliation of suspended CloudFormationStacks",
+	Long: `The resume command unsets 'spec.suspend' on CloudFormationStacks,
+then requests a reconciliation of each stack and waits for it to complete.`,
+	Example: `  # Resume the reconciliation of a stack
+  cfnstack resume my-stack`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: resumeCmdRun,
+}
+
+func init() {
+	rootCmd.AddCommand(suspendCmd)
+	rootCmd.AddCommand(resumeCmd)
+}
+
+func suspendCmdRun(cmd *cobra.Command, args []string) error {
+	kubeClient, namespace, err := newKubeClient()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(cmd.Context(), rootArgs.timeout)
+	defer cancel()
+
+	for _, name := range args {
+		key := types.NamespacedName{Namespace: namespace, Name: name}
+		if err := suspendStack(ctx, cmd.OutOrStdout(), kubeClient, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func resumeCmdRun(cmd *cobra.Command, args []string) error {
+	kubeClient, namespace, err := newKubeClient()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(cmd.Context(), rootArgs.timeout)
+	defer cancel()
+
+	for _, name := range args {
+		key := types.NamespacedName{Namespace: namespace, Name: name}
+		if err := resumeStack(ctx, cmd.OutOrStdout(), kubeClient, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// suspendStack suspends the reconciliation of the stack.
+func suspendStack(ctx context.Context, out io.Writer, kubeClient client.Client, key types.NamespacedName) error {
+	logAction(out, "suspending CloudFormationStack %s in %s namespace", key.Name, key.Namespace)
+	if _, err := setSuspend(ctx, kubeClient, key, true); err != nil {
+		return err
+	}
+	logSuccess(out, "CloudFormationStack suspended")
+	return nil
+}
+
+// resumeStack resumes the reconciliation of the stack and waits for the controller to reconcile it.
+func resumeStack(ctx context.Context, out io.Writer, kubeClient client.Client, key types.NamespacedName) error {
+	logAction(out, "resuming CloudFormationStack %s in %s namespace", key.Name, key.Namespace)
+	cfnStack, err := setSuspend(ctx, kubeClient, key, false)
+	if err != nil {
+		return err
+	}
+	logSuccess(out, "CloudFormationStack resumed")
+
+	requestedAt, err := requestReconcile(ctx, kubeClient, cfnStack)
+	if err != nil {
+		return fmt.Errorf("unable to annotate CloudFormationStack '%s': %w", key, err)
+	}
+	logWaiting(out, "waiting for CloudFormationStack reconciliation")
+	if err := waitForReconcile(ctx, kubeClient, cfnStack, requestedAt); err != nil {
+		return fmt.Errorf("CloudFormationStack '%s' reconciliation failed: %w", key, err)
+	}
+	logSuccess(out, "CloudFormationStack reconciliation completed")
+	return nil
+}
+
+// setSuspend sets the suspend field of the stack's spec. Returns the updated stack.
+func setSuspend(ctx context.Context, kubeClient client.Client, key types.NamespacedName, suspend bool) (*cfnv1.CloudFormationStack, error) {
+	cfnStack := &cfnv1.CloudFormationStack{}
+	if err := kubeClient.Get(ctx, key, cfnStack); err != nil {
+		return nil, fmt.Errorf("unable to get CloudFormationStack '%s': %w", key, err)
+	}
+
+	patch := client.MergeFrom(cfnStack.DeepCopy())
+	cfnStack.Spec.Suspend = suspend
+	if err := kubeClient.Patch(ctx, cfnStack, patch); err != nil {
+		return nil, fmt.Errorf("unable to patch CloudFormationStack '%s': %w", key, err)
+	}
+	return cfnStack, nil
+}
@@ -0,0 +1,47 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT-0
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/fluxcd/pkg/apis/meta"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	cfnv1 "github.com/awslabs/aws-cloudformation-controller-for-flux/api/v1alpha1"
+)
+
+func TestSuspendAndResumeStack(t *testing.T) {
+	key := types.NamespacedName{Namespace: mockNamespace, Name: mockStackName}
+	kubeClient := newFakeClient(t, metav1.ConditionTrue, generateMockCfnStack())
+	ctx := context.Background()
+	out := &bytes.Buffer{}
+
+	require.NoError(t, suspendStack(ctx, out, kubeClient, key))
+	cfnStack := &cfnv1.CloudFormationStack{}
+	require.NoError(t, kubeClient.Get(ctx, key, cfnStack))
+	require.True(t, cfnStack.Spec.Suspend)
+	require.NotContains(t, cfnStack.GetAnnotations(), meta.ReconcileRequestAnnotation)
+
+	require.NoError(t, resumeStack(ctx, out, kubeClient, key))
+	require.NoError(t, kubeClient.Get(ctx, key, cfnStack))
+	require.False(t, cfnStack.Spec.Suspend)
+	require.Equal(t, cfnStack.GetAnnotations()[meta.ReconcileRequestAnnotation], cfnStack.Status.LastHandledReconcileAt)
+
+	require.Contains(t, out.String(), "✔ CloudFormationStack suspended")
+	require.Contains(t, out.String(), "✔ CloudFormationStack resumed")
+	require.Contains(t, out.String(), "✔ CloudFormationStack reconciliation completed")
+}
+
+func TestSuspendStack_NotFound(t *testing.T) {
+	key := types.NamespacedName{Namespace: mockNamespace, Name: "other-stack"}
+	kubeClient := newFakeClient(t, metav1.ConditionTrue, generateMockCfnStack())
+
+	err := suspendStack(context.Background(), &bytes.Buffer{}, kubeClient, key)
+	require.ErrorContains(t, err, "unable to get CloudFormationStack 'mock-namespace/other-stack'")
+}
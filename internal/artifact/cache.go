@@ -0,0 +1,535 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT-0
+
+// Package artifact caches the source artifacts that the controllers download from source-controller on disk,
+// so that each artifact is downloaded and verified once for all the objects and reconciliations that use it.
+package artifact
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	sourcev1 "github.com/fluxcd/source-controller/api/v1"
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/opencontainers/go-digest"
+	_ "github.com/opencontainers/go-digest/blake3"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/metrics"
+)
+
+const (
+	// The name of the downloaded archive in the directory of a cached artifact
+	archiveFileName = "artifact.tar.gz"
+	// The name of the directory of the extracted files in the directory of a cached artifact
+	filesDirName = "files"
+)
+
+// Cache is a least recently used cache of verified source artifacts on disk, keyed by artifact digest.
+// Only the paths of an artifact that are requested are extracted from its cached archive.
+// When the archives and extracted files of the cached artifacts exceed the cache's maximum size,
+// the least recently used artifacts that are not in use are evicted.
+type Cache struct {
+	dir     string
+	maxSize int64
+
+	mu sync.Mutex
+	// entries are the elements of the cached artifacts in lru, by digest
+	entries map[string]*list.Element
+	// lru lists the cached artifacts from the most recently used to the least recently used
+	lru  *list.List
+	size int64
+}
+
+// entry is a cached artifact
+type entry struct {
+	digest string
+	dir    string
+	source string
+	// artifact is the source artifact that the archive was downloaded for
+	artifact sourcev1.Artifact
+
+	// size is the size of the archive and the extracted files, guarded by the cache's mutex
+	size int64
+	// refs is the number of Artifacts in use, guarded by the cache's mutex
+	refs int
+	// stored is true once the archive was downloaded and verified, guarded by the cache's mutex
+	stored bool
+
+	// mu is held while the archive is downloaded and while its files are extracted
+	mu     sync.Mutex
+	loaded bool
+	// extracted are the paths that were extracted from the archive
+	extracted []string
+}
+
+// Artifact is a cached artifact in use. Release must be called when the artifact is no longer in use.
+type Artifact struct {
+	// Artifact is the source artifact that was downloaded. If the requested artifact could not be downloaded,
+	// it is the verified artifact of the same source at the fallback revision.
+	Artifact sourcev1.Artifact
+
+	cache *Cache
+	entry *entry
+}
+
+// NewCache creates an artifact cache in a new directory in the given directory, or in the default directory
+// for temporary files if dir is empty. The artifacts are evicted when their total size exceeds maxSize bytes.
+func NewCache(dir string, maxSize int64) (*Cache, error) {
+	cacheDir, err := os.MkdirTemp(dir, "artifact-cache-")
+	if err != nil {
+		return nil, fmt.Errorf("unable to create artifact cache directory: %w", err)
+	}
+	return &Cache{
+		dir:     cacheDir,
+		maxSize: maxSize,
+		entries: map[string]*list.Element{},
+		lru:     list.New(),
+	}, nil
+}
+
+// Get returns the cached artifact with the digest of the given artifact. If the artifact is not cached,
+// it is downloaded with the HTTP client and verified against its digest first.
+// If the download fails, for example because source-controller is unavailable, the verified artifact
+// of the same source at the fallback revision is returned instead, if it is still cached.
+// An empty fallback revision disables the fallback.
+func (c *Cache) Get(ctx context.Context, httpClient *retryablehttp.Client, artifact *sourcev1.Artifact, fallbackRevision string) (*Artifact, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	dig, err := digest.Parse(artifact.Digest)
+	if err != nil {
+		metrics.RecordArtifactCacheRequest(metrics.ArtifactCacheFailed)
+		return nil, fmt.Errorf("failed to verify artifact: %w", err)
+	}
+
+	e := c.acquire(dig, artifact)
+	e.mu.Lock()
+	if e.loaded {
+		e.mu.Unlock()
+		metrics.RecordArtifactCacheRequest(metrics.ArtifactCacheHit)
+		return &Artifact{Artifact: e.artifact, cache: c, entry: e}, nil
+	}
+	size, err := download(ctx, httpClient, e.dir, dig, artifact)
+	e.loaded = err == nil
+	e.mu.Unlock()
+	if err == nil {
+		c.stored(e, size)
+		metrics.RecordArtifactCacheRequest(metrics.ArtifactCacheMiss)
+		return &Artifact{Artifact: e.artifact, cache: c, entry: e}, nil
+	}
+	c.release(e)
+
+	var unavailable *unavailableError
+	if errors.As(err, &unavailable) {
+		if fallback := c.acquireRevision(e.source, fallbackRevision); fallback != nil {
+			log.Error(err, "Source artifact is unavailable, using the verified artifact of the source at the fallback revision",
+				"revision", artifact.Revision, "fallbackRevision", fallback.artifact.Revision)
+			metrics.RecordArtifactCacheRequest(metrics.ArtifactCacheFallback)
+			return &Artifact{Artifact: fallback.artifact, cache: c, entry: fallback}, nil
+		}
+		err = unavailable.err
+	}
+	metrics.RecordArtifactCacheRequest(metrics.ArtifactCacheFailed)
+	return nil, err
+}
+
+// Extract extracts the files at the given paths of the artifact, and the files in the directories at the given paths,
+// unless they were already extracted. It returns the directory of the extracted files.
+func (a *Artifact) Extract(paths ...string) (string, error) {
+	e := a.entry
+	filesDir := filepath.Join(e.dir, filesDirName)
+
+	e.mu.Lock()
+	var missing []string
+	for _, p := range paths {
+		p = cleanPath(p)
+		if !e.isExtracted(p) && !contains(missing, p) {
+			missing = append(missing, p)
+		}
+	}
+	if len(missing) == 0 {
+		e.mu.Unlock()
+		return filesDir, nil
+	}
+	size, err := extract(filepath.Join(e.dir, archiveFileName), filesDir, missing)
+	if err == nil {
+		e.extracted = append(e.extracted, missing...)
+	}
+	e.mu.Unlock()
+
+	// the partially extracted files of a failed extraction are counted too
+	a.cache.grow(e, size)
+	if err != nil {
+		return "", err
+	}
+	return filesDir, nil
+}
+
+// Load extracts the files at the given paths of the artifact, and calls load with the directory of the extracted files.
+// If load fails because a file of the artifact was not extracted, the file is extracted and load is called again,
+// so that load can read files whose paths are only known from the contents of other files, like nested templates.
+func (a *Artifact) Load(load func(dir string) error, paths ...string) error {
+	dir, err := a.Extract(paths...)
+	if err != nil {
+		return err
+	}
+	for {
+		err := load(dir)
+		var pathErr *fs.PathError
+		if !errors.As(err, &pathErr) || !errors.Is(pathErr.Err, fs.ErrNotExist) {
+			return err
+		}
+		rel, relErr := filepath.Rel(dir, pathErr.Path)
+		if relErr != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) || a.isExtracted(rel) {
+			return err
+		}
+		if _, err := a.Extract(rel); err != nil {
+			return err
+		}
+	}
+}
+
+// Release releases the artifact, so that it can be evicted from the cache.
+func (a *Artifact) Release() {
+	a.cache.release(a.entry)
+}
+
+func (a *Artifact) isExtracted(p string) bool {
+	a.entry.mu.Lock()
+	defer a.entry.mu.Unlock()
+	return a.entry.isExtracted(cleanPath(p))
+}
+
+// isExtracted returns true if the path or one of its parent directories was extracted
+func (e *entry) isExtracted(p string) bool {
+	for _, extracted := range e.extracted {
+		if matches(p, extracted) {
+			return true
+		}
+	}
+	return false
+}
+
+// acquire returns the cache entry of the artifact with the given digest, adding an entry if it is not cached
+func (c *Cache) acquire(dig digest.Digest, artifact *sourcev1.Artifact) *entry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[dig.String()]; ok {
+		c.lru.MoveToFront(elem)
+		e := elem.Value.(*entry)
+		e.refs++
+		return e
+	}
+	e := &entry{
+		digest:   dig.String(),
+		dir:      filepath.Join(c.dir, fmt.Sprintf("%s-%s", dig.Algorithm(), dig.Encoded())),
+		source:   sourceKey(artifact),
+		artifact: *artifact.DeepCopy(),
+		refs:     1,
+	}
+	c.entries[e.digest] = c.lru.PushFront(e)
+	return e
+}
+
+// acquireRevision returns the cache entry of the verified artifact of the source at the given revision,
+// or nil if it is not cached
+func (c *Cache) acquireRevision(source string, revision string) *entry {
+	if revision == "" {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for elem := c.lru.Front(); elem != nil; elem = elem.Next() {
+		e := elem.Value.(*entry)
+		if e.stored && e.source == source && e.artifact.Revision == revision {
+			c.lru.MoveToFront(elem)
+			e.refs++
+			return e
+		}
+	}
+	return nil
+}
+
+// stored records that the archive of the entry was downloaded and verified
+func (c *Cache) stored(e *entry, size int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e.stored = true
+	c.add(e, size)
+}
+
+// grow adds the given size to the size of the entry, and evicts the least recently used entries if the cache is full
+func (c *Cache) grow(e *entry, size int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.add(e, size)
+}
+
+// add adds the given size to the size of the entry, and evicts the least recently used entries if the cache is full.
+// The cache's mutex must be held.
+func (c *Cache) add(e *entry, size int64) {
+	e.size += size
+	c.size += size
+	c.evict()
+}
+
+// release releases an entry, and removes it if its archive could not be downloaded
+func (c *Cache) release(e *entry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e.refs--
+	if e.refs == 0 && !e.stored {
+		c.remove(e)
+	}
+	c.evict()
+}
+
+// evict removes the least recently used entries that are not in use, until the cache is no longer full.
+// The cache's mutex must be held.
+func (c *Cache) evict() {
+	for elem := c.lru.Back(); elem != nil && c.size > c.maxSize; {
+		prev := elem.Prev()
+		if e := elem.Value.(*entry); e.refs == 0 {
+			c.remove(e)
+			metrics.RecordArtifactCacheEviction()
+		}
+		elem = prev
+	}
+	metrics.RecordArtifactCacheSize(c.lru.Len(), c.size)
+}
+
+// remove removes an entry and its files from the cache. The cache's mutex must be held.
+func (c *Cache) remove(e *entry) {
+	elem, ok := c.entries[e.digest]
+	if !ok || elem.Value.(*entry) != e {
+		return
+	}
+	c.lru.Remove(elem)
+	delete(c.entries, e.digest)
+	c.size -= e.size
+	os.RemoveAll(e.dir)
+}
+
+// sourceKey identifies the source of an artifact: source-controller stores the artifacts of each source
+// in the same directory.
+func sourceKey(artifact *sourcev1.Artifact) string {
+	if artifact.Path != "" {
+		return path.Dir(artifact.Path)
+	}
+	if u, err := url.Parse(artifact.URL); err == nil {
+		u.Path = path.Dir(u.Path)
+		u.RawQuery = ""
+		return u.String()
+	}
+	return artifact.URL
+}
+
+// unavailableError occurs when an artifact cannot be downloaded from source-controller
+type unavailableError struct {
+	err error
+}
+
+func (e *unavailableError) Error() string {
+	return e.err.Error()
+}
+
+func (e *unavailableError) Unwrap() error {
+	return e.err
+}
+
+// download downloads the archive of the artifact into the directory and verifies its digest.
+// It returns the size of the archive.
+func download(ctx context.Context, httpClient *retryablehttp.Client, dir string, dig digest.Digest, artifact *sourcev1.Artifact) (int64, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	artifactURL := artifact.URL
+	if hostname := os.Getenv("SOURCE_CONTROLLER_LOCALHOST"); hostname != "" {
+		u, err := url.Parse(artifactURL)
+		if err != nil {
+			return 0, err
+		}
+		u.Host = hostname
+		artifactURL = u.String()
+	}
+
+	req, err := retryablehttp.NewRequestWithContext(ctx, http.MethodGet, artifactURL, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create a new request: %w", err)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return 0, &unavailableError{err: fmt.Errorf("failed to download artifact, error: %w", err)}
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			log.Error(err, "Error closing artifact download")
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, &unavailableError{err: fmt.Errorf("failed to download artifact, status code: %s", resp.Status)}
+	}
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return 0, fmt.Errorf("unable to create artifact cache directory: %w", err)
+	}
+	tmpFile, err := os.CreateTemp(dir, archiveFileName+".*")
+	if err != nil {
+		return 0, fmt.Errorf("unable to create artifact cache file: %w", err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	// Verify the downloaded artifact against the advertised digest.
+	verifier := dig.Verifier()
+	size, err := io.Copy(io.MultiWriter(verifier, tmpFile), resp.Body)
+	if closeErr := tmpFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return 0, &unavailableError{err: fmt.Errorf("failed to download artifact, error: %w", err)}
+	}
+	if !verifier.Verified() {
+		return 0, fmt.Errorf("failed to verify artifact: computed digest doesn't match advertised '%s'", dig)
+	}
+
+	if err := os.Rename(tmpFile.Name(), filepath.Join(dir, archiveFileName)); err != nil {
+		return 0, fmt.Errorf("unable to store artifact in the artifact cache: %w", err)
+	}
+	return size, nil
+}
+
+// extract extracts the files of the gzip-compressed tar archive that match the given paths into the directory,
+// except the files that already exist. It returns the size of the extracted files.
+func extract(archive string, dir string, paths []string) (int64, error) {
+	f, err := os.Open(archive)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return 0, fmt.Errorf("requires gzip-compressed body: %w", err)
+	}
+	tr := tar.NewReader(zr)
+
+	var size int64
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return size, fmt.Errorf("tar error: %w", err)
+		}
+		if !validRelPath(header.Name) {
+			return size, fmt.Errorf("tar contained invalid name error %q", header.Name)
+		}
+		name := path.Clean(header.Name)
+		if !matchesAny(name, paths) {
+			continue
+		}
+		abs := filepath.Join(dir, filepath.FromSlash(name))
+
+		mode := header.FileInfo().Mode()
+		switch {
+		case mode.IsDir():
+			if err := os.MkdirAll(abs, 0o755); err != nil {
+				return size, err
+			}
+		case mode.IsRegular():
+			if _, err := os.Lstat(abs); err == nil {
+				// extracted with an earlier path, and possibly in use
+				continue
+			}
+			if err := os.MkdirAll(filepath.Dir(abs), 0o755); err != nil {
+				return size, err
+			}
+			n, err := writeFile(abs, tr, mode.Perm())
+			if err != nil {
+				return size, fmt.Errorf("error writing to %s: %w", abs, err)
+			}
+			size += n
+		default:
+			return size, fmt.Errorf("tar file entry %s contained unsupported file type %v", header.Name, mode)
+		}
+	}
+	return size, nil
+}
+
+// writeFile writes the file through a temporary file, so that the file is never read partially written
+func writeFile(name string, r io.Reader, perm fs.FileMode) (int64, error) {
+	tmpFile, err := os.CreateTemp(filepath.Dir(name), "."+filepath.Base(name)+".*")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(tmpFile.Name())
+
+	n, err := io.Copy(tmpFile, r)
+	if closeErr := tmpFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmpFile.Name(), perm)
+	}
+	if err == nil {
+		err = os.Rename(tmpFile.Name(), name)
+	}
+	return n, err
+}
+
+// cleanPath returns the artifact path relative to the root of the artifact, or "." for the root.
+// Like the paths joined securely with the artifact directory, parent directories of the root resolve to the root.
+func cleanPath(p string) string {
+	p = path.Clean("/" + filepath.ToSlash(p))
+	if p == "/" {
+		return "."
+	}
+	return p[1:]
+}
+
+// matches returns true if the name is the path or in the directory at the path
+func matches(name string, p string) bool {
+	return p == "." || name == p || strings.HasPrefix(name, p+"/")
+}
+
+func matchesAny(name string, paths []string) bool {
+	for _, p := range paths {
+		if matches(name, p) {
+			return true
+		}
+	}
+	return false
+}
+
+func validRelPath(p string) bool {
+	if p == "" || strings.Contains(p, `\`) || strings.HasPrefix(p, "/") || strings.Contains(p, "../") {
+		return false
+	}
+	return true
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,260 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT-0
+
+package artifact
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	sourcev1 "github.com/fluxcd/source-controller/api/v1"
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/require"
+)
+
+// artifactServer serves gzip-compressed tar archives by URL path, and counts the downloads
+type artifactServer struct {
+	*httptest.Server
+	mu        sync.Mutex
+	archives  map[string][]byte
+	downloads atomic.Int32
+}
+
+func newArtifactServer(t *testing.T) *artifactServer {
+	s := &artifactServer{archives: map[string][]byte{}}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		archive, ok := s.archive(r.URL.Path)
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		s.downloads.Add(1)
+		w.Write(archive)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *artifactServer) archive(urlPath string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	archive, ok := s.archives[urlPath]
+	return archive, ok
+}
+
+// remove removes the archive of the artifact from the server
+func (s *artifactServer) remove(artifact *sourcev1.Artifact) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.archives, "/"+artifact.Path)
+}
+
+// add adds an archive of the files of a source revision to the server, and returns its artifact
+func (s *artifactServer) add(t *testing.T, revision string, files map[string]string) *sourcev1.Artifact {
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+	for name, contents := range files {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0o600, Size: int64(len(contents)), Typeflag: tar.TypeReg}))
+		_, err := tw.Write([]byte(contents))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gw.Close())
+
+	artifactPath := fmt.Sprintf("gitrepository/flux-system/my-repo/%s.tar.gz", revision)
+	s.mu.Lock()
+	s.archives["/"+artifactPath] = buf.Bytes()
+	s.mu.Unlock()
+	return &sourcev1.Artifact{
+		Path:     artifactPath,
+		URL:      s.URL + "/" + artifactPath,
+		Revision: revision,
+		Digest:   digest.FromBytes(buf.Bytes()).String(),
+	}
+}
+
+func newHTTPClient() *retryablehttp.Client {
+	httpClient := retryablehttp.NewClient()
+	httpClient.RetryMax = 0
+	httpClient.Logger = nil
+	return httpClient
+}
+
+func TestCache_GetAndExtract(t *testing.T) {
+	server := newArtifactServer(t)
+	artifact := server.add(t, "rev1", map[string]string{
+		"stacks/template.yaml":        "template",
+		"stacks/nested/network.yaml":  "network",
+		"policies/topics.rego":        "policy",
+		"README.md":                   "readme",
+		"stacks/nested/database.yaml": "database",
+	})
+	cache, err := NewCache(t.TempDir(), 1<<20)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	// The artifact is downloaded once for all its users
+	a, err := cache.Get(ctx, newHTTPClient(), artifact, "")
+	require.NoError(t, err)
+	defer a.Release()
+	b, err := cache.Get(ctx, newHTTPClient(), artifact, "")
+	require.NoError(t, err)
+	defer b.Release()
+	require.Equal(t, int32(1), server.downloads.Load())
+	require.Equal(t, "rev1", a.Artifact.Revision)
+
+	// Only the requested paths are extracted
+	dir, err := a.Extract("stacks/template.yaml", "policies")
+	require.NoError(t, err)
+	requireFile(t, dir, "stacks/template.yaml", "template")
+	requireFile(t, dir, "policies/topics.rego", "policy")
+	require.NoFileExists(t, filepath.Join(dir, "stacks/nested/network.yaml"))
+	require.NoFileExists(t, filepath.Join(dir, "README.md"))
+
+	// The extracted files are shared
+	dir, err = b.Extract("stacks/nested/../template.yaml", "/../stacks/nested")
+	require.NoError(t, err)
+	requireFile(t, dir, "stacks/template.yaml", "template")
+	requireFile(t, dir, "stacks/nested/network.yaml", "network")
+	requireFile(t, dir, "stacks/nested/database.yaml", "database")
+	require.NoFileExists(t, filepath.Join(dir, "README.md"))
+}
+
+func TestCache_Load(t *testing.T) {
+	server := newArtifactServer(t)
+	artifact := server.add(t, "rev1", map[string]string{
+		"template.yaml":       "nested/network.yaml",
+		"nested/network.yaml": "nested/missing.yaml",
+		"README.md":           "readme",
+	})
+	cache, err := NewCache(t.TempDir(), 1<<20)
+	require.NoError(t, err)
+
+	a, err := cache.Get(context.Background(), newHTTPClient(), artifact, "")
+	require.NoError(t, err)
+	defer a.Release()
+
+	// Each file names the next file to read, until a file that does not exist in the artifact
+	var read []string
+	err = a.Load(func(dir string) error {
+		read = nil
+		name := "template.yaml"
+		for {
+			data, err := os.ReadFile(filepath.Join(dir, name))
+			if err != nil {
+				return err
+			}
+			read = append(read, name)
+			name = string(data)
+		}
+	}, "template.yaml")
+	require.ErrorIs(t, err, os.ErrNotExist)
+	require.Equal(t, []string{"template.yaml", "nested/network.yaml"}, read)
+
+	dir, err := a.Extract()
+	require.NoError(t, err)
+	require.NoFileExists(t, filepath.Join(dir, "README.md"))
+}
+
+func TestCache_Fallback(t *testing.T) {
+	server := newArtifactServer(t)
+	artifact := server.add(t, "rev1", map[string]string{"template.yaml": "rev1"})
+	cache, err := NewCache(t.TempDir(), 1<<20)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	a, err := cache.Get(ctx, newHTTPClient(), artifact, "")
+	require.NoError(t, err)
+	a.Release()
+
+	// The new artifact of the source cannot be downloaded
+	newArtifact := server.add(t, "rev2", map[string]string{"template.yaml": "rev2"})
+	server.remove(newArtifact)
+
+	a, err = cache.Get(ctx, newHTTPClient(), newArtifact, "rev1")
+	require.NoError(t, err)
+	require.Equal(t, "rev1", a.Artifact.Revision)
+	dir, err := a.Extract("template.yaml")
+	require.NoError(t, err)
+	requireFile(t, dir, "template.yaml", "rev1")
+	a.Release()
+
+	// Only the artifact at the fallback revision is used
+	_, err = cache.Get(ctx, newHTTPClient(), newArtifact, "")
+	require.ErrorContains(t, err, "failed to download artifact, status code: 404")
+	_, err = cache.Get(ctx, newHTTPClient(), newArtifact, "rev0")
+	require.ErrorContains(t, err, "failed to download artifact, status code: 404")
+
+	// The artifacts of other sources are not used
+	otherArtifact := *newArtifact
+	otherArtifact.Path = "gitrepository/flux-system/other-repo/rev2.tar.gz"
+	_, err = cache.Get(ctx, newHTTPClient(), &otherArtifact, "rev1")
+	require.ErrorContains(t, err, "failed to download artifact, status code: 404")
+
+	// Artifacts that cannot be verified are not replaced
+	corruptArtifact := *newArtifact
+	corruptArtifact.Path = artifact.Path
+	corruptArtifact.URL = artifact.URL
+	_, err = cache.Get(ctx, newHTTPClient(), &corruptArtifact, "rev1")
+	require.ErrorContains(t, err, "failed to verify artifact")
+}
+
+func TestCache_Evict(t *testing.T) {
+	server := newArtifactServer(t)
+	artifacts := []*sourcev1.Artifact{
+		server.add(t, "rev1", map[string]string{"template.yaml": "rev1"}),
+		server.add(t, "rev2", map[string]string{"template.yaml": "rev2"}),
+		server.add(t, "rev3", map[string]string{"template.yaml": "rev3"}),
+	}
+	// The cache holds two artifacts and their extracted files
+	archive, _ := server.archive("/" + artifacts[0].Path)
+	archiveSize := len(archive)
+	maxSize := int64(archiveSize*2 + archiveSize/2)
+	cache, err := NewCache(t.TempDir(), maxSize)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	get := func(artifact *sourcev1.Artifact) *Artifact {
+		a, err := cache.Get(ctx, newHTTPClient(), artifact, "")
+		require.NoError(t, err)
+		return a
+	}
+
+	get(artifacts[0]).Release()
+	inUse := get(artifacts[1])
+	get(artifacts[0]).Release()
+	// The least recently used artifact is in use, the other artifact is evicted
+	get(artifacts[2]).Release()
+	require.Equal(t, int32(3), server.downloads.Load())
+	require.Len(t, cache.entries, 2)
+	require.NotContains(t, cache.entries, artifacts[0].Digest)
+
+	dir, err := inUse.Extract("template.yaml")
+	require.NoError(t, err)
+	requireFile(t, dir, "template.yaml", "rev2")
+	inUse.Release()
+
+	get(artifacts[2]).Release()
+	require.Equal(t, int32(3), server.downloads.Load())
+	get(artifacts[0]).Release()
+	require.Equal(t, int32(4), server.downloads.Load())
+	require.NoDirExists(t, filepath.Join(dir, ".."))
+}
+
+func requireFile(t *testing.T, dir string, name string, contents string) {
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	require.NoError(t, err)
+	require.Equal(t, contents, string(data))
+}
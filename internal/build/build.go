@@ -0,0 +1,207 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT-0
+
+// Package build computes the deployment inputs that the controller submits to CloudFormation for a stack:
+// the template loaded from a source artifact directory, and the stack parameters and tags.
+// It does not call AWS or Kubernetes, so the same inputs can be computed offline.
+package build
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	sdktypes "github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+
+	cfnv1 "github.com/awslabs/aws-cloudformation-controller-for-flux/api/v1alpha1"
+	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/clients/cloudformation"
+	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/clients/types"
+	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/template"
+)
+
+// changeSetDigestLength is the number of hexadecimal digits of the digest in the change set names
+const changeSetDigestLength = 16
+
+// Options are the controller settings that contribute to the deployment inputs of all stacks.
+type Options struct {
+	// ControllerName is the prefix of the default tags, like 'cfn-flux-controller'.
+	ControllerName string
+
+	// ControllerVersion is the value of the default version tag.
+	ControllerVersion string
+
+	// StackTags are the tags that the controller applies to all stacks.
+	StackTags map[string]string
+}
+
+// Stack returns the CloudFormation client stack that deploys the given template for the given stack.
+// The template bucket settings and the change set name are left to the caller.
+func Stack(cfnStack cfnv1.CloudFormationStack, tmpl *template.Template, opts Options) *types.Stack {
+	return &types.Stack{
+		Name: cfnStack.Spec.StackName,
+		StackConfig: &types.StackConfig{
+			TemplateBody: tmpl.Body,
+			Parameters:   Parameters(cfnStack, tmpl),
+			Tags:         Tags(cfnStack.Name, cfnStack.Namespace, cfnStack.Spec.StackTags, opts),
+		},
+	}
+}
+
+// Parameters returns the stack parameters: the parameters generated with the template,
+// like CDK stack parameters, followed by the parameters in the stack's spec, which override them.
+func Parameters(cfnStack cfnv1.CloudFormationStack, tmpl *template.Template) []sdktypes.Parameter {
+	var params []sdktypes.Parameter
+	templateParamKeys := make([]string, 0, len(tmpl.Parameters))
+	for key := range tmpl.Parameters {
+		templateParamKeys = append(templateParamKeys, key)
+	}
+	sort.Strings(templateParamKeys)
+	for _, key := range templateParamKeys {
+		if !hasStackParameter(cfnStack, key) {
+			params = append(params, sdktypes.Parameter{
+				ParameterKey:   aws.String(key),
+				ParameterValue: aws.String(tmpl.Parameters[key]),
+			})
+		}
+	}
+	for _, param := range cfnStack.Spec.StackParameters {
+		params = append(params, sdktypes.Parameter{
+			ParameterKey:   aws.String(param.Key),
+			ParameterValue: aws.String(param.Value),
+		})
+	}
+	return params
+}
+
+// Tags returns the stack tags for the object with the given name and namespace: the controller's default
+// '<controller name>/version', '<controller name>/name' and '<controller name>/namespace' tags,
+// followed by the controller's stack tags, sorted by key, and the given tags from the object's spec.
+func Tags(name string, namespace string, specTags []cfnv1.StackTag, opts Options) []sdktypes.Tag {
+	tags := []sdktypes.Tag{
+		{
+			Key:   aws.String(fmt.Sprintf("%s/version", opts.ControllerName)),
+			Value: aws.String(opts.ControllerVersion),
+		},
+		{
+			Key:   aws.String(fmt.Sprintf("%s/name", opts.ControllerName)),
+			Value: aws.String(name),
+		},
+		{
+			Key:   aws.String(fmt.Sprintf("%s/namespace", opts.ControllerName)),
+			Value: aws.String(namespace),
+		},
+	}
+	keys := make([]string, 0, len(opts.StackTags))
+	for key := range opts.StackTags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		tags = append(tags, sdktypes.Tag{
+			Key:   aws.String(key),
+			Value: aws.String(opts.StackTags[key]),
+		})
+	}
+	for _, tag := range specTags {
+		tags = append(tags, sdktypes.Tag{
+			Key:   aws.String(tag.Key),
+			Value: aws.String(tag.Value),
+		})
+	}
+	return tags
+}
+
+// hasStackParameter returns true if the stack's spec sets the parameter with the given key
+func hasStackParameter(cfnStack cfnv1.CloudFormationStack, key string) bool {
+	for _, param := range cfnStack.Spec.StackParameters {
+		if param.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// ConfigDigest returns the SHA-256 digest of the deployment inputs of the given client stack: its template,
+// the nested templates and assets of the template, the stack parameters and tags, and the template bucket options.
+// Two client stacks with the same digest deploy the same changes.
+func ConfigDigest(clientStack *types.Stack, tmpl *template.Template) string {
+	h := sha256.New()
+	writeSource(h, tmpl)
+	// the order of the parameters and tags is significant, later values override earlier ones
+	for _, param := range clientStack.Parameters {
+		fmt.Fprintf(h, "parameter %q=%q\n", aws.ToString(param.ParameterKey), aws.ToString(param.ParameterValue))
+	}
+	for _, tag := range clientStack.Tags {
+		fmt.Fprintf(h, "tag %q=%q\n", aws.ToString(tag.Key), aws.ToString(tag.Value))
+	}
+	opts := clientStack.TemplateBucketOptions
+	fmt.Fprintf(h, "bucket %q %q %q %q %q\n", clientStack.TemplateBucket, opts.KeyPrefix, opts.KMSKeyID, opts.ACL, opts.ExpectedBucketOwner)
+	writeSorted(h, "object-tag", opts.ObjectTags)
+	return fmt.Sprintf("sha256:%x", h.Sum(nil))
+}
+
+// ChangeSetName returns the name of the change set that deploys the deployment inputs with the given source
+// and config digests to the stack, at the stack's current generation.
+// Source revisions that change neither the source contents nor the deployment inputs share the same change set.
+// The name also covers the stack update time recorded when the last change set was applied, so the stack gets
+// a new change set when it is forced or was updated outside the controller, even if its inputs did not change.
+func ChangeSetName(cfnStack cfnv1.CloudFormationStack, sourceDigest string, configDigest string) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "source %s\n", sourceDigest)
+	fmt.Fprintf(h, "config %s\n", configDigest)
+	if updateTime := cfnStack.Status.LastAppliedStackUpdateTime; updateTime != nil {
+		fmt.Fprintf(h, "applied %s\n", updateTime.UTC().Format(time.RFC3339Nano))
+	}
+	return cloudformation.GetChangeSetName(cfnStack.Generation, fmt.Sprintf("%x", h.Sum(nil))[:changeSetDigestLength])
+}
+
+// SourceDigest returns the SHA-256 digest of the contents that the template was loaded from in the source artifact:
+// the template file and its nested templates, the parameters generated with the template, like the parameters
+// synthesized by the AWS CDK, and the assets of the template, like the zip archives of the CDK asset directories.
+// Source revisions that do not change these contents have the same digest.
+func SourceDigest(tmpl *template.Template) string {
+	h := sha256.New()
+	writeSource(h, tmpl)
+	return fmt.Sprintf("sha256:%x", h.Sum(nil))
+}
+
+// writeSource writes the digests of the template, nested templates and assets, and the generated parameters
+func writeSource(w io.Writer, tmpl *template.Template) {
+	templates := map[string]string{}
+	addTemplateDigests(templates, tmpl)
+	writeSorted(w, "template", templates)
+	assets := map[string]string{}
+	for _, asset := range tmpl.Assets {
+		for _, key := range asset.Keys {
+			assets[key] = fmt.Sprintf("%x", sha256.Sum256(asset.Body))
+		}
+	}
+	writeSorted(w, "asset", assets)
+	writeSorted(w, "generated-parameter", tmpl.Parameters)
+}
+
+// addTemplateDigests adds the digests of the template and its nested templates, by path
+func addTemplateDigests(digests map[string]string, tmpl *template.Template) {
+	if _, ok := digests[tmpl.Path]; ok {
+		return
+	}
+	digests[tmpl.Path] = tmpl.Digest()
+	for _, ref := range tmpl.Nested {
+		addTemplateDigests(digests, ref.Template)
+	}
+}
+
+// writeSorted writes the entries of the map to the writer, sorted by key
+func writeSorted(w io.Writer, kind string, entries map[string]string) {
+	keys := make([]string, 0, len(entries))
+	for key := range entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(w, "%s %q=%q\n", kind, key, entries[key])
+	}
+}
@@ -0,0 +1,228 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT-0
+
+package build
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	sdktypes "github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cfnv1 "github.com/awslabs/aws-cloudformation-controller-for-flux/api/v1alpha1"
+	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/clients/types"
+	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/template"
+)
+
+func generateMockCfnStack() cfnv1.CloudFormationStack {
+	return cfnv1.CloudFormationStack{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "mock-stack",
+			Namespace:  "mock-namespace",
+			Generation: 2,
+		},
+		Spec: cfnv1.CloudFormationStackSpec{
+			StackName:    "mock-real-stack",
+			TemplatePath: "template.yaml",
+			StackParameters: []cfnv1.StackParameter{
+				{Key: "BucketName", Value: "my-bucket"},
+			},
+			StackTags: []cfnv1.StackTag{
+				{Key: "team", Value: "payments"},
+			},
+		},
+	}
+}
+
+func TestStack(t *testing.T) {
+	cfnStack := generateMockCfnStack()
+	tmpl := &template.Template{
+		Path: "template.yaml",
+		Body: "Resources: {}\n",
+		Parameters: map[string]string{
+			"BootstrapVersion": "/cdk-bootstrap/hnb659fds/version",
+			"BucketName":       "generated-bucket",
+		},
+	}
+	opts := Options{
+		ControllerName:    "cfn-flux-controller",
+		ControllerVersion: "v1.2.3",
+		StackTags:         map[string]string{"owner": "platform", "env": "prod"},
+	}
+
+	clientStack := Stack(cfnStack, tmpl, opts)
+
+	require.Equal(t, "mock-real-stack", clientStack.Name)
+	require.Empty(t, clientStack.ChangeSetName)
+	require.Equal(t, "Resources: {}\n", clientStack.TemplateBody)
+	require.Equal(t, []sdktypes.Parameter{
+		{ParameterKey: aws.String("BootstrapVersion"), ParameterValue: aws.String("/cdk-bootstrap/hnb659fds/version")},
+		{ParameterKey: aws.String("BucketName"), ParameterValue: aws.String("my-bucket")},
+	}, clientStack.Parameters)
+	require.Equal(t, []sdktypes.Tag{
+		{Key: aws.String("cfn-flux-controller/version"), Value: aws.String("v1.2.3")},
+		{Key: aws.String("cfn-flux-controller/name"), Value: aws.String("mock-stack")},
+		{Key: aws.String("cfn-flux-controller/namespace"), Value: aws.String("mock-namespace")},
+		{Key: aws.String("env"), Value: aws.String("prod")},
+		{Key: aws.String("owner"), Value: aws.String("platform")},
+		{Key: aws.String("team"), Value: aws.String("payments")},
+	}, clientStack.Tags)
+}
+
+func TestConfigDigest(t *testing.T) {
+	newStack := func() (*types.Stack, *template.Template) {
+		nested := &template.Template{Path: "nested/network.yaml", Body: "Resources: {}\n"}
+		tmpl := &template.Template{
+			Path:   "template.yaml",
+			Body:   "Resources: {}\n",
+			Nested: []*template.Reference{{Location: "nested/network.yaml", Template: nested}},
+			Assets: []*template.Asset{{Keys: []string{"asset.zip"}, Body: []byte("hello")}},
+		}
+		clientStack := Stack(generateMockCfnStack(), tmpl, Options{})
+		clientStack.TemplateBucket = "my-bucket"
+		return clientStack, tmpl
+	}
+
+	clientStack, tmpl := newStack()
+	digest := ConfigDigest(clientStack, tmpl)
+	require.Regexp(t, "^sha256:[0-9a-f]{64}$", digest)
+
+	// The change set name is not a deployment input
+	clientStack.ChangeSetName = "flux-3-5f0c1e2b9a7d4c36"
+	require.Equal(t, digest, ConfigDigest(clientStack, tmpl))
+
+	changes := map[string]func(clientStack *types.Stack, tmpl *template.Template){
+		"template": func(_ *types.Stack, tmpl *template.Template) {
+			tmpl.Body = "Resources:\n  Topic:\n    Type: AWS::SNS::Topic\n"
+		},
+		"nested template": func(_ *types.Stack, tmpl *template.Template) {
+			tmpl.Nested[0].Template.Body = "Resources:\n  Vpc:\n    Type: AWS::EC2::VPC\n"
+		},
+		"asset": func(_ *types.Stack, tmpl *template.Template) {
+			tmpl.Assets[0].Body = []byte("world")
+		},
+		"parameters": func(clientStack *types.Stack, _ *template.Template) {
+			clientStack.Parameters[0].ParameterValue = aws.String("other-bucket")
+		},
+		"tags": func(clientStack *types.Stack, _ *template.Template) {
+			clientStack.Tags = clientStack.Tags[1:]
+		},
+		"template bucket options": func(clientStack *types.Stack, _ *template.Template) {
+			clientStack.TemplateBucketOptions.KMSKeyID = "alias/my-key"
+		},
+	}
+	for name, change := range changes {
+		t.Run(name, func(t *testing.T) {
+			clientStack, tmpl := newStack()
+			change(clientStack, tmpl)
+			require.NotEqual(t, digest, ConfigDigest(clientStack, tmpl))
+		})
+	}
+}
+
+func TestChangeSetName(t *testing.T) {
+	cfnStack := generateMockCfnStack()
+	sourceDigest := "sha256:a719d1a02d551b210a2361ff3359086a7bf8b8368e6af3d8d8ecc3ced1c34896"
+	configDigest := "sha256:f5eed354ddd458b4741a31ead6425edf7bc319a625169093424d79edb4353feb"
+
+	name := ChangeSetName(cfnStack, sourceDigest, configDigest)
+	require.Regexp(t, "^flux-2-[0-9a-f]{16}$", name)
+	require.Equal(t, name, ChangeSetName(cfnStack, sourceDigest, configDigest))
+
+	// A new source digest, config digest or generation requires a new change set
+	require.NotEqual(t, name, ChangeSetName(cfnStack, "sha256:e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855", configDigest))
+	require.NotEqual(t, name, ChangeSetName(cfnStack, sourceDigest, "sha256:e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"))
+	cfnStack.Generation = 3
+	require.Regexp(t, "^flux-3-[0-9a-f]{16}$", ChangeSetName(cfnStack, sourceDigest, configDigest))
+
+	// Applying a change set to the stack requires a new change set for the same inputs
+	name = ChangeSetName(cfnStack, sourceDigest, configDigest)
+	updateTime := metav1.NewMicroTime(time.Date(2024, 1, 2, 3, 4, 5, 6000, time.UTC))
+	cfnStack.Status.LastAppliedStackUpdateTime = &updateTime
+	appliedName := ChangeSetName(cfnStack, sourceDigest, configDigest)
+	require.NotEqual(t, name, appliedName)
+	laterUpdateTime := metav1.NewMicroTime(updateTime.Add(time.Hour))
+	cfnStack.Status.LastAppliedStackUpdateTime = &laterUpdateTime
+	require.NotEqual(t, appliedName, ChangeSetName(cfnStack, sourceDigest, configDigest))
+}
+
+func TestSourceDigest(t *testing.T) {
+	artifactDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(artifactDir, "nested"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(artifactDir, "template.yaml"), []byte(
+		"Resources:\n  Network:\n    Type: AWS::CloudFormation::Stack\n    Properties:\n      TemplateURL: nested/network.yaml\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(artifactDir, "nested", "network.yaml"), []byte("Resources: {}\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(artifactDir, "unrelated.yaml"), []byte("Resources: {}\n"), 0o600))
+
+	tmpl, err := LoadTemplate(artifactDir, "template.yaml", nil, nil)
+	require.NoError(t, err)
+	digest := SourceDigest(tmpl)
+	require.Regexp(t, "^sha256:[0-9a-f]{64}$", digest)
+
+	// Files that the template does not consume do not change the digest
+	require.NoError(t, os.WriteFile(filepath.Join(artifactDir, "unrelated.yaml"), []byte("Resources:\n  Topic:\n    Type: AWS::SNS::Topic\n"), 0o600))
+	tmpl, err = LoadTemplate(artifactDir, "template.yaml", nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, digest, SourceDigest(tmpl))
+
+	// Nested templates do
+	require.NoError(t, os.WriteFile(filepath.Join(artifactDir, "nested", "network.yaml"), []byte("Resources:\n  Vpc:\n    Type: AWS::EC2::VPC\n"), 0o600))
+	tmpl, err = LoadTemplate(artifactDir, "template.yaml", nil, nil)
+	require.NoError(t, err)
+	require.NotEqual(t, digest, SourceDigest(tmpl))
+
+	// So do the generated parameters and the assets
+	tmpl, err = LoadTemplate(artifactDir, "template.yaml", nil, nil)
+	require.NoError(t, err)
+	nestedDigest := SourceDigest(tmpl)
+	tmpl.Parameters = map[string]string{"BootstrapVersion": "/cdk-bootstrap/hnb659fds/version"}
+	require.NotEqual(t, nestedDigest, SourceDigest(tmpl))
+	parametersDigest := SourceDigest(tmpl)
+	tmpl.Assets = []*template.Asset{{Keys: []string{"asset.zip"}, Body: []byte("hello")}}
+	require.NotEqual(t, parametersDigest, SourceDigest(tmpl))
+}
+
+func TestLoadTemplate(t *testing.T) {
+	artifactDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(artifactDir, "nested"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(artifactDir, "template.yaml"), []byte(
+		"Resources:\n  Network:\n    Type: AWS::CloudFormation::Stack\n    Properties:\n      TemplateURL: nested/network.yaml\n  Topic:\n    Type: AWS::SNS::Topic\n    Properties:\n      TopicName: ${env}-topic\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(artifactDir, "nested", "network.yaml"), []byte(
+		"Resources:\n  Vpc:\n    Type: AWS::EC2::VPC\n    Properties:\n      CidrBlock: ${cidr:=10.0.0.0/16}\n"), 0o600))
+
+	cfnStack := generateMockCfnStack()
+	cfnStack.Spec.PostBuild = &cfnv1.PostBuild{Substitute: map[string]string{"env": "prod"}}
+
+	tmpl, err := LoadTemplate(artifactDir, "template.yaml", nil, SubstituteTemplateTransform(cfnStack, cfnStack.Spec.PostBuild.Substitute))
+	require.NoError(t, err)
+	require.Contains(t, tmpl.Body, "TopicName: prod-topic")
+	require.Len(t, tmpl.Nested, 1)
+	require.Equal(t, "nested/network.yaml", tmpl.Nested[0].Location)
+	require.Contains(t, tmpl.Nested[0].Template.Body, "CidrBlock: 10.0.0.0/16")
+
+	_, err = LoadTemplate(artifactDir, "does-not-exist.yaml", nil, nil)
+	require.EqualError(t, err, "unable to read template file 'does-not-exist.yaml' in the artifact temp directory")
+
+	strictStack := generateMockCfnStack()
+	strictStack.Spec.PostBuild = &cfnv1.PostBuild{Strict: true, Substitute: map[string]string{"other": "value"}}
+	_, err = LoadTemplate(artifactDir, "template.yaml", nil, SubstituteTemplateTransform(strictStack, strictStack.Spec.PostBuild.Substitute))
+	require.ErrorIs(t, err, ErrSubstitutionFailed)
+}
+
+func TestSubstituteStackSpec(t *testing.T) {
+	cfnStack := generateMockCfnStack()
+	cfnStack.Spec.PostBuild = &cfnv1.PostBuild{}
+	cfnStack.Spec.StackParameters[0].Value = "${env}-bucket"
+	cfnStack.Spec.StackTags[0].Value = "${team:=payments}-${env}"
+
+	require.NoError(t, SubstituteStackSpec(&cfnStack, map[string]string{"env": "prod"}))
+	require.Equal(t, "prod-bucket", cfnStack.Spec.StackParameters[0].Value)
+	require.Equal(t, "payments-prod", cfnStack.Spec.StackTags[0].Value)
+
+	require.ErrorIs(t, ValidateVariables(map[string]string{"not-valid": "value"}), ErrSubstitutionFailed)
+}
@@ -0,0 +1,78 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT-0
+
+package build
+
+import (
+	"errors"
+	"fmt"
+
+	cfnv1 "github.com/awslabs/aws-cloudformation-controller-for-flux/api/v1alpha1"
+	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/substitute"
+	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/template"
+)
+
+// ErrSubstitutionFailed occurs when the post-build variables cannot be loaded or substituted.
+var ErrSubstitutionFailed = errors.New("variable substitution failed")
+
+// SubstituteStackSpec substitutes the post-build variables in the stack parameter values, stack tag values
+// and template patches.
+func SubstituteStackSpec(cfnStack *cfnv1.CloudFormationStack, vars map[string]string) error {
+	if vars == nil {
+		return nil
+	}
+	strict := cfnStack.Spec.PostBuild.Strict
+
+	for i, param := range cfnStack.Spec.StackParameters {
+		value, err := substitute.Substitute(param.Value, vars, strict)
+		if err != nil {
+			return fmt.Errorf("%w in stack parameter '%s': %w", ErrSubstitutionFailed, param.Key, err)
+		}
+		cfnStack.Spec.StackParameters[i].Value = value
+	}
+
+	for i, tag := range cfnStack.Spec.StackTags {
+		value, err := substitute.Substitute(tag.Value, vars, strict)
+		if err != nil {
+			return fmt.Errorf("%w in stack tag '%s': %w", ErrSubstitutionFailed, tag.Key, err)
+		}
+		cfnStack.Spec.StackTags[i].Value = value
+	}
+
+	for i, patch := range cfnStack.Spec.Patches {
+		value, err := substitute.Substitute(patch.Patch, vars, strict)
+		if err != nil {
+			return fmt.Errorf("%w in template patch %d: %w", ErrSubstitutionFailed, i, err)
+		}
+		cfnStack.Spec.Patches[i].Patch = value
+	}
+
+	return nil
+}
+
+// SubstituteTemplateTransform returns a template transform that substitutes the post-build variables
+// in the stack's template files, or nil if the stack does not specify any post-build substitutions.
+func SubstituteTemplateTransform(cfnStack cfnv1.CloudFormationStack, vars map[string]string) template.Transform {
+	if vars == nil {
+		return nil
+	}
+	strict := cfnStack.Spec.PostBuild.Strict
+
+	return func(templatePath string, body []byte) ([]byte, error) {
+		substituted, err := substitute.Substitute(string(body), vars, strict)
+		if err != nil {
+			return nil, fmt.Errorf("%w in template '%s': %w", ErrSubstitutionFailed, templatePath, err)
+		}
+		return []byte(substituted), nil
+	}
+}
+
+// ValidateVariables returns an error if any of the post-build variables has an invalid name.
+func ValidateVariables(vars map[string]string) error {
+	for name := range vars {
+		if err := substitute.ValidateVariableName(name); err != nil {
+			return fmt.Errorf("%w: %w", ErrSubstitutionFailed, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT-0
+
+package build
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	securejoin "github.com/cyphar/filepath-securejoin"
+
+	cfnv1 "github.com/awslabs/aws-cloudformation-controller-for-flux/api/v1alpha1"
+	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/cdk"
+	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/template"
+)
+
+// LoadTemplate loads the template file at the given path in the extracted artifact directory, and any nested
+// template files it references. If the stack is deployed from a CDK cloud assembly, the stack's synthesized
+// template, parameters and file assets are loaded from the assembly instead. If transform is not nil,
+// it is applied to the contents of each template file.
+func LoadTemplate(artifactDir string, templatePath string, cdkAssembly *cfnv1.CDKAssembly, transform template.Transform) (*template.Template, error) {
+	// find the template file, and the stack's parameters and assets if the stack is deployed from a CDK cloud assembly
+	var cdkStack *cdk.Stack
+	if cdkAssembly != nil {
+		var err error
+		if cdkStack, err = cdk.LoadStack(artifactDir, cdkAssembly.Path, cdkAssembly.StackID); err != nil {
+			return nil, err
+		}
+		templatePath = cdkStack.TemplatePath
+	}
+
+	// load the template file
+	templateFilePath, err := securejoin.SecureJoin(artifactDir, templatePath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to join securely the artifact temp directory with template path '%s'", templatePath)
+	}
+
+	templateBytes, err := os.ReadFile(filepath.Clean(templateFilePath))
+	if err != nil {
+		return nil, fmt.Errorf("unable to read template file '%s' in the artifact temp directory", templatePath)
+	}
+
+	if transform != nil {
+		if templateBytes, err = transform(templatePath, templateBytes); err != nil {
+			return nil, err
+		}
+	}
+
+	tmpl, err := template.Parse(templatePath, templateBytes)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse template file '%s'", templatePath)
+	}
+
+	// load the nested template files referenced by the template
+	if err := tmpl.LoadNested(artifactDir, transform); err != nil {
+		return nil, fmt.Errorf("unable to load nested templates for template file '%s': %w", templatePath, err)
+	}
+
+	if cdkStack != nil {
+		tmpl.Parameters = cdkStack.Parameters
+		for _, asset := range cdkStack.Assets {
+			tmpl.Assets = append(tmpl.Assets, &template.Asset{Keys: asset.Keys, Body: asset.Body})
+		}
+	}
+
+	return tmpl, nil
+}
+
+// TemplatePatches converts the stack's patches into template patches
+func TemplatePatches(cfnStack *cfnv1.CloudFormationStack) []template.Patch {
+	var patches []template.Patch
+	for _, patch := range cfnStack.Spec.Patches {
+		templatePatch := template.Patch{Patch: patch.Patch}
+		if patch.Target != nil {
+			templatePatch.LogicalID = patch.Target.LogicalID
+			templatePatch.Type = patch.Target.Type
+		}
+		patches = append(patches, templatePatch)
+	}
+	return patches
+}
@@ -0,0 +1,285 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT-0
+
+// Package cdk reads the stacks and file assets synthesized by the AWS CDK from a cloud assembly directory (cdk.out).
+package cdk
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"time"
+
+	securejoin "github.com/cyphar/filepath-securejoin"
+)
+
+const (
+	manifestFileName = "manifest.json"
+
+	stackArtifactType         = "aws:cloudformation:stack"
+	assetManifestArtifactType = "cdk:asset-manifest"
+
+	filePackaging = "file"
+	zipPackaging  = "zip"
+)
+
+// Stack is a CloudFormation stack synthesized in a cloud assembly.
+type Stack struct {
+	// ID is the artifact ID of the stack in the cloud assembly manifest.
+	ID string
+
+	// TemplatePath is the path of the stack's template file, relative to the root of the source artifact.
+	TemplatePath string
+
+	// Parameters contains the parameter values that were synthesized for the stack.
+	Parameters map[string]string
+
+	// Assets contains the file assets that the stack's template references.
+	Assets []*Asset
+}
+
+// Asset is a file asset that is published to S3 before the stack is deployed.
+type Asset struct {
+	// ID is the ID of the asset in the asset manifest, usually the hash of the asset source.
+	ID string
+
+	// Keys contains the S3 object keys that the stack's template expects the asset to be published at.
+	Keys []string
+
+	// Body is the contents of the asset file, or the zip archive of the asset directory.
+	Body []byte
+}
+
+type manifest struct {
+	Artifacts map[string]manifestArtifact `json:"artifacts"`
+}
+
+type manifestArtifact struct {
+	Type         string          `json:"type"`
+	Properties   json.RawMessage `json:"properties"`
+	Dependencies []string        `json:"dependencies"`
+}
+
+type stackProperties struct {
+	TemplateFile string            `json:"templateFile"`
+	Parameters   map[string]string `json:"parameters"`
+}
+
+type assetManifestProperties struct {
+	File string `json:"file"`
+}
+
+type assetManifest struct {
+	Files        map[string]fileAsset       `json:"files"`
+	DockerImages map[string]json.RawMessage `json:"dockerImages"`
+}
+
+type fileAsset struct {
+	Source struct {
+		Path       string   `json:"path"`
+		Packaging  string   `json:"packaging"`
+		Executable []string `json:"executable"`
+	} `json:"source"`
+	Destinations map[string]struct {
+		ObjectKey string `json:"objectKey"`
+	} `json:"destinations"`
+}
+
+// LoadStack reads the cloud assembly in the given directory of the artifact, and loads the stack
+// with the given artifact ID and the file assets listed in the stack's asset manifests.
+// Container image assets and assets that are built by running a command are not supported.
+func LoadStack(artifactDir string, assemblyPath string, stackID string) (*Stack, error) {
+	assemblyPath = path.Clean(filepath.ToSlash(assemblyPath))
+	assemblyDir, err := securejoin.SecureJoin(artifactDir, assemblyPath)
+	if err != nil {
+		return nil, fmt.Errorf("resolve cloud assembly '%s': %w", assemblyPath, err)
+	}
+
+	var m manifest
+	if err := readJSON(assemblyDir, manifestFileName, &m); err != nil {
+		return nil, fmt.Errorf("read cloud assembly '%s': %w", assemblyPath, err)
+	}
+
+	artifact, ok := m.Artifacts[stackID]
+	if !ok || artifact.Type != stackArtifactType {
+		return nil, fmt.Errorf("stack '%s' not found in cloud assembly '%s'", stackID, assemblyPath)
+	}
+	var props stackProperties
+	if err := json.Unmarshal(artifact.Properties, &props); err != nil {
+		return nil, fmt.Errorf("read properties of stack '%s': %w", stackID, err)
+	}
+	if props.TemplateFile == "" {
+		return nil, fmt.Errorf("stack '%s' does not have a template file", stackID)
+	}
+
+	stack := &Stack{
+		ID:           stackID,
+		TemplatePath: path.Join(assemblyPath, props.TemplateFile),
+		Parameters:   props.Parameters,
+	}
+
+	for _, dependency := range artifact.Dependencies {
+		dependencyArtifact, ok := m.Artifacts[dependency]
+		if !ok || dependencyArtifact.Type != assetManifestArtifactType {
+			continue
+		}
+		var assetProps assetManifestProperties
+		if err := json.Unmarshal(dependencyArtifact.Properties, &assetProps); err != nil {
+			return nil, fmt.Errorf("read properties of asset manifest '%s': %w", dependency, err)
+		}
+		assets, err := loadAssets(assemblyDir, assetProps.File)
+		if err != nil {
+			return nil, fmt.Errorf("load assets of stack '%s': %w", stackID, err)
+		}
+		stack.Assets = append(stack.Assets, assets...)
+	}
+
+	return stack, nil
+}
+
+func loadAssets(assemblyDir string, manifestFile string) ([]*Asset, error) {
+	var m assetManifest
+	if err := readJSON(assemblyDir, manifestFile, &m); err != nil {
+		return nil, err
+	}
+	if len(m.DockerImages) > 0 {
+		return nil, fmt.Errorf("asset manifest '%s' contains container image assets, which are not supported", manifestFile)
+	}
+
+	ids := make([]string, 0, len(m.Files))
+	for id := range m.Files {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	var assets []*Asset
+	for _, id := range ids {
+		file := m.Files[id]
+		if len(file.Source.Executable) > 0 || file.Source.Path == "" {
+			return nil, fmt.Errorf("asset '%s' is not built from a file or directory, which is not supported", id)
+		}
+
+		asset := &Asset{ID: id}
+		for _, destination := range file.Destinations {
+			if destination.ObjectKey != "" && !contains(asset.Keys, destination.ObjectKey) {
+				asset.Keys = append(asset.Keys, destination.ObjectKey)
+			}
+		}
+		sort.Strings(asset.Keys)
+
+		body, err := readAsset(assemblyDir, file.Source.Path, file.Source.Packaging)
+		if err != nil {
+			return nil, fmt.Errorf("read asset '%s': %w", id, err)
+		}
+		asset.Body = body
+		assets = append(assets, asset)
+	}
+	return assets, nil
+}
+
+// readAsset returns the contents of a file asset, or a zip archive of a directory asset
+func readAsset(assemblyDir string, sourcePath string, packaging string) ([]byte, error) {
+	fullPath, err := securejoin.SecureJoin(assemblyDir, sourcePath)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(fullPath)
+	if err != nil {
+		return nil, err
+	}
+
+	switch packaging {
+	case filePackaging, "":
+		if info.IsDir() {
+			return nil, fmt.Errorf("'%s' is a directory", sourcePath)
+		}
+		return os.ReadFile(fullPath)
+	case zipPackaging:
+		if !info.IsDir() {
+			// The asset is already an archive
+			return os.ReadFile(fullPath)
+		}
+		return zipDirectory(fullPath)
+	}
+	return nil, fmt.Errorf("unsupported packaging '%s'", packaging)
+}
+
+// zipDirectory creates a zip archive of the directory. The archive only depends on the names and contents
+// of the files in the directory, so that the same directory always produces the same archive.
+func zipDirectory(dir string) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := zip.NewWriter(&buf)
+
+	err := filepath.WalkDir(dir, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		if !entry.Type().IsRegular() {
+			return fmt.Errorf("'%s' is not a regular file", entry.Name())
+		}
+		name, err := filepath.Rel(dir, filePath)
+		if err != nil {
+			return err
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		header := &zip.FileHeader{
+			Name:     filepath.ToSlash(name),
+			Method:   zip.Deflate,
+			Modified: time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC),
+		}
+		header.SetMode(info.Mode())
+		w, err := writer.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+		f, err := os.Open(filePath)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(w, f)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func readJSON(dir string, name string, v interface{}) error {
+	fullPath, err := securejoin.SecureJoin(dir, name)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(fullPath)
+	if err != nil {
+		return fmt.Errorf("read '%s': %w", name, err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("parse '%s': %w", name, err)
+	}
+	return nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
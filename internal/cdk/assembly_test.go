@@ -0,0 +1,199 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT-0
+
+package cdk
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	mockManifest = `{
+  "version": "36.0.0",
+  "artifacts": {
+    "MyStack.assets": {
+      "type": "cdk:asset-manifest",
+      "properties": {
+        "file": "MyStack.assets.json",
+        "requiresBootstrapStackVersion": 6
+      }
+    },
+    "MyStack": {
+      "type": "aws:cloudformation:stack",
+      "environment": "aws://unknown-account/unknown-region",
+      "properties": {
+        "templateFile": "MyStack.template.json",
+        "parameters": {
+          "Environment": "prod"
+        }
+      },
+      "dependencies": [
+        "MyStack.assets"
+      ]
+    },
+    "Tree": {
+      "type": "cdk:tree",
+      "properties": {
+        "file": "tree.json"
+      }
+    }
+  }
+}`
+
+	mockAssetManifest = `{
+  "version": "36.0.0",
+  "files": {
+    "1111": {
+      "source": {
+        "path": "asset.1111",
+        "packaging": "zip"
+      },
+      "destinations": {
+        "current_account-current_region": {
+          "bucketName": "my-template-bucket",
+          "objectKey": "1111.zip"
+        }
+      }
+    },
+    "2222": {
+      "source": {
+        "path": "MyStack.template.json",
+        "packaging": "file"
+      },
+      "destinations": {
+        "current_account-current_region": {
+          "bucketName": "my-template-bucket",
+          "objectKey": "2222.json"
+        }
+      }
+    }
+  },
+  "dockerImages": {}
+}`
+
+	mockStackTemplate = `{"Resources": {"Function": {"Type": "AWS::Lambda::Function"}}}`
+)
+
+func writeAssembly(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, contents := range files {
+		filePath := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(filePath), 0o755))
+		require.NoError(t, os.WriteFile(filePath, []byte(contents), 0o644))
+	}
+	return dir
+}
+
+func TestLoadStack(t *testing.T) {
+	dir := writeAssembly(t, map[string]string{
+		"cdk.out/manifest.json":             mockManifest,
+		"cdk.out/MyStack.assets.json":       mockAssetManifest,
+		"cdk.out/MyStack.template.json":     mockStackTemplate,
+		"cdk.out/asset.1111/index.js":       "exports.handler = async () => {};",
+		"cdk.out/asset.1111/lib/helpers.js": "module.exports = {};",
+	})
+
+	stack, err := LoadStack(dir, "cdk.out", "MyStack")
+	require.NoError(t, err)
+	require.Equal(t, "MyStack", stack.ID)
+	require.Equal(t, "cdk.out/MyStack.template.json", stack.TemplatePath)
+	require.Equal(t, map[string]string{"Environment": "prod"}, stack.Parameters)
+
+	require.Len(t, stack.Assets, 2)
+	require.Equal(t, "1111", stack.Assets[0].ID)
+	require.Equal(t, []string{"1111.zip"}, stack.Assets[0].Keys)
+	require.Equal(t, "2222", stack.Assets[1].ID)
+	require.Equal(t, []string{"2222.json"}, stack.Assets[1].Keys)
+	require.Equal(t, mockStackTemplate, string(stack.Assets[1].Body))
+
+	// Directory assets are zipped
+	archive, err := zip.NewReader(bytes.NewReader(stack.Assets[0].Body), int64(len(stack.Assets[0].Body)))
+	require.NoError(t, err)
+	require.Len(t, archive.File, 2)
+	require.Equal(t, "index.js", archive.File[0].Name)
+	require.Equal(t, "lib/helpers.js", archive.File[1].Name)
+	f, err := archive.File[0].Open()
+	require.NoError(t, err)
+	contents, err := io.ReadAll(f)
+	require.NoError(t, err)
+	require.Equal(t, "exports.handler = async () => {};", string(contents))
+
+	// Zipping the same directory produces the same archive
+	again, err := LoadStack(dir, "cdk.out", "MyStack")
+	require.NoError(t, err)
+	require.Equal(t, stack.Assets[0].Body, again.Assets[0].Body)
+}
+
+func TestLoadStackAtArtifactRoot(t *testing.T) {
+	dir := writeAssembly(t, map[string]string{
+		"manifest.json":         `{"artifacts": {"MyStack": {"type": "aws:cloudformation:stack", "properties": {"templateFile": "MyStack.template.json"}}}}`,
+		"MyStack.template.json": mockStackTemplate,
+	})
+
+	stack, err := LoadStack(dir, "", "MyStack")
+	require.NoError(t, err)
+	require.Equal(t, "MyStack.template.json", stack.TemplatePath)
+	require.Empty(t, stack.Assets)
+}
+
+func TestLoadStackFailures(t *testing.T) {
+	for name, tc := range map[string]struct {
+		files   map[string]string
+		stackID string
+		wantErr string
+	}{
+		"missing manifest": {
+			files:   map[string]string{},
+			stackID: "MyStack",
+			wantErr: "read cloud assembly 'cdk.out': read 'manifest.json'",
+		},
+		"missing stack": {
+			files:   map[string]string{"cdk.out/manifest.json": mockManifest},
+			stackID: "OtherStack",
+			wantErr: "stack 'OtherStack' not found in cloud assembly 'cdk.out'",
+		},
+		"not a stack": {
+			files:   map[string]string{"cdk.out/manifest.json": mockManifest},
+			stackID: "Tree",
+			wantErr: "stack 'Tree' not found in cloud assembly 'cdk.out'",
+		},
+		"container image assets": {
+			files: map[string]string{
+				"cdk.out/manifest.json":       mockManifest,
+				"cdk.out/MyStack.assets.json": `{"files": {}, "dockerImages": {"3333": {}}}`,
+			},
+			stackID: "MyStack",
+			wantErr: "load assets of stack 'MyStack': asset manifest 'MyStack.assets.json' contains container image assets, which are not supported",
+		},
+		"executable assets": {
+			files: map[string]string{
+				"cdk.out/manifest.json":       mockManifest,
+				"cdk.out/MyStack.assets.json": `{"files": {"4444": {"source": {"executable": ["build.sh"]}}}}`,
+			},
+			stackID: "MyStack",
+			wantErr: "load assets of stack 'MyStack': asset '4444' is not built from a file or directory, which is not supported",
+		},
+		"missing asset": {
+			files: map[string]string{
+				"cdk.out/manifest.json":       mockManifest,
+				"cdk.out/MyStack.assets.json": mockAssetManifest,
+			},
+			stackID: "MyStack",
+			wantErr: "load assets of stack 'MyStack': read asset '1111'",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			dir := writeAssembly(t, tc.files)
+			_, err := LoadStack(dir, "cdk.out", tc.stackID)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.wantErr)
+		})
+	}
+}
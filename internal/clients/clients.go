@@ -4,6 +4,7 @@
 package clients
 
 import (
+	"context"
 	"io"
 
 	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/clients/types"
@@ -11,18 +12,49 @@ import (
 
 type CloudFormationClient interface {
 	// Stack methods
-	CreateStack(stack *types.Stack) (changeSetArn string, err error)
-	UpdateStack(stack *types.Stack) (changeSetArn string, err error)
-	DescribeStack(stack *types.Stack) (*types.StackDescription, error)
-	DeleteStack(stack *types.Stack) error
-	ContinueStackRollback(stack *types.Stack) error
+	CreateStack(ctx context.Context, stack *types.Stack) (changeSetArn string, err error)
+	UpdateStack(ctx context.Context, stack *types.Stack) (changeSetArn string, err error)
+	DescribeStack(ctx context.Context, stack *types.Stack) (*types.StackDescription, error)
+	DescribeStackResources(ctx context.Context, stack *types.Stack) ([]types.StackResource, error)
+	DeleteStack(ctx context.Context, stack *types.Stack) error
+	ContinueStackRollback(ctx context.Context, stack *types.Stack) error
 
 	// Change set methods
-	ExecuteChangeSet(stack *types.Stack) error
-	DeleteChangeSet(stack *types.Stack) error
-	DescribeChangeSet(stack *types.Stack) (*types.ChangeSetDescription, error)
+	ExecuteChangeSet(ctx context.Context, stack *types.Stack) error
+	DeleteChangeSet(ctx context.Context, stack *types.Stack) error
+	DescribeChangeSet(ctx context.Context, stack *types.Stack) (*types.ChangeSetDescription, error)
+}
+
+// CloudFormationStackCache caches the descriptions of the stacks, to describe many stacks with few API calls.
+type CloudFormationStackCache interface {
+	DescribeStack(ctx context.Context, stack *types.Stack) (*types.StackDescription, error)
+	StoreStack(stack *types.Stack, desc *types.StackDescription)
+}
+
+type CloudFormationStackSetClient interface {
+	// Stack set methods
+	CreateStackSet(ctx context.Context, stackSet *types.StackSet) error
+	UpdateStackSet(ctx context.Context, stackSet *types.StackSet) (operationID string, err error)
+	DescribeStackSet(ctx context.Context, stackSet *types.StackSet) (*types.StackSetDescription, error)
+	DeleteStackSet(ctx context.Context, stackSet *types.StackSet) error
+
+	// Stack instance methods
+	ListStackInstances(ctx context.Context, stackSet *types.StackSet) ([]*types.StackInstanceSummary, error)
+	CreateStackInstances(ctx context.Context, stackSet *types.StackSet, targets *types.StackInstanceTargets) (operationID string, err error)
+	DeleteStackInstances(ctx context.Context, stackSet *types.StackSet, targets *types.StackInstanceTargets, retainStacks bool) (operationID string, err error)
+
+	// Stack set operation methods
+	DescribeStackSetOperation(ctx context.Context, stackSet *types.StackSet, operationID string) (*types.StackSetOperationDescription, error)
+}
+
+type CloudFormationExportClient interface {
+	ListStacks(ctx context.Context) ([]*types.StackDescription, error)
+	GetTemplate(ctx context.Context, stack *types.Stack) (string, error)
 }
 
 type S3Client interface {
-	UploadTemplate(bucket, region, key string, data io.Reader) (string, error)
+	UploadTemplate(ctx context.Context, bucket, region, key string, data io.Reader, opts *types.TemplateBucketOptions) (string, error)
+	HeadTemplate(ctx context.Context, bucket, region, key string, opts *types.TemplateBucketOptions) (*types.TemplateObject, error)
+	ListTemplates(ctx context.Context, bucket, region, prefix string, opts *types.TemplateBucketOptions) ([]*types.TemplateObject, error)
+	DeleteTemplates(ctx context.Context, bucket, region string, keys []string, opts *types.TemplateBucketOptions) error
 }
@@ -16,14 +16,27 @@ import (
 )
 
 const (
-	// The change set name will be formatted as "flux-<generation sequence number>".
+	// The change set name will be formatted as "flux-<generation>-<digest>", where the digest is the first 16 hex
+	// characters of the SHA-256 digest of the stack's source digest, config digest and last applied stack update time.
 	fmtChangeSetName       = "flux-%d-%s"
 	maxLengthChangeSetName = 128
+
+	// MaxTemplateBodySize is the maximum size in bytes of a template that can be passed
+	// directly to CloudFormation in the TemplateBody parameter.
+	// Larger templates must be uploaded to S3 and passed in the TemplateURL parameter.
+	MaxTemplateBodySize = 51200
 )
 
 var (
 	// all except alphanumeric characters
 	changeSetNameSpecialChars, _ = regexp.Compile("[^a-zA-Z0-9]+")
+
+	// ChangeSetCapabilities are the capabilities that the change sets acknowledge.
+	ChangeSetCapabilities = []sdktypes.Capability{
+		sdktypes.CapabilityCapabilityIam,
+		sdktypes.CapabilityCapabilityNamedIam,
+		sdktypes.CapabilityCapabilityAutoExpand,
+	}
 )
 
 type changeSet struct {
@@ -32,20 +45,18 @@ type changeSet struct {
 	region    string
 	csType    sdktypes.ChangeSetType
 	client    changeSetAPI
-	ctx       context.Context
 }
 
 // GetChangeSetName generates a unique change set name using the generation number
-// (a specific version of the CloudFormationStack Spec contents) and the source
-// revision (such as the branch and commit ID for git sources).
+// (a specific version of the CloudFormationStack Spec contents) and a digest of
+// the stack's source contents (the template, nested templates, assets and generated parameters),
+// its deployment inputs (the parameters, tags and template bucket options) and the stack update time
+// recorded when the last change set was applied, so that source revisions that do not change the
+// source contents or deployment inputs share the same change set.
 //
-// Examples:
-//
-//	Git repository: main@sha1:132f4e719209eb10b9485302f8593fc0e680f4fc
-//	Bucket: sha256:e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855
-//	OCI repository: latest@sha256:3b6cdcc7adcc9a84d3214ee1c029543789d90b5ae69debe9efa3f66e982875de
-func GetChangeSetName(generation int64, sourceRevision string) string {
-	name := fmt.Sprintf(fmtChangeSetName, generation, sourceRevision)
+// Example: flux-2-5f0c1e2b9a7d4c36
+func GetChangeSetName(generation int64, digest string) string {
+	name := fmt.Sprintf(fmtChangeSetName, generation, digest)
 	name = changeSetNameSpecialChars.ReplaceAllString(name, "-")
 	if len(name) <= maxLengthChangeSetName {
 		return name
@@ -64,25 +75,23 @@ func ExtractChangeSetName(arn string) string {
 	return arnParts[1]
 }
 
-func newCreateChangeSet(ctx context.Context, cfnClient changeSetAPI, region string, stackName string, generation int64, sourceRevision string) *changeSet {
+func newCreateChangeSet(cfnClient changeSetAPI, region string, stackName string, name string) *changeSet {
 	return &changeSet{
-		name:      GetChangeSetName(generation, sourceRevision),
+		name:      name,
 		stackName: stackName,
 		region:    region,
 		csType:    sdktypes.ChangeSetTypeCreate,
 		client:    cfnClient,
-		ctx:       ctx,
 	}
 }
 
-func newUpdateChangeSet(ctx context.Context, cfnClient changeSetAPI, region string, stackName string, generation int64, sourceRevision string) *changeSet {
+func newUpdateChangeSet(cfnClient changeSetAPI, region string, stackName string, name string) *changeSet {
 	return &changeSet{
-		name:      GetChangeSetName(generation, sourceRevision),
+		name:      name,
 		stackName: stackName,
 		region:    region,
 		csType:    sdktypes.ChangeSetTypeUpdate,
 		client:    cfnClient,
-		ctx:       ctx,
 	}
 }
 
@@ -91,21 +100,22 @@ func (cs *changeSet) String() string {
 }
 
 // create creates a ChangeSet, waits until it's created, and returns the change set ARN on success.
-func (cs *changeSet) create(conf *types.StackConfig) (string, error) {
+// The template is passed by URL if it was uploaded to S3, otherwise the template body is passed inline.
+func (cs *changeSet) create(ctx context.Context, conf *types.StackConfig) (string, error) {
 	input := &cloudformation.CreateChangeSetInput{
 		ChangeSetName:       aws.String(cs.name),
 		StackName:           aws.String(cs.stackName),
 		Description:         aws.String("Managed by Flux"),
 		ChangeSetType:       cs.csType,
-		TemplateURL:         aws.String(conf.TemplateURL),
 		Parameters:          conf.Parameters,
 		Tags:                conf.Tags,
 		IncludeNestedStacks: aws.Bool(true),
-		Capabilities: []sdktypes.Capability{
-			sdktypes.CapabilityCapabilityIam,
-			sdktypes.CapabilityCapabilityNamedIam,
-			sdktypes.CapabilityCapabilityAutoExpand,
-		},
+		Capabilities:        ChangeSetCapabilities,
+	}
+	if conf.TemplateURL != "" {
+		input.TemplateURL = aws.String(conf.TemplateURL)
+	} else {
+		input.TemplateBody = aws.String(conf.TemplateBody)
 	}
 
 	opts := func(opts *cloudformation.Options) {
@@ -114,7 +124,7 @@ func (cs *changeSet) create(conf *types.StackConfig) (string, error) {
 		}
 	}
 
-	out, err := cs.client.CreateChangeSet(cs.ctx, input, opts)
+	out, err := cs.client.CreateChangeSet(ctx, input, opts)
 	if err != nil {
 		return "", fmt.Errorf("create %s: %w", cs, err)
 	}
@@ -122,7 +132,7 @@ func (cs *changeSet) create(conf *types.StackConfig) (string, error) {
 }
 
 // describe collects all the changes and statuses that the change set will apply and returns them.
-func (cs *changeSet) describe() (*types.ChangeSetDescription, error) {
+func (cs *changeSet) describe(ctx context.Context) (*types.ChangeSetDescription, error) {
 	var arn string
 	var status sdktypes.ChangeSetStatus
 	var executionStatus sdktypes.ExecutionStatus
@@ -130,7 +140,7 @@ func (cs *changeSet) describe() (*types.ChangeSetDescription, error) {
 	var changes []sdktypes.Change
 	var nextToken *string
 	for {
-		out, err := cs.client.DescribeChangeSet(cs.ctx, &cloudformation.DescribeChangeSetInput{
+		out, err := cs.client.DescribeChangeSet(ctx, &cloudformation.DescribeChangeSetInput{
 			ChangeSetName: aws.String(cs.name),
 			StackName:     aws.String(cs.stackName),
 			NextToken:     nextToken,
@@ -165,8 +175,8 @@ func (cs *changeSet) describe() (*types.ChangeSetDescription, error) {
 }
 
 // execute executes a created change set.
-func (cs *changeSet) execute() error {
-	_, err := cs.client.ExecuteChangeSet(cs.ctx, &cloudformation.ExecuteChangeSetInput{
+func (cs *changeSet) execute(ctx context.Context) error {
+	_, err := cs.client.ExecuteChangeSet(ctx, &cloudformation.ExecuteChangeSetInput{
 		ChangeSetName: aws.String(cs.name),
 		StackName:     aws.String(cs.stackName),
 	}, func(opts *cloudformation.Options) {
@@ -181,8 +191,8 @@ func (cs *changeSet) execute() error {
 }
 
 // delete removes the change set.
-func (cs *changeSet) delete() error {
-	_, err := cs.client.DeleteChangeSet(cs.ctx, &cloudformation.DeleteChangeSetInput{
+func (cs *changeSet) delete(ctx context.Context) error {
+	_, err := cs.client.DeleteChangeSet(ctx, &cloudformation.DeleteChangeSetInput{
 		ChangeSetName: aws.String(cs.name),
 		StackName:     aws.String(cs.stackName),
 	}, func(opts *cloudformation.Options) {
@@ -9,11 +9,8 @@ import (
 	"fmt"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
-	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
-	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
 	sdktypes "github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
-	"github.com/aws/smithy-go/middleware"
 	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/clients"
 	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/clients/types"
 )
@@ -21,40 +18,57 @@ import (
 // CloudFormation represents a client to make requests to AWS CloudFormation.
 type CloudFormation struct {
 	client
-	ctx context.Context
 }
 
 // New creates a new CloudFormation client.
-func New(ctx context.Context, region string) (clients.CloudFormationClient, error) {
-	cfg, err := config.LoadDefaultConfig(
-		ctx,
-		config.WithAPIOptions([]func(*middleware.Stack) error{
-			awsmiddleware.AddUserAgentKey("cfn-flux-controller"),
-		}),
-		config.WithRegion(region),
-	)
+func New(ctx context.Context, region string, opts types.ClientOptions) (clients.CloudFormationClient, error) {
+	c, err := newCloudFormation(ctx, region, opts)
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// NewStackSetClient creates a new CloudFormation client for stack sets.
+func NewStackSetClient(ctx context.Context, region string, opts types.ClientOptions) (clients.CloudFormationStackSetClient, error) {
+	c, err := newCloudFormation(ctx, region, opts)
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// NewExportClient creates a new CloudFormation client for exporting existing stacks.
+func NewExportClient(ctx context.Context, region string) (clients.CloudFormationExportClient, error) {
+	c, err := newCloudFormation(ctx, region, types.ClientOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func newCloudFormation(ctx context.Context, region string, opts types.ClientOptions) (*CloudFormation, error) {
+	cfg, err := clients.LoadConfig(ctx, region, opts)
 	if err != nil {
 		return nil, err
 	}
 
 	return &CloudFormation{
 		client: cloudformation.NewFromConfig(cfg),
-		ctx:    ctx,
 	}, nil
 }
 
 // For passing a mock client in tests
-func NewWithClient(ctx context.Context, client client) *CloudFormation {
+func NewWithClient(client client) *CloudFormation {
 	return &CloudFormation{
 		client: client,
-		ctx:    ctx,
 	}
 }
 
 // Describe returns a description of an existing stack.
 // If the stack does not exist, returns ErrStackNotFound.
-func (c *CloudFormation) DescribeStack(stack *types.Stack) (*types.StackDescription, error) {
-	out, err := c.client.DescribeStacks(c.ctx, &cloudformation.DescribeStacksInput{
+func (c *CloudFormation) DescribeStack(ctx context.Context, stack *types.Stack) (*types.StackDescription, error) {
+	out, err := c.client.DescribeStacks(ctx, &cloudformation.DescribeStacksInput{
 		StackName: aws.String(stack.Name),
 	}, func(opts *cloudformation.Options) {
 		if stack.Region != "" {
@@ -83,18 +97,41 @@ func (c *CloudFormation) DescribeStack(stack *types.Stack) (*types.StackDescript
 	return &descr, nil
 }
 
+// DescribeStackResources returns the resources of an existing stack, up to the first 100 resources.
+// If the stack does not exist, returns ErrStackNotFound.
+func (c *CloudFormation) DescribeStackResources(ctx context.Context, stack *types.Stack) ([]types.StackResource, error) {
+	out, err := c.client.DescribeStackResources(ctx, &cloudformation.DescribeStackResourcesInput{
+		StackName: aws.String(stack.Name),
+	}, func(opts *cloudformation.Options) {
+		if stack.Region != "" {
+			opts.Region = stack.Region
+		}
+	})
+	if err != nil {
+		if stackDoesNotExist(err) {
+			return nil, &ErrStackNotFound{name: stack.Name}
+		}
+		return nil, fmt.Errorf("describe resources of stack %s: %w", stack.Name, err)
+	}
+	resources := make([]types.StackResource, 0, len(out.StackResources))
+	for _, resource := range out.StackResources {
+		resources = append(resources, types.StackResource(resource))
+	}
+	return resources, nil
+}
+
 // DescribeChangeSet gathers and returns all changes for the stack's current change set.
 // If the stack or changeset does not exist, returns ErrChangeSetNotFound.
-func (c *CloudFormation) DescribeChangeSet(stack *types.Stack) (*types.ChangeSetDescription, error) {
+func (c *CloudFormation) DescribeChangeSet(ctx context.Context, stack *types.Stack) (*types.ChangeSetDescription, error) {
 	var changeSetName string
 	if stack.ChangeSetArn != "" {
 		changeSetName = stack.ChangeSetArn
 	} else {
-		changeSetName = GetChangeSetName(stack.Generation, stack.SourceRevision)
+		changeSetName = stack.ChangeSetName
 	}
-	cs := &changeSet{name: changeSetName, stackName: stack.Name, region: stack.Region, client: c.client, ctx: c.ctx}
+	cs := &changeSet{name: changeSetName, stackName: stack.Name, region: stack.Region, client: c.client}
 
-	out, err := cs.describe()
+	out, err := cs.describe(ctx)
 	if err != nil {
 		if changeSetDoesNotExist(err) || stackDoesNotExist(err) {
 			return nil, &ErrChangeSetNotFound{name: changeSetName, stackName: stack.Name}
@@ -119,9 +156,9 @@ func (c *CloudFormation) DescribeChangeSet(stack *types.Stack) (*types.ChangeSet
 
 // CreateStack begins the process of deploying a new CloudFormation stack by creating a change set.
 // The change set must be executed when it is successfully created.
-func (c *CloudFormation) CreateStack(stack *types.Stack) (changeSetArn string, err error) {
-	cs := newCreateChangeSet(c.ctx, c.client, stack.Region, stack.Name, stack.Generation, stack.SourceRevision)
-	arn, err := cs.create(stack.StackConfig)
+func (c *CloudFormation) CreateStack(ctx context.Context, stack *types.Stack) (changeSetArn string, err error) {
+	cs := newCreateChangeSet(c.client, stack.Region, stack.Name, stack.ChangeSetName)
+	arn, err := cs.create(ctx, stack.StackConfig)
 	if err != nil {
 		return "", err
 	}
@@ -132,9 +169,9 @@ func (c *CloudFormation) CreateStack(stack *types.Stack) (changeSetArn string, e
 // UpdateStack begins the process of updating an existing CloudFormation stack with new configuration
 // by creating a change set.
 // The change set must be executed when it is successfully created.
-func (c *CloudFormation) UpdateStack(stack *types.Stack) (changeSetArn string, err error) {
-	cs := newUpdateChangeSet(c.ctx, c.client, stack.Region, stack.Name, stack.Generation, stack.SourceRevision)
-	arn, err := cs.create(stack.StackConfig)
+func (c *CloudFormation) UpdateStack(ctx context.Context, stack *types.Stack) (changeSetArn string, err error) {
+	cs := newUpdateChangeSet(c.client, stack.Region, stack.Name, stack.ChangeSetName)
+	arn, err := cs.create(ctx, stack.StackConfig)
 	if err != nil {
 		return "", err
 	}
@@ -144,15 +181,15 @@ func (c *CloudFormation) UpdateStack(stack *types.Stack) (changeSetArn string, e
 
 // ExecutChangeSet starts the execution of the stack's current change set.
 // If the stack or changeset does not exist, returns ErrChangeSetNotFound.
-func (c *CloudFormation) ExecuteChangeSet(stack *types.Stack) error {
-	cs := &changeSet{name: stack.ChangeSetArn, stackName: stack.Name, region: stack.Region, client: c.client, ctx: c.ctx}
-	return cs.execute()
+func (c *CloudFormation) ExecuteChangeSet(ctx context.Context, stack *types.Stack) error {
+	cs := &changeSet{name: stack.ChangeSetArn, stackName: stack.Name, region: stack.Region, client: c.client}
+	return cs.execute(ctx)
 }
 
 // Delete removes an existing CloudFormation stack.
 // If the stack doesn't exist then do nothing.
-func (c *CloudFormation) DeleteStack(stack *types.Stack) error {
-	_, err := c.client.DeleteStack(c.ctx, &cloudformation.DeleteStackInput{
+func (c *CloudFormation) DeleteStack(ctx context.Context, stack *types.Stack) error {
+	_, err := c.client.DeleteStack(ctx, &cloudformation.DeleteStackInput{
 		StackName: aws.String(stack.Name),
 	}, func(opts *cloudformation.Options) {
 		if stack.Region != "" {
@@ -170,9 +207,9 @@ func (c *CloudFormation) DeleteStack(stack *types.Stack) error {
 
 // Delete removes an existing CloudFormation change set.
 // If the change set doesn't exist then do nothing.
-func (c *CloudFormation) DeleteChangeSet(stack *types.Stack) error {
-	cs := &changeSet{name: stack.ChangeSetArn, stackName: stack.Name, region: stack.Region, client: c.client, ctx: c.ctx}
-	if err := cs.delete(); err != nil {
+func (c *CloudFormation) DeleteChangeSet(ctx context.Context, stack *types.Stack) error {
+	cs := &changeSet{name: stack.ChangeSetArn, stackName: stack.Name, region: stack.Region, client: c.client}
+	if err := cs.delete(ctx); err != nil {
 		if !changeSetDoesNotExist(err) && !stackDoesNotExist(err) {
 			return err
 		}
@@ -182,8 +219,8 @@ func (c *CloudFormation) DeleteChangeSet(stack *types.Stack) error {
 }
 
 // ContinueStackRollback attempts to continue an Update rollback for an existing CloudFormation stack.
-func (c *CloudFormation) ContinueStackRollback(stack *types.Stack) error {
-	_, err := c.client.ContinueUpdateRollback(c.ctx, &cloudformation.ContinueUpdateRollbackInput{
+func (c *CloudFormation) ContinueStackRollback(ctx context.Context, stack *types.Stack) error {
+	_, err := c.client.ContinueUpdateRollback(ctx, &cloudformation.ContinueUpdateRollbackInput{
 		StackName: aws.String(stack.Name),
 	}, func(opts *cloudformation.Options) {
 		if stack.Region != "" {
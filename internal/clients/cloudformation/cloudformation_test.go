@@ -20,11 +20,9 @@ import (
 
 const (
 	mockStackName       = "mock-stack-1234"
-	mockChangeSetName   = "flux-2-main-sha1-132f4e719209eb10b9485302f8593fc0e680f4fc"
+	mockChangeSetName   = "flux-2-5f0c1e2b9a7d4c36"
 	mockChangeSetArn    = "arn:aws:cloudformation:us-west-2:111:changeSet/mock-31323334-3536-4738-b930-313233333435/9edc39b0-ee18-440d-823e-3dda74646b2"
 	mockRegion          = "mock-region"
-	mockGenerationId    = 2
-	mockSourceRevision  = "main@sha1:132f4e719209eb10b9485302f8593fc0e680f4fc"
 	mockBucket          = "mock-bucket"
 	mockTemplateContent = "hello world"
 	mockTemplateUrl     = "mock-url"
@@ -41,10 +39,9 @@ var (
 
 func generateMockStack() *types.Stack {
 	return &types.Stack{
-		Name:           mockStackName,
-		Region:         mockRegion,
-		Generation:     mockGenerationId,
-		SourceRevision: mockSourceRevision,
+		Name:          mockStackName,
+		Region:        mockRegion,
+		ChangeSetName: mockChangeSetName,
 		StackConfig: &types.StackConfig{
 			TemplateBucket: mockBucket,
 			TemplateBody:   mockTemplateContent,
@@ -152,11 +149,10 @@ func TestCloudFormation_DescribeStack(t *testing.T) {
 			defer ctrl.Finish()
 			c := CloudFormation{
 				client: tc.createMock(ctrl),
-				ctx:    ctx,
 			}
 
 			// WHEN
-			descr, err := c.DescribeStack(mockStack)
+			descr, err := c.DescribeStack(ctx, mockStack)
 
 			// THEN
 			require.Equal(t, tc.wantedDescr, descr)
@@ -165,6 +161,80 @@ func TestCloudFormation_DescribeStack(t *testing.T) {
 	}
 }
 
+func TestCloudFormation_DescribeStackResources(t *testing.T) {
+	testCases := map[string]struct {
+		createMock      func(ctrl *gomock.Controller) client
+		wantedResources []types.StackResource
+		wantedErr       error
+	}{
+		"return ErrStackNotFound if stack does not exist": {
+			createMock: func(ctrl *gomock.Controller) client {
+				m := mocks.NewMockclient(ctrl)
+				m.EXPECT().DescribeStackResources(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errDoesNotExist)
+				return m
+			},
+			wantedErr: &ErrStackNotFound{name: mockStackName},
+		},
+		"return wrapped error if describe call fails": {
+			createMock: func(ctrl *gomock.Controller) client {
+				m := mocks.NewMockclient(ctrl)
+				m.EXPECT().DescribeStackResources(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, genericApiError)
+				return m
+			},
+			wantedErr: fmt.Errorf("describe resources of stack %s: %w", mockStackName, genericApiError),
+		},
+		"return the stack resources": {
+			createMock: func(ctrl *gomock.Controller) client {
+				m := mocks.NewMockclient(ctrl)
+				expectedIn := &cloudformation.DescribeStackResourcesInput{
+					StackName: aws.String(mockStackName),
+				}
+				m.EXPECT().DescribeStackResources(gomock.Any(), gomock.Eq(expectedIn), gomock.Any()).Return(&cloudformation.DescribeStackResourcesOutput{
+					StackResources: []sdktypes.StackResource{
+						{
+							LogicalResourceId:  aws.String("Topic"),
+							PhysicalResourceId: aws.String("arn:aws:sns:us-west-2:111:topic"),
+							ResourceType:       aws.String("AWS::SNS::Topic"),
+							ResourceStatus:     sdktypes.ResourceStatusCreateComplete,
+						},
+					},
+				}, nil)
+				return m
+			},
+			wantedResources: []types.StackResource{
+				{
+					LogicalResourceId:  aws.String("Topic"),
+					PhysicalResourceId: aws.String("arn:aws:sns:us-west-2:111:topic"),
+					ResourceType:       aws.String("AWS::SNS::Topic"),
+					ResourceStatus:     sdktypes.ResourceStatusCreateComplete,
+				},
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			// GIVEN
+			ctrl, ctx := gomock.WithContext(context.Background(), t)
+			defer ctrl.Finish()
+			c := CloudFormation{
+				client: tc.createMock(ctrl),
+			}
+
+			// WHEN
+			resources, err := c.DescribeStackResources(ctx, generateMockStack())
+
+			// THEN
+			if tc.wantedErr != nil {
+				require.EqualError(t, err, tc.wantedErr.Error())
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, tc.wantedResources, resources)
+			}
+		})
+	}
+}
+
 func TestCloudFormation_DescribeChangeSet(t *testing.T) {
 	testCases := map[string]struct {
 		createMock         func(ctrl *gomock.Controller, mockStack *types.Stack) client
@@ -178,7 +248,7 @@ func TestCloudFormation_DescribeChangeSet(t *testing.T) {
 				m.EXPECT().DescribeChangeSet(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, genericApiError)
 				return m
 			},
-			wantedErr: fmt.Errorf("describe change set flux-2-main-sha1-132f4e719209eb10b9485302f8593fc0e680f4fc for stack mock-stack-1234: %w", genericApiError),
+			wantedErr: fmt.Errorf("describe change set flux-2-5f0c1e2b9a7d4c36 for stack mock-stack-1234: %w", genericApiError),
 		},
 		"return ErrChangeSetNotFound if stack does not exist": {
 			createMock: func(ctrl *gomock.Controller, mockStack *types.Stack) client {
@@ -310,11 +380,10 @@ func TestCloudFormation_DescribeChangeSet(t *testing.T) {
 			defer ctrl.Finish()
 			c := CloudFormation{
 				client: tc.createMock(ctrl, mockStack),
-				ctx:    ctx,
 			}
 
 			// WHEN
-			descr, err := c.DescribeChangeSet(mockStack)
+			descr, err := c.DescribeChangeSet(ctx, mockStack)
 
 			// THEN
 			require.Equal(t, tc.wantedDescr, descr)
@@ -375,11 +444,10 @@ func TestCloudFormation_DescribeChangeSet(t *testing.T) {
 
 		cfn := CloudFormation{
 			client: m,
-			ctx:    ctx,
 		}
 
 		// WHEN
-		out, err := cfn.DescribeChangeSet(mockStack)
+		out, err := cfn.DescribeChangeSet(ctx, mockStack)
 
 		// THEN
 		require.NoError(t, err)
@@ -400,7 +468,7 @@ func TestCloudFormation_CreateStack(t *testing.T) {
 				m.EXPECT().CreateChangeSet(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, genericApiError)
 				return m
 			},
-			wantedErr: fmt.Errorf("create change set flux-2-main-sha1-132f4e719209eb10b9485302f8593fc0e680f4fc for stack mock-stack-1234: %w", genericApiError),
+			wantedErr: fmt.Errorf("create change set flux-2-5f0c1e2b9a7d4c36 for stack mock-stack-1234: %w", genericApiError),
 		},
 		"creates the stack": {
 			inStack: generateMockStack(),
@@ -425,12 +493,41 @@ func TestCloudFormation_CreateStack(t *testing.T) {
 				return m
 			},
 		},
+		"creates the stack with an inline template body": {
+			inStack: &types.Stack{
+				Name:          mockStackName,
+				Region:        mockRegion,
+				ChangeSetName: mockChangeSetName,
+				StackConfig: &types.StackConfig{
+					TemplateBody: mockTemplateContent,
+				},
+			},
+			createMock: func(ctrl *gomock.Controller) client {
+				m := mocks.NewMockclient(ctrl)
+				expectedIn := &cloudformation.CreateChangeSetInput{
+					ChangeSetName:       aws.String(mockChangeSetName),
+					StackName:           aws.String(mockStackName),
+					Description:         aws.String("Managed by Flux"),
+					ChangeSetType:       sdktypes.ChangeSetTypeCreate,
+					TemplateBody:        aws.String(mockTemplateContent),
+					IncludeNestedStacks: aws.Bool(true),
+					Capabilities: []sdktypes.Capability{
+						sdktypes.CapabilityCapabilityIam,
+						sdktypes.CapabilityCapabilityNamedIam,
+						sdktypes.CapabilityCapabilityAutoExpand,
+					},
+				}
+				m.EXPECT().CreateChangeSet(gomock.Any(), gomock.Eq(expectedIn), gomock.Any()).Return(&cloudformation.CreateChangeSetOutput{
+					Id: aws.String(mockChangeSetArn),
+				}, nil)
+				return m
+			},
+		},
 		"creates the stack with parameters": {
 			inStack: &types.Stack{
-				Name:           mockStackName,
-				Region:         mockRegion,
-				Generation:     mockGenerationId,
-				SourceRevision: mockSourceRevision,
+				Name:          mockStackName,
+				Region:        mockRegion,
+				ChangeSetName: mockChangeSetName,
 				StackConfig: &types.StackConfig{
 					TemplateBucket: mockBucket,
 					TemplateBody:   mockTemplateContent,
@@ -472,10 +569,9 @@ func TestCloudFormation_CreateStack(t *testing.T) {
 		},
 		"creates the stack with tags": {
 			inStack: &types.Stack{
-				Name:           mockStackName,
-				Region:         mockRegion,
-				Generation:     mockGenerationId,
-				SourceRevision: mockSourceRevision,
+				Name:          mockStackName,
+				Region:        mockRegion,
+				ChangeSetName: mockChangeSetName,
 				StackConfig: &types.StackConfig{
 					TemplateBucket: mockBucket,
 					TemplateBody:   mockTemplateContent,
@@ -524,11 +620,10 @@ func TestCloudFormation_CreateStack(t *testing.T) {
 			defer ctrl.Finish()
 			c := CloudFormation{
 				client: tc.createMock(ctrl),
-				ctx:    ctx,
 			}
 
 			// WHEN
-			arn, err := c.CreateStack(tc.inStack)
+			arn, err := c.CreateStack(ctx, tc.inStack)
 
 			// THEN
 			if tc.wantedErr != nil {
@@ -555,7 +650,7 @@ func TestCloudFormation_UpdateStack(t *testing.T) {
 				m.EXPECT().CreateChangeSet(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, genericApiError)
 				return m
 			},
-			wantedErr: fmt.Errorf("create change set flux-2-main-sha1-132f4e719209eb10b9485302f8593fc0e680f4fc for stack mock-stack-1234: %w", genericApiError),
+			wantedErr: fmt.Errorf("create change set flux-2-5f0c1e2b9a7d4c36 for stack mock-stack-1234: %w", genericApiError),
 		},
 		"updates the stack": {
 			inStack: generateMockStack(),
@@ -582,10 +677,9 @@ func TestCloudFormation_UpdateStack(t *testing.T) {
 		},
 		"updates the stack with parameters and tags": {
 			inStack: &types.Stack{
-				Name:           mockStackName,
-				Region:         mockRegion,
-				Generation:     mockGenerationId,
-				SourceRevision: mockSourceRevision,
+				Name:          mockStackName,
+				Region:        mockRegion,
+				ChangeSetName: mockChangeSetName,
 				StackConfig: &types.StackConfig{
 					TemplateBucket: mockBucket,
 					TemplateBody:   mockTemplateContent,
@@ -646,11 +740,10 @@ func TestCloudFormation_UpdateStack(t *testing.T) {
 			defer ctrl.Finish()
 			c := CloudFormation{
 				client: tc.createMock(ctrl),
-				ctx:    ctx,
 			}
 
 			// WHEN
-			arn, err := c.UpdateStack(tc.inStack)
+			arn, err := c.UpdateStack(ctx, tc.inStack)
 
 			// THEN
 			if tc.wantedErr != nil {
@@ -697,13 +790,12 @@ func TestCloudFormation_ExecuteChangeSet(t *testing.T) {
 			defer ctrl.Finish()
 			c := CloudFormation{
 				client: tc.createMock(ctrl),
-				ctx:    ctx,
 			}
 			mockStack := generateMockStack()
 			mockStack.ChangeSetArn = mockChangeSetArn
 
 			// WHEN
-			err := c.ExecuteChangeSet(mockStack)
+			err := c.ExecuteChangeSet(ctx, mockStack)
 
 			// THEN
 			if tc.wantedErr != nil {
@@ -747,12 +839,11 @@ func TestCloudFormation_ContinueStackRollback(t *testing.T) {
 			defer ctrl.Finish()
 			c := CloudFormation{
 				client: tc.createMock(ctrl),
-				ctx:    ctx,
 			}
 			mockStack := generateMockStack()
 
 			// WHEN
-			err := c.ContinueStackRollback(mockStack)
+			err := c.ContinueStackRollback(ctx, mockStack)
 
 			// THEN
 			if tc.wantedErr != nil {
@@ -804,11 +895,10 @@ func TestCloudFormation_DeleteStack(t *testing.T) {
 			defer ctrl.Finish()
 			c := CloudFormation{
 				client: tc.createMock(ctrl),
-				ctx:    ctx,
 			}
 
 			// WHEN
-			err := c.DeleteStack(mockStack)
+			err := c.DeleteStack(ctx, mockStack)
 
 			// THEN
 			require.Equal(t, tc.wantedErr, err)
@@ -865,11 +955,10 @@ func TestCloudFormation_DeleteChangeSet(t *testing.T) {
 			defer ctrl.Finish()
 			c := CloudFormation{
 				client: tc.createMock(ctrl, mockStack),
-				ctx:    ctx,
 			}
 
 			// WHEN
-			err := c.DeleteChangeSet(mockStack)
+			err := c.DeleteChangeSet(ctx, mockStack)
 
 			// THEN
 			require.Equal(t, tc.wantedErr, err)
@@ -41,6 +41,25 @@ func (e *ErrChangeSetNotFound) Error() string {
 	return fmt.Sprintf("change set with name %s for stack %s cannot be found", e.name, e.stackName)
 }
 
+// ErrStackSetNotFound occurs when a CloudFormation stack set does not exist.
+type ErrStackSetNotFound struct {
+	name string
+}
+
+func (e *ErrStackSetNotFound) Error() string {
+	return fmt.Sprintf("stack set named %s cannot be found", e.name)
+}
+
+// ErrStackSetOperationNotFound occurs when a CloudFormation stack set operation does not exist.
+type ErrStackSetOperationNotFound struct {
+	id           string
+	stackSetName string
+}
+
+func (e *ErrStackSetOperationNotFound) Error() string {
+	return fmt.Sprintf("operation with ID %s for stack set %s cannot be found", e.id, e.stackSetName)
+}
+
 // stackDoesNotExist returns true if the underlying error is a stack doesn't exist.
 func stackDoesNotExist(err error) bool {
 	var ae smithy.APIError
@@ -67,3 +86,27 @@ func changeSetDoesNotExist(err error) bool {
 	}
 	return false
 }
+
+// stackSetDoesNotExist returns true if the underlying error is a stack set doesn't exist.
+func stackSetDoesNotExist(err error) bool {
+	var ae smithy.APIError
+	if errors.As(err, &ae) {
+		switch ae.ErrorCode() {
+		case "StackSetNotFoundException":
+			return true
+		}
+	}
+	return false
+}
+
+// stackSetOperationDoesNotExist returns true if the underlying error is a stack set operation doesn't exist.
+func stackSetOperationDoesNotExist(err error) bool {
+	var ae smithy.APIError
+	if errors.As(err, &ae) {
+		switch ae.ErrorCode() {
+		case "OperationNotFoundException":
+			return true
+		}
+	}
+	return false
+}
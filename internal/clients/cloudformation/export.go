@@ -0,0 +1,63 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT-0
+
+package cloudformation
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+	sdktypes "github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/clients/types"
+)
+
+// ListStacks returns the descriptions of all the existing stacks in the client's region.
+// Stacks that are being created from a change set that has not been executed yet are omitted.
+func (c *CloudFormation) ListStacks(ctx context.Context) ([]*types.StackDescription, error) {
+	var stacks []*types.StackDescription
+	var nextToken *string
+	for {
+		out, err := c.client.DescribeStacks(ctx, &cloudformation.DescribeStacksInput{
+			NextToken: nextToken,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("list stacks: %w", err)
+		}
+		for _, stack := range out.Stacks {
+			if stack.StackStatus == sdktypes.StackStatusReviewInProgress || stack.StackStatus == sdktypes.StackStatusDeleteComplete {
+				continue
+			}
+			descr := types.StackDescription(stack)
+			stacks = append(stacks, &descr)
+		}
+		nextToken = out.NextToken
+
+		if nextToken == nil { // no more results left
+			break
+		}
+	}
+	return stacks, nil
+}
+
+// GetTemplate returns the body of the template of an existing stack, as it was submitted
+// before any transforms were processed.
+// If the stack does not exist, returns ErrStackNotFound.
+func (c *CloudFormation) GetTemplate(ctx context.Context, stack *types.Stack) (string, error) {
+	out, err := c.client.GetTemplate(ctx, &cloudformation.GetTemplateInput{
+		StackName:     aws.String(stack.Name),
+		TemplateStage: sdktypes.TemplateStageOriginal,
+	}, func(opts *cloudformation.Options) {
+		if stack.Region != "" {
+			opts.Region = stack.Region
+		}
+	})
+	if err != nil {
+		if stackDoesNotExist(err) {
+			return "", &ErrStackNotFound{name: stack.Name}
+		}
+		return "", fmt.Errorf("get template of stack %s: %w", stack.Name, err)
+	}
+	return aws.ToString(out.TemplateBody), nil
+}
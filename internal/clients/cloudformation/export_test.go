@@ -0,0 +1,140 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT-0
+
+package cloudformation
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+	sdktypes "github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/clients/cloudformation/mocks"
+	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/clients/types"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCloudFormation_ListStacks(t *testing.T) {
+	testCases := map[string]struct {
+		createMock   func(ctrl *gomock.Controller) client
+		wantedStacks []*types.StackDescription
+		wantedErr    error
+	}{
+		"return wrapped error if describe call fails": {
+			createMock: func(ctrl *gomock.Controller) client {
+				m := mocks.NewMockclient(ctrl)
+				m.EXPECT().DescribeStacks(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, genericApiError)
+				return m
+			},
+			wantedErr: fmt.Errorf("list stacks: %w", genericApiError),
+		},
+		"return the stacks of all pages, except stacks in review": {
+			createMock: func(ctrl *gomock.Controller) client {
+				m := mocks.NewMockclient(ctrl)
+				m.EXPECT().DescribeStacks(gomock.Any(), gomock.Eq(&cloudformation.DescribeStacksInput{}), gomock.Any()).Return(&cloudformation.DescribeStacksOutput{
+					Stacks: []sdktypes.Stack{
+						{StackName: aws.String("stack-1"), StackStatus: sdktypes.StackStatusCreateComplete},
+						{StackName: aws.String("stack-2"), StackStatus: sdktypes.StackStatusReviewInProgress},
+					},
+					NextToken: aws.String("next"),
+				}, nil)
+				m.EXPECT().DescribeStacks(gomock.Any(), gomock.Eq(&cloudformation.DescribeStacksInput{NextToken: aws.String("next")}), gomock.Any()).Return(&cloudformation.DescribeStacksOutput{
+					Stacks: []sdktypes.Stack{
+						{StackName: aws.String("stack-3"), StackStatus: sdktypes.StackStatusUpdateComplete},
+					},
+				}, nil)
+				return m
+			},
+			wantedStacks: []*types.StackDescription{
+				{StackName: aws.String("stack-1"), StackStatus: sdktypes.StackStatusCreateComplete},
+				{StackName: aws.String("stack-3"), StackStatus: sdktypes.StackStatusUpdateComplete},
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			// GIVEN
+			ctrl, ctx := gomock.WithContext(context.Background(), t)
+			defer ctrl.Finish()
+			c := CloudFormation{
+				client: tc.createMock(ctrl),
+			}
+
+			// WHEN
+			stacks, err := c.ListStacks(ctx)
+
+			// THEN
+			if tc.wantedErr != nil {
+				require.EqualError(t, err, tc.wantedErr.Error())
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, tc.wantedStacks, stacks)
+			}
+		})
+	}
+}
+
+func TestCloudFormation_GetTemplate(t *testing.T) {
+	testCases := map[string]struct {
+		createMock     func(ctrl *gomock.Controller) client
+		wantedTemplate string
+		wantedErr      error
+	}{
+		"return ErrStackNotFound if stack does not exist": {
+			createMock: func(ctrl *gomock.Controller) client {
+				m := mocks.NewMockclient(ctrl)
+				m.EXPECT().GetTemplate(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errDoesNotExist)
+				return m
+			},
+			wantedErr: &ErrStackNotFound{name: mockStackName},
+		},
+		"return wrapped error if get template call fails": {
+			createMock: func(ctrl *gomock.Controller) client {
+				m := mocks.NewMockclient(ctrl)
+				m.EXPECT().GetTemplate(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, genericApiError)
+				return m
+			},
+			wantedErr: fmt.Errorf("get template of stack %s: %w", mockStackName, genericApiError),
+		},
+		"return the original template": {
+			createMock: func(ctrl *gomock.Controller) client {
+				m := mocks.NewMockclient(ctrl)
+				expectedIn := &cloudformation.GetTemplateInput{
+					StackName:     aws.String(mockStackName),
+					TemplateStage: sdktypes.TemplateStageOriginal,
+				}
+				m.EXPECT().GetTemplate(gomock.Any(), gomock.Eq(expectedIn), gomock.Any()).Return(&cloudformation.GetTemplateOutput{
+					TemplateBody: aws.String(mockTemplateContent),
+				}, nil)
+				return m
+			},
+			wantedTemplate: mockTemplateContent,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			// GIVEN
+			ctrl, ctx := gomock.WithContext(context.Background(), t)
+			defer ctrl.Finish()
+			c := CloudFormation{
+				client: tc.createMock(ctrl),
+			}
+
+			// WHEN
+			body, err := c.GetTemplate(ctx, generateMockStack())
+
+			// THEN
+			if tc.wantedErr != nil {
+				require.EqualError(t, err, tc.wantedErr.Error())
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, tc.wantedTemplate, body)
+			}
+		})
+	}
+}
@@ -0,0 +1,189 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT-0
+
+package cloudformation
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+	sdktypes "github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+
+	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/clients"
+	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/clients/types"
+)
+
+// StackCache caches the descriptions of all the stacks of the client's account, for each region.
+// The descriptions of a region are refreshed by a paginated DescribeStacks sweep of the region
+// when they are older than the cache's maximum age, so that describing many stacks takes a few API calls
+// per maximum age instead of one API call per stack.
+type StackCache struct {
+	client client
+	maxAge time.Duration
+
+	mu      sync.Mutex
+	regions map[string]*regionStackCache
+
+	// now returns the current time, for tests
+	now func() time.Time
+}
+
+// regionStackCache caches the descriptions of the stacks of a region
+type regionStackCache struct {
+	// sweepMu serializes the sweeps of the region
+	sweepMu sync.Mutex
+
+	mu     sync.Mutex
+	stacks map[string]cachedStack
+	// sweptAt is the start time of the last successful sweep
+	sweptAt time.Time
+	// sweepErr is the error of the last sweep, if it failed, and failedAt its time
+	sweepErr error
+	failedAt time.Time
+}
+
+type cachedStack struct {
+	desc       *types.StackDescription
+	observedAt time.Time
+}
+
+// NewStackCache creates a stack cache whose descriptions are refreshed when they are older than maxAge.
+func NewStackCache(ctx context.Context, region string, opts types.ClientOptions, maxAge time.Duration) (clients.CloudFormationStackCache, error) {
+	cfn, err := newCloudFormation(ctx, region, opts)
+	if err != nil {
+		return nil, err
+	}
+	return newStackCache(cfn.client, maxAge), nil
+}
+
+func newStackCache(client client, maxAge time.Duration) *StackCache {
+	return &StackCache{
+		client:  client,
+		maxAge:  maxAge,
+		regions: map[string]*regionStackCache{},
+		now:     time.Now,
+	}
+}
+
+// DescribeStack returns the cached description of a stack, sweeping the stacks of the stack's region first
+// if the cached descriptions are too old.
+// If the stack was not found by the last sweep, returns ErrStackNotFound.
+func (c *StackCache) DescribeStack(ctx context.Context, stack *types.Stack) (*types.StackDescription, error) {
+	rc := c.region(stack.Region)
+	if err := c.refresh(ctx, rc, stack.Region); err != nil {
+		return nil, err
+	}
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	cached, ok := rc.stacks[stack.Name]
+	if !ok || cached.desc == nil {
+		return nil, &ErrStackNotFound{name: stack.Name}
+	}
+	return cached.desc, nil
+}
+
+// StoreStack records the description of a stack that was described outside of the cache,
+// or that the stack does not exist if the description is nil.
+// The description is kept until a sweep that started after it was recorded.
+func (c *StackCache) StoreStack(stack *types.Stack, desc *types.StackDescription) {
+	rc := c.region(stack.Region)
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.stacks[stack.Name] = cachedStack{desc: desc, observedAt: c.now()}
+}
+
+// region returns the cache of the stacks of a region
+func (c *StackCache) region(region string) *regionStackCache {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	rc, ok := c.regions[region]
+	if !ok {
+		rc = &regionStackCache{stacks: map[string]cachedStack{}}
+		c.regions[region] = rc
+	}
+	return rc
+}
+
+// refresh sweeps the stacks of the region if the last sweep is older than the cache's maximum age.
+// After a failed sweep, the region is not swept again until the maximum age has passed,
+// and the sweep's error is returned instead.
+func (c *StackCache) refresh(ctx context.Context, rc *regionStackCache, region string) error {
+	rc.sweepMu.Lock()
+	defer rc.sweepMu.Unlock()
+
+	rc.mu.Lock()
+	now := c.now()
+	fresh := now.Sub(rc.sweptAt) < c.maxAge
+	sweepErr := rc.sweepErr
+	recentlyFailed := sweepErr != nil && now.Sub(rc.failedAt) < c.maxAge
+	rc.mu.Unlock()
+	if fresh {
+		return nil
+	}
+	if recentlyFailed {
+		return sweepErr
+	}
+
+	stacks, err := c.sweep(ctx, region)
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if err != nil {
+		rc.sweepErr = err
+		rc.failedAt = c.now()
+		return err
+	}
+	// Keep the descriptions recorded while the sweep was in progress, they are more recent
+	for name, cached := range rc.stacks {
+		if cached.observedAt.After(now) {
+			stacks[name] = cached
+		}
+	}
+	rc.stacks = stacks
+	rc.sweptAt = now
+	rc.sweepErr = nil
+	return nil
+}
+
+// sweep describes all the stacks of the region
+func (c *StackCache) sweep(ctx context.Context, region string) (map[string]cachedStack, error) {
+	now := c.now()
+	stacks := map[string]cachedStack{}
+	var nextToken *string
+	for {
+		out, err := c.client.DescribeStacks(ctx, &cloudformation.DescribeStacksInput{
+			NextToken: nextToken,
+		}, func(opts *cloudformation.Options) {
+			if region != "" {
+				opts.Region = region
+			}
+		})
+		if err != nil {
+			return nil, fmt.Errorf("sweep stacks: %w", err)
+		}
+		for _, stack := range out.Stacks {
+			if stack.StackStatus == sdktypes.StackStatusReviewInProgress {
+				// there is a creation change set for the stack, but it has not been executed,
+				// so the stack has not been created yet
+				continue
+			}
+			if stack.StackStatus == sdktypes.StackStatusDeleteComplete {
+				// the stack was previously successfully deleted
+				continue
+			}
+			descr := types.StackDescription(stack)
+			stacks[aws.ToString(stack.StackName)] = cachedStack{desc: &descr, observedAt: now}
+		}
+		nextToken = out.NextToken
+
+		if nextToken == nil { // no more results left
+			break
+		}
+	}
+	return stacks, nil
+}
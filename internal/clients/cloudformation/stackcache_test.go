@@ -0,0 +1,145 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT-0
+
+package cloudformation
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+	sdktypes "github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/clients/cloudformation/mocks"
+	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/clients/types"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStackCache_DescribeStack(t *testing.T) {
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+	defer ctrl.Finish()
+	m := mocks.NewMockclient(ctrl)
+	now := time.Now()
+	c := newStackCache(m, time.Minute)
+	c.now = func() time.Time { return now }
+
+	// The first description sweeps all the pages of stacks
+	gomock.InOrder(
+		m.EXPECT().DescribeStacks(gomock.Any(), &cloudformation.DescribeStacksInput{}, gomock.Any()).Return(&cloudformation.DescribeStacksOutput{
+			Stacks: []sdktypes.Stack{
+				{StackName: aws.String(mockStackName), StackStatus: sdktypes.StackStatusUpdateComplete},
+				{StackName: aws.String("deleted-stack"), StackStatus: sdktypes.StackStatusDeleteComplete},
+				{StackName: aws.String("review-stack"), StackStatus: sdktypes.StackStatusReviewInProgress},
+			},
+			NextToken: aws.String("page-2"),
+		}, nil),
+		m.EXPECT().DescribeStacks(gomock.Any(), &cloudformation.DescribeStacksInput{NextToken: aws.String("page-2")}, gomock.Any()).Return(&cloudformation.DescribeStacksOutput{
+			Stacks: []sdktypes.Stack{
+				{StackName: aws.String("other-stack"), StackStatus: sdktypes.StackStatusUpdateInProgress},
+			},
+		}, nil),
+	)
+	desc, err := c.DescribeStack(ctx, &types.Stack{Name: mockStackName})
+	require.NoError(t, err)
+	require.Equal(t, sdktypes.StackStatusUpdateComplete, desc.StackStatus)
+
+	// The following descriptions are read from the cache until it is too old
+	now = now.Add(30 * time.Second)
+	desc, err = c.DescribeStack(ctx, &types.Stack{Name: "other-stack"})
+	require.NoError(t, err)
+	require.Equal(t, sdktypes.StackStatusUpdateInProgress, desc.StackStatus)
+	_, err = c.DescribeStack(ctx, &types.Stack{Name: "deleted-stack"})
+	require.Equal(t, &ErrStackNotFound{name: "deleted-stack"}, err)
+	_, err = c.DescribeStack(ctx, &types.Stack{Name: "review-stack"})
+	require.Equal(t, &ErrStackNotFound{name: "review-stack"}, err)
+
+	// Stored descriptions replace the cached ones
+	c.StoreStack(&types.Stack{Name: "other-stack"}, &types.StackDescription{StackName: aws.String("other-stack"), StackStatus: sdktypes.StackStatusUpdateComplete})
+	c.StoreStack(&types.Stack{Name: mockStackName}, nil)
+	desc, err = c.DescribeStack(ctx, &types.Stack{Name: "other-stack"})
+	require.NoError(t, err)
+	require.Equal(t, sdktypes.StackStatusUpdateComplete, desc.StackStatus)
+	_, err = c.DescribeStack(ctx, &types.Stack{Name: mockStackName})
+	require.Equal(t, &ErrStackNotFound{name: mockStackName}, err)
+
+	// The stacks are swept again once the cache is too old,
+	// keeping the descriptions stored while the sweep is in progress
+	now = now.Add(time.Minute)
+	m.EXPECT().DescribeStacks(gomock.Any(), &cloudformation.DescribeStacksInput{}, gomock.Any()).DoAndReturn(
+		func(ctx context.Context, params *cloudformation.DescribeStacksInput, optFns ...func(*cloudformation.Options)) (*cloudformation.DescribeStacksOutput, error) {
+			now = now.Add(time.Second)
+			c.StoreStack(&types.Stack{Name: "other-stack"}, &types.StackDescription{StackName: aws.String("other-stack"), StackStatus: sdktypes.StackStatusDeleteInProgress})
+			return &cloudformation.DescribeStacksOutput{
+				Stacks: []sdktypes.Stack{
+					{StackName: aws.String(mockStackName), StackStatus: sdktypes.StackStatusUpdateRollbackComplete},
+					{StackName: aws.String("other-stack"), StackStatus: sdktypes.StackStatusUpdateComplete},
+				},
+			}, nil
+		})
+	desc, err = c.DescribeStack(ctx, &types.Stack{Name: mockStackName})
+	require.NoError(t, err)
+	require.Equal(t, sdktypes.StackStatusUpdateRollbackComplete, desc.StackStatus)
+	desc, err = c.DescribeStack(ctx, &types.Stack{Name: "other-stack"})
+	require.NoError(t, err)
+	require.Equal(t, sdktypes.StackStatusDeleteInProgress, desc.StackStatus)
+}
+
+func TestStackCache_Regions(t *testing.T) {
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+	defer ctrl.Finish()
+	m := mocks.NewMockclient(ctrl)
+	c := newStackCache(m, time.Minute)
+
+	// Each region is swept separately
+	for _, region := range []string{"us-west-2", "us-east-1"} {
+		region := region
+		m.EXPECT().DescribeStacks(gomock.Any(), &cloudformation.DescribeStacksInput{}, gomock.Any()).DoAndReturn(
+			func(ctx context.Context, params *cloudformation.DescribeStacksInput, optFns ...func(*cloudformation.Options)) (*cloudformation.DescribeStacksOutput, error) {
+				opts := cloudformation.Options{}
+				for _, fn := range optFns {
+					fn(&opts)
+				}
+				require.Equal(t, region, opts.Region)
+				return &cloudformation.DescribeStacksOutput{
+					Stacks: []sdktypes.Stack{
+						{StackName: aws.String(mockStackName), StackStatus: sdktypes.StackStatusUpdateComplete, StackId: aws.String(region)},
+					},
+				}, nil
+			})
+	}
+	for _, region := range []string{"us-west-2", "us-east-1", "us-west-2"} {
+		desc, err := c.DescribeStack(ctx, &types.Stack{Name: mockStackName, Region: region})
+		require.NoError(t, err)
+		require.Equal(t, region, aws.ToString(desc.StackId))
+	}
+}
+
+func TestStackCache_SweepFailure(t *testing.T) {
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+	defer ctrl.Finish()
+	m := mocks.NewMockclient(ctrl)
+	now := time.Now()
+	c := newStackCache(m, time.Minute)
+	c.now = func() time.Time { return now }
+
+	// A failed sweep is not retried until the cache's maximum age has passed
+	m.EXPECT().DescribeStacks(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("some error"))
+	_, err := c.DescribeStack(ctx, &types.Stack{Name: mockStackName})
+	require.EqualError(t, err, "sweep stacks: some error")
+	now = now.Add(30 * time.Second)
+	_, err = c.DescribeStack(ctx, &types.Stack{Name: mockStackName})
+	require.EqualError(t, err, "sweep stacks: some error")
+
+	now = now.Add(time.Minute)
+	m.EXPECT().DescribeStacks(gomock.Any(), gomock.Any(), gomock.Any()).Return(&cloudformation.DescribeStacksOutput{
+		Stacks: []sdktypes.Stack{
+			{StackName: aws.String(mockStackName), StackStatus: sdktypes.StackStatusCreateComplete},
+		},
+	}, nil)
+	desc, err := c.DescribeStack(ctx, &types.Stack{Name: mockStackName})
+	require.NoError(t, err)
+	require.Equal(t, sdktypes.StackStatusCreateComplete, desc.StackStatus)
+}
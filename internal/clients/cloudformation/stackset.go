@@ -0,0 +1,208 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT-0
+
+package cloudformation
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+	sdktypes "github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/clients/types"
+)
+
+var stackSetCapabilities = []sdktypes.Capability{
+	sdktypes.CapabilityCapabilityIam,
+	sdktypes.CapabilityCapabilityNamedIam,
+	sdktypes.CapabilityCapabilityAutoExpand,
+}
+
+// DescribeStackSet returns a description of an existing stack set.
+// If the stack set does not exist or was deleted, returns ErrStackSetNotFound.
+func (c *CloudFormation) DescribeStackSet(ctx context.Context, stackSet *types.StackSet) (*types.StackSetDescription, error) {
+	out, err := c.client.DescribeStackSet(ctx, &cloudformation.DescribeStackSetInput{
+		StackSetName: aws.String(stackSet.Name),
+	}, withStackSetRegion(stackSet))
+	if err != nil {
+		if stackSetDoesNotExist(err) {
+			return nil, &ErrStackSetNotFound{name: stackSet.Name}
+		}
+		return nil, fmt.Errorf("describe stack set %s: %w", stackSet.Name, err)
+	}
+	if out.StackSet == nil || out.StackSet.Status == sdktypes.StackSetStatusDeleted {
+		return nil, &ErrStackSetNotFound{name: stackSet.Name}
+	}
+	descr := types.StackSetDescription(*out.StackSet)
+	return &descr, nil
+}
+
+// CreateStackSet creates a new stack set without any stack instances.
+func (c *CloudFormation) CreateStackSet(ctx context.Context, stackSet *types.StackSet) error {
+	input := &cloudformation.CreateStackSetInput{
+		StackSetName:    aws.String(stackSet.Name),
+		Description:     aws.String("Managed by Flux"),
+		Parameters:      stackSet.Parameters,
+		Tags:            stackSet.Tags,
+		Capabilities:    stackSetCapabilities,
+		PermissionModel: stackSet.PermissionModel,
+		AutoDeployment:  stackSet.AutoDeployment,
+	}
+	if stackSet.AdministrationRoleARN != "" {
+		input.AdministrationRoleARN = aws.String(stackSet.AdministrationRoleARN)
+	}
+	if stackSet.ExecutionRoleName != "" {
+		input.ExecutionRoleName = aws.String(stackSet.ExecutionRoleName)
+	}
+	if stackSet.TemplateURL != "" {
+		input.TemplateURL = aws.String(stackSet.TemplateURL)
+	} else {
+		input.TemplateBody = aws.String(stackSet.TemplateBody)
+	}
+
+	if _, err := c.client.CreateStackSet(ctx, input, withStackSetRegion(stackSet)); err != nil {
+		return fmt.Errorf("create stack set %s: %w", stackSet.Name, err)
+	}
+	return nil
+}
+
+// UpdateStackSet starts an operation that updates the stack set and all of its stack instances
+// with the stack set's configuration, and returns the ID of the operation.
+func (c *CloudFormation) UpdateStackSet(ctx context.Context, stackSet *types.StackSet) (operationID string, err error) {
+	input := &cloudformation.UpdateStackSetInput{
+		StackSetName:         aws.String(stackSet.Name),
+		Description:          aws.String("Managed by Flux"),
+		Parameters:           stackSet.Parameters,
+		Tags:                 stackSet.Tags,
+		Capabilities:         stackSetCapabilities,
+		PermissionModel:      stackSet.PermissionModel,
+		AutoDeployment:       stackSet.AutoDeployment,
+		OperationPreferences: stackSet.OperationPreferences,
+	}
+	if stackSet.AdministrationRoleARN != "" {
+		input.AdministrationRoleARN = aws.String(stackSet.AdministrationRoleARN)
+	}
+	if stackSet.ExecutionRoleName != "" {
+		input.ExecutionRoleName = aws.String(stackSet.ExecutionRoleName)
+	}
+	if stackSet.TemplateURL != "" {
+		input.TemplateURL = aws.String(stackSet.TemplateURL)
+	} else {
+		input.TemplateBody = aws.String(stackSet.TemplateBody)
+	}
+
+	out, err := c.client.UpdateStackSet(ctx, input, withStackSetRegion(stackSet))
+	if err != nil {
+		return "", fmt.Errorf("update stack set %s: %w", stackSet.Name, err)
+	}
+	return aws.ToString(out.OperationId), nil
+}
+
+// DeleteStackSet deletes a stack set that does not have any stack instances.
+// If the stack set doesn't exist then do nothing.
+func (c *CloudFormation) DeleteStackSet(ctx context.Context, stackSet *types.StackSet) error {
+	_, err := c.client.DeleteStackSet(ctx, &cloudformation.DeleteStackSetInput{
+		StackSetName: aws.String(stackSet.Name),
+	}, withStackSetRegion(stackSet))
+	if err != nil && !stackSetDoesNotExist(err) {
+		return fmt.Errorf("delete stack set %s: %w", stackSet.Name, err)
+	}
+	return nil
+}
+
+// ListStackInstances returns the summaries of all the stack instances of the stack set.
+func (c *CloudFormation) ListStackInstances(ctx context.Context, stackSet *types.StackSet) ([]*types.StackInstanceSummary, error) {
+	var instances []*types.StackInstanceSummary
+	var nextToken *string
+	for {
+		out, err := c.client.ListStackInstances(ctx, &cloudformation.ListStackInstancesInput{
+			StackSetName: aws.String(stackSet.Name),
+			NextToken:    nextToken,
+		}, withStackSetRegion(stackSet))
+		if err != nil {
+			return nil, fmt.Errorf("list stack instances of stack set %s: %w", stackSet.Name, err)
+		}
+		for _, summary := range out.Summaries {
+			instance := types.StackInstanceSummary(summary)
+			instances = append(instances, &instance)
+		}
+		nextToken = out.NextToken
+
+		if nextToken == nil { // no more results left
+			break
+		}
+	}
+	return instances, nil
+}
+
+// CreateStackInstances starts an operation that creates stack instances for the given targets,
+// and returns the ID of the operation.
+func (c *CloudFormation) CreateStackInstances(ctx context.Context, stackSet *types.StackSet, targets *types.StackInstanceTargets) (operationID string, err error) {
+	input := &cloudformation.CreateStackInstancesInput{
+		StackSetName:         aws.String(stackSet.Name),
+		Regions:              targets.Regions,
+		OperationPreferences: stackSet.OperationPreferences,
+	}
+	if len(targets.OrganizationalUnitIDs) > 0 {
+		input.DeploymentTargets = &sdktypes.DeploymentTargets{OrganizationalUnitIds: targets.OrganizationalUnitIDs}
+	} else {
+		input.Accounts = targets.Accounts
+	}
+
+	out, err := c.client.CreateStackInstances(ctx, input, withStackSetRegion(stackSet))
+	if err != nil {
+		return "", fmt.Errorf("create stack instances of stack set %s: %w", stackSet.Name, err)
+	}
+	return aws.ToString(out.OperationId), nil
+}
+
+// DeleteStackInstances starts an operation that deletes the stack instances for the given targets,
+// and returns the ID of the operation. If retainStacks is true, the stacks of the stack instances are not deleted.
+func (c *CloudFormation) DeleteStackInstances(ctx context.Context, stackSet *types.StackSet, targets *types.StackInstanceTargets, retainStacks bool) (operationID string, err error) {
+	input := &cloudformation.DeleteStackInstancesInput{
+		StackSetName:         aws.String(stackSet.Name),
+		Regions:              targets.Regions,
+		RetainStacks:         aws.Bool(retainStacks),
+		OperationPreferences: stackSet.OperationPreferences,
+	}
+	if len(targets.OrganizationalUnitIDs) > 0 {
+		input.DeploymentTargets = &sdktypes.DeploymentTargets{OrganizationalUnitIds: targets.OrganizationalUnitIDs}
+	} else {
+		input.Accounts = targets.Accounts
+	}
+
+	out, err := c.client.DeleteStackInstances(ctx, input, withStackSetRegion(stackSet))
+	if err != nil {
+		return "", fmt.Errorf("delete stack instances of stack set %s: %w", stackSet.Name, err)
+	}
+	return aws.ToString(out.OperationId), nil
+}
+
+// DescribeStackSetOperation returns a description of a stack set operation.
+// If the operation does not exist, returns ErrStackSetOperationNotFound.
+func (c *CloudFormation) DescribeStackSetOperation(ctx context.Context, stackSet *types.StackSet, operationID string) (*types.StackSetOperationDescription, error) {
+	out, err := c.client.DescribeStackSetOperation(ctx, &cloudformation.DescribeStackSetOperationInput{
+		StackSetName: aws.String(stackSet.Name),
+		OperationId:  aws.String(operationID),
+	}, withStackSetRegion(stackSet))
+	if err != nil {
+		if stackSetOperationDoesNotExist(err) || stackSetDoesNotExist(err) {
+			return nil, &ErrStackSetOperationNotFound{id: operationID, stackSetName: stackSet.Name}
+		}
+		return nil, fmt.Errorf("describe operation %s for stack set %s: %w", operationID, stackSet.Name, err)
+	}
+	if out.StackSetOperation == nil {
+		return nil, &ErrStackSetOperationNotFound{id: operationID, stackSetName: stackSet.Name}
+	}
+	descr := types.StackSetOperationDescription(*out.StackSetOperation)
+	return &descr, nil
+}
+
+func withStackSetRegion(stackSet *types.StackSet) func(*cloudformation.Options) {
+	return func(opts *cloudformation.Options) {
+		if stackSet.Region != "" {
+			opts.Region = stackSet.Region
+		}
+	}
+}
@@ -0,0 +1,376 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT-0
+
+package cloudformation
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+	sdktypes "github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/clients/cloudformation/mocks"
+	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/clients/types"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	mockStackSetName   = "mock-stack-set-1234"
+	mockOperationId    = "mock-operation-id"
+	mockAccount        = "123456789012"
+	mockOrganizationId = "ou-abcd-12345678"
+)
+
+var (
+	errStackSetDoesNotExist = &sdktypes.StackSetNotFoundException{
+		Message: aws.String("does not exist"),
+	}
+	errOperationDoesNotExist = &sdktypes.OperationNotFoundException{
+		Message: aws.String("does not exist"),
+	}
+)
+
+func generateMockStackSet() *types.StackSet {
+	return &types.StackSet{
+		Name:   mockStackSetName,
+		Region: mockRegion,
+		StackConfig: &types.StackConfig{
+			TemplateBucket: mockBucket,
+			TemplateBody:   mockTemplateContent,
+		},
+		StackSetConfig: &types.StackSetConfig{
+			PermissionModel: sdktypes.PermissionModelsSelfManaged,
+		},
+	}
+}
+
+func TestCloudFormation_DescribeStackSet(t *testing.T) {
+	testCases := map[string]struct {
+		createMock  func(ctrl *gomock.Controller) client
+		wantedDescr *types.StackSetDescription
+		wantedErr   error
+	}{
+		"return error if describe call fails": {
+			createMock: func(ctrl *gomock.Controller) client {
+				m := mocks.NewMockclient(ctrl)
+				m.EXPECT().DescribeStackSet(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, genericApiError)
+				return m
+			},
+			wantedErr: fmt.Errorf("describe stack set mock-stack-set-1234: %w", genericApiError),
+		},
+		"return ErrStackSetNotFound if stack set does not exist": {
+			createMock: func(ctrl *gomock.Controller) client {
+				m := mocks.NewMockclient(ctrl)
+				m.EXPECT().DescribeStackSet(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errStackSetDoesNotExist)
+				return m
+			},
+			wantedErr: &ErrStackSetNotFound{name: mockStackSetName},
+		},
+		"return ErrStackSetNotFound if stack set is deleted": {
+			createMock: func(ctrl *gomock.Controller) client {
+				m := mocks.NewMockclient(ctrl)
+				m.EXPECT().DescribeStackSet(gomock.Any(), gomock.Any(), gomock.Any()).Return(&cloudformation.DescribeStackSetOutput{
+					StackSet: &sdktypes.StackSet{
+						StackSetName: aws.String(mockStackSetName),
+						Status:       sdktypes.StackSetStatusDeleted,
+					},
+				}, nil)
+				return m
+			},
+			wantedErr: &ErrStackSetNotFound{name: mockStackSetName},
+		},
+		"returns a StackSetDescription if stack set exists": {
+			createMock: func(ctrl *gomock.Controller) client {
+				m := mocks.NewMockclient(ctrl)
+				expectedIn := &cloudformation.DescribeStackSetInput{
+					StackSetName: aws.String(mockStackSetName),
+				}
+				m.EXPECT().DescribeStackSet(gomock.Any(), gomock.Eq(expectedIn), gomock.Any()).Return(&cloudformation.DescribeStackSetOutput{
+					StackSet: &sdktypes.StackSet{
+						StackSetName: aws.String(mockStackSetName),
+						Status:       sdktypes.StackSetStatusActive,
+					},
+				}, nil)
+				return m
+			},
+			wantedDescr: &types.StackSetDescription{
+				StackSetName: aws.String(mockStackSetName),
+				Status:       sdktypes.StackSetStatusActive,
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			// GIVEN
+			mockStackSet := generateMockStackSet()
+			ctrl, ctx := gomock.WithContext(context.Background(), t)
+			defer ctrl.Finish()
+			c := CloudFormation{
+				client: tc.createMock(ctrl),
+			}
+
+			// WHEN
+			descr, err := c.DescribeStackSet(ctx, mockStackSet)
+
+			// THEN
+			require.Equal(t, tc.wantedDescr, descr)
+			require.Equal(t, tc.wantedErr, err)
+		})
+	}
+}
+
+func TestCloudFormation_UpdateStackSet(t *testing.T) {
+	testCases := map[string]struct {
+		createMock        func(ctrl *gomock.Controller) client
+		modifyStackSet    func(stackSet *types.StackSet)
+		wantedOperationId string
+		wantedErr         error
+	}{
+		"return error if update call fails": {
+			createMock: func(ctrl *gomock.Controller) client {
+				m := mocks.NewMockclient(ctrl)
+				m.EXPECT().UpdateStackSet(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, genericApiError)
+				return m
+			},
+			wantedErr: fmt.Errorf("update stack set mock-stack-set-1234: %w", genericApiError),
+		},
+		"update with template body": {
+			createMock: func(ctrl *gomock.Controller) client {
+				m := mocks.NewMockclient(ctrl)
+				expectedIn := &cloudformation.UpdateStackSetInput{
+					StackSetName:    aws.String(mockStackSetName),
+					Description:     aws.String("Managed by Flux"),
+					TemplateBody:    aws.String(mockTemplateContent),
+					Capabilities:    stackSetCapabilities,
+					PermissionModel: sdktypes.PermissionModelsSelfManaged,
+				}
+				m.EXPECT().UpdateStackSet(gomock.Any(), gomock.Eq(expectedIn), gomock.Any()).Return(&cloudformation.UpdateStackSetOutput{
+					OperationId: aws.String(mockOperationId),
+				}, nil)
+				return m
+			},
+			wantedOperationId: mockOperationId,
+		},
+		"update with template URL and roles": {
+			modifyStackSet: func(stackSet *types.StackSet) {
+				stackSet.TemplateURL = mockTemplateUrl
+				stackSet.AdministrationRoleARN = "mock-admin-role"
+				stackSet.ExecutionRoleName = "mock-execution-role"
+			},
+			createMock: func(ctrl *gomock.Controller) client {
+				m := mocks.NewMockclient(ctrl)
+				expectedIn := &cloudformation.UpdateStackSetInput{
+					StackSetName:          aws.String(mockStackSetName),
+					Description:           aws.String("Managed by Flux"),
+					TemplateURL:           aws.String(mockTemplateUrl),
+					Capabilities:          stackSetCapabilities,
+					PermissionModel:       sdktypes.PermissionModelsSelfManaged,
+					AdministrationRoleARN: aws.String("mock-admin-role"),
+					ExecutionRoleName:     aws.String("mock-execution-role"),
+				}
+				m.EXPECT().UpdateStackSet(gomock.Any(), gomock.Eq(expectedIn), gomock.Any()).Return(&cloudformation.UpdateStackSetOutput{
+					OperationId: aws.String(mockOperationId),
+				}, nil)
+				return m
+			},
+			wantedOperationId: mockOperationId,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			// GIVEN
+			mockStackSet := generateMockStackSet()
+			if tc.modifyStackSet != nil {
+				tc.modifyStackSet(mockStackSet)
+			}
+			ctrl, ctx := gomock.WithContext(context.Background(), t)
+			defer ctrl.Finish()
+			c := CloudFormation{
+				client: tc.createMock(ctrl),
+			}
+
+			// WHEN
+			operationId, err := c.UpdateStackSet(ctx, mockStackSet)
+
+			// THEN
+			require.Equal(t, tc.wantedOperationId, operationId)
+			require.Equal(t, tc.wantedErr, err)
+		})
+	}
+}
+
+func TestCloudFormation_CreateStackInstances(t *testing.T) {
+	testCases := map[string]struct {
+		createMock        func(ctrl *gomock.Controller) client
+		targets           *types.StackInstanceTargets
+		wantedOperationId string
+		wantedErr         error
+	}{
+		"return error if create call fails": {
+			targets: &types.StackInstanceTargets{Accounts: []string{mockAccount}, Regions: []string{mockRegion}},
+			createMock: func(ctrl *gomock.Controller) client {
+				m := mocks.NewMockclient(ctrl)
+				m.EXPECT().CreateStackInstances(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, genericApiError)
+				return m
+			},
+			wantedErr: fmt.Errorf("create stack instances of stack set mock-stack-set-1234: %w", genericApiError),
+		},
+		"create stack instances in accounts": {
+			targets: &types.StackInstanceTargets{Accounts: []string{mockAccount}, Regions: []string{mockRegion}},
+			createMock: func(ctrl *gomock.Controller) client {
+				m := mocks.NewMockclient(ctrl)
+				expectedIn := &cloudformation.CreateStackInstancesInput{
+					StackSetName: aws.String(mockStackSetName),
+					Accounts:     []string{mockAccount},
+					Regions:      []string{mockRegion},
+				}
+				m.EXPECT().CreateStackInstances(gomock.Any(), gomock.Eq(expectedIn), gomock.Any()).Return(&cloudformation.CreateStackInstancesOutput{
+					OperationId: aws.String(mockOperationId),
+				}, nil)
+				return m
+			},
+			wantedOperationId: mockOperationId,
+		},
+		"create stack instances in organizational units": {
+			targets: &types.StackInstanceTargets{OrganizationalUnitIDs: []string{mockOrganizationId}, Regions: []string{mockRegion}},
+			createMock: func(ctrl *gomock.Controller) client {
+				m := mocks.NewMockclient(ctrl)
+				expectedIn := &cloudformation.CreateStackInstancesInput{
+					StackSetName: aws.String(mockStackSetName),
+					DeploymentTargets: &sdktypes.DeploymentTargets{
+						OrganizationalUnitIds: []string{mockOrganizationId},
+					},
+					Regions: []string{mockRegion},
+				}
+				m.EXPECT().CreateStackInstances(gomock.Any(), gomock.Eq(expectedIn), gomock.Any()).Return(&cloudformation.CreateStackInstancesOutput{
+					OperationId: aws.String(mockOperationId),
+				}, nil)
+				return m
+			},
+			wantedOperationId: mockOperationId,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			// GIVEN
+			mockStackSet := generateMockStackSet()
+			ctrl, ctx := gomock.WithContext(context.Background(), t)
+			defer ctrl.Finish()
+			c := CloudFormation{
+				client: tc.createMock(ctrl),
+			}
+
+			// WHEN
+			operationId, err := c.CreateStackInstances(ctx, mockStackSet, tc.targets)
+
+			// THEN
+			require.Equal(t, tc.wantedOperationId, operationId)
+			require.Equal(t, tc.wantedErr, err)
+		})
+	}
+}
+
+func TestCloudFormation_ListStackInstances(t *testing.T) {
+	// GIVEN
+	mockStackSet := generateMockStackSet()
+	ctrl, ctx := gomock.WithContext(context.Background(), t)
+	defer ctrl.Finish()
+	m := mocks.NewMockclient(ctrl)
+	gomock.InOrder(
+		m.EXPECT().ListStackInstances(gomock.Any(), gomock.Eq(&cloudformation.ListStackInstancesInput{
+			StackSetName: aws.String(mockStackSetName),
+		}), gomock.Any()).Return(&cloudformation.ListStackInstancesOutput{
+			Summaries: []sdktypes.StackInstanceSummary{{Account: aws.String(mockAccount), Region: aws.String("us-east-1")}},
+			NextToken: aws.String("next"),
+		}, nil),
+		m.EXPECT().ListStackInstances(gomock.Any(), gomock.Eq(&cloudformation.ListStackInstancesInput{
+			StackSetName: aws.String(mockStackSetName),
+			NextToken:    aws.String("next"),
+		}), gomock.Any()).Return(&cloudformation.ListStackInstancesOutput{
+			Summaries: []sdktypes.StackInstanceSummary{{Account: aws.String(mockAccount), Region: aws.String("us-west-2")}},
+		}, nil),
+	)
+	c := CloudFormation{
+		client: m,
+	}
+
+	// WHEN
+	instances, err := c.ListStackInstances(ctx, mockStackSet)
+
+	// THEN
+	require.NoError(t, err)
+	require.Equal(t, []*types.StackInstanceSummary{
+		{Account: aws.String(mockAccount), Region: aws.String("us-east-1")},
+		{Account: aws.String(mockAccount), Region: aws.String("us-west-2")},
+	}, instances)
+}
+
+func TestCloudFormation_DescribeStackSetOperation(t *testing.T) {
+	testCases := map[string]struct {
+		createMock  func(ctrl *gomock.Controller) client
+		wantedDescr *types.StackSetOperationDescription
+		wantedErr   error
+	}{
+		"return error if describe call fails": {
+			createMock: func(ctrl *gomock.Controller) client {
+				m := mocks.NewMockclient(ctrl)
+				m.EXPECT().DescribeStackSetOperation(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, genericApiError)
+				return m
+			},
+			wantedErr: fmt.Errorf("describe operation mock-operation-id for stack set mock-stack-set-1234: %w", genericApiError),
+		},
+		"return ErrStackSetOperationNotFound if operation does not exist": {
+			createMock: func(ctrl *gomock.Controller) client {
+				m := mocks.NewMockclient(ctrl)
+				m.EXPECT().DescribeStackSetOperation(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errOperationDoesNotExist)
+				return m
+			},
+			wantedErr: &ErrStackSetOperationNotFound{id: mockOperationId, stackSetName: mockStackSetName},
+		},
+		"returns a StackSetOperationDescription if operation exists": {
+			createMock: func(ctrl *gomock.Controller) client {
+				m := mocks.NewMockclient(ctrl)
+				expectedIn := &cloudformation.DescribeStackSetOperationInput{
+					StackSetName: aws.String(mockStackSetName),
+					OperationId:  aws.String(mockOperationId),
+				}
+				m.EXPECT().DescribeStackSetOperation(gomock.Any(), gomock.Eq(expectedIn), gomock.Any()).Return(&cloudformation.DescribeStackSetOperationOutput{
+					StackSetOperation: &sdktypes.StackSetOperation{
+						OperationId: aws.String(mockOperationId),
+						Status:      sdktypes.StackSetOperationStatusRunning,
+					},
+				}, nil)
+				return m
+			},
+			wantedDescr: &types.StackSetOperationDescription{
+				OperationId: aws.String(mockOperationId),
+				Status:      sdktypes.StackSetOperationStatusRunning,
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			// GIVEN
+			mockStackSet := generateMockStackSet()
+			ctrl, ctx := gomock.WithContext(context.Background(), t)
+			defer ctrl.Finish()
+			c := CloudFormation{
+				client: tc.createMock(ctrl),
+			}
+
+			// WHEN
+			descr, err := c.DescribeStackSetOperation(ctx, mockStackSet, mockOperationId)
+
+			// THEN
+			require.Equal(t, tc.wantedDescr, descr)
+			require.Equal(t, tc.wantedErr, err)
+		})
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT-0
+
+package clients
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/aws/smithy-go/middleware"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/aws/aws-sdk-go-v2/otelaws"
+
+	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/clients/types"
+	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/metrics"
+)
+
+// LoadConfig loads the AWS SDK configuration of the controller's clients for the given region,
+// with the controller's user agent, API call metrics, tracing, retries, rate limits and timeouts.
+func LoadConfig(ctx context.Context, region string, opts types.ClientOptions) (aws.Config, error) {
+	loadOpts := []func(*config.LoadOptions) error{
+		config.WithAPIOptions([]func(*middleware.Stack) error{
+			awsmiddleware.AddUserAgentKey("cfn-flux-controller"),
+			metrics.AddAPICallMetrics,
+		}),
+		config.WithRegion(region),
+	}
+	if opts.RetryMode != "" {
+		loadOpts = append(loadOpts, config.WithRetryMode(opts.RetryMode))
+	}
+	if opts.RetryMaxAttempts > 0 {
+		loadOpts = append(loadOpts, config.WithRetryMaxAttempts(opts.RetryMaxAttempts))
+	}
+
+	cfg, err := config.LoadDefaultConfig(ctx, loadOpts...)
+	if err != nil {
+		return aws.Config{}, err
+	}
+	if opts.RateLimiter != nil {
+		cfg.APIOptions = append(cfg.APIOptions, opts.RateLimiter.APIOptions(cfg.Credentials)...)
+	}
+	if opts.Timeout > 0 || len(opts.OperationTimeouts) > 0 {
+		cfg.APIOptions = append(cfg.APIOptions, addOperationTimeouts(opts.Timeout, opts.OperationTimeouts))
+	}
+	otelaws.AppendMiddlewares(&cfg.APIOptions)
+	return cfg, nil
+}
+
+// CallerAccount returns the AWS account ID of the controller's credentials.
+func CallerAccount(ctx context.Context, region string, opts types.ClientOptions) (string, error) {
+	cfg, err := LoadConfig(ctx, region, opts)
+	if err != nil {
+		return "", err
+	}
+	identity, err := sts.NewFromConfig(cfg).GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
+	if err != nil {
+		return "", err
+	}
+	return aws.ToString(identity.Account), nil
+}
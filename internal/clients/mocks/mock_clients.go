@@ -5,6 +5,7 @@
 package mocks
 
 import (
+	context "context"
 	io "io"
 	reflect "reflect"
 
@@ -36,119 +37,378 @@ func (m *MockCloudFormationClient) EXPECT() *MockCloudFormationClientMockRecorde
 }
 
 // ContinueStackRollback mocks base method.
-func (m *MockCloudFormationClient) ContinueStackRollback(stack *types.Stack) error {
+func (m *MockCloudFormationClient) ContinueStackRollback(ctx context.Context, stack *types.Stack) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ContinueStackRollback", stack)
+	ret := m.ctrl.Call(m, "ContinueStackRollback", ctx, stack)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // ContinueStackRollback indicates an expected call of ContinueStackRollback.
-func (mr *MockCloudFormationClientMockRecorder) ContinueStackRollback(stack interface{}) *gomock.Call {
+func (mr *MockCloudFormationClientMockRecorder) ContinueStackRollback(ctx, stack interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ContinueStackRollback", reflect.TypeOf((*MockCloudFormationClient)(nil).ContinueStackRollback), stack)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ContinueStackRollback", reflect.TypeOf((*MockCloudFormationClient)(nil).ContinueStackRollback), ctx, stack)
 }
 
 // CreateStack mocks base method.
-func (m *MockCloudFormationClient) CreateStack(stack *types.Stack) (string, error) {
+func (m *MockCloudFormationClient) CreateStack(ctx context.Context, stack *types.Stack) (string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateStack", stack)
+	ret := m.ctrl.Call(m, "CreateStack", ctx, stack)
 	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // CreateStack indicates an expected call of CreateStack.
-func (mr *MockCloudFormationClientMockRecorder) CreateStack(stack interface{}) *gomock.Call {
+func (mr *MockCloudFormationClientMockRecorder) CreateStack(ctx, stack interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateStack", reflect.TypeOf((*MockCloudFormationClient)(nil).CreateStack), stack)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateStack", reflect.TypeOf((*MockCloudFormationClient)(nil).CreateStack), ctx, stack)
 }
 
 // DeleteChangeSet mocks base method.
-func (m *MockCloudFormationClient) DeleteChangeSet(stack *types.Stack) error {
+func (m *MockCloudFormationClient) DeleteChangeSet(ctx context.Context, stack *types.Stack) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteChangeSet", stack)
+	ret := m.ctrl.Call(m, "DeleteChangeSet", ctx, stack)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // DeleteChangeSet indicates an expected call of DeleteChangeSet.
-func (mr *MockCloudFormationClientMockRecorder) DeleteChangeSet(stack interface{}) *gomock.Call {
+func (mr *MockCloudFormationClientMockRecorder) DeleteChangeSet(ctx, stack interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteChangeSet", reflect.TypeOf((*MockCloudFormationClient)(nil).DeleteChangeSet), stack)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteChangeSet", reflect.TypeOf((*MockCloudFormationClient)(nil).DeleteChangeSet), ctx, stack)
 }
 
 // DeleteStack mocks base method.
-func (m *MockCloudFormationClient) DeleteStack(stack *types.Stack) error {
+func (m *MockCloudFormationClient) DeleteStack(ctx context.Context, stack *types.Stack) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteStack", stack)
+	ret := m.ctrl.Call(m, "DeleteStack", ctx, stack)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // DeleteStack indicates an expected call of DeleteStack.
-func (mr *MockCloudFormationClientMockRecorder) DeleteStack(stack interface{}) *gomock.Call {
+func (mr *MockCloudFormationClientMockRecorder) DeleteStack(ctx, stack interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteStack", reflect.TypeOf((*MockCloudFormationClient)(nil).DeleteStack), stack)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteStack", reflect.TypeOf((*MockCloudFormationClient)(nil).DeleteStack), ctx, stack)
 }
 
 // DescribeChangeSet mocks base method.
-func (m *MockCloudFormationClient) DescribeChangeSet(stack *types.Stack) (*types.ChangeSetDescription, error) {
+func (m *MockCloudFormationClient) DescribeChangeSet(ctx context.Context, stack *types.Stack) (*types.ChangeSetDescription, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DescribeChangeSet", stack)
+	ret := m.ctrl.Call(m, "DescribeChangeSet", ctx, stack)
 	ret0, _ := ret[0].(*types.ChangeSetDescription)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // DescribeChangeSet indicates an expected call of DescribeChangeSet.
-func (mr *MockCloudFormationClientMockRecorder) DescribeChangeSet(stack interface{}) *gomock.Call {
+func (mr *MockCloudFormationClientMockRecorder) DescribeChangeSet(ctx, stack interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DescribeChangeSet", reflect.TypeOf((*MockCloudFormationClient)(nil).DescribeChangeSet), stack)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DescribeChangeSet", reflect.TypeOf((*MockCloudFormationClient)(nil).DescribeChangeSet), ctx, stack)
 }
 
 // DescribeStack mocks base method.
-func (m *MockCloudFormationClient) DescribeStack(stack *types.Stack) (*types.StackDescription, error) {
+func (m *MockCloudFormationClient) DescribeStack(ctx context.Context, stack *types.Stack) (*types.StackDescription, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DescribeStack", stack)
+	ret := m.ctrl.Call(m, "DescribeStack", ctx, stack)
 	ret0, _ := ret[0].(*types.StackDescription)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // DescribeStack indicates an expected call of DescribeStack.
-func (mr *MockCloudFormationClientMockRecorder) DescribeStack(stack interface{}) *gomock.Call {
+func (mr *MockCloudFormationClientMockRecorder) DescribeStack(ctx, stack interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DescribeStack", reflect.TypeOf((*MockCloudFormationClient)(nil).DescribeStack), stack)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DescribeStack", reflect.TypeOf((*MockCloudFormationClient)(nil).DescribeStack), ctx, stack)
+}
+
+// DescribeStackResources mocks base method.
+func (m *MockCloudFormationClient) DescribeStackResources(ctx context.Context, stack *types.Stack) ([]types.StackResource, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DescribeStackResources", ctx, stack)
+	ret0, _ := ret[0].([]types.StackResource)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DescribeStackResources indicates an expected call of DescribeStackResources.
+func (mr *MockCloudFormationClientMockRecorder) DescribeStackResources(ctx, stack interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DescribeStackResources", reflect.TypeOf((*MockCloudFormationClient)(nil).DescribeStackResources), ctx, stack)
 }
 
 // ExecuteChangeSet mocks base method.
-func (m *MockCloudFormationClient) ExecuteChangeSet(stack *types.Stack) error {
+func (m *MockCloudFormationClient) ExecuteChangeSet(ctx context.Context, stack *types.Stack) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ExecuteChangeSet", stack)
+	ret := m.ctrl.Call(m, "ExecuteChangeSet", ctx, stack)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // ExecuteChangeSet indicates an expected call of ExecuteChangeSet.
-func (mr *MockCloudFormationClientMockRecorder) ExecuteChangeSet(stack interface{}) *gomock.Call {
+func (mr *MockCloudFormationClientMockRecorder) ExecuteChangeSet(ctx, stack interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecuteChangeSet", reflect.TypeOf((*MockCloudFormationClient)(nil).ExecuteChangeSet), stack)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecuteChangeSet", reflect.TypeOf((*MockCloudFormationClient)(nil).ExecuteChangeSet), ctx, stack)
 }
 
 // UpdateStack mocks base method.
-func (m *MockCloudFormationClient) UpdateStack(stack *types.Stack) (string, error) {
+func (m *MockCloudFormationClient) UpdateStack(ctx context.Context, stack *types.Stack) (string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateStack", stack)
+	ret := m.ctrl.Call(m, "UpdateStack", ctx, stack)
 	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // UpdateStack indicates an expected call of UpdateStack.
-func (mr *MockCloudFormationClientMockRecorder) UpdateStack(stack interface{}) *gomock.Call {
+func (mr *MockCloudFormationClientMockRecorder) UpdateStack(ctx, stack interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStack", reflect.TypeOf((*MockCloudFormationClient)(nil).UpdateStack), stack)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStack", reflect.TypeOf((*MockCloudFormationClient)(nil).UpdateStack), ctx, stack)
+}
+
+// MockCloudFormationStackCache is a mock of CloudFormationStackCache interface.
+type MockCloudFormationStackCache struct {
+	ctrl     *gomock.Controller
+	recorder *MockCloudFormationStackCacheMockRecorder
+}
+
+// MockCloudFormationStackCacheMockRecorder is the mock recorder for MockCloudFormationStackCache.
+type MockCloudFormationStackCacheMockRecorder struct {
+	mock *MockCloudFormationStackCache
+}
+
+// NewMockCloudFormationStackCache creates a new mock instance.
+func NewMockCloudFormationStackCache(ctrl *gomock.Controller) *MockCloudFormationStackCache {
+	mock := &MockCloudFormationStackCache{ctrl: ctrl}
+	mock.recorder = &MockCloudFormationStackCacheMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCloudFormationStackCache) EXPECT() *MockCloudFormationStackCacheMockRecorder {
+	return m.recorder
+}
+
+// DescribeStack mocks base method.
+func (m *MockCloudFormationStackCache) DescribeStack(ctx context.Context, stack *types.Stack) (*types.StackDescription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DescribeStack", ctx, stack)
+	ret0, _ := ret[0].(*types.StackDescription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DescribeStack indicates an expected call of DescribeStack.
+func (mr *MockCloudFormationStackCacheMockRecorder) DescribeStack(ctx, stack interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DescribeStack", reflect.TypeOf((*MockCloudFormationStackCache)(nil).DescribeStack), ctx, stack)
+}
+
+// StoreStack mocks base method.
+func (m *MockCloudFormationStackCache) StoreStack(stack *types.Stack, desc *types.StackDescription) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "StoreStack", stack, desc)
+}
+
+// StoreStack indicates an expected call of StoreStack.
+func (mr *MockCloudFormationStackCacheMockRecorder) StoreStack(stack, desc interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StoreStack", reflect.TypeOf((*MockCloudFormationStackCache)(nil).StoreStack), stack, desc)
+}
+
+// MockCloudFormationStackSetClient is a mock of CloudFormationStackSetClient interface.
+type MockCloudFormationStackSetClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockCloudFormationStackSetClientMockRecorder
+}
+
+// MockCloudFormationStackSetClientMockRecorder is the mock recorder for MockCloudFormationStackSetClient.
+type MockCloudFormationStackSetClientMockRecorder struct {
+	mock *MockCloudFormationStackSetClient
+}
+
+// NewMockCloudFormationStackSetClient creates a new mock instance.
+func NewMockCloudFormationStackSetClient(ctrl *gomock.Controller) *MockCloudFormationStackSetClient {
+	mock := &MockCloudFormationStackSetClient{ctrl: ctrl}
+	mock.recorder = &MockCloudFormationStackSetClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCloudFormationStackSetClient) EXPECT() *MockCloudFormationStackSetClientMockRecorder {
+	return m.recorder
+}
+
+// CreateStackInstances mocks base method.
+func (m *MockCloudFormationStackSetClient) CreateStackInstances(ctx context.Context, stackSet *types.StackSet, targets *types.StackInstanceTargets) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateStackInstances", ctx, stackSet, targets)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateStackInstances indicates an expected call of CreateStackInstances.
+func (mr *MockCloudFormationStackSetClientMockRecorder) CreateStackInstances(ctx, stackSet, targets interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateStackInstances", reflect.TypeOf((*MockCloudFormationStackSetClient)(nil).CreateStackInstances), ctx, stackSet, targets)
+}
+
+// CreateStackSet mocks base method.
+func (m *MockCloudFormationStackSetClient) CreateStackSet(ctx context.Context, stackSet *types.StackSet) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateStackSet", ctx, stackSet)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateStackSet indicates an expected call of CreateStackSet.
+func (mr *MockCloudFormationStackSetClientMockRecorder) CreateStackSet(ctx, stackSet interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateStackSet", reflect.TypeOf((*MockCloudFormationStackSetClient)(nil).CreateStackSet), ctx, stackSet)
+}
+
+// DeleteStackInstances mocks base method.
+func (m *MockCloudFormationStackSetClient) DeleteStackInstances(ctx context.Context, stackSet *types.StackSet, targets *types.StackInstanceTargets, retainStacks bool) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteStackInstances", ctx, stackSet, targets, retainStacks)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteStackInstances indicates an expected call of DeleteStackInstances.
+func (mr *MockCloudFormationStackSetClientMockRecorder) DeleteStackInstances(ctx, stackSet, targets, retainStacks interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteStackInstances", reflect.TypeOf((*MockCloudFormationStackSetClient)(nil).DeleteStackInstances), ctx, stackSet, targets, retainStacks)
+}
+
+// DeleteStackSet mocks base method.
+func (m *MockCloudFormationStackSetClient) DeleteStackSet(ctx context.Context, stackSet *types.StackSet) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteStackSet", ctx, stackSet)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteStackSet indicates an expected call of DeleteStackSet.
+func (mr *MockCloudFormationStackSetClientMockRecorder) DeleteStackSet(ctx, stackSet interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteStackSet", reflect.TypeOf((*MockCloudFormationStackSetClient)(nil).DeleteStackSet), ctx, stackSet)
+}
+
+// DescribeStackSet mocks base method.
+func (m *MockCloudFormationStackSetClient) DescribeStackSet(ctx context.Context, stackSet *types.StackSet) (*types.StackSetDescription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DescribeStackSet", ctx, stackSet)
+	ret0, _ := ret[0].(*types.StackSetDescription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DescribeStackSet indicates an expected call of DescribeStackSet.
+func (mr *MockCloudFormationStackSetClientMockRecorder) DescribeStackSet(ctx, stackSet interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DescribeStackSet", reflect.TypeOf((*MockCloudFormationStackSetClient)(nil).DescribeStackSet), ctx, stackSet)
+}
+
+// DescribeStackSetOperation mocks base method.
+func (m *MockCloudFormationStackSetClient) DescribeStackSetOperation(ctx context.Context, stackSet *types.StackSet, operationID string) (*types.StackSetOperationDescription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DescribeStackSetOperation", ctx, stackSet, operationID)
+	ret0, _ := ret[0].(*types.StackSetOperationDescription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DescribeStackSetOperation indicates an expected call of DescribeStackSetOperation.
+func (mr *MockCloudFormationStackSetClientMockRecorder) DescribeStackSetOperation(ctx, stackSet, operationID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DescribeStackSetOperation", reflect.TypeOf((*MockCloudFormationStackSetClient)(nil).DescribeStackSetOperation), ctx, stackSet, operationID)
+}
+
+// ListStackInstances mocks base method.
+func (m *MockCloudFormationStackSetClient) ListStackInstances(ctx context.Context, stackSet *types.StackSet) ([]*types.StackInstanceSummary, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListStackInstances", ctx, stackSet)
+	ret0, _ := ret[0].([]*types.StackInstanceSummary)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListStackInstances indicates an expected call of ListStackInstances.
+func (mr *MockCloudFormationStackSetClientMockRecorder) ListStackInstances(ctx, stackSet interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListStackInstances", reflect.TypeOf((*MockCloudFormationStackSetClient)(nil).ListStackInstances), ctx, stackSet)
+}
+
+// UpdateStackSet mocks base method.
+func (m *MockCloudFormationStackSetClient) UpdateStackSet(ctx context.Context, stackSet *types.StackSet) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateStackSet", ctx, stackSet)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateStackSet indicates an expected call of UpdateStackSet.
+func (mr *MockCloudFormationStackSetClientMockRecorder) UpdateStackSet(ctx, stackSet interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStackSet", reflect.TypeOf((*MockCloudFormationStackSetClient)(nil).UpdateStackSet), ctx, stackSet)
+}
+
+// MockCloudFormationExportClient is a mock of CloudFormationExportClient interface.
+type MockCloudFormationExportClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockCloudFormationExportClientMockRecorder
+}
+
+// MockCloudFormationExportClientMockRecorder is the mock recorder for MockCloudFormationExportClient.
+type MockCloudFormationExportClientMockRecorder struct {
+	mock *MockCloudFormationExportClient
+}
+
+// NewMockCloudFormationExportClient creates a new mock instance.
+func NewMockCloudFormationExportClient(ctrl *gomock.Controller) *MockCloudFormationExportClient {
+	mock := &MockCloudFormationExportClient{ctrl: ctrl}
+	mock.recorder = &MockCloudFormationExportClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCloudFormationExportClient) EXPECT() *MockCloudFormationExportClientMockRecorder {
+	return m.recorder
+}
+
+// GetTemplate mocks base method.
+func (m *MockCloudFormationExportClient) GetTemplate(ctx context.Context, stack *types.Stack) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTemplate", ctx, stack)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTemplate indicates an expected call of GetTemplate.
+func (mr *MockCloudFormationExportClientMockRecorder) GetTemplate(ctx, stack interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTemplate", reflect.TypeOf((*MockCloudFormationExportClient)(nil).GetTemplate), ctx, stack)
+}
+
+// ListStacks mocks base method.
+func (m *MockCloudFormationExportClient) ListStacks(ctx context.Context) ([]*types.StackDescription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListStacks", ctx)
+	ret0, _ := ret[0].([]*types.StackDescription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListStacks indicates an expected call of ListStacks.
+func (mr *MockCloudFormationExportClientMockRecorder) ListStacks(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListStacks", reflect.TypeOf((*MockCloudFormationExportClient)(nil).ListStacks), ctx)
 }
 
 // MockS3Client is a mock of S3Client interface.
@@ -174,17 +434,61 @@ func (m *MockS3Client) EXPECT() *MockS3ClientMockRecorder {
 	return m.recorder
 }
 
+// DeleteTemplates mocks base method.
+func (m *MockS3Client) DeleteTemplates(ctx context.Context, bucket, region string, keys []string, opts *types.TemplateBucketOptions) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteTemplates", ctx, bucket, region, keys, opts)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteTemplates indicates an expected call of DeleteTemplates.
+func (mr *MockS3ClientMockRecorder) DeleteTemplates(ctx, bucket, region, keys, opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTemplates", reflect.TypeOf((*MockS3Client)(nil).DeleteTemplates), ctx, bucket, region, keys, opts)
+}
+
+// HeadTemplate mocks base method.
+func (m *MockS3Client) HeadTemplate(ctx context.Context, bucket, region, key string, opts *types.TemplateBucketOptions) (*types.TemplateObject, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HeadTemplate", ctx, bucket, region, key, opts)
+	ret0, _ := ret[0].(*types.TemplateObject)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HeadTemplate indicates an expected call of HeadTemplate.
+func (mr *MockS3ClientMockRecorder) HeadTemplate(ctx, bucket, region, key, opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HeadTemplate", reflect.TypeOf((*MockS3Client)(nil).HeadTemplate), ctx, bucket, region, key, opts)
+}
+
+// ListTemplates mocks base method.
+func (m *MockS3Client) ListTemplates(ctx context.Context, bucket, region, prefix string, opts *types.TemplateBucketOptions) ([]*types.TemplateObject, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTemplates", ctx, bucket, region, prefix, opts)
+	ret0, _ := ret[0].([]*types.TemplateObject)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTemplates indicates an expected call of ListTemplates.
+func (mr *MockS3ClientMockRecorder) ListTemplates(ctx, bucket, region, prefix, opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTemplates", reflect.TypeOf((*MockS3Client)(nil).ListTemplates), ctx, bucket, region, prefix, opts)
+}
+
 // UploadTemplate mocks base method.
-func (m *MockS3Client) UploadTemplate(bucket, region, key string, data io.Reader) (string, error) {
+func (m *MockS3Client) UploadTemplate(ctx context.Context, bucket, region, key string, data io.Reader, opts *types.TemplateBucketOptions) (string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UploadTemplate", bucket, region, key, data)
+	ret := m.ctrl.Call(m, "UploadTemplate", ctx, bucket, region, key, data, opts)
 	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // UploadTemplate indicates an expected call of UploadTemplate.
-func (mr *MockS3ClientMockRecorder) UploadTemplate(bucket, region, key, data interface{}) *gomock.Call {
+func (mr *MockS3ClientMockRecorder) UploadTemplate(ctx, bucket, region, key, data, opts interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UploadTemplate", reflect.TypeOf((*MockS3Client)(nil).UploadTemplate), bucket, region, key, data)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UploadTemplate", reflect.TypeOf((*MockS3Client)(nil).UploadTemplate), ctx, bucket, region, key, data, opts)
 }
@@ -0,0 +1,203 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT-0
+
+// Package ratelimit provides a controller-wide rate limiter for the controller's AWS API calls,
+// and detects when the AWS APIs throttle the controller's calls so that the controller can back off.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/smithy-go/middleware"
+	"golang.org/x/time/rate"
+)
+
+// The AWS SDK service IDs of the rate limited services
+const (
+	ServiceCloudFormation = "CloudFormation"
+	ServiceS3             = "S3"
+)
+
+const (
+	// throttleWindow is the period after a throttled call during which further throttled calls
+	// increase the backoff, and after which the backoff is reset.
+	throttleWindow = 2 * time.Minute
+
+	// maxBackoffLevel caps the backoff factor of poll intervals at 2^maxBackoffLevel.
+	maxBackoffLevel = 5
+
+	// maxBackoffInterval caps the poll intervals that are backed off.
+	maxBackoffInterval = 5 * time.Minute
+)
+
+// Limit is the rate limit of the calls to an AWS service.
+type Limit struct {
+	// Rate is the number of calls per second. Zero disables the rate limit.
+	Rate float64
+	// Burst is the maximum number of calls that can be made at once.
+	Burst int
+}
+
+// Limiter rate limits the AWS API calls of all the controller's clients with a token bucket per service, account and region,
+// and tracks the throttled calls per service, account and region.
+type Limiter struct {
+	limits map[string]Limit
+
+	mu        sync.Mutex
+	buckets   map[bucketKey]*rate.Limiter
+	throttles map[bucketKey]*throttleState
+
+	// now returns the current time, for tests
+	now func() time.Time
+}
+
+type bucketKey struct {
+	service string
+	account string
+	region  string
+}
+
+// throttleState is the backoff level of the calls of a service, account and region
+type throttleState struct {
+	level int
+	last  time.Time
+}
+
+// New creates a rate limiter with the given limits, keyed by AWS SDK service ID.
+// Calls to services without a limit are not rate limited.
+func New(limits map[string]Limit) *Limiter {
+	return &Limiter{
+		limits:    limits,
+		buckets:   map[bucketKey]*rate.Limiter{},
+		throttles: map[bucketKey]*throttleState{},
+		now:       time.Now,
+	}
+}
+
+// APIOptions returns the AWS SDK API options that rate limit the calls of a client created from the given config.
+// The middleware is added at the end of the finalize step, after the retry middleware, so that each attempt
+// of a call is rate limited. The account of a call is taken from the config's credentials, if known.
+func (l *Limiter) APIOptions(credentials aws.CredentialsProvider) []func(*middleware.Stack) error {
+	return []func(*middleware.Stack) error{
+		func(stack *middleware.Stack) error {
+			return stack.Finalize.Add(middleware.FinalizeMiddlewareFunc("RateLimit", func(ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler) (middleware.FinalizeOutput, middleware.Metadata, error) {
+				service := awsmiddleware.GetServiceID(ctx)
+				accountID, region := account(ctx, credentials), awsmiddleware.GetRegion(ctx)
+				if err := l.wait(ctx, service, accountID, region); err != nil {
+					return middleware.FinalizeOutput{}, middleware.Metadata{}, err
+				}
+				out, metadata, err := next.HandleFinalize(ctx, in)
+				if IsThrottle(err) {
+					l.throttled(service, accountID, region)
+				}
+				return out, metadata, err
+			}), middleware.After)
+		},
+	}
+}
+
+// account returns the account ID of the credentials, or an empty string if it is not known
+func account(ctx context.Context, credentials aws.CredentialsProvider) string {
+	if credentials == nil {
+		return ""
+	}
+	creds, err := credentials.Retrieve(ctx)
+	if err != nil {
+		return ""
+	}
+	return creds.AccountID
+}
+
+// wait blocks until the token bucket of the service, account and region allows a call
+func (l *Limiter) wait(ctx context.Context, service, account, region string) error {
+	limit, ok := l.limits[service]
+	if !ok || limit.Rate <= 0 {
+		return nil
+	}
+
+	key := bucketKey{service: service, account: account, region: region}
+	l.mu.Lock()
+	bucket, ok := l.buckets[key]
+	if !ok {
+		burst := limit.Burst
+		if burst < 1 {
+			burst = 1
+		}
+		bucket = rate.NewLimiter(rate.Limit(limit.Rate), burst)
+		l.buckets[key] = bucket
+	}
+	l.mu.Unlock()
+
+	if err := bucket.Wait(ctx); err != nil {
+		return fmt.Errorf("wait for %s rate limit: %w", service, err)
+	}
+	return nil
+}
+
+// throttled records a throttled call of the service, account and region,
+// increasing the backoff of the poll intervals of that account and region
+func (l *Limiter) throttled(service, account, region string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	key := bucketKey{service: service, account: account, region: region}
+	state, ok := l.throttles[key]
+	if !ok {
+		state = &throttleState{}
+		l.throttles[key] = state
+	}
+	now := l.now()
+	if now.Sub(state.last) > throttleWindow {
+		state.level = 0
+	}
+	if state.level < maxBackoffLevel {
+		state.level++
+	}
+	state.last = now
+}
+
+// Backoff returns the given poll interval, backed off if calls to any service in the given account and region
+// were recently throttled. An empty account, or calls whose account is not known, match all accounts.
+// The interval doubles for each recently throttled call, up to 5 minutes;
+// longer intervals are returned unchanged.
+func (l *Limiter) Backoff(account, region string, interval time.Duration) time.Duration {
+	if l == nil {
+		return interval
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	level := 0
+	now := l.now()
+	for key, state := range l.throttles {
+		if key.region != region || (account != "" && key.account != "" && key.account != account) {
+			continue
+		}
+		if now.Sub(state.last) <= throttleWindow && state.level > level {
+			level = state.level
+		}
+	}
+	if level == 0 {
+		return interval
+	}
+	backoff := interval << level
+	if backoff > maxBackoffInterval {
+		backoff = maxBackoffInterval
+	}
+	if backoff < interval {
+		return interval
+	}
+	return backoff
+}
+
+// IsThrottle returns true if the error is, or wraps, an AWS API throttling error.
+func IsThrottle(err error) bool {
+	if err == nil {
+		return false
+	}
+	return retry.IsErrorThrottles(retry.DefaultThrottles).IsErrorThrottle(err) == aws.TrueTernary
+}
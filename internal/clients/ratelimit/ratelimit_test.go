@@ -0,0 +1,109 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT-0
+
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aws/smithy-go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimiter_Backoff(t *testing.T) {
+	now := time.Now()
+	l := New(nil)
+	l.now = func() time.Time { return now }
+
+	// No backoff before any calls are throttled
+	require.Equal(t, 5*time.Second, l.Backoff("123456789012", "us-west-2", 5*time.Second))
+
+	// The backoff doubles for each throttled call
+	l.throttled(ServiceCloudFormation, "123456789012", "us-west-2")
+	require.Equal(t, 10*time.Second, l.Backoff("123456789012", "us-west-2", 5*time.Second))
+	l.throttled(ServiceCloudFormation, "123456789012", "us-west-2")
+	require.Equal(t, 20*time.Second, l.Backoff("123456789012", "us-west-2", 5*time.Second))
+
+	// The backoff is capped
+	for i := 0; i < 10; i++ {
+		l.throttled(ServiceCloudFormation, "123456789012", "us-west-2")
+	}
+	require.Equal(t, 160*time.Second, l.Backoff("123456789012", "us-west-2", 5*time.Second))
+	require.Equal(t, 5*time.Minute, l.Backoff("123456789012", "us-west-2", time.Minute))
+
+	// Long intervals are not backed off
+	require.Equal(t, time.Hour, l.Backoff("123456789012", "us-west-2", time.Hour))
+
+	// The backoff is reset after a period without throttled calls
+	now = now.Add(throttleWindow + time.Second)
+	require.Equal(t, 5*time.Second, l.Backoff("123456789012", "us-west-2", 5*time.Second))
+	l.throttled(ServiceCloudFormation, "123456789012", "us-west-2")
+	require.Equal(t, 10*time.Second, l.Backoff("123456789012", "us-west-2", 5*time.Second))
+
+	// A nil limiter does not back off
+	var nilLimiter *Limiter
+	require.Equal(t, 5*time.Second, nilLimiter.Backoff("123456789012", "us-west-2", 5*time.Second))
+}
+
+func TestLimiter_BackoffPerAccountAndRegion(t *testing.T) {
+	now := time.Now()
+	l := New(nil)
+	l.now = func() time.Time { return now }
+
+	// A throttled call in one region does not back off other regions
+	l.throttled(ServiceCloudFormation, "123456789012", "us-west-2")
+	require.Equal(t, 10*time.Second, l.Backoff("123456789012", "us-west-2", 5*time.Second))
+	require.Equal(t, 5*time.Second, l.Backoff("123456789012", "us-east-1", 5*time.Second))
+
+	// A throttled call in one account does not back off other accounts
+	require.Equal(t, 5*time.Second, l.Backoff("210987654321", "us-west-2", 5*time.Second))
+
+	// Throttled calls of any service back off the account and region, by the highest backoff
+	l.throttled(ServiceS3, "123456789012", "us-east-1")
+	l.throttled(ServiceS3, "123456789012", "us-east-1")
+	require.Equal(t, 20*time.Second, l.Backoff("123456789012", "us-east-1", 5*time.Second))
+	require.Equal(t, 10*time.Second, l.Backoff("123456789012", "us-west-2", 5*time.Second))
+
+	// Unknown accounts match all the accounts of the region
+	require.Equal(t, 10*time.Second, l.Backoff("", "us-west-2", 5*time.Second))
+	l.throttled(ServiceCloudFormation, "", "eu-west-1")
+	require.Equal(t, 10*time.Second, l.Backoff("210987654321", "eu-west-1", 5*time.Second))
+}
+
+func TestLimiter_Wait(t *testing.T) {
+	l := New(map[string]Limit{
+		ServiceCloudFormation: {Rate: 1, Burst: 2},
+	})
+
+	// Calls within the burst are not delayed
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	require.NoError(t, l.wait(ctx, ServiceCloudFormation, "123456789012", "us-west-2"))
+	require.NoError(t, l.wait(ctx, ServiceCloudFormation, "123456789012", "us-west-2"))
+
+	// Calls above the burst wait for the bucket to refill
+	err := l.wait(ctx, ServiceCloudFormation, "123456789012", "us-west-2")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "wait for CloudFormation rate limit")
+
+	// Other accounts and regions have their own bucket
+	require.NoError(t, l.wait(ctx, ServiceCloudFormation, "123456789012", "us-east-1"))
+	require.NoError(t, l.wait(ctx, ServiceCloudFormation, "210987654321", "us-west-2"))
+
+	// Services without a limit are not rate limited
+	for i := 0; i < 10; i++ {
+		require.NoError(t, l.wait(ctx, ServiceS3, "123456789012", "us-west-2"))
+	}
+}
+
+func TestIsThrottle(t *testing.T) {
+	require.False(t, IsThrottle(nil))
+	require.False(t, IsThrottle(errors.New("hello world")))
+	require.False(t, IsThrottle(&smithy.GenericAPIError{Code: "ValidationError", Message: "Stack does not exist"}))
+	require.True(t, IsThrottle(&smithy.GenericAPIError{Code: "Throttling", Message: "Rate exceeded"}))
+	require.True(t, IsThrottle(fmt.Errorf("describe stack my-stack: %w", &smithy.GenericAPIError{Code: "ThrottlingException", Message: "Rate exceeded"})))
+}
@@ -0,0 +1,33 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT-0
+
+package s3
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/aws/smithy-go"
+)
+
+// ErrObjectNotFound occurs when an S3 object does not exist.
+type ErrObjectNotFound struct {
+	bucket string
+	key    string
+}
+
+func (e *ErrObjectNotFound) Error() string {
+	return fmt.Sprintf("object %s cannot be found in bucket %s", e.key, e.bucket)
+}
+
+// objectDoesNotExist returns true if the underlying error is an object doesn't exist.
+func objectDoesNotExist(err error) bool {
+	var ae smithy.APIError
+	if errors.As(err, &ae) {
+		switch ae.ErrorCode() {
+		case errCodeNotFound:
+			return true
+		}
+	}
+	return false
+}
@@ -6,22 +6,31 @@ package s3
 
 import (
 	"context"
+	"crypto/sha256"
+	"fmt"
 	"io"
+	"net/url"
+	"sort"
+	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
-	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
-	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"github.com/aws/aws-sdk-go-v2/service/sts"
-	"github.com/aws/smithy-go/middleware"
 	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/clients"
+	clienttypes "github.com/awslabs/aws-cloudformation-controller-for-flux/internal/clients/types"
 )
 
 const (
 	// Error codes.
 	errCodeNotFound = "NotFound"
+
+	// The maximum number of keys that can be deleted in a single DeleteObjects request
+	maxDeleteObjectsKeys = 1000
+
+	// The user metadata key of the digest of the options that a template was uploaded with
+	optionsDigestMetadataKey = "flux-options-digest"
 )
 
 // S3 wraps an Amazon S3 client.
@@ -29,18 +38,12 @@ type S3 struct {
 	manager   s3ManagerAPI
 	client    s3API
 	stsClient stsAPI
-	ctx       context.Context
+	region    string
 }
 
 // New returns an S3 client.
-func New(ctx context.Context, region string) (clients.S3Client, error) {
-	cfg, err := config.LoadDefaultConfig(
-		ctx,
-		config.WithAPIOptions([]func(*middleware.Stack) error{
-			awsmiddleware.AddUserAgentKey("cfn-flux-controller"),
-		}),
-		config.WithRegion(region),
-	)
+func New(ctx context.Context, region string, opts clienttypes.ClientOptions) (clients.S3Client, error) {
+	cfg, err := clients.LoadConfig(ctx, region, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -52,55 +55,224 @@ func New(ctx context.Context, region string) (clients.S3Client, error) {
 		client:    client,
 		manager:   manager.NewUploader(client),
 		stsClient: stsClient,
-		ctx:       ctx,
+		region:    cfg.Region,
 	}, nil
 }
 
 // Upload uploads a template file to an S3 bucket under the specified key.
 // Returns an object URL that can be passed directly to CloudFormation
-func (s *S3) UploadTemplate(bucket, region, key string, data io.Reader) (string, error) {
-	url, err := s.upload(bucket, region, key, data)
-	if err != nil {
+func (s *S3) UploadTemplate(ctx context.Context, bucket, region, key string, data io.Reader, opts *clienttypes.TemplateBucketOptions) (string, error) {
+	if err := s.upload(ctx, bucket, region, key, data, opts); err != nil {
 		return "", err
 	}
-	return url, nil
+	return s.objectURL(bucket, region, key), nil
+}
+
+// HeadTemplate retrieves the metadata of a template file in an S3 bucket, including whether the template
+// was uploaded with the KMS key, object tags and ACL of the specified options.
+// Returns ErrObjectNotFound if there is no template file under the specified key.
+func (s *S3) HeadTemplate(ctx context.Context, bucket, region, key string, opts *clienttypes.TemplateBucketOptions) (*clienttypes.TemplateObject, error) {
+	expectedBucketOwner, err := s.expectedBucketOwner(ctx, region, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := s.client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket:              aws.String(bucket),
+		Key:                 aws.String(key),
+		ExpectedBucketOwner: expectedBucketOwner,
+	}, withRegion(region))
+	if err != nil {
+		if objectDoesNotExist(err) {
+			return nil, &ErrObjectNotFound{bucket: bucket, key: key}
+		}
+		return nil, fmt.Errorf("head object %s in bucket %s: %w", key, bucket, err)
+	}
+
+	return &clienttypes.TemplateObject{
+		Key:            key,
+		URL:            s.objectURL(bucket, region, key),
+		LastModified:   aws.ToTime(out.LastModified),
+		MatchesOptions: out.Metadata[optionsDigestMetadataKey] == optionsDigest(opts),
+	}, nil
+}
+
+// ListTemplates lists the template files in an S3 bucket with keys starting with the specified prefix.
+func (s *S3) ListTemplates(ctx context.Context, bucket, region, prefix string, opts *clienttypes.TemplateBucketOptions) ([]*clienttypes.TemplateObject, error) {
+	expectedBucketOwner, err := s.expectedBucketOwner(ctx, region, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var objects []*clienttypes.TemplateObject
+	paginator := s3.NewListObjectsV2Paginator(s.client, &s3.ListObjectsV2Input{
+		Bucket:              aws.String(bucket),
+		Prefix:              aws.String(prefix),
+		ExpectedBucketOwner: expectedBucketOwner,
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx, withRegion(region))
+		if err != nil {
+			return nil, fmt.Errorf("list objects with prefix %s in bucket %s: %w", prefix, bucket, err)
+		}
+		for _, object := range page.Contents {
+			key := aws.ToString(object.Key)
+			objects = append(objects, &clienttypes.TemplateObject{
+				Key:          key,
+				URL:          s.objectURL(bucket, region, key),
+				LastModified: aws.ToTime(object.LastModified),
+			})
+		}
+	}
+	return objects, nil
+}
+
+// DeleteTemplates deletes the template files with the specified keys from an S3 bucket.
+func (s *S3) DeleteTemplates(ctx context.Context, bucket, region string, keys []string, opts *clienttypes.TemplateBucketOptions) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	expectedBucketOwner, err := s.expectedBucketOwner(ctx, region, opts)
+	if err != nil {
+		return err
+	}
+
+	for start := 0; start < len(keys); start += maxDeleteObjectsKeys {
+		end := start + maxDeleteObjectsKeys
+		if end > len(keys) {
+			end = len(keys)
+		}
+		var objects []types.ObjectIdentifier
+		for _, key := range keys[start:end] {
+			objects = append(objects, types.ObjectIdentifier{Key: aws.String(key)})
+		}
+
+		out, err := s.client.DeleteObjects(ctx, &s3.DeleteObjectsInput{
+			Bucket: aws.String(bucket),
+			Delete: &types.Delete{
+				Objects: objects,
+				Quiet:   aws.Bool(true),
+			},
+			ExpectedBucketOwner: expectedBucketOwner,
+		}, withRegion(region))
+		if err != nil {
+			return fmt.Errorf("delete objects from bucket %s: %w", bucket, err)
+		}
+		if len(out.Errors) > 0 {
+			deleteErr := out.Errors[0]
+			return fmt.Errorf("delete object %s from bucket %s: %s: %s", aws.ToString(deleteErr.Key), bucket,
+				aws.ToString(deleteErr.Code), aws.ToString(deleteErr.Message))
+		}
+	}
+	return nil
 }
 
-func (s *S3) upload(bucket, region, key string, buf io.Reader) (string, error) {
-	// The expected bucket owner is the current caller
-	identityResp, err := s.stsClient.GetCallerIdentity(s.ctx, &sts.GetCallerIdentityInput{}, func(opts *sts.Options) {
+// expectedBucketOwner returns the account ID that is expected to own the template bucket.
+// Unless configured otherwise, the expected bucket owner is the current caller.
+func (s *S3) expectedBucketOwner(ctx context.Context, region string, opts *clienttypes.TemplateBucketOptions) (*string, error) {
+	if opts != nil && opts.ExpectedBucketOwner != "" {
+		return aws.String(opts.ExpectedBucketOwner), nil
+	}
+	identityResp, err := s.stsClient.GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{}, func(opts *sts.Options) {
 		if region != "" {
 			opts.Region = region
 		}
 	})
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+	return identityResp.Account, nil
+}
+
+// objectURL returns the virtual-hosted-style URL of an object.
+// The URL is built from the bucket, region and key rather than taken from the upload response, so that
+// the URL of a given object is always the same whether the object was just uploaded or already existed.
+func (s *S3) objectURL(bucket, region, key string) string {
+	if region == "" {
+		region = s.region
+	}
+	dnsSuffix := "amazonaws.com"
+	if strings.HasPrefix(region, "cn-") {
+		dnsSuffix = "amazonaws.com.cn"
+	}
+	return fmt.Sprintf("https://%s.s3.%s.%s/%s", bucket, region, dnsSuffix, key)
+}
+
+// optionsDigest returns a digest of the options that determine how an uploaded template is encrypted,
+// tagged and shared. It is stored in the metadata of uploaded templates, so that templates uploaded
+// with other options are detected and uploaded again.
+func optionsDigest(opts *clienttypes.TemplateBucketOptions) string {
+	if opts == nil {
+		opts = &clienttypes.TemplateBucketOptions{}
+	}
+	tagKeys := make([]string, 0, len(opts.ObjectTags))
+	for k := range opts.ObjectTags {
+		tagKeys = append(tagKeys, k)
+	}
+	sort.Strings(tagKeys)
+
+	h := sha256.New()
+	fmt.Fprintf(h, "acl=%q\nkms=%q\n", opts.ACL, opts.KMSKeyID)
+	for _, k := range tagKeys {
+		fmt.Fprintf(h, "tag=%q=%q\n", k, opts.ObjectTags[k])
+	}
+	return fmt.Sprintf("sha256:%x", h.Sum(nil))
+}
+
+func withRegion(region string) func(*s3.Options) {
+	return func(opts *s3.Options) {
+		if region != "" {
+			opts.Region = region
+		}
+	}
+}
+
+func (s *S3) upload(ctx context.Context, bucket, region, key string, buf io.Reader, opts *clienttypes.TemplateBucketOptions) error {
+	if opts == nil {
+		opts = &clienttypes.TemplateBucketOptions{}
+	}
+
+	expectedBucketOwner, err := s.expectedBucketOwner(ctx, region, opts)
+	if err != nil {
+		return err
 	}
-	expectedBucketOwner := identityResp.Account
 
 	in := &s3.PutObjectInput{
-		Body:   buf,
-		Bucket: aws.String(bucket),
-		Key:    aws.String(key),
+		Body:                buf,
+		Bucket:              aws.String(bucket),
+		Key:                 aws.String(key),
+		ExpectedBucketOwner: expectedBucketOwner,
+		Metadata:            map[string]string{optionsDigestMetadataKey: optionsDigest(opts)},
+	}
+	switch opts.ACL {
+	case "":
 		// Per s3's recommendation, the bucket owner, in addition to the
 		// object owner, is granted full control.
 		// https://docs.aws.amazon.com/AmazonS3/latest/userguide/about-object-ownership.html
-		ACL:                 types.ObjectCannedACLBucketOwnerFullControl,
-		ExpectedBucketOwner: expectedBucketOwner,
+		in.ACL = types.ObjectCannedACLBucketOwnerFullControl
+	case clienttypes.TemplateObjectACLNone:
+		// Buckets with Object Ownership set to 'bucket owner enforced' reject ACLs
+	default:
+		in.ACL = types.ObjectCannedACL(opts.ACL)
 	}
-	var opts []func(*manager.Uploader)
+	if opts.KMSKeyID != "" {
+		in.ServerSideEncryption = types.ServerSideEncryptionAwsKms
+		in.SSEKMSKeyId = aws.String(opts.KMSKeyID)
+	}
+	if len(opts.ObjectTags) > 0 {
+		tags := url.Values{}
+		for k, v := range opts.ObjectTags {
+			tags.Set(k, v)
+		}
+		in.Tagging = aws.String(tags.Encode())
+	}
+	var uploadOpts []func(*manager.Uploader)
 	if region != "" {
-		opts = append(opts, manager.WithUploaderRequestOptions(
-			func(opts *s3.Options) {
-				if region != "" {
-					opts.Region = region
-				}
-			},
-		))
+		uploadOpts = append(uploadOpts, manager.WithUploaderRequestOptions(withRegion(region)))
 	}
-	resp, err := s.manager.Upload(s.ctx, in, opts...)
-	if err != nil {
-		return "", err
+	if _, err := s.manager.Upload(ctx, in, uploadOpts...); err != nil {
+		return err
 	}
-	return resp.Location, nil
+	return nil
 }
@@ -9,13 +9,16 @@ import (
 	"fmt"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/aws/smithy-go"
 	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/clients/s3/mocks"
+	clienttypes "github.com/awslabs/aws-cloudformation-controller-for-flux/internal/clients/types"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
 )
@@ -26,12 +29,24 @@ const (
 	mockBucket    = "mockBucket"
 	mockRegion    = "mockRegion"
 	mockObjectKey = "mockFileName"
+	mockObjectURL = "https://mockBucket.s3.mockRegion.amazonaws.com/mockFileName"
 )
 
+func mockCallerIdentity(m *mocks.MockstsAPI) {
+	m.EXPECT().GetCallerIdentity(
+		gomock.Any(),
+		gomock.Any(),
+		gomock.Any(),
+	).Return(&sts.GetCallerIdentityOutput{
+		Account: aws.String(awsAccountId),
+	}, nil)
+}
+
 func TestS3_Upload(t *testing.T) {
 	testCases := map[string]struct {
 		mockS3ManagerClient func(m *mocks.Mocks3ManagerAPI)
 		mockStsClient       func(m *mocks.MockstsAPI)
+		opts                *clienttypes.TemplateBucketOptions
 
 		wantedURL string
 		wantError error
@@ -57,6 +72,7 @@ func TestS3_Upload(t *testing.T) {
 					Key:                 aws.String(mockObjectKey),
 					ACL:                 types.ObjectCannedACLBucketOwnerFullControl,
 					ExpectedBucketOwner: aws.String(awsAccountId),
+					Metadata:            map[string]string{"flux-options-digest": optionsDigest(nil)},
 				}
 				m.EXPECT().Upload(
 					gomock.Any(),
@@ -83,13 +99,14 @@ func TestS3_Upload(t *testing.T) {
 					Key:                 aws.String(mockObjectKey),
 					ACL:                 types.ObjectCannedACLBucketOwnerFullControl,
 					ExpectedBucketOwner: aws.String(awsAccountId),
+					Metadata:            map[string]string{"flux-options-digest": optionsDigest(nil)},
 				}
 				m.EXPECT().Upload(
 					gomock.Any(),
 					gomock.Eq(expectedIn),
 					gomock.Any(),
 				).Return(&manager.UploadOutput{
-					Location: "mockURL",
+					Location: "https://mockBucket.s3.mockRegion.amazonaws.com/mockFileName",
 				}, nil)
 			},
 			mockStsClient: func(m *mocks.MockstsAPI) {
@@ -101,7 +118,62 @@ func TestS3_Upload(t *testing.T) {
 					Account: aws.String(awsAccountId),
 				}, nil)
 			},
-			wantedURL: "mockURL",
+			wantedURL: mockObjectURL,
+		},
+		"should upload with the configured object settings": {
+			opts: &clienttypes.TemplateBucketOptions{
+				KMSKeyID:            "alias/my-key",
+				ObjectTags:          map[string]string{"team": "platform", "env": "prod"},
+				ACL:                 clienttypes.TemplateObjectACLNone,
+				ExpectedBucketOwner: "210987654321",
+			},
+			mockS3ManagerClient: func(m *mocks.Mocks3ManagerAPI) {
+				expectedIn := &s3.PutObjectInput{
+					Body:                 strings.NewReader(templateBody),
+					Bucket:               aws.String(mockBucket),
+					Key:                  aws.String(mockObjectKey),
+					ExpectedBucketOwner:  aws.String("210987654321"),
+					ServerSideEncryption: types.ServerSideEncryptionAwsKms,
+					SSEKMSKeyId:          aws.String("alias/my-key"),
+					Tagging:              aws.String("env=prod&team=platform"),
+					Metadata: map[string]string{"flux-options-digest": optionsDigest(&clienttypes.TemplateBucketOptions{
+						KMSKeyID:   "alias/my-key",
+						ObjectTags: map[string]string{"env": "prod", "team": "platform"},
+						ACL:        clienttypes.TemplateObjectACLNone,
+					})},
+				}
+				m.EXPECT().Upload(
+					gomock.Any(),
+					gomock.Eq(expectedIn),
+					gomock.Any(),
+				).Return(&manager.UploadOutput{}, nil)
+			},
+			mockStsClient: func(m *mocks.MockstsAPI) {
+				m.EXPECT().GetCallerIdentity(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			wantedURL: mockObjectURL,
+		},
+		"should upload with a canned ACL": {
+			opts: &clienttypes.TemplateBucketOptions{
+				ACL: "bucket-owner-read",
+			},
+			mockS3ManagerClient: func(m *mocks.Mocks3ManagerAPI) {
+				expectedIn := &s3.PutObjectInput{
+					Body:                strings.NewReader(templateBody),
+					Bucket:              aws.String(mockBucket),
+					Key:                 aws.String(mockObjectKey),
+					ACL:                 types.ObjectCannedACLBucketOwnerRead,
+					ExpectedBucketOwner: aws.String(awsAccountId),
+					Metadata:            map[string]string{"flux-options-digest": optionsDigest(&clienttypes.TemplateBucketOptions{ACL: "bucket-owner-read"})},
+				}
+				m.EXPECT().Upload(
+					gomock.Any(),
+					gomock.Eq(expectedIn),
+					gomock.Any(),
+				).Return(&manager.UploadOutput{}, nil)
+			},
+			mockStsClient: mockCallerIdentity,
+			wantedURL:     mockObjectURL,
 		},
 	}
 
@@ -119,10 +191,9 @@ func TestS3_Upload(t *testing.T) {
 			service := S3{
 				manager:   mockS3ManagerClient,
 				stsClient: mockStsClient,
-				ctx:       ctx,
 			}
 
-			gotURL, gotErr := service.UploadTemplate(mockBucket, mockRegion, mockObjectKey, strings.NewReader(templateBody))
+			gotURL, gotErr := service.UploadTemplate(ctx, mockBucket, mockRegion, mockObjectKey, strings.NewReader(templateBody), tc.opts)
 
 			if gotErr != nil {
 				require.EqualError(t, gotErr, tc.wantError.Error())
@@ -133,3 +204,250 @@ func TestS3_Upload(t *testing.T) {
 		})
 	}
 }
+
+func TestS3_HeadTemplate(t *testing.T) {
+	lastModified := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	testCases := map[string]struct {
+		mockS3Client func(m *mocks.Mocks3API)
+
+		wantedObject *clienttypes.TemplateObject
+		wantError    error
+	}{
+		"return the template object if it exists": {
+			mockS3Client: func(m *mocks.Mocks3API) {
+				m.EXPECT().HeadObject(gomock.Any(), &s3.HeadObjectInput{
+					Bucket:              aws.String(mockBucket),
+					Key:                 aws.String(mockObjectKey),
+					ExpectedBucketOwner: aws.String(awsAccountId),
+				}, gomock.Any()).Return(&s3.HeadObjectOutput{
+					LastModified: aws.Time(lastModified),
+					Metadata:     map[string]string{"flux-options-digest": optionsDigest(nil)},
+				}, nil)
+			},
+			wantedObject: &clienttypes.TemplateObject{
+				Key:            mockObjectKey,
+				URL:            mockObjectURL,
+				LastModified:   lastModified,
+				MatchesOptions: true,
+			},
+		},
+		"return a template object uploaded with other options": {
+			mockS3Client: func(m *mocks.Mocks3API) {
+				m.EXPECT().HeadObject(gomock.Any(), gomock.Any(), gomock.Any()).Return(&s3.HeadObjectOutput{
+					LastModified: aws.Time(lastModified),
+					Metadata: map[string]string{"flux-options-digest": optionsDigest(&clienttypes.TemplateBucketOptions{
+						KMSKeyID: "alias/my-old-key",
+					})},
+				}, nil)
+			},
+			wantedObject: &clienttypes.TemplateObject{
+				Key:          mockObjectKey,
+				URL:          mockObjectURL,
+				LastModified: lastModified,
+			},
+		},
+		"return a template object uploaded without options metadata": {
+			mockS3Client: func(m *mocks.Mocks3API) {
+				m.EXPECT().HeadObject(gomock.Any(), gomock.Any(), gomock.Any()).Return(&s3.HeadObjectOutput{
+					LastModified: aws.Time(lastModified),
+				}, nil)
+			},
+			wantedObject: &clienttypes.TemplateObject{
+				Key:          mockObjectKey,
+				URL:          mockObjectURL,
+				LastModified: lastModified,
+			},
+		},
+		"return ErrObjectNotFound if the template object does not exist": {
+			mockS3Client: func(m *mocks.Mocks3API) {
+				m.EXPECT().HeadObject(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, &smithy.GenericAPIError{Code: "NotFound"})
+			},
+			wantError: &ErrObjectNotFound{bucket: mockBucket, key: mockObjectKey},
+		},
+		"return error if head object fails": {
+			mockS3Client: func(m *mocks.Mocks3API) {
+				m.EXPECT().HeadObject(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("some head error"))
+			},
+			wantError: fmt.Errorf("head object mockFileName in bucket mockBucket: some head error"),
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			ctrl, ctx := gomock.WithContext(context.Background(), t)
+			defer ctrl.Finish()
+
+			mockS3Client := mocks.NewMocks3API(ctrl)
+			tc.mockS3Client(mockS3Client)
+
+			mockStsClient := mocks.NewMockstsAPI(ctrl)
+			mockCallerIdentity(mockStsClient)
+
+			service := S3{
+				client:    mockS3Client,
+				stsClient: mockStsClient,
+				region:    mockRegion,
+			}
+
+			gotObject, gotErr := service.HeadTemplate(ctx, mockBucket, "", mockObjectKey, nil)
+
+			if tc.wantError != nil {
+				require.EqualError(t, gotErr, tc.wantError.Error())
+			} else {
+				require.NoError(t, gotErr)
+				require.Equal(t, tc.wantedObject, gotObject)
+			}
+		})
+	}
+}
+
+func TestS3_ListTemplates(t *testing.T) {
+	lastModified := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	testCases := map[string]struct {
+		mockS3Client func(m *mocks.Mocks3API)
+
+		wantedObjects []*clienttypes.TemplateObject
+		wantError     error
+	}{
+		"list template objects across pages": {
+			mockS3Client: func(m *mocks.Mocks3API) {
+				m.EXPECT().ListObjectsV2(gomock.Any(), &s3.ListObjectsV2Input{
+					Bucket:              aws.String(mockBucket),
+					Prefix:              aws.String("flux-"),
+					ExpectedBucketOwner: aws.String(awsAccountId),
+				}, gomock.Any()).Return(&s3.ListObjectsV2Output{
+					Contents: []types.Object{
+						{Key: aws.String("flux-a.template"), LastModified: aws.Time(lastModified)},
+					},
+					IsTruncated:           aws.Bool(true),
+					NextContinuationToken: aws.String("next"),
+				}, nil)
+				m.EXPECT().ListObjectsV2(gomock.Any(), &s3.ListObjectsV2Input{
+					Bucket:              aws.String(mockBucket),
+					Prefix:              aws.String("flux-"),
+					ExpectedBucketOwner: aws.String(awsAccountId),
+					ContinuationToken:   aws.String("next"),
+				}, gomock.Any()).Return(&s3.ListObjectsV2Output{
+					Contents: []types.Object{
+						{Key: aws.String("flux-b.template"), LastModified: aws.Time(lastModified)},
+					},
+				}, nil)
+			},
+			wantedObjects: []*clienttypes.TemplateObject{
+				{
+					Key:          "flux-a.template",
+					URL:          "https://mockBucket.s3.mockRegion.amazonaws.com/flux-a.template",
+					LastModified: lastModified,
+				},
+				{
+					Key:          "flux-b.template",
+					URL:          "https://mockBucket.s3.mockRegion.amazonaws.com/flux-b.template",
+					LastModified: lastModified,
+				},
+			},
+		},
+		"return error if list objects fails": {
+			mockS3Client: func(m *mocks.Mocks3API) {
+				m.EXPECT().ListObjectsV2(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("some list error"))
+			},
+			wantError: fmt.Errorf("list objects with prefix flux- in bucket mockBucket: some list error"),
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			ctrl, ctx := gomock.WithContext(context.Background(), t)
+			defer ctrl.Finish()
+
+			mockS3Client := mocks.NewMocks3API(ctrl)
+			tc.mockS3Client(mockS3Client)
+
+			mockStsClient := mocks.NewMockstsAPI(ctrl)
+			mockCallerIdentity(mockStsClient)
+
+			service := S3{
+				client:    mockS3Client,
+				stsClient: mockStsClient,
+				region:    mockRegion,
+			}
+
+			gotObjects, gotErr := service.ListTemplates(ctx, mockBucket, "", "flux-", nil)
+
+			if tc.wantError != nil {
+				require.EqualError(t, gotErr, tc.wantError.Error())
+			} else {
+				require.NoError(t, gotErr)
+				require.Equal(t, tc.wantedObjects, gotObjects)
+			}
+		})
+	}
+}
+
+func TestS3_DeleteTemplates(t *testing.T) {
+	testCases := map[string]struct {
+		mockS3Client func(m *mocks.Mocks3API)
+
+		wantError error
+	}{
+		"delete the template objects": {
+			mockS3Client: func(m *mocks.Mocks3API) {
+				m.EXPECT().DeleteObjects(gomock.Any(), &s3.DeleteObjectsInput{
+					Bucket: aws.String(mockBucket),
+					Delete: &types.Delete{
+						Objects: []types.ObjectIdentifier{
+							{Key: aws.String("flux-a.template")},
+							{Key: aws.String("flux-b.template")},
+						},
+						Quiet: aws.Bool(true),
+					},
+					ExpectedBucketOwner: aws.String(awsAccountId),
+				}, gomock.Any()).Return(&s3.DeleteObjectsOutput{}, nil)
+			},
+		},
+		"return error if an object cannot be deleted": {
+			mockS3Client: func(m *mocks.Mocks3API) {
+				m.EXPECT().DeleteObjects(gomock.Any(), gomock.Any(), gomock.Any()).Return(&s3.DeleteObjectsOutput{
+					Errors: []types.Error{
+						{Key: aws.String("flux-b.template"), Code: aws.String("AccessDenied"), Message: aws.String("Access Denied")},
+					},
+				}, nil)
+			},
+			wantError: fmt.Errorf("delete object flux-b.template from bucket mockBucket: AccessDenied: Access Denied"),
+		},
+		"return error if delete objects fails": {
+			mockS3Client: func(m *mocks.Mocks3API) {
+				m.EXPECT().DeleteObjects(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("some delete error"))
+			},
+			wantError: fmt.Errorf("delete objects from bucket mockBucket: some delete error"),
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			ctrl, ctx := gomock.WithContext(context.Background(), t)
+			defer ctrl.Finish()
+
+			mockS3Client := mocks.NewMocks3API(ctrl)
+			tc.mockS3Client(mockS3Client)
+
+			mockStsClient := mocks.NewMockstsAPI(ctrl)
+			mockCallerIdentity(mockStsClient)
+
+			service := S3{
+				client:    mockS3Client,
+				stsClient: mockStsClient,
+				region:    mockRegion,
+			}
+
+			gotErr := service.DeleteTemplates(ctx, mockBucket, "", []string{"flux-a.template", "flux-b.template"}, nil)
+
+			if tc.wantError != nil {
+				require.EqualError(t, gotErr, tc.wantError.Error())
+			} else {
+				require.NoError(t, gotErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT-0
+
+package clients
+
+import (
+	"context"
+	"time"
+
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/smithy-go/middleware"
+)
+
+// addOperationTimeouts returns an AWS SDK API option that bounds each AWS API call by a timeout,
+// including the call's retries and rate limit waits, so that a hung call cannot block a reconciliation forever.
+// The timeout of an operation can be overridden by operation name, for example PutObject or UploadPart.
+// Calls are not bounded if their timeout is zero.
+func addOperationTimeouts(timeout time.Duration, operationTimeouts map[string]time.Duration) func(*middleware.Stack) error {
+	return func(stack *middleware.Stack) error {
+		// The middleware is added after the service metadata middleware, which records the operation name
+		return stack.Initialize.Add(middleware.InitializeMiddlewareFunc("OperationTimeout", func(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (middleware.InitializeOutput, middleware.Metadata, error) {
+			operationTimeout := timeout
+			if t, ok := operationTimeouts[awsmiddleware.GetOperationName(ctx)]; ok {
+				operationTimeout = t
+			}
+			if operationTimeout <= 0 {
+				return next.HandleInitialize(ctx, in)
+			}
+			ctx, cancel := context.WithTimeout(ctx, operationTimeout)
+			defer cancel()
+			return next.HandleInitialize(ctx, in)
+		}), middleware.After)
+	}
+}
@@ -0,0 +1,60 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT-0
+
+package clients
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/smithy-go/middleware"
+	"github.com/stretchr/testify/require"
+)
+
+// callDeadline returns the deadline of the context passed to the handler of a call to the given operation
+func callDeadline(t *testing.T, operation string, apiOption func(*middleware.Stack) error) (time.Duration, bool) {
+	stack := middleware.NewStack(operation, func() interface{} { return nil })
+	require.NoError(t, stack.Initialize.Add(&awsmiddleware.RegisterServiceMetadata{
+		ServiceID:     "S3",
+		OperationName: operation,
+	}, middleware.Before))
+	require.NoError(t, apiOption(stack))
+
+	var deadline time.Time
+	var hasDeadline bool
+	handler := middleware.HandlerFunc(func(ctx context.Context, input interface{}) (interface{}, middleware.Metadata, error) {
+		deadline, hasDeadline = ctx.Deadline()
+		return nil, middleware.Metadata{}, ctx.Err()
+	})
+	start := time.Now()
+	_, _, err := middleware.DecorateHandler(handler, stack).Handle(context.Background(), nil)
+	require.NoError(t, err)
+	return deadline.Sub(start), hasDeadline
+}
+
+func TestOperationTimeouts(t *testing.T) {
+	apiOption := addOperationTimeouts(time.Minute, map[string]time.Duration{
+		"PutObject":  10 * time.Minute,
+		"HeadObject": 0,
+	})
+
+	// Calls are bounded by the default timeout
+	timeout, ok := callDeadline(t, "GetObject", apiOption)
+	require.True(t, ok)
+	require.InDelta(t, time.Minute, timeout, float64(time.Second))
+
+	// The timeout can be overridden for an operation
+	timeout, ok = callDeadline(t, "PutObject", apiOption)
+	require.True(t, ok)
+	require.InDelta(t, 10*time.Minute, timeout, float64(time.Second))
+
+	// A zero timeout disables the timeout of an operation
+	_, ok = callDeadline(t, "HeadObject", apiOption)
+	require.False(t, ok)
+
+	// Calls are not bounded without a default timeout
+	_, ok = callDeadline(t, "GetObject", addOperationTimeouts(0, nil))
+	require.False(t, ok)
+}
@@ -0,0 +1,26 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT-0
+
+package types
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+
+	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/clients/ratelimit"
+)
+
+// ClientOptions configures the AWS SDK clients of the controller.
+type ClientOptions struct {
+	// RetryMode is the AWS SDK retry mode. Defaults to the standard retry mode.
+	RetryMode aws.RetryMode
+	// RetryMaxAttempts is the maximum number of attempts of an AWS API call. Defaults to the SDK's default.
+	RetryMaxAttempts int
+	// RateLimiter rate limits the AWS API calls of the clients. Calls are not rate limited if nil.
+	RateLimiter *ratelimit.Limiter
+	// Timeout bounds each AWS API call, including its retries. Calls are not bounded if zero.
+	Timeout time.Duration
+	// OperationTimeouts overrides Timeout for the AWS API operations with the given names, for example PutObject.
+	OperationTimeouts map[string]time.Duration
+}
@@ -43,20 +43,20 @@ var (
 
 // Stack represents a AWS CloudFormation stack.
 type Stack struct {
-	Name           string
-	Region         string
-	Generation     int64
-	SourceRevision string
-	ChangeSetArn   string
+	Name          string
+	Region        string
+	ChangeSetName string
+	ChangeSetArn  string
 	*StackConfig
 }
 
 type StackConfig struct {
-	TemplateBucket string
-	TemplateBody   string
-	TemplateURL    string
-	Parameters     []sdktypes.Parameter
-	Tags           []sdktypes.Tag
+	TemplateBucket        string
+	TemplateBucketOptions TemplateBucketOptions
+	TemplateBody          string
+	TemplateURL           string
+	Parameters            []sdktypes.Parameter
+	Tags                  []sdktypes.Tag
 }
 
 // StackEvent is an alias the SDK's StackEvent type.
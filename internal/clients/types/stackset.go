@@ -0,0 +1,69 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT-0
+
+package types
+
+import (
+	sdktypes "github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+)
+
+var (
+	inProgressStackSetOperationStatuses = []sdktypes.StackSetOperationStatus{
+		sdktypes.StackSetOperationStatusQueued,
+		sdktypes.StackSetOperationStatusRunning,
+		sdktypes.StackSetOperationStatusStopping,
+	}
+)
+
+// StackSet represents a AWS CloudFormation stack set.
+type StackSet struct {
+	Name   string
+	Region string
+	*StackConfig
+	*StackSetConfig
+}
+
+// StackSetConfig is the configuration of a stack set, in addition to the configuration of its stacks.
+type StackSetConfig struct {
+	PermissionModel       sdktypes.PermissionModels
+	AdministrationRoleARN string
+	ExecutionRoleName     string
+	AutoDeployment        *sdktypes.AutoDeployment
+	OperationPreferences  *sdktypes.StackSetOperationPreferences
+}
+
+// StackInstanceTargets are the accounts or organizational units, and the regions, of stack instances.
+type StackInstanceTargets struct {
+	Accounts              []string
+	OrganizationalUnitIDs []string
+	Regions               []string
+}
+
+// StackSetDescription is an alias the SDK's StackSet type.
+type StackSetDescription sdktypes.StackSet
+
+// StackInstanceSummary is an alias the SDK's StackInstanceSummary type.
+type StackInstanceSummary sdktypes.StackInstanceSummary
+
+// StackSetOperationDescription is an alias the SDK's StackSetOperation type.
+type StackSetOperationDescription sdktypes.StackSetOperation
+
+// InProgress returns true if the stack set operation is queued or running.
+func (d *StackSetOperationDescription) InProgress() bool {
+	for _, status := range inProgressStackSetOperationStatuses {
+		if d.Status == status {
+			return true
+		}
+	}
+	return false
+}
+
+// IsSuccess returns true if the stack set operation succeeded.
+func (d *StackSetOperationDescription) IsSuccess() bool {
+	return d.Status == sdktypes.StackSetOperationStatusSucceeded
+}
+
+// IsCurrent returns true if the stack instance is up to date with the stack set.
+func (s *StackInstanceSummary) IsCurrent() bool {
+	return s.Status == sdktypes.StackInstanceStatusCurrent
+}
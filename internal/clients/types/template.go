@@ -0,0 +1,37 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT-0
+
+package types
+
+import "time"
+
+// TemplateObject is a CloudFormation template file stored in an S3 bucket.
+type TemplateObject struct {
+	Key          string
+	URL          string
+	LastModified time.Time
+	// MatchesOptions is true if the template was uploaded with the KMS key, object tags and ACL
+	// of the template bucket options that it was retrieved with.
+	MatchesOptions bool
+}
+
+// TemplateObjectACLNone disables setting an ACL on uploaded templates,
+// for buckets that have S3 Object Ownership set to 'bucket owner enforced'.
+const TemplateObjectACLNone = "none"
+
+// TemplateBucketOptions configures how templates are stored in the template bucket.
+type TemplateBucketOptions struct {
+	// KeyPrefix is prepended to the object keys of uploaded templates.
+	KeyPrefix string
+	// KMSKeyID is the AWS KMS key used to encrypt uploaded templates with SSE-KMS.
+	// Uploaded templates use the default encryption of the bucket if empty.
+	KMSKeyID string
+	// ObjectTags are set on uploaded templates.
+	ObjectTags map[string]string
+	// ACL is the canned ACL applied to uploaded templates, or TemplateObjectACLNone.
+	// Defaults to bucket-owner-full-control if empty.
+	ACL string
+	// ExpectedBucketOwner is the account ID expected to own the bucket.
+	// Defaults to the account of the caller if empty.
+	ExpectedBucketOwner string
+}
@@ -4,16 +4,20 @@
 package controllers
 
 import (
-	"bytes"
 	"context"
 	"errors"
 	"fmt"
 	"strings"
 	"time"
 
-	"github.com/google/uuid"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	sdktypes "github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
 	"github.com/hashicorp/go-retryablehttp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	kuberecorder "k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -21,8 +25,10 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	eventv1 "github.com/fluxcd/pkg/apis/event/v1beta1"
 	"github.com/fluxcd/pkg/apis/meta"
@@ -31,12 +37,18 @@ import (
 	sourcev1 "github.com/fluxcd/source-controller/api/v1"
 	sourcev1b2 "github.com/fluxcd/source-controller/api/v1beta2"
 
-	"github.com/aws/aws-sdk-go-v2/aws"
-	sdktypes "github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
 	cfnv1 "github.com/awslabs/aws-cloudformation-controller-for-flux/api/v1alpha1"
+	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/artifact"
+	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/build"
 	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/clients"
 	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/clients/cloudformation"
+	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/clients/ratelimit"
+	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/clients/s3"
 	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/clients/types"
+	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/metrics"
+	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/policy"
+	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/template"
+	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/tracing"
 )
 
 //+kubebuilder:rbac:groups=cloudformation.contrib.fluxcd.io,resources=cloudformationstacks,verbs=get;list;watch;create;update;patch;delete
@@ -45,6 +57,13 @@ import (
 //+kubebuilder:rbac:groups=source.toolkit.fluxcd.io,resources=buckets;gitrepositories;ocirepositories,verbs=get;list;watch
 //+kubebuilder:rbac:groups=source.toolkit.fluxcd.io,resources=buckets/status;gitrepositories/status;ocirepositories/status,verbs=get
 //+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+//+kubebuilder:rbac:groups="",resources=configmaps;secrets,verbs=get
+
+// errSourceRefMissing occurs when a stack that is not in Observe mode does not reference a source.
+var errSourceRefMissing = errors.New("the stack has no source reference, sourceRef is required unless the stack is in Observe mode")
+
+// errTemplateBucketMissing occurs when a template must be uploaded to S3, but no template bucket is configured.
+var errTemplateBucketMissing = errors.New("no template bucket is configured for the controller")
 
 // CloudFormationStackReconciler reconciles a CloudFormationStack object
 type CloudFormationStackReconciler struct {
@@ -58,18 +77,54 @@ type CloudFormationStackReconciler struct {
 	ControllerName    string
 	ControllerVersion string
 
-	CfnClient      clients.CloudFormationClient
+	CfnClient clients.CloudFormationClient
+	// StackCache caches the descriptions of the stacks, to check the stacks of ready CloudFormationStacks
+	// without describing each stack. The stacks are always described directly if nil.
+	StackCache     clients.CloudFormationStackCache
 	S3Client       clients.S3Client
 	TemplateBucket string
-	StackTags      map[string]string
+	// TemplateBucketOptions are the default settings for uploading templates to the template bucket,
+	// which can be overridden for each stack.
+	TemplateBucketOptions types.TemplateBucketOptions
+	// TemplateRetention is the minimum age of the uploaded templates that are no longer in use by their stack
+	// before they are deleted from the template bucket. Zero disables the deletion of uploaded templates.
+	TemplateRetention time.Duration
+	StackTags         map[string]string
+	// DryRun plans all stacks as if they were in Plan mode, without deploying any changes.
+	DryRun bool
+	// Region is the AWS region that the stacks are deployed to, for labelling the stack metrics,
+	// selecting the stack status change events of the stacks and backing off the poll intervals
+	// when the calls in the region are throttled.
+	Region string
+	// Account is the AWS account ID that the stacks are deployed to, for selecting the stack status change events
+	// of the stacks that have no recorded stack ID yet and backing off the poll intervals when the calls
+	// in the account are throttled.
+	Account string
+	// RateLimiter rate limits the AWS API calls of all reconciliations, and backs off the poll
+	// intervals when the calls are throttled.
+	RateLimiter *ratelimit.Limiter
+	// ArtifactCache caches the source artifacts of the stacks and their policies on disk.
+	ArtifactCache *artifact.Cache
 
 	httpClient        *retryablehttp.Client
 	requeueDependency time.Duration
+
+	// stackEvents receives the CloudFormationStacks to reconcile on stack status change events
+	stackEvents chan event.GenericEvent
+	// stackEventsPollInterval is the minimum interval at which stack actions in progress are polled
+	// when stack status change events are received
+	stackEventsPollInterval time.Duration
 }
 
 type CloudFormationStackReconcilerOptions struct {
 	HTTPRetry                 int
 	DependencyRequeueInterval time.Duration
+	// StackEvents enables the reconciliation of the CloudFormationStacks on the status change events
+	// of their stacks, passed to EnqueueStackStatusChange.
+	StackEvents bool
+	// StackEventsPollInterval is the minimum interval at which stack actions in progress are polled
+	// when StackEvents is enabled, in case a stack status change event is lost.
+	StackEventsPollInterval time.Duration
 }
 
 func (r *CloudFormationStackReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, opts CloudFormationStackReconcilerOptions) error {
@@ -87,6 +142,15 @@ func (r *CloudFormationStackReconciler) SetupWithManager(ctx context.Context, mg
 		return fmt.Errorf("failed setting index fields: %w", err)
 	}
 
+	// Index the CloudFormationStacks by their stack IDs, and by their stack names until their stack IDs are recorded,
+	// to find the objects of the stack status change events
+	if err := mgr.GetCache().IndexField(ctx, &cfnv1.CloudFormationStack{}, cfnv1.StackIDIndexKey, indexByStackID); err != nil {
+		return fmt.Errorf("failed setting index fields: %w", err)
+	}
+	if err := mgr.GetCache().IndexField(ctx, &cfnv1.CloudFormationStack{}, cfnv1.StackNameIndexKey, indexByStackName); err != nil {
+		return fmt.Errorf("failed setting index fields: %w", err)
+	}
+
 	r.requeueDependency = opts.DependencyRequeueInterval
 
 	// Configure the retryable http client for retrieving artifacts.
@@ -98,7 +162,7 @@ func (r *CloudFormationStackReconciler) SetupWithManager(ctx context.Context, mg
 	r.httpClient = httpClient
 
 	// Watch for source object changes and CloudFormation stack object changes
-	return ctrl.NewControllerManagedBy(mgr).
+	b := ctrl.NewControllerManagedBy(mgr).
 		For(&cfnv1.CloudFormationStack{}, builder.WithPredicates(
 			predicate.Or(predicate.GenerationChangedPredicate{}, predicates.ReconcileRequestedPredicate{}),
 		)).
@@ -117,14 +181,21 @@ func (r *CloudFormationStackReconciler) SetupWithManager(ctx context.Context, mg
 			handler.EnqueueRequestsFromMapFunc(r.requestsForRevisionChangeOf(cfnv1.OCIRepositoryIndexKey)),
 			builder.WithPredicates(SourceRevisionChangePredicate{}),
 		).
-		WithOptions(controller.Options{}).
-		Complete(r)
+		WithOptions(controller.Options{})
+
+	// Reconcile the stacks on their stack status change events
+	if opts.StackEvents {
+		r.stackEvents = make(chan event.GenericEvent)
+		r.stackEventsPollInterval = opts.StackEventsPollInterval
+		b = b.WatchesRawSource(&source.Channel{Source: r.stackEvents}, &handler.EnqueueRequestForObject{})
+	}
+	return b.Complete(r)
 }
 
 func (r *CloudFormationStackReconciler) IndexBy(kind string) func(o client.Object) []string {
 	return func(o client.Object) []string {
 		stack := o.(*cfnv1.CloudFormationStack)
-		if stack.Spec.SourceRef.Kind == kind {
+		if stack.Spec.SourceRef != nil && stack.Spec.SourceRef.Kind == kind {
 			namespace := stack.GetNamespace()
 			// default to the stack's namespace
 			if stack.Spec.SourceRef.Namespace != "" {
@@ -137,7 +208,7 @@ func (r *CloudFormationStackReconciler) IndexBy(kind string) func(o client.Objec
 	}
 }
 
-func (r *CloudFormationStackReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+func (r *CloudFormationStackReconciler) Reconcile(ctx context.Context, req ctrl.Request) (_ ctrl.Result, retErr error) {
 	start := time.Now()
 	log := ctrl.LoggerFrom(ctx)
 
@@ -146,6 +217,18 @@ func (r *CloudFormationStackReconciler) Reconcile(ctx context.Context, req ctrl.
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
+	// Trace the reconciliation, linking the reconciliations that poll a change set to the one that created it
+	spanOpts := []trace.SpanStartOption{
+		trace.WithAttributes(tracing.ObjectAttributes(cfnv1.CloudFormationStackKind, cfnStack.Namespace, cfnStack.Name, cfnStack.Spec.StackName)...),
+	}
+	if cfnStack.Status.LastAttemptedChangeSet != cfnStack.Status.LastAppliedChangeSet {
+		spanOpts = append(spanOpts, tracing.LinkTo(cfnStack.Status.LastAttemptedChangeSetTraceParent))
+	}
+	ctx, span := tracing.Start(ctx, "CloudFormationStack.Reconcile", spanOpts...)
+	defer func() {
+		tracing.End(span, retErr)
+	}()
+
 	defer func() {
 		// Always record metrics.
 		r.Metrics.RecordSuspend(ctx, &cfnStack, cfnStack.Spec.Suspend)
@@ -175,6 +258,8 @@ func (r *CloudFormationStackReconciler) Reconcile(ctx context.Context, req ctrl.
 				log.Error(updateStatusErr, "Unable to update status after delete reconciliation")
 				return ctrl.Result{Requeue: true}, updateStatusErr
 			}
+		} else if err == nil {
+			metrics.DeleteStack(cfnv1.CloudFormationStackKind, cfnStack.Namespace, cfnStack.Name)
 		}
 
 		durationMsg := fmt.Sprintf("Deletion reconcilation loop finished in %s", time.Now().Sub(start).String())
@@ -195,6 +280,16 @@ func (r *CloudFormationStackReconciler) Reconcile(ctx context.Context, req ctrl.
 	// Reconcile
 	cfnStack, result, err := r.reconcile(ctx, cfnStack)
 
+	// Back off when the AWS APIs throttle the controller's calls, instead of failing the reconciliation
+	if ratelimit.IsThrottle(err) {
+		msg := fmt.Sprintf("AWS API calls for stack '%s' were throttled: %s", cfnStack.Spec.StackName, err.Error())
+		log.Info(msg)
+		cfnStack = cfnv1.CloudFormationStackNotReady(cfnStack, cfnv1.ReadinessUpdate{Message: msg, Reason: cfnv1.ThrottledReason})
+		result, err = ctrl.Result{RequeueAfter: r.RateLimiter.Backoff(r.Account, r.Region, cfnStack.GetRetryInterval())}, nil
+	} else if result.RequeueAfter > 0 {
+		result.RequeueAfter = r.RateLimiter.Backoff(r.Account, r.Region, result.RequeueAfter)
+	}
+
 	// Update status
 	if updateStatusErr := r.patchStatus(ctx, &cfnStack); updateStatusErr != nil {
 		log.Error(updateStatusErr, "Unable to update status after reconciliation")
@@ -229,10 +324,27 @@ func (r *CloudFormationStackReconciler) reconcile(ctx context.Context, cfnStack
 		}
 	}
 
+	// Clear the last plan once the stack is no longer planned
+	if !r.planOnly(cfnStack) {
+		apimeta.RemoveStatusCondition(cfnStack.GetStatusConditions(), cfnv1.PlannedCondition)
+		cfnStack.Status.LastPlan = nil
+	}
+
+	// Mirror the stack without deploying it in Observe mode
+	if cfnStack.Spec.Mode == cfnv1.ObserveMode {
+		return r.reconcileObserve(ctx, cfnStack)
+	}
+	cfnStack = clearObservedStack(cfnStack)
+
 	// Resolve source reference
 	sourceObj, err := r.getSource(ctx, cfnStack)
 	if err != nil {
-		if apierrors.IsNotFound(err) {
+		if errors.Is(err, errSourceRefMissing) {
+			msg := fmt.Sprintf("Failed to resolve source: %s", err.Error())
+			cfnStack = cfnv1.CloudFormationStackNotReady(cfnStack, cfnv1.ReadinessUpdate{Message: msg, Reason: cfnv1.ArtifactFailedReason, Stalled: true})
+			log.Info(msg)
+			return cfnStack, ctrl.Result{RequeueAfter: cfnStack.GetRetryInterval()}, nil
+		} else if apierrors.IsNotFound(err) {
 			msg := fmt.Sprintf("Source '%s' not found", cfnStack.Spec.SourceRef.String())
 			cfnStack = cfnv1.CloudFormationStackNotReady(cfnStack, cfnv1.ReadinessUpdate{Message: msg, Reason: cfnv1.ArtifactFailedReason})
 			log.Info(msg)
@@ -270,22 +382,66 @@ func (r *CloudFormationStackReconciler) reconcile(ctx context.Context, cfnStack
 		}
 	}
 
+	// Load the post-build variables and substitute them in the stack parameters and tags
+	stackToReconcile := cfnStack.DeepCopy()
+	vars, err := r.loadPostBuildVariables(ctx, cfnStack)
+	if err == nil {
+		err = build.SubstituteStackSpec(stackToReconcile, vars)
+	}
+	if err != nil {
+		return r.substitutionFailed(ctx, cfnStack, sourceObj.GetArtifact().Revision, err)
+	}
+
 	// Load stack template file from artifact
-	templateContents, err := r.loadCloudFormationTemplate(ctx, cfnStack, sourceObj.GetArtifact())
+	tmpl, sourceArtifact, err := r.loadCloudFormationTemplate(ctx, cfnStack, sourceObj.GetArtifact(), build.SubstituteTemplateTransform(cfnStack, vars))
+	if errors.Is(err, build.ErrSubstitutionFailed) {
+		return r.substitutionFailed(ctx, cfnStack, sourceObj.GetArtifact().Revision, err)
+	}
 	if err != nil {
-		msg := fmt.Sprintf("Failed to load template '%s' from source '%s'", cfnStack.Spec.TemplatePath, cfnStack.Spec.SourceRef.String())
+		msg := fmt.Sprintf("Failed to load %s from source '%s'", templateDescription(cfnStack), cfnStack.Spec.SourceRef.String())
 		log.Error(err, msg)
 		cfnStack = cfnv1.CloudFormationStackNotReady(cfnStack, cfnv1.ReadinessUpdate{
 			Message:        msg,
 			Reason:         cfnv1.ArtifactFailedReason,
 			SourceRevision: sourceObj.GetArtifact().Revision,
+			Stalled:        errors.Is(err, errInvalidTemplate),
 		})
+		if errors.Is(err, errInvalidTemplate) {
+			// Retrying does not fix the template until the source revision or the stack changes
+			return cfnStack, ctrl.Result{RequeueAfter: cfnStack.GetRetryInterval()}, nil
+		}
 		return cfnStack, ctrl.Result{RequeueAfter: cfnStack.GetRetryInterval()}, err
 	}
-	revision := sourceObj.GetArtifact().Revision
+	revision := sourceArtifact.Revision
+	sourceDigest := build.SourceDigest(tmpl)
+
+	// Apply the stack's patches to the template
+	tmpl, err = tmpl.ApplyPatches(build.TemplatePatches(stackToReconcile))
+	if err != nil {
+		msg := fmt.Sprintf("Failed to patch %s: %s", templateDescription(cfnStack), err.Error())
+		log.Error(err, msg)
+		r.event(ctx, cfnStack, revision, eventv1.EventSeverityError, msg)
+		cfnStack = cfnv1.CloudFormationStackNotReady(cfnStack, cfnv1.ReadinessUpdate{
+			Message:        msg,
+			Reason:         cfnv1.PatchFailedReason,
+			SourceRevision: revision,
+			Stalled:        true,
+		})
+		return cfnStack, ctrl.Result{RequeueAfter: cfnStack.GetRetryInterval()}, nil
+	}
+
+	// Load the policies that the stack must comply with before it is deployed
+	stackPolicy, err := r.loadPolicy(ctx, *stackToReconcile)
+	if err != nil {
+		return r.policyLoadFailed(ctx, cfnStack, revision, err)
+	}
 
 	// Reconcile CloudFormation stack
-	reconciledCfnStack, requeueInterval, err := r.reconcileStack(ctx, *cfnStack.DeepCopy(), templateContents, revision)
+	reconciledCfnStack, requeueInterval, err := r.reconcileStack(ctx, *stackToReconcile, tmpl, stackPolicy, revision, sourceDigest)
+	if reconciledCfnStack.Status.LastAppliedChangeSet != cfnStack.Status.LastAppliedChangeSet &&
+		apimeta.IsStatusConditionTrue(reconciledCfnStack.Status.Conditions, meta.ReadyCondition) {
+		metrics.RecordRevisionApplied(r.stackMetricsLabels(cfnStack), sourceArtifact.LastUpdateTime.Time)
+	}
 	if err != nil {
 		log.Error(err, "Failed to reconcile stack")
 		msg := fmt.Sprintf("Failed to reconcile stack: %s", err.Error())
@@ -293,83 +449,91 @@ func (r *CloudFormationStackReconciler) reconcile(ctx context.Context, cfnStack
 		return reconciledCfnStack, ctrl.Result{RequeueAfter: requeueInterval}, err
 	}
 
+	// The stack is up-to-date with the last applied revision, not with the source's latest revision
+	if revision != sourceObj.GetArtifact().Revision &&
+		apimeta.IsStatusConditionTrue(reconciledCfnStack.Status.Conditions, meta.ReadyCondition) {
+		return r.fallbackArtifactInUse(ctx, reconciledCfnStack, sourceObj.GetArtifact().Revision, revision)
+	}
+
 	return reconciledCfnStack, ctrl.Result{RequeueAfter: requeueInterval}, nil
 }
 
-func (r *CloudFormationStackReconciler) reconcileStack(ctx context.Context, cfnStack cfnv1.CloudFormationStack, templateContents *bytes.Buffer, revision string) (cfnv1.CloudFormationStack, time.Duration, error) {
+// fallbackArtifactInUse marks the stack as not ready because it was reconciled with the artifact of the last applied
+// revision, as the artifact of the source's latest revision could not be downloaded. The download is retried at the
+// retry interval.
+func (r *CloudFormationStackReconciler) fallbackArtifactInUse(ctx context.Context, cfnStack cfnv1.CloudFormationStack, revision, fallbackRevision string) (cfnv1.CloudFormationStack, ctrl.Result, error) {
 	log := ctrl.LoggerFrom(ctx)
 
-	// Convert the Flux controller stack type into the CloudFormation client stack type
-	clientStack := &types.Stack{
-		Name: cfnStack.Spec.StackName,
-		// Region:         cfnStack.Spec.Region,
-		Generation:     cfnStack.Generation,
+	msg := fmt.Sprintf("Failed to download the artifact of revision '%s', the stack is up-to-date with the last applied revision '%s'", revision, fallbackRevision)
+	log.Info(msg)
+	r.event(ctx, cfnStack, revision, eventv1.EventSeverityError, msg)
+	cfnStack = cfnv1.CloudFormationStackNotReady(cfnStack, cfnv1.ReadinessUpdate{
+		Message:        msg,
+		Reason:         cfnv1.ArtifactFailedReason,
 		SourceRevision: revision,
-		StackConfig: &types.StackConfig{
-			TemplateBucket: r.TemplateBucket,
-			TemplateBody:   templateContents.String(),
-		},
-	}
-
-	if len(cfnStack.Spec.StackParameters) > 0 {
-		var params []sdktypes.Parameter
-		for _, param := range cfnStack.Spec.StackParameters {
-			params = append(params, sdktypes.Parameter{
-				ParameterKey:   aws.String(param.Key),
-				ParameterValue: aws.String(param.Value),
-			})
-		}
-		clientStack.StackConfig.Parameters = params
-	}
-
-	tags := []sdktypes.Tag{
-		{
-			Key:   aws.String(fmt.Sprintf("%s/version", r.ControllerName)),
-			Value: aws.String(r.ControllerVersion),
-		},
-		{
-			Key:   aws.String(fmt.Sprintf("%s/name", r.ControllerName)),
-			Value: aws.String(cfnStack.Name),
-		},
-		{
-			Key:   aws.String(fmt.Sprintf("%s/namespace", r.ControllerName)),
-			Value: aws.String(cfnStack.Namespace),
-		},
-	}
-	for key, value := range r.StackTags {
-		tags = append(tags, sdktypes.Tag{
-			Key:   aws.String(key),
-			Value: aws.String(value),
-		})
-	}
-	if len(cfnStack.Spec.StackTags) > 0 {
-		for _, tag := range cfnStack.Spec.StackTags {
-			tags = append(tags, sdktypes.Tag{
-				Key:   aws.String(tag.Key),
-				Value: aws.String(tag.Value),
-			})
-		}
+	})
+	return cfnStack, ctrl.Result{RequeueAfter: cfnStack.GetRetryInterval()}, nil
+}
+
+// substitutionFailed marks the stack as not ready because the post-build variables could not be loaded or substituted.
+// Variables that are not defined or ConfigMaps and Secrets that do not exist require a change to the stack's
+// configuration, so these failures are retried at the retry interval. Other failures are returned as errors.
+func (r *CloudFormationStackReconciler) substitutionFailed(ctx context.Context, cfnStack cfnv1.CloudFormationStack, revision string, err error) (cfnv1.CloudFormationStack, ctrl.Result, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	var statusErr apierrors.APIStatus
+	apiErr := errors.As(err, &statusErr) && !apierrors.IsNotFound(err)
+
+	msg := fmt.Sprintf("Failed to substitute variables for stack '%s': %s", cfnStack.Spec.StackName, err.Error())
+	log.Error(err, msg)
+	r.event(ctx, cfnStack, revision, eventv1.EventSeverityError, msg)
+	cfnStack = cfnv1.CloudFormationStackNotReady(cfnStack, cfnv1.ReadinessUpdate{
+		Message:        msg,
+		Reason:         cfnv1.SubstitutionFailedReason,
+		SourceRevision: revision,
+		Stalled:        !apiErr,
+	})
+
+	if apiErr {
+		return cfnStack, ctrl.Result{RequeueAfter: cfnStack.GetRetryInterval()}, err
 	}
-	clientStack.StackConfig.Tags = tags
+	return cfnStack, ctrl.Result{RequeueAfter: cfnStack.GetRetryInterval()}, nil
+}
+
+func (r *CloudFormationStackReconciler) reconcileStack(ctx context.Context, cfnStack cfnv1.CloudFormationStack, tmpl *template.Template, stackPolicy *policy.Policy, revision string, sourceDigest string) (cfnv1.CloudFormationStack, time.Duration, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	// Convert the Flux controller stack type into the CloudFormation client stack type
+	clientStack := build.Stack(cfnStack, tmpl, r.buildOptions())
+	clientStack.StackConfig.TemplateBucket = r.TemplateBucket
+	clientStack.StackConfig.TemplateBucketOptions = r.templateBucketOptions(cfnStack)
+
+	// The change set is named after the source contents and the deployment inputs, so new source revisions
+	// that change neither of them keep the current change set
+	configDigest := build.ConfigDigest(clientStack, tmpl)
+	clientStack.ChangeSetName = build.ChangeSetName(cfnStack, sourceDigest, configDigest)
+	cfnStack.Status.LastAttemptedRevision = revision
+	cfnStack.Status.LastAttemptedSourceDigest = sourceDigest
 
 	// Check if we need to generate a new change set or describe the current one
-	desiredChangeSetName := cloudformation.GetChangeSetName(cfnStack.Generation, revision)
-	lastAttemptedChangeSetName := cloudformation.ExtractChangeSetName(cfnStack.Status.LastAttemptedChangeSet)
-	if desiredChangeSetName != lastAttemptedChangeSetName {
-		// We need to create a new change set for the new revision
-		clientStack.ChangeSetArn = ""
-	} else {
+	if clientStack.ChangeSetName == cloudformation.ExtractChangeSetName(cfnStack.Status.LastAttemptedChangeSet) {
 		clientStack.ChangeSetArn = cfnStack.Status.LastAttemptedChangeSet
 	}
 
 	// Find the existing stack, if any
-	desc, err := r.CfnClient.DescribeStack(clientStack)
+	desc, err := r.describeStack(ctx, cfnStack, clientStack)
+	if err == nil {
+		metrics.RecordStackStatus(r.stackMetricsLabels(cfnStack), string(desc.StackStatus))
+		cfnStack.Status.StackID = aws.ToString(desc.StackId)
+	}
 
 	// Check if the stack exists; if not, create it
 	if err != nil {
 		var e *cloudformation.ErrStackNotFound
 		if errors.As(err, &e) {
-			return r.reconcileChangeset(ctx, cfnStack, clientStack, revision, true)
+			metrics.DeleteStackStatus(r.stackMetricsLabels(cfnStack))
+			cfnStack.Status.StackID = ""
+			return r.reconcileChangeset(ctx, cfnStack, clientStack, tmpl, stackPolicy, revision, true)
 		} else {
 			msg := fmt.Sprintf("Failed to describe the stack '%s'", clientStack.Name)
 			log.Error(err, msg)
@@ -383,12 +547,12 @@ func (r *CloudFormationStackReconciler) reconcileStack(ctx context.Context, cfnS
 		msg := fmt.Sprintf("Stack action for stack '%s' is in progress (status: '%s'), waiting for stack action to complete", clientStack.Name, desc.StackStatus)
 		log.Info(msg)
 		cfnStack = cfnv1.CloudFormationStackProgressing(cfnStack, cfnv1.ReadinessUpdate{SourceRevision: revision, Message: msg})
-		return cfnStack, cfnStack.Spec.PollInterval.Duration, nil
+		return cfnStack, r.stackActionPollInterval(cfnStack), nil
 	}
 
 	// Continue rollback if a previous update rollback failed
 	if desc.RequiresRollbackContinuation() {
-		if err := r.CfnClient.ContinueStackRollback(clientStack); err != nil {
+		if err := r.CfnClient.ContinueStackRollback(ctx, clientStack); err != nil {
 			msg := fmt.Sprintf("Failed to continue a failed rollback for stack '%s'", clientStack.Name)
 			log.Error(err, msg)
 			cfnStack = cfnv1.CloudFormationStackNotReady(cfnStack, cfnv1.ReadinessUpdate{SourceRevision: revision, Message: msg, Reason: cfnv1.CloudFormationApiCallFailedReason})
@@ -404,7 +568,7 @@ func (r *CloudFormationStackReconciler) reconcileStack(ctx context.Context, cfnS
 
 	// Delete the stack if it has failed to create or delete
 	if desc.RequiresCleanup() {
-		if err := r.CfnClient.DeleteStack(clientStack); err != nil {
+		if err := r.CfnClient.DeleteStack(ctx, clientStack); err != nil {
 			msg := fmt.Sprintf("Failed to delete the failed stack '%s'", clientStack.Name)
 			log.Error(err, msg)
 			cfnStack = cfnv1.CloudFormationStackNotReady(cfnStack, cfnv1.ReadinessUpdate{SourceRevision: revision, Message: msg, Reason: cfnv1.CloudFormationApiCallFailedReason})
@@ -432,7 +596,22 @@ func (r *CloudFormationStackReconciler) reconcileStack(ctx context.Context, cfnS
 			msg = fmt.Sprintf("%s), creating a new change set", msg)
 			r.event(ctx, cfnStack, revision, eventv1.EventSeverityError, msg)
 		}
-		return r.reconcileChangeset(ctx, cfnStack, clientStack, revision, false)
+
+		// Skip the change set if nothing changed since the last applied change set
+		if r.upToDate(cfnStack, desc, configDigest) {
+			log.Info(fmt.Sprintf("Stack '%s' is up to date with its last applied change set, skipping change set creation", clientStack.Name))
+			return cfnv1.CloudFormationStackReady(cfnStack, ""), cfnStack.Spec.Interval.Duration, nil
+		}
+
+		// Record what the applied change set deployed, to skip the change sets of the next reconciliations
+		reconciledCfnStack, requeueInterval, err := r.reconcileChangeset(ctx, cfnStack, clientStack, tmpl, stackPolicy, revision, false)
+		if err == nil && desc.IsSuccess() && !r.planOnly(cfnStack) &&
+			apimeta.IsStatusConditionTrue(reconciledCfnStack.Status.Conditions, meta.ReadyCondition) {
+			reconciledCfnStack.Status.LastAppliedConfigDigest = configDigest
+			updateTime := metav1.NewMicroTime(stackUpdateTime(desc))
+			reconciledCfnStack.Status.LastAppliedStackUpdateTime = &updateTime
+		}
+		return reconciledCfnStack, requeueInterval, err
 	}
 
 	msg := fmt.Sprintf("Unexpected stack status for stack '%s': status '%s'", clientStack.Name, desc.StackStatus)
@@ -445,10 +624,10 @@ func (r *CloudFormationStackReconciler) reconcileStack(ctx context.Context, cfnS
 	return cfnStack, cfnStack.GetRetryInterval(), nil
 }
 
-func (r *CloudFormationStackReconciler) reconcileChangeset(ctx context.Context, cfnStack cfnv1.CloudFormationStack, clientStack *types.Stack, revision string, isCreate bool) (cfnv1.CloudFormationStack, time.Duration, error) {
+func (r *CloudFormationStackReconciler) reconcileChangeset(ctx context.Context, cfnStack cfnv1.CloudFormationStack, clientStack *types.Stack, tmpl *template.Template, stackPolicy *policy.Policy, revision string, isCreate bool) (cfnv1.CloudFormationStack, time.Duration, error) {
 	log := ctrl.LoggerFrom(ctx)
 
-	desc, err := r.CfnClient.DescribeChangeSet(clientStack)
+	desc, err := r.CfnClient.DescribeChangeSet(ctx, clientStack)
 
 	// Check if the change set exists; if not, create it.
 	// If the change set is empty, we can delete it and declare success
@@ -456,17 +635,36 @@ func (r *CloudFormationStackReconciler) reconcileChangeset(ctx context.Context,
 		var notFoundErr *cloudformation.ErrChangeSetNotFound
 		var emptyErr *cloudformation.ErrChangeSetEmpty
 		if errors.As(err, &notFoundErr) {
-			err := r.uploadStackTemplate(clientStack)
+			// Check the stack template, parameters and tags against the policies before creating the change set
+			if denied, err := r.checkPolicy(ctx, stackPolicy, &cfnStack, clientStack, tmpl, revision, "", nil); denied {
+				return cfnStack, cfnStack.GetRetryInterval(), err
+			}
+
+			keys, err := uploadStackTemplate(ctx, r.S3Client, clientStack, tmpl, r.stackMetricsLabels(cfnStack))
 			if err != nil {
+				if errors.Is(err, errTemplateBucketMissing) {
+					msg := fmt.Sprintf("Failed to deploy stack '%s': %s", clientStack.Name, err.Error())
+					log.Info(msg)
+					r.event(ctx, cfnStack, revision, eventv1.EventSeverityError, msg)
+					cfnStack = cfnv1.CloudFormationStackNotReady(cfnStack, cfnv1.ReadinessUpdate{SourceRevision: revision, Message: msg, Reason: cfnv1.TemplateBucketMissingReason, Stalled: true})
+					return cfnStack, cfnStack.GetRetryInterval(), nil
+				}
 				msg := fmt.Sprintf("Failed to upload template to S3 for stack '%s'", clientStack.Name)
 				log.Error(err, msg)
 				cfnStack = cfnv1.CloudFormationStackNotReady(cfnStack, cfnv1.ReadinessUpdate{SourceRevision: revision, Message: msg, Reason: cfnv1.TemplateUploadFailedReason})
 				return cfnStack, cfnStack.GetRetryInterval(), err
 			}
-			log.Info(fmt.Sprintf("Creating a change set for stack '%s' with template '%s'", clientStack.Name, clientStack.TemplateURL))
+			if len(keys) > 0 {
+				recordTemplateObjectKeyPrefix(&cfnStack, templateObjectKeyPrefix(clientStack))
+			}
+			if clientStack.TemplateURL != "" {
+				log.Info(fmt.Sprintf("Creating a change set for stack '%s' with template '%s'", clientStack.Name, clientStack.TemplateURL))
+			} else {
+				log.Info(fmt.Sprintf("Creating a change set for stack '%s' with an inline template", clientStack.Name))
+			}
 
 			if isCreate {
-				arn, err := r.CfnClient.CreateStack(clientStack)
+				arn, err := r.CfnClient.CreateStack(ctx, clientStack)
 				if err != nil {
 					msg := fmt.Sprintf("Failed to create a change set for stack '%s'", clientStack.Name)
 					log.Error(err, msg)
@@ -474,12 +672,16 @@ func (r *CloudFormationStackReconciler) reconcileChangeset(ctx context.Context,
 					return cfnStack, cfnStack.GetRetryInterval(), err
 				}
 				msg := fmt.Sprintf("Creation of stack '%s' in progress (change set %s)", clientStack.Name, arn)
+				if r.planOnly(cfnStack) {
+					msg = fmt.Sprintf("Planning of stack '%s' in progress (change set %s)", clientStack.Name, arn)
+				}
 				log.Info(msg)
 				r.event(ctx, cfnStack, revision, eventv1.EventSeverityInfo, msg)
 				cfnStack = cfnv1.CloudFormationStackProgressing(cfnStack, cfnv1.ReadinessUpdate{SourceRevision: revision, Message: msg, ChangeSetArn: arn})
+				cfnStack.Status.LastAttemptedChangeSetTraceParent = tracing.TraceParent(ctx)
 				return cfnStack, cfnStack.Spec.PollInterval.Duration, nil
 			} else {
-				arn, err := r.CfnClient.UpdateStack(clientStack)
+				arn, err := r.CfnClient.UpdateStack(ctx, clientStack)
 				if err != nil {
 					msg := fmt.Sprintf("Failed to create a change set for stack '%s'", clientStack.Name)
 					log.Error(err, msg)
@@ -487,21 +689,29 @@ func (r *CloudFormationStackReconciler) reconcileChangeset(ctx context.Context,
 					return cfnStack, cfnStack.GetRetryInterval(), err
 				}
 				msg := fmt.Sprintf("Update of stack '%s' in progress (change set %s)", clientStack.Name, arn)
+				if r.planOnly(cfnStack) {
+					msg = fmt.Sprintf("Planning of stack '%s' in progress (change set %s)", clientStack.Name, arn)
+				}
 				log.Info(msg)
 				r.event(ctx, cfnStack, revision, eventv1.EventSeverityInfo, msg)
 				cfnStack = cfnv1.CloudFormationStackProgressing(cfnStack, cfnv1.ReadinessUpdate{SourceRevision: revision, Message: msg, ChangeSetArn: arn})
+				cfnStack.Status.LastAttemptedChangeSetTraceParent = tracing.TraceParent(ctx)
 				return cfnStack, cfnStack.Spec.PollInterval.Duration, nil
 			}
 		} else if errors.As(err, &emptyErr) {
 			// This changeset was empty, meaning that the stack is up to date with the latest template
-			if err := r.CfnClient.DeleteChangeSet(clientStack); err != nil {
+			if err := r.CfnClient.DeleteChangeSet(ctx, clientStack); err != nil {
 				msg := fmt.Sprintf("Failed to delete an empty change set for stack '%s'", clientStack.Name)
 				log.Error(err, msg)
 				cfnStack = cfnv1.CloudFormationStackNotReady(cfnStack, cfnv1.ReadinessUpdate{SourceRevision: revision, Message: msg, Reason: cfnv1.CloudFormationApiCallFailedReason})
 				return cfnStack, cfnStack.GetRetryInterval(), err
 			}
+			if r.planOnly(cfnStack) {
+				return r.planned(ctx, cfnStack, clientStack, tmpl, revision, emptyErr.Arn, nil)
+			}
 			// Success!
 			log.Info(fmt.Sprintf("Successfully reconciled stack '%s' with change set '%s' (empty change set)", clientStack.Name, emptyErr.Arn))
+			r.cleanUpStackTemplates(ctx, &cfnStack, clientStack, tmpl)
 			return cfnv1.CloudFormationStackReady(cfnStack, emptyErr.Arn), cfnStack.Spec.Interval.Duration, nil
 		} else {
 			msg := fmt.Sprintf("Failed to describe a change set for stack '%s'", clientStack.Name)
@@ -513,7 +723,7 @@ func (r *CloudFormationStackReconciler) reconcileChangeset(ctx context.Context,
 
 	// If change set failed, delete it so we can create it again
 	if desc.IsFailed() {
-		if err := r.CfnClient.DeleteChangeSet(clientStack); err != nil {
+		if err := r.CfnClient.DeleteChangeSet(ctx, clientStack); err != nil {
 			msg := fmt.Sprintf("Failed to delete a failed change set for stack '%s'", clientStack.Name)
 			log.Error(err, msg)
 			cfnStack = cfnv1.CloudFormationStackNotReady(cfnStack, cfnv1.ReadinessUpdate{
@@ -527,6 +737,9 @@ func (r *CloudFormationStackReconciler) reconcileChangeset(ctx context.Context,
 
 		msg := fmt.Sprintf("Change set failed for stack '%s': status '%s', execution status '%s', reason '%s'", clientStack.Name, desc.Status, desc.ExecutionStatus, desc.StatusReason)
 		log.Info(msg)
+		if desc.ExecutionStatus == sdktypes.ExecutionStatusExecuteFailed {
+			metrics.RecordChangeSetExecution(r.stackMetricsLabels(cfnStack), metrics.ExecutionFailed)
+		}
 		cfnStack = cfnv1.CloudFormationStackNotReady(cfnStack, cfnv1.ReadinessUpdate{
 			ChangeSetArn:   desc.Arn,
 			SourceRevision: revision,
@@ -549,12 +762,26 @@ func (r *CloudFormationStackReconciler) reconcileChangeset(ctx context.Context,
 	if desc.IsSuccess() {
 		// Success!
 		log.Info(fmt.Sprintf("Successfully reconciled stack '%s' with change set '%s'", clientStack.Name, desc.Arn))
+		if cfnStack.Status.LastAppliedChangeSet != desc.Arn {
+			metrics.RecordChangeSetExecution(r.stackMetricsLabels(cfnStack), metrics.ExecutionSucceeded)
+		}
+		r.cleanUpStackTemplates(ctx, &cfnStack, clientStack, tmpl)
 		return cfnv1.CloudFormationStackReady(cfnStack, desc.Arn), cfnStack.Spec.Interval.Duration, nil
 	}
 
 	// Start the change set execution
 	if desc.ReadyForExecution() {
-		if err := r.CfnClient.ExecuteChangeSet(clientStack); err != nil {
+		// Check the change set's changes against the policies before executing the change set
+		if denied, err := r.checkPolicy(ctx, stackPolicy, &cfnStack, clientStack, tmpl, revision, desc.Arn, desc.Changes); denied {
+			return cfnStack, cfnStack.GetRetryInterval(), err
+		}
+
+		// Delete the change set instead of executing it when the stack is only planned
+		if r.planOnly(cfnStack) {
+			return r.planChangeset(ctx, cfnStack, clientStack, tmpl, revision, desc, isCreate)
+		}
+
+		if err := r.CfnClient.ExecuteChangeSet(ctx, clientStack); err != nil {
 			msg := fmt.Sprintf("Failed to execute a change set for stack '%s'", clientStack.Name)
 			log.Error(err, msg)
 			cfnStack = cfnv1.CloudFormationStackNotReady(cfnStack, cfnv1.ReadinessUpdate{
@@ -569,7 +796,7 @@ func (r *CloudFormationStackReconciler) reconcileChangeset(ctx context.Context,
 		log.Info(msg)
 		r.event(ctx, cfnStack, revision, eventv1.EventSeverityInfo, msg)
 		cfnStack = cfnv1.CloudFormationStackProgressing(cfnStack, cfnv1.ReadinessUpdate{Message: msg})
-		return cfnStack, cfnStack.Spec.PollInterval.Duration, nil
+		return cfnStack, r.stackActionPollInterval(cfnStack), nil
 	}
 
 	msg := fmt.Sprintf("Unexpected change set status for stack '%s': status '%s', execution status '%s', reason '%s'", clientStack.Name, desc.Status, desc.ExecutionStatus, desc.StatusReason)
@@ -584,25 +811,180 @@ func (r *CloudFormationStackReconciler) reconcileChangeset(ctx context.Context,
 	return cfnStack, cfnStack.GetRetryInterval(), nil
 }
 
-func (r *CloudFormationStackReconciler) uploadStackTemplate(clientStack *types.Stack) error {
-	id, err := uuid.NewRandom()
-	if err != nil {
-		return fmt.Errorf("generate random id: %w", err)
+// uploadStackTemplate uploads the file assets and any nested templates referenced by the stack template to S3, then uploads the
+// stack template itself if it is too large to be passed inline to CloudFormation.
+// Templates are uploaded with a key derived from the stack name and the template contents, so that CloudFormation
+// detects a change to the nested stack whenever the nested template contents change, and so that a template
+// that was already uploaded by a previous reconciliation is not uploaded again, unless it was uploaded with
+// another KMS key, other object tags or another ACL.
+// Returns the keys of the templates that the stack template depends on in the template bucket.
+// If an upload is required but no template bucket is configured, errTemplateBucketMissing is returned.
+func uploadStackTemplate(ctx context.Context, s3Client clients.S3Client, clientStack *types.Stack, tmpl *template.Template, labels metrics.StackLabels) (keys []string, err error) {
+	ctx, span := tracing.Start(ctx, "uploadStackTemplate", trace.WithAttributes(attribute.String("aws.s3.bucket", clientStack.TemplateBucket)))
+	defer func() {
+		tracing.End(span, err)
+	}()
+
+	store := func(key string, body string) (string, error) {
+		existing, err := s3Client.HeadTemplate(ctx, clientStack.TemplateBucket, clientStack.Region, key, &clientStack.TemplateBucketOptions)
+		if err == nil && existing.MatchesOptions {
+			return existing.URL, nil
+		}
+		var notFoundErr *s3.ErrObjectNotFound
+		if err != nil && !errors.As(err, &notFoundErr) {
+			return "", err
+		}
+		start := time.Now()
+		url, err := s3Client.UploadTemplate(ctx, clientStack.TemplateBucket, clientStack.Region, key, strings.NewReader(body), &clientStack.TemplateBucketOptions)
+		if err != nil {
+			return "", err
+		}
+		metrics.RecordTemplateUpload(labels, len(body), time.Since(start))
+		return url, nil
+	}
+
+	// Upload the assets that the template references at fixed keys, like CDK file assets
+	for _, asset := range tmpl.Assets {
+		if clientStack.TemplateBucket == "" {
+			return nil, fmt.Errorf("template '%s' references file assets that must be uploaded to S3: %w", tmpl.Path, errTemplateBucketMissing)
+		}
+		for _, key := range asset.Keys {
+			if _, err := store(key, string(asset.Body)); err != nil {
+				return nil, fmt.Errorf("upload asset '%s': %w", key, err)
+			}
+		}
 	}
-	objectKey := fmt.Sprintf("flux-%s-%s.template", clientStack.Name, id.String())
 
-	url, err := r.S3Client.UploadTemplate(
-		clientStack.TemplateBucket,
-		clientStack.Region,
-		objectKey,
-		strings.NewReader(clientStack.TemplateBody),
-	)
+	return resolveStackTemplate(clientStack, tmpl, store)
+}
+
+// resolveStackTemplate sets the template body or template URL of the stack, using the given function to store
+// the nested templates and the stack template (if it is too large to be passed inline) in the template bucket.
+// Returns the keys of the template bucket objects that the stack template depends on.
+func resolveStackTemplate(clientStack *types.Stack, tmpl *template.Template, store func(key string, body string) (string, error)) ([]string, error) {
+	var keys []string
+	storeTemplate := func(t *template.Template) (string, error) {
+		if clientStack.TemplateBucket == "" {
+			return "", errTemplateBucketMissing
+		}
+		key := templateObjectKey(clientStack, t)
+		url, err := store(key, t.Body)
+		if err != nil {
+			return "", err
+		}
+		keys = append(keys, key)
+		return url, nil
+	}
+
+	rewritten, err := tmpl.RewriteNested(storeTemplate)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	clientStack.TemplateBody = rewritten.Body
+
+	if len(clientStack.TemplateBody) <= cloudformation.MaxTemplateBodySize {
+		// Pass the template to CloudFormation inline
+		clientStack.TemplateURL = ""
+		return keys, nil
+	}
+	if clientStack.TemplateBucket == "" {
+		return nil, fmt.Errorf("template '%s' is larger than %d bytes and must be uploaded to S3: %w",
+			tmpl.Path, cloudformation.MaxTemplateBodySize, errTemplateBucketMissing)
 	}
 
+	url, err := storeTemplate(rewritten)
+	if err != nil {
+		return nil, err
+	}
 	clientStack.TemplateURL = url
-	return nil
+	return keys, nil
+}
+
+// cleanUpStackTemplates deletes the templates uploaded for the stack that are older than the template retention
+// period, and that are not referenced by the stack template that was last applied to the stack.
+// The templates uploaded under previous key prefixes of the stack are deleted too, and the previous key prefixes
+// are removed from the stack status once no templates are left under them.
+// Failures are logged, but do not fail the reconciliation.
+func (r *CloudFormationStackReconciler) cleanUpStackTemplates(ctx context.Context, cfnStack *cfnv1.CloudFormationStack, clientStack *types.Stack, tmpl *template.Template) {
+	log := ctrl.LoggerFrom(ctx)
+
+	if r.TemplateRetention <= 0 || clientStack.TemplateBucket == "" {
+		return
+	}
+	s3Client := r.S3Client
+
+	// Find the templates referenced by the applied stack template, without uploading them again
+	appliedStack := *clientStack
+	inUse, err := resolveStackTemplate(&appliedStack, tmpl, func(key string, body string) (string, error) {
+		existing, err := s3Client.HeadTemplate(ctx, clientStack.TemplateBucket, clientStack.Region, key, &clientStack.TemplateBucketOptions)
+		if err != nil {
+			return "", err
+		}
+		return existing.URL, nil
+	})
+	if err != nil {
+		log.Error(err, fmt.Sprintf("Failed to find the templates in use by stack '%s', skipping template clean up", clientStack.Name))
+		return
+	}
+	inUseKeys := make(map[string]bool, len(inUse))
+	for _, key := range inUse {
+		inUseKeys[key] = true
+	}
+
+	currentPrefix := templateObjectKeyPrefix(clientStack)
+	if _, err := r.deleteExpiredTemplates(ctx, clientStack, currentPrefix, inUseKeys); err != nil {
+		log.Error(err, fmt.Sprintf("Failed to clean up the templates uploaded for stack '%s'", clientStack.Name))
+	}
+
+	var prefixes []string
+	for _, prefix := range cfnStack.Status.TemplateObjectKeyPrefixes {
+		if prefix != currentPrefix {
+			remaining, err := r.deleteExpiredTemplates(ctx, clientStack, prefix, inUseKeys)
+			if err != nil {
+				log.Error(err, fmt.Sprintf("Failed to clean up the templates uploaded for stack '%s' under previous key prefix '%s'", clientStack.Name, prefix))
+			} else if remaining == 0 {
+				continue
+			}
+		}
+		prefixes = append(prefixes, prefix)
+	}
+	cfnStack.Status.TemplateObjectKeyPrefixes = prefixes
+}
+
+// deleteExpiredTemplates deletes the templates of the stack under the given key prefix that are older than the
+// template retention period and that are not in use. Returns the number of templates left under the key prefix.
+func (r *CloudFormationStackReconciler) deleteExpiredTemplates(ctx context.Context, clientStack *types.Stack, prefix string, inUseKeys map[string]bool) (int, error) {
+	log := ctrl.LoggerFrom(ctx)
+	s3Client := r.S3Client
+
+	objects, err := s3Client.ListTemplates(ctx, clientStack.TemplateBucket, clientStack.Region, prefix, &clientStack.TemplateBucketOptions)
+	if err != nil {
+		return 0, fmt.Errorf("list templates with key prefix '%s': %w", prefix, err)
+	}
+
+	keyPattern := templateObjectKeyPattern(prefix)
+	expiry := time.Now().Add(-r.TemplateRetention)
+	var expired []string
+	remaining := 0
+	for _, object := range objects {
+		if !keyPattern.MatchString(object.Key) {
+			continue
+		}
+		if inUseKeys[object.Key] || object.LastModified.After(expiry) {
+			remaining++
+			continue
+		}
+		expired = append(expired, object.Key)
+	}
+	if len(expired) == 0 {
+		return remaining, nil
+	}
+
+	if err := s3Client.DeleteTemplates(ctx, clientStack.TemplateBucket, clientStack.Region, expired, &clientStack.TemplateBucketOptions); err != nil {
+		return 0, fmt.Errorf("delete expired templates with key prefix '%s': %w", prefix, err)
+	}
+	log.Info(fmt.Sprintf("Deleted %d expired templates for stack '%s'", len(expired), clientStack.Name))
+	return remaining, nil
 }
 
 // reconcileDelete deletes the CloudFormation stack.
@@ -616,6 +998,27 @@ func (r *CloudFormationStackReconciler) reconcileDelete(ctx context.Context, cfn
 		return cfnStack, ctrl.Result{}, err
 	}
 
+	// A stack that was deployed before it was planned must not be orphaned: keep the finalizer,
+	// so that the stack is deleted once it is no longer planned
+	if r.planOnly(cfnStack) && cfnStack.Spec.Mode != cfnv1.ObserveMode && cfnStack.Spec.DestroyStackOnDeletion &&
+		(cfnStack.Status.LastAppliedRevision != "" || cfnStack.Status.LastAppliedChangeSet != "") {
+		msg := fmt.Sprintf("Deletion of stack '%s' is blocked until the stack is no longer planned: the stack was deployed and must be destroyed on deletion", cfnStack.Spec.StackName)
+		if r.DryRun {
+			msg = fmt.Sprintf("Deletion of stack '%s' is blocked until the controller is no longer in dry-run mode: the stack was deployed and must be destroyed on deletion", cfnStack.Spec.StackName)
+		}
+		log.Info(msg)
+		r.event(ctx, cfnStack, cfnStack.Status.LastAttemptedRevision, eventv1.EventSeverityError, msg)
+		cfnStack = cfnv1.CloudFormationStackNotReady(cfnStack, cfnv1.ReadinessUpdate{Message: msg, Reason: cfnv1.DeletionBlockedReason})
+		return cfnStack, ctrl.Result{RequeueAfter: cfnStack.GetRetryInterval()}, nil
+	}
+
+	if r.planOnly(cfnStack) || cfnStack.Spec.Mode == cfnv1.ObserveMode {
+		log.Info(fmt.Sprintf("Skipping CloudFormation stack deletion for stack '%s/%s' (stack is only planned or observed)", cfnStack.Namespace, cfnStack.Name))
+		controllerutil.RemoveFinalizer(&cfnStack, cfnv1.CloudFormationStackFinalizer)
+		err := r.Update(ctx, &cfnStack)
+		return cfnStack, ctrl.Result{}, err
+	}
+
 	if !cfnStack.Spec.DestroyStackOnDeletion {
 		log.Info(fmt.Sprintf("Skipping CloudFormation stack deletion for stack '%s/%s' (DestroyStackOnDeletion is false)", cfnStack.Namespace, cfnStack.Name))
 		controllerutil.RemoveFinalizer(&cfnStack, cfnv1.CloudFormationStackFinalizer)
@@ -627,13 +1030,12 @@ func (r *CloudFormationStackReconciler) reconcileDelete(ctx context.Context, cfn
 	clientStack := &types.Stack{
 		Name: cfnStack.Spec.StackName,
 		// Region:         cfnStack.Spec.Region,
-		Generation:     cfnStack.Generation,
-		SourceRevision: cfnStack.Status.LastAttemptedRevision,
-		ChangeSetArn:   cfnStack.Status.LastAttemptedChangeSet,
+		ChangeSetName: cloudformation.ExtractChangeSetName(cfnStack.Status.LastAttemptedChangeSet),
+		ChangeSetArn:  cfnStack.Status.LastAttemptedChangeSet,
 	}
 
 	// Find the existing stack, if any
-	desc, err := r.CfnClient.DescribeStack(clientStack)
+	desc, err := r.CfnClient.DescribeStack(ctx, clientStack)
 
 	if err != nil {
 		var e *cloudformation.ErrStackNotFound
@@ -661,7 +1063,7 @@ func (r *CloudFormationStackReconciler) reconcileDelete(ctx context.Context, cfn
 		msg := fmt.Sprintf("Stack action is in progress for stack marked for deletion '%s' (status '%s'), waiting for stack action to complete", clientStack.Name, desc.StackStatus)
 		log.Info(msg)
 		cfnStack = cfnv1.CloudFormationStackProgressing(cfnStack, cfnv1.ReadinessUpdate{Message: msg})
-		return cfnStack, ctrl.Result{RequeueAfter: cfnStack.Spec.PollInterval.Duration}, nil
+		return cfnStack, ctrl.Result{RequeueAfter: r.stackActionPollInterval(cfnStack)}, nil
 	}
 
 	if desc.ReadyForCleanup() {
@@ -672,7 +1074,7 @@ func (r *CloudFormationStackReconciler) reconcileDelete(ctx context.Context, cfn
 		}
 
 		// start the stack deletion
-		if err := r.CfnClient.DeleteStack(clientStack); err != nil {
+		if err := r.CfnClient.DeleteStack(ctx, clientStack); err != nil {
 			msg := fmt.Sprintf("Failed to delete the stack '%s'", clientStack.Name)
 			log.Error(err, msg)
 			r.event(ctx, cfnStack, cfnStack.Status.LastAttemptedRevision, eventv1.EventSeverityError, fmt.Sprintf("Failed to reconcile stack: %s", err.Error()))
@@ -684,7 +1086,7 @@ func (r *CloudFormationStackReconciler) reconcileDelete(ctx context.Context, cfn
 		log.Info(msg)
 		r.event(ctx, cfnStack, cfnStack.Status.LastAttemptedRevision, eventv1.EventSeverityInfo, msg)
 		cfnStack = cfnv1.CloudFormationStackProgressing(cfnStack, cfnv1.ReadinessUpdate{Message: msg})
-		return cfnStack, ctrl.Result{RequeueAfter: cfnStack.Spec.PollInterval.Duration}, nil
+		return cfnStack, ctrl.Result{RequeueAfter: r.stackActionPollInterval(cfnStack)}, nil
 	}
 
 	msg := fmt.Sprintf("Unexpected stack status for stack '%s': %s", clientStack.Name, desc.StackStatus)
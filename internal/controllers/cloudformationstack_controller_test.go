@@ -15,17 +15,26 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	sdktypes "github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	"github.com/aws/smithy-go"
 	cfnv1 "github.com/awslabs/aws-cloudformation-controller-for-flux/api/v1alpha1"
+	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/artifact"
+	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/build"
+	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/clients"
 	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/clients/cloudformation"
 	clientmocks "github.com/awslabs/aws-cloudformation-controller-for-flux/internal/clients/mocks"
+	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/clients/s3"
 	clienttypes "github.com/awslabs/aws-cloudformation-controller-for-flux/internal/clients/types"
 	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/mocks"
+	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/stackevents"
+	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/template"
+	kstatus "github.com/fluxcd/cli-utils/pkg/kstatus/status"
 	"github.com/fluxcd/pkg/apis/meta"
 	"github.com/fluxcd/pkg/runtime/acl"
 	sourcev1 "github.com/fluxcd/source-controller/api/v1"
@@ -33,46 +42,53 @@ import (
 	"github.com/golang/mock/gomock"
 	"github.com/hashicorp/go-retryablehttp"
 	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
 	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	// +kubebuilder:scaffold:imports
 )
 
 const (
-	mockStackName                                     = "mock-stack"
-	mockNamespace                                     = "mock-namespace"
-	mockGenerationId                                  = 1
-	mockGenerationId2                                 = 2
-	mockSourceNamespace                               = "mock-namespace"
-	mockSourceNamespace2                              = "mock-source-namespace"
-	mockRealStackName                                 = "mock-real-stack"
-	mockTemplatePath                                  = "template.yaml"
-	mockTemplateGitRepoName                           = "mock-cfn-template-git-repo"
-	mockTemplateOCIRepoName                           = "mock-cfn-template-oci-repo"
-	mockTemplateSourceBucketName                      = "mock-cfn-template-source-bucket"
-	mockSourceRevision                                = "mock-source-revision"
-	mockSourceRevision2                               = "mock-new-source-revision"
-	mockTemplateSourceFile                            = "../../examples/my-cloudformation-templates/template.yaml"
-	mockTemplateUploadBucket                          = "mock-template-upload-bucket"
-	mockChangeSetName                                 = "flux-1-mock-source-revision"
-	mockChangeSetNameNewGeneration                    = "flux-2-mock-source-revision"
-	mockChangeSetNameNewSourceRevision                = "flux-1-mock-new-source-revision"
-	mockChangeSetArn                                  = "arn:aws:cloudformation:us-west-2:123456789012:changeSet/flux-1-mock-source-revision/uuid"
-	mockChangeSetArnNewGeneration                     = "arn:aws:cloudformation:us-west-2:123456789012:changeSet/flux-2-mock-source-revision/uuid"
-	mockChangeSetArnNewSourceRevision                 = "arn:aws:cloudformation:us-west-2:123456789012:changeSet/flux-1-mock-new-source-revision/uuid"
-	mockChangeSetArnNewSourceRevisionAndNewGeneration = "arn:aws:cloudformation:us-west-2:123456789012:changeSet/flux-2-mock-new-source-revision/uuid"
-	mockTemplateS3Url                                 = "https://mock-template-upload-bucket.s3.mock-region.amazonaws.com/mock-flux-template-file-object-key"
+	mockStackName                = "mock-stack"
+	mockNamespace                = "mock-namespace"
+	mockGenerationId             = 1
+	mockGenerationId2            = 2
+	mockSourceNamespace          = "mock-namespace"
+	mockSourceNamespace2         = "mock-source-namespace"
+	mockRealStackName            = "mock-real-stack"
+	mockTemplatePath             = "template.yaml"
+	mockTemplateGitRepoName      = "mock-cfn-template-git-repo"
+	mockTemplateOCIRepoName      = "mock-cfn-template-oci-repo"
+	mockTemplateSourceBucketName = "mock-cfn-template-source-bucket"
+	mockSourceRevision           = "mock-source-revision"
+	mockSourceRevision2          = "mock-new-source-revision"
+	mockTemplateSourceFile       = "../../examples/my-cloudformation-templates/template.yaml"
+	mockTemplateUploadBucket     = "mock-template-upload-bucket"
+	mockTemplateS3Url            = "https://mock-template-upload-bucket.s3.mock-region.amazonaws.com/mock-flux-template-file-object-key"
+	mockConfigDigest             = "sha256:f5eed354ddd458b4741a31ead6425edf7bc319a625169093424d79edb4353feb"
+	mockSourceDigest             = "sha256:a719d1a02d551b210a2361ff3359086a7bf8b8368e6af3d8d8ecc3ced1c34896"
 )
 
 var (
 	scheme = runtime.NewScheme()
 
+	mockChangeSetName              = generateChangeSetName(mockGenerationId, mockSourceDigest, mockConfigDigest)
+	mockChangeSetNameNewGeneration = generateChangeSetName(mockGenerationId2, mockSourceDigest, mockConfigDigest)
+	mockChangeSetArn               = generateChangeSetArn(mockChangeSetName)
+	mockChangeSetArnNewGeneration  = generateChangeSetArn(mockChangeSetNameNewGeneration)
+
+	mockStackUpdateTime        = time.Date(2024, 5, 1, 12, 30, 0, 123000000, time.UTC)
+	mockAppliedStackUpdateTime = metav1.NewMicroTime(mockStackUpdateTime)
+
 	mockStackNamespacedName = types.NamespacedName{
 		Name:      mockStackName,
 		Namespace: mockNamespace,
@@ -207,6 +223,15 @@ func compareCfnStackStatus(t *testing.T, kind string, expectedStackStatus *cfnv1
 	require.Equalf(t, expectedStackStatus.LastAppliedChangeSet, actualStackStatus.LastAppliedChangeSet, "LastAppliedChangeSet in %s stack status not equal", kind)
 	require.Equalf(t, expectedStackStatus.LastAttemptedChangeSet, actualStackStatus.LastAttemptedChangeSet, "LastAttemptedChangeSet in %s stack status not equal", kind)
 	require.Equalf(t, expectedStackStatus.StackName, actualStackStatus.StackName, "StackName in %s stack status not equal", kind)
+	require.Equalf(t, expectedStackStatus.StackID, actualStackStatus.StackID, "StackID in %s stack status not equal", kind)
+	require.Equalf(t, expectedStackStatus.StackStatus, actualStackStatus.StackStatus, "StackStatus in %s stack status not equal", kind)
+	require.Equalf(t, expectedStackStatus.Outputs, actualStackStatus.Outputs, "Outputs in %s stack status not equal", kind)
+	require.Equalf(t, expectedStackStatus.Resources, actualStackStatus.Resources, "Resources in %s stack status not equal", kind)
+	require.Equalf(t, expectedStackStatus.LastPlan, actualStackStatus.LastPlan, "LastPlan in %s stack status not equal", kind)
+	require.Equalf(t, expectedStackStatus.LastAppliedConfigDigest, actualStackStatus.LastAppliedConfigDigest, "LastAppliedConfigDigest in %s stack status not equal", kind)
+	require.Equalf(t, expectedStackStatus.LastAttemptedSourceDigest, actualStackStatus.LastAttemptedSourceDigest, "LastAttemptedSourceDigest in %s stack status not equal", kind)
+	require.Equalf(t, expectedStackStatus.LastAppliedStackUpdateTime, actualStackStatus.LastAppliedStackUpdateTime, "LastAppliedStackUpdateTime in %s stack status not equal", kind)
+	require.Equalf(t, expectedStackStatus.TemplateObjectKeyPrefixes, actualStackStatus.TemplateObjectKeyPrefixes, "TemplateObjectKeyPrefixes in %s stack status not equal", kind)
 	require.Equalf(t, len(expectedStackStatus.Conditions), len(actualStackStatus.Conditions), "Wrong number of conditions in %s stack status", kind)
 	for i, expectedCondition := range expectedStackStatus.Conditions {
 		actualCondition := actualStackStatus.Conditions[i]
@@ -218,7 +243,42 @@ func compareCfnStackStatus(t *testing.T, kind string, expectedStackStatus *cfnv1
 	}
 }
 
+// createArtifact packages the given files into an artifact tarball, and returns the artifact and its checksum
+func createArtifact(files map[string]string) ([]byte, string, error) {
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+
+	for name, contents := range files {
+		header := &tar.Header{
+			Name:     name,
+			Mode:     0o600,
+			Size:     int64(len(contents)),
+			Typeflag: tar.TypeReg,
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return nil, "", err
+		}
+		if _, err := tw.Write([]byte(contents)); err != nil {
+			return nil, "", err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, "", err
+	}
+	if err := gw.Close(); err != nil {
+		return nil, "", err
+	}
+
+	return buf.Bytes(), fmt.Sprintf("sha256:%x", sha256.Sum256(buf.Bytes())), nil
+}
+
 func generateMockArtifactServer(t *testing.T) *httptest.Server {
+	return generateArtifactServer(t, mockTestArtifactBytes)
+}
+
+func generateArtifactServer(t *testing.T, artifact []byte) *httptest.Server {
 	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path != "/path.tar.gz" {
 			t.Errorf("Expected to request '/path.tar.gz', got: %s", r.URL.Path)
@@ -227,6 +287,21 @@ func generateMockArtifactServer(t *testing.T) *httptest.Server {
 			t.Errorf("Expected to do a GET request, got: %s", r.Method)
 		}
 		w.WriteHeader(http.StatusOK)
+		w.Write(artifact)
+	}))
+}
+
+// generateUnavailableArtifactServer serves the mock artifact once, for the artifact cached before the reconciliation,
+// and then fails to serve artifacts.
+func generateUnavailableArtifactServer(t *testing.T) *httptest.Server {
+	served := false
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if served {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		served = true
+		w.WriteHeader(http.StatusOK)
 		w.Write(mockTestArtifactBytes)
 	}))
 }
@@ -279,12 +354,41 @@ func generateMockOCIRepoSource(ociRepo *sourcev1b2.OCIRepository, mockSourceArti
 	}
 }
 
-func generateStackInput(generation int64, sourceRevision string, changeSetArn string) *clienttypes.Stack {
+// generateChangeSetName returns the name of the change set that deploys the deployment inputs with the given digests
+// at the given stack generation
+func generateChangeSetName(generation int64, sourceDigest string, configDigest string) string {
+	cfnStack := cfnv1.CloudFormationStack{ObjectMeta: metav1.ObjectMeta{Generation: generation}}
+	return build.ChangeSetName(cfnStack, sourceDigest, configDigest)
+}
+
+// generateAppliedChangeSetName returns the name of the change set that deploys the mock deployment inputs
+// at the given stack generation, after a change set was applied to the stack at the mock stack update time
+func generateAppliedChangeSetName(generation int64) string {
+	cfnStack := cfnv1.CloudFormationStack{ObjectMeta: metav1.ObjectMeta{Generation: generation}}
+	cfnStack.Status.LastAppliedStackUpdateTime = &mockAppliedStackUpdateTime
+	return build.ChangeSetName(cfnStack, mockSourceDigest, mockConfigDigest)
+}
+
+// setChangeSetName sets the name of the change set that deploys the given stack input,
+// built from the given source template
+func setChangeSetName(input *clienttypes.Stack, generation int64, tmpl *template.Template) {
+	input.ChangeSetName = generateChangeSetName(generation, build.SourceDigest(tmpl), build.ConfigDigest(input, tmpl))
+}
+
+// generateMockTemplate returns the template loaded from the mock source artifact
+func generateMockTemplate() *template.Template {
+	return &template.Template{Path: mockTemplatePath, Body: mockTemplateSourceFileContents}
+}
+
+func generateChangeSetArn(changeSetName string) string {
+	return fmt.Sprintf("arn:aws:cloudformation:us-west-2:123456789012:changeSet/%s/uuid", changeSetName)
+}
+
+func generateStackInput(generation int64, changeSetArn string) *clienttypes.Stack {
 	return &clienttypes.Stack{
-		Name:           mockRealStackName,
-		Generation:     generation,
-		SourceRevision: sourceRevision,
-		ChangeSetArn:   changeSetArn,
+		Name:          mockRealStackName,
+		ChangeSetName: generateChangeSetName(generation, mockSourceDigest, mockConfigDigest),
+		ChangeSetArn:  changeSetArn,
 		StackConfig: &clienttypes.StackConfig{
 			TemplateBucket: mockTemplateUploadBucket,
 			TemplateBody:   mockTemplateSourceFileContents,
@@ -310,17 +414,11 @@ func generateStackInput(generation int64, sourceRevision string, changeSetArn st
 	}
 }
 
-func generateStackInputWithTemplateUrl(generation int64, sourceRevision string) *clienttypes.Stack {
-	input := generateStackInput(generation, sourceRevision, "")
-	input.StackConfig.TemplateURL = mockTemplateS3Url
-	return input
-}
-
 func generateMockCfnStackSpec() cfnv1.CloudFormationStackSpec {
 	return cfnv1.CloudFormationStackSpec{
 		StackName:              mockRealStackName,
 		TemplatePath:           mockTemplatePath,
-		SourceRef:              mockGitRef,
+		SourceRef:              &mockGitRef,
 		Interval:               mockInterval,
 		RetryInterval:          &mockRetryInterval,
 		PollInterval:           mockPollInterval,
@@ -329,15 +427,6 @@ func generateMockCfnStackSpec() cfnv1.CloudFormationStackSpec {
 	}
 }
 
-func mockS3ClientUpload(s3Client *clientmocks.MockS3Client) {
-	s3Client.EXPECT().UploadTemplate(
-		mockTemplateUploadBucket,
-		"",
-		gomock.Any(),
-		strings.NewReader(mockTemplateSourceFileContents),
-	).Return(mockTemplateS3Url, nil)
-}
-
 type expectedEvent struct {
 	eventType string
 	severity  string
@@ -357,12 +446,23 @@ type reconciliationLoopTestCase struct {
 	fillInOCIRepository        func(ociRepo *sourcev1b2.OCIRepository, mockSourceArtifactURL string)
 	mockDependencyRetrieval    func(k8sClient *mocks.MockClient)
 	mockSourceRetrieval        func(k8sClient *mocks.MockClient)
+	mockPostBuildRetrieval     func(k8sClient *mocks.MockClient)
+	mockPolicyRetrieval        func(k8sClient *mocks.MockClient)
 	mockArtifactServer         func(t *testing.T) *httptest.Server
+	cachedSourceRevision       string
 	mockCfnClientCalls         func(cfnClient *clientmocks.MockCloudFormationClient)
 	mockS3ClientCalls          func(s3Client *clientmocks.MockS3Client)
+	mockStackCacheCalls        func(stackCache *clientmocks.MockCloudFormationStackCache)
 	markStackAsInProgress      bool
 	removeFinalizers           bool
+	dryRun                     bool
+	noTemplateBucket           bool
+	templateRetention          time.Duration
+	templateBucketOptions      clienttypes.TemplateBucketOptions
+	stackEventsPollInterval    time.Duration
 	wantedStackStatus          *cfnv1.CloudFormationStackStatus
+	wantedInitialKstatus       kstatus.Status
+	wantedKstatus              kstatus.Status
 	wantedEvents               []*expectedEvent
 	wantedRequeueDelay         time.Duration
 	wantedErr                  error
@@ -380,6 +480,8 @@ func runReconciliationLoopTestCase(t *testing.T, tc *reconciliationLoopTestCase)
 	eventRecorder := mocks.NewMockEventRecorder(mockCtrl)
 	//metricsH := fluxCtrlRuntime.NewMetrics(mgr, metrics.NewRecorder(), cfnv1.CloudFormationStackFinalizer)
 	httpClient := retryablehttp.NewClient()
+	artifactCache, err := artifact.NewCache(t.TempDir(), 1<<20)
+	require.NoError(t, err)
 
 	var server *httptest.Server
 	if tc.mockArtifactServer != nil {
@@ -390,6 +492,24 @@ func runReconciliationLoopTestCase(t *testing.T, tc *reconciliationLoopTestCase)
 	defer server.Close()
 	mockSourceArtifactURL := server.URL + "/path.tar.gz"
 
+	// Compute the kstatus of the stack object before the reconciliation, like Flux health checks do
+	if tc.wantedInitialKstatus != "" {
+		initialCfnStack := cfnv1.CloudFormationStack{}
+		tc.fillInInitialCfnStack(&initialCfnStack)
+		requireKstatus(t, tc.wantedInitialKstatus, &initialCfnStack)
+	}
+
+	// Cache the mock artifact at an earlier revision of the source, like a previous reconciliation would have
+	if tc.cachedSourceRevision != "" {
+		cached, err := artifactCache.Get(ctx, httpClient, &sourcev1.Artifact{
+			URL:      mockSourceArtifactURL,
+			Revision: tc.cachedSourceRevision,
+			Digest:   mockTemplateContentsChecksum,
+		}, "")
+		require.NoError(t, err)
+		cached.Release()
+	}
+
 	// Mock the initial CFNStack object that the controller will work off of
 	if tc.cfnStackObjectDoesNotExist {
 		k8sClient.EXPECT().Get(
@@ -428,6 +548,16 @@ func runReconciliationLoopTestCase(t *testing.T, tc *reconciliationLoopTestCase)
 	if tc.mockSourceRetrieval != nil {
 		tc.mockSourceRetrieval(k8sClient)
 	}
+
+	// Mock the ConfigMaps and Secrets referenced for post-build substitutions
+	if tc.mockPostBuildRetrieval != nil {
+		tc.mockPostBuildRetrieval(k8sClient)
+	}
+
+	// Mock the ConfigMap referenced for policies
+	if tc.mockPolicyRetrieval != nil {
+		tc.mockPolicyRetrieval(k8sClient)
+	}
 	if tc.fillInSource != nil {
 		k8sClient.EXPECT().Get(
 			gomock.Any(),
@@ -497,6 +627,12 @@ func runReconciliationLoopTestCase(t *testing.T, tc *reconciliationLoopTestCase)
 	if tc.mockS3ClientCalls != nil {
 		tc.mockS3ClientCalls(s3Client)
 	}
+	var stackCache clients.CloudFormationStackCache
+	if tc.mockStackCacheCalls != nil {
+		mockStackCache := clientmocks.NewMockCloudFormationStackCache(mockCtrl)
+		tc.mockStackCacheCalls(mockStackCache)
+		stackCache = mockStackCache
+	}
 
 	// Validate event recorded
 	if tc.wantedEvents != nil {
@@ -541,6 +677,9 @@ func runReconciliationLoopTestCase(t *testing.T, tc *reconciliationLoopTestCase)
 				return errors.New(fmt.Sprintf("Expected a CloudFormationStack object, but got a %T", obj))
 			}
 			compareCfnStackStatus(t, "final", tc.wantedStackStatus, &cfnStack.Status)
+			if tc.wantedKstatus != "" {
+				requireKstatus(t, tc.wantedKstatus, cfnStack)
+			}
 			return nil
 		})
 		if tc.markStackAsInProgress {
@@ -568,6 +707,13 @@ func runReconciliationLoopTestCase(t *testing.T, tc *reconciliationLoopTestCase)
 						Reason:             "Progressing",
 						Message:            "Stack reconciliation in progress",
 					},
+					{
+						Type:               "Reconciling",
+						Status:             "True",
+						ObservedGeneration: expectedStack.Generation,
+						Reason:             "Progressing",
+						Message:            "Stack reconciliation in progress",
+					},
 				}
 				compareCfnStackStatus(t, "initial", &expectedStack.Status, &cfnStack.Status)
 				return nil
@@ -579,13 +725,21 @@ func runReconciliationLoopTestCase(t *testing.T, tc *reconciliationLoopTestCase)
 		}
 	}
 
+	templateBucket := mockTemplateUploadBucket
+	if tc.noTemplateBucket {
+		templateBucket = ""
+	}
+
 	reconciler := &CloudFormationStackReconciler{
-		Scheme:         scheme,
-		Client:         k8sClient,
-		CfnClient:      cfnClient,
-		S3Client:       s3Client,
-		TemplateBucket: mockTemplateUploadBucket,
-		EventRecorder:  eventRecorder,
+		Scheme:                scheme,
+		Client:                k8sClient,
+		CfnClient:             cfnClient,
+		StackCache:            stackCache,
+		S3Client:              s3Client,
+		TemplateBucket:        templateBucket,
+		TemplateRetention:     tc.templateRetention,
+		TemplateBucketOptions: tc.templateBucketOptions,
+		EventRecorder:         eventRecorder,
 		//Metrics:           metricsH,
 		ControllerName:    "cfn-controller-test",
 		ControllerVersion: "v0.0.0",
@@ -593,8 +747,12 @@ func runReconciliationLoopTestCase(t *testing.T, tc *reconciliationLoopTestCase)
 			"default-tag": "default-tag-value",
 		},
 		NoCrossNamespaceRef: true,
+		ArtifactCache:       artifactCache,
 		httpClient:          httpClient,
 		requeueDependency:   mockDependencyRetryIntervalDuration,
+		DryRun:              tc.dryRun,
+
+		stackEventsPollInterval: tc.stackEventsPollInterval,
 	}
 
 	request := ctrl.Request{NamespacedName: mockStackNamespacedName}
@@ -638,6 +796,13 @@ func TestCfnController_ReconcileStack(t *testing.T) {
 						Reason:             "DependencyNotReady",
 						Message:            "Dependencies do not meet ready condition (dependency 'mock-namespace2/mock-stack2' is not ready)",
 					},
+					{
+						Type:               "Reconciling",
+						Status:             "True",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "ProgressingWithRetry",
+						Message:            "Dependencies do not meet ready condition (dependency 'mock-namespace2/mock-stack2' is not ready)",
+					},
 				},
 			},
 			markStackAsInProgress: true,
@@ -677,6 +842,13 @@ func TestCfnController_ReconcileStack(t *testing.T) {
 								Reason:             "HelloWorld",
 								Message:            "Hello world",
 							},
+							{
+								Type:               "Reconciling",
+								Status:             "True",
+								ObservedGeneration: mockGenerationId,
+								Reason:             "ProgressingWithRetry",
+								Message:            "Hello world",
+							},
 						},
 					}
 					return nil
@@ -702,6 +874,13 @@ func TestCfnController_ReconcileStack(t *testing.T) {
 						Reason:             "DependencyNotReady",
 						Message:            "Dependencies do not meet ready condition (dependency 'mock-namespace2/mock-stack2' is not ready)",
 					},
+					{
+						Type:               "Reconciling",
+						Status:             "True",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "ProgressingWithRetry",
+						Message:            "Dependencies do not meet ready condition (dependency 'mock-namespace2/mock-stack2' is not ready)",
+					},
 				},
 			},
 			markStackAsInProgress: true,
@@ -744,10 +923,11 @@ func TestCfnController_ReconcileStack(t *testing.T) {
 			}},
 			wantedRequeueDelay: mockPollIntervalDuration,
 			wantedStackStatus: &cfnv1.CloudFormationStackStatus{
-				ObservedGeneration:     mockGenerationId,
-				StackName:              mockRealStackName,
-				LastAttemptedRevision:  mockSourceRevision,
-				LastAttemptedChangeSet: mockChangeSetArn,
+				ObservedGeneration:        mockGenerationId,
+				StackName:                 mockRealStackName,
+				LastAttemptedRevision:     mockSourceRevision,
+				LastAttemptedChangeSet:    mockChangeSetArn,
+				LastAttemptedSourceDigest: mockSourceDigest,
 				Conditions: []metav1.Condition{
 					{
 						Type:               "Ready",
@@ -756,11 +936,17 @@ func TestCfnController_ReconcileStack(t *testing.T) {
 						Reason:             "Progressing",
 						Message:            fmt.Sprintf("Creation of stack 'mock-real-stack' in progress (change set %s)", mockChangeSetArn),
 					},
+					{
+						Type:               "Reconciling",
+						Status:             "True",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "Progressing",
+						Message:            fmt.Sprintf("Creation of stack 'mock-real-stack' in progress (change set %s)", mockChangeSetArn),
+					},
 				},
 			},
 			markStackAsInProgress: true,
 			fillInSource:          generateMockGitRepoSource,
-			mockS3ClientCalls:     mockS3ClientUpload,
 			fillInInitialCfnStack: func(cfnStack *cfnv1.CloudFormationStack) {
 				cfnStack.Name = mockStackName
 				cfnStack.Namespace = mockNamespace
@@ -768,14 +954,14 @@ func TestCfnController_ReconcileStack(t *testing.T) {
 				cfnStack.Spec = generateMockCfnStackSpec()
 			},
 			mockCfnClientCalls: func(cfnClient *clientmocks.MockCloudFormationClient) {
-				expectedDescribeStackIn := generateStackInput(mockGenerationId, mockSourceRevision, "")
-				cfnClient.EXPECT().DescribeStack(expectedDescribeStackIn).Return(nil, &cloudformation.ErrStackNotFound{})
+				expectedDescribeStackIn := generateStackInput(mockGenerationId, "")
+				cfnClient.EXPECT().DescribeStack(gomock.Any(), expectedDescribeStackIn).Return(nil, &cloudformation.ErrStackNotFound{})
 
-				expectedDescribeChangeSetIn := generateStackInput(mockGenerationId, mockSourceRevision, "")
-				cfnClient.EXPECT().DescribeChangeSet(expectedDescribeChangeSetIn).Return(nil, &cloudformation.ErrChangeSetNotFound{})
+				expectedDescribeChangeSetIn := generateStackInput(mockGenerationId, "")
+				cfnClient.EXPECT().DescribeChangeSet(gomock.Any(), expectedDescribeChangeSetIn).Return(nil, &cloudformation.ErrChangeSetNotFound{})
 
-				expectedCreateStackIn := generateStackInputWithTemplateUrl(mockGenerationId, mockSourceRevision)
-				cfnClient.EXPECT().CreateStack(expectedCreateStackIn).Return(mockChangeSetArn, nil)
+				expectedCreateStackIn := generateStackInput(mockGenerationId, "")
+				cfnClient.EXPECT().CreateStack(gomock.Any(), expectedCreateStackIn).Return(mockChangeSetArn, nil)
 			},
 		},
 		"reconcile stack if dependencies are ready": {
@@ -786,10 +972,11 @@ func TestCfnController_ReconcileStack(t *testing.T) {
 			}},
 			wantedRequeueDelay: mockPollIntervalDuration,
 			wantedStackStatus: &cfnv1.CloudFormationStackStatus{
-				ObservedGeneration:     mockGenerationId,
-				StackName:              mockRealStackName,
-				LastAttemptedRevision:  mockSourceRevision,
-				LastAttemptedChangeSet: mockChangeSetArn,
+				ObservedGeneration:        mockGenerationId,
+				StackName:                 mockRealStackName,
+				LastAttemptedRevision:     mockSourceRevision,
+				LastAttemptedChangeSet:    mockChangeSetArn,
+				LastAttemptedSourceDigest: mockSourceDigest,
 				Conditions: []metav1.Condition{
 					{
 						Type:               "Ready",
@@ -798,11 +985,17 @@ func TestCfnController_ReconcileStack(t *testing.T) {
 						Reason:             "Progressing",
 						Message:            fmt.Sprintf("Creation of stack 'mock-real-stack' in progress (change set %s)", mockChangeSetArn),
 					},
+					{
+						Type:               "Reconciling",
+						Status:             "True",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "Progressing",
+						Message:            fmt.Sprintf("Creation of stack 'mock-real-stack' in progress (change set %s)", mockChangeSetArn),
+					},
 				},
 			},
 			markStackAsInProgress: true,
 			fillInSource:          generateMockGitRepoSource,
-			mockS3ClientCalls:     mockS3ClientUpload,
 			fillInInitialCfnStack: func(cfnStack *cfnv1.CloudFormationStack) {
 				cfnStack.Name = mockStackName
 				cfnStack.Namespace = mockNamespace
@@ -847,14 +1040,14 @@ func TestCfnController_ReconcileStack(t *testing.T) {
 				})
 			},
 			mockCfnClientCalls: func(cfnClient *clientmocks.MockCloudFormationClient) {
-				expectedDescribeStackIn := generateStackInput(mockGenerationId, mockSourceRevision, "")
-				cfnClient.EXPECT().DescribeStack(expectedDescribeStackIn).Return(nil, &cloudformation.ErrStackNotFound{})
+				expectedDescribeStackIn := generateStackInput(mockGenerationId, "")
+				cfnClient.EXPECT().DescribeStack(gomock.Any(), expectedDescribeStackIn).Return(nil, &cloudformation.ErrStackNotFound{})
 
-				expectedDescribeChangeSetIn := generateStackInput(mockGenerationId, mockSourceRevision, "")
-				cfnClient.EXPECT().DescribeChangeSet(expectedDescribeChangeSetIn).Return(nil, &cloudformation.ErrChangeSetNotFound{})
+				expectedDescribeChangeSetIn := generateStackInput(mockGenerationId, "")
+				cfnClient.EXPECT().DescribeChangeSet(gomock.Any(), expectedDescribeChangeSetIn).Return(nil, &cloudformation.ErrChangeSetNotFound{})
 
-				expectedCreateStackIn := generateStackInputWithTemplateUrl(mockGenerationId, mockSourceRevision)
-				cfnClient.EXPECT().CreateStack(expectedCreateStackIn).Return(mockChangeSetArn, nil)
+				expectedCreateStackIn := generateStackInput(mockGenerationId, "")
+				cfnClient.EXPECT().CreateStack(gomock.Any(), expectedCreateStackIn).Return(mockChangeSetArn, nil)
 			},
 		},
 		"create stack with parameters": {
@@ -865,10 +1058,11 @@ func TestCfnController_ReconcileStack(t *testing.T) {
 			}},
 			wantedRequeueDelay: mockPollIntervalDuration,
 			wantedStackStatus: &cfnv1.CloudFormationStackStatus{
-				ObservedGeneration:     mockGenerationId,
-				StackName:              mockRealStackName,
-				LastAttemptedRevision:  mockSourceRevision,
-				LastAttemptedChangeSet: mockChangeSetArn,
+				ObservedGeneration:        mockGenerationId,
+				StackName:                 mockRealStackName,
+				LastAttemptedRevision:     mockSourceRevision,
+				LastAttemptedChangeSet:    mockChangeSetArn,
+				LastAttemptedSourceDigest: mockSourceDigest,
 				Conditions: []metav1.Condition{
 					{
 						Type:               "Ready",
@@ -877,11 +1071,17 @@ func TestCfnController_ReconcileStack(t *testing.T) {
 						Reason:             "Progressing",
 						Message:            fmt.Sprintf("Creation of stack 'mock-real-stack' in progress (change set %s)", mockChangeSetArn),
 					},
+					{
+						Type:               "Reconciling",
+						Status:             "True",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "Progressing",
+						Message:            fmt.Sprintf("Creation of stack 'mock-real-stack' in progress (change set %s)", mockChangeSetArn),
+					},
 				},
 			},
 			markStackAsInProgress: true,
 			fillInSource:          generateMockGitRepoSource,
-			mockS3ClientCalls:     mockS3ClientUpload,
 			fillInInitialCfnStack: func(cfnStack *cfnv1.CloudFormationStack) {
 				cfnStack.Name = mockStackName
 				cfnStack.Namespace = mockNamespace
@@ -895,22 +1095,25 @@ func TestCfnController_ReconcileStack(t *testing.T) {
 				}
 			},
 			mockCfnClientCalls: func(cfnClient *clientmocks.MockCloudFormationClient) {
-				expectedDescribeStackIn := generateStackInput(mockGenerationId, mockSourceRevision, "")
+				expectedDescribeStackIn := generateStackInput(mockGenerationId, "")
 				expectedDescribeStackIn.Parameters = []sdktypes.Parameter{
 					{
 						ParameterKey:   aws.String("ParamKey"),
 						ParameterValue: aws.String("ParamValue"),
 					},
 				}
-				cfnClient.EXPECT().DescribeStack(expectedDescribeStackIn).Return(nil, &cloudformation.ErrStackNotFound{})
+				setChangeSetName(expectedDescribeStackIn, mockGenerationId, generateMockTemplate())
+				cfnClient.EXPECT().DescribeStack(gomock.Any(), expectedDescribeStackIn).Return(nil, &cloudformation.ErrStackNotFound{})
 
-				expectedDescribeChangeSetIn := generateStackInput(mockGenerationId, mockSourceRevision, "")
+				expectedDescribeChangeSetIn := generateStackInput(mockGenerationId, "")
 				expectedDescribeChangeSetIn.Parameters = expectedDescribeStackIn.Parameters
-				cfnClient.EXPECT().DescribeChangeSet(expectedDescribeChangeSetIn).Return(nil, &cloudformation.ErrChangeSetNotFound{})
+				expectedDescribeChangeSetIn.ChangeSetName = expectedDescribeStackIn.ChangeSetName
+				cfnClient.EXPECT().DescribeChangeSet(gomock.Any(), expectedDescribeChangeSetIn).Return(nil, &cloudformation.ErrChangeSetNotFound{})
 
-				expectedCreateStackIn := generateStackInputWithTemplateUrl(mockGenerationId, mockSourceRevision)
+				expectedCreateStackIn := generateStackInput(mockGenerationId, "")
 				expectedCreateStackIn.Parameters = expectedDescribeStackIn.Parameters
-				cfnClient.EXPECT().CreateStack(expectedCreateStackIn).Return(mockChangeSetArn, nil)
+				expectedCreateStackIn.ChangeSetName = expectedDescribeStackIn.ChangeSetName
+				cfnClient.EXPECT().CreateStack(gomock.Any(), expectedCreateStackIn).Return(mockChangeSetArn, nil)
 			},
 		},
 		"update stack with parameters": {
@@ -921,12 +1124,13 @@ func TestCfnController_ReconcileStack(t *testing.T) {
 			}},
 			wantedRequeueDelay: mockPollIntervalDuration,
 			wantedStackStatus: &cfnv1.CloudFormationStackStatus{
-				ObservedGeneration:     mockGenerationId2,
-				StackName:              mockRealStackName,
-				LastAttemptedRevision:  mockSourceRevision,
-				LastAppliedRevision:    mockSourceRevision,
-				LastAttemptedChangeSet: mockChangeSetArnNewGeneration,
-				LastAppliedChangeSet:   mockChangeSetArn,
+				ObservedGeneration:        mockGenerationId2,
+				StackName:                 mockRealStackName,
+				LastAttemptedRevision:     mockSourceRevision,
+				LastAppliedRevision:       mockSourceRevision,
+				LastAttemptedChangeSet:    mockChangeSetArnNewGeneration,
+				LastAttemptedSourceDigest: mockSourceDigest,
+				LastAppliedChangeSet:      mockChangeSetArn,
 				Conditions: []metav1.Condition{
 					{
 						Type:               "Ready",
@@ -935,11 +1139,17 @@ func TestCfnController_ReconcileStack(t *testing.T) {
 						Reason:             "Progressing",
 						Message:            fmt.Sprintf("Update of stack 'mock-real-stack' in progress (change set %s)", mockChangeSetArnNewGeneration),
 					},
+					{
+						Type:               "Reconciling",
+						Status:             "True",
+						ObservedGeneration: mockGenerationId2,
+						Reason:             "Progressing",
+						Message:            fmt.Sprintf("Update of stack 'mock-real-stack' in progress (change set %s)", mockChangeSetArnNewGeneration),
+					},
 				},
 			},
 			markStackAsInProgress: true,
 			fillInSource:          generateMockGitRepoSource,
-			mockS3ClientCalls:     mockS3ClientUpload,
 			fillInInitialCfnStack: func(cfnStack *cfnv1.CloudFormationStack) {
 				cfnStack.Name = mockStackName
 				cfnStack.Namespace = mockNamespace
@@ -970,26 +1180,29 @@ func TestCfnController_ReconcileStack(t *testing.T) {
 				}
 			},
 			mockCfnClientCalls: func(cfnClient *clientmocks.MockCloudFormationClient) {
-				expectedDescribeStackIn := generateStackInput(mockGenerationId2, mockSourceRevision, "")
+				expectedDescribeStackIn := generateStackInput(mockGenerationId2, "")
 				expectedDescribeStackIn.Parameters = []sdktypes.Parameter{
 					{
 						ParameterKey:   aws.String("ParamKey"),
 						ParameterValue: aws.String("ParamValue"),
 					},
 				}
-				cfnClient.EXPECT().DescribeStack(expectedDescribeStackIn).Return(&clienttypes.StackDescription{
+				setChangeSetName(expectedDescribeStackIn, mockGenerationId2, generateMockTemplate())
+				cfnClient.EXPECT().DescribeStack(gomock.Any(), expectedDescribeStackIn).Return(&clienttypes.StackDescription{
 					StackName:         aws.String(mockRealStackName),
 					StackStatus:       sdktypes.StackStatusCreateComplete,
 					StackStatusReason: aws.String("hello world"),
 				}, nil)
 
-				expectedDescribeChangeSetIn := generateStackInput(mockGenerationId2, mockSourceRevision, "")
+				expectedDescribeChangeSetIn := generateStackInput(mockGenerationId2, "")
 				expectedDescribeChangeSetIn.Parameters = expectedDescribeStackIn.Parameters
-				cfnClient.EXPECT().DescribeChangeSet(expectedDescribeChangeSetIn).Return(nil, &cloudformation.ErrChangeSetNotFound{})
+				expectedDescribeChangeSetIn.ChangeSetName = expectedDescribeStackIn.ChangeSetName
+				cfnClient.EXPECT().DescribeChangeSet(gomock.Any(), expectedDescribeChangeSetIn).Return(nil, &cloudformation.ErrChangeSetNotFound{})
 
-				expectedUpdateStackIn := generateStackInputWithTemplateUrl(mockGenerationId2, mockSourceRevision)
+				expectedUpdateStackIn := generateStackInput(mockGenerationId2, "")
 				expectedUpdateStackIn.Parameters = expectedDescribeStackIn.Parameters
-				cfnClient.EXPECT().UpdateStack(expectedUpdateStackIn).Return(mockChangeSetArnNewGeneration, nil)
+				expectedUpdateStackIn.ChangeSetName = expectedDescribeStackIn.ChangeSetName
+				cfnClient.EXPECT().UpdateStack(gomock.Any(), expectedUpdateStackIn).Return(mockChangeSetArnNewGeneration, nil)
 			},
 		},
 		"create stack with tags": {
@@ -1000,10 +1213,11 @@ func TestCfnController_ReconcileStack(t *testing.T) {
 			}},
 			wantedRequeueDelay: mockPollIntervalDuration,
 			wantedStackStatus: &cfnv1.CloudFormationStackStatus{
-				ObservedGeneration:     mockGenerationId,
-				StackName:              mockRealStackName,
-				LastAttemptedRevision:  mockSourceRevision,
-				LastAttemptedChangeSet: mockChangeSetArn,
+				ObservedGeneration:        mockGenerationId,
+				StackName:                 mockRealStackName,
+				LastAttemptedRevision:     mockSourceRevision,
+				LastAttemptedChangeSet:    mockChangeSetArn,
+				LastAttemptedSourceDigest: mockSourceDigest,
 				Conditions: []metav1.Condition{
 					{
 						Type:               "Ready",
@@ -1012,11 +1226,17 @@ func TestCfnController_ReconcileStack(t *testing.T) {
 						Reason:             "Progressing",
 						Message:            fmt.Sprintf("Creation of stack 'mock-real-stack' in progress (change set %s)", mockChangeSetArn),
 					},
+					{
+						Type:               "Reconciling",
+						Status:             "True",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "Progressing",
+						Message:            fmt.Sprintf("Creation of stack 'mock-real-stack' in progress (change set %s)", mockChangeSetArn),
+					},
 				},
 			},
 			markStackAsInProgress: true,
 			fillInSource:          generateMockGitRepoSource,
-			mockS3ClientCalls:     mockS3ClientUpload,
 			fillInInitialCfnStack: func(cfnStack *cfnv1.CloudFormationStack) {
 				cfnStack.Name = mockStackName
 				cfnStack.Namespace = mockNamespace
@@ -1030,22 +1250,25 @@ func TestCfnController_ReconcileStack(t *testing.T) {
 				}
 			},
 			mockCfnClientCalls: func(cfnClient *clientmocks.MockCloudFormationClient) {
-				expectedDescribeStackIn := generateStackInput(mockGenerationId, mockSourceRevision, "")
+				expectedDescribeStackIn := generateStackInput(mockGenerationId, "")
 				expectedDescribeStackIn.Tags = append(expectedDescribeStackIn.Tags,
 					sdktypes.Tag{
 						Key:   aws.String("TagKey"),
 						Value: aws.String("TagValue"),
 					},
 				)
-				cfnClient.EXPECT().DescribeStack(expectedDescribeStackIn).Return(nil, &cloudformation.ErrStackNotFound{})
+				setChangeSetName(expectedDescribeStackIn, mockGenerationId, generateMockTemplate())
+				cfnClient.EXPECT().DescribeStack(gomock.Any(), expectedDescribeStackIn).Return(nil, &cloudformation.ErrStackNotFound{})
 
-				expectedDescribeChangeSetIn := generateStackInput(mockGenerationId, mockSourceRevision, "")
+				expectedDescribeChangeSetIn := generateStackInput(mockGenerationId, "")
 				expectedDescribeChangeSetIn.Tags = expectedDescribeStackIn.Tags
-				cfnClient.EXPECT().DescribeChangeSet(expectedDescribeChangeSetIn).Return(nil, &cloudformation.ErrChangeSetNotFound{})
+				expectedDescribeChangeSetIn.ChangeSetName = expectedDescribeStackIn.ChangeSetName
+				cfnClient.EXPECT().DescribeChangeSet(gomock.Any(), expectedDescribeChangeSetIn).Return(nil, &cloudformation.ErrChangeSetNotFound{})
 
-				expectedCreateStackIn := generateStackInputWithTemplateUrl(mockGenerationId, mockSourceRevision)
+				expectedCreateStackIn := generateStackInput(mockGenerationId, "")
 				expectedCreateStackIn.Tags = expectedDescribeStackIn.Tags
-				cfnClient.EXPECT().CreateStack(expectedCreateStackIn).Return(mockChangeSetArn, nil)
+				expectedCreateStackIn.ChangeSetName = expectedDescribeStackIn.ChangeSetName
+				cfnClient.EXPECT().CreateStack(gomock.Any(), expectedCreateStackIn).Return(mockChangeSetArn, nil)
 			},
 		},
 		"update stack with tags": {
@@ -1056,12 +1279,13 @@ func TestCfnController_ReconcileStack(t *testing.T) {
 			}},
 			wantedRequeueDelay: mockPollIntervalDuration,
 			wantedStackStatus: &cfnv1.CloudFormationStackStatus{
-				ObservedGeneration:     mockGenerationId2,
-				StackName:              mockRealStackName,
-				LastAttemptedRevision:  mockSourceRevision,
-				LastAppliedRevision:    mockSourceRevision,
-				LastAttemptedChangeSet: mockChangeSetArnNewGeneration,
-				LastAppliedChangeSet:   mockChangeSetArn,
+				ObservedGeneration:        mockGenerationId2,
+				StackName:                 mockRealStackName,
+				LastAttemptedRevision:     mockSourceRevision,
+				LastAppliedRevision:       mockSourceRevision,
+				LastAttemptedChangeSet:    mockChangeSetArnNewGeneration,
+				LastAttemptedSourceDigest: mockSourceDigest,
+				LastAppliedChangeSet:      mockChangeSetArn,
 				Conditions: []metav1.Condition{
 					{
 						Type:               "Ready",
@@ -1070,11 +1294,17 @@ func TestCfnController_ReconcileStack(t *testing.T) {
 						Reason:             "Progressing",
 						Message:            fmt.Sprintf("Update of stack 'mock-real-stack' in progress (change set %s)", mockChangeSetArnNewGeneration),
 					},
+					{
+						Type:               "Reconciling",
+						Status:             "True",
+						ObservedGeneration: mockGenerationId2,
+						Reason:             "Progressing",
+						Message:            fmt.Sprintf("Update of stack 'mock-real-stack' in progress (change set %s)", mockChangeSetArnNewGeneration),
+					},
 				},
 			},
 			markStackAsInProgress: true,
 			fillInSource:          generateMockGitRepoSource,
-			mockS3ClientCalls:     mockS3ClientUpload,
 			fillInInitialCfnStack: func(cfnStack *cfnv1.CloudFormationStack) {
 				cfnStack.Name = mockStackName
 				cfnStack.Namespace = mockNamespace
@@ -1105,26 +1335,29 @@ func TestCfnController_ReconcileStack(t *testing.T) {
 				}
 			},
 			mockCfnClientCalls: func(cfnClient *clientmocks.MockCloudFormationClient) {
-				expectedDescribeStackIn := generateStackInput(mockGenerationId2, mockSourceRevision, "")
+				expectedDescribeStackIn := generateStackInput(mockGenerationId2, "")
 				expectedDescribeStackIn.Tags = append(expectedDescribeStackIn.Tags,
 					sdktypes.Tag{
 						Key:   aws.String("TagKey"),
 						Value: aws.String("TagValue"),
 					},
 				)
-				cfnClient.EXPECT().DescribeStack(expectedDescribeStackIn).Return(&clienttypes.StackDescription{
+				setChangeSetName(expectedDescribeStackIn, mockGenerationId2, generateMockTemplate())
+				cfnClient.EXPECT().DescribeStack(gomock.Any(), expectedDescribeStackIn).Return(&clienttypes.StackDescription{
 					StackName:         aws.String(mockRealStackName),
 					StackStatus:       sdktypes.StackStatusCreateComplete,
 					StackStatusReason: aws.String("hello world"),
 				}, nil)
 
-				expectedDescribeChangeSetIn := generateStackInput(mockGenerationId2, mockSourceRevision, "")
+				expectedDescribeChangeSetIn := generateStackInput(mockGenerationId2, "")
 				expectedDescribeChangeSetIn.Tags = expectedDescribeStackIn.Tags
-				cfnClient.EXPECT().DescribeChangeSet(expectedDescribeChangeSetIn).Return(nil, &cloudformation.ErrChangeSetNotFound{})
+				expectedDescribeChangeSetIn.ChangeSetName = expectedDescribeStackIn.ChangeSetName
+				cfnClient.EXPECT().DescribeChangeSet(gomock.Any(), expectedDescribeChangeSetIn).Return(nil, &cloudformation.ErrChangeSetNotFound{})
 
-				expectedUpdateStackIn := generateStackInputWithTemplateUrl(mockGenerationId2, mockSourceRevision)
+				expectedUpdateStackIn := generateStackInput(mockGenerationId2, "")
 				expectedUpdateStackIn.Tags = expectedDescribeStackIn.Tags
-				cfnClient.EXPECT().UpdateStack(expectedUpdateStackIn).Return(mockChangeSetArnNewGeneration, nil)
+				expectedUpdateStackIn.ChangeSetName = expectedDescribeStackIn.ChangeSetName
+				cfnClient.EXPECT().UpdateStack(gomock.Any(), expectedUpdateStackIn).Return(mockChangeSetArnNewGeneration, nil)
 			},
 		},
 		"continue stack rollback if the real stack has UPDATE_ROLLBACK_FAILED status": {
@@ -1135,9 +1368,10 @@ func TestCfnController_ReconcileStack(t *testing.T) {
 			}},
 			wantedRequeueDelay: mockRetryIntervalDuration,
 			wantedStackStatus: &cfnv1.CloudFormationStackStatus{
-				ObservedGeneration:    mockGenerationId,
-				StackName:             mockRealStackName,
-				LastAttemptedRevision: mockSourceRevision,
+				ObservedGeneration:        mockGenerationId,
+				StackName:                 mockRealStackName,
+				LastAttemptedRevision:     mockSourceRevision,
+				LastAttemptedSourceDigest: mockSourceDigest,
 				Conditions: []metav1.Condition{
 					{
 						Type:               "Ready",
@@ -1146,6 +1380,13 @@ func TestCfnController_ReconcileStack(t *testing.T) {
 						Reason:             "StackRollbackFailed",
 						Message:            "Stack 'mock-real-stack' has a previously failed rollback (status 'UPDATE_ROLLBACK_FAILED'), continuing rollback",
 					},
+					{
+						Type:               "Reconciling",
+						Status:             "True",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "ProgressingWithRetry",
+						Message:            "Stack 'mock-real-stack' has a previously failed rollback (status 'UPDATE_ROLLBACK_FAILED'), continuing rollback",
+					},
 				},
 			},
 			markStackAsInProgress: true,
@@ -1157,13 +1398,13 @@ func TestCfnController_ReconcileStack(t *testing.T) {
 				cfnStack.Spec = generateMockCfnStackSpec()
 			},
 			mockCfnClientCalls: func(cfnClient *clientmocks.MockCloudFormationClient) {
-				expectedDescribeStackIn := generateStackInput(mockGenerationId, mockSourceRevision, "")
-				cfnClient.EXPECT().DescribeStack(expectedDescribeStackIn).Return(&clienttypes.StackDescription{
+				expectedDescribeStackIn := generateStackInput(mockGenerationId, "")
+				cfnClient.EXPECT().DescribeStack(gomock.Any(), expectedDescribeStackIn).Return(&clienttypes.StackDescription{
 					StackName:   aws.String(mockRealStackName),
 					StackStatus: sdktypes.StackStatusUpdateRollbackFailed,
 				}, nil)
 
-				cfnClient.EXPECT().ContinueStackRollback(expectedDescribeStackIn).Return(nil)
+				cfnClient.EXPECT().ContinueStackRollback(gomock.Any(), expectedDescribeStackIn).Return(nil)
 			},
 		},
 	}
@@ -1186,10 +1427,11 @@ func TestCfnController_ReconcileStack(t *testing.T) {
 			&reconciliationLoopTestCase{
 				wantedRequeueDelay: mockPollIntervalDuration,
 				wantedStackStatus: &cfnv1.CloudFormationStackStatus{
-					ObservedGeneration:     mockGenerationId,
-					StackName:              mockRealStackName,
-					LastAttemptedRevision:  mockSourceRevision,
-					LastAttemptedChangeSet: mockChangeSetArn,
+					ObservedGeneration:        mockGenerationId,
+					StackName:                 mockRealStackName,
+					LastAttemptedRevision:     mockSourceRevision,
+					LastAttemptedSourceDigest: mockSourceDigest,
+					LastAttemptedChangeSet:    mockChangeSetArn,
 					Conditions: []metav1.Condition{
 						{
 							Type:               "Ready",
@@ -1198,6 +1440,13 @@ func TestCfnController_ReconcileStack(t *testing.T) {
 							Reason:             "Progressing",
 							Message:            fmt.Sprintf("Stack action for stack '%s' is in progress (status: '%s'), waiting for stack action to complete", mockRealStackName, expectedStackStatus),
 						},
+						{
+							Type:               "Reconciling",
+							Status:             "True",
+							ObservedGeneration: mockGenerationId,
+							Reason:             "Progressing",
+							Message:            fmt.Sprintf("Stack action for stack '%s' is in progress (status: '%s'), waiting for stack action to complete", mockRealStackName, expectedStackStatus),
+						},
 					},
 				},
 				fillInSource: generateMockGitRepoSource,
@@ -1219,12 +1468,19 @@ func TestCfnController_ReconcileStack(t *testing.T) {
 								Reason:             "Progressing",
 								Message:            "Hello world",
 							},
+							{
+								Type:               "Reconciling",
+								Status:             "True",
+								ObservedGeneration: mockGenerationId,
+								Reason:             "Progressing",
+								Message:            "Hello world",
+							},
 						},
 					}
 				},
 				mockCfnClientCalls: func(cfnClient *clientmocks.MockCloudFormationClient) {
-					expectedDescribeStackIn := generateStackInput(mockGenerationId, mockSourceRevision, mockChangeSetArn)
-					cfnClient.EXPECT().DescribeStack(expectedDescribeStackIn).Return(&clienttypes.StackDescription{
+					expectedDescribeStackIn := generateStackInput(mockGenerationId, mockChangeSetArn)
+					cfnClient.EXPECT().DescribeStack(gomock.Any(), expectedDescribeStackIn).Return(&clienttypes.StackDescription{
 						StackName:   aws.String(mockRealStackName),
 						StackStatus: expectedStackStatus,
 					}, nil)
@@ -1232,6 +1488,12 @@ func TestCfnController_ReconcileStack(t *testing.T) {
 			}
 	}
 
+	// Stack actions in progress are polled at a longer interval when stack status change events are received
+	stackEventsTestCase := *testCases["set stack as in-progress if the real stack has UPDATE_IN_PROGRESS status"]
+	stackEventsTestCase.stackEventsPollInterval = 5 * time.Minute
+	stackEventsTestCase.wantedRequeueDelay = 5 * time.Minute
+	testCases["poll the in-progress stack at the stack events poll interval if stack status change events are received"] = &stackEventsTestCase
+
 	unrecoverableFailureStackStatuses := []sdktypes.StackStatus{
 		sdktypes.StackStatusCreateFailed,
 		sdktypes.StackStatusDeleteFailed,
@@ -1251,9 +1513,10 @@ func TestCfnController_ReconcileStack(t *testing.T) {
 				}},
 				wantedRequeueDelay: mockRetryIntervalDuration,
 				wantedStackStatus: &cfnv1.CloudFormationStackStatus{
-					ObservedGeneration:    mockGenerationId,
-					StackName:             mockRealStackName,
-					LastAttemptedRevision: mockSourceRevision,
+					ObservedGeneration:        mockGenerationId,
+					StackName:                 mockRealStackName,
+					LastAttemptedRevision:     mockSourceRevision,
+					LastAttemptedSourceDigest: mockSourceDigest,
 					Conditions: []metav1.Condition{
 						{
 							Type:               "Ready",
@@ -1262,6 +1525,13 @@ func TestCfnController_ReconcileStack(t *testing.T) {
 							Reason:             "UnrecoverableStackFailure",
 							Message:            expectedStatusMessage,
 						},
+						{
+							Type:               "Reconciling",
+							Status:             "True",
+							ObservedGeneration: mockGenerationId,
+							Reason:             "ProgressingWithRetry",
+							Message:            expectedStatusMessage,
+						},
 					},
 				},
 				markStackAsInProgress: true,
@@ -1273,14 +1543,14 @@ func TestCfnController_ReconcileStack(t *testing.T) {
 					cfnStack.Spec = generateMockCfnStackSpec()
 				},
 				mockCfnClientCalls: func(cfnClient *clientmocks.MockCloudFormationClient) {
-					expectedDescribeStackIn := generateStackInput(mockGenerationId, mockSourceRevision, "")
-					cfnClient.EXPECT().DescribeStack(expectedDescribeStackIn).Return(&clienttypes.StackDescription{
+					expectedDescribeStackIn := generateStackInput(mockGenerationId, "")
+					cfnClient.EXPECT().DescribeStack(gomock.Any(), expectedDescribeStackIn).Return(&clienttypes.StackDescription{
 						StackName:         aws.String(mockRealStackName),
 						StackStatus:       expectedStackStatus,
 						StackStatusReason: aws.String("hello world"),
 					}, nil)
 
-					cfnClient.EXPECT().DeleteStack(expectedDescribeStackIn).Return(nil)
+					cfnClient.EXPECT().DeleteStack(gomock.Any(), expectedDescribeStackIn).Return(nil)
 				},
 			}
 	}
@@ -1297,6 +1567,17 @@ func TestCfnController_ReconcileStack(t *testing.T) {
 		sdktypes.StackStatusImportRollbackFailed,
 	}
 	idleStackStatuses := append(successfulDeploymentStackStatuses, recoverableFailureStackStatuses...)
+
+	// The new source revision changes the stack's template
+	newRevisionTemplate := mockTemplateSourceFileContents + "\n# new source revision\n"
+	newRevisionArtifact, newRevisionChecksum, err := createArtifact(map[string]string{mockTemplatePath: newRevisionTemplate})
+	require.NoError(t, err)
+	newRevisionTmpl := &template.Template{Path: mockTemplatePath, Body: newRevisionTemplate}
+	newRevisionStackInput := generateStackInput(mockGenerationId, "")
+	newRevisionStackInput.TemplateBody = newRevisionTemplate
+	setChangeSetName(newRevisionStackInput, mockGenerationId, newRevisionTmpl)
+	mockChangeSetArnNewSourceRevision := generateChangeSetArn(newRevisionStackInput.ChangeSetName)
+
 	for _, stackStatus := range idleStackStatuses {
 		expectedStackStatus := stackStatus
 
@@ -1305,12 +1586,13 @@ func TestCfnController_ReconcileStack(t *testing.T) {
 			wantedEvents:       []*expectedEvent{},
 			wantedRequeueDelay: mockPollIntervalDuration,
 			wantedStackStatus: &cfnv1.CloudFormationStackStatus{
-				ObservedGeneration:     mockGenerationId2,
-				StackName:              mockRealStackName,
-				LastAttemptedRevision:  mockSourceRevision,
-				LastAppliedRevision:    mockSourceRevision,
-				LastAttemptedChangeSet: mockChangeSetArnNewGeneration,
-				LastAppliedChangeSet:   mockChangeSetArn,
+				ObservedGeneration:        mockGenerationId2,
+				StackName:                 mockRealStackName,
+				LastAttemptedRevision:     mockSourceRevision,
+				LastAppliedRevision:       mockSourceRevision,
+				LastAttemptedChangeSet:    mockChangeSetArnNewGeneration,
+				LastAttemptedSourceDigest: mockSourceDigest,
+				LastAppliedChangeSet:      mockChangeSetArn,
 				Conditions: []metav1.Condition{
 					{
 						Type:               "Ready",
@@ -1319,6 +1601,13 @@ func TestCfnController_ReconcileStack(t *testing.T) {
 						Reason:             "Progressing",
 						Message:            expectedStatusMsgNewGeneration,
 					},
+					{
+						Type:               "Reconciling",
+						Status:             "True",
+						ObservedGeneration: mockGenerationId2,
+						Reason:             "Progressing",
+						Message:            expectedStatusMsgNewGeneration,
+					},
 				},
 			},
 			fillInSource: generateMockGitRepoSource,
@@ -1346,20 +1635,19 @@ func TestCfnController_ReconcileStack(t *testing.T) {
 				}
 			},
 			markStackAsInProgress: true,
-			mockS3ClientCalls:     mockS3ClientUpload,
 			mockCfnClientCalls: func(cfnClient *clientmocks.MockCloudFormationClient) {
-				expectedDescribeStackIn := generateStackInput(mockGenerationId2, mockSourceRevision, "")
-				cfnClient.EXPECT().DescribeStack(expectedDescribeStackIn).Return(&clienttypes.StackDescription{
+				expectedDescribeStackIn := generateStackInput(mockGenerationId2, "")
+				cfnClient.EXPECT().DescribeStack(gomock.Any(), expectedDescribeStackIn).Return(&clienttypes.StackDescription{
 					StackName:         aws.String(mockRealStackName),
 					StackStatus:       expectedStackStatus,
 					StackStatusReason: aws.String("hello world"),
 				}, nil)
 
-				expectedDescribeChangeSetIn := generateStackInput(mockGenerationId2, mockSourceRevision, "")
-				cfnClient.EXPECT().DescribeChangeSet(expectedDescribeChangeSetIn).Return(nil, &cloudformation.ErrChangeSetNotFound{})
+				expectedDescribeChangeSetIn := generateStackInput(mockGenerationId2, "")
+				cfnClient.EXPECT().DescribeChangeSet(gomock.Any(), expectedDescribeChangeSetIn).Return(nil, &cloudformation.ErrChangeSetNotFound{})
 
-				expectedUpdateStackIn := generateStackInputWithTemplateUrl(mockGenerationId2, mockSourceRevision)
-				cfnClient.EXPECT().UpdateStack(expectedUpdateStackIn).Return(mockChangeSetArnNewGeneration, nil)
+				expectedUpdateStackIn := generateStackInput(mockGenerationId2, "")
+				cfnClient.EXPECT().UpdateStack(gomock.Any(), expectedUpdateStackIn).Return(mockChangeSetArnNewGeneration, nil)
 			},
 		}
 
@@ -1387,12 +1675,13 @@ func TestCfnController_ReconcileStack(t *testing.T) {
 			wantedEvents:       []*expectedEvent{},
 			wantedRequeueDelay: mockPollIntervalDuration,
 			wantedStackStatus: &cfnv1.CloudFormationStackStatus{
-				ObservedGeneration:     mockGenerationId,
-				StackName:              mockRealStackName,
-				LastAttemptedRevision:  mockSourceRevision2,
-				LastAppliedRevision:    mockSourceRevision,
-				LastAttemptedChangeSet: mockChangeSetArnNewSourceRevision,
-				LastAppliedChangeSet:   mockChangeSetArn,
+				ObservedGeneration:        mockGenerationId,
+				StackName:                 mockRealStackName,
+				LastAttemptedRevision:     mockSourceRevision2,
+				LastAppliedRevision:       mockSourceRevision,
+				LastAttemptedChangeSet:    mockChangeSetArnNewSourceRevision,
+				LastAttemptedSourceDigest: build.SourceDigest(newRevisionTmpl),
+				LastAppliedChangeSet:      mockChangeSetArn,
 				Conditions: []metav1.Condition{
 					{
 						Type:               "Ready",
@@ -1401,9 +1690,22 @@ func TestCfnController_ReconcileStack(t *testing.T) {
 						Reason:             "Progressing",
 						Message:            expectedStatusMsgNewSourceRevision,
 					},
+					{
+						Type:               "Reconciling",
+						Status:             "True",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "Progressing",
+						Message:            expectedStatusMsgNewSourceRevision,
+					},
 				},
 			},
-			fillInSource: generateMockGitRepoSource2,
+			fillInSource: func(gitRepo *sourcev1.GitRepository, mockSourceArtifactURL string) {
+				generateMockGitRepoSource2(gitRepo, mockSourceArtifactURL)
+				gitRepo.Status.Artifact.Digest = newRevisionChecksum
+			},
+			mockArtifactServer: func(t *testing.T) *httptest.Server {
+				return generateArtifactServer(t, newRevisionArtifact)
+			},
 			fillInInitialCfnStack: func(cfnStack *cfnv1.CloudFormationStack) {
 				cfnStack.Name = mockStackName
 				cfnStack.Namespace = mockNamespace
@@ -1428,20 +1730,16 @@ func TestCfnController_ReconcileStack(t *testing.T) {
 				}
 			},
 			markStackAsInProgress: false,
-			mockS3ClientCalls:     mockS3ClientUpload,
 			mockCfnClientCalls: func(cfnClient *clientmocks.MockCloudFormationClient) {
-				expectedDescribeStackIn := generateStackInput(mockGenerationId, mockSourceRevision2, "")
-				cfnClient.EXPECT().DescribeStack(expectedDescribeStackIn).Return(&clienttypes.StackDescription{
+				cfnClient.EXPECT().DescribeStack(gomock.Any(), newRevisionStackInput).Return(&clienttypes.StackDescription{
 					StackName:         aws.String(mockRealStackName),
 					StackStatus:       expectedStackStatus,
 					StackStatusReason: aws.String("hello world"),
 				}, nil)
 
-				expectedDescribeChangeSetIn := generateStackInput(mockGenerationId, mockSourceRevision2, "")
-				cfnClient.EXPECT().DescribeChangeSet(expectedDescribeChangeSetIn).Return(nil, &cloudformation.ErrChangeSetNotFound{})
+				cfnClient.EXPECT().DescribeChangeSet(gomock.Any(), newRevisionStackInput).Return(nil, &cloudformation.ErrChangeSetNotFound{})
 
-				expectedUpdateStackIn := generateStackInputWithTemplateUrl(mockGenerationId, mockSourceRevision2)
-				cfnClient.EXPECT().UpdateStack(expectedUpdateStackIn).Return(mockChangeSetArnNewSourceRevision, nil)
+				cfnClient.EXPECT().UpdateStack(gomock.Any(), newRevisionStackInput).Return(mockChangeSetArnNewSourceRevision, nil)
 			},
 		}
 
@@ -1462,7 +1760,7 @@ func TestCfnController_ReconcileStack(t *testing.T) {
 			message:   fmt.Sprintf("Update of stack 'mock-real-stack' in progress (change set %s)", mockChangeSetArnNewSourceRevision),
 		})
 
-		testCases[fmt.Sprintf("update the real stack if the real stack has %s status and the desired change set does not exist due to a new source revision", expectedStackStatus)] = changeSetDoesNotExistNewSourceRevisionTC
+		testCases[fmt.Sprintf("update the real stack if the real stack has %s status and the desired change set does not exist due to a new source revision that changes the template", expectedStackStatus)] = changeSetDoesNotExistNewSourceRevisionTC
 	}
 
 	for name, tc := range testCases {
@@ -1482,10 +1780,11 @@ func TestCfnController_ReconcileSource(t *testing.T) {
 			}},
 			wantedRequeueDelay: mockPollIntervalDuration,
 			wantedStackStatus: &cfnv1.CloudFormationStackStatus{
-				ObservedGeneration:     mockGenerationId,
-				StackName:              mockRealStackName,
-				LastAttemptedRevision:  mockSourceRevision,
-				LastAttemptedChangeSet: mockChangeSetArn,
+				ObservedGeneration:        mockGenerationId,
+				StackName:                 mockRealStackName,
+				LastAttemptedRevision:     mockSourceRevision,
+				LastAttemptedChangeSet:    mockChangeSetArn,
+				LastAttemptedSourceDigest: mockSourceDigest,
 				Conditions: []metav1.Condition{
 					{
 						Type:               "Ready",
@@ -1494,27 +1793,33 @@ func TestCfnController_ReconcileSource(t *testing.T) {
 						Reason:             "Progressing",
 						Message:            fmt.Sprintf("Creation of stack 'mock-real-stack' in progress (change set %s)", mockChangeSetArn),
 					},
+					{
+						Type:               "Reconciling",
+						Status:             "True",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "Progressing",
+						Message:            fmt.Sprintf("Creation of stack 'mock-real-stack' in progress (change set %s)", mockChangeSetArn),
+					},
 				},
 			},
 			markStackAsInProgress: true,
 			fillInBucket:          generateMockBucketSource,
-			mockS3ClientCalls:     mockS3ClientUpload,
 			fillInInitialCfnStack: func(cfnStack *cfnv1.CloudFormationStack) {
 				cfnStack.Name = mockStackName
 				cfnStack.Namespace = mockNamespace
 				cfnStack.Generation = mockGenerationId
 				cfnStack.Spec = generateMockCfnStackSpec()
-				cfnStack.Spec.SourceRef = mockBucketSourceRef
+				cfnStack.Spec.SourceRef = &mockBucketSourceRef
 			},
 			mockCfnClientCalls: func(cfnClient *clientmocks.MockCloudFormationClient) {
-				expectedDescribeStackIn := generateStackInput(mockGenerationId, mockSourceRevision, "")
-				cfnClient.EXPECT().DescribeStack(expectedDescribeStackIn).Return(nil, &cloudformation.ErrStackNotFound{})
+				expectedDescribeStackIn := generateStackInput(mockGenerationId, "")
+				cfnClient.EXPECT().DescribeStack(gomock.Any(), expectedDescribeStackIn).Return(nil, &cloudformation.ErrStackNotFound{})
 
-				expectedDescribeChangeSetIn := generateStackInput(mockGenerationId, mockSourceRevision, "")
-				cfnClient.EXPECT().DescribeChangeSet(expectedDescribeChangeSetIn).Return(nil, &cloudformation.ErrChangeSetNotFound{})
+				expectedDescribeChangeSetIn := generateStackInput(mockGenerationId, "")
+				cfnClient.EXPECT().DescribeChangeSet(gomock.Any(), expectedDescribeChangeSetIn).Return(nil, &cloudformation.ErrChangeSetNotFound{})
 
-				expectedCreateStackIn := generateStackInputWithTemplateUrl(mockGenerationId, mockSourceRevision)
-				cfnClient.EXPECT().CreateStack(expectedCreateStackIn).Return(mockChangeSetArn, nil)
+				expectedCreateStackIn := generateStackInput(mockGenerationId, "")
+				cfnClient.EXPECT().CreateStack(gomock.Any(), expectedCreateStackIn).Return(mockChangeSetArn, nil)
 			},
 		},
 		"use an OCI Repository source": {
@@ -1525,10 +1830,11 @@ func TestCfnController_ReconcileSource(t *testing.T) {
 			}},
 			wantedRequeueDelay: mockPollIntervalDuration,
 			wantedStackStatus: &cfnv1.CloudFormationStackStatus{
-				ObservedGeneration:     mockGenerationId,
-				StackName:              mockRealStackName,
-				LastAttemptedRevision:  mockSourceRevision,
-				LastAttemptedChangeSet: mockChangeSetArn,
+				ObservedGeneration:        mockGenerationId,
+				StackName:                 mockRealStackName,
+				LastAttemptedRevision:     mockSourceRevision,
+				LastAttemptedChangeSet:    mockChangeSetArn,
+				LastAttemptedSourceDigest: mockSourceDigest,
 				Conditions: []metav1.Condition{
 					{
 						Type:               "Ready",
@@ -1537,27 +1843,33 @@ func TestCfnController_ReconcileSource(t *testing.T) {
 						Reason:             "Progressing",
 						Message:            fmt.Sprintf("Creation of stack 'mock-real-stack' in progress (change set %s)", mockChangeSetArn),
 					},
+					{
+						Type:               "Reconciling",
+						Status:             "True",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "Progressing",
+						Message:            fmt.Sprintf("Creation of stack 'mock-real-stack' in progress (change set %s)", mockChangeSetArn),
+					},
 				},
 			},
 			markStackAsInProgress: true,
 			fillInOCIRepository:   generateMockOCIRepoSource,
-			mockS3ClientCalls:     mockS3ClientUpload,
 			fillInInitialCfnStack: func(cfnStack *cfnv1.CloudFormationStack) {
 				cfnStack.Name = mockStackName
 				cfnStack.Namespace = mockNamespace
 				cfnStack.Generation = mockGenerationId
 				cfnStack.Spec = generateMockCfnStackSpec()
-				cfnStack.Spec.SourceRef = mockOCIRef
+				cfnStack.Spec.SourceRef = &mockOCIRef
 			},
 			mockCfnClientCalls: func(cfnClient *clientmocks.MockCloudFormationClient) {
-				expectedDescribeStackIn := generateStackInput(mockGenerationId, mockSourceRevision, "")
-				cfnClient.EXPECT().DescribeStack(expectedDescribeStackIn).Return(nil, &cloudformation.ErrStackNotFound{})
+				expectedDescribeStackIn := generateStackInput(mockGenerationId, "")
+				cfnClient.EXPECT().DescribeStack(gomock.Any(), expectedDescribeStackIn).Return(nil, &cloudformation.ErrStackNotFound{})
 
-				expectedDescribeChangeSetIn := generateStackInput(mockGenerationId, mockSourceRevision, "")
-				cfnClient.EXPECT().DescribeChangeSet(expectedDescribeChangeSetIn).Return(nil, &cloudformation.ErrChangeSetNotFound{})
+				expectedDescribeChangeSetIn := generateStackInput(mockGenerationId, "")
+				cfnClient.EXPECT().DescribeChangeSet(gomock.Any(), expectedDescribeChangeSetIn).Return(nil, &cloudformation.ErrChangeSetNotFound{})
 
-				expectedCreateStackIn := generateStackInputWithTemplateUrl(mockGenerationId, mockSourceRevision)
-				cfnClient.EXPECT().CreateStack(expectedCreateStackIn).Return(mockChangeSetArn, nil)
+				expectedCreateStackIn := generateStackInput(mockGenerationId, "")
+				cfnClient.EXPECT().CreateStack(gomock.Any(), expectedCreateStackIn).Return(mockChangeSetArn, nil)
 			},
 		},
 		"reject source in another namespace": {
@@ -1574,6 +1886,13 @@ func TestCfnController_ReconcileSource(t *testing.T) {
 						Reason:             "ArtifactFailed",
 						Message:            fmt.Sprintf("Failed to resolve source 'GitRepository/mock-source-namespace/mock-cfn-template-git-repo'"),
 					},
+					{
+						Type:               "Reconciling",
+						Status:             "True",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "ProgressingWithRetry",
+						Message:            fmt.Sprintf("Failed to resolve source 'GitRepository/mock-source-namespace/mock-cfn-template-git-repo'"),
+					},
 				},
 			},
 			markStackAsInProgress: true,
@@ -1582,7 +1901,7 @@ func TestCfnController_ReconcileSource(t *testing.T) {
 				cfnStack.Namespace = mockNamespace
 				cfnStack.Generation = mockGenerationId
 				cfnStack.Spec = generateMockCfnStackSpec()
-				cfnStack.Spec.SourceRef = mockGitRef2
+				cfnStack.Spec.SourceRef = &mockGitRef2
 			},
 		},
 		"git source cannot be found": {
@@ -1598,6 +1917,13 @@ func TestCfnController_ReconcileSource(t *testing.T) {
 						Reason:             "ArtifactFailed",
 						Message:            fmt.Sprintf("Source 'GitRepository/%s/%s' not found", mockSourceNamespace, mockTemplateGitRepoName),
 					},
+					{
+						Type:               "Reconciling",
+						Status:             "True",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "ProgressingWithRetry",
+						Message:            fmt.Sprintf("Source 'GitRepository/%s/%s' not found", mockSourceNamespace, mockTemplateGitRepoName),
+					},
 				},
 			},
 			markStackAsInProgress: true,
@@ -1635,6 +1961,13 @@ func TestCfnController_ReconcileSource(t *testing.T) {
 						Reason:             "ArtifactFailed",
 						Message:            fmt.Sprintf("Source 'Bucket/%s/%s' not found", mockSourceNamespace, mockTemplateSourceBucketName),
 					},
+					{
+						Type:               "Reconciling",
+						Status:             "True",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "ProgressingWithRetry",
+						Message:            fmt.Sprintf("Source 'Bucket/%s/%s' not found", mockSourceNamespace, mockTemplateSourceBucketName),
+					},
 				},
 			},
 			markStackAsInProgress: true,
@@ -1657,7 +1990,7 @@ func TestCfnController_ReconcileSource(t *testing.T) {
 				cfnStack.Namespace = mockNamespace
 				cfnStack.Generation = mockGenerationId
 				cfnStack.Spec = generateMockCfnStackSpec()
-				cfnStack.Spec.SourceRef = mockBucketSourceRef
+				cfnStack.Spec.SourceRef = &mockBucketSourceRef
 			},
 		},
 		"OCI repository source cannot be found": {
@@ -1673,6 +2006,13 @@ func TestCfnController_ReconcileSource(t *testing.T) {
 						Reason:             "ArtifactFailed",
 						Message:            fmt.Sprintf("Source 'OCIRepository/%s/%s' not found", mockSourceNamespace, mockTemplateOCIRepoName),
 					},
+					{
+						Type:               "Reconciling",
+						Status:             "True",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "ProgressingWithRetry",
+						Message:            fmt.Sprintf("Source 'OCIRepository/%s/%s' not found", mockSourceNamespace, mockTemplateOCIRepoName),
+					},
 				},
 			},
 			markStackAsInProgress: true,
@@ -1695,7 +2035,7 @@ func TestCfnController_ReconcileSource(t *testing.T) {
 				cfnStack.Namespace = mockNamespace
 				cfnStack.Generation = mockGenerationId
 				cfnStack.Spec = generateMockCfnStackSpec()
-				cfnStack.Spec.SourceRef = mockOCIRef
+				cfnStack.Spec.SourceRef = &mockOCIRef
 			},
 		},
 		"unsupported source type": {
@@ -1712,6 +2052,13 @@ func TestCfnController_ReconcileSource(t *testing.T) {
 						Reason:             "ArtifactFailed",
 						Message:            fmt.Sprintf("Failed to resolve source 'HelmRepository/%s/%s'", mockSourceNamespace, mockTemplateOCIRepoName),
 					},
+					{
+						Type:               "Reconciling",
+						Status:             "True",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "ProgressingWithRetry",
+						Message:            fmt.Sprintf("Failed to resolve source 'HelmRepository/%s/%s'", mockSourceNamespace, mockTemplateOCIRepoName),
+					},
 				},
 			},
 			markStackAsInProgress: true,
@@ -1720,7 +2067,7 @@ func TestCfnController_ReconcileSource(t *testing.T) {
 				cfnStack.Namespace = mockNamespace
 				cfnStack.Generation = mockGenerationId
 				cfnStack.Spec = generateMockCfnStackSpec()
-				cfnStack.Spec.SourceRef = cfnv1.SourceReference{
+				cfnStack.Spec.SourceRef = &cfnv1.SourceReference{
 					Kind:      sourcev1b2.HelmRepositoryKind,
 					Name:      mockTemplateOCIRepoName,
 					Namespace: mockSourceNamespace,
@@ -1740,6 +2087,13 @@ func TestCfnController_ReconcileSource(t *testing.T) {
 						Reason:             "ArtifactFailed",
 						Message:            "Source 'GitRepository/mock-namespace/mock-cfn-template-git-repo' is not ready, artifact not found",
 					},
+					{
+						Type:               "Reconciling",
+						Status:             "True",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "ProgressingWithRetry",
+						Message:            "Source 'GitRepository/mock-namespace/mock-cfn-template-git-repo' is not ready, artifact not found",
+					},
 				},
 			},
 			markStackAsInProgress: true,
@@ -1770,6 +2124,13 @@ func TestCfnController_ReconcileSource(t *testing.T) {
 						Reason:             "ArtifactFailed",
 						Message:            "Failed to load template 'template.yaml' from source 'GitRepository/mock-namespace/mock-cfn-template-git-repo'",
 					},
+					{
+						Type:               "Reconciling",
+						Status:             "True",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "ProgressingWithRetry",
+						Message:            "Failed to load template 'template.yaml' from source 'GitRepository/mock-namespace/mock-cfn-template-git-repo'",
+					},
 				},
 			},
 			markStackAsInProgress: true,
@@ -1801,6 +2162,13 @@ func TestCfnController_ReconcileSource(t *testing.T) {
 						Reason:             "ArtifactFailed",
 						Message:            "Failed to load template 'template.yaml' from source 'GitRepository/mock-namespace/mock-cfn-template-git-repo'",
 					},
+					{
+						Type:               "Reconciling",
+						Status:             "True",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "ProgressingWithRetry",
+						Message:            "Failed to load template 'template.yaml' from source 'GitRepository/mock-namespace/mock-cfn-template-git-repo'",
+					},
 				},
 			},
 			markStackAsInProgress: true,
@@ -1823,7 +2191,6 @@ func TestCfnController_ReconcileSource(t *testing.T) {
 			},
 		},
 		"specified template file path cannot be found in the artifact": {
-			wantedErr:          fmt.Errorf("unable to read template file 'does-not-exist.yaml' in the artifact temp directory"),
 			wantedRequeueDelay: mockRetryIntervalDuration,
 			wantedStackStatus: &cfnv1.CloudFormationStackStatus{
 				ObservedGeneration:    mockGenerationId,
@@ -1837,11 +2204,18 @@ func TestCfnController_ReconcileSource(t *testing.T) {
 						Reason:             "ArtifactFailed",
 						Message:            "Failed to load template 'does-not-exist.yaml' from source 'GitRepository/mock-namespace/mock-cfn-template-git-repo'",
 					},
-				},
-			},
-			markStackAsInProgress: true,
-			fillInSource:          generateMockGitRepoSource,
-			fillInInitialCfnStack: func(cfnStack *cfnv1.CloudFormationStack) {
+					{
+						Type:               "Stalled",
+						Status:             "True",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "ArtifactFailed",
+						Message:            "Failed to load template 'does-not-exist.yaml' from source 'GitRepository/mock-namespace/mock-cfn-template-git-repo'",
+					},
+				},
+			},
+			markStackAsInProgress: true,
+			fillInSource:          generateMockGitRepoSource,
+			fillInInitialCfnStack: func(cfnStack *cfnv1.CloudFormationStack) {
 				cfnStack.Name = mockStackName
 				cfnStack.Namespace = mockNamespace
 				cfnStack.Generation = mockGenerationId
@@ -1850,7 +2224,6 @@ func TestCfnController_ReconcileSource(t *testing.T) {
 			},
 		},
 		"cannot traverse paths outside of the artifact": {
-			wantedErr:          fmt.Errorf("unable to read template file '../../usr/bin/ls' in the artifact temp directory"),
 			wantedRequeueDelay: mockRetryIntervalDuration,
 			wantedStackStatus: &cfnv1.CloudFormationStackStatus{
 				ObservedGeneration:    mockGenerationId,
@@ -1864,6 +2237,13 @@ func TestCfnController_ReconcileSource(t *testing.T) {
 						Reason:             "ArtifactFailed",
 						Message:            "Failed to load template '../../usr/bin/ls' from source 'GitRepository/mock-namespace/mock-cfn-template-git-repo'",
 					},
+					{
+						Type:               "Stalled",
+						Status:             "True",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "ArtifactFailed",
+						Message:            "Failed to load template '../../usr/bin/ls' from source 'GitRepository/mock-namespace/mock-cfn-template-git-repo'",
+					},
 				},
 			},
 			markStackAsInProgress: true,
@@ -1877,7 +2257,6 @@ func TestCfnController_ReconcileSource(t *testing.T) {
 			},
 		},
 		"cannot provide absolute file paths for the template": {
-			wantedErr:          fmt.Errorf("unable to read template file '/usr/bin/ls' in the artifact temp directory"),
 			wantedRequeueDelay: mockRetryIntervalDuration,
 			wantedStackStatus: &cfnv1.CloudFormationStackStatus{
 				ObservedGeneration:    mockGenerationId,
@@ -1891,6 +2270,13 @@ func TestCfnController_ReconcileSource(t *testing.T) {
 						Reason:             "ArtifactFailed",
 						Message:            "Failed to load template '/usr/bin/ls' from source 'GitRepository/mock-namespace/mock-cfn-template-git-repo'",
 					},
+					{
+						Type:               "Stalled",
+						Status:             "True",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "ArtifactFailed",
+						Message:            "Failed to load template '/usr/bin/ls' from source 'GitRepository/mock-namespace/mock-cfn-template-git-repo'",
+					},
 				},
 			},
 			markStackAsInProgress: true,
@@ -1904,7 +2290,6 @@ func TestCfnController_ReconcileSource(t *testing.T) {
 			},
 		},
 		"cannot provide directories for the template": {
-			wantedErr:          fmt.Errorf("unable to read template file './' in the artifact temp directory"),
 			wantedRequeueDelay: mockRetryIntervalDuration,
 			wantedStackStatus: &cfnv1.CloudFormationStackStatus{
 				ObservedGeneration:    mockGenerationId,
@@ -1918,6 +2303,13 @@ func TestCfnController_ReconcileSource(t *testing.T) {
 						Reason:             "ArtifactFailed",
 						Message:            "Failed to load template './' from source 'GitRepository/mock-namespace/mock-cfn-template-git-repo'",
 					},
+					{
+						Type:               "Stalled",
+						Status:             "True",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "ArtifactFailed",
+						Message:            "Failed to load template './' from source 'GitRepository/mock-namespace/mock-cfn-template-git-repo'",
+					},
 				},
 			},
 			markStackAsInProgress: true,
@@ -1944,12 +2336,15 @@ func TestCfnController_ReconcileChangeSet(t *testing.T) {
 		"mark the stack as ready if the change set is empty": {
 			wantedRequeueDelay: mockIntervalDuration,
 			wantedStackStatus: &cfnv1.CloudFormationStackStatus{
-				ObservedGeneration:     mockGenerationId2,
-				StackName:              mockRealStackName,
-				LastAttemptedRevision:  mockSourceRevision2,
-				LastAppliedRevision:    mockSourceRevision2,
-				LastAttemptedChangeSet: mockChangeSetArnNewSourceRevisionAndNewGeneration,
-				LastAppliedChangeSet:   mockChangeSetArnNewSourceRevisionAndNewGeneration,
+				ObservedGeneration:         mockGenerationId2,
+				StackName:                  mockRealStackName,
+				LastAttemptedRevision:      mockSourceRevision2,
+				LastAttemptedSourceDigest:  mockSourceDigest,
+				LastAppliedRevision:        mockSourceRevision2,
+				LastAttemptedChangeSet:     mockChangeSetArnNewGeneration,
+				LastAppliedChangeSet:       mockChangeSetArnNewGeneration,
+				LastAppliedConfigDigest:    mockConfigDigest,
+				LastAppliedStackUpdateTime: &mockAppliedStackUpdateTime,
 				Conditions: []metav1.Condition{
 					{
 						Type:               "Ready",
@@ -1971,7 +2366,7 @@ func TestCfnController_ReconcileChangeSet(t *testing.T) {
 					StackName:              mockRealStackName,
 					LastAttemptedRevision:  mockSourceRevision2,
 					LastAppliedRevision:    mockSourceRevision,
-					LastAttemptedChangeSet: mockChangeSetArnNewSourceRevisionAndNewGeneration,
+					LastAttemptedChangeSet: mockChangeSetArnNewGeneration,
 					LastAppliedChangeSet:   mockChangeSetArn,
 					Conditions: []metav1.Condition{
 						{
@@ -1981,33 +2376,44 @@ func TestCfnController_ReconcileChangeSet(t *testing.T) {
 							Reason:             "Hello",
 							Message:            "World",
 						},
+						{
+							Type:               "Reconciling",
+							Status:             "True",
+							ObservedGeneration: mockGenerationId2,
+							Reason:             "Progressing",
+							Message:            "World",
+						},
 					},
 				}
 			},
 			markStackAsInProgress: false,
 			mockCfnClientCalls: func(cfnClient *clientmocks.MockCloudFormationClient) {
-				expectedDescribeStackIn := generateStackInput(mockGenerationId2, mockSourceRevision2, mockChangeSetArnNewSourceRevisionAndNewGeneration)
-				cfnClient.EXPECT().DescribeStack(expectedDescribeStackIn).Return(&clienttypes.StackDescription{
+				expectedDescribeStackIn := generateStackInput(mockGenerationId2, mockChangeSetArnNewGeneration)
+				cfnClient.EXPECT().DescribeStack(gomock.Any(), expectedDescribeStackIn).Return(&clienttypes.StackDescription{
 					StackName:         aws.String(mockRealStackName),
 					StackStatus:       sdktypes.StackStatusCreateComplete,
+					LastUpdatedTime:   aws.Time(mockStackUpdateTime),
 					StackStatusReason: aws.String("hello world"),
 				}, nil)
 
-				expectedDescribeChangeSetIn := generateStackInput(mockGenerationId2, mockSourceRevision2, mockChangeSetArnNewSourceRevisionAndNewGeneration)
-				cfnClient.EXPECT().DescribeChangeSet(expectedDescribeChangeSetIn).Return(nil, &cloudformation.ErrChangeSetEmpty{})
+				expectedDescribeChangeSetIn := generateStackInput(mockGenerationId2, mockChangeSetArnNewGeneration)
+				cfnClient.EXPECT().DescribeChangeSet(gomock.Any(), expectedDescribeChangeSetIn).Return(nil, &cloudformation.ErrChangeSetEmpty{})
 
-				cfnClient.EXPECT().DeleteChangeSet(expectedDescribeChangeSetIn).Return(nil)
+				cfnClient.EXPECT().DeleteChangeSet(gomock.Any(), expectedDescribeChangeSetIn).Return(nil)
 			},
 		},
 		"mark the stack as ready if the change set successfully executed": {
 			wantedRequeueDelay: mockIntervalDuration,
 			wantedStackStatus: &cfnv1.CloudFormationStackStatus{
-				ObservedGeneration:     mockGenerationId2,
-				StackName:              mockRealStackName,
-				LastAttemptedRevision:  mockSourceRevision2,
-				LastAppliedRevision:    mockSourceRevision2,
-				LastAttemptedChangeSet: mockChangeSetArnNewSourceRevisionAndNewGeneration,
-				LastAppliedChangeSet:   mockChangeSetArnNewSourceRevisionAndNewGeneration,
+				ObservedGeneration:         mockGenerationId2,
+				StackName:                  mockRealStackName,
+				LastAttemptedRevision:      mockSourceRevision2,
+				LastAttemptedSourceDigest:  mockSourceDigest,
+				LastAppliedRevision:        mockSourceRevision2,
+				LastAttemptedChangeSet:     mockChangeSetArnNewGeneration,
+				LastAppliedChangeSet:       mockChangeSetArnNewGeneration,
+				LastAppliedConfigDigest:    mockConfigDigest,
+				LastAppliedStackUpdateTime: &mockAppliedStackUpdateTime,
 				Conditions: []metav1.Condition{
 					{
 						Type:               "Ready",
@@ -2029,7 +2435,7 @@ func TestCfnController_ReconcileChangeSet(t *testing.T) {
 					StackName:              mockRealStackName,
 					LastAttemptedRevision:  mockSourceRevision2,
 					LastAppliedRevision:    mockSourceRevision,
-					LastAttemptedChangeSet: mockChangeSetArnNewSourceRevisionAndNewGeneration,
+					LastAttemptedChangeSet: mockChangeSetArnNewGeneration,
 					LastAppliedChangeSet:   mockChangeSetArn,
 					Conditions: []metav1.Condition{
 						{
@@ -2039,21 +2445,29 @@ func TestCfnController_ReconcileChangeSet(t *testing.T) {
 							Reason:             "Hello",
 							Message:            "World",
 						},
+						{
+							Type:               "Reconciling",
+							Status:             "True",
+							ObservedGeneration: mockGenerationId2,
+							Reason:             "Progressing",
+							Message:            "World",
+						},
 					},
 				}
 			},
 			markStackAsInProgress: false,
 			mockCfnClientCalls: func(cfnClient *clientmocks.MockCloudFormationClient) {
-				expectedDescribeStackIn := generateStackInput(mockGenerationId2, mockSourceRevision2, mockChangeSetArnNewSourceRevisionAndNewGeneration)
-				cfnClient.EXPECT().DescribeStack(expectedDescribeStackIn).Return(&clienttypes.StackDescription{
+				expectedDescribeStackIn := generateStackInput(mockGenerationId2, mockChangeSetArnNewGeneration)
+				cfnClient.EXPECT().DescribeStack(gomock.Any(), expectedDescribeStackIn).Return(&clienttypes.StackDescription{
 					StackName:         aws.String(mockRealStackName),
 					StackStatus:       sdktypes.StackStatusCreateComplete,
+					LastUpdatedTime:   aws.Time(mockStackUpdateTime),
 					StackStatusReason: aws.String("hello world"),
 				}, nil)
 
-				expectedDescribeChangeSetIn := generateStackInput(mockGenerationId2, mockSourceRevision2, mockChangeSetArnNewSourceRevisionAndNewGeneration)
-				cfnClient.EXPECT().DescribeChangeSet(expectedDescribeChangeSetIn).Return(&clienttypes.ChangeSetDescription{
-					Arn:             mockChangeSetArnNewSourceRevisionAndNewGeneration,
+				expectedDescribeChangeSetIn := generateStackInput(mockGenerationId2, mockChangeSetArnNewGeneration)
+				cfnClient.EXPECT().DescribeChangeSet(gomock.Any(), expectedDescribeChangeSetIn).Return(&clienttypes.ChangeSetDescription{
+					Arn:             mockChangeSetArnNewGeneration,
 					Status:          sdktypes.ChangeSetStatusCreateComplete,
 					ExecutionStatus: sdktypes.ExecutionStatusExecuteComplete,
 				}, nil)
@@ -2063,23 +2477,31 @@ func TestCfnController_ReconcileChangeSet(t *testing.T) {
 			wantedEvents: []*expectedEvent{{
 				eventType: "Normal",
 				severity:  "info",
-				message:   fmt.Sprintf("Change set execution started for stack 'mock-real-stack' (change set %s)", mockChangeSetArnNewSourceRevisionAndNewGeneration),
+				message:   fmt.Sprintf("Change set execution started for stack 'mock-real-stack' (change set %s)", mockChangeSetArnNewGeneration),
 			}},
 			wantedRequeueDelay: mockPollIntervalDuration,
 			wantedStackStatus: &cfnv1.CloudFormationStackStatus{
-				ObservedGeneration:     mockGenerationId2,
-				StackName:              mockRealStackName,
-				LastAttemptedRevision:  mockSourceRevision2,
-				LastAppliedRevision:    mockSourceRevision,
-				LastAttemptedChangeSet: mockChangeSetArnNewSourceRevisionAndNewGeneration,
-				LastAppliedChangeSet:   mockChangeSetArn,
+				ObservedGeneration:        mockGenerationId2,
+				StackName:                 mockRealStackName,
+				LastAttemptedRevision:     mockSourceRevision2,
+				LastAttemptedSourceDigest: mockSourceDigest,
+				LastAppliedRevision:       mockSourceRevision,
+				LastAttemptedChangeSet:    mockChangeSetArnNewGeneration,
+				LastAppliedChangeSet:      mockChangeSetArn,
 				Conditions: []metav1.Condition{
 					{
 						Type:               "Ready",
 						Status:             "Unknown",
 						ObservedGeneration: mockGenerationId2,
 						Reason:             "Progressing",
-						Message:            fmt.Sprintf("Change set execution started for stack 'mock-real-stack' (change set %s)", mockChangeSetArnNewSourceRevisionAndNewGeneration),
+						Message:            fmt.Sprintf("Change set execution started for stack 'mock-real-stack' (change set %s)", mockChangeSetArnNewGeneration),
+					},
+					{
+						Type:               "Reconciling",
+						Status:             "True",
+						ObservedGeneration: mockGenerationId2,
+						Reason:             "Progressing",
+						Message:            fmt.Sprintf("Change set execution started for stack 'mock-real-stack' (change set %s)", mockChangeSetArnNewGeneration),
 					},
 				},
 			},
@@ -2094,7 +2516,7 @@ func TestCfnController_ReconcileChangeSet(t *testing.T) {
 					StackName:              mockRealStackName,
 					LastAttemptedRevision:  mockSourceRevision2,
 					LastAppliedRevision:    mockSourceRevision,
-					LastAttemptedChangeSet: mockChangeSetArnNewSourceRevisionAndNewGeneration,
+					LastAttemptedChangeSet: mockChangeSetArnNewGeneration,
 					LastAppliedChangeSet:   mockChangeSetArn,
 					Conditions: []metav1.Condition{
 						{
@@ -2104,26 +2526,33 @@ func TestCfnController_ReconcileChangeSet(t *testing.T) {
 							Reason:             "Hello",
 							Message:            "World",
 						},
+						{
+							Type:               "Reconciling",
+							Status:             "True",
+							ObservedGeneration: mockGenerationId2,
+							Reason:             "Progressing",
+							Message:            "World",
+						},
 					},
 				}
 			},
 			markStackAsInProgress: false,
 			mockCfnClientCalls: func(cfnClient *clientmocks.MockCloudFormationClient) {
-				expectedDescribeStackIn := generateStackInput(mockGenerationId2, mockSourceRevision2, mockChangeSetArnNewSourceRevisionAndNewGeneration)
-				cfnClient.EXPECT().DescribeStack(expectedDescribeStackIn).Return(&clienttypes.StackDescription{
+				expectedDescribeStackIn := generateStackInput(mockGenerationId2, mockChangeSetArnNewGeneration)
+				cfnClient.EXPECT().DescribeStack(gomock.Any(), expectedDescribeStackIn).Return(&clienttypes.StackDescription{
 					StackName:         aws.String(mockRealStackName),
 					StackStatus:       sdktypes.StackStatusCreateComplete,
 					StackStatusReason: aws.String("hello world"),
 				}, nil)
 
-				expectedDescribeChangeSetIn := generateStackInput(mockGenerationId2, mockSourceRevision2, mockChangeSetArnNewSourceRevisionAndNewGeneration)
-				cfnClient.EXPECT().DescribeChangeSet(expectedDescribeChangeSetIn).Return(&clienttypes.ChangeSetDescription{
-					Arn:             mockChangeSetArnNewSourceRevisionAndNewGeneration,
+				expectedDescribeChangeSetIn := generateStackInput(mockGenerationId2, mockChangeSetArnNewGeneration)
+				cfnClient.EXPECT().DescribeChangeSet(gomock.Any(), expectedDescribeChangeSetIn).Return(&clienttypes.ChangeSetDescription{
+					Arn:             mockChangeSetArnNewGeneration,
 					Status:          sdktypes.ChangeSetStatusCreateComplete,
 					ExecutionStatus: sdktypes.ExecutionStatusAvailable,
 				}, nil)
 
-				cfnClient.EXPECT().ExecuteChangeSet(expectedDescribeChangeSetIn).Return(nil)
+				cfnClient.EXPECT().ExecuteChangeSet(gomock.Any(), expectedDescribeChangeSetIn).Return(nil)
 			},
 		},
 	}
@@ -2161,10 +2590,11 @@ func TestCfnController_ReconcileChangeSet(t *testing.T) {
 			&reconciliationLoopTestCase{
 				wantedRequeueDelay: mockPollIntervalDuration,
 				wantedStackStatus: &cfnv1.CloudFormationStackStatus{
-					ObservedGeneration:     mockGenerationId,
-					StackName:              mockRealStackName,
-					LastAttemptedRevision:  mockSourceRevision,
-					LastAttemptedChangeSet: mockChangeSetArn,
+					ObservedGeneration:        mockGenerationId,
+					StackName:                 mockRealStackName,
+					LastAttemptedRevision:     mockSourceRevision,
+					LastAttemptedSourceDigest: mockSourceDigest,
+					LastAttemptedChangeSet:    mockChangeSetArn,
 					Conditions: []metav1.Condition{
 						{
 							Type:               "Ready",
@@ -2173,6 +2603,13 @@ func TestCfnController_ReconcileChangeSet(t *testing.T) {
 							Reason:             "Progressing",
 							Message:            fmt.Sprintf("Change set is in progress for stack 'mock-real-stack': status '%s', execution status '%s', reason ''", expectedChangeSetStatus.status, expectedChangeSetStatus.executionStatus),
 						},
+						{
+							Type:               "Reconciling",
+							Status:             "True",
+							ObservedGeneration: mockGenerationId,
+							Reason:             "Progressing",
+							Message:            fmt.Sprintf("Change set is in progress for stack 'mock-real-stack': status '%s', execution status '%s', reason ''", expectedChangeSetStatus.status, expectedChangeSetStatus.executionStatus),
+						},
 					},
 				},
 				fillInSource: generateMockGitRepoSource,
@@ -2194,19 +2631,26 @@ func TestCfnController_ReconcileChangeSet(t *testing.T) {
 								Reason:             "Progressing",
 								Message:            "Hello world",
 							},
+							{
+								Type:               "Reconciling",
+								Status:             "True",
+								ObservedGeneration: mockGenerationId,
+								Reason:             "Progressing",
+								Message:            "Hello world",
+							},
 						},
 					}
 				},
 				mockCfnClientCalls: func(cfnClient *clientmocks.MockCloudFormationClient) {
-					expectedDescribeStackIn := generateStackInput(mockGenerationId, mockSourceRevision, mockChangeSetArn)
-					cfnClient.EXPECT().DescribeStack(expectedDescribeStackIn).Return(&clienttypes.StackDescription{
+					expectedDescribeStackIn := generateStackInput(mockGenerationId, mockChangeSetArn)
+					cfnClient.EXPECT().DescribeStack(gomock.Any(), expectedDescribeStackIn).Return(&clienttypes.StackDescription{
 						StackName:         aws.String(mockRealStackName),
 						StackStatus:       sdktypes.StackStatusCreateComplete,
 						StackStatusReason: aws.String("hello world"),
 					}, nil)
 
-					expectedDescribeChangeSetIn := generateStackInput(mockGenerationId, mockSourceRevision, mockChangeSetArn)
-					cfnClient.EXPECT().DescribeChangeSet(expectedDescribeChangeSetIn).Return(&clienttypes.ChangeSetDescription{
+					expectedDescribeChangeSetIn := generateStackInput(mockGenerationId, mockChangeSetArn)
+					cfnClient.EXPECT().DescribeChangeSet(gomock.Any(), expectedDescribeChangeSetIn).Return(&clienttypes.ChangeSetDescription{
 						Arn:             mockChangeSetArn,
 						Status:          expectedChangeSetStatus.status,
 						ExecutionStatus: expectedChangeSetStatus.executionStatus,
@@ -2247,10 +2691,11 @@ func TestCfnController_ReconcileChangeSet(t *testing.T) {
 				}},
 				wantedRequeueDelay: mockRetryIntervalDuration,
 				wantedStackStatus: &cfnv1.CloudFormationStackStatus{
-					ObservedGeneration:     mockGenerationId,
-					StackName:              mockRealStackName,
-					LastAttemptedRevision:  mockSourceRevision,
-					LastAttemptedChangeSet: mockChangeSetArn,
+					ObservedGeneration:        mockGenerationId,
+					StackName:                 mockRealStackName,
+					LastAttemptedRevision:     mockSourceRevision,
+					LastAttemptedSourceDigest: mockSourceDigest,
+					LastAttemptedChangeSet:    mockChangeSetArn,
 					Conditions: []metav1.Condition{
 						{
 							Type:               "Ready",
@@ -2259,6 +2704,13 @@ func TestCfnController_ReconcileChangeSet(t *testing.T) {
 							Reason:             "ChangeSetFailed",
 							Message:            expectedStatusMessage,
 						},
+						{
+							Type:               "Reconciling",
+							Status:             "True",
+							ObservedGeneration: mockGenerationId,
+							Reason:             "ProgressingWithRetry",
+							Message:            expectedStatusMessage,
+						},
 					},
 				},
 				markStackAsInProgress: false,
@@ -2281,28 +2733,508 @@ func TestCfnController_ReconcileChangeSet(t *testing.T) {
 								Reason:             "Progressing",
 								Message:            "Hello world",
 							},
+							{
+								Type:               "Reconciling",
+								Status:             "True",
+								ObservedGeneration: mockGenerationId,
+								Reason:             "Progressing",
+								Message:            "Hello world",
+							},
 						},
 					}
 				},
 				mockCfnClientCalls: func(cfnClient *clientmocks.MockCloudFormationClient) {
-					expectedDescribeStackIn := generateStackInput(mockGenerationId, mockSourceRevision, mockChangeSetArn)
-					cfnClient.EXPECT().DescribeStack(expectedDescribeStackIn).Return(&clienttypes.StackDescription{
+					expectedDescribeStackIn := generateStackInput(mockGenerationId, mockChangeSetArn)
+					cfnClient.EXPECT().DescribeStack(gomock.Any(), expectedDescribeStackIn).Return(&clienttypes.StackDescription{
 						StackName:         aws.String(mockRealStackName),
 						StackStatus:       sdktypes.StackStatusCreateComplete,
 						StackStatusReason: aws.String("hello world"),
 					}, nil)
 
-					expectedDescribeChangeSetIn := generateStackInput(mockGenerationId, mockSourceRevision, mockChangeSetArn)
-					cfnClient.EXPECT().DescribeChangeSet(expectedDescribeChangeSetIn).Return(&clienttypes.ChangeSetDescription{
+					expectedDescribeChangeSetIn := generateStackInput(mockGenerationId, mockChangeSetArn)
+					cfnClient.EXPECT().DescribeChangeSet(gomock.Any(), expectedDescribeChangeSetIn).Return(&clienttypes.ChangeSetDescription{
 						Arn:             mockChangeSetArn,
 						Status:          expectedChangeSetStatus.status,
 						ExecutionStatus: expectedChangeSetStatus.executionStatus,
 						StatusReason:    "hello world",
 					}, nil)
 
-					cfnClient.EXPECT().DeleteChangeSet(expectedDescribeChangeSetIn).Return(nil)
+					cfnClient.EXPECT().DeleteChangeSet(gomock.Any(), expectedDescribeChangeSetIn).Return(nil)
+				},
+			}
+	}
+
+	// Test cases when the stack did not change since its last applied change set
+	upToDateCfnStack := func(cfnStack *cfnv1.CloudFormationStack) {
+		cfnStack.Name = mockStackName
+		cfnStack.Namespace = mockNamespace
+		cfnStack.Generation = mockGenerationId2
+		cfnStack.Spec = generateMockCfnStackSpec()
+		cfnStack.Status = cfnv1.CloudFormationStackStatus{
+			ObservedGeneration:         mockGenerationId2,
+			StackName:                  mockRealStackName,
+			LastAttemptedRevision:      mockSourceRevision,
+			LastAppliedRevision:        mockSourceRevision,
+			LastAttemptedChangeSet:     mockChangeSetArnNewGeneration,
+			LastAppliedChangeSet:       mockChangeSetArnNewGeneration,
+			LastAppliedConfigDigest:    mockConfigDigest,
+			LastAppliedStackUpdateTime: &mockAppliedStackUpdateTime,
+			Conditions: []metav1.Condition{
+				{
+					Type:               "Ready",
+					Status:             "True",
+					ObservedGeneration: mockGenerationId2,
+					Reason:             "Succeeded",
+					Message:            "Stack reconciliation succeeded",
+				},
+			},
+		}
+	}
+	readyConditions := []metav1.Condition{
+		{
+			Type:               "Ready",
+			Status:             "True",
+			ObservedGeneration: mockGenerationId2,
+			Reason:             "Succeeded",
+			Message:            "Stack reconciliation succeeded",
+		},
+	}
+	// The stack's inputs did not change, so the new change set is named after the applied stack update
+	appliedChangeSetName := generateAppliedChangeSetName(mockGenerationId2)
+	appliedChangeSetArn := generateChangeSetArn(appliedChangeSetName)
+	generateAppliedStackInput := func() *clienttypes.Stack {
+		input := generateStackInput(mockGenerationId2, "")
+		input.ChangeSetName = appliedChangeSetName
+		return input
+	}
+	expectChangeSetCreated := func(cfnClient *clientmocks.MockCloudFormationClient) {
+		cfnClient.EXPECT().DescribeChangeSet(gomock.Any(), generateAppliedStackInput()).Return(nil, &cloudformation.ErrChangeSetNotFound{})
+		cfnClient.EXPECT().UpdateStack(gomock.Any(), generateAppliedStackInput()).Return(appliedChangeSetArn, nil)
+	}
+	changeSetCreatedMsg := fmt.Sprintf("Update of stack 'mock-real-stack' in progress (change set %s)", appliedChangeSetArn)
+	changeSetCreatedEvents := []*expectedEvent{{
+		eventType: "Normal",
+		severity:  "info",
+		message:   changeSetCreatedMsg,
+	}}
+	changeSetCreatedStatus := &cfnv1.CloudFormationStackStatus{
+		ObservedGeneration:         mockGenerationId2,
+		StackName:                  mockRealStackName,
+		LastAttemptedRevision:      mockSourceRevision2,
+		LastAppliedRevision:        mockSourceRevision,
+		LastAttemptedChangeSet:     appliedChangeSetArn,
+		LastAttemptedSourceDigest:  mockSourceDigest,
+		LastAppliedChangeSet:       mockChangeSetArnNewGeneration,
+		LastAppliedConfigDigest:    mockConfigDigest,
+		LastAppliedStackUpdateTime: &mockAppliedStackUpdateTime,
+		Conditions: []metav1.Condition{
+			{
+				Type:               "Ready",
+				Status:             "Unknown",
+				ObservedGeneration: mockGenerationId2,
+				Reason:             "Progressing",
+				Message:            changeSetCreatedMsg,
+			},
+			{
+				Type:               "Reconciling",
+				Status:             "True",
+				ObservedGeneration: mockGenerationId2,
+				Reason:             "Progressing",
+				Message:            changeSetCreatedMsg,
+			},
+		},
+	}
+
+	testCases["skip the change set if the stack did not change since the last applied change set"] = &reconciliationLoopTestCase{
+		wantedRequeueDelay: mockIntervalDuration,
+		wantedStackStatus: &cfnv1.CloudFormationStackStatus{
+			ObservedGeneration:         mockGenerationId2,
+			StackName:                  mockRealStackName,
+			LastAttemptedRevision:      mockSourceRevision2,
+			LastAppliedRevision:        mockSourceRevision2,
+			LastAttemptedChangeSet:     mockChangeSetArnNewGeneration,
+			LastAppliedChangeSet:       mockChangeSetArnNewGeneration,
+			LastAttemptedSourceDigest:  mockSourceDigest,
+			LastAppliedConfigDigest:    mockConfigDigest,
+			LastAppliedStackUpdateTime: &mockAppliedStackUpdateTime,
+			Conditions:                 readyConditions,
+		},
+		fillInSource:          generateMockGitRepoSource2,
+		fillInInitialCfnStack: upToDateCfnStack,
+		mockCfnClientCalls: func(cfnClient *clientmocks.MockCloudFormationClient) {
+			expectedDescribeStackIn := generateAppliedStackInput()
+			cfnClient.EXPECT().DescribeStack(gomock.Any(), expectedDescribeStackIn).Return(&clienttypes.StackDescription{
+				StackName:       aws.String(mockRealStackName),
+				StackStatus:     sdktypes.StackStatusUpdateComplete,
+				LastUpdatedTime: aws.Time(mockStackUpdateTime),
+			}, nil)
+		},
+	}
+	testCases["create a change set if the stack was updated since the last applied change set"] = &reconciliationLoopTestCase{
+		wantedEvents:          changeSetCreatedEvents,
+		wantedRequeueDelay:    mockPollIntervalDuration,
+		wantedStackStatus:     changeSetCreatedStatus,
+		fillInSource:          generateMockGitRepoSource2,
+		fillInInitialCfnStack: upToDateCfnStack,
+		mockCfnClientCalls: func(cfnClient *clientmocks.MockCloudFormationClient) {
+			expectedDescribeStackIn := generateAppliedStackInput()
+			cfnClient.EXPECT().DescribeStack(gomock.Any(), expectedDescribeStackIn).Return(&clienttypes.StackDescription{
+				StackName:       aws.String(mockRealStackName),
+				StackStatus:     sdktypes.StackStatusUpdateComplete,
+				LastUpdatedTime: aws.Time(mockStackUpdateTime.Add(time.Hour)),
+			}, nil)
+			expectChangeSetCreated(cfnClient)
+		},
+	}
+	testCases["create a change set if the stack has the force change set annotation"] = &reconciliationLoopTestCase{
+		wantedEvents:       changeSetCreatedEvents,
+		wantedRequeueDelay: mockPollIntervalDuration,
+		wantedStackStatus:  changeSetCreatedStatus,
+		fillInSource:       generateMockGitRepoSource2,
+		fillInInitialCfnStack: func(cfnStack *cfnv1.CloudFormationStack) {
+			upToDateCfnStack(cfnStack)
+			cfnStack.Annotations = map[string]string{cfnv1.ForceChangeSetAnnotation: "true"}
+		},
+		mockCfnClientCalls: func(cfnClient *clientmocks.MockCloudFormationClient) {
+			expectedDescribeStackIn := generateAppliedStackInput()
+			cfnClient.EXPECT().DescribeStack(gomock.Any(), expectedDescribeStackIn).Return(&clienttypes.StackDescription{
+				StackName:       aws.String(mockRealStackName),
+				StackStatus:     sdktypes.StackStatusUpdateComplete,
+				LastUpdatedTime: aws.Time(mockStackUpdateTime),
+			}, nil)
+			expectChangeSetCreated(cfnClient)
+		},
+	}
+	testCases["create a change set if the stack has the force change set annotation and the same generation and source revision"] = &reconciliationLoopTestCase{
+		wantedEvents:       changeSetCreatedEvents,
+		wantedRequeueDelay: mockPollIntervalDuration,
+		wantedStackStatus: &cfnv1.CloudFormationStackStatus{
+			ObservedGeneration:         mockGenerationId2,
+			StackName:                  mockRealStackName,
+			LastAttemptedRevision:      mockSourceRevision,
+			LastAppliedRevision:        mockSourceRevision,
+			LastAttemptedChangeSet:     appliedChangeSetArn,
+			LastAttemptedSourceDigest:  mockSourceDigest,
+			LastAppliedChangeSet:       mockChangeSetArnNewGeneration,
+			LastAppliedConfigDigest:    mockConfigDigest,
+			LastAppliedStackUpdateTime: &mockAppliedStackUpdateTime,
+			Conditions:                 changeSetCreatedStatus.Conditions,
+		},
+		fillInSource: generateMockGitRepoSource,
+		fillInInitialCfnStack: func(cfnStack *cfnv1.CloudFormationStack) {
+			upToDateCfnStack(cfnStack)
+			cfnStack.Annotations = map[string]string{cfnv1.ForceChangeSetAnnotation: "true"}
+			cfnStack.Status.LastAttemptedSourceDigest = mockSourceDigest
+		},
+		mockCfnClientCalls: func(cfnClient *clientmocks.MockCloudFormationClient) {
+			cfnClient.EXPECT().DescribeStack(gomock.Any(), generateAppliedStackInput()).Return(&clienttypes.StackDescription{
+				StackName:       aws.String(mockRealStackName),
+				StackStatus:     sdktypes.StackStatusUpdateComplete,
+				LastUpdatedTime: aws.Time(mockStackUpdateTime),
+			}, nil)
+			expectChangeSetCreated(cfnClient)
+		},
+	}
+
+	// Test cases when the artifact of the source's new revision cannot be downloaded
+	unavailableArtifactSource := func(gitRepo *sourcev1.GitRepository, mockSourceArtifactURL string) {
+		generateMockGitRepoSource2(gitRepo, mockSourceArtifactURL)
+		gitRepo.Status.Artifact.Digest = fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(mockSourceRevision2)))
+	}
+	fallbackMsg := fmt.Sprintf("Failed to download the artifact of revision '%s', the stack is up-to-date with the last applied revision '%s'", mockSourceRevision2, mockSourceRevision)
+	testCases["mark the stack as not ready if it is up-to-date with the artifact of the last applied revision"] = &reconciliationLoopTestCase{
+		wantedEvents: []*expectedEvent{{
+			eventType: "Warning",
+			severity:  "error",
+			message:   fallbackMsg,
+		}},
+		wantedRequeueDelay: mockRetryIntervalDuration,
+		wantedStackStatus: &cfnv1.CloudFormationStackStatus{
+			ObservedGeneration:         mockGenerationId2,
+			StackName:                  mockRealStackName,
+			LastAttemptedRevision:      mockSourceRevision2,
+			LastAppliedRevision:        mockSourceRevision,
+			LastAttemptedChangeSet:     mockChangeSetArnNewGeneration,
+			LastAppliedChangeSet:       mockChangeSetArnNewGeneration,
+			LastAttemptedSourceDigest:  mockSourceDigest,
+			LastAppliedConfigDigest:    mockConfigDigest,
+			LastAppliedStackUpdateTime: &mockAppliedStackUpdateTime,
+			Conditions: []metav1.Condition{
+				{
+					Type:               "Ready",
+					Status:             "False",
+					ObservedGeneration: mockGenerationId2,
+					Reason:             "ArtifactFailed",
+					Message:            fallbackMsg,
+				},
+				{
+					Type:               "Reconciling",
+					Status:             "True",
+					ObservedGeneration: mockGenerationId2,
+					Reason:             "ProgressingWithRetry",
+					Message:            fallbackMsg,
+				},
+			},
+		},
+		fillInSource:          unavailableArtifactSource,
+		mockArtifactServer:    generateUnavailableArtifactServer,
+		cachedSourceRevision:  mockSourceRevision,
+		fillInInitialCfnStack: upToDateCfnStack,
+		mockCfnClientCalls: func(cfnClient *clientmocks.MockCloudFormationClient) {
+			cfnClient.EXPECT().DescribeStack(gomock.Any(), generateAppliedStackInput()).Return(&clienttypes.StackDescription{
+				StackName:       aws.String(mockRealStackName),
+				StackStatus:     sdktypes.StackStatusUpdateComplete,
+				LastUpdatedTime: aws.Time(mockStackUpdateTime),
+			}, nil)
+		},
+	}
+	artifactFailedMsg := "Failed to load template 'template.yaml' from source 'GitRepository/mock-namespace/mock-cfn-template-git-repo'"
+	testCases["do not deploy an earlier cached artifact of the source to a stack that was never applied"] = &reconciliationLoopTestCase{
+		wantedErr:          fmt.Errorf("failed to download artifact, status code: 404 Not Found"),
+		wantedRequeueDelay: mockRetryIntervalDuration,
+		wantedStackStatus: &cfnv1.CloudFormationStackStatus{
+			ObservedGeneration:    mockGenerationId,
+			StackName:             mockRealStackName,
+			LastAttemptedRevision: mockSourceRevision2,
+			Conditions: []metav1.Condition{
+				{
+					Type:               "Ready",
+					Status:             "False",
+					ObservedGeneration: mockGenerationId,
+					Reason:             "ArtifactFailed",
+					Message:            artifactFailedMsg,
+				},
+				{
+					Type:               "Reconciling",
+					Status:             "True",
+					ObservedGeneration: mockGenerationId,
+					Reason:             "ProgressingWithRetry",
+					Message:            artifactFailedMsg,
 				},
+			},
+		},
+		markStackAsInProgress: true,
+		fillInSource:          unavailableArtifactSource,
+		mockArtifactServer:    generateUnavailableArtifactServer,
+		cachedSourceRevision:  mockSourceRevision,
+		fillInInitialCfnStack: func(cfnStack *cfnv1.CloudFormationStack) {
+			cfnStack.Name = mockStackName
+			cfnStack.Namespace = mockNamespace
+			cfnStack.Generation = mockGenerationId
+			cfnStack.Spec = generateMockCfnStackSpec()
+		},
+	}
+
+	// Test case when a new source revision does not change the stack's source contents
+	sourceUnchangedMsg := fmt.Sprintf("Change set is in progress for stack 'mock-real-stack': status '%s', execution status '%s', reason ''", sdktypes.ChangeSetStatusCreateInProgress, sdktypes.ExecutionStatusUnavailable)
+	sourceUnchangedConditions := []metav1.Condition{
+		{
+			Type:               "Ready",
+			Status:             "Unknown",
+			ObservedGeneration: mockGenerationId,
+			Reason:             "Progressing",
+			Message:            sourceUnchangedMsg,
+		},
+		{
+			Type:               "Reconciling",
+			Status:             "True",
+			ObservedGeneration: mockGenerationId,
+			Reason:             "Progressing",
+			Message:            sourceUnchangedMsg,
+		},
+	}
+	testCases["keep the change set if the new source revision does not change the stack's source contents"] = &reconciliationLoopTestCase{
+		wantedRequeueDelay: mockPollIntervalDuration,
+		wantedStackStatus: &cfnv1.CloudFormationStackStatus{
+			ObservedGeneration:        mockGenerationId,
+			StackName:                 mockRealStackName,
+			LastAttemptedRevision:     mockSourceRevision2,
+			LastAttemptedChangeSet:    mockChangeSetArn,
+			LastAttemptedSourceDigest: mockSourceDigest,
+			Conditions:                sourceUnchangedConditions,
+		},
+		fillInSource: generateMockGitRepoSource2,
+		fillInInitialCfnStack: func(cfnStack *cfnv1.CloudFormationStack) {
+			cfnStack.Name = mockStackName
+			cfnStack.Namespace = mockNamespace
+			cfnStack.Generation = mockGenerationId
+			cfnStack.Spec = generateMockCfnStackSpec()
+			cfnStack.Status = cfnv1.CloudFormationStackStatus{
+				ObservedGeneration:        mockGenerationId,
+				StackName:                 mockRealStackName,
+				LastAttemptedRevision:     mockSourceRevision,
+				LastAttemptedChangeSet:    mockChangeSetArn,
+				LastAttemptedSourceDigest: mockSourceDigest,
+				Conditions:                sourceUnchangedConditions,
 			}
+		},
+		mockCfnClientCalls: func(cfnClient *clientmocks.MockCloudFormationClient) {
+			expectedIn := generateStackInput(mockGenerationId, mockChangeSetArn)
+			cfnClient.EXPECT().DescribeStack(gomock.Any(), expectedIn).Return(&clienttypes.StackDescription{
+				StackName:   aws.String(mockRealStackName),
+				StackStatus: sdktypes.StackStatusCreateComplete,
+			}, nil)
+			cfnClient.EXPECT().DescribeChangeSet(gomock.Any(), expectedIn).Return(&clienttypes.ChangeSetDescription{
+				Arn:             mockChangeSetArn,
+				Status:          sdktypes.ChangeSetStatusCreateInProgress,
+				ExecutionStatus: sdktypes.ExecutionStatusUnavailable,
+			}, nil)
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			runReconciliationLoopTestCase(t, tc)
+		})
+	}
+}
+
+func TestCfnController_ReconcileStackCache(t *testing.T) {
+	readyCfnStack := func(cfnStack *cfnv1.CloudFormationStack) {
+		cfnStack.Name = mockStackName
+		cfnStack.Namespace = mockNamespace
+		cfnStack.Generation = mockGenerationId2
+		cfnStack.Spec = generateMockCfnStackSpec()
+		cfnStack.Status = cfnv1.CloudFormationStackStatus{
+			ObservedGeneration:     mockGenerationId2,
+			StackName:              mockRealStackName,
+			LastAttemptedRevision:  mockSourceRevision2,
+			LastAppliedRevision:    mockSourceRevision2,
+			LastAttemptedChangeSet: mockChangeSetArnNewGeneration,
+			LastAppliedChangeSet:   mockChangeSetArnNewGeneration,
+			Conditions: []metav1.Condition{
+				{
+					Type:               "Ready",
+					Status:             "True",
+					ObservedGeneration: mockGenerationId2,
+					Reason:             "Succeeded",
+					Message:            "Stack reconciliation succeeded",
+				},
+			},
+		}
+	}
+	readyStackStatus := &cfnv1.CloudFormationStackStatus{
+		ObservedGeneration:         mockGenerationId2,
+		StackName:                  mockRealStackName,
+		LastAttemptedRevision:      mockSourceRevision2,
+		LastAppliedRevision:        mockSourceRevision2,
+		LastAttemptedChangeSet:     mockChangeSetArnNewGeneration,
+		LastAppliedChangeSet:       mockChangeSetArnNewGeneration,
+		LastAppliedConfigDigest:    mockConfigDigest,
+		LastAttemptedSourceDigest:  mockSourceDigest,
+		LastAppliedStackUpdateTime: &mockAppliedStackUpdateTime,
+		Conditions: []metav1.Condition{
+			{
+				Type:               "Ready",
+				Status:             "True",
+				ObservedGeneration: mockGenerationId2,
+				Reason:             "Succeeded",
+				Message:            "Stack reconciliation succeeded",
+			},
+		},
+	}
+	expectExecutedChangeSet := func(cfnClient *clientmocks.MockCloudFormationClient) {
+		expectedDescribeChangeSetIn := generateStackInput(mockGenerationId2, mockChangeSetArnNewGeneration)
+		cfnClient.EXPECT().DescribeChangeSet(gomock.Any(), expectedDescribeChangeSetIn).Return(&clienttypes.ChangeSetDescription{
+			Arn:             mockChangeSetArnNewGeneration,
+			Status:          sdktypes.ChangeSetStatusCreateComplete,
+			ExecutionStatus: sdktypes.ExecutionStatusExecuteComplete,
+		}, nil)
+	}
+
+	testCases := map[string]*reconciliationLoopTestCase{
+		"describe the stack of a ready stack object from the stack cache": {
+			wantedRequeueDelay:    mockIntervalDuration,
+			wantedStackStatus:     readyStackStatus,
+			fillInSource:          generateMockGitRepoSource2,
+			fillInInitialCfnStack: readyCfnStack,
+			mockStackCacheCalls: func(stackCache *clientmocks.MockCloudFormationStackCache) {
+				expectedDescribeStackIn := generateStackInput(mockGenerationId2, mockChangeSetArnNewGeneration)
+				stackCache.EXPECT().DescribeStack(gomock.Any(), expectedDescribeStackIn).Return(&clienttypes.StackDescription{
+					StackName:       aws.String(mockRealStackName),
+					StackStatus:     sdktypes.StackStatusUpdateComplete,
+					LastUpdatedTime: aws.Time(mockStackUpdateTime),
+				}, nil)
+			},
+			mockCfnClientCalls: expectExecutedChangeSet,
+		},
+		"describe the stack directly if the cached stack is in progress": {
+			wantedRequeueDelay:    mockIntervalDuration,
+			wantedStackStatus:     readyStackStatus,
+			fillInSource:          generateMockGitRepoSource2,
+			fillInInitialCfnStack: readyCfnStack,
+			mockStackCacheCalls: func(stackCache *clientmocks.MockCloudFormationStackCache) {
+				expectedDescribeStackIn := generateStackInput(mockGenerationId2, mockChangeSetArnNewGeneration)
+				stackCache.EXPECT().DescribeStack(gomock.Any(), expectedDescribeStackIn).Return(&clienttypes.StackDescription{
+					StackName:   aws.String(mockRealStackName),
+					StackStatus: sdktypes.StackStatusUpdateInProgress,
+				}, nil)
+				stackCache.EXPECT().StoreStack(expectedDescribeStackIn, &clienttypes.StackDescription{
+					StackName:       aws.String(mockRealStackName),
+					StackStatus:     sdktypes.StackStatusUpdateComplete,
+					LastUpdatedTime: aws.Time(mockStackUpdateTime),
+				})
+			},
+			mockCfnClientCalls: func(cfnClient *clientmocks.MockCloudFormationClient) {
+				expectedDescribeStackIn := generateStackInput(mockGenerationId2, mockChangeSetArnNewGeneration)
+				cfnClient.EXPECT().DescribeStack(gomock.Any(), expectedDescribeStackIn).Return(&clienttypes.StackDescription{
+					StackName:       aws.String(mockRealStackName),
+					StackStatus:     sdktypes.StackStatusUpdateComplete,
+					LastUpdatedTime: aws.Time(mockStackUpdateTime),
+				}, nil)
+				expectExecutedChangeSet(cfnClient)
+			},
+		},
+		"describe the stack directly if the cached stack is not found": {
+			wantedRequeueDelay:    mockIntervalDuration,
+			wantedStackStatus:     readyStackStatus,
+			fillInSource:          generateMockGitRepoSource2,
+			fillInInitialCfnStack: readyCfnStack,
+			mockStackCacheCalls: func(stackCache *clientmocks.MockCloudFormationStackCache) {
+				expectedDescribeStackIn := generateStackInput(mockGenerationId2, mockChangeSetArnNewGeneration)
+				stackCache.EXPECT().DescribeStack(gomock.Any(), expectedDescribeStackIn).Return(nil, &cloudformation.ErrStackNotFound{})
+				stackCache.EXPECT().StoreStack(expectedDescribeStackIn, gomock.Any())
+			},
+			mockCfnClientCalls: func(cfnClient *clientmocks.MockCloudFormationClient) {
+				expectedDescribeStackIn := generateStackInput(mockGenerationId2, mockChangeSetArnNewGeneration)
+				cfnClient.EXPECT().DescribeStack(gomock.Any(), expectedDescribeStackIn).Return(&clienttypes.StackDescription{
+					StackName:       aws.String(mockRealStackName),
+					StackStatus:     sdktypes.StackStatusUpdateComplete,
+					LastUpdatedTime: aws.Time(mockStackUpdateTime),
+				}, nil)
+				expectExecutedChangeSet(cfnClient)
+			},
+		},
+		"describe the stack of a progressing stack object directly": {
+			wantedRequeueDelay: mockIntervalDuration,
+			wantedStackStatus:  readyStackStatus,
+			fillInSource:       generateMockGitRepoSource2,
+			fillInInitialCfnStack: func(cfnStack *cfnv1.CloudFormationStack) {
+				readyCfnStack(cfnStack)
+				cfnStack.Status.Conditions = []metav1.Condition{
+					{
+						Type:               "Ready",
+						Status:             "Unknown",
+						ObservedGeneration: mockGenerationId2,
+						Reason:             "Progressing",
+						Message:            "Hello world",
+					},
+				}
+			},
+			mockStackCacheCalls: func(stackCache *clientmocks.MockCloudFormationStackCache) {
+				expectedDescribeStackIn := generateStackInput(mockGenerationId2, mockChangeSetArnNewGeneration)
+				stackCache.EXPECT().StoreStack(expectedDescribeStackIn, gomock.Any())
+			},
+			mockCfnClientCalls: func(cfnClient *clientmocks.MockCloudFormationClient) {
+				expectedDescribeStackIn := generateStackInput(mockGenerationId2, mockChangeSetArnNewGeneration)
+				cfnClient.EXPECT().DescribeStack(gomock.Any(), expectedDescribeStackIn).Return(&clienttypes.StackDescription{
+					StackName:       aws.String(mockRealStackName),
+					StackStatus:     sdktypes.StackStatusUpdateComplete,
+					LastUpdatedTime: aws.Time(mockStackUpdateTime),
+				}, nil)
+				expectExecutedChangeSet(cfnClient)
+			},
+		},
 	}
 
 	for name, tc := range testCases {
@@ -2357,9 +3289,9 @@ func TestCfnController_ReconcileDelete(t *testing.T) {
 				}
 			},
 			mockCfnClientCalls: func(cfnClient *clientmocks.MockCloudFormationClient) {
-				expectedDescribeStackIn := generateStackInput(mockGenerationId, mockSourceRevision, mockChangeSetArn)
+				expectedDescribeStackIn := generateStackInput(mockGenerationId, mockChangeSetArn)
 				expectedDescribeStackIn.StackConfig = nil
-				cfnClient.EXPECT().DescribeStack(expectedDescribeStackIn).Return(nil, &cloudformation.ErrStackNotFound{})
+				cfnClient.EXPECT().DescribeStack(gomock.Any(), expectedDescribeStackIn).Return(nil, &cloudformation.ErrStackNotFound{})
 			},
 		},
 		"skip deleting the real stack if the stack does not specify destroying the stack on deletion": {
@@ -2449,6 +3381,100 @@ func TestCfnController_ReconcileDelete(t *testing.T) {
 		},
 	}
 
+	// Test cases for stacks marked for deletion while they are only planned
+	appliedCfnStack := func(mode string) func(cfnStack *cfnv1.CloudFormationStack) {
+		return func(cfnStack *cfnv1.CloudFormationStack) {
+			cfnStack.Name = mockStackName
+			cfnStack.Namespace = mockNamespace
+			cfnStack.Generation = mockGenerationId
+			cfnStack.ObjectMeta.DeletionTimestamp = &deleteTimestamp
+			cfnStack.Spec = generateMockCfnStackSpec()
+			cfnStack.Spec.Mode = mode
+			cfnStack.Spec.DestroyStackOnDeletion = true
+			cfnStack.Status = cfnv1.CloudFormationStackStatus{
+				ObservedGeneration:     mockGenerationId,
+				StackName:              mockRealStackName,
+				LastAttemptedRevision:  mockSourceRevision,
+				LastAppliedRevision:    mockSourceRevision,
+				LastAttemptedChangeSet: mockChangeSetArn,
+				LastAppliedChangeSet:   mockChangeSetArn,
+				Conditions: []metav1.Condition{
+					{
+						Type:               "Ready",
+						Status:             "True",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "Succeeded",
+						Message:            "Stack reconciliation succeeded",
+					},
+				},
+			}
+		}
+	}
+	deletionBlockedStatus := func(msg string) *cfnv1.CloudFormationStackStatus {
+		return &cfnv1.CloudFormationStackStatus{
+			ObservedGeneration:     mockGenerationId,
+			StackName:              mockRealStackName,
+			LastAttemptedRevision:  mockSourceRevision,
+			LastAppliedRevision:    mockSourceRevision,
+			LastAttemptedChangeSet: mockChangeSetArn,
+			LastAppliedChangeSet:   mockChangeSetArn,
+			Conditions: []metav1.Condition{
+				{
+					Type:               "Ready",
+					Status:             "False",
+					ObservedGeneration: mockGenerationId,
+					Reason:             "DeletionBlocked",
+					Message:            msg,
+				},
+				{
+					Type:               "Reconciling",
+					Status:             "True",
+					ObservedGeneration: mockGenerationId,
+					Reason:             "ProgressingWithRetry",
+					Message:            msg,
+				},
+			},
+		}
+	}
+	dryRunBlockedMsg := "Deletion of stack 'mock-real-stack' is blocked until the controller is no longer in dry-run mode: the stack was deployed and must be destroyed on deletion"
+	testCases["keep the finalizer of a previously applied stack marked for deletion in dry-run mode"] = &reconciliationLoopTestCase{
+		wantedEvents: []*expectedEvent{{
+			eventType: "Warning",
+			severity:  "error",
+			message:   dryRunBlockedMsg,
+		}},
+		wantedRequeueDelay:    mockRetryIntervalDuration,
+		wantedStackStatus:     deletionBlockedStatus(dryRunBlockedMsg),
+		dryRun:                true,
+		fillInInitialCfnStack: appliedCfnStack(""),
+	}
+	planBlockedMsg := "Deletion of stack 'mock-real-stack' is blocked until the stack is no longer planned: the stack was deployed and must be destroyed on deletion"
+	testCases["keep the finalizer of a previously applied stack marked for deletion in plan mode"] = &reconciliationLoopTestCase{
+		wantedEvents: []*expectedEvent{{
+			eventType: "Warning",
+			severity:  "error",
+			message:   planBlockedMsg,
+		}},
+		wantedRequeueDelay:    mockRetryIntervalDuration,
+		wantedStackStatus:     deletionBlockedStatus(planBlockedMsg),
+		fillInInitialCfnStack: appliedCfnStack(cfnv1.PlanMode),
+	}
+	testCases["skip deleting the real stack of a stack that was never applied in dry-run mode"] = &reconciliationLoopTestCase{
+		wantedStackStatus: &cfnv1.CloudFormationStackStatus{
+			ObservedGeneration: mockGenerationId,
+			StackName:          mockRealStackName,
+		},
+		removeFinalizers: true,
+		dryRun:           true,
+		fillInInitialCfnStack: func(cfnStack *cfnv1.CloudFormationStack) {
+			appliedCfnStack("")(cfnStack)
+			cfnStack.Status = cfnv1.CloudFormationStackStatus{
+				ObservedGeneration: mockGenerationId,
+				StackName:          mockRealStackName,
+			}
+		},
+	}
+
 	// Test cases when stack is in progress
 	inProgressStackStatuses := []sdktypes.StackStatus{
 		sdktypes.StackStatusCreateInProgress,
@@ -2479,6 +3505,13 @@ func TestCfnController_ReconcileDelete(t *testing.T) {
 							Reason:             "Progressing",
 							Message:            fmt.Sprintf("Stack action is in progress for stack marked for deletion 'mock-real-stack' (status '%s'), waiting for stack action to complete", expectedStackStatus),
 						},
+						{
+							Type:               "Reconciling",
+							Status:             "True",
+							ObservedGeneration: mockGenerationId,
+							Reason:             "Progressing",
+							Message:            fmt.Sprintf("Stack action is in progress for stack marked for deletion 'mock-real-stack' (status '%s'), waiting for stack action to complete", expectedStackStatus),
+						},
 					},
 				},
 				fillInInitialCfnStack: func(cfnStack *cfnv1.CloudFormationStack) {
@@ -2501,13 +3534,20 @@ func TestCfnController_ReconcileDelete(t *testing.T) {
 								Reason:             "Progressing",
 								Message:            "Hello world",
 							},
+							{
+								Type:               "Reconciling",
+								Status:             "True",
+								ObservedGeneration: mockGenerationId,
+								Reason:             "Progressing",
+								Message:            "Hello world",
+							},
 						},
 					}
 				},
 				mockCfnClientCalls: func(cfnClient *clientmocks.MockCloudFormationClient) {
-					expectedDescribeStackIn := generateStackInput(mockGenerationId, mockSourceRevision, mockChangeSetArn)
+					expectedDescribeStackIn := generateStackInput(mockGenerationId, mockChangeSetArn)
 					expectedDescribeStackIn.StackConfig = nil
-					cfnClient.EXPECT().DescribeStack(expectedDescribeStackIn).Return(&clienttypes.StackDescription{
+					cfnClient.EXPECT().DescribeStack(gomock.Any(), expectedDescribeStackIn).Return(&clienttypes.StackDescription{
 						StackName:   aws.String(mockRealStackName),
 						StackStatus: expectedStackStatus,
 					}, nil)
@@ -2553,6 +3593,13 @@ func TestCfnController_ReconcileDelete(t *testing.T) {
 						Reason:             "Progressing",
 						Message:            "Started deletion of stack 'mock-real-stack'",
 					},
+					{
+						Type:               "Reconciling",
+						Status:             "True",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "Progressing",
+						Message:            "Started deletion of stack 'mock-real-stack'",
+					},
 				},
 			},
 			fillInInitialCfnStack: func(cfnStack *cfnv1.CloudFormationStack) {
@@ -2575,17 +3622,24 @@ func TestCfnController_ReconcileDelete(t *testing.T) {
 							Reason:             "Progressing",
 							Message:            "Hello world",
 						},
+						{
+							Type:               "Reconciling",
+							Status:             "True",
+							ObservedGeneration: mockGenerationId,
+							Reason:             "Progressing",
+							Message:            "Hello world",
+						},
 					},
 				}
 			},
 			mockCfnClientCalls: func(cfnClient *clientmocks.MockCloudFormationClient) {
-				expectedDescribeStackIn := generateStackInput(mockGenerationId, mockSourceRevision, mockChangeSetArn)
+				expectedDescribeStackIn := generateStackInput(mockGenerationId, mockChangeSetArn)
 				expectedDescribeStackIn.StackConfig = nil
-				cfnClient.EXPECT().DescribeStack(expectedDescribeStackIn).Return(&clienttypes.StackDescription{
+				cfnClient.EXPECT().DescribeStack(gomock.Any(), expectedDescribeStackIn).Return(&clienttypes.StackDescription{
 					StackName:   aws.String(mockRealStackName),
 					StackStatus: expectedStackStatus,
 				}, nil)
-				cfnClient.EXPECT().DeleteStack(expectedDescribeStackIn).Return(nil)
+				cfnClient.EXPECT().DeleteStack(gomock.Any(), expectedDescribeStackIn).Return(nil)
 			},
 		}
 
@@ -2614,9 +3668,96 @@ func TestCfnController_ReconcileDelete(t *testing.T) {
 	}
 }
 
-func TestCfnController_S3Failures(t *testing.T) {
-	testCases := map[string]*reconciliationLoopTestCase{
-		"mark stack as not ready if template upload to S3 fails": {
+func TestCfnController_LargeTemplates(t *testing.T) {
+	sourceDigest := "sha256:9071e8a8a507d5af693d074c3772960600064e6b8d90df12f4830812f639a237"
+	var largeTemplateBuilder strings.Builder
+	largeTemplateBuilder.WriteString("Resources:\n")
+	for i := 0; largeTemplateBuilder.Len() <= cloudformation.MaxTemplateBodySize; i++ {
+		largeTemplateBuilder.WriteString(fmt.Sprintf("  Topic%d:\n    Type: AWS::SNS::Topic\n", i))
+	}
+	largeTemplate := largeTemplateBuilder.String()
+	largeTemplateKey := fmt.Sprintf("flux-mock-real-stack-%x.template", sha256.Sum256([]byte(largeTemplate)))
+	largeTmpl := &template.Template{Path: mockTemplatePath, Body: largeTemplate}
+
+	artifact, checksum, err := createArtifact(map[string]string{
+		"template.yaml": largeTemplate,
+	})
+	require.NoError(t, err)
+
+	fillInSource := func(gitRepo *sourcev1.GitRepository, mockSourceArtifactURL string) {
+		generateMockGitRepoSource(gitRepo, mockSourceArtifactURL)
+		gitRepo.Status.Artifact.Digest = checksum
+	}
+	generateLargeStackInput := func() *clienttypes.Stack {
+		input := generateStackInput(mockGenerationId, "")
+		input.StackConfig.TemplateBody = largeTemplate
+		setChangeSetName(input, mockGenerationId, largeTmpl)
+		return input
+	}
+
+	testCases := map[string]*reconciliationLoopTestCase{
+		"upload large template to S3 and create stack with template URL": {
+			wantedEvents: []*expectedEvent{{
+				eventType: "Normal",
+				severity:  "info",
+				message:   fmt.Sprintf("Creation of stack 'mock-real-stack' in progress (change set %s)", mockChangeSetArn),
+			}},
+			wantedRequeueDelay: mockPollIntervalDuration,
+			wantedStackStatus: &cfnv1.CloudFormationStackStatus{
+				ObservedGeneration:        mockGenerationId,
+				StackName:                 mockRealStackName,
+				LastAttemptedRevision:     mockSourceRevision,
+				LastAttemptedChangeSet:    mockChangeSetArn,
+				LastAttemptedSourceDigest: sourceDigest,
+				TemplateObjectKeyPrefixes: []string{"flux-mock-real-stack-"},
+				Conditions: []metav1.Condition{
+					{
+						Type:               "Ready",
+						Status:             "Unknown",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "Progressing",
+						Message:            fmt.Sprintf("Creation of stack 'mock-real-stack' in progress (change set %s)", mockChangeSetArn),
+					},
+					{
+						Type:               "Reconciling",
+						Status:             "True",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "Progressing",
+						Message:            fmt.Sprintf("Creation of stack 'mock-real-stack' in progress (change set %s)", mockChangeSetArn),
+					},
+				},
+			},
+			markStackAsInProgress: true,
+			mockArtifactServer: func(t *testing.T) *httptest.Server {
+				return generateArtifactServer(t, artifact)
+			},
+			fillInSource: fillInSource,
+			fillInInitialCfnStack: func(cfnStack *cfnv1.CloudFormationStack) {
+				cfnStack.Name = mockStackName
+				cfnStack.Namespace = mockNamespace
+				cfnStack.Generation = mockGenerationId
+				cfnStack.Spec = generateMockCfnStackSpec()
+			},
+			mockS3ClientCalls: func(s3Client *clientmocks.MockS3Client) {
+				s3Client.EXPECT().HeadTemplate(gomock.Any(), mockTemplateUploadBucket, "", largeTemplateKey, &clienttypes.TemplateBucketOptions{}).Return(nil, &s3.ErrObjectNotFound{})
+				s3Client.EXPECT().UploadTemplate(gomock.Any(),
+					mockTemplateUploadBucket,
+					"",
+					largeTemplateKey,
+					strings.NewReader(largeTemplate),
+					&clienttypes.TemplateBucketOptions{},
+				).Return(mockTemplateS3Url, nil)
+			},
+			mockCfnClientCalls: func(cfnClient *clientmocks.MockCloudFormationClient) {
+				cfnClient.EXPECT().DescribeStack(gomock.Any(), generateLargeStackInput()).Return(nil, &cloudformation.ErrStackNotFound{})
+				cfnClient.EXPECT().DescribeChangeSet(gomock.Any(), generateLargeStackInput()).Return(nil, &cloudformation.ErrChangeSetNotFound{})
+
+				expectedCreateStackIn := generateLargeStackInput()
+				expectedCreateStackIn.StackConfig.TemplateURL = mockTemplateS3Url
+				cfnClient.EXPECT().CreateStack(gomock.Any(), expectedCreateStackIn).Return(mockChangeSetArn, nil)
+			},
+		},
+		"mark stack as not ready if template upload to S3 fails": {
 			wantedErr: errors.New("template upload failed"),
 			wantedEvents: []*expectedEvent{{
 				eventType: "Warning",
@@ -2625,42 +3766,2061 @@ func TestCfnController_S3Failures(t *testing.T) {
 			}},
 			wantedRequeueDelay: mockRetryIntervalDuration,
 			wantedStackStatus: &cfnv1.CloudFormationStackStatus{
-				ObservedGeneration:    mockGenerationId,
-				StackName:             mockRealStackName,
-				LastAttemptedRevision: mockSourceRevision,
+				ObservedGeneration:        mockGenerationId,
+				StackName:                 mockRealStackName,
+				LastAttemptedRevision:     mockSourceRevision,
+				LastAttemptedSourceDigest: sourceDigest,
+				Conditions: []metav1.Condition{
+					{
+						Type:               "Ready",
+						Status:             "False",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "TemplateUploadFailed",
+						Message:            "Failed to upload template to S3 for stack 'mock-real-stack'",
+					},
+					{
+						Type:               "Reconciling",
+						Status:             "True",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "ProgressingWithRetry",
+						Message:            "Failed to upload template to S3 for stack 'mock-real-stack'",
+					},
+				},
+			},
+			markStackAsInProgress: true,
+			mockArtifactServer: func(t *testing.T) *httptest.Server {
+				return generateArtifactServer(t, artifact)
+			},
+			fillInSource: fillInSource,
+			mockS3ClientCalls: func(s3Client *clientmocks.MockS3Client) {
+				s3Client.EXPECT().HeadTemplate(gomock.Any(), mockTemplateUploadBucket, "", largeTemplateKey, &clienttypes.TemplateBucketOptions{}).Return(nil, &s3.ErrObjectNotFound{})
+				s3Client.EXPECT().UploadTemplate(gomock.Any(),
+					mockTemplateUploadBucket,
+					"",
+					largeTemplateKey,
+					strings.NewReader(largeTemplate),
+					&clienttypes.TemplateBucketOptions{},
+				).Return("", errors.New("template upload failed"))
+			},
+			fillInInitialCfnStack: func(cfnStack *cfnv1.CloudFormationStack) {
+				cfnStack.Name = mockStackName
+				cfnStack.Namespace = mockNamespace
+				cfnStack.Generation = mockGenerationId
+				cfnStack.Spec = generateMockCfnStackSpec()
+			},
+			mockCfnClientCalls: func(cfnClient *clientmocks.MockCloudFormationClient) {
+				cfnClient.EXPECT().DescribeStack(gomock.Any(), generateLargeStackInput()).Return(nil, &cloudformation.ErrStackNotFound{})
+				cfnClient.EXPECT().DescribeChangeSet(gomock.Any(), generateLargeStackInput()).Return(nil, &cloudformation.ErrChangeSetNotFound{})
+			},
+		},
+		"mark stack as not ready if template is too large and no template bucket is configured": {
+			wantedEvents: []*expectedEvent{{
+				eventType: "Warning",
+				severity:  "error",
+				message:   "Failed to deploy stack 'mock-real-stack': template 'template.yaml' is larger than 51200 bytes and must be uploaded to S3: no template bucket is configured for the controller",
+			}},
+			wantedRequeueDelay: mockRetryIntervalDuration,
+			wantedStackStatus: &cfnv1.CloudFormationStackStatus{
+				ObservedGeneration:        mockGenerationId,
+				StackName:                 mockRealStackName,
+				LastAttemptedRevision:     mockSourceRevision,
+				LastAttemptedSourceDigest: sourceDigest,
+				Conditions: []metav1.Condition{
+					{
+						Type:               "Ready",
+						Status:             "False",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "TemplateBucketMissing",
+						Message:            "Failed to deploy stack 'mock-real-stack': template 'template.yaml' is larger than 51200 bytes and must be uploaded to S3: no template bucket is configured for the controller",
+					},
+					{
+						Type:               "Stalled",
+						Status:             "True",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "TemplateBucketMissing",
+						Message:            "Failed to deploy stack 'mock-real-stack': template 'template.yaml' is larger than 51200 bytes and must be uploaded to S3: no template bucket is configured for the controller",
+					},
+				},
+			},
+			markStackAsInProgress: true,
+			noTemplateBucket:      true,
+			mockArtifactServer: func(t *testing.T) *httptest.Server {
+				return generateArtifactServer(t, artifact)
+			},
+			fillInSource: fillInSource,
+			fillInInitialCfnStack: func(cfnStack *cfnv1.CloudFormationStack) {
+				cfnStack.Name = mockStackName
+				cfnStack.Namespace = mockNamespace
+				cfnStack.Generation = mockGenerationId
+				cfnStack.Spec = generateMockCfnStackSpec()
+			},
+			mockCfnClientCalls: func(cfnClient *clientmocks.MockCloudFormationClient) {
+				expectedIn := generateLargeStackInput()
+				expectedIn.StackConfig.TemplateBucket = ""
+				setChangeSetName(expectedIn, mockGenerationId, largeTmpl)
+				cfnClient.EXPECT().DescribeStack(gomock.Any(), expectedIn).Return(nil, &cloudformation.ErrStackNotFound{})
+				cfnClient.EXPECT().DescribeChangeSet(gomock.Any(), expectedIn).Return(nil, &cloudformation.ErrChangeSetNotFound{})
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			runReconciliationLoopTestCase(t, tc)
+		})
+	}
+}
+
+func TestCfnController_NestedTemplates(t *testing.T) {
+	rootTemplate := "Resources:\n  Network:\n    Type: AWS::CloudFormation::Stack\n    Properties:\n      TemplateURL: ./nested/network.yaml\n"
+	nestedTemplate := "Resources:\n  Vpc:\n    Type: AWS::EC2::VPC\n"
+	nestedTemplateKey := fmt.Sprintf("flux-mock-real-stack-%x.template", sha256.Sum256([]byte(nestedTemplate)))
+	nestedTemplateUrl := "https://mock-template-upload-bucket.s3.mock-region.amazonaws.com/" + nestedTemplateKey
+	prefixedNestedTemplateUrl := "https://mock-template-upload-bucket.s3.mock-region.amazonaws.com/my-cluster/" + nestedTemplateKey
+	rewrittenRootTemplate := "Resources:\n  Network:\n    Type: AWS::CloudFormation::Stack\n    Properties:\n      TemplateURL: " + nestedTemplateUrl + "\n"
+	nestedConfigDigest := "sha256:c3f47e309b4cd1c3b72b8f360ee4309168e88c55481e790b18b57e66a23a9b9a"
+	nestedSourceDigest := "sha256:b7256eebae69cd5450f5d48d3e42372938963883e2f449ab1db72b3b70e0d2d5"
+	nestedTmpl := &template.Template{
+		Path: mockTemplatePath,
+		Body: rootTemplate,
+		Nested: []*template.Reference{{
+			Location: "./nested/network.yaml",
+			Template: &template.Template{Path: "nested/network.yaml", Body: nestedTemplate},
+		}},
+	}
+	nestedChangeSetArn := generateChangeSetArn(generateChangeSetName(mockGenerationId, nestedSourceDigest, nestedConfigDigest))
+
+	artifact, checksum, err := createArtifact(map[string]string{
+		"template.yaml":       rootTemplate,
+		"nested/network.yaml": nestedTemplate,
+	})
+	require.NoError(t, err)
+
+	fillInSource := func(gitRepo *sourcev1.GitRepository, mockSourceArtifactURL string) {
+		generateMockGitRepoSource(gitRepo, mockSourceArtifactURL)
+		gitRepo.Status.Artifact.Digest = checksum
+	}
+	uploadErr := errors.New("template upload failed")
+	generateNestedStackInput := func() *clienttypes.Stack {
+		input := generateStackInput(mockGenerationId, "")
+		input.StackConfig.TemplateBody = rootTemplate
+		setChangeSetName(input, mockGenerationId, nestedTmpl)
+		return input
+	}
+
+	testCases := map[string]*reconciliationLoopTestCase{
+		"upload nested templates and create stack with rewritten inline template": {
+			wantedEvents: []*expectedEvent{{
+				eventType: "Normal",
+				severity:  "info",
+				message:   fmt.Sprintf("Creation of stack 'mock-real-stack' in progress (change set %s)", mockChangeSetArn),
+			}},
+			wantedRequeueDelay: mockPollIntervalDuration,
+			wantedStackStatus: &cfnv1.CloudFormationStackStatus{
+				ObservedGeneration:        mockGenerationId,
+				StackName:                 mockRealStackName,
+				LastAttemptedRevision:     mockSourceRevision,
+				LastAttemptedChangeSet:    mockChangeSetArn,
+				LastAttemptedSourceDigest: nestedSourceDigest,
+				TemplateObjectKeyPrefixes: []string{"flux-mock-real-stack-"},
+				Conditions: []metav1.Condition{
+					{
+						Type:               "Ready",
+						Status:             "Unknown",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "Progressing",
+						Message:            fmt.Sprintf("Creation of stack 'mock-real-stack' in progress (change set %s)", mockChangeSetArn),
+					},
+					{
+						Type:               "Reconciling",
+						Status:             "True",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "Progressing",
+						Message:            fmt.Sprintf("Creation of stack 'mock-real-stack' in progress (change set %s)", mockChangeSetArn),
+					},
+				},
+			},
+			markStackAsInProgress: true,
+			mockArtifactServer: func(t *testing.T) *httptest.Server {
+				return generateArtifactServer(t, artifact)
+			},
+			fillInSource: fillInSource,
+			fillInInitialCfnStack: func(cfnStack *cfnv1.CloudFormationStack) {
+				cfnStack.Name = mockStackName
+				cfnStack.Namespace = mockNamespace
+				cfnStack.Generation = mockGenerationId
+				cfnStack.Spec = generateMockCfnStackSpec()
+			},
+			mockS3ClientCalls: func(s3Client *clientmocks.MockS3Client) {
+				s3Client.EXPECT().HeadTemplate(gomock.Any(), mockTemplateUploadBucket, "", nestedTemplateKey, &clienttypes.TemplateBucketOptions{}).Return(nil, &s3.ErrObjectNotFound{})
+				s3Client.EXPECT().UploadTemplate(gomock.Any(),
+					mockTemplateUploadBucket,
+					"",
+					nestedTemplateKey,
+					strings.NewReader(nestedTemplate),
+					&clienttypes.TemplateBucketOptions{},
+				).Return(nestedTemplateUrl, nil)
+			},
+			mockCfnClientCalls: func(cfnClient *clientmocks.MockCloudFormationClient) {
+				cfnClient.EXPECT().DescribeStack(gomock.Any(), generateNestedStackInput()).Return(nil, &cloudformation.ErrStackNotFound{})
+				cfnClient.EXPECT().DescribeChangeSet(gomock.Any(), generateNestedStackInput()).Return(nil, &cloudformation.ErrChangeSetNotFound{})
+
+				expectedCreateStackIn := generateNestedStackInput()
+				expectedCreateStackIn.StackConfig.TemplateBody = rewrittenRootTemplate
+				cfnClient.EXPECT().CreateStack(gomock.Any(), expectedCreateStackIn).Return(mockChangeSetArn, nil)
+			},
+		},
+		"upload nested templates with the stack's template upload settings": {
+			wantedEvents: []*expectedEvent{{
+				eventType: "Normal",
+				severity:  "info",
+				message:   fmt.Sprintf("Creation of stack 'mock-real-stack' in progress (change set %s)", mockChangeSetArn),
+			}},
+			wantedRequeueDelay: mockPollIntervalDuration,
+			wantedStackStatus: &cfnv1.CloudFormationStackStatus{
+				ObservedGeneration:        mockGenerationId,
+				StackName:                 mockRealStackName,
+				LastAttemptedRevision:     mockSourceRevision,
+				LastAttemptedChangeSet:    mockChangeSetArn,
+				LastAttemptedSourceDigest: nestedSourceDigest,
+				TemplateObjectKeyPrefixes: []string{"my-cluster/flux-mock-real-stack-"},
+				Conditions: []metav1.Condition{
+					{
+						Type:               "Ready",
+						Status:             "Unknown",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "Progressing",
+						Message:            fmt.Sprintf("Creation of stack 'mock-real-stack' in progress (change set %s)", mockChangeSetArn),
+					},
+					{
+						Type:               "Reconciling",
+						Status:             "True",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "Progressing",
+						Message:            fmt.Sprintf("Creation of stack 'mock-real-stack' in progress (change set %s)", mockChangeSetArn),
+					},
+				},
+			},
+			markStackAsInProgress: true,
+			templateBucketOptions: clienttypes.TemplateBucketOptions{
+				KeyPrefix:  "controller/",
+				KMSKeyID:   "alias/controller-key",
+				ObjectTags: map[string]string{"team": "platform", "env": "dev"},
+				ACL:        "bucket-owner-full-control",
+			},
+			mockArtifactServer: func(t *testing.T) *httptest.Server {
+				return generateArtifactServer(t, artifact)
+			},
+			fillInSource: fillInSource,
+			fillInInitialCfnStack: func(cfnStack *cfnv1.CloudFormationStack) {
+				cfnStack.Name = mockStackName
+				cfnStack.Namespace = mockNamespace
+				cfnStack.Generation = mockGenerationId
+				cfnStack.Spec = generateMockCfnStackSpec()
+				cfnStack.Spec.TemplateUpload = &cfnv1.TemplateUploadSettings{
+					KeyPrefix:           "my-cluster/",
+					ObjectTags:          []cfnv1.TemplateObjectTag{{Key: "env", Value: "prod"}},
+					ACL:                 "none",
+					ExpectedBucketOwner: "210987654321",
+				}
+			},
+			mockS3ClientCalls: func(s3Client *clientmocks.MockS3Client) {
+				expectedOpts := &clienttypes.TemplateBucketOptions{
+					KeyPrefix:           "my-cluster/",
+					KMSKeyID:            "alias/controller-key",
+					ObjectTags:          map[string]string{"team": "platform", "env": "prod"},
+					ACL:                 "none",
+					ExpectedBucketOwner: "210987654321",
+				}
+				s3Client.EXPECT().HeadTemplate(gomock.Any(), mockTemplateUploadBucket, "", "my-cluster/"+nestedTemplateKey, expectedOpts).Return(nil, &s3.ErrObjectNotFound{})
+				s3Client.EXPECT().UploadTemplate(gomock.Any(),
+					mockTemplateUploadBucket,
+					"",
+					"my-cluster/"+nestedTemplateKey,
+					strings.NewReader(nestedTemplate),
+					expectedOpts,
+				).Return(prefixedNestedTemplateUrl, nil)
+			},
+			mockCfnClientCalls: func(cfnClient *clientmocks.MockCloudFormationClient) {
+				expectedIn := generateNestedStackInput()
+				expectedIn.StackConfig.TemplateBucketOptions = clienttypes.TemplateBucketOptions{
+					KeyPrefix:           "my-cluster/",
+					KMSKeyID:            "alias/controller-key",
+					ObjectTags:          map[string]string{"team": "platform", "env": "prod"},
+					ACL:                 "none",
+					ExpectedBucketOwner: "210987654321",
+				}
+				setChangeSetName(expectedIn, mockGenerationId, nestedTmpl)
+				cfnClient.EXPECT().DescribeStack(gomock.Any(), expectedIn).Return(nil, &cloudformation.ErrStackNotFound{})
+				cfnClient.EXPECT().DescribeChangeSet(gomock.Any(), expectedIn).Return(nil, &cloudformation.ErrChangeSetNotFound{})
+
+				expectedCreateStackIn := generateNestedStackInput()
+				expectedCreateStackIn.StackConfig.TemplateBucketOptions = expectedIn.StackConfig.TemplateBucketOptions
+				expectedCreateStackIn.ChangeSetName = expectedIn.ChangeSetName
+				expectedCreateStackIn.StackConfig.TemplateBody = strings.Replace(rewrittenRootTemplate, nestedTemplateUrl, prefixedNestedTemplateUrl, 1)
+				cfnClient.EXPECT().CreateStack(gomock.Any(), expectedCreateStackIn).Return(mockChangeSetArn, nil)
+			},
+		},
+		"skip uploading nested templates that already exist in the template bucket": {
+			wantedEvents: []*expectedEvent{{
+				eventType: "Normal",
+				severity:  "info",
+				message:   fmt.Sprintf("Creation of stack 'mock-real-stack' in progress (change set %s)", mockChangeSetArn),
+			}},
+			wantedRequeueDelay: mockPollIntervalDuration,
+			wantedStackStatus: &cfnv1.CloudFormationStackStatus{
+				ObservedGeneration:        mockGenerationId,
+				StackName:                 mockRealStackName,
+				LastAttemptedRevision:     mockSourceRevision,
+				LastAttemptedChangeSet:    mockChangeSetArn,
+				LastAttemptedSourceDigest: nestedSourceDigest,
+				TemplateObjectKeyPrefixes: []string{"flux-mock-real-stack-"},
+				Conditions: []metav1.Condition{
+					{
+						Type:               "Ready",
+						Status:             "Unknown",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "Progressing",
+						Message:            fmt.Sprintf("Creation of stack 'mock-real-stack' in progress (change set %s)", mockChangeSetArn),
+					},
+					{
+						Type:               "Reconciling",
+						Status:             "True",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "Progressing",
+						Message:            fmt.Sprintf("Creation of stack 'mock-real-stack' in progress (change set %s)", mockChangeSetArn),
+					},
+				},
+			},
+			markStackAsInProgress: true,
+			mockArtifactServer: func(t *testing.T) *httptest.Server {
+				return generateArtifactServer(t, artifact)
+			},
+			fillInSource: fillInSource,
+			fillInInitialCfnStack: func(cfnStack *cfnv1.CloudFormationStack) {
+				cfnStack.Name = mockStackName
+				cfnStack.Namespace = mockNamespace
+				cfnStack.Generation = mockGenerationId
+				cfnStack.Spec = generateMockCfnStackSpec()
+			},
+			mockS3ClientCalls: func(s3Client *clientmocks.MockS3Client) {
+				s3Client.EXPECT().HeadTemplate(gomock.Any(), mockTemplateUploadBucket, "", nestedTemplateKey, &clienttypes.TemplateBucketOptions{}).Return(&clienttypes.TemplateObject{
+					Key:            nestedTemplateKey,
+					URL:            nestedTemplateUrl,
+					MatchesOptions: true,
+				}, nil)
+			},
+			mockCfnClientCalls: func(cfnClient *clientmocks.MockCloudFormationClient) {
+				cfnClient.EXPECT().DescribeStack(gomock.Any(), generateNestedStackInput()).Return(nil, &cloudformation.ErrStackNotFound{})
+				cfnClient.EXPECT().DescribeChangeSet(gomock.Any(), generateNestedStackInput()).Return(nil, &cloudformation.ErrChangeSetNotFound{})
+
+				expectedCreateStackIn := generateNestedStackInput()
+				expectedCreateStackIn.StackConfig.TemplateBody = rewrittenRootTemplate
+				cfnClient.EXPECT().CreateStack(gomock.Any(), expectedCreateStackIn).Return(mockChangeSetArn, nil)
+			},
+		},
+		"upload nested templates again that were uploaded with other options": {
+			wantedEvents: []*expectedEvent{{
+				eventType: "Normal",
+				severity:  "info",
+				message:   fmt.Sprintf("Creation of stack 'mock-real-stack' in progress (change set %s)", mockChangeSetArn),
+			}},
+			wantedRequeueDelay: mockPollIntervalDuration,
+			wantedStackStatus: &cfnv1.CloudFormationStackStatus{
+				ObservedGeneration:        mockGenerationId,
+				StackName:                 mockRealStackName,
+				LastAttemptedRevision:     mockSourceRevision,
+				LastAttemptedChangeSet:    mockChangeSetArn,
+				LastAttemptedSourceDigest: nestedSourceDigest,
+				TemplateObjectKeyPrefixes: []string{"flux-mock-real-stack-"},
+				Conditions: []metav1.Condition{
+					{
+						Type:               "Ready",
+						Status:             "Unknown",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "Progressing",
+						Message:            fmt.Sprintf("Creation of stack 'mock-real-stack' in progress (change set %s)", mockChangeSetArn),
+					},
+					{
+						Type:               "Reconciling",
+						Status:             "True",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "Progressing",
+						Message:            fmt.Sprintf("Creation of stack 'mock-real-stack' in progress (change set %s)", mockChangeSetArn),
+					},
+				},
+			},
+			markStackAsInProgress: true,
+			mockArtifactServer: func(t *testing.T) *httptest.Server {
+				return generateArtifactServer(t, artifact)
+			},
+			fillInSource: fillInSource,
+			fillInInitialCfnStack: func(cfnStack *cfnv1.CloudFormationStack) {
+				cfnStack.Name = mockStackName
+				cfnStack.Namespace = mockNamespace
+				cfnStack.Generation = mockGenerationId
+				cfnStack.Spec = generateMockCfnStackSpec()
+			},
+			mockS3ClientCalls: func(s3Client *clientmocks.MockS3Client) {
+				s3Client.EXPECT().HeadTemplate(gomock.Any(), mockTemplateUploadBucket, "", nestedTemplateKey, &clienttypes.TemplateBucketOptions{}).Return(&clienttypes.TemplateObject{
+					Key: nestedTemplateKey,
+					URL: nestedTemplateUrl,
+				}, nil)
+				s3Client.EXPECT().UploadTemplate(gomock.Any(),
+					mockTemplateUploadBucket,
+					"",
+					nestedTemplateKey,
+					strings.NewReader(nestedTemplate),
+					&clienttypes.TemplateBucketOptions{},
+				).Return(nestedTemplateUrl, nil)
+			},
+			mockCfnClientCalls: func(cfnClient *clientmocks.MockCloudFormationClient) {
+				cfnClient.EXPECT().DescribeStack(gomock.Any(), generateNestedStackInput()).Return(nil, &cloudformation.ErrStackNotFound{})
+				cfnClient.EXPECT().DescribeChangeSet(gomock.Any(), generateNestedStackInput()).Return(nil, &cloudformation.ErrChangeSetNotFound{})
+
+				expectedCreateStackIn := generateNestedStackInput()
+				expectedCreateStackIn.StackConfig.TemplateBody = rewrittenRootTemplate
+				cfnClient.EXPECT().CreateStack(gomock.Any(), expectedCreateStackIn).Return(mockChangeSetArn, nil)
+			},
+		},
+		"delete expired templates that are not used by the applied change set": {
+			wantedRequeueDelay: mockIntervalDuration,
+			wantedStackStatus: &cfnv1.CloudFormationStackStatus{
+				ObservedGeneration:         mockGenerationId,
+				StackName:                  mockRealStackName,
+				StackID:                    "arn:aws:cloudformation:us-west-2:123456789012:stack/mock-real-stack/uuid",
+				LastAttemptedRevision:      mockSourceRevision,
+				LastAppliedRevision:        mockSourceRevision,
+				LastAttemptedChangeSet:     nestedChangeSetArn,
+				LastAppliedChangeSet:       nestedChangeSetArn,
+				LastAppliedConfigDigest:    nestedConfigDigest,
+				LastAttemptedSourceDigest:  nestedSourceDigest,
+				LastAppliedStackUpdateTime: &mockAppliedStackUpdateTime,
+				Conditions: []metav1.Condition{
+					{
+						Type:               "Ready",
+						Status:             "True",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "Succeeded",
+						Message:            "Stack reconciliation succeeded",
+					},
+				},
+			},
+			templateRetention: 24 * time.Hour,
+			mockArtifactServer: func(t *testing.T) *httptest.Server {
+				return generateArtifactServer(t, artifact)
+			},
+			fillInSource: fillInSource,
+			fillInInitialCfnStack: func(cfnStack *cfnv1.CloudFormationStack) {
+				cfnStack.Name = mockStackName
+				cfnStack.Namespace = mockNamespace
+				cfnStack.Generation = mockGenerationId
+				cfnStack.Spec = generateMockCfnStackSpec()
+				cfnStack.Status = cfnv1.CloudFormationStackStatus{
+					ObservedGeneration:     mockGenerationId,
+					StackName:              mockRealStackName,
+					LastAttemptedRevision:  mockSourceRevision,
+					LastAttemptedChangeSet: nestedChangeSetArn,
+				}
+			},
+			mockS3ClientCalls: func(s3Client *clientmocks.MockS3Client) {
+				expiredKey := fmt.Sprintf("flux-mock-real-stack-%x.template", sha256.Sum256([]byte("expired")))
+				recentKey := fmt.Sprintf("flux-mock-real-stack-%x.template", sha256.Sum256([]byte("recent")))
+				otherStackKey := fmt.Sprintf("flux-mock-real-stack-other-%x.template", sha256.Sum256([]byte("other")))
+				legacyKey := "flux-mock-real-stack-0b7cc7b0-a5f2-4a8e-9d1c-4a0a7f1f3c2e.template"
+				expired := time.Now().Add(-48 * time.Hour)
+
+				s3Client.EXPECT().HeadTemplate(gomock.Any(), mockTemplateUploadBucket, "", nestedTemplateKey, &clienttypes.TemplateBucketOptions{}).Return(&clienttypes.TemplateObject{
+					Key:            nestedTemplateKey,
+					URL:            nestedTemplateUrl,
+					MatchesOptions: true,
+				}, nil)
+				s3Client.EXPECT().ListTemplates(gomock.Any(), mockTemplateUploadBucket, "", "flux-mock-real-stack-", &clienttypes.TemplateBucketOptions{}).Return([]*clienttypes.TemplateObject{
+					{Key: nestedTemplateKey, LastModified: expired},
+					{Key: expiredKey, LastModified: expired},
+					{Key: recentKey, LastModified: time.Now().Add(-time.Hour)},
+					{Key: otherStackKey, LastModified: expired},
+					{Key: legacyKey, LastModified: expired},
+				}, nil)
+				s3Client.EXPECT().DeleteTemplates(gomock.Any(), mockTemplateUploadBucket, "", []string{expiredKey, legacyKey}, &clienttypes.TemplateBucketOptions{}).Return(nil)
+			},
+			mockCfnClientCalls: func(cfnClient *clientmocks.MockCloudFormationClient) {
+				expectedIn := generateNestedStackInput()
+				expectedIn.ChangeSetArn = nestedChangeSetArn
+				cfnClient.EXPECT().DescribeStack(gomock.Any(), expectedIn).Return(&clienttypes.StackDescription{
+					StackId:      aws.String("arn:aws:cloudformation:us-west-2:123456789012:stack/mock-real-stack/uuid"),
+					StackName:    aws.String(mockRealStackName),
+					StackStatus:  sdktypes.StackStatusCreateComplete,
+					CreationTime: aws.Time(mockStackUpdateTime),
+				}, nil)
+				cfnClient.EXPECT().DescribeChangeSet(gomock.Any(), expectedIn).Return(&clienttypes.ChangeSetDescription{
+					Arn:             nestedChangeSetArn,
+					Status:          sdktypes.ChangeSetStatusCreateComplete,
+					ExecutionStatus: sdktypes.ExecutionStatusExecuteComplete,
+				}, nil)
+			},
+		},
+		"delete expired templates under previous key prefixes": {
+			wantedRequeueDelay: mockIntervalDuration,
+			wantedStackStatus: &cfnv1.CloudFormationStackStatus{
+				ObservedGeneration:         mockGenerationId,
+				StackName:                  mockRealStackName,
+				LastAttemptedRevision:      mockSourceRevision,
+				LastAppliedRevision:        mockSourceRevision,
+				LastAttemptedChangeSet:     nestedChangeSetArn,
+				LastAppliedChangeSet:       nestedChangeSetArn,
+				LastAppliedConfigDigest:    nestedConfigDigest,
+				LastAttemptedSourceDigest:  nestedSourceDigest,
+				LastAppliedStackUpdateTime: &mockAppliedStackUpdateTime,
+				TemplateObjectKeyPrefixes:  []string{"flux-mock-real-stack-", "older/flux-mock-real-stack-"},
+				Conditions: []metav1.Condition{
+					{
+						Type:               "Ready",
+						Status:             "True",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "Succeeded",
+						Message:            "Stack reconciliation succeeded",
+					},
+				},
+			},
+			templateRetention: 24 * time.Hour,
+			mockArtifactServer: func(t *testing.T) *httptest.Server {
+				return generateArtifactServer(t, artifact)
+			},
+			fillInSource: fillInSource,
+			fillInInitialCfnStack: func(cfnStack *cfnv1.CloudFormationStack) {
+				cfnStack.Name = mockStackName
+				cfnStack.Namespace = mockNamespace
+				cfnStack.Generation = mockGenerationId
+				cfnStack.Spec = generateMockCfnStackSpec()
+				cfnStack.Status = cfnv1.CloudFormationStackStatus{
+					ObservedGeneration:     mockGenerationId,
+					StackName:              mockRealStackName,
+					LastAttemptedRevision:  mockSourceRevision,
+					LastAttemptedChangeSet: nestedChangeSetArn,
+					TemplateObjectKeyPrefixes: []string{
+						"old/flux-mock-real-stack-",
+						"flux-mock-real-stack-",
+						"older/flux-mock-real-stack-",
+					},
+				}
+			},
+			mockS3ClientCalls: func(s3Client *clientmocks.MockS3Client) {
+				expired := time.Now().Add(-48 * time.Hour)
+				oldKey := fmt.Sprintf("old/flux-mock-real-stack-%x.template", sha256.Sum256([]byte("old")))
+				olderExpiredKey := fmt.Sprintf("older/flux-mock-real-stack-%x.template", sha256.Sum256([]byte("expired")))
+				olderRecentKey := fmt.Sprintf("older/flux-mock-real-stack-%x.template", sha256.Sum256([]byte("recent")))
+
+				s3Client.EXPECT().HeadTemplate(gomock.Any(), mockTemplateUploadBucket, "", nestedTemplateKey, &clienttypes.TemplateBucketOptions{}).Return(&clienttypes.TemplateObject{
+					Key:            nestedTemplateKey,
+					URL:            nestedTemplateUrl,
+					MatchesOptions: true,
+				}, nil)
+				s3Client.EXPECT().ListTemplates(gomock.Any(), mockTemplateUploadBucket, "", "flux-mock-real-stack-", &clienttypes.TemplateBucketOptions{}).Return([]*clienttypes.TemplateObject{
+					{Key: nestedTemplateKey, LastModified: expired},
+				}, nil)
+				s3Client.EXPECT().ListTemplates(gomock.Any(), mockTemplateUploadBucket, "", "old/flux-mock-real-stack-", &clienttypes.TemplateBucketOptions{}).Return([]*clienttypes.TemplateObject{
+					{Key: oldKey, LastModified: expired},
+				}, nil)
+				s3Client.EXPECT().DeleteTemplates(gomock.Any(), mockTemplateUploadBucket, "", []string{oldKey}, &clienttypes.TemplateBucketOptions{}).Return(nil)
+				s3Client.EXPECT().ListTemplates(gomock.Any(), mockTemplateUploadBucket, "", "older/flux-mock-real-stack-", &clienttypes.TemplateBucketOptions{}).Return([]*clienttypes.TemplateObject{
+					{Key: olderExpiredKey, LastModified: expired},
+					{Key: olderRecentKey, LastModified: time.Now().Add(-time.Hour)},
+				}, nil)
+				s3Client.EXPECT().DeleteTemplates(gomock.Any(), mockTemplateUploadBucket, "", []string{olderExpiredKey}, &clienttypes.TemplateBucketOptions{}).Return(nil)
+			},
+			mockCfnClientCalls: func(cfnClient *clientmocks.MockCloudFormationClient) {
+				expectedIn := generateNestedStackInput()
+				expectedIn.ChangeSetArn = nestedChangeSetArn
+				cfnClient.EXPECT().DescribeStack(gomock.Any(), expectedIn).Return(&clienttypes.StackDescription{
+					StackName:    aws.String(mockRealStackName),
+					StackStatus:  sdktypes.StackStatusCreateComplete,
+					CreationTime: aws.Time(mockStackUpdateTime),
+				}, nil)
+				cfnClient.EXPECT().DescribeChangeSet(gomock.Any(), expectedIn).Return(&clienttypes.ChangeSetDescription{
+					Arn:             nestedChangeSetArn,
+					Status:          sdktypes.ChangeSetStatusCreateComplete,
+					ExecutionStatus: sdktypes.ExecutionStatusExecuteComplete,
+				}, nil)
+			},
+		},
+		"mark stack as not ready if nested template upload fails": {
+			wantedErr: fmt.Errorf("upload nested template 'nested/network.yaml': %w", uploadErr),
+			wantedEvents: []*expectedEvent{{
+				eventType: "Warning",
+				severity:  "error",
+				message:   "Failed to reconcile stack: upload nested template 'nested/network.yaml': template upload failed",
+			}},
+			wantedRequeueDelay: mockRetryIntervalDuration,
+			wantedStackStatus: &cfnv1.CloudFormationStackStatus{
+				ObservedGeneration:        mockGenerationId,
+				StackName:                 mockRealStackName,
+				LastAttemptedRevision:     mockSourceRevision,
+				LastAttemptedSourceDigest: nestedSourceDigest,
+				Conditions: []metav1.Condition{
+					{
+						Type:               "Ready",
+						Status:             "False",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "TemplateUploadFailed",
+						Message:            "Failed to upload template to S3 for stack 'mock-real-stack'",
+					},
+					{
+						Type:               "Reconciling",
+						Status:             "True",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "ProgressingWithRetry",
+						Message:            "Failed to upload template to S3 for stack 'mock-real-stack'",
+					},
+				},
+			},
+			markStackAsInProgress: true,
+			mockArtifactServer: func(t *testing.T) *httptest.Server {
+				return generateArtifactServer(t, artifact)
+			},
+			fillInSource: fillInSource,
+			fillInInitialCfnStack: func(cfnStack *cfnv1.CloudFormationStack) {
+				cfnStack.Name = mockStackName
+				cfnStack.Namespace = mockNamespace
+				cfnStack.Generation = mockGenerationId
+				cfnStack.Spec = generateMockCfnStackSpec()
+			},
+			mockS3ClientCalls: func(s3Client *clientmocks.MockS3Client) {
+				s3Client.EXPECT().HeadTemplate(gomock.Any(), mockTemplateUploadBucket, "", nestedTemplateKey, &clienttypes.TemplateBucketOptions{}).Return(nil, &s3.ErrObjectNotFound{})
+				s3Client.EXPECT().UploadTemplate(gomock.Any(),
+					mockTemplateUploadBucket,
+					"",
+					nestedTemplateKey,
+					strings.NewReader(nestedTemplate),
+					&clienttypes.TemplateBucketOptions{},
+				).Return("", uploadErr)
+			},
+			mockCfnClientCalls: func(cfnClient *clientmocks.MockCloudFormationClient) {
+				cfnClient.EXPECT().DescribeStack(gomock.Any(), generateNestedStackInput()).Return(nil, &cloudformation.ErrStackNotFound{})
+				cfnClient.EXPECT().DescribeChangeSet(gomock.Any(), generateNestedStackInput()).Return(nil, &cloudformation.ErrChangeSetNotFound{})
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			runReconciliationLoopTestCase(t, tc)
+		})
+	}
+}
+
+func TestCfnController_PostBuild(t *testing.T) {
+	sourceDigest := "sha256:f99dd51c543cd0099cd41f598ff59ffbc9bc134b4139ffebe1c82b76d9b70612"
+	sourceTemplate := "Resources:\n  Topic:\n    Type: AWS::SNS::Topic\n    Properties:\n      TopicName: !Sub '${CLUSTER_NAME}-${AWS::Region}-${TopicSuffix}'\n"
+	substitutedTemplate := "Resources:\n  Topic:\n    Type: AWS::SNS::Topic\n    Properties:\n      TopicName: !Sub 'prod-${AWS::Region}-${TopicSuffix}'\n"
+
+	artifact, checksum, err := createArtifact(map[string]string{
+		"template.yaml": sourceTemplate,
+	})
+	require.NoError(t, err)
+
+	fillInSource := func(gitRepo *sourcev1.GitRepository, mockSourceArtifactURL string) {
+		generateMockGitRepoSource(gitRepo, mockSourceArtifactURL)
+		gitRepo.Status.Artifact.Digest = checksum
+	}
+	fillInInitialCfnStack := func(postBuild *cfnv1.PostBuild) func(cfnStack *cfnv1.CloudFormationStack) {
+		return func(cfnStack *cfnv1.CloudFormationStack) {
+			cfnStack.Name = mockStackName
+			cfnStack.Namespace = mockNamespace
+			cfnStack.Generation = mockGenerationId
+			cfnStack.Spec = generateMockCfnStackSpec()
+			cfnStack.Spec.StackParameters = []cfnv1.StackParameter{
+				{Key: "Environment", Value: "${ENVIRONMENT:=dev}"},
+			}
+			cfnStack.Spec.StackTags = []cfnv1.StackTag{
+				{Key: "Team", Value: "${TEAM}"},
+			}
+			cfnStack.Spec.PostBuild = postBuild
+		}
+	}
+	mockConfigMap := func(k8sClient *mocks.MockClient, name string, data map[string]string) {
+		k8sClient.EXPECT().Get(
+			gomock.Any(),
+			types.NamespacedName{Namespace: mockNamespace, Name: name},
+			gomock.AssignableToTypeOf(&corev1.ConfigMap{}),
+		).DoAndReturn(func(ctx context.Context, key ctrlclient.ObjectKey, obj ctrlclient.Object, opts ...ctrlclient.GetOption) error {
+			if data == nil {
+				return apierrors.NewNotFound(corev1.Resource("configmaps"), name)
+			}
+			obj.(*corev1.ConfigMap).Data = data
+			return nil
+		})
+	}
+	mockSecret := func(k8sClient *mocks.MockClient, name string, data map[string][]byte) {
+		k8sClient.EXPECT().Get(
+			gomock.Any(),
+			types.NamespacedName{Namespace: mockNamespace, Name: name},
+			gomock.AssignableToTypeOf(&corev1.Secret{}),
+		).DoAndReturn(func(ctx context.Context, key ctrlclient.ObjectKey, obj ctrlclient.Object, opts ...ctrlclient.GetOption) error {
+			if data == nil {
+				return apierrors.NewNotFound(corev1.Resource("secrets"), name)
+			}
+			obj.(*corev1.Secret).Data = data
+			return nil
+		})
+	}
+
+	substituteFromPostBuild := &cfnv1.PostBuild{
+		Substitute: map[string]string{"CLUSTER_NAME": "prod"},
+		SubstituteFrom: []cfnv1.SubstituteReference{
+			{Kind: "ConfigMap", Name: "cluster-vars"},
+			{Kind: "Secret", Name: "cluster-secret-vars"},
+			{Kind: "Secret", Name: "optional-vars", Optional: true},
+		},
+	}
+	substitutedTmpl := &template.Template{Path: mockTemplatePath, Body: substitutedTemplate}
+	generateSubstitutedStackInput := func(team string) *clienttypes.Stack {
+		input := generateStackInput(mockGenerationId, "")
+		input.TemplateBody = substitutedTemplate
+		input.Parameters = []sdktypes.Parameter{
+			{
+				ParameterKey:   aws.String("Environment"),
+				ParameterValue: aws.String("production"),
+			},
+		}
+		input.Tags = append(input.Tags, sdktypes.Tag{
+			Key:   aws.String("Team"),
+			Value: aws.String(team),
+		})
+		input.ChangeSetName = generateChangeSetName(mockGenerationId, sourceDigest, build.ConfigDigest(input, substitutedTmpl))
+		return input
+	}
+	// The stack was deployed with the previous ConfigMap values, then only the value of the TEAM variable changed
+	previousValuesConfigDigest := build.ConfigDigest(generateSubstitutedStackInput("platform"), substitutedTmpl)
+	previousValuesChangeSetArn := generateChangeSetArn(generateSubstitutedStackInput("platform").ChangeSetName)
+	updatedValuesStack := cfnv1.CloudFormationStack{ObjectMeta: metav1.ObjectMeta{Generation: mockGenerationId}}
+	updatedValuesStack.Status.LastAppliedStackUpdateTime = &mockAppliedStackUpdateTime
+	updatedValuesChangeSetName := build.ChangeSetName(updatedValuesStack, sourceDigest, build.ConfigDigest(generateSubstitutedStackInput("security"), substitutedTmpl))
+	updatedValuesChangeSetArn := generateChangeSetArn(updatedValuesChangeSetName)
+
+	testCases := map[string]*reconciliationLoopTestCase{
+		"substitute variables in the template, parameters and tags": {
+			wantedEvents: []*expectedEvent{{
+				eventType: "Normal",
+				severity:  "info",
+				message:   fmt.Sprintf("Creation of stack 'mock-real-stack' in progress (change set %s)", mockChangeSetArn),
+			}},
+			wantedRequeueDelay: mockPollIntervalDuration,
+			wantedStackStatus: &cfnv1.CloudFormationStackStatus{
+				ObservedGeneration:        mockGenerationId,
+				StackName:                 mockRealStackName,
+				LastAttemptedRevision:     mockSourceRevision,
+				LastAttemptedChangeSet:    mockChangeSetArn,
+				LastAttemptedSourceDigest: sourceDigest,
+				Conditions: []metav1.Condition{
+					{
+						Type:               "Ready",
+						Status:             "Unknown",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "Progressing",
+						Message:            fmt.Sprintf("Creation of stack 'mock-real-stack' in progress (change set %s)", mockChangeSetArn),
+					},
+					{
+						Type:               "Reconciling",
+						Status:             "True",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "Progressing",
+						Message:            fmt.Sprintf("Creation of stack 'mock-real-stack' in progress (change set %s)", mockChangeSetArn),
+					},
+				},
+			},
+			markStackAsInProgress: true,
+			mockArtifactServer: func(t *testing.T) *httptest.Server {
+				return generateArtifactServer(t, artifact)
+			},
+			fillInSource:          fillInSource,
+			fillInInitialCfnStack: fillInInitialCfnStack(substituteFromPostBuild),
+			mockPostBuildRetrieval: func(k8sClient *mocks.MockClient) {
+				mockConfigMap(k8sClient, "cluster-vars", map[string]string{"CLUSTER_NAME": "staging", "TEAM": "platform"})
+				mockSecret(k8sClient, "cluster-secret-vars", map[string][]byte{"ENVIRONMENT": []byte("production")})
+				mockSecret(k8sClient, "optional-vars", nil)
+			},
+			mockCfnClientCalls: func(cfnClient *clientmocks.MockCloudFormationClient) {
+				expectedIn := generateSubstitutedStackInput("platform")
+				cfnClient.EXPECT().DescribeStack(gomock.Any(), expectedIn).Return(nil, &cloudformation.ErrStackNotFound{})
+				cfnClient.EXPECT().DescribeChangeSet(gomock.Any(), expectedIn).Return(nil, &cloudformation.ErrChangeSetNotFound{})
+				cfnClient.EXPECT().CreateStack(gomock.Any(), expectedIn).Return(mockChangeSetArn, nil)
+			},
+		},
+		"create a new change set if only a substituted ConfigMap value changes": {
+			wantedEvents: []*expectedEvent{{
+				eventType: "Normal",
+				severity:  "info",
+				message:   fmt.Sprintf("Update of stack 'mock-real-stack' in progress (change set %s)", updatedValuesChangeSetArn),
+			}},
+			wantedRequeueDelay: mockPollIntervalDuration,
+			wantedStackStatus: &cfnv1.CloudFormationStackStatus{
+				ObservedGeneration:         mockGenerationId,
+				StackName:                  mockRealStackName,
+				LastAttemptedRevision:      mockSourceRevision,
+				LastAppliedRevision:        mockSourceRevision,
+				LastAttemptedChangeSet:     updatedValuesChangeSetArn,
+				LastAttemptedSourceDigest:  sourceDigest,
+				LastAppliedChangeSet:       previousValuesChangeSetArn,
+				LastAppliedConfigDigest:    previousValuesConfigDigest,
+				LastAppliedStackUpdateTime: &mockAppliedStackUpdateTime,
+				Conditions: []metav1.Condition{
+					{
+						Type:               "Ready",
+						Status:             "Unknown",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "Progressing",
+						Message:            fmt.Sprintf("Update of stack 'mock-real-stack' in progress (change set %s)", updatedValuesChangeSetArn),
+					},
+					{
+						Type:               "Reconciling",
+						Status:             "True",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "Progressing",
+						Message:            fmt.Sprintf("Update of stack 'mock-real-stack' in progress (change set %s)", updatedValuesChangeSetArn),
+					},
+				},
+			},
+			mockArtifactServer: func(t *testing.T) *httptest.Server {
+				return generateArtifactServer(t, artifact)
+			},
+			fillInSource: fillInSource,
+			fillInInitialCfnStack: func(cfnStack *cfnv1.CloudFormationStack) {
+				fillInInitialCfnStack(substituteFromPostBuild)(cfnStack)
+				cfnStack.Status = cfnv1.CloudFormationStackStatus{
+					ObservedGeneration:         mockGenerationId,
+					StackName:                  mockRealStackName,
+					LastAttemptedRevision:      mockSourceRevision,
+					LastAppliedRevision:        mockSourceRevision,
+					LastAttemptedChangeSet:     previousValuesChangeSetArn,
+					LastAttemptedSourceDigest:  sourceDigest,
+					LastAppliedChangeSet:       previousValuesChangeSetArn,
+					LastAppliedConfigDigest:    previousValuesConfigDigest,
+					LastAppliedStackUpdateTime: &mockAppliedStackUpdateTime,
+					Conditions: []metav1.Condition{
+						{
+							Type:               "Ready",
+							Status:             "True",
+							ObservedGeneration: mockGenerationId,
+							Reason:             "Succeeded",
+							Message:            "Stack reconciliation succeeded",
+						},
+					},
+				}
+			},
+			mockPostBuildRetrieval: func(k8sClient *mocks.MockClient) {
+				mockConfigMap(k8sClient, "cluster-vars", map[string]string{"CLUSTER_NAME": "staging", "TEAM": "security"})
+				mockSecret(k8sClient, "cluster-secret-vars", map[string][]byte{"ENVIRONMENT": []byte("production")})
+				mockSecret(k8sClient, "optional-vars", nil)
+			},
+			mockCfnClientCalls: func(cfnClient *clientmocks.MockCloudFormationClient) {
+				expectedIn := generateSubstitutedStackInput("security")
+				expectedIn.ChangeSetName = updatedValuesChangeSetName
+				cfnClient.EXPECT().DescribeStack(gomock.Any(), expectedIn).Return(&clienttypes.StackDescription{
+					StackName:       aws.String(mockRealStackName),
+					StackStatus:     sdktypes.StackStatusUpdateComplete,
+					LastUpdatedTime: aws.Time(mockStackUpdateTime),
+				}, nil)
+				cfnClient.EXPECT().DescribeChangeSet(gomock.Any(), expectedIn).Return(nil, &cloudformation.ErrChangeSetNotFound{})
+				cfnClient.EXPECT().UpdateStack(gomock.Any(), expectedIn).Return(updatedValuesChangeSetArn, nil)
+			},
+		},
+		"mark stack as not ready if a variable is not defined in strict mode": {
+			wantedEvents: []*expectedEvent{{
+				eventType: "Warning",
+				severity:  "error",
+				message:   "Failed to substitute variables for stack 'mock-real-stack': variable substitution failed in template 'template.yaml': variable 'TopicSuffix' is not defined",
+			}},
+			wantedRequeueDelay: mockRetryIntervalDuration,
+			wantedStackStatus: &cfnv1.CloudFormationStackStatus{
+				ObservedGeneration:    mockGenerationId,
+				StackName:             mockRealStackName,
+				LastAttemptedRevision: mockSourceRevision,
+				Conditions: []metav1.Condition{
+					{
+						Type:               "Ready",
+						Status:             "False",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "SubstitutionFailed",
+						Message:            "Failed to substitute variables for stack 'mock-real-stack': variable substitution failed in template 'template.yaml': variable 'TopicSuffix' is not defined",
+					},
+					{
+						Type:               "Stalled",
+						Status:             "True",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "SubstitutionFailed",
+						Message:            "Failed to substitute variables for stack 'mock-real-stack': variable substitution failed in template 'template.yaml': variable 'TopicSuffix' is not defined",
+					},
+				},
+			},
+			markStackAsInProgress: true,
+			mockArtifactServer: func(t *testing.T) *httptest.Server {
+				return generateArtifactServer(t, artifact)
+			},
+			fillInSource: fillInSource,
+			fillInInitialCfnStack: fillInInitialCfnStack(&cfnv1.PostBuild{
+				Substitute: map[string]string{"CLUSTER_NAME": "prod", "TEAM": "platform"},
+				Strict:     true,
+			}),
+		},
+		"mark stack as not ready if a referenced ConfigMap does not exist": {
+			wantedEvents: []*expectedEvent{{
+				eventType: "Warning",
+				severity:  "error",
+				message:   "Failed to substitute variables for stack 'mock-real-stack': variable substitution failed: unable to get ConfigMap 'mock-namespace/cluster-vars': configmaps \"cluster-vars\" not found",
+			}},
+			wantedRequeueDelay: mockRetryIntervalDuration,
+			wantedStackStatus: &cfnv1.CloudFormationStackStatus{
+				ObservedGeneration:    mockGenerationId,
+				StackName:             mockRealStackName,
+				LastAttemptedRevision: mockSourceRevision,
+				Conditions: []metav1.Condition{
+					{
+						Type:               "Ready",
+						Status:             "False",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "SubstitutionFailed",
+						Message:            "Failed to substitute variables for stack 'mock-real-stack': variable substitution failed: unable to get ConfigMap 'mock-namespace/cluster-vars': configmaps \"cluster-vars\" not found",
+					},
+					{
+						Type:               "Stalled",
+						Status:             "True",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "SubstitutionFailed",
+						Message:            "Failed to substitute variables for stack 'mock-real-stack': variable substitution failed: unable to get ConfigMap 'mock-namespace/cluster-vars': configmaps \"cluster-vars\" not found",
+					},
+				},
+			},
+			markStackAsInProgress: true,
+			fillInSource:          fillInSource,
+			fillInInitialCfnStack: fillInInitialCfnStack(&cfnv1.PostBuild{
+				SubstituteFrom: []cfnv1.SubstituteReference{
+					{Kind: "ConfigMap", Name: "cluster-vars"},
+				},
+			}),
+			mockPostBuildRetrieval: func(k8sClient *mocks.MockClient) {
+				mockConfigMap(k8sClient, "cluster-vars", nil)
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			runReconciliationLoopTestCase(t, tc)
+		})
+	}
+}
+
+func TestCfnController_Patches(t *testing.T) {
+	sourceDigest := "sha256:4f68ef0f87718c3b045bf472f9eca4bba0ec7cff0dcc283b5c83bb6fffa05a25"
+	sourceTemplate := "Resources:\n  Topic:\n    Type: AWS::SNS::Topic\n    Properties:\n      TopicName: !Ref AWS::StackName\n  Queue:\n    Type: AWS::SQS::Queue\n"
+	patchedTemplate := "Resources:\n  Topic:\n    Type: AWS::SNS::Topic\n    Properties:\n      TopicName: !Ref AWS::StackName\n      KmsMasterKeyId: alias/prod\n"
+
+	artifact, checksum, err := createArtifact(map[string]string{
+		"template.yaml": sourceTemplate,
+	})
+	require.NoError(t, err)
+
+	fillInSource := func(gitRepo *sourcev1.GitRepository, mockSourceArtifactURL string) {
+		generateMockGitRepoSource(gitRepo, mockSourceArtifactURL)
+		gitRepo.Status.Artifact.Digest = checksum
+	}
+	fillInInitialCfnStack := func(patches []cfnv1.TemplatePatch) func(cfnStack *cfnv1.CloudFormationStack) {
+		return func(cfnStack *cfnv1.CloudFormationStack) {
+			cfnStack.Name = mockStackName
+			cfnStack.Namespace = mockNamespace
+			cfnStack.Generation = mockGenerationId
+			cfnStack.Spec = generateMockCfnStackSpec()
+			cfnStack.Spec.PostBuild = &cfnv1.PostBuild{
+				Substitute: map[string]string{"KMS_KEY": "alias/prod"},
+			}
+			cfnStack.Spec.Patches = patches
+		}
+	}
+
+	testCases := map[string]*reconciliationLoopTestCase{
+		"apply patches to the template": {
+			wantedEvents: []*expectedEvent{{
+				eventType: "Normal",
+				severity:  "info",
+				message:   fmt.Sprintf("Creation of stack 'mock-real-stack' in progress (change set %s)", mockChangeSetArn),
+			}},
+			wantedRequeueDelay: mockPollIntervalDuration,
+			wantedStackStatus: &cfnv1.CloudFormationStackStatus{
+				ObservedGeneration:        mockGenerationId,
+				StackName:                 mockRealStackName,
+				LastAttemptedRevision:     mockSourceRevision,
+				LastAttemptedChangeSet:    mockChangeSetArn,
+				LastAttemptedSourceDigest: sourceDigest,
+				Conditions: []metav1.Condition{
+					{
+						Type:               "Ready",
+						Status:             "Unknown",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "Progressing",
+						Message:            fmt.Sprintf("Creation of stack 'mock-real-stack' in progress (change set %s)", mockChangeSetArn),
+					},
+					{
+						Type:               "Reconciling",
+						Status:             "True",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "Progressing",
+						Message:            fmt.Sprintf("Creation of stack 'mock-real-stack' in progress (change set %s)", mockChangeSetArn),
+					},
+				},
+			},
+			markStackAsInProgress: true,
+			mockArtifactServer: func(t *testing.T) *httptest.Server {
+				return generateArtifactServer(t, artifact)
+			},
+			fillInSource: fillInSource,
+			fillInInitialCfnStack: fillInInitialCfnStack([]cfnv1.TemplatePatch{
+				{
+					Patch:  "[{op: add, path: /Properties/KmsMasterKeyId, value: '${KMS_KEY}'}]",
+					Target: &cfnv1.PatchTarget{Type: "AWS::SNS::Topic"},
+				},
+				{
+					Patch:  "$patch: delete",
+					Target: &cfnv1.PatchTarget{LogicalID: "Queue"},
+				},
+			}),
+			mockCfnClientCalls: func(cfnClient *clientmocks.MockCloudFormationClient) {
+				expectedIn := generateStackInput(mockGenerationId, "")
+				expectedIn.TemplateBody = patchedTemplate
+				patchedTmpl := &template.Template{Path: mockTemplatePath, Body: patchedTemplate}
+				expectedIn.ChangeSetName = generateChangeSetName(mockGenerationId, sourceDigest, build.ConfigDigest(expectedIn, patchedTmpl))
+				cfnClient.EXPECT().DescribeStack(gomock.Any(), expectedIn).Return(nil, &cloudformation.ErrStackNotFound{})
+				cfnClient.EXPECT().DescribeChangeSet(gomock.Any(), expectedIn).Return(nil, &cloudformation.ErrChangeSetNotFound{})
+				cfnClient.EXPECT().CreateStack(gomock.Any(), expectedIn).Return(mockChangeSetArn, nil)
+			},
+		},
+		"mark stack as not ready if a patch cannot be applied": {
+			wantedEvents: []*expectedEvent{{
+				eventType: "Warning",
+				severity:  "error",
+				message:   "Failed to patch template 'template.yaml': apply patch 0 to template 'template.yaml': no resources match the patch target",
+			}},
+			wantedRequeueDelay: mockRetryIntervalDuration,
+			wantedStackStatus: &cfnv1.CloudFormationStackStatus{
+				ObservedGeneration:    mockGenerationId,
+				StackName:             mockRealStackName,
+				LastAttemptedRevision: mockSourceRevision,
+				Conditions: []metav1.Condition{
+					{
+						Type:               "Ready",
+						Status:             "False",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "PatchFailed",
+						Message:            "Failed to patch template 'template.yaml': apply patch 0 to template 'template.yaml': no resources match the patch target",
+					},
+					{
+						Type:               "Stalled",
+						Status:             "True",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "PatchFailed",
+						Message:            "Failed to patch template 'template.yaml': apply patch 0 to template 'template.yaml': no resources match the patch target",
+					},
+				},
+			},
+			markStackAsInProgress: true,
+			mockArtifactServer: func(t *testing.T) *httptest.Server {
+				return generateArtifactServer(t, artifact)
+			},
+			fillInSource: fillInSource,
+			fillInInitialCfnStack: fillInInitialCfnStack([]cfnv1.TemplatePatch{
+				{
+					Patch:  "Properties: {BucketName: my-bucket}",
+					Target: &cfnv1.PatchTarget{Type: "AWS::S3::Bucket"},
+				},
+			}),
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			runReconciliationLoopTestCase(t, tc)
+		})
+	}
+}
+
+func TestCfnController_Policies(t *testing.T) {
+	sourceDigest := "sha256:2c8872b92579b953b5f967e4baa881a545ceff30532794daf1ba6830f0bcf321"
+	sourceTemplate := "Resources:\n  Topic:\n    Type: AWS::SNS::Topic\n    Properties:\n      TopicName: !Ref AWS::StackName\n"
+	encryptedTemplate := "Resources:\n  Topic:\n    Type: AWS::SNS::Topic\n    Properties:\n      TopicName: !Ref AWS::StackName\n      KmsMasterKeyId: alias/prod\n"
+
+	encryptedTopicsPolicy := `package cloudformation
+
+deny contains msg if {
+	some name, resource in input.template.Resources
+	resource.Type == "AWS::SNS::Topic"
+	not resource.Properties.KmsMasterKeyId
+	msg := sprintf("topic '%s' must be encrypted", [name])
+}
+`
+	noReplacementPolicy := `package cloudformation
+
+deny contains msg if {
+	some change in input.changes
+	change.ResourceChange.Replacement == "True"
+	msg := sprintf("resource '%s' must not be replaced", [change.ResourceChange.LogicalResourceId])
+}
+`
+
+	artifact, checksum, err := createArtifact(map[string]string{
+		"template.yaml": sourceTemplate,
+	})
+	require.NoError(t, err)
+
+	fillInSource := func(gitRepo *sourcev1.GitRepository, mockSourceArtifactURL string) {
+		generateMockGitRepoSource(gitRepo, mockSourceArtifactURL)
+		gitRepo.Status.Artifact.Digest = checksum
+	}
+	fillInInitialCfnStack := func(patches []cfnv1.TemplatePatch, status cfnv1.CloudFormationStackStatus) func(cfnStack *cfnv1.CloudFormationStack) {
+		return func(cfnStack *cfnv1.CloudFormationStack) {
+			cfnStack.Name = mockStackName
+			cfnStack.Namespace = mockNamespace
+			cfnStack.Generation = mockGenerationId
+			cfnStack.Spec = generateMockCfnStackSpec()
+			cfnStack.Spec.Patches = patches
+			cfnStack.Spec.PolicyRef = &cfnv1.PolicyReference{Kind: "ConfigMap", Name: "stack-policies"}
+			cfnStack.Status = status
+		}
+	}
+	mockPolicyConfigMap := func(data map[string]string) func(k8sClient *mocks.MockClient) {
+		return func(k8sClient *mocks.MockClient) {
+			k8sClient.EXPECT().Get(
+				gomock.Any(),
+				types.NamespacedName{Namespace: mockNamespace, Name: "stack-policies"},
+				gomock.AssignableToTypeOf(&corev1.ConfigMap{}),
+			).DoAndReturn(func(ctx context.Context, key ctrlclient.ObjectKey, obj ctrlclient.Object, opts ...ctrlclient.GetOption) error {
+				if data == nil {
+					return apierrors.NewNotFound(corev1.Resource("configmaps"), "stack-policies")
+				}
+				obj.(*corev1.ConfigMap).Data = data
+				return nil
+			})
+		}
+	}
+	generatePolicyStackInput := func(body string, changeSetArn string) *clienttypes.Stack {
+		input := generateStackInput(mockGenerationId, changeSetArn)
+		input.TemplateBody = body
+		tmpl := &template.Template{Path: mockTemplatePath, Body: body}
+		input.ChangeSetName = generateChangeSetName(mockGenerationId, sourceDigest, build.ConfigDigest(input, tmpl))
+		return input
+	}
+	encryptedChangeSetArn := generateChangeSetArn(generatePolicyStackInput(encryptedTemplate, "").ChangeSetName)
+	encryptTopic := []cfnv1.TemplatePatch{{
+		Patch:  "Properties: {KmsMasterKeyId: alias/prod}",
+		Target: &cfnv1.PatchTarget{Type: "AWS::SNS::Topic"},
+	}}
+
+	testCases := map[string]*reconciliationLoopTestCase{
+		"create the change set if the stack complies with the policies": {
+			wantedEvents: []*expectedEvent{{
+				eventType: "Normal",
+				severity:  "info",
+				message:   fmt.Sprintf("Creation of stack 'mock-real-stack' in progress (change set %s)", mockChangeSetArn),
+			}},
+			wantedRequeueDelay: mockPollIntervalDuration,
+			wantedStackStatus: &cfnv1.CloudFormationStackStatus{
+				ObservedGeneration:        mockGenerationId,
+				StackName:                 mockRealStackName,
+				LastAttemptedRevision:     mockSourceRevision,
+				LastAttemptedChangeSet:    mockChangeSetArn,
+				LastAttemptedSourceDigest: sourceDigest,
+				Conditions: []metav1.Condition{
+					{
+						Type:               "Ready",
+						Status:             "Unknown",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "Progressing",
+						Message:            fmt.Sprintf("Creation of stack 'mock-real-stack' in progress (change set %s)", mockChangeSetArn),
+					},
+					{
+						Type:               "Reconciling",
+						Status:             "True",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "Progressing",
+						Message:            fmt.Sprintf("Creation of stack 'mock-real-stack' in progress (change set %s)", mockChangeSetArn),
+					},
+				},
+			},
+			markStackAsInProgress: true,
+			mockArtifactServer: func(t *testing.T) *httptest.Server {
+				return generateArtifactServer(t, artifact)
+			},
+			fillInSource:          fillInSource,
+			fillInInitialCfnStack: fillInInitialCfnStack(encryptTopic, cfnv1.CloudFormationStackStatus{}),
+			mockPolicyRetrieval: mockPolicyConfigMap(map[string]string{
+				"topics.rego": encryptedTopicsPolicy,
+				"README.md":   "not a policy",
+			}),
+			mockCfnClientCalls: func(cfnClient *clientmocks.MockCloudFormationClient) {
+				expectedIn := generatePolicyStackInput(encryptedTemplate, "")
+				cfnClient.EXPECT().DescribeStack(gomock.Any(), expectedIn).Return(nil, &cloudformation.ErrStackNotFound{})
+				cfnClient.EXPECT().DescribeChangeSet(gomock.Any(), expectedIn).Return(nil, &cloudformation.ErrChangeSetNotFound{})
+				cfnClient.EXPECT().CreateStack(gomock.Any(), expectedIn).Return(mockChangeSetArn, nil)
+			},
+		},
+		"mark stack as not ready if the template violates the policies": {
+			wantedEvents: []*expectedEvent{{
+				eventType: "Warning",
+				severity:  "error",
+				message:   "Stack 'mock-real-stack' violates policies: topic 'Topic' must be encrypted",
+			}},
+			wantedRequeueDelay: mockRetryIntervalDuration,
+			wantedStackStatus: &cfnv1.CloudFormationStackStatus{
+				ObservedGeneration:        mockGenerationId,
+				StackName:                 mockRealStackName,
+				LastAttemptedRevision:     mockSourceRevision,
+				LastAttemptedSourceDigest: sourceDigest,
+				Conditions: []metav1.Condition{
+					{
+						Type:               "Ready",
+						Status:             "False",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "PolicyViolation",
+						Message:            "Stack 'mock-real-stack' violates policies: topic 'Topic' must be encrypted",
+					},
+					{
+						Type:               "Stalled",
+						Status:             "True",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "PolicyViolation",
+						Message:            "Stack 'mock-real-stack' violates policies: topic 'Topic' must be encrypted",
+					},
+				},
+			},
+			markStackAsInProgress: true,
+			mockArtifactServer: func(t *testing.T) *httptest.Server {
+				return generateArtifactServer(t, artifact)
+			},
+			fillInSource:          fillInSource,
+			fillInInitialCfnStack: fillInInitialCfnStack(nil, cfnv1.CloudFormationStackStatus{}),
+			mockPolicyRetrieval:   mockPolicyConfigMap(map[string]string{"topics.rego": encryptedTopicsPolicy}),
+			mockCfnClientCalls: func(cfnClient *clientmocks.MockCloudFormationClient) {
+				expectedIn := generatePolicyStackInput(sourceTemplate, "")
+				cfnClient.EXPECT().DescribeStack(gomock.Any(), expectedIn).Return(nil, &cloudformation.ErrStackNotFound{})
+				cfnClient.EXPECT().DescribeChangeSet(gomock.Any(), expectedIn).Return(nil, &cloudformation.ErrChangeSetNotFound{})
+			},
+		},
+		"mark stack as not ready if the change set violates the policies": {
+			wantedEvents: []*expectedEvent{{
+				eventType: "Warning",
+				severity:  "error",
+				message:   "Stack 'mock-real-stack' violates policies: resource 'Topic' must not be replaced",
+			}},
+			wantedRequeueDelay: mockRetryIntervalDuration,
+			wantedStackStatus: &cfnv1.CloudFormationStackStatus{
+				ObservedGeneration:        mockGenerationId,
+				StackName:                 mockRealStackName,
+				LastAttemptedRevision:     mockSourceRevision,
+				LastAttemptedChangeSet:    encryptedChangeSetArn,
+				LastAttemptedSourceDigest: sourceDigest,
+				Conditions: []metav1.Condition{
+					{
+						Type:               "Ready",
+						Status:             "False",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "PolicyViolation",
+						Message:            "Stack 'mock-real-stack' violates policies: resource 'Topic' must not be replaced",
+					},
+					{
+						Type:               "Stalled",
+						Status:             "True",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "PolicyViolation",
+						Message:            "Stack 'mock-real-stack' violates policies: resource 'Topic' must not be replaced",
+					},
+				},
+			},
+			mockArtifactServer: func(t *testing.T) *httptest.Server {
+				return generateArtifactServer(t, artifact)
+			},
+			fillInSource: fillInSource,
+			fillInInitialCfnStack: fillInInitialCfnStack(encryptTopic, cfnv1.CloudFormationStackStatus{
+				ObservedGeneration:     mockGenerationId,
+				StackName:              mockRealStackName,
+				LastAttemptedRevision:  mockSourceRevision,
+				LastAttemptedChangeSet: encryptedChangeSetArn,
+				Conditions: []metav1.Condition{
+					{
+						Type:               "Ready",
+						Status:             "Unknown",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "Progressing",
+						Message:            "Stack reconciliation in progress",
+					},
+					{
+						Type:               "Reconciling",
+						Status:             "True",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "Progressing",
+						Message:            "Stack reconciliation in progress",
+					},
+				},
+			}),
+			mockPolicyRetrieval: mockPolicyConfigMap(map[string]string{
+				"topics.rego":      encryptedTopicsPolicy,
+				"replacement.rego": noReplacementPolicy,
+			}),
+			mockCfnClientCalls: func(cfnClient *clientmocks.MockCloudFormationClient) {
+				expectedIn := generatePolicyStackInput(encryptedTemplate, encryptedChangeSetArn)
+				cfnClient.EXPECT().DescribeStack(gomock.Any(), expectedIn).Return(&clienttypes.StackDescription{
+					StackName:   aws.String(mockRealStackName),
+					StackStatus: sdktypes.StackStatusCreateComplete,
+				}, nil)
+				cfnClient.EXPECT().DescribeChangeSet(gomock.Any(), expectedIn).Return(&clienttypes.ChangeSetDescription{
+					Arn:             encryptedChangeSetArn,
+					Status:          sdktypes.ChangeSetStatusCreateComplete,
+					ExecutionStatus: sdktypes.ExecutionStatusAvailable,
+					Changes: []sdktypes.Change{{
+						Type: sdktypes.ChangeTypeResource,
+						ResourceChange: &sdktypes.ResourceChange{
+							Action:            sdktypes.ChangeActionModify,
+							LogicalResourceId: aws.String("Topic"),
+							Replacement:       sdktypes.ReplacementTrue,
+						},
+					}},
+				}, nil)
+			},
+		},
+		"mark stack as not ready if the policy ConfigMap does not exist": {
+			wantedEvents: []*expectedEvent{{
+				eventType: "Warning",
+				severity:  "error",
+				message:   "Failed to load policies for stack 'mock-real-stack': unable to get ConfigMap 'mock-namespace/stack-policies': configmaps \"stack-policies\" not found",
+			}},
+			wantedRequeueDelay: mockRetryIntervalDuration,
+			wantedStackStatus: &cfnv1.CloudFormationStackStatus{
+				ObservedGeneration:    mockGenerationId,
+				StackName:             mockRealStackName,
+				LastAttemptedRevision: mockSourceRevision,
+				Conditions: []metav1.Condition{
+					{
+						Type:               "Ready",
+						Status:             "False",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "PolicyFailed",
+						Message:            "Failed to load policies for stack 'mock-real-stack': unable to get ConfigMap 'mock-namespace/stack-policies': configmaps \"stack-policies\" not found",
+					},
+					{
+						Type:               "Stalled",
+						Status:             "True",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "PolicyFailed",
+						Message:            "Failed to load policies for stack 'mock-real-stack': unable to get ConfigMap 'mock-namespace/stack-policies': configmaps \"stack-policies\" not found",
+					},
+				},
+			},
+			markStackAsInProgress: true,
+			mockArtifactServer: func(t *testing.T) *httptest.Server {
+				return generateArtifactServer(t, artifact)
+			},
+			fillInSource:          fillInSource,
+			fillInInitialCfnStack: fillInInitialCfnStack(nil, cfnv1.CloudFormationStackStatus{}),
+			mockPolicyRetrieval:   mockPolicyConfigMap(nil),
+		},
+		"mark stack as stalled if the policies do not compile": {
+			wantedEvents: []*expectedEvent{{
+				eventType: "Warning",
+				severity:  "error",
+				message:   "Failed to load policies for stack 'mock-real-stack': compile policies: 1 error occurred: broken.rego:4: rego_type_error: undefined function lookup",
+			}},
+			wantedRequeueDelay: mockRetryIntervalDuration,
+			wantedStackStatus: &cfnv1.CloudFormationStackStatus{
+				ObservedGeneration:    mockGenerationId,
+				StackName:             mockRealStackName,
+				LastAttemptedRevision: mockSourceRevision,
+				Conditions: []metav1.Condition{
+					{
+						Type:               "Ready",
+						Status:             "False",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "PolicyFailed",
+						Message:            "Failed to load policies for stack 'mock-real-stack': compile policies: 1 error occurred: broken.rego:4: rego_type_error: undefined function lookup",
+					},
+					{
+						Type:               "Stalled",
+						Status:             "True",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "PolicyFailed",
+						Message:            "Failed to load policies for stack 'mock-real-stack': compile policies: 1 error occurred: broken.rego:4: rego_type_error: undefined function lookup",
+					},
+				},
+			},
+			markStackAsInProgress: true,
+			mockArtifactServer: func(t *testing.T) *httptest.Server {
+				return generateArtifactServer(t, artifact)
+			},
+			fillInSource:          fillInSource,
+			fillInInitialCfnStack: fillInInitialCfnStack(nil, cfnv1.CloudFormationStackStatus{}),
+			mockPolicyRetrieval:   mockPolicyConfigMap(map[string]string{"broken.rego": "package cloudformation\n\ndeny contains msg if {\n\tmsg := lookup(input.tags)\n}\n"}),
+		},
+		"retry without stalling if the policy source artifact is temporarily unavailable": {
+			wantedErr:          fmt.Errorf("policy source 'GitRepository/policies' is not ready, artifact not found"),
+			wantedRequeueDelay: mockRetryIntervalDuration,
+			wantedEvents: []*expectedEvent{{
+				eventType: "Warning",
+				severity:  "error",
+				message:   "Failed to load policies for stack 'mock-real-stack': policy source 'GitRepository/policies' is not ready, artifact not found",
+			}},
+			wantedStackStatus: &cfnv1.CloudFormationStackStatus{
+				ObservedGeneration:    mockGenerationId,
+				StackName:             mockRealStackName,
+				LastAttemptedRevision: mockSourceRevision,
+				Conditions: []metav1.Condition{
+					{
+						Type:               "Ready",
+						Status:             "False",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "PolicyFailed",
+						Message:            "Failed to load policies for stack 'mock-real-stack': policy source 'GitRepository/policies' is not ready, artifact not found",
+					},
+					{
+						Type:               "Reconciling",
+						Status:             "True",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "ProgressingWithRetry",
+						Message:            "Failed to load policies for stack 'mock-real-stack': policy source 'GitRepository/policies' is not ready, artifact not found",
+					},
+				},
+			},
+			markStackAsInProgress: true,
+			mockArtifactServer: func(t *testing.T) *httptest.Server {
+				return generateArtifactServer(t, artifact)
+			},
+			fillInSource: fillInSource,
+			fillInInitialCfnStack: func(cfnStack *cfnv1.CloudFormationStack) {
+				fillInInitialCfnStack(nil, cfnv1.CloudFormationStackStatus{})(cfnStack)
+				cfnStack.Spec.PolicyRef = &cfnv1.PolicyReference{Kind: "GitRepository", Name: "policies", Path: "./policies"}
+			},
+			mockPolicyRetrieval: func(k8sClient *mocks.MockClient) {
+				k8sClient.EXPECT().Get(
+					gomock.Any(),
+					types.NamespacedName{Namespace: mockNamespace, Name: "policies"},
+					gomock.AssignableToTypeOf(&sourcev1.GitRepository{}),
+				).Return(nil)
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			runReconciliationLoopTestCase(t, tc)
+		})
+	}
+}
+
+func TestCfnController_Plan(t *testing.T) {
+	fillInInitialCfnStack := func(mode string, status cfnv1.CloudFormationStackStatus) func(cfnStack *cfnv1.CloudFormationStack) {
+		return func(cfnStack *cfnv1.CloudFormationStack) {
+			cfnStack.Name = mockStackName
+			cfnStack.Namespace = mockNamespace
+			cfnStack.Generation = mockGenerationId
+			cfnStack.Spec = generateMockCfnStackSpec()
+			cfnStack.Spec.Mode = mode
+			cfnStack.Status = status
+		}
+	}
+	inProgressStatus := cfnv1.CloudFormationStackStatus{
+		ObservedGeneration:        mockGenerationId,
+		StackName:                 mockRealStackName,
+		LastAttemptedRevision:     mockSourceRevision,
+		LastAttemptedChangeSet:    mockChangeSetArn,
+		LastAttemptedSourceDigest: mockSourceDigest,
+		Conditions: []metav1.Condition{
+			{
+				Type:               "Ready",
+				Status:             "Unknown",
+				ObservedGeneration: mockGenerationId,
+				Reason:             "Progressing",
+				Message:            fmt.Sprintf("Planning of stack 'mock-real-stack' in progress (change set %s)", mockChangeSetArn),
+			},
+			{
+				Type:               "Reconciling",
+				Status:             "True",
+				ObservedGeneration: mockGenerationId,
+				Reason:             "Progressing",
+				Message:            fmt.Sprintf("Planning of stack 'mock-real-stack' in progress (change set %s)", mockChangeSetArn),
+			},
+		},
+	}
+	plannedStatus := func(plan *cfnv1.StackPlan) *cfnv1.CloudFormationStackStatus {
+		return &cfnv1.CloudFormationStackStatus{
+			ObservedGeneration:        mockGenerationId,
+			StackName:                 mockRealStackName,
+			LastAttemptedRevision:     mockSourceRevision,
+			LastAttemptedChangeSet:    mockChangeSetArn,
+			LastAttemptedSourceDigest: mockSourceDigest,
+			LastPlan:                  plan,
+			Conditions: []metav1.Condition{
+				{
+					Type:               "Ready",
+					Status:             "True",
+					ObservedGeneration: mockGenerationId,
+					Reason:             "Succeeded",
+					Message:            "Stack plan succeeded: " + plan.Summary(),
+				},
+				{
+					Type:               "Planned",
+					Status:             "True",
+					ObservedGeneration: mockGenerationId,
+					Reason:             "Succeeded",
+					Message:            "Stack plan succeeded: " + plan.Summary(),
+				},
+			},
+		}
+	}
+	changes := []sdktypes.Change{
+		{
+			Type: sdktypes.ChangeTypeResource,
+			ResourceChange: &sdktypes.ResourceChange{
+				Action:            sdktypes.ChangeActionAdd,
+				LogicalResourceId: aws.String("Queue"),
+				ResourceType:      aws.String("AWS::SQS::Queue"),
+			},
+		},
+		{
+			Type: sdktypes.ChangeTypeResource,
+			ResourceChange: &sdktypes.ResourceChange{
+				Action:             sdktypes.ChangeActionModify,
+				LogicalResourceId:  aws.String("Topic"),
+				PhysicalResourceId: aws.String("arn:aws:sns:us-west-2:123456789012:topic"),
+				ResourceType:       aws.String("AWS::SNS::Topic"),
+				Replacement:        sdktypes.ReplacementFalse,
+			},
+		},
+	}
+	updatePlan := &cfnv1.StackPlan{
+		ChangeSet: mockChangeSetArn,
+		Revision:  mockSourceRevision,
+		Add:       1,
+		Modify:    1,
+		Changes: []cfnv1.PlannedResourceChange{
+			{Action: "Add", LogicalID: "Queue", ResourceType: "AWS::SQS::Queue"},
+			{Action: "Modify", LogicalID: "Topic", PhysicalID: "arn:aws:sns:us-west-2:123456789012:topic", ResourceType: "AWS::SNS::Topic", Replacement: "False"},
+		},
+	}
+
+	testCases := map[string]*reconciliationLoopTestCase{
+		"create a change set to plan a new stack": {
+			wantedEvents: []*expectedEvent{{
+				eventType: "Normal",
+				severity:  "info",
+				message:   fmt.Sprintf("Planning of stack 'mock-real-stack' in progress (change set %s)", mockChangeSetArn),
+			}},
+			wantedRequeueDelay:    mockPollIntervalDuration,
+			wantedStackStatus:     &inProgressStatus,
+			markStackAsInProgress: true,
+			fillInSource:          generateMockGitRepoSource,
+			fillInInitialCfnStack: fillInInitialCfnStack(cfnv1.PlanMode, cfnv1.CloudFormationStackStatus{}),
+			mockCfnClientCalls: func(cfnClient *clientmocks.MockCloudFormationClient) {
+				expectedIn := generateStackInput(mockGenerationId, "")
+				cfnClient.EXPECT().DescribeStack(gomock.Any(), expectedIn).Return(nil, &cloudformation.ErrStackNotFound{})
+				cfnClient.EXPECT().DescribeChangeSet(gomock.Any(), expectedIn).Return(nil, &cloudformation.ErrChangeSetNotFound{})
+				cfnClient.EXPECT().CreateStack(gomock.Any(), expectedIn).Return(mockChangeSetArn, nil)
+			},
+		},
+		"record the plan and delete the change set instead of executing it": {
+			wantedEvents: []*expectedEvent{{
+				eventType: "Normal",
+				severity:  "info",
+				message:   fmt.Sprintf("Planned changes for stack 'mock-real-stack' (change set %s): 1 to add, 1 to modify, 0 to remove", mockChangeSetArn),
+			}},
+			wantedRequeueDelay:    mockIntervalDuration,
+			wantedStackStatus:     plannedStatus(updatePlan),
+			fillInSource:          generateMockGitRepoSource,
+			fillInInitialCfnStack: fillInInitialCfnStack(cfnv1.PlanMode, inProgressStatus),
+			mockCfnClientCalls: func(cfnClient *clientmocks.MockCloudFormationClient) {
+				expectedIn := generateStackInput(mockGenerationId, mockChangeSetArn)
+				cfnClient.EXPECT().DescribeStack(gomock.Any(), expectedIn).Return(&clienttypes.StackDescription{
+					StackName:   aws.String(mockRealStackName),
+					StackStatus: sdktypes.StackStatusUpdateComplete,
+				}, nil)
+				cfnClient.EXPECT().DescribeChangeSet(gomock.Any(), expectedIn).Return(&clienttypes.ChangeSetDescription{
+					Arn:             mockChangeSetArn,
+					Status:          sdktypes.ChangeSetStatusCreateComplete,
+					ExecutionStatus: sdktypes.ExecutionStatusAvailable,
+					Changes:         changes,
+				}, nil)
+				cfnClient.EXPECT().DeleteChangeSet(gomock.Any(), expectedIn).Return(nil)
+			},
+		},
+		"delete the stack in review of a new stack in dry-run mode": {
+			wantedEvents: []*expectedEvent{{
+				eventType: "Normal",
+				severity:  "info",
+				message:   fmt.Sprintf("Planned changes for stack 'mock-real-stack' (change set %s): 1 to add, 0 to modify, 0 to remove", mockChangeSetArn),
+			}},
+			wantedRequeueDelay: mockIntervalDuration,
+			wantedStackStatus: plannedStatus(&cfnv1.StackPlan{
+				ChangeSet: mockChangeSetArn,
+				Revision:  mockSourceRevision,
+				Add:       1,
+				Changes: []cfnv1.PlannedResourceChange{
+					{Action: "Add", LogicalID: "Queue", ResourceType: "AWS::SQS::Queue"},
+				},
+			}),
+			dryRun:                true,
+			fillInSource:          generateMockGitRepoSource,
+			fillInInitialCfnStack: fillInInitialCfnStack("", inProgressStatus),
+			mockCfnClientCalls: func(cfnClient *clientmocks.MockCloudFormationClient) {
+				expectedIn := generateStackInput(mockGenerationId, mockChangeSetArn)
+				cfnClient.EXPECT().DescribeStack(gomock.Any(), expectedIn).Return(nil, &cloudformation.ErrStackNotFound{})
+				cfnClient.EXPECT().DescribeChangeSet(gomock.Any(), expectedIn).Return(&clienttypes.ChangeSetDescription{
+					Arn:             mockChangeSetArn,
+					Status:          sdktypes.ChangeSetStatusCreateComplete,
+					ExecutionStatus: sdktypes.ExecutionStatusAvailable,
+					Changes:         changes[:1],
+				}, nil)
+				cfnClient.EXPECT().DeleteStack(gomock.Any(), expectedIn).Return(nil)
+			},
+		},
+		"record an empty plan if the change set is empty": {
+			wantedEvents: []*expectedEvent{{
+				eventType: "Normal",
+				severity:  "info",
+				message:   fmt.Sprintf("Planned changes for stack 'mock-real-stack' (change set %s): 0 to add, 0 to modify, 0 to remove", mockChangeSetArn),
+			}},
+			wantedRequeueDelay: mockIntervalDuration,
+			wantedStackStatus: plannedStatus(&cfnv1.StackPlan{
+				ChangeSet: mockChangeSetArn,
+				Revision:  mockSourceRevision,
+			}),
+			fillInSource:          generateMockGitRepoSource,
+			fillInInitialCfnStack: fillInInitialCfnStack(cfnv1.PlanMode, inProgressStatus),
+			mockCfnClientCalls: func(cfnClient *clientmocks.MockCloudFormationClient) {
+				expectedIn := generateStackInput(mockGenerationId, mockChangeSetArn)
+				cfnClient.EXPECT().DescribeStack(gomock.Any(), expectedIn).Return(&clienttypes.StackDescription{
+					StackName:   aws.String(mockRealStackName),
+					StackStatus: sdktypes.StackStatusUpdateComplete,
+				}, nil)
+				cfnClient.EXPECT().DescribeChangeSet(gomock.Any(), expectedIn).Return(nil, &cloudformation.ErrChangeSetEmpty{Arn: mockChangeSetArn})
+				cfnClient.EXPECT().DeleteChangeSet(gomock.Any(), expectedIn).Return(nil)
+			},
+		},
+		"clear the last plan when the stack is deployed": {
+			wantedEvents: []*expectedEvent{{
+				eventType: "Normal",
+				severity:  "info",
+				message:   fmt.Sprintf("Change set execution started for stack 'mock-real-stack' (change set %s)", mockChangeSetArn),
+			}},
+			wantedRequeueDelay: mockPollIntervalDuration,
+			wantedStackStatus: &cfnv1.CloudFormationStackStatus{
+				ObservedGeneration:        mockGenerationId,
+				StackName:                 mockRealStackName,
+				LastAttemptedRevision:     mockSourceRevision,
+				LastAttemptedChangeSet:    mockChangeSetArn,
+				LastAttemptedSourceDigest: mockSourceDigest,
+				Conditions: []metav1.Condition{
+					{
+						Type:               "Ready",
+						Status:             "Unknown",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "Progressing",
+						Message:            fmt.Sprintf("Change set execution started for stack 'mock-real-stack' (change set %s)", mockChangeSetArn),
+					},
+					{
+						Type:               "Reconciling",
+						Status:             "True",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "Progressing",
+						Message:            fmt.Sprintf("Change set execution started for stack 'mock-real-stack' (change set %s)", mockChangeSetArn),
+					},
+				},
+			},
+			fillInSource:          generateMockGitRepoSource,
+			fillInInitialCfnStack: fillInInitialCfnStack(cfnv1.DeployMode, *plannedStatus(updatePlan)),
+			mockCfnClientCalls: func(cfnClient *clientmocks.MockCloudFormationClient) {
+				expectedIn := generateStackInput(mockGenerationId, mockChangeSetArn)
+				cfnClient.EXPECT().DescribeStack(gomock.Any(), expectedIn).Return(&clienttypes.StackDescription{
+					StackName:   aws.String(mockRealStackName),
+					StackStatus: sdktypes.StackStatusUpdateComplete,
+				}, nil)
+				cfnClient.EXPECT().DescribeChangeSet(gomock.Any(), expectedIn).Return(&clienttypes.ChangeSetDescription{
+					Arn:             mockChangeSetArn,
+					Status:          sdktypes.ChangeSetStatusCreateComplete,
+					ExecutionStatus: sdktypes.ExecutionStatusAvailable,
+					Changes:         changes,
+				}, nil)
+				cfnClient.EXPECT().ExecuteChangeSet(gomock.Any(), expectedIn).Return(nil)
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			runReconciliationLoopTestCase(t, tc)
+		})
+	}
+}
+
+func TestCfnController_Observe(t *testing.T) {
+	deleteTimestamp := metav1.NewTime(time.Now())
+
+	fillInInitialCfnStack := func(cfnStack *cfnv1.CloudFormationStack) {
+		cfnStack.Name = mockStackName
+		cfnStack.Namespace = mockNamespace
+		cfnStack.Generation = mockGenerationId
+		cfnStack.Spec = generateMockCfnStackSpec()
+		cfnStack.Spec.Mode = cfnv1.ObserveMode
+		cfnStack.Spec.SourceRef = nil
+	}
+	expectedIn := &clienttypes.Stack{Name: mockRealStackName}
+	resources := []clienttypes.StackResource{
+		{
+			LogicalResourceId:  aws.String("Topic"),
+			PhysicalResourceId: aws.String("arn:aws:sns:us-west-2:123456789012:topic"),
+			ResourceType:       aws.String("AWS::SNS::Topic"),
+			ResourceStatus:     sdktypes.ResourceStatusUpdateComplete,
+		},
+	}
+	statusResources := []cfnv1.StackResource{
+		{
+			LogicalID:    "Topic",
+			PhysicalID:   "arn:aws:sns:us-west-2:123456789012:topic",
+			ResourceType: "AWS::SNS::Topic",
+			Status:       "UPDATE_COMPLETE",
+		},
+	}
+
+	testCases := map[string]*reconciliationLoopTestCase{
+		"mirror the status, outputs and resources of an existing stack": {
+			wantedRequeueDelay: mockIntervalDuration,
+			wantedStackStatus: &cfnv1.CloudFormationStackStatus{
+				ObservedGeneration: mockGenerationId,
+				StackName:          mockRealStackName,
+				StackID:            "arn:aws:cloudformation:us-west-2:123456789012:stack/mock-real-stack/uuid",
+				StackStatus:        "UPDATE_COMPLETE",
+				Outputs: []cfnv1.StackOutput{
+					{Key: "TopicArn", Value: "arn:aws:sns:us-west-2:123456789012:topic", ExportName: "shared-topic"},
+				},
+				Resources: statusResources,
+				Conditions: []metav1.Condition{
+					{
+						Type:               "Ready",
+						Status:             "True",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "Succeeded",
+						Message:            "Stack reconciliation succeeded",
+					},
+				},
+			},
+			markStackAsInProgress: true,
+			fillInInitialCfnStack: fillInInitialCfnStack,
+			mockCfnClientCalls: func(cfnClient *clientmocks.MockCloudFormationClient) {
+				cfnClient.EXPECT().DescribeStack(gomock.Any(), expectedIn).Return(&clienttypes.StackDescription{
+					StackId:     aws.String("arn:aws:cloudformation:us-west-2:123456789012:stack/mock-real-stack/uuid"),
+					StackName:   aws.String(mockRealStackName),
+					StackStatus: sdktypes.StackStatusUpdateComplete,
+					Outputs: []sdktypes.Output{
+						{
+							OutputKey:   aws.String("TopicArn"),
+							OutputValue: aws.String("arn:aws:sns:us-west-2:123456789012:topic"),
+							ExportName:  aws.String("shared-topic"),
+						},
+					},
+				}, nil)
+				cfnClient.EXPECT().DescribeStackResources(gomock.Any(), expectedIn).Return(resources, nil)
+			},
+		},
+		"mark stack as progressing if the observed stack is in progress": {
+			wantedRequeueDelay: mockPollIntervalDuration,
+			wantedStackStatus: &cfnv1.CloudFormationStackStatus{
+				ObservedGeneration: mockGenerationId,
+				StackName:          mockRealStackName,
+				StackStatus:        "UPDATE_IN_PROGRESS",
+				Resources:          statusResources,
+				Conditions: []metav1.Condition{
+					{
+						Type:               "Ready",
+						Status:             "Unknown",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "Progressing",
+						Message:            "Stack action for observed stack 'mock-real-stack' is in progress (status: 'UPDATE_IN_PROGRESS')",
+					},
+					{
+						Type:               "Reconciling",
+						Status:             "True",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "Progressing",
+						Message:            "Stack action for observed stack 'mock-real-stack' is in progress (status: 'UPDATE_IN_PROGRESS')",
+					},
+				},
+			},
+			markStackAsInProgress: true,
+			fillInInitialCfnStack: fillInInitialCfnStack,
+			mockCfnClientCalls: func(cfnClient *clientmocks.MockCloudFormationClient) {
+				cfnClient.EXPECT().DescribeStack(gomock.Any(), expectedIn).Return(&clienttypes.StackDescription{
+					StackName:   aws.String(mockRealStackName),
+					StackStatus: sdktypes.StackStatusUpdateInProgress,
+				}, nil)
+				cfnClient.EXPECT().DescribeStackResources(gomock.Any(), expectedIn).Return(resources, nil)
+			},
+		},
+		"mark stack as not ready if the observed stack failed": {
+			wantedRequeueDelay: mockRetryIntervalDuration,
+			wantedStackStatus: &cfnv1.CloudFormationStackStatus{
+				ObservedGeneration: mockGenerationId,
+				StackName:          mockRealStackName,
+				StackStatus:        "UPDATE_ROLLBACK_FAILED",
+				Resources:          statusResources,
 				Conditions: []metav1.Condition{
 					{
 						Type:               "Ready",
 						Status:             "False",
 						ObservedGeneration: mockGenerationId,
-						Reason:             "TemplateUploadFailed",
-						Message:            "Failed to upload template to S3 for stack 'mock-real-stack'",
+						Reason:             "StackRollbackFailed",
+						Message:            "Observed stack 'mock-real-stack' is in a failed state (status 'UPDATE_ROLLBACK_FAILED', reason 'hello world')",
+					},
+					{
+						Type:               "Reconciling",
+						Status:             "True",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "ProgressingWithRetry",
+						Message:            "Observed stack 'mock-real-stack' is in a failed state (status 'UPDATE_ROLLBACK_FAILED', reason 'hello world')",
 					},
 				},
 			},
 			markStackAsInProgress: true,
-			fillInSource:          generateMockGitRepoSource,
+			fillInInitialCfnStack: fillInInitialCfnStack,
+			mockCfnClientCalls: func(cfnClient *clientmocks.MockCloudFormationClient) {
+				cfnClient.EXPECT().DescribeStack(gomock.Any(), expectedIn).Return(&clienttypes.StackDescription{
+					StackName:         aws.String(mockRealStackName),
+					StackStatus:       sdktypes.StackStatusUpdateRollbackFailed,
+					StackStatusReason: aws.String("hello world"),
+				}, nil)
+				cfnClient.EXPECT().DescribeStackResources(gomock.Any(), expectedIn).Return(resources, nil)
+			},
+		},
+		"mark stack as not ready if the observed stack does not exist": {
+			wantedRequeueDelay: mockRetryIntervalDuration,
+			wantedStackStatus: &cfnv1.CloudFormationStackStatus{
+				ObservedGeneration: mockGenerationId,
+				StackName:          mockRealStackName,
+				Conditions: []metav1.Condition{
+					{
+						Type:               "Ready",
+						Status:             "False",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "StackNotFound",
+						Message:            "Observed stack 'mock-real-stack' does not exist",
+					},
+					{
+						Type:               "Reconciling",
+						Status:             "True",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "ProgressingWithRetry",
+						Message:            "Observed stack 'mock-real-stack' does not exist",
+					},
+				},
+			},
+			markStackAsInProgress: true,
+			fillInInitialCfnStack: fillInInitialCfnStack,
+			mockCfnClientCalls: func(cfnClient *clientmocks.MockCloudFormationClient) {
+				cfnClient.EXPECT().DescribeStack(gomock.Any(), expectedIn).Return(nil, &cloudformation.ErrStackNotFound{})
+			},
+		},
+		"never destroy the observed stack on deletion": {
+			wantedStackStatus: &cfnv1.CloudFormationStackStatus{
+				ObservedGeneration: mockGenerationId,
+				StackName:          mockRealStackName,
+			},
+			removeFinalizers: true,
+			fillInInitialCfnStack: func(cfnStack *cfnv1.CloudFormationStack) {
+				fillInInitialCfnStack(cfnStack)
+				cfnStack.ObjectMeta.DeletionTimestamp = &deleteTimestamp
+				cfnStack.Spec.DestroyStackOnDeletion = true
+				cfnStack.Status = cfnv1.CloudFormationStackStatus{
+					ObservedGeneration: mockGenerationId,
+					StackName:          mockRealStackName,
+				}
+			},
+		},
+		"mark stack as not ready if a deployed stack has no source reference": {
+			wantedRequeueDelay: mockRetryIntervalDuration,
+			wantedStackStatus: &cfnv1.CloudFormationStackStatus{
+				ObservedGeneration: mockGenerationId,
+				StackName:          mockRealStackName,
+				Conditions: []metav1.Condition{
+					{
+						Type:               "Ready",
+						Status:             "False",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "ArtifactFailed",
+						Message:            "Failed to resolve source: the stack has no source reference, sourceRef is required unless the stack is in Observe mode",
+					},
+					{
+						Type:               "Stalled",
+						Status:             "True",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "ArtifactFailed",
+						Message:            "Failed to resolve source: the stack has no source reference, sourceRef is required unless the stack is in Observe mode",
+					},
+				},
+			},
+			markStackAsInProgress: true,
+			fillInInitialCfnStack: func(cfnStack *cfnv1.CloudFormationStack) {
+				fillInInitialCfnStack(cfnStack)
+				cfnStack.Spec.Mode = cfnv1.DeployMode
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			runReconciliationLoopTestCase(t, tc)
+		})
+	}
+}
+
+func TestCfnController_CDKAssembly(t *testing.T) {
+	sourceDigest := "sha256:188b6b138ea2970ee2293c7217790412622ec3ea44c800bc600543b58b188c13"
+	manifest := `{"artifacts": {
+  "MyStack.assets": {"type": "cdk:asset-manifest", "properties": {"file": "MyStack.assets.json"}},
+  "MyStack": {
+    "type": "aws:cloudformation:stack",
+    "properties": {"templateFile": "MyStack.template.json", "parameters": {"Environment": "prod", "Stage": "beta"}},
+    "dependencies": ["MyStack.assets"]
+  }
+}}`
+	assetManifest := `{"files": {
+  "1111": {"source": {"path": "asset.1111.txt", "packaging": "file"}, "destinations": {"current": {"objectKey": "1111.txt"}}},
+  "2222": {"source": {"path": "MyStack.template.json", "packaging": "file"}, "destinations": {"current": {"objectKey": "2222.json"}}}
+}}`
+	stackTemplate := `{"Resources": {"Topic": {"Type": "AWS::SNS::Topic"}}}`
+
+	files := map[string]string{
+		"cdk.out/manifest.json":         manifest,
+		"cdk.out/MyStack.assets.json":   assetManifest,
+		"cdk.out/MyStack.template.json": stackTemplate,
+		"cdk.out/asset.1111.txt":        "hello world",
+	}
+	artifact, checksum, err := createArtifact(files)
+	require.NoError(t, err)
+
+	// load the synthesized template, parameters and assets as the controller does, to name the change set
+	artifactDir := t.TempDir()
+	for name, contents := range files {
+		require.NoError(t, os.MkdirAll(filepath.Join(artifactDir, filepath.Dir(name)), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(artifactDir, name), []byte(contents), 0o600))
+	}
+	cdkTmpl, err := build.LoadTemplate(artifactDir, "", &cfnv1.CDKAssembly{Path: "cdk.out", StackID: "MyStack"}, nil)
+	require.NoError(t, err)
+
+	fillInSource := func(gitRepo *sourcev1.GitRepository, mockSourceArtifactURL string) {
+		generateMockGitRepoSource(gitRepo, mockSourceArtifactURL)
+		gitRepo.Status.Artifact.Digest = checksum
+	}
+	fillInInitialCfnStack := func(stackID string) func(cfnStack *cfnv1.CloudFormationStack) {
+		return func(cfnStack *cfnv1.CloudFormationStack) {
+			cfnStack.Name = mockStackName
+			cfnStack.Namespace = mockNamespace
+			cfnStack.Generation = mockGenerationId
+			cfnStack.Spec = generateMockCfnStackSpec()
+			cfnStack.Spec.CDKAssembly = &cfnv1.CDKAssembly{Path: "cdk.out", StackID: stackID}
+			cfnStack.Spec.StackParameters = []cfnv1.StackParameter{{Key: "Stage", Value: "gamma"}}
+		}
+	}
+	generateCDKStackInput := func() *clienttypes.Stack {
+		input := generateStackInput(mockGenerationId, "")
+		input.StackConfig.TemplateBody = stackTemplate
+		input.StackConfig.Parameters = []sdktypes.Parameter{
+			{ParameterKey: aws.String("Environment"), ParameterValue: aws.String("prod")},
+			{ParameterKey: aws.String("Stage"), ParameterValue: aws.String("gamma")},
+		}
+		setChangeSetName(input, mockGenerationId, cdkTmpl)
+		return input
+	}
+
+	testCases := map[string]*reconciliationLoopTestCase{
+		"upload file assets and create stack from the synthesized template": {
+			wantedEvents: []*expectedEvent{{
+				eventType: "Normal",
+				severity:  "info",
+				message:   fmt.Sprintf("Creation of stack 'mock-real-stack' in progress (change set %s)", mockChangeSetArn),
+			}},
+			wantedRequeueDelay: mockPollIntervalDuration,
+			wantedStackStatus: &cfnv1.CloudFormationStackStatus{
+				ObservedGeneration:        mockGenerationId,
+				StackName:                 mockRealStackName,
+				LastAttemptedRevision:     mockSourceRevision,
+				LastAttemptedChangeSet:    mockChangeSetArn,
+				LastAttemptedSourceDigest: sourceDigest,
+				Conditions: []metav1.Condition{
+					{
+						Type:               "Ready",
+						Status:             "Unknown",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "Progressing",
+						Message:            fmt.Sprintf("Creation of stack 'mock-real-stack' in progress (change set %s)", mockChangeSetArn),
+					},
+					{
+						Type:               "Reconciling",
+						Status:             "True",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "Progressing",
+						Message:            fmt.Sprintf("Creation of stack 'mock-real-stack' in progress (change set %s)", mockChangeSetArn),
+					},
+				},
+			},
+			markStackAsInProgress: true,
+			mockArtifactServer: func(t *testing.T) *httptest.Server {
+				return generateArtifactServer(t, artifact)
+			},
+			fillInSource:          fillInSource,
+			fillInInitialCfnStack: fillInInitialCfnStack("MyStack"),
 			mockS3ClientCalls: func(s3Client *clientmocks.MockS3Client) {
-				s3Client.EXPECT().UploadTemplate(
+				s3Client.EXPECT().HeadTemplate(gomock.Any(), mockTemplateUploadBucket, "", "1111.txt", &clienttypes.TemplateBucketOptions{}).Return(nil, &s3.ErrObjectNotFound{})
+				s3Client.EXPECT().UploadTemplate(gomock.Any(),
 					mockTemplateUploadBucket,
 					"",
-					gomock.Any(),
-					strings.NewReader(mockTemplateSourceFileContents),
-				).Return("", errors.New("template upload failed"))
-			},
-			fillInInitialCfnStack: func(cfnStack *cfnv1.CloudFormationStack) {
-				cfnStack.Name = mockStackName
-				cfnStack.Namespace = mockNamespace
-				cfnStack.Generation = mockGenerationId
-				cfnStack.Spec = generateMockCfnStackSpec()
+					"1111.txt",
+					strings.NewReader("hello world"),
+					&clienttypes.TemplateBucketOptions{},
+				).Return("https://mock-template-upload-bucket.s3.mock-region.amazonaws.com/1111.txt", nil)
+				s3Client.EXPECT().HeadTemplate(gomock.Any(), mockTemplateUploadBucket, "", "2222.json", &clienttypes.TemplateBucketOptions{}).Return(&clienttypes.TemplateObject{
+					Key:            "2222.json",
+					URL:            "https://mock-template-upload-bucket.s3.mock-region.amazonaws.com/2222.json",
+					MatchesOptions: true,
+				}, nil)
 			},
 			mockCfnClientCalls: func(cfnClient *clientmocks.MockCloudFormationClient) {
-				expectedDescribeStackIn := generateStackInput(mockGenerationId, mockSourceRevision, "")
-				cfnClient.EXPECT().DescribeStack(expectedDescribeStackIn).Return(nil, &cloudformation.ErrStackNotFound{})
-
-				expectedDescribeChangeSetIn := generateStackInput(mockGenerationId, mockSourceRevision, "")
-				cfnClient.EXPECT().DescribeChangeSet(expectedDescribeChangeSetIn).Return(nil, &cloudformation.ErrChangeSetNotFound{})
+				cfnClient.EXPECT().DescribeStack(gomock.Any(), generateCDKStackInput()).Return(nil, &cloudformation.ErrStackNotFound{})
+				cfnClient.EXPECT().DescribeChangeSet(gomock.Any(), generateCDKStackInput()).Return(nil, &cloudformation.ErrChangeSetNotFound{})
+				cfnClient.EXPECT().CreateStack(gomock.Any(), generateCDKStackInput()).Return(mockChangeSetArn, nil)
+			},
+		},
+		"mark stack as not ready if the stack is not in the cloud assembly": {
+			wantedRequeueDelay: mockRetryIntervalDuration,
+			wantedStackStatus: &cfnv1.CloudFormationStackStatus{
+				ObservedGeneration:    mockGenerationId,
+				StackName:             mockRealStackName,
+				LastAttemptedRevision: mockSourceRevision,
+				Conditions: []metav1.Condition{
+					{
+						Type:               "Ready",
+						Status:             "False",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "ArtifactFailed",
+						Message:            "Failed to load CDK stack 'OtherStack' of cloud assembly 'cdk.out' from source 'GitRepository/mock-namespace/mock-cfn-template-git-repo'",
+					},
+					{
+						Type:               "Stalled",
+						Status:             "True",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "ArtifactFailed",
+						Message:            "Failed to load CDK stack 'OtherStack' of cloud assembly 'cdk.out' from source 'GitRepository/mock-namespace/mock-cfn-template-git-repo'",
+					},
+				},
+			},
+			markStackAsInProgress: true,
+			mockArtifactServer: func(t *testing.T) *httptest.Server {
+				return generateArtifactServer(t, artifact)
 			},
+			fillInSource:          fillInSource,
+			fillInInitialCfnStack: fillInInitialCfnStack("OtherStack"),
 		},
 	}
 
@@ -2686,9 +5846,10 @@ func TestCfnController_CloudFormationFailures(t *testing.T) {
 			wantedEvents:       []*expectedEvent{apiFailureEvent},
 			wantedRequeueDelay: mockRetryIntervalDuration,
 			wantedStackStatus: &cfnv1.CloudFormationStackStatus{
-				ObservedGeneration:    mockGenerationId,
-				StackName:             mockRealStackName,
-				LastAttemptedRevision: mockSourceRevision,
+				ObservedGeneration:        mockGenerationId,
+				StackName:                 mockRealStackName,
+				LastAttemptedRevision:     mockSourceRevision,
+				LastAttemptedSourceDigest: mockSourceDigest,
 				Conditions: []metav1.Condition{
 					{
 						Type:               "Ready",
@@ -2697,6 +5858,55 @@ func TestCfnController_CloudFormationFailures(t *testing.T) {
 						Reason:             "CloudFormationApiCallFailed",
 						Message:            "Failed to describe the stack 'mock-real-stack'",
 					},
+					{
+						Type:               "Reconciling",
+						Status:             "True",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "ProgressingWithRetry",
+						Message:            "Failed to describe the stack 'mock-real-stack'",
+					},
+				},
+			},
+			markStackAsInProgress: true,
+			fillInSource:          generateMockGitRepoSource,
+			fillInInitialCfnStack: func(cfnStack *cfnv1.CloudFormationStack) {
+				cfnStack.Name = mockStackName
+				cfnStack.Namespace = mockNamespace
+				cfnStack.Generation = mockGenerationId
+				cfnStack.Spec = generateMockCfnStackSpec()
+			},
+			mockCfnClientCalls: func(cfnClient *clientmocks.MockCloudFormationClient) {
+				expectedDescribeStackIn := generateStackInput(mockGenerationId, "")
+				cfnClient.EXPECT().DescribeStack(gomock.Any(), expectedDescribeStackIn).Return(nil, expectedErr)
+			},
+		},
+		"mark stack as throttled and back off if DescribeStack is throttled": {
+			wantedEvents: []*expectedEvent{{
+				eventType: "Warning",
+				severity:  "error",
+				message:   "Failed to reconcile stack: describe stack mock-real-stack: api error Throttling: Rate exceeded",
+			}},
+			wantedRequeueDelay: mockRetryIntervalDuration,
+			wantedStackStatus: &cfnv1.CloudFormationStackStatus{
+				ObservedGeneration:        mockGenerationId,
+				StackName:                 mockRealStackName,
+				LastAttemptedRevision:     mockSourceRevision,
+				LastAttemptedSourceDigest: mockSourceDigest,
+				Conditions: []metav1.Condition{
+					{
+						Type:               "Ready",
+						Status:             "False",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "Throttled",
+						Message:            "AWS API calls for stack 'mock-real-stack' were throttled: describe stack mock-real-stack: api error Throttling: Rate exceeded",
+					},
+					{
+						Type:               "Reconciling",
+						Status:             "True",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "ProgressingWithRetry",
+						Message:            "AWS API calls for stack 'mock-real-stack' were throttled: describe stack mock-real-stack: api error Throttling: Rate exceeded",
+					},
 				},
 			},
 			markStackAsInProgress: true,
@@ -2708,8 +5918,9 @@ func TestCfnController_CloudFormationFailures(t *testing.T) {
 				cfnStack.Spec = generateMockCfnStackSpec()
 			},
 			mockCfnClientCalls: func(cfnClient *clientmocks.MockCloudFormationClient) {
-				expectedDescribeStackIn := generateStackInput(mockGenerationId, mockSourceRevision, "")
-				cfnClient.EXPECT().DescribeStack(expectedDescribeStackIn).Return(nil, expectedErr)
+				expectedDescribeStackIn := generateStackInput(mockGenerationId, "")
+				throttleErr := fmt.Errorf("describe stack %s: %w", mockRealStackName, &smithy.GenericAPIError{Code: "Throttling", Message: "Rate exceeded"})
+				cfnClient.EXPECT().DescribeStack(gomock.Any(), expectedDescribeStackIn).Return(nil, throttleErr)
 			},
 		},
 		"mark stack as not ready if DescribeStack fails during stack deletion": {
@@ -2729,6 +5940,13 @@ func TestCfnController_CloudFormationFailures(t *testing.T) {
 						Reason:             "CloudFormationApiCallFailed",
 						Message:            "Failed to describe the stack 'mock-real-stack'",
 					},
+					{
+						Type:               "Reconciling",
+						Status:             "True",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "ProgressingWithRetry",
+						Message:            "Failed to describe the stack 'mock-real-stack'",
+					},
 				},
 			},
 			fillInInitialCfnStack: func(cfnStack *cfnv1.CloudFormationStack) {
@@ -2751,13 +5969,20 @@ func TestCfnController_CloudFormationFailures(t *testing.T) {
 							Reason:             "Progressing",
 							Message:            "Hello world",
 						},
+						{
+							Type:               "Reconciling",
+							Status:             "True",
+							ObservedGeneration: mockGenerationId,
+							Reason:             "Progressing",
+							Message:            "Hello world",
+						},
 					},
 				}
 			},
 			mockCfnClientCalls: func(cfnClient *clientmocks.MockCloudFormationClient) {
-				expectedDescribeStackIn := generateStackInput(mockGenerationId, mockSourceRevision, mockChangeSetArn)
+				expectedDescribeStackIn := generateStackInput(mockGenerationId, mockChangeSetArn)
 				expectedDescribeStackIn.StackConfig = nil
-				cfnClient.EXPECT().DescribeStack(expectedDescribeStackIn).Return(nil, expectedErr)
+				cfnClient.EXPECT().DescribeStack(gomock.Any(), expectedDescribeStackIn).Return(nil, expectedErr)
 			},
 		},
 		"mark stack as not ready if DescribeChangeSet fails": {
@@ -2765,9 +5990,10 @@ func TestCfnController_CloudFormationFailures(t *testing.T) {
 			wantedEvents:       []*expectedEvent{apiFailureEvent},
 			wantedRequeueDelay: mockRetryIntervalDuration,
 			wantedStackStatus: &cfnv1.CloudFormationStackStatus{
-				ObservedGeneration:    mockGenerationId,
-				StackName:             mockRealStackName,
-				LastAttemptedRevision: mockSourceRevision,
+				ObservedGeneration:        mockGenerationId,
+				StackName:                 mockRealStackName,
+				LastAttemptedRevision:     mockSourceRevision,
+				LastAttemptedSourceDigest: mockSourceDigest,
 				Conditions: []metav1.Condition{
 					{
 						Type:               "Ready",
@@ -2776,6 +6002,13 @@ func TestCfnController_CloudFormationFailures(t *testing.T) {
 						Reason:             "CloudFormationApiCallFailed",
 						Message:            "Failed to describe a change set for stack 'mock-real-stack'",
 					},
+					{
+						Type:               "Reconciling",
+						Status:             "True",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "ProgressingWithRetry",
+						Message:            "Failed to describe a change set for stack 'mock-real-stack'",
+					},
 				},
 			},
 			markStackAsInProgress: true,
@@ -2787,11 +6020,11 @@ func TestCfnController_CloudFormationFailures(t *testing.T) {
 				cfnStack.Spec = generateMockCfnStackSpec()
 			},
 			mockCfnClientCalls: func(cfnClient *clientmocks.MockCloudFormationClient) {
-				expectedDescribeStackIn := generateStackInput(mockGenerationId, mockSourceRevision, "")
-				cfnClient.EXPECT().DescribeStack(expectedDescribeStackIn).Return(nil, &cloudformation.ErrStackNotFound{})
+				expectedDescribeStackIn := generateStackInput(mockGenerationId, "")
+				cfnClient.EXPECT().DescribeStack(gomock.Any(), expectedDescribeStackIn).Return(nil, &cloudformation.ErrStackNotFound{})
 
-				expectedDescribeChangeSetIn := generateStackInput(mockGenerationId, mockSourceRevision, "")
-				cfnClient.EXPECT().DescribeChangeSet(expectedDescribeChangeSetIn).Return(nil, expectedErr)
+				expectedDescribeChangeSetIn := generateStackInput(mockGenerationId, "")
+				cfnClient.EXPECT().DescribeChangeSet(gomock.Any(), expectedDescribeChangeSetIn).Return(nil, expectedErr)
 			},
 		},
 		"mark stack as not ready if CreateStack fails": {
@@ -2799,9 +6032,10 @@ func TestCfnController_CloudFormationFailures(t *testing.T) {
 			wantedEvents:       []*expectedEvent{apiFailureEvent},
 			wantedRequeueDelay: mockRetryIntervalDuration,
 			wantedStackStatus: &cfnv1.CloudFormationStackStatus{
-				ObservedGeneration:    mockGenerationId,
-				StackName:             mockRealStackName,
-				LastAttemptedRevision: mockSourceRevision,
+				ObservedGeneration:        mockGenerationId,
+				StackName:                 mockRealStackName,
+				LastAttemptedRevision:     mockSourceRevision,
+				LastAttemptedSourceDigest: mockSourceDigest,
 				Conditions: []metav1.Condition{
 					{
 						Type:               "Ready",
@@ -2810,6 +6044,13 @@ func TestCfnController_CloudFormationFailures(t *testing.T) {
 						Reason:             "CloudFormationApiCallFailed",
 						Message:            "Failed to create a change set for stack 'mock-real-stack'",
 					},
+					{
+						Type:               "Reconciling",
+						Status:             "True",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "ProgressingWithRetry",
+						Message:            "Failed to create a change set for stack 'mock-real-stack'",
+					},
 				},
 			},
 			markStackAsInProgress: true,
@@ -2820,16 +6061,15 @@ func TestCfnController_CloudFormationFailures(t *testing.T) {
 				cfnStack.Generation = mockGenerationId
 				cfnStack.Spec = generateMockCfnStackSpec()
 			},
-			mockS3ClientCalls: mockS3ClientUpload,
 			mockCfnClientCalls: func(cfnClient *clientmocks.MockCloudFormationClient) {
-				expectedDescribeStackIn := generateStackInput(mockGenerationId, mockSourceRevision, "")
-				cfnClient.EXPECT().DescribeStack(expectedDescribeStackIn).Return(nil, &cloudformation.ErrStackNotFound{})
+				expectedDescribeStackIn := generateStackInput(mockGenerationId, "")
+				cfnClient.EXPECT().DescribeStack(gomock.Any(), expectedDescribeStackIn).Return(nil, &cloudformation.ErrStackNotFound{})
 
-				expectedDescribeChangeSetIn := generateStackInput(mockGenerationId, mockSourceRevision, "")
-				cfnClient.EXPECT().DescribeChangeSet(expectedDescribeChangeSetIn).Return(nil, &cloudformation.ErrChangeSetNotFound{})
+				expectedDescribeChangeSetIn := generateStackInput(mockGenerationId, "")
+				cfnClient.EXPECT().DescribeChangeSet(gomock.Any(), expectedDescribeChangeSetIn).Return(nil, &cloudformation.ErrChangeSetNotFound{})
 
-				expectedCreateStackIn := generateStackInputWithTemplateUrl(mockGenerationId, mockSourceRevision)
-				cfnClient.EXPECT().CreateStack(expectedCreateStackIn).Return("", expectedErr)
+				expectedCreateStackIn := generateStackInput(mockGenerationId, "")
+				cfnClient.EXPECT().CreateStack(gomock.Any(), expectedCreateStackIn).Return("", expectedErr)
 			},
 		},
 		"mark stack as not ready if UpdateStack fails": {
@@ -2837,12 +6077,13 @@ func TestCfnController_CloudFormationFailures(t *testing.T) {
 			wantedEvents:       []*expectedEvent{apiFailureEvent},
 			wantedRequeueDelay: mockRetryIntervalDuration,
 			wantedStackStatus: &cfnv1.CloudFormationStackStatus{
-				ObservedGeneration:     mockGenerationId2,
-				StackName:              mockRealStackName,
-				LastAttemptedRevision:  mockSourceRevision,
-				LastAppliedRevision:    mockSourceRevision,
-				LastAttemptedChangeSet: mockChangeSetArn,
-				LastAppliedChangeSet:   mockChangeSetArn,
+				ObservedGeneration:        mockGenerationId2,
+				StackName:                 mockRealStackName,
+				LastAttemptedRevision:     mockSourceRevision,
+				LastAttemptedSourceDigest: mockSourceDigest,
+				LastAppliedRevision:       mockSourceRevision,
+				LastAttemptedChangeSet:    mockChangeSetArn,
+				LastAppliedChangeSet:      mockChangeSetArn,
 				Conditions: []metav1.Condition{
 					{
 						Type:               "Ready",
@@ -2851,6 +6092,13 @@ func TestCfnController_CloudFormationFailures(t *testing.T) {
 						Reason:             "CloudFormationApiCallFailed",
 						Message:            "Failed to create a change set for stack 'mock-real-stack'",
 					},
+					{
+						Type:               "Reconciling",
+						Status:             "True",
+						ObservedGeneration: mockGenerationId2,
+						Reason:             "ProgressingWithRetry",
+						Message:            "Failed to create a change set for stack 'mock-real-stack'",
+					},
 				},
 			},
 			fillInSource: generateMockGitRepoSource,
@@ -2878,20 +6126,19 @@ func TestCfnController_CloudFormationFailures(t *testing.T) {
 				}
 			},
 			markStackAsInProgress: true,
-			mockS3ClientCalls:     mockS3ClientUpload,
 			mockCfnClientCalls: func(cfnClient *clientmocks.MockCloudFormationClient) {
-				expectedDescribeStackIn := generateStackInput(mockGenerationId2, mockSourceRevision, "")
-				cfnClient.EXPECT().DescribeStack(expectedDescribeStackIn).Return(&clienttypes.StackDescription{
+				expectedDescribeStackIn := generateStackInput(mockGenerationId2, "")
+				cfnClient.EXPECT().DescribeStack(gomock.Any(), expectedDescribeStackIn).Return(&clienttypes.StackDescription{
 					StackName:         aws.String(mockRealStackName),
 					StackStatus:       sdktypes.StackStatusCreateComplete,
 					StackStatusReason: aws.String("hello world"),
 				}, nil)
 
-				expectedDescribeChangeSetIn := generateStackInput(mockGenerationId2, mockSourceRevision, "")
-				cfnClient.EXPECT().DescribeChangeSet(expectedDescribeChangeSetIn).Return(nil, &cloudformation.ErrChangeSetNotFound{})
+				expectedDescribeChangeSetIn := generateStackInput(mockGenerationId2, "")
+				cfnClient.EXPECT().DescribeChangeSet(gomock.Any(), expectedDescribeChangeSetIn).Return(nil, &cloudformation.ErrChangeSetNotFound{})
 
-				expectedUpdateStackIn := generateStackInputWithTemplateUrl(mockGenerationId2, mockSourceRevision)
-				cfnClient.EXPECT().UpdateStack(expectedUpdateStackIn).Return("", expectedErr)
+				expectedUpdateStackIn := generateStackInput(mockGenerationId2, "")
+				cfnClient.EXPECT().UpdateStack(gomock.Any(), expectedUpdateStackIn).Return("", expectedErr)
 			},
 		},
 		"mark stack as not ready if ContinueStackRollback fails": {
@@ -2899,15 +6146,23 @@ func TestCfnController_CloudFormationFailures(t *testing.T) {
 			wantedEvents:       []*expectedEvent{apiFailureEvent},
 			wantedRequeueDelay: mockRetryIntervalDuration,
 			wantedStackStatus: &cfnv1.CloudFormationStackStatus{
-				ObservedGeneration:    mockGenerationId,
-				StackName:             mockRealStackName,
-				LastAttemptedRevision: mockSourceRevision,
+				ObservedGeneration:        mockGenerationId,
+				StackName:                 mockRealStackName,
+				LastAttemptedRevision:     mockSourceRevision,
+				LastAttemptedSourceDigest: mockSourceDigest,
 				Conditions: []metav1.Condition{
 					{
 						Type:               "Ready",
 						Status:             "False",
 						ObservedGeneration: mockGenerationId,
-						Reason:             "CloudFormationApiCallFailed",
+						Reason:             "CloudFormationApiCallFailed",
+						Message:            "Failed to continue a failed rollback for stack 'mock-real-stack'",
+					},
+					{
+						Type:               "Reconciling",
+						Status:             "True",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "ProgressingWithRetry",
 						Message:            "Failed to continue a failed rollback for stack 'mock-real-stack'",
 					},
 				},
@@ -2921,13 +6176,13 @@ func TestCfnController_CloudFormationFailures(t *testing.T) {
 				cfnStack.Spec = generateMockCfnStackSpec()
 			},
 			mockCfnClientCalls: func(cfnClient *clientmocks.MockCloudFormationClient) {
-				expectedDescribeStackIn := generateStackInput(mockGenerationId, mockSourceRevision, "")
-				cfnClient.EXPECT().DescribeStack(expectedDescribeStackIn).Return(&clienttypes.StackDescription{
+				expectedDescribeStackIn := generateStackInput(mockGenerationId, "")
+				cfnClient.EXPECT().DescribeStack(gomock.Any(), expectedDescribeStackIn).Return(&clienttypes.StackDescription{
 					StackName:   aws.String(mockRealStackName),
 					StackStatus: sdktypes.StackStatusUpdateRollbackFailed,
 				}, nil)
 
-				cfnClient.EXPECT().ContinueStackRollback(expectedDescribeStackIn).Return(expectedErr)
+				cfnClient.EXPECT().ContinueStackRollback(gomock.Any(), expectedDescribeStackIn).Return(expectedErr)
 			},
 		},
 		"mark stack as not ready if DeleteStack fails": {
@@ -2935,9 +6190,10 @@ func TestCfnController_CloudFormationFailures(t *testing.T) {
 			wantedEvents:       []*expectedEvent{apiFailureEvent},
 			wantedRequeueDelay: mockRetryIntervalDuration,
 			wantedStackStatus: &cfnv1.CloudFormationStackStatus{
-				ObservedGeneration:    mockGenerationId,
-				StackName:             mockRealStackName,
-				LastAttemptedRevision: mockSourceRevision,
+				ObservedGeneration:        mockGenerationId,
+				StackName:                 mockRealStackName,
+				LastAttemptedRevision:     mockSourceRevision,
+				LastAttemptedSourceDigest: mockSourceDigest,
 				Conditions: []metav1.Condition{
 					{
 						Type:               "Ready",
@@ -2946,6 +6202,13 @@ func TestCfnController_CloudFormationFailures(t *testing.T) {
 						Reason:             "CloudFormationApiCallFailed",
 						Message:            "Failed to delete the failed stack 'mock-real-stack'",
 					},
+					{
+						Type:               "Reconciling",
+						Status:             "True",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "ProgressingWithRetry",
+						Message:            "Failed to delete the failed stack 'mock-real-stack'",
+					},
 				},
 			},
 			markStackAsInProgress: true,
@@ -2957,14 +6220,14 @@ func TestCfnController_CloudFormationFailures(t *testing.T) {
 				cfnStack.Spec = generateMockCfnStackSpec()
 			},
 			mockCfnClientCalls: func(cfnClient *clientmocks.MockCloudFormationClient) {
-				expectedDescribeStackIn := generateStackInput(mockGenerationId, mockSourceRevision, "")
-				cfnClient.EXPECT().DescribeStack(expectedDescribeStackIn).Return(&clienttypes.StackDescription{
+				expectedDescribeStackIn := generateStackInput(mockGenerationId, "")
+				cfnClient.EXPECT().DescribeStack(gomock.Any(), expectedDescribeStackIn).Return(&clienttypes.StackDescription{
 					StackName:         aws.String(mockRealStackName),
 					StackStatus:       sdktypes.StackStatusCreateFailed,
 					StackStatusReason: aws.String("hello world"),
 				}, nil)
 
-				cfnClient.EXPECT().DeleteStack(expectedDescribeStackIn).Return(expectedErr)
+				cfnClient.EXPECT().DeleteStack(gomock.Any(), expectedDescribeStackIn).Return(expectedErr)
 			},
 		},
 		"mark stack as not ready if DeleteStack fails during stack deletion": {
@@ -2984,6 +6247,13 @@ func TestCfnController_CloudFormationFailures(t *testing.T) {
 						Reason:             "CloudFormationApiCallFailed",
 						Message:            "Failed to delete the stack 'mock-real-stack'",
 					},
+					{
+						Type:               "Reconciling",
+						Status:             "True",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "ProgressingWithRetry",
+						Message:            "Failed to delete the stack 'mock-real-stack'",
+					},
 				},
 			},
 			fillInInitialCfnStack: func(cfnStack *cfnv1.CloudFormationStack) {
@@ -3010,15 +6280,15 @@ func TestCfnController_CloudFormationFailures(t *testing.T) {
 				}
 			},
 			mockCfnClientCalls: func(cfnClient *clientmocks.MockCloudFormationClient) {
-				expectedDescribeStackIn := generateStackInput(mockGenerationId, mockSourceRevision, mockChangeSetArn)
+				expectedDescribeStackIn := generateStackInput(mockGenerationId, mockChangeSetArn)
 				expectedDescribeStackIn.StackConfig = nil
-				cfnClient.EXPECT().DescribeStack(expectedDescribeStackIn).Return(&clienttypes.StackDescription{
+				cfnClient.EXPECT().DescribeStack(gomock.Any(), expectedDescribeStackIn).Return(&clienttypes.StackDescription{
 					StackName:         aws.String(mockRealStackName),
 					StackStatus:       sdktypes.StackStatusCreateComplete,
 					StackStatusReason: aws.String("hello world"),
 				}, nil)
 
-				cfnClient.EXPECT().DeleteStack(expectedDescribeStackIn).Return(expectedErr)
+				cfnClient.EXPECT().DeleteStack(gomock.Any(), expectedDescribeStackIn).Return(expectedErr)
 			},
 		},
 		"mark stack as not ready if DeleteChangeSet fails for an empty change set": {
@@ -3026,12 +6296,13 @@ func TestCfnController_CloudFormationFailures(t *testing.T) {
 			wantedEvents:       []*expectedEvent{apiFailureEvent},
 			wantedRequeueDelay: mockRetryIntervalDuration,
 			wantedStackStatus: &cfnv1.CloudFormationStackStatus{
-				ObservedGeneration:     mockGenerationId2,
-				StackName:              mockRealStackName,
-				LastAttemptedRevision:  mockSourceRevision2,
-				LastAppliedRevision:    mockSourceRevision,
-				LastAttemptedChangeSet: mockChangeSetArnNewSourceRevisionAndNewGeneration,
-				LastAppliedChangeSet:   mockChangeSetArn,
+				ObservedGeneration:        mockGenerationId2,
+				StackName:                 mockRealStackName,
+				LastAttemptedRevision:     mockSourceRevision2,
+				LastAttemptedSourceDigest: mockSourceDigest,
+				LastAppliedRevision:       mockSourceRevision,
+				LastAttemptedChangeSet:    mockChangeSetArnNewGeneration,
+				LastAppliedChangeSet:      mockChangeSetArn,
 				Conditions: []metav1.Condition{
 					{
 						Type:               "Ready",
@@ -3040,6 +6311,13 @@ func TestCfnController_CloudFormationFailures(t *testing.T) {
 						Reason:             "CloudFormationApiCallFailed",
 						Message:            "Failed to delete an empty change set for stack 'mock-real-stack'",
 					},
+					{
+						Type:               "Reconciling",
+						Status:             "True",
+						ObservedGeneration: mockGenerationId2,
+						Reason:             "ProgressingWithRetry",
+						Message:            "Failed to delete an empty change set for stack 'mock-real-stack'",
+					},
 				},
 			},
 			fillInSource: generateMockGitRepoSource2,
@@ -3053,7 +6331,7 @@ func TestCfnController_CloudFormationFailures(t *testing.T) {
 					StackName:              mockRealStackName,
 					LastAttemptedRevision:  mockSourceRevision2,
 					LastAppliedRevision:    mockSourceRevision,
-					LastAttemptedChangeSet: mockChangeSetArnNewSourceRevisionAndNewGeneration,
+					LastAttemptedChangeSet: mockChangeSetArnNewGeneration,
 					LastAppliedChangeSet:   mockChangeSetArn,
 					Conditions: []metav1.Condition{
 						{
@@ -3063,22 +6341,29 @@ func TestCfnController_CloudFormationFailures(t *testing.T) {
 							Reason:             "Hello",
 							Message:            "World",
 						},
+						{
+							Type:               "Reconciling",
+							Status:             "True",
+							ObservedGeneration: mockGenerationId2,
+							Reason:             "Progressing",
+							Message:            "World",
+						},
 					},
 				}
 			},
 			markStackAsInProgress: false,
 			mockCfnClientCalls: func(cfnClient *clientmocks.MockCloudFormationClient) {
-				expectedDescribeStackIn := generateStackInput(mockGenerationId2, mockSourceRevision2, mockChangeSetArnNewSourceRevisionAndNewGeneration)
-				cfnClient.EXPECT().DescribeStack(expectedDescribeStackIn).Return(&clienttypes.StackDescription{
+				expectedDescribeStackIn := generateStackInput(mockGenerationId2, mockChangeSetArnNewGeneration)
+				cfnClient.EXPECT().DescribeStack(gomock.Any(), expectedDescribeStackIn).Return(&clienttypes.StackDescription{
 					StackName:         aws.String(mockRealStackName),
 					StackStatus:       sdktypes.StackStatusCreateComplete,
 					StackStatusReason: aws.String("hello world"),
 				}, nil)
 
-				expectedDescribeChangeSetIn := generateStackInput(mockGenerationId2, mockSourceRevision2, mockChangeSetArnNewSourceRevisionAndNewGeneration)
-				cfnClient.EXPECT().DescribeChangeSet(expectedDescribeChangeSetIn).Return(nil, &cloudformation.ErrChangeSetEmpty{})
+				expectedDescribeChangeSetIn := generateStackInput(mockGenerationId2, mockChangeSetArnNewGeneration)
+				cfnClient.EXPECT().DescribeChangeSet(gomock.Any(), expectedDescribeChangeSetIn).Return(nil, &cloudformation.ErrChangeSetEmpty{})
 
-				cfnClient.EXPECT().DeleteChangeSet(expectedDescribeChangeSetIn).Return(expectedErr)
+				cfnClient.EXPECT().DeleteChangeSet(gomock.Any(), expectedDescribeChangeSetIn).Return(expectedErr)
 			},
 		},
 		"mark stack as not ready if DeleteChangeSet fails for failed change set": {
@@ -3086,10 +6371,11 @@ func TestCfnController_CloudFormationFailures(t *testing.T) {
 			wantedEvents:       []*expectedEvent{apiFailureEvent},
 			wantedRequeueDelay: mockRetryIntervalDuration,
 			wantedStackStatus: &cfnv1.CloudFormationStackStatus{
-				ObservedGeneration:     mockGenerationId,
-				StackName:              mockRealStackName,
-				LastAttemptedRevision:  mockSourceRevision,
-				LastAttemptedChangeSet: mockChangeSetArn,
+				ObservedGeneration:        mockGenerationId,
+				StackName:                 mockRealStackName,
+				LastAttemptedRevision:     mockSourceRevision,
+				LastAttemptedSourceDigest: mockSourceDigest,
+				LastAttemptedChangeSet:    mockChangeSetArn,
 				Conditions: []metav1.Condition{
 					{
 						Type:               "Ready",
@@ -3098,6 +6384,13 @@ func TestCfnController_CloudFormationFailures(t *testing.T) {
 						Reason:             "CloudFormationApiCallFailed",
 						Message:            "Failed to delete a failed change set for stack 'mock-real-stack'",
 					},
+					{
+						Type:               "Reconciling",
+						Status:             "True",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "ProgressingWithRetry",
+						Message:            "Failed to delete a failed change set for stack 'mock-real-stack'",
+					},
 				},
 			},
 			markStackAsInProgress: false,
@@ -3120,26 +6413,33 @@ func TestCfnController_CloudFormationFailures(t *testing.T) {
 							Reason:             "Progressing",
 							Message:            "Hello world",
 						},
+						{
+							Type:               "Reconciling",
+							Status:             "True",
+							ObservedGeneration: mockGenerationId,
+							Reason:             "Progressing",
+							Message:            "Hello world",
+						},
 					},
 				}
 			},
 			mockCfnClientCalls: func(cfnClient *clientmocks.MockCloudFormationClient) {
-				expectedDescribeStackIn := generateStackInput(mockGenerationId, mockSourceRevision, mockChangeSetArn)
-				cfnClient.EXPECT().DescribeStack(expectedDescribeStackIn).Return(&clienttypes.StackDescription{
+				expectedDescribeStackIn := generateStackInput(mockGenerationId, mockChangeSetArn)
+				cfnClient.EXPECT().DescribeStack(gomock.Any(), expectedDescribeStackIn).Return(&clienttypes.StackDescription{
 					StackName:         aws.String(mockRealStackName),
 					StackStatus:       sdktypes.StackStatusCreateComplete,
 					StackStatusReason: aws.String("hello world"),
 				}, nil)
 
-				expectedDescribeChangeSetIn := generateStackInput(mockGenerationId, mockSourceRevision, mockChangeSetArn)
-				cfnClient.EXPECT().DescribeChangeSet(expectedDescribeChangeSetIn).Return(&clienttypes.ChangeSetDescription{
+				expectedDescribeChangeSetIn := generateStackInput(mockGenerationId, mockChangeSetArn)
+				cfnClient.EXPECT().DescribeChangeSet(gomock.Any(), expectedDescribeChangeSetIn).Return(&clienttypes.ChangeSetDescription{
 					Arn:             mockChangeSetArn,
 					Status:          sdktypes.ChangeSetStatusFailed,
 					ExecutionStatus: sdktypes.ExecutionStatusUnavailable,
 					StatusReason:    "hello world",
 				}, nil)
 
-				cfnClient.EXPECT().DeleteChangeSet(expectedDescribeChangeSetIn).Return(expectedErr)
+				cfnClient.EXPECT().DeleteChangeSet(gomock.Any(), expectedDescribeChangeSetIn).Return(expectedErr)
 			},
 		},
 		"mark the stack as not ready if ExecuteChangeSet fails": {
@@ -3147,12 +6447,13 @@ func TestCfnController_CloudFormationFailures(t *testing.T) {
 			wantedEvents:       []*expectedEvent{apiFailureEvent},
 			wantedRequeueDelay: mockRetryIntervalDuration,
 			wantedStackStatus: &cfnv1.CloudFormationStackStatus{
-				ObservedGeneration:     mockGenerationId2,
-				StackName:              mockRealStackName,
-				LastAttemptedRevision:  mockSourceRevision2,
-				LastAppliedRevision:    mockSourceRevision,
-				LastAttemptedChangeSet: mockChangeSetArnNewSourceRevisionAndNewGeneration,
-				LastAppliedChangeSet:   mockChangeSetArn,
+				ObservedGeneration:        mockGenerationId2,
+				StackName:                 mockRealStackName,
+				LastAttemptedRevision:     mockSourceRevision2,
+				LastAttemptedSourceDigest: mockSourceDigest,
+				LastAppliedRevision:       mockSourceRevision,
+				LastAttemptedChangeSet:    mockChangeSetArnNewGeneration,
+				LastAppliedChangeSet:      mockChangeSetArn,
 				Conditions: []metav1.Condition{
 					{
 						Type:               "Ready",
@@ -3161,6 +6462,13 @@ func TestCfnController_CloudFormationFailures(t *testing.T) {
 						Reason:             "CloudFormationApiCallFailed",
 						Message:            "Failed to execute a change set for stack 'mock-real-stack'",
 					},
+					{
+						Type:               "Reconciling",
+						Status:             "True",
+						ObservedGeneration: mockGenerationId2,
+						Reason:             "ProgressingWithRetry",
+						Message:            "Failed to execute a change set for stack 'mock-real-stack'",
+					},
 				},
 			},
 			fillInSource: generateMockGitRepoSource2,
@@ -3174,7 +6482,7 @@ func TestCfnController_CloudFormationFailures(t *testing.T) {
 					StackName:              mockRealStackName,
 					LastAttemptedRevision:  mockSourceRevision2,
 					LastAppliedRevision:    mockSourceRevision,
-					LastAttemptedChangeSet: mockChangeSetArnNewSourceRevisionAndNewGeneration,
+					LastAttemptedChangeSet: mockChangeSetArnNewGeneration,
 					LastAppliedChangeSet:   mockChangeSetArn,
 					Conditions: []metav1.Condition{
 						{
@@ -3184,26 +6492,387 @@ func TestCfnController_CloudFormationFailures(t *testing.T) {
 							Reason:             "Hello",
 							Message:            "World",
 						},
+						{
+							Type:               "Reconciling",
+							Status:             "True",
+							ObservedGeneration: mockGenerationId2,
+							Reason:             "Progressing",
+							Message:            "World",
+						},
 					},
 				}
 			},
 			markStackAsInProgress: false,
 			mockCfnClientCalls: func(cfnClient *clientmocks.MockCloudFormationClient) {
-				expectedDescribeStackIn := generateStackInput(mockGenerationId2, mockSourceRevision2, mockChangeSetArnNewSourceRevisionAndNewGeneration)
-				cfnClient.EXPECT().DescribeStack(expectedDescribeStackIn).Return(&clienttypes.StackDescription{
+				expectedDescribeStackIn := generateStackInput(mockGenerationId2, mockChangeSetArnNewGeneration)
+				cfnClient.EXPECT().DescribeStack(gomock.Any(), expectedDescribeStackIn).Return(&clienttypes.StackDescription{
 					StackName:         aws.String(mockRealStackName),
 					StackStatus:       sdktypes.StackStatusCreateComplete,
 					StackStatusReason: aws.String("hello world"),
 				}, nil)
 
-				expectedDescribeChangeSetIn := generateStackInput(mockGenerationId2, mockSourceRevision2, mockChangeSetArnNewSourceRevisionAndNewGeneration)
-				cfnClient.EXPECT().DescribeChangeSet(expectedDescribeChangeSetIn).Return(&clienttypes.ChangeSetDescription{
-					Arn:             mockChangeSetArnNewSourceRevisionAndNewGeneration,
+				expectedDescribeChangeSetIn := generateStackInput(mockGenerationId2, mockChangeSetArnNewGeneration)
+				cfnClient.EXPECT().DescribeChangeSet(gomock.Any(), expectedDescribeChangeSetIn).Return(&clienttypes.ChangeSetDescription{
+					Arn:             mockChangeSetArnNewGeneration,
 					Status:          sdktypes.ChangeSetStatusCreateComplete,
 					ExecutionStatus: sdktypes.ExecutionStatusAvailable,
 				}, nil)
 
-				cfnClient.EXPECT().ExecuteChangeSet(expectedDescribeChangeSetIn).Return(expectedErr)
+				cfnClient.EXPECT().ExecuteChangeSet(gomock.Any(), expectedDescribeChangeSetIn).Return(expectedErr)
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			runReconciliationLoopTestCase(t, tc)
+		})
+	}
+}
+
+// Flux Kustomizations with 'wait: true' and other kstatus-based tools compute the health of a stack from its conditions:
+// a Kustomization waits for InProgress stacks, succeeds when all the stacks are Current, and fails when a stack is Failed.
+func TestCfnController_Kstatus(t *testing.T) {
+	transientFailure := cfnv1.ReadinessUpdate{Message: "Failed to describe stack", Reason: cfnv1.CloudFormationApiCallFailedReason}
+	stalledFailure := cfnv1.ReadinessUpdate{Message: "Stack violates policies", Reason: cfnv1.PolicyViolationReason, Stalled: true}
+
+	testCases := map[string]struct {
+		updateStack  func(cfnStack cfnv1.CloudFormationStack) cfnv1.CloudFormationStack
+		wantedStatus kstatus.Status
+	}{
+		"new stack is in progress": {
+			updateStack: func(cfnStack cfnv1.CloudFormationStack) cfnv1.CloudFormationStack {
+				// The API server defaults the observed generation of new stacks
+				cfnStack.Status.ObservedGeneration = -1
+				return cfnStack
+			},
+			wantedStatus: kstatus.InProgressStatus,
+		},
+		"progressing stack is in progress": {
+			updateStack: func(cfnStack cfnv1.CloudFormationStack) cfnv1.CloudFormationStack {
+				return cfnv1.CloudFormationStackProgressing(cfnStack, cfnv1.ReadinessUpdate{Message: "Stack reconciliation in progress"})
+			},
+			wantedStatus: kstatus.InProgressStatus,
+		},
+		"stack with a transient failure is in progress": {
+			updateStack: func(cfnStack cfnv1.CloudFormationStack) cfnv1.CloudFormationStack {
+				cfnStack = cfnv1.CloudFormationStackProgressing(cfnStack, cfnv1.ReadinessUpdate{Message: "Stack reconciliation in progress"})
+				return cfnv1.CloudFormationStackNotReady(cfnStack, transientFailure)
+			},
+			wantedStatus: kstatus.InProgressStatus,
+		},
+		"stalled stack is failed": {
+			updateStack: func(cfnStack cfnv1.CloudFormationStack) cfnv1.CloudFormationStack {
+				cfnStack = cfnv1.CloudFormationStackProgressing(cfnStack, cfnv1.ReadinessUpdate{Message: "Stack reconciliation in progress"})
+				return cfnv1.CloudFormationStackNotReady(cfnStack, stalledFailure)
+			},
+			wantedStatus: kstatus.FailedStatus,
+		},
+		"stalled stack is in progress again after a spec change": {
+			updateStack: func(cfnStack cfnv1.CloudFormationStack) cfnv1.CloudFormationStack {
+				cfnStack = cfnv1.CloudFormationStackNotReady(cfnStack, stalledFailure)
+				cfnStack.Generation++
+				return cfnv1.CloudFormationStackProgressing(cfnStack, cfnv1.ReadinessUpdate{Message: "Stack reconciliation in progress"})
+			},
+			wantedStatus: kstatus.InProgressStatus,
+		},
+		"ready stack is current": {
+			updateStack: func(cfnStack cfnv1.CloudFormationStack) cfnv1.CloudFormationStack {
+				cfnStack = cfnv1.CloudFormationStackNotReady(cfnStack, stalledFailure)
+				cfnStack = cfnv1.CloudFormationStackProgressing(cfnStack, cfnv1.ReadinessUpdate{Message: "Stack reconciliation in progress"})
+				return cfnv1.CloudFormationStackReady(cfnStack, mockChangeSetArn)
+			},
+			wantedStatus: kstatus.CurrentStatus,
+		},
+		"planned stack is current": {
+			updateStack: func(cfnStack cfnv1.CloudFormationStack) cfnv1.CloudFormationStack {
+				cfnStack = cfnv1.CloudFormationStackProgressing(cfnStack, cfnv1.ReadinessUpdate{Message: "Planning of stack in progress"})
+				return cfnv1.CloudFormationStackPlanned(cfnStack, cfnv1.StackPlan{ChangeSet: mockChangeSetArn, Revision: mockSourceRevision})
+			},
+			wantedStatus: kstatus.CurrentStatus,
+		},
+		"ready stack is in progress until the controller observes its new generation": {
+			updateStack: func(cfnStack cfnv1.CloudFormationStack) cfnv1.CloudFormationStack {
+				cfnStack = cfnv1.CloudFormationStackReady(cfnStack, mockChangeSetArn)
+				cfnStack.Generation++
+				return cfnStack
+			},
+			wantedStatus: kstatus.InProgressStatus,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			cfnStack := cfnv1.CloudFormationStack{
+				TypeMeta: metav1.TypeMeta{
+					APIVersion: cfnv1.GroupVersion.String(),
+					Kind:       cfnv1.CloudFormationStackKind,
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:       mockStackName,
+					Namespace:  mockNamespace,
+					Generation: mockGenerationId,
+				},
+				Spec: generateMockCfnStackSpec(),
+			}
+			cfnStack = tc.updateStack(cfnStack)
+
+			obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&cfnStack)
+			require.NoError(t, err)
+			result, err := kstatus.Compute(&unstructured.Unstructured{Object: obj})
+			require.NoError(t, err)
+			require.Equal(t, tc.wantedStatus, result.Status, result.Message)
+		})
+	}
+}
+
+// requireKstatus computes the kstatus of the stack object, like Flux Kustomizations do to wait for
+// or health check the objects they apply, and requires it to be the wanted status
+func requireKstatus(t *testing.T, wanted kstatus.Status, cfnStack *cfnv1.CloudFormationStack) {
+	cfnStack = cfnStack.DeepCopy()
+	cfnStack.APIVersion = cfnv1.GroupVersion.String()
+	cfnStack.Kind = cfnv1.CloudFormationStackKind
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(cfnStack)
+	require.NoError(t, err)
+	result, err := kstatus.Compute(&unstructured.Unstructured{Object: obj})
+	require.NoError(t, err)
+	require.Equal(t, wanted, result.Status, result.Message)
+}
+
+func TestCfnController_KstatusReconcile(t *testing.T) {
+	newCfnStack := func(cfnStack *cfnv1.CloudFormationStack) {
+		cfnStack.Name = mockStackName
+		cfnStack.Namespace = mockNamespace
+		cfnStack.Generation = mockGenerationId
+		cfnStack.Spec = generateMockCfnStackSpec()
+	}
+	creationMsg := fmt.Sprintf("Creation of stack 'mock-real-stack' in progress (change set %s)", mockChangeSetArn)
+	artifactFailedMsg := "Failed to load template 'template.yaml' from source 'GitRepository/mock-namespace/mock-cfn-template-git-repo'"
+	stalledMsg := "Failed to load template 'does-not-exist.yaml' from source 'GitRepository/mock-namespace/mock-cfn-template-git-repo'"
+
+	// The stack is up-to-date with its last applied change set, and its new generation does not change its inputs
+	appliedChangeSetName := generateAppliedChangeSetName(mockGenerationId2)
+	appliedStackInput := generateStackInput(mockGenerationId2, "")
+	appliedStackInput.ChangeSetName = appliedChangeSetName
+	readyStatus := func(generation int64) *cfnv1.CloudFormationStackStatus {
+		return &cfnv1.CloudFormationStackStatus{
+			ObservedGeneration:         generation,
+			StackName:                  mockRealStackName,
+			LastAttemptedRevision:      mockSourceRevision,
+			LastAppliedRevision:        mockSourceRevision,
+			LastAttemptedChangeSet:     mockChangeSetArnNewGeneration,
+			LastAppliedChangeSet:       mockChangeSetArnNewGeneration,
+			LastAttemptedSourceDigest:  mockSourceDigest,
+			LastAppliedConfigDigest:    mockConfigDigest,
+			LastAppliedStackUpdateTime: &mockAppliedStackUpdateTime,
+			Conditions: []metav1.Condition{
+				{
+					Type:               "Ready",
+					Status:             "True",
+					ObservedGeneration: generation,
+					Reason:             "Succeeded",
+					Message:            "Stack reconciliation succeeded",
+				},
+			},
+		}
+	}
+
+	testCases := map[string]*reconciliationLoopTestCase{
+		"stack is in progress while it is created": {
+			wantedEvents: []*expectedEvent{{
+				eventType: "Normal",
+				severity:  "info",
+				message:   creationMsg,
+			}},
+			wantedRequeueDelay: mockPollIntervalDuration,
+			wantedStackStatus: &cfnv1.CloudFormationStackStatus{
+				ObservedGeneration:        mockGenerationId,
+				StackName:                 mockRealStackName,
+				LastAttemptedRevision:     mockSourceRevision,
+				LastAttemptedChangeSet:    mockChangeSetArn,
+				LastAttemptedSourceDigest: mockSourceDigest,
+				Conditions: []metav1.Condition{
+					{
+						Type:               "Ready",
+						Status:             "Unknown",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "Progressing",
+						Message:            creationMsg,
+					},
+					{
+						Type:               "Reconciling",
+						Status:             "True",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "Progressing",
+						Message:            creationMsg,
+					},
+				},
+			},
+			wantedKstatus:         kstatus.InProgressStatus,
+			markStackAsInProgress: true,
+			fillInSource:          generateMockGitRepoSource,
+			fillInInitialCfnStack: newCfnStack,
+			mockCfnClientCalls: func(cfnClient *clientmocks.MockCloudFormationClient) {
+				expectedIn := generateStackInput(mockGenerationId, "")
+				cfnClient.EXPECT().DescribeStack(gomock.Any(), expectedIn).Return(nil, &cloudformation.ErrStackNotFound{})
+				cfnClient.EXPECT().DescribeChangeSet(gomock.Any(), expectedIn).Return(nil, &cloudformation.ErrChangeSetNotFound{})
+				cfnClient.EXPECT().CreateStack(gomock.Any(), expectedIn).Return(mockChangeSetArn, nil)
+			},
+		},
+		"stack with a transient failure is in progress": {
+			wantedErr:          fmt.Errorf("failed to download artifact, status code: 404 Not Found"),
+			wantedRequeueDelay: mockRetryIntervalDuration,
+			wantedStackStatus: &cfnv1.CloudFormationStackStatus{
+				ObservedGeneration:    mockGenerationId,
+				StackName:             mockRealStackName,
+				LastAttemptedRevision: mockSourceRevision,
+				Conditions: []metav1.Condition{
+					{
+						Type:               "Ready",
+						Status:             "False",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "ArtifactFailed",
+						Message:            artifactFailedMsg,
+					},
+					{
+						Type:               "Reconciling",
+						Status:             "True",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "ProgressingWithRetry",
+						Message:            artifactFailedMsg,
+					},
+				},
+			},
+			wantedKstatus:         kstatus.InProgressStatus,
+			markStackAsInProgress: true,
+			mockArtifactServer: func(t *testing.T) *httptest.Server {
+				return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.WriteHeader(http.StatusNotFound)
+				}))
+			},
+			fillInSource:          generateMockGitRepoSource,
+			fillInInitialCfnStack: newCfnStack,
+		},
+		"stalled stack is failed": {
+			wantedRequeueDelay: mockRetryIntervalDuration,
+			wantedStackStatus: &cfnv1.CloudFormationStackStatus{
+				ObservedGeneration:    mockGenerationId,
+				StackName:             mockRealStackName,
+				LastAttemptedRevision: mockSourceRevision,
+				Conditions: []metav1.Condition{
+					{
+						Type:               "Ready",
+						Status:             "False",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "ArtifactFailed",
+						Message:            stalledMsg,
+					},
+					{
+						Type:               "Stalled",
+						Status:             "True",
+						ObservedGeneration: mockGenerationId,
+						Reason:             "ArtifactFailed",
+						Message:            stalledMsg,
+					},
+				},
+			},
+			wantedKstatus:         kstatus.FailedStatus,
+			markStackAsInProgress: true,
+			fillInSource:          generateMockGitRepoSource,
+			fillInInitialCfnStack: func(cfnStack *cfnv1.CloudFormationStack) {
+				newCfnStack(cfnStack)
+				cfnStack.Spec.TemplatePath = "does-not-exist.yaml"
+			},
+		},
+		"ready stack is current": {
+			wantedRequeueDelay: mockIntervalDuration,
+			wantedStackStatus: &cfnv1.CloudFormationStackStatus{
+				ObservedGeneration:         mockGenerationId2,
+				StackName:                  mockRealStackName,
+				LastAttemptedRevision:      mockSourceRevision2,
+				LastAttemptedSourceDigest:  mockSourceDigest,
+				LastAppliedRevision:        mockSourceRevision2,
+				LastAttemptedChangeSet:     mockChangeSetArnNewGeneration,
+				LastAppliedChangeSet:       mockChangeSetArnNewGeneration,
+				LastAppliedConfigDigest:    mockConfigDigest,
+				LastAppliedStackUpdateTime: &mockAppliedStackUpdateTime,
+				Conditions: []metav1.Condition{
+					{
+						Type:               "Ready",
+						Status:             "True",
+						ObservedGeneration: mockGenerationId2,
+						Reason:             "Succeeded",
+						Message:            "Stack reconciliation succeeded",
+					},
+				},
+			},
+			wantedInitialKstatus: kstatus.InProgressStatus,
+			wantedKstatus:        kstatus.CurrentStatus,
+			fillInSource:         generateMockGitRepoSource2,
+			fillInInitialCfnStack: func(cfnStack *cfnv1.CloudFormationStack) {
+				cfnStack.Name = mockStackName
+				cfnStack.Namespace = mockNamespace
+				cfnStack.Generation = mockGenerationId2
+				cfnStack.Spec = generateMockCfnStackSpec()
+				cfnStack.Status = cfnv1.CloudFormationStackStatus{
+					ObservedGeneration:     mockGenerationId2,
+					StackName:              mockRealStackName,
+					LastAttemptedRevision:  mockSourceRevision2,
+					LastAppliedRevision:    mockSourceRevision,
+					LastAttemptedChangeSet: mockChangeSetArnNewGeneration,
+					LastAppliedChangeSet:   mockChangeSetArn,
+					Conditions: []metav1.Condition{
+						{
+							Type:               "Ready",
+							Status:             "Unknown",
+							ObservedGeneration: mockGenerationId2,
+							Reason:             "Progressing",
+							Message:            "Change set execution started",
+						},
+						{
+							Type:               "Reconciling",
+							Status:             "True",
+							ObservedGeneration: mockGenerationId2,
+							Reason:             "Progressing",
+							Message:            "Change set execution started",
+						},
+					},
+				}
+			},
+			mockCfnClientCalls: func(cfnClient *clientmocks.MockCloudFormationClient) {
+				expectedIn := generateStackInput(mockGenerationId2, mockChangeSetArnNewGeneration)
+				cfnClient.EXPECT().DescribeStack(gomock.Any(), expectedIn).Return(&clienttypes.StackDescription{
+					StackName:       aws.String(mockRealStackName),
+					StackStatus:     sdktypes.StackStatusUpdateComplete,
+					LastUpdatedTime: aws.Time(mockStackUpdateTime),
+				}, nil)
+				cfnClient.EXPECT().DescribeChangeSet(gomock.Any(), expectedIn).Return(&clienttypes.ChangeSetDescription{
+					Arn:             mockChangeSetArnNewGeneration,
+					Status:          sdktypes.ChangeSetStatusCreateComplete,
+					ExecutionStatus: sdktypes.ExecutionStatusExecuteComplete,
+				}, nil)
+			},
+		},
+		"ready stack is in progress until the controller observes its new generation": {
+			wantedRequeueDelay:    mockIntervalDuration,
+			wantedStackStatus:     readyStatus(mockGenerationId2),
+			wantedInitialKstatus:  kstatus.InProgressStatus,
+			wantedKstatus:         kstatus.CurrentStatus,
+			markStackAsInProgress: true,
+			fillInSource:          generateMockGitRepoSource,
+			fillInInitialCfnStack: func(cfnStack *cfnv1.CloudFormationStack) {
+				cfnStack.Name = mockStackName
+				cfnStack.Namespace = mockNamespace
+				cfnStack.Generation = mockGenerationId2
+				cfnStack.Spec = generateMockCfnStackSpec()
+				cfnStack.Status = *readyStatus(mockGenerationId)
+			},
+			mockCfnClientCalls: func(cfnClient *clientmocks.MockCloudFormationClient) {
+				cfnClient.EXPECT().DescribeStack(gomock.Any(), appliedStackInput).Return(&clienttypes.StackDescription{
+					StackName:       aws.String(mockRealStackName),
+					StackStatus:     sdktypes.StackStatusUpdateComplete,
+					LastUpdatedTime: aws.Time(mockStackUpdateTime),
+				}, nil)
 			},
 		},
 	}
@@ -3214,3 +6883,93 @@ func TestCfnController_CloudFormationFailures(t *testing.T) {
 		})
 	}
 }
+
+func TestCfnController_EnqueueStackStatusChange(t *testing.T) {
+	mockStackID := "arn:aws:cloudformation:us-west-2:123456789012:stack/mock-real-stack/uuid"
+	newStack := func(name, stackName, stackID string) *cfnv1.CloudFormationStack {
+		return &cfnv1.CloudFormationStack{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: mockNamespace},
+			Spec:       cfnv1.CloudFormationStackSpec{StackName: stackName},
+			Status:     cfnv1.CloudFormationStackStatus{StackID: stackID},
+		}
+	}
+	kubeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(newStack(mockStackName, mockRealStackName, mockStackID), newStack("other-stack", "other-real-stack", "")).
+		WithIndex(&cfnv1.CloudFormationStack{}, cfnv1.StackIDIndexKey, indexByStackID).
+		WithIndex(&cfnv1.CloudFormationStack{}, cfnv1.StackNameIndexKey, indexByStackName).
+		Build()
+	reconciler := &CloudFormationStackReconciler{
+		Client:      kubeClient,
+		Region:      "us-west-2",
+		Account:     "123456789012",
+		stackEvents: make(chan event.GenericEvent, 10),
+	}
+	ctx := context.Background()
+
+	// The CloudFormationStack of the stack is enqueued by its recorded stack ID
+	require.NoError(t, reconciler.EnqueueStackStatusChange(ctx, stackevents.StackStatusChange{
+		StackID:   mockStackID,
+		StackName: mockRealStackName,
+		Region:    "us-west-2",
+		Account:   "123456789012",
+		Status:    "UPDATE_COMPLETE",
+	}))
+	require.Len(t, reconciler.stackEvents, 1)
+	enqueued := <-reconciler.stackEvents
+	require.Equal(t, mockStackNamespacedName, ctrlclient.ObjectKeyFromObject(enqueued.Object))
+
+	// The CloudFormationStack of a stack without a recorded stack ID is enqueued by its stack name
+	require.NoError(t, reconciler.EnqueueStackStatusChange(ctx, stackevents.StackStatusChange{
+		StackID:   "arn:aws:cloudformation:us-west-2:123456789012:stack/other-real-stack/uuid",
+		StackName: "other-real-stack",
+		Region:    "us-west-2",
+		Account:   "123456789012",
+		Status:    "CREATE_COMPLETE",
+	}))
+	require.Len(t, reconciler.stackEvents, 1)
+	enqueued = <-reconciler.stackEvents
+	require.Equal(t, types.NamespacedName{Namespace: mockNamespace, Name: "other-stack"}, ctrlclient.ObjectKeyFromObject(enqueued.Object))
+
+	// Stacks that are not managed by the controller, other stacks with the same name as a stack with a recorded
+	// stack ID, and stacks in other accounts or regions are ignored
+	for _, change := range []stackevents.StackStatusChange{
+		{
+			StackID:   "arn:aws:cloudformation:us-west-2:123456789012:stack/unknown-stack/uuid",
+			StackName: "unknown-stack",
+			Region:    "us-west-2",
+			Account:   "123456789012",
+		},
+		{
+			StackID:   "arn:aws:cloudformation:us-west-2:123456789012:stack/mock-real-stack/other-uuid",
+			StackName: mockRealStackName,
+			Region:    "us-west-2",
+			Account:   "123456789012",
+		},
+		{
+			StackID:   "arn:aws:cloudformation:us-west-2:210987654321:stack/other-real-stack/uuid",
+			StackName: "other-real-stack",
+			Region:    "us-west-2",
+			Account:   "210987654321",
+		},
+		{
+			StackID:   "arn:aws:cloudformation:us-east-1:123456789012:stack/other-real-stack/uuid",
+			StackName: "other-real-stack",
+			Region:    "us-east-1",
+			Account:   "123456789012",
+		},
+	} {
+		change.Status = "UPDATE_COMPLETE"
+		require.NoError(t, reconciler.EnqueueStackStatusChange(ctx, change))
+	}
+	require.Empty(t, reconciler.stackEvents)
+
+	// Stack status change events must be enabled
+	reconciler.stackEvents = nil
+	require.Error(t, reconciler.EnqueueStackStatusChange(ctx, stackevents.StackStatusChange{
+		StackID:   mockStackID,
+		StackName: mockRealStackName,
+		Region:    "us-west-2",
+		Account:   "123456789012",
+	}))
+}
@@ -0,0 +1,579 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT-0
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	securejoin "github.com/cyphar/filepath-securejoin"
+	"github.com/hashicorp/go-retryablehttp"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	kuberecorder "k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/yaml"
+
+	eventv1 "github.com/fluxcd/pkg/apis/event/v1beta1"
+	"github.com/fluxcd/pkg/apis/meta"
+	runtimeCtrl "github.com/fluxcd/pkg/runtime/controller"
+	"github.com/fluxcd/pkg/runtime/predicates"
+	sourcev1 "github.com/fluxcd/source-controller/api/v1"
+	sourcev1b2 "github.com/fluxcd/source-controller/api/v1beta2"
+
+	cfnv1 "github.com/awslabs/aws-cloudformation-controller-for-flux/api/v1alpha1"
+	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/artifact"
+	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/substitute"
+)
+
+//+kubebuilder:rbac:groups=cloudformation.contrib.fluxcd.io,resources=cloudformationstackgenerators,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=cloudformation.contrib.fluxcd.io,resources=cloudformationstackgenerators/status,verbs=get;update;patch
+
+// CloudFormationStackGeneratorReconciler reconciles a CloudFormationStackGenerator object
+type CloudFormationStackGeneratorReconciler struct {
+	client.Client
+	runtimeCtrl.Metrics
+
+	Scheme              *runtime.Scheme
+	EventRecorder       kuberecorder.EventRecorder
+	NoCrossNamespaceRef bool
+
+	ControllerName string
+	// ArtifactCache caches the source artifacts of the elements files on disk.
+	ArtifactCache *artifact.Cache
+
+	httpClient *retryablehttp.Client
+}
+
+type CloudFormationStackGeneratorReconcilerOptions struct {
+	HTTPRetry int
+}
+
+func (r *CloudFormationStackGeneratorReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, opts CloudFormationStackGeneratorReconcilerOptions) error {
+	// Index the CloudFormationStackGenerators by the sources that their elements are loaded from
+	if err := mgr.GetCache().IndexField(ctx, &cfnv1.CloudFormationStackGenerator{}, cfnv1.GitRepositoryIndexKey,
+		r.IndexBy(sourcev1.GitRepositoryKind)); err != nil {
+		return fmt.Errorf("failed setting index fields: %w", err)
+	}
+	if err := mgr.GetCache().IndexField(ctx, &cfnv1.CloudFormationStackGenerator{}, cfnv1.BucketIndexKey,
+		r.IndexBy(sourcev1b2.BucketKind)); err != nil {
+		return fmt.Errorf("failed setting index fields: %w", err)
+	}
+	if err := mgr.GetCache().IndexField(ctx, &cfnv1.CloudFormationStackGenerator{}, cfnv1.OCIRepositoryIndexKey,
+		r.IndexBy(sourcev1b2.OCIRepositoryKind)); err != nil {
+		return fmt.Errorf("failed setting index fields: %w", err)
+	}
+
+	// Configure the retryable http client for retrieving artifacts.
+	httpClient := retryablehttp.NewClient()
+	httpClient.RetryWaitMin = 5 * time.Second
+	httpClient.RetryWaitMax = 30 * time.Second
+	httpClient.RetryMax = opts.HTTPRetry
+	httpClient.Logger = nil
+	r.httpClient = httpClient
+
+	// Watch for generator changes, changes to the generated stack objects, and source object changes
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&cfnv1.CloudFormationStackGenerator{}, builder.WithPredicates(
+			predicate.Or(predicate.GenerationChangedPredicate{}, predicates.ReconcileRequestedPredicate{}),
+		)).
+		Owns(&cfnv1.CloudFormationStack{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(
+			&sourcev1.GitRepository{},
+			handler.EnqueueRequestsFromMapFunc(r.requestsForRevisionChangeOf(cfnv1.GitRepositoryIndexKey)),
+			builder.WithPredicates(SourceRevisionChangePredicate{}),
+		).
+		Watches(
+			&sourcev1b2.Bucket{},
+			handler.EnqueueRequestsFromMapFunc(r.requestsForRevisionChangeOf(cfnv1.BucketIndexKey)),
+			builder.WithPredicates(SourceRevisionChangePredicate{}),
+		).
+		Watches(
+			&sourcev1b2.OCIRepository{},
+			handler.EnqueueRequestsFromMapFunc(r.requestsForRevisionChangeOf(cfnv1.OCIRepositoryIndexKey)),
+			builder.WithPredicates(SourceRevisionChangePredicate{}),
+		).
+		WithOptions(controller.Options{}).
+		Complete(r)
+}
+
+func (r *CloudFormationStackGeneratorReconciler) IndexBy(kind string) func(o client.Object) []string {
+	return func(o client.Object) []string {
+		generator := o.(*cfnv1.CloudFormationStackGenerator)
+		var keys []string
+		for _, reference := range generator.Spec.ElementsFrom {
+			if reference.Kind == kind {
+				namespace := generator.GetNamespace()
+				// default to the generator's namespace
+				if reference.Namespace != "" {
+					namespace = reference.Namespace
+				}
+				keys = append(keys, fmt.Sprintf("%s/%s", namespace, reference.Name))
+			}
+		}
+		return keys
+	}
+}
+
+func (r *CloudFormationStackGeneratorReconciler) requestsForRevisionChangeOf(indexKey string) func(ctx context.Context, obj client.Object) []reconcile.Request {
+	return func(ctx context.Context, obj client.Object) []reconcile.Request {
+		log := ctrl.LoggerFrom(ctx)
+
+		var list cfnv1.CloudFormationStackGeneratorList
+		if err := r.List(ctx, &list, client.MatchingFields{
+			indexKey: client.ObjectKeyFromObject(obj).String(),
+		}); err != nil {
+			log.Error(err, "failed to list CloudFormation stack generators")
+			return nil
+		}
+		var reqs []reconcile.Request
+		for _, d := range list.Items {
+			reqs = append(reqs, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&d)})
+		}
+		return reqs
+	}
+}
+
+func (r *CloudFormationStackGeneratorReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	start := time.Now()
+	log := ctrl.LoggerFrom(ctx)
+
+	var generator cfnv1.CloudFormationStackGenerator
+	if err := r.Get(ctx, req.NamespacedName, &generator); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	defer func() {
+		// Always record metrics.
+		r.Metrics.RecordSuspend(ctx, &generator, generator.Spec.Suspend)
+		r.Metrics.RecordReadiness(ctx, &generator)
+		r.Metrics.RecordDuration(ctx, &generator, start)
+	}()
+
+	// The generated stack objects are garbage collected through their owner references,
+	// so there is nothing to clean up when the generator is deleted
+	if !generator.ObjectMeta.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+
+	// Check if the CloudFormationStackGenerator is suspended
+	if generator.Spec.Suspend {
+		log.Info("Reconciliation is suspended for this object")
+		return ctrl.Result{}, nil
+	}
+
+	// Reconcile
+	generator, result, err := r.reconcile(ctx, generator)
+
+	// Update status
+	if updateStatusErr := r.patchStatus(ctx, &generator); updateStatusErr != nil {
+		log.Error(updateStatusErr, "Unable to update status after reconciliation")
+		return ctrl.Result{Requeue: true}, updateStatusErr
+	}
+
+	// Log reconciliation duration
+	durationMsg := fmt.Sprintf("Reconciliation loop finished in %s", time.Now().Sub(start).String())
+	if result.RequeueAfter > 0 {
+		durationMsg = fmt.Sprintf("%s, next run in %s", durationMsg, result.RequeueAfter.String())
+	}
+	log.Info(durationMsg)
+
+	return result, err
+}
+
+// reconcile generates a CloudFormationStack object for each element, and prunes the objects that no longer match an element.
+func (r *CloudFormationStackGeneratorReconciler) reconcile(ctx context.Context, generator cfnv1.CloudFormationStackGenerator) (cfnv1.CloudFormationStackGenerator, ctrl.Result, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	// Record the value of the reconciliation request, if any
+	if v, ok := meta.ReconcileAnnotationValue(generator.GetAnnotations()); ok {
+		generator.Status.SetLastHandledReconcileRequest(v)
+	}
+
+	// Observe CloudFormationStackGenerator generation.
+	if generator.Status.ObservedGeneration != generator.Generation {
+		generator = cfnv1.CloudFormationStackGeneratorProgressing(generator, "Stack generation in progress")
+		if updateStatusErr := r.patchStatus(ctx, &generator); updateStatusErr != nil {
+			log.Error(updateStatusErr, "Unable to update status after generation update")
+			return generator, ctrl.Result{Requeue: true}, updateStatusErr
+		}
+	}
+
+	// Load the elements
+	elements, err := r.loadElements(ctx, generator)
+	if err != nil {
+		msg := fmt.Sprintf("Failed to load elements: %s", err.Error())
+		log.Error(err, "Failed to load elements")
+		r.event(ctx, generator, eventv1.EventSeverityError, msg)
+		generator = cfnv1.CloudFormationStackGeneratorNotReady(generator, cfnv1.ElementsFailedReason, msg)
+		return generator, ctrl.Result{RequeueAfter: generator.GetRetryInterval()}, nil
+	}
+
+	// Generate the stack objects
+	var stacks []*cfnv1.CloudFormationStack
+	names := map[string]bool{}
+	for i, element := range elements {
+		stack, err := generateStack(generator, element)
+		if err == nil && names[stack.Name] {
+			err = fmt.Errorf("the name '%s' is generated for more than one element", stack.Name)
+		}
+		if err != nil {
+			msg := fmt.Sprintf("Failed to generate stack for element %d: %s", i, err.Error())
+			log.Error(err, "Failed to generate stack")
+			r.event(ctx, generator, eventv1.EventSeverityError, msg)
+			generator = cfnv1.CloudFormationStackGeneratorNotReady(generator, cfnv1.GenerateFailedReason, msg)
+			return generator, ctrl.Result{RequeueAfter: generator.GetRetryInterval()}, nil
+		}
+		names[stack.Name] = true
+		stacks = append(stacks, stack)
+	}
+
+	// Create or update the stack objects
+	var generated []string
+	for _, stack := range stacks {
+		if err := r.applyStack(ctx, generator, stack); err != nil {
+			msg := fmt.Sprintf("Failed to apply CloudFormationStack '%s': %s", stack.Name, err.Error())
+			log.Error(err, "Failed to apply stack")
+			r.event(ctx, generator, eventv1.EventSeverityError, msg)
+			generator = cfnv1.CloudFormationStackGeneratorNotReady(generator, cfnv1.GenerateFailedReason, msg)
+			return generator, ctrl.Result{RequeueAfter: generator.GetRetryInterval()}, err
+		}
+		generated = append(generated, stack.Name)
+	}
+	sort.Strings(generated)
+
+	// Prune the stack objects that are no longer generated
+	if generator.Spec.Prune {
+		if err := r.pruneStacks(ctx, generator, names); err != nil {
+			msg := fmt.Sprintf("Failed to prune CloudFormationStack objects: %s", err.Error())
+			log.Error(err, "Failed to prune stacks")
+			r.event(ctx, generator, eventv1.EventSeverityError, msg)
+			generator = cfnv1.CloudFormationStackGeneratorNotReady(generator, cfnv1.GenerateFailedReason, msg)
+			return generator, ctrl.Result{RequeueAfter: generator.GetRetryInterval()}, err
+		}
+	}
+
+	// Success!
+	generator.Status.GeneratedStacks = generated
+	msg := fmt.Sprintf("Generated %d CloudFormationStack objects", len(generated))
+	log.Info(msg)
+	return cfnv1.CloudFormationStackGeneratorReady(generator, msg), ctrl.Result{RequeueAfter: generator.Spec.Interval.Duration}, nil
+}
+
+// applyStack creates the generated stack object, or updates it if it already exists and is owned by the generator.
+func (r *CloudFormationStackGeneratorReconciler) applyStack(ctx context.Context, generator cfnv1.CloudFormationStackGenerator, generated *cfnv1.CloudFormationStack) error {
+	log := ctrl.LoggerFrom(ctx)
+
+	stack := &cfnv1.CloudFormationStack{
+		ObjectMeta: metav1.ObjectMeta{Name: generated.Name, Namespace: generated.Namespace},
+	}
+	result, err := controllerutil.CreateOrUpdate(ctx, r.Client, stack, func() error {
+		if stack.ResourceVersion != "" && !metav1.IsControlledBy(stack, &generator) {
+			return fmt.Errorf("the object already exists and is not owned by the generator")
+		}
+		if stack.Labels == nil {
+			stack.Labels = map[string]string{}
+		}
+		for key, value := range generated.Labels {
+			stack.Labels[key] = value
+		}
+		if len(generated.Annotations) > 0 && stack.Annotations == nil {
+			stack.Annotations = map[string]string{}
+		}
+		for key, value := range generated.Annotations {
+			stack.Annotations[key] = value
+		}
+		stack.Spec = generated.Spec
+		return controllerutil.SetControllerReference(&generator, stack, r.Scheme)
+	})
+	if err != nil {
+		return err
+	}
+
+	switch result {
+	case controllerutil.OperationResultCreated:
+		msg := fmt.Sprintf("Created CloudFormationStack '%s'", stack.Name)
+		log.Info(msg)
+		r.event(ctx, generator, eventv1.EventSeverityInfo, msg)
+	case controllerutil.OperationResultUpdated:
+		msg := fmt.Sprintf("Updated CloudFormationStack '%s'", stack.Name)
+		log.Info(msg)
+		r.event(ctx, generator, eventv1.EventSeverityInfo, msg)
+	}
+	return nil
+}
+
+// pruneStacks deletes the stack objects owned by the generator that are not in the given set of generated names.
+func (r *CloudFormationStackGeneratorReconciler) pruneStacks(ctx context.Context, generator cfnv1.CloudFormationStackGenerator, generated map[string]bool) error {
+	log := ctrl.LoggerFrom(ctx)
+
+	var list cfnv1.CloudFormationStackList
+	if err := r.List(ctx, &list, client.InNamespace(generator.Namespace), client.MatchingLabels{
+		cfnv1.GeneratorNameLabel: generator.Name,
+	}); err != nil {
+		return err
+	}
+
+	for i := range list.Items {
+		stack := &list.Items[i]
+		if generated[stack.Name] || !metav1.IsControlledBy(stack, &generator) || !stack.DeletionTimestamp.IsZero() {
+			continue
+		}
+		if err := r.Delete(ctx, stack); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+		msg := fmt.Sprintf("Deleted CloudFormationStack '%s'", stack.Name)
+		log.Info(msg)
+		r.event(ctx, generator, eventv1.EventSeverityInfo, msg)
+	}
+	return nil
+}
+
+// loadElements returns the inline elements of the generator, followed by the elements of each elements reference.
+func (r *CloudFormationStackGeneratorReconciler) loadElements(ctx context.Context, generator cfnv1.CloudFormationStackGenerator) ([]map[string]string, error) {
+	var elements []map[string]string
+	for _, element := range generator.Spec.Elements {
+		elements = append(elements, element)
+	}
+
+	for _, reference := range generator.Spec.ElementsFrom {
+		var data []byte
+		var err error
+		if reference.Kind == "ConfigMap" {
+			data, err = r.loadConfigMapElements(ctx, generator, reference)
+		} else {
+			data, err = r.loadArtifactElements(ctx, generator, reference)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if data == nil {
+			continue
+		}
+
+		referenced, err := parseElements(data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid elements in %s '%s': %w", reference.Kind, reference.Name, err)
+		}
+		elements = append(elements, referenced...)
+	}
+
+	for i, element := range elements {
+		for name := range element {
+			if err := substitute.ValidateVariableName(name); err != nil {
+				return nil, fmt.Errorf("invalid element %d: %w", i, err)
+			}
+		}
+	}
+	return elements, nil
+}
+
+// loadConfigMapElements returns the data entry of the referenced ConfigMap,
+// or nil if the optional ConfigMap or data entry does not exist.
+func (r *CloudFormationStackGeneratorReconciler) loadConfigMapElements(ctx context.Context, generator cfnv1.CloudFormationStackGenerator, reference cfnv1.GeneratorElementsReference) ([]byte, error) {
+	if reference.Key == "" {
+		return nil, fmt.Errorf("the key of ConfigMap '%s' must be set", reference.Name)
+	}
+
+	namespacedName := types.NamespacedName{Namespace: generator.GetNamespace(), Name: reference.Name}
+	configMap := &corev1.ConfigMap{}
+	if err := r.Get(ctx, namespacedName, configMap); err != nil {
+		if apierrors.IsNotFound(err) && reference.Optional {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("unable to get ConfigMap '%s': %w", namespacedName, err)
+	}
+
+	data, ok := configMap.Data[reference.Key]
+	if !ok {
+		if reference.Optional {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("key '%s' not found in ConfigMap '%s'", reference.Key, namespacedName)
+	}
+	return []byte(data), nil
+}
+
+// loadArtifactElements returns the contents of the referenced file in the source artifact,
+// or nil if the optional file does not exist.
+func (r *CloudFormationStackGeneratorReconciler) loadArtifactElements(ctx context.Context, generator cfnv1.CloudFormationStackGenerator, reference cfnv1.GeneratorElementsReference) ([]byte, error) {
+	if reference.Path == "" {
+		return nil, fmt.Errorf("the path of the elements file in %s '%s' must be set", reference.Kind, reference.Name)
+	}
+
+	sourceRef := cfnv1.SourceReference{Kind: reference.Kind, Name: reference.Name, Namespace: reference.Namespace}
+	sourceObj, err := getSourceObject(ctx, r.Client, r.NoCrossNamespaceRef, generator.GetNamespace(), sourceRef)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve source '%s': %w", sourceRef.String(), err)
+	}
+	if sourceObj.GetArtifact() == nil {
+		return nil, fmt.Errorf("source '%s' is not ready, artifact not found", sourceRef.String())
+	}
+
+	cached, err := r.ArtifactCache.Get(ctx, r.httpClient, sourceObj.GetArtifact(), "")
+	if err != nil {
+		return nil, err
+	}
+	defer cached.Release()
+
+	artifactDir, err := cached.Extract(reference.Path)
+	if err != nil {
+		return nil, err
+	}
+	filePath, err := securejoin.SecureJoin(artifactDir, reference.Path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to join securely the artifact directory with path '%s'", reference.Path)
+	}
+	data, err := os.ReadFile(filepath.Clean(filePath))
+	if err != nil {
+		if os.IsNotExist(err) && reference.Optional {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("unable to read elements file '%s' in source '%s'", reference.Path, sourceRef.String())
+	}
+	return data, nil
+}
+
+// parseElements parses a JSON or YAML list of elements. The values of the elements must be scalars.
+func parseElements(data []byte) ([]map[string]string, error) {
+	var parsed []map[string]interface{}
+	if err := yaml.Unmarshal(data, &parsed); err != nil {
+		return nil, err
+	}
+
+	elements := make([]map[string]string, 0, len(parsed))
+	for i, element := range parsed {
+		values := make(map[string]string, len(element))
+		for name, value := range element {
+			switch v := value.(type) {
+			case nil:
+				values[name] = ""
+			case string:
+				values[name] = v
+			case bool:
+				values[name] = strconv.FormatBool(v)
+			case float64:
+				values[name] = strconv.FormatFloat(v, 'f', -1, 64)
+			default:
+				return nil, fmt.Errorf("the value of '%s' in element %d must be a string, number or boolean", name, i)
+			}
+		}
+		elements = append(elements, values)
+	}
+	return elements, nil
+}
+
+// generateStack generates the stack object for the given element from the generator's template.
+func generateStack(generator cfnv1.CloudFormationStackGenerator, element map[string]string) (*cfnv1.CloudFormationStack, error) {
+	stack := &cfnv1.CloudFormationStack{
+		ObjectMeta: metav1.ObjectMeta{Namespace: generator.Namespace},
+	}
+
+	var metadata cfnv1.CloudFormationStackTemplateMetadata
+	if err := substituteObject(generator.Spec.Template.Metadata, &metadata, element); err != nil {
+		return nil, err
+	}
+	if err := substituteObject(generator.Spec.Template.Spec, &stack.Spec, element); err != nil {
+		return nil, err
+	}
+
+	stack.Name = metadata.Name
+	if stack.Name == "" {
+		stack.Name = fmt.Sprintf("%s-%s", generator.Name, strings.ToLower(stack.Spec.StackName))
+	}
+	if errs := validation.IsDNS1123Subdomain(stack.Name); len(errs) > 0 {
+		return nil, fmt.Errorf("invalid name '%s': %s", stack.Name, strings.Join(errs, ", "))
+	}
+
+	stack.Labels = map[string]string{}
+	for key, value := range metadata.Labels {
+		stack.Labels[key] = value
+	}
+	stack.Labels[cfnv1.GeneratorNameLabel] = generator.Name
+	stack.Annotations = metadata.Annotations
+
+	return stack, nil
+}
+
+// substituteObject replaces the variable references in all the string values of the input object
+// with the values of the given variables, and stores the result in the output object.
+func substituteObject(in interface{}, out interface{}, vars map[string]string) error {
+	data, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	var obj interface{}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return err
+	}
+	if obj, err = substituteValues(obj, vars); err != nil {
+		return err
+	}
+	if data, err = json.Marshal(obj); err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
+
+func substituteValues(obj interface{}, vars map[string]string) (interface{}, error) {
+	switch v := obj.(type) {
+	case string:
+		return substitute.Substitute(v, vars, false)
+	case map[string]interface{}:
+		for key, value := range v {
+			substituted, err := substituteValues(value, vars)
+			if err != nil {
+				return nil, err
+			}
+			v[key] = substituted
+		}
+	case []interface{}:
+		for i, value := range v {
+			substituted, err := substituteValues(value, vars)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = substituted
+		}
+	}
+	return obj, nil
+}
+
+func (r *CloudFormationStackGeneratorReconciler) patchStatus(ctx context.Context, generator *cfnv1.CloudFormationStackGenerator) error {
+	key := client.ObjectKeyFromObject(generator)
+	latest := &cfnv1.CloudFormationStackGenerator{}
+	if err := r.Client.Get(ctx, key, latest); err != nil {
+		return err
+	}
+	patch := client.MergeFrom(latest.DeepCopy())
+	latest.Status = generator.Status
+	return r.Client.Status().Patch(ctx, latest, patch, client.FieldOwner(r.ControllerName))
+}
+
+// event emits a Kubernetes event and forwards the event to notification controller if configured.
+func (r *CloudFormationStackGeneratorReconciler) event(_ context.Context, generator cfnv1.CloudFormationStackGenerator, severity, msg string) {
+	eventtype := "Normal"
+	if severity == eventv1.EventSeverityError {
+		eventtype = "Warning"
+	}
+	r.EventRecorder.AnnotatedEventf(&generator, nil, eventtype, severity, msg)
+}
@@ -0,0 +1,219 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT-0
+
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	cfnv1 "github.com/awslabs/aws-cloudformation-controller-for-flux/api/v1alpha1"
+	"github.com/fluxcd/pkg/apis/meta"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const mockGeneratorName = "mock-generator"
+
+func generateMockCfnStackGenerator() *cfnv1.CloudFormationStackGenerator {
+	return &cfnv1.CloudFormationStackGenerator{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       mockGeneratorName,
+			Namespace:  mockNamespace,
+			Generation: mockGenerationId,
+			UID:        "mock-generator-uid",
+		},
+		Spec: cfnv1.CloudFormationStackGeneratorSpec{
+			Template: cfnv1.CloudFormationStackTemplate{
+				Metadata: cfnv1.CloudFormationStackTemplateMetadata{
+					Labels: map[string]string{"tenant": "${tenant}"},
+				},
+				Spec: cfnv1.CloudFormationStackSpec{
+					StackName:    "tenant-${tenant}",
+					TemplatePath: mockTemplatePath,
+					SourceRef: &cfnv1.SourceReference{
+						Kind: "GitRepository",
+						Name: mockTemplateGitRepoName,
+					},
+					Interval: metav1.Duration{Duration: 5 * time.Hour},
+					StackParameters: []cfnv1.StackParameter{
+						{Key: "Tenant", Value: "${tenant}"},
+						{Key: "Size", Value: "${size:=small}"},
+						{Key: "Region", Value: "$${region}"},
+					},
+				},
+			},
+			Elements: []map[string]string{
+				{"tenant": "alpha"},
+				{"tenant": "beta", "size": "large"},
+			},
+			Interval: metav1.Duration{Duration: 1 * time.Hour},
+			Prune:    true,
+		},
+	}
+}
+
+func newGeneratorReconciler(t *testing.T, objs ...ctrlclient.Object) *CloudFormationStackGeneratorReconciler {
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	require.NoError(t, cfnv1.AddToScheme(scheme))
+
+	kubeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(objs...).
+		WithStatusSubresource(&cfnv1.CloudFormationStackGenerator{}).
+		Build()
+
+	return &CloudFormationStackGeneratorReconciler{
+		Client:        kubeClient,
+		Scheme:        scheme,
+		EventRecorder: record.NewFakeRecorder(100),
+	}
+}
+
+func listGeneratedStacks(t *testing.T, r *CloudFormationStackGeneratorReconciler) map[string]cfnv1.CloudFormationStack {
+	var list cfnv1.CloudFormationStackList
+	require.NoError(t, r.List(context.Background(), &list, ctrlclient.InNamespace(mockNamespace)))
+	stacks := map[string]cfnv1.CloudFormationStack{}
+	for _, stack := range list.Items {
+		stacks[stack.Name] = stack
+	}
+	return stacks
+}
+
+func TestCfnStackGeneratorController_GenerateAndPrune(t *testing.T) {
+	generator := generateMockCfnStackGenerator()
+	r := newGeneratorReconciler(t, generator)
+
+	// Generate a stack for each element
+	reconciled, result, err := r.reconcile(context.Background(), *generator)
+	require.NoError(t, err)
+	require.Equal(t, time.Hour, result.RequeueAfter)
+	require.Equal(t, []string{"mock-generator-tenant-alpha", "mock-generator-tenant-beta"}, reconciled.Status.GeneratedStacks)
+	readyCondition := apimeta.FindStatusCondition(reconciled.Status.Conditions, meta.ReadyCondition)
+	require.NotNil(t, readyCondition)
+	require.Equal(t, metav1.ConditionTrue, readyCondition.Status)
+	require.Equal(t, "Generated 2 CloudFormationStack objects", readyCondition.Message)
+
+	stacks := listGeneratedStacks(t, r)
+	require.Len(t, stacks, 2)
+	beta := stacks["mock-generator-tenant-beta"]
+	require.Equal(t, "tenant-beta", beta.Spec.StackName)
+	require.Equal(t, []cfnv1.StackParameter{
+		{Key: "Tenant", Value: "beta"},
+		{Key: "Size", Value: "large"},
+		{Key: "Region", Value: "${region}"},
+	}, beta.Spec.StackParameters)
+	require.Equal(t, map[string]string{"tenant": "beta", cfnv1.GeneratorNameLabel: mockGeneratorName}, beta.Labels)
+	require.True(t, metav1.IsControlledBy(&beta, generator))
+	require.Equal(t, "small", stacks["mock-generator-tenant-alpha"].Spec.StackParameters[1].Value)
+
+	// Prune the stack of a removed element
+	generator.Spec.Elements = generator.Spec.Elements[1:]
+	reconciled, _, err = r.reconcile(context.Background(), *generator)
+	require.NoError(t, err)
+	require.Equal(t, []string{"mock-generator-tenant-beta"}, reconciled.Status.GeneratedStacks)
+	stacks = listGeneratedStacks(t, r)
+	require.Len(t, stacks, 1)
+	require.Contains(t, stacks, "mock-generator-tenant-beta")
+}
+
+func TestCfnStackGeneratorController_ConfigMapElements(t *testing.T) {
+	generator := generateMockCfnStackGenerator()
+	generator.Spec.Elements = nil
+	generator.Spec.ElementsFrom = []cfnv1.GeneratorElementsReference{
+		{Kind: "ConfigMap", Name: "tenants", Key: "tenants.yaml"},
+		{Kind: "ConfigMap", Name: "missing", Key: "tenants.yaml", Optional: true},
+	}
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "tenants", Namespace: mockNamespace},
+		Data: map[string]string{
+			"tenants.yaml": "- tenant: gamma\n  size: 3\n- tenant: delta\n",
+		},
+	}
+	r := newGeneratorReconciler(t, generator, configMap)
+
+	reconciled, _, err := r.reconcile(context.Background(), *generator)
+	require.NoError(t, err)
+	require.Equal(t, []string{"mock-generator-tenant-delta", "mock-generator-tenant-gamma"}, reconciled.Status.GeneratedStacks)
+	require.Equal(t, "3", listGeneratedStacks(t, r)["mock-generator-tenant-gamma"].Spec.StackParameters[1].Value)
+}
+
+func TestCfnStackGeneratorController_Failures(t *testing.T) {
+	testCases := map[string]struct {
+		modifyGenerator func(generator *cfnv1.CloudFormationStackGenerator)
+		existingObjects []ctrlclient.Object
+		wantedReason    string
+		wantedMessage   string
+		wantedErr       bool
+	}{
+		"missing ConfigMap": {
+			modifyGenerator: func(generator *cfnv1.CloudFormationStackGenerator) {
+				generator.Spec.ElementsFrom = []cfnv1.GeneratorElementsReference{{Kind: "ConfigMap", Name: "missing", Key: "tenants.yaml"}}
+			},
+			wantedReason:  cfnv1.ElementsFailedReason,
+			wantedMessage: "Failed to load elements: unable to get ConfigMap 'mock-namespace/missing': configmaps \"missing\" not found",
+		},
+		"invalid variable name": {
+			modifyGenerator: func(generator *cfnv1.CloudFormationStackGenerator) {
+				generator.Spec.Elements = []map[string]string{{"tenant-name": "alpha"}}
+			},
+			wantedReason:  cfnv1.ElementsFailedReason,
+			wantedMessage: "Failed to load elements: invalid element 0: 'tenant-name' is not a valid variable name, variable names must match '^[_a-zA-Z][_a-zA-Z0-9]*$'",
+		},
+		"duplicate names": {
+			modifyGenerator: func(generator *cfnv1.CloudFormationStackGenerator) {
+				generator.Spec.Elements = []map[string]string{{"tenant": "alpha"}, {"tenant": "alpha", "size": "large"}}
+			},
+			wantedReason:  cfnv1.GenerateFailedReason,
+			wantedMessage: "Failed to generate stack for element 1: the name 'mock-generator-tenant-alpha' is generated for more than one element",
+		},
+		"existing object not owned by the generator": {
+			existingObjects: []ctrlclient.Object{
+				&cfnv1.CloudFormationStack{ObjectMeta: metav1.ObjectMeta{Name: "mock-generator-tenant-alpha", Namespace: mockNamespace}},
+			},
+			wantedReason:  cfnv1.GenerateFailedReason,
+			wantedMessage: "Failed to apply CloudFormationStack 'mock-generator-tenant-alpha': the object already exists and is not owned by the generator",
+			wantedErr:     true,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			generator := generateMockCfnStackGenerator()
+			if tc.modifyGenerator != nil {
+				tc.modifyGenerator(generator)
+			}
+			r := newGeneratorReconciler(t, append(tc.existingObjects, generator)...)
+
+			reconciled, _, err := r.reconcile(context.Background(), *generator)
+			if tc.wantedErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			readyCondition := apimeta.FindStatusCondition(reconciled.Status.Conditions, meta.ReadyCondition)
+			require.NotNil(t, readyCondition)
+			require.Equal(t, metav1.ConditionFalse, readyCondition.Status)
+			require.Equal(t, tc.wantedReason, readyCondition.Reason)
+			require.Equal(t, tc.wantedMessage, readyCondition.Message)
+		})
+	}
+}
+
+func TestCfnStackGeneratorController_ParseElements(t *testing.T) {
+	elements, err := parseElements([]byte(`[{"tenant": "alpha", "replicas": 2, "enabled": true, "empty": null}]`))
+	require.NoError(t, err)
+	require.Equal(t, []map[string]string{{"tenant": "alpha", "replicas": "2", "enabled": "true", "empty": ""}}, elements)
+
+	_, err = parseElements([]byte("- tenant:\n    nested: value\n"))
+	require.EqualError(t, err, "the value of 'tenant' in element 0 must be a string, number or boolean")
+}
@@ -0,0 +1,868 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT-0
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"go.opentelemetry.io/otel/trace"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	kuberecorder "k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	eventv1 "github.com/fluxcd/pkg/apis/event/v1beta1"
+	"github.com/fluxcd/pkg/apis/meta"
+	runtimeCtrl "github.com/fluxcd/pkg/runtime/controller"
+	"github.com/fluxcd/pkg/runtime/predicates"
+	sourcev1 "github.com/fluxcd/source-controller/api/v1"
+	sourcev1b2 "github.com/fluxcd/source-controller/api/v1beta2"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	sdktypes "github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	cfnv1 "github.com/awslabs/aws-cloudformation-controller-for-flux/api/v1alpha1"
+	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/artifact"
+	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/build"
+	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/clients"
+	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/clients/cloudformation"
+	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/clients/ratelimit"
+	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/clients/types"
+	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/metrics"
+	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/template"
+	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/tracing"
+)
+
+//+kubebuilder:rbac:groups=cloudformation.contrib.fluxcd.io,resources=cloudformationstacksets,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=cloudformation.contrib.fluxcd.io,resources=cloudformationstacksets/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=cloudformation.contrib.fluxcd.io,resources=cloudformationstacksets/finalizers,verbs=get;create;update;patch;delete
+
+// CloudFormationStackSetReconciler reconciles a CloudFormationStackSet object
+type CloudFormationStackSetReconciler struct {
+	client.Client
+	runtimeCtrl.Metrics
+
+	Scheme              *runtime.Scheme
+	EventRecorder       kuberecorder.EventRecorder
+	NoCrossNamespaceRef bool
+
+	ControllerName    string
+	ControllerVersion string
+
+	CfnClient      clients.CloudFormationStackSetClient
+	S3Client       clients.S3Client
+	TemplateBucket string
+	// TemplateBucketOptions are the settings for uploading templates to the template bucket.
+	TemplateBucketOptions types.TemplateBucketOptions
+	StackTags             map[string]string
+	// Region is the AWS region that the stack sets are administered in, for labelling the stack set metrics
+	// and backing off the poll intervals when the calls in the region are throttled.
+	Region string
+	// Account is the AWS account ID that the stack sets are administered in, if known,
+	// for backing off the poll intervals when the calls in the account are throttled.
+	Account string
+	// RateLimiter rate limits the AWS API calls of all reconciliations, and backs off the poll
+	// intervals when the calls are throttled.
+	RateLimiter *ratelimit.Limiter
+	// ArtifactCache caches the source artifacts of the stack sets on disk.
+	ArtifactCache *artifact.Cache
+
+	httpClient *retryablehttp.Client
+}
+
+type CloudFormationStackSetReconcilerOptions struct {
+	HTTPRetry int
+}
+
+func (r *CloudFormationStackSetReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, opts CloudFormationStackSetReconcilerOptions) error {
+	// Index the CloudFormationStackSets by their source references
+	if err := mgr.GetCache().IndexField(ctx, &cfnv1.CloudFormationStackSet{}, cfnv1.GitRepositoryIndexKey,
+		r.IndexBy(sourcev1.GitRepositoryKind)); err != nil {
+		return fmt.Errorf("failed setting index fields: %w", err)
+	}
+	if err := mgr.GetCache().IndexField(ctx, &cfnv1.CloudFormationStackSet{}, cfnv1.BucketIndexKey,
+		r.IndexBy(sourcev1b2.BucketKind)); err != nil {
+		return fmt.Errorf("failed setting index fields: %w", err)
+	}
+	if err := mgr.GetCache().IndexField(ctx, &cfnv1.CloudFormationStackSet{}, cfnv1.OCIRepositoryIndexKey,
+		r.IndexBy(sourcev1b2.OCIRepositoryKind)); err != nil {
+		return fmt.Errorf("failed setting index fields: %w", err)
+	}
+
+	// Configure the retryable http client for retrieving artifacts.
+	httpClient := retryablehttp.NewClient()
+	httpClient.RetryWaitMin = 5 * time.Second
+	httpClient.RetryWaitMax = 30 * time.Second
+	httpClient.RetryMax = opts.HTTPRetry
+	httpClient.Logger = nil
+	r.httpClient = httpClient
+
+	// Watch for source object changes and CloudFormation stack set object changes
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&cfnv1.CloudFormationStackSet{}, builder.WithPredicates(
+			predicate.Or(predicate.GenerationChangedPredicate{}, predicates.ReconcileRequestedPredicate{}),
+		)).
+		Watches(
+			&sourcev1.GitRepository{},
+			handler.EnqueueRequestsFromMapFunc(r.requestsForRevisionChangeOf(cfnv1.GitRepositoryIndexKey)),
+			builder.WithPredicates(SourceRevisionChangePredicate{}),
+		).
+		Watches(
+			&sourcev1b2.Bucket{},
+			handler.EnqueueRequestsFromMapFunc(r.requestsForRevisionChangeOf(cfnv1.BucketIndexKey)),
+			builder.WithPredicates(SourceRevisionChangePredicate{}),
+		).
+		Watches(
+			&sourcev1b2.OCIRepository{},
+			handler.EnqueueRequestsFromMapFunc(r.requestsForRevisionChangeOf(cfnv1.OCIRepositoryIndexKey)),
+			builder.WithPredicates(SourceRevisionChangePredicate{}),
+		).
+		WithOptions(controller.Options{}).
+		Complete(r)
+}
+
+func (r *CloudFormationStackSetReconciler) IndexBy(kind string) func(o client.Object) []string {
+	return func(o client.Object) []string {
+		stackSet := o.(*cfnv1.CloudFormationStackSet)
+		if stackSet.Spec.SourceRef.Kind == kind {
+			namespace := stackSet.GetNamespace()
+			// default to the stack set's namespace
+			if stackSet.Spec.SourceRef.Namespace != "" {
+				namespace = stackSet.Spec.SourceRef.Namespace
+			}
+			return []string{fmt.Sprintf("%s/%s", namespace, stackSet.Spec.SourceRef.Name)}
+		}
+
+		return nil
+	}
+}
+
+func (r *CloudFormationStackSetReconciler) requestsForRevisionChangeOf(indexKey string) func(ctx context.Context, obj client.Object) []reconcile.Request {
+	return func(ctx context.Context, obj client.Object) []reconcile.Request {
+		log := ctrl.LoggerFrom(ctx)
+		repo, ok := obj.(interface {
+			GetArtifact() *sourcev1.Artifact
+		})
+		if !ok {
+			err := fmt.Errorf("expected an object conformed with GetArtifact() method, but got a %T", obj)
+			log.Error(err, "failed to get requests for CloudFormationStackSet change")
+			return nil
+		}
+		// If we do not have an artifact, we have no requests to make
+		if repo.GetArtifact() == nil {
+			return nil
+		}
+
+		var list cfnv1.CloudFormationStackSetList
+		if err := r.List(ctx, &list, client.MatchingFields{
+			indexKey: client.ObjectKeyFromObject(obj).String(),
+		}); err != nil {
+			log.Error(err, "failed to list CloudFormation stack sets")
+			return nil
+		}
+		var reqs []reconcile.Request
+		for _, d := range list.Items {
+			// If the revision of the artifact equals to the last attempted revision,
+			// we should not make a request for this CloudFormation stack set
+			if repo.GetArtifact().HasRevision(d.Status.LastAttemptedRevision) {
+				continue
+			}
+			reqs = append(reqs, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&d)})
+		}
+		return reqs
+	}
+}
+
+func (r *CloudFormationStackSetReconciler) Reconcile(ctx context.Context, req ctrl.Request) (_ ctrl.Result, retErr error) {
+	start := time.Now()
+	log := ctrl.LoggerFrom(ctx)
+
+	var cfnStackSet cfnv1.CloudFormationStackSet
+	if err := r.Get(ctx, req.NamespacedName, &cfnStackSet); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	ctx, span := tracing.Start(ctx, "CloudFormationStackSet.Reconcile",
+		trace.WithAttributes(tracing.ObjectAttributes(cfnv1.CloudFormationStackSetKind, cfnStackSet.Namespace, cfnStackSet.Name, cfnStackSet.Spec.StackSetName)...))
+	defer func() {
+		tracing.End(span, retErr)
+	}()
+
+	defer func() {
+		// Always record metrics.
+		r.Metrics.RecordSuspend(ctx, &cfnStackSet, cfnStackSet.Spec.Suspend)
+		r.Metrics.RecordReadiness(ctx, &cfnStackSet)
+		r.Metrics.RecordDuration(ctx, &cfnStackSet, start)
+	}()
+
+	// Add our finalizer if it does not exist
+	if !controllerutil.ContainsFinalizer(&cfnStackSet, cfnv1.CloudFormationStackFinalizer) {
+		patch := client.MergeFrom(cfnStackSet.DeepCopy())
+		controllerutil.AddFinalizer(&cfnStackSet, cfnv1.CloudFormationStackFinalizer)
+		if err := r.Patch(ctx, &cfnStackSet, patch); err != nil {
+			log.Error(err, "Unable to register finalizer")
+			return ctrl.Result{}, err
+		}
+	}
+
+	// Check if the object is being deleted
+	if !cfnStackSet.ObjectMeta.DeletionTimestamp.IsZero() {
+		cfnStackSet, result, err := r.reconcileDelete(ctx, cfnStackSet)
+
+		// Update status
+		// Skip updating the status if the stack set is successfully deleted (no requeueAfter set).
+		// The finalizer has been removed, so the object is likely gone from the API server already.
+		if result.Requeue || result.RequeueAfter > 0 {
+			if updateStatusErr := r.patchStatus(ctx, &cfnStackSet); updateStatusErr != nil {
+				log.Error(updateStatusErr, "Unable to update status after delete reconciliation")
+				return ctrl.Result{Requeue: true}, updateStatusErr
+			}
+		} else if err == nil {
+			metrics.DeleteStack(cfnv1.CloudFormationStackSetKind, cfnStackSet.Namespace, cfnStackSet.Name)
+		}
+
+		durationMsg := fmt.Sprintf("Deletion reconcilation loop finished in %s", time.Now().Sub(start).String())
+		if result.RequeueAfter > 0 {
+			durationMsg = fmt.Sprintf("%s, next run in %s", durationMsg, result.RequeueAfter.String())
+		}
+		log.Info(durationMsg)
+
+		return result, err
+	}
+
+	// Check if the CloudFormationStackSet is suspended
+	if cfnStackSet.Spec.Suspend {
+		log.Info("Reconciliation is suspended for this object")
+		return ctrl.Result{}, nil
+	}
+
+	// Reconcile
+	cfnStackSet, result, err := r.reconcile(ctx, cfnStackSet)
+
+	// Back off when the AWS APIs throttle the controller's calls, instead of failing the reconciliation
+	if ratelimit.IsThrottle(err) {
+		msg := fmt.Sprintf("AWS API calls for stack set '%s' were throttled: %s", cfnStackSet.Spec.StackSetName, err.Error())
+		log.Info(msg)
+		cfnStackSet = cfnv1.CloudFormationStackSetNotReady(cfnStackSet, cfnv1.StackSetReadinessUpdate{Message: msg, Reason: cfnv1.ThrottledReason})
+		result, err = ctrl.Result{RequeueAfter: r.RateLimiter.Backoff(r.Account, r.Region, cfnStackSet.GetRetryInterval())}, nil
+	} else if result.RequeueAfter > 0 {
+		result.RequeueAfter = r.RateLimiter.Backoff(r.Account, r.Region, result.RequeueAfter)
+	}
+
+	// Update status
+	if updateStatusErr := r.patchStatus(ctx, &cfnStackSet); updateStatusErr != nil {
+		log.Error(updateStatusErr, "Unable to update status after reconciliation")
+		return ctrl.Result{Requeue: true}, updateStatusErr
+	}
+
+	// Log reconciliation duration
+	durationMsg := fmt.Sprintf("Reconciliation loop finished in %s", time.Now().Sub(start).String())
+	if result.RequeueAfter > 0 {
+		durationMsg = fmt.Sprintf("%s, next run in %s", durationMsg, result.RequeueAfter.String())
+	}
+	log.Info(durationMsg)
+
+	return result, err
+}
+
+// reconcile creates or updates the CloudFormation stack set and its stack instances as needed.
+func (r *CloudFormationStackSetReconciler) reconcile(ctx context.Context, cfnStackSet cfnv1.CloudFormationStackSet) (cfnv1.CloudFormationStackSet, ctrl.Result, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	// Record the value of the reconciliation request, if any
+	if v, ok := meta.ReconcileAnnotationValue(cfnStackSet.GetAnnotations()); ok {
+		cfnStackSet.Status.SetLastHandledReconcileRequest(v)
+	}
+
+	// Observe CloudFormationStackSet generation.
+	if cfnStackSet.Status.ObservedGeneration != cfnStackSet.Generation {
+		cfnStackSet = cfnv1.CloudFormationStackSetProgressing(cfnStackSet, cfnv1.StackSetReadinessUpdate{Message: "Stack set reconciliation in progress"})
+		if updateStatusErr := r.patchStatus(ctx, &cfnStackSet); updateStatusErr != nil {
+			log.Error(updateStatusErr, "Unable to update status after generation update")
+			return cfnStackSet, ctrl.Result{Requeue: true}, updateStatusErr
+		}
+	}
+
+	// Resolve source reference
+	sourceObj, err := getSourceObject(ctx, r.Client, r.NoCrossNamespaceRef, cfnStackSet.GetNamespace(), cfnStackSet.Spec.SourceRef)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			msg := fmt.Sprintf("Source '%s' not found", cfnStackSet.Spec.SourceRef.String())
+			cfnStackSet = cfnv1.CloudFormationStackSetNotReady(cfnStackSet, cfnv1.StackSetReadinessUpdate{Message: msg, Reason: cfnv1.ArtifactFailedReason})
+			log.Info(msg)
+			// do not requeue immediately, when the source is created the watcher should trigger a reconciliation
+			return cfnStackSet, ctrl.Result{RequeueAfter: cfnStackSet.GetRetryInterval()}, nil
+		} else {
+			msg := fmt.Sprintf("Failed to resolve source '%s'", cfnStackSet.Spec.SourceRef.String())
+			log.Error(err, msg)
+			cfnStackSet = cfnv1.CloudFormationStackSetNotReady(cfnStackSet, cfnv1.StackSetReadinessUpdate{Message: msg, Reason: cfnv1.ArtifactFailedReason})
+			return cfnStackSet, ctrl.Result{RequeueAfter: cfnStackSet.GetRetryInterval()}, err
+		}
+	}
+
+	// Check source readiness
+	if sourceObj.GetArtifact() == nil {
+		msg := fmt.Sprintf("Source '%s' is not ready, artifact not found", cfnStackSet.Spec.SourceRef.String())
+		cfnStackSet = cfnv1.CloudFormationStackSetNotReady(cfnStackSet, cfnv1.StackSetReadinessUpdate{Message: msg, Reason: cfnv1.ArtifactFailedReason})
+		log.Info(msg)
+		// do not requeue immediately, when the artifact is created the watcher should trigger a reconciliation
+		return cfnStackSet, ctrl.Result{RequeueAfter: cfnStackSet.GetRetryInterval()}, nil
+	}
+	revision := sourceObj.GetArtifact().Revision
+
+	// Load stack set template file from artifact
+	tmpl, sourceArtifact, err := loadTemplate(ctx, r.ArtifactCache, r.httpClient, cfnStackSet.Spec.TemplatePath, nil, sourceObj.GetArtifact(), cfnStackSet.Status.LastAppliedRevision, nil)
+	if err != nil {
+		msg := fmt.Sprintf("Failed to load template '%s' from source '%s'", cfnStackSet.Spec.TemplatePath, cfnStackSet.Spec.SourceRef.String())
+		log.Error(err, msg)
+		cfnStackSet = cfnv1.CloudFormationStackSetNotReady(cfnStackSet, cfnv1.StackSetReadinessUpdate{
+			Message:        msg,
+			Reason:         cfnv1.ArtifactFailedReason,
+			SourceRevision: revision,
+		})
+		return cfnStackSet, ctrl.Result{RequeueAfter: cfnStackSet.GetRetryInterval()}, err
+	}
+	revision = sourceArtifact.Revision
+
+	// Reconcile CloudFormation stack set
+	reconciledCfnStackSet, requeueInterval, err := r.reconcileStackSet(ctx, cfnStackSet, tmpl, revision)
+	if err != nil {
+		log.Error(err, "Failed to reconcile stack set")
+		msg := fmt.Sprintf("Failed to reconcile stack set: %s", err.Error())
+		r.event(ctx, cfnStackSet, revision, eventv1.EventSeverityError, msg)
+		return reconciledCfnStackSet, ctrl.Result{RequeueAfter: requeueInterval}, err
+	}
+
+	// The stack set is up-to-date with the last applied revision, not with the source's latest revision
+	if revision != sourceObj.GetArtifact().Revision &&
+		apimeta.IsStatusConditionTrue(reconciledCfnStackSet.Status.Conditions, meta.ReadyCondition) {
+		msg := fmt.Sprintf("Failed to download the artifact of revision '%s', the stack set is up-to-date with the last applied revision '%s'", sourceObj.GetArtifact().Revision, revision)
+		log.Info(msg)
+		r.event(ctx, reconciledCfnStackSet, sourceObj.GetArtifact().Revision, eventv1.EventSeverityError, msg)
+		reconciledCfnStackSet = cfnv1.CloudFormationStackSetNotReady(reconciledCfnStackSet, cfnv1.StackSetReadinessUpdate{
+			Message:        msg,
+			Reason:         cfnv1.ArtifactFailedReason,
+			SourceRevision: sourceObj.GetArtifact().Revision,
+		})
+		return reconciledCfnStackSet, ctrl.Result{RequeueAfter: reconciledCfnStackSet.GetRetryInterval()}, nil
+	}
+
+	return reconciledCfnStackSet, ctrl.Result{RequeueAfter: requeueInterval}, nil
+}
+
+func (r *CloudFormationStackSetReconciler) reconcileStackSet(ctx context.Context, cfnStackSet cfnv1.CloudFormationStackSet, tmpl *template.Template, revision string) (cfnv1.CloudFormationStackSet, time.Duration, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	clientStackSet := r.clientStackSet(cfnStackSet)
+	clientStackSet.TemplateBody = tmpl.Body
+
+	// Wait for the last stack set operation to complete
+	cfnStackSet, requeueInterval, done, err := r.checkLastOperation(ctx, cfnStackSet, clientStackSet, revision)
+	if !done || err != nil {
+		return cfnStackSet, requeueInterval, err
+	}
+
+	// Upload the nested templates, and the stack set template if it is too large to be passed inline.
+	// The uploaded templates are keyed by the stack set name, with a prefix that distinguishes them from stack templates.
+	templateStack := &types.Stack{Name: stackSetTemplateKeyName(clientStackSet), StackConfig: clientStackSet.StackConfig}
+	if _, err := uploadStackTemplate(ctx, r.S3Client, templateStack, tmpl, r.stackSetMetricsLabels(cfnStackSet)); err != nil {
+		if errors.Is(err, errTemplateBucketMissing) {
+			msg := fmt.Sprintf("Failed to deploy stack set '%s': %s", clientStackSet.Name, err.Error())
+			log.Info(msg)
+			r.event(ctx, cfnStackSet, revision, eventv1.EventSeverityError, msg)
+			cfnStackSet = cfnv1.CloudFormationStackSetNotReady(cfnStackSet, cfnv1.StackSetReadinessUpdate{SourceRevision: revision, Message: msg, Reason: cfnv1.TemplateBucketMissingReason})
+			return cfnStackSet, cfnStackSet.GetRetryInterval(), nil
+		}
+		msg := fmt.Sprintf("Failed to upload template to S3 for stack set '%s'", clientStackSet.Name)
+		log.Error(err, msg)
+		cfnStackSet = cfnv1.CloudFormationStackSetNotReady(cfnStackSet, cfnv1.StackSetReadinessUpdate{SourceRevision: revision, Message: msg, Reason: cfnv1.TemplateUploadFailedReason})
+		return cfnStackSet, cfnStackSet.GetRetryInterval(), err
+	}
+
+	// Create the stack set if it does not exist yet
+	desc, err := r.CfnClient.DescribeStackSet(ctx, clientStackSet)
+	var notFoundErr *cloudformation.ErrStackSetNotFound
+	if errors.As(err, &notFoundErr) {
+		if err := r.CfnClient.CreateStackSet(ctx, clientStackSet); err != nil {
+			msg := fmt.Sprintf("Failed to create the stack set '%s'", clientStackSet.Name)
+			log.Error(err, msg)
+			cfnStackSet = cfnv1.CloudFormationStackSetNotReady(cfnStackSet, cfnv1.StackSetReadinessUpdate{SourceRevision: revision, Message: msg, Reason: cfnv1.CloudFormationApiCallFailedReason})
+			return cfnStackSet, cfnStackSet.GetRetryInterval(), err
+		}
+		msg := fmt.Sprintf("Created stack set '%s'", clientStackSet.Name)
+		log.Info(msg)
+		r.event(ctx, cfnStackSet, revision, eventv1.EventSeverityInfo, msg)
+	} else if err != nil {
+		msg := fmt.Sprintf("Failed to describe the stack set '%s'", clientStackSet.Name)
+		log.Error(err, msg)
+		cfnStackSet = cfnv1.CloudFormationStackSetNotReady(cfnStackSet, cfnv1.StackSetReadinessUpdate{SourceRevision: revision, Message: msg, Reason: cfnv1.CloudFormationApiCallFailedReason})
+		return cfnStackSet, cfnStackSet.GetRetryInterval(), err
+	}
+
+	// Find the existing stack instances
+	instances, err := r.CfnClient.ListStackInstances(ctx, clientStackSet)
+	if err != nil {
+		msg := fmt.Sprintf("Failed to list the stack instances of stack set '%s'", clientStackSet.Name)
+		log.Error(err, msg)
+		cfnStackSet = cfnv1.CloudFormationStackSetNotReady(cfnStackSet, cfnv1.StackSetReadinessUpdate{SourceRevision: revision, Message: msg, Reason: cfnv1.CloudFormationApiCallFailedReason})
+		return cfnStackSet, cfnStackSet.GetRetryInterval(), err
+	}
+	cfnStackSet.Status.StackInstances = stackInstanceStatuses(instances)
+
+	// Update the stack set and its stack instances if the stack set configuration changed,
+	// or if stack instances are outdated because a previous operation failed
+	if desc != nil && (stackSetNeedsUpdate(desc, clientStackSet) || countOutdatedStackInstances(instances) > 0) {
+		operationID, err := r.CfnClient.UpdateStackSet(ctx, clientStackSet)
+		if err != nil {
+			msg := fmt.Sprintf("Failed to update the stack set '%s'", clientStackSet.Name)
+			log.Error(err, msg)
+			cfnStackSet = cfnv1.CloudFormationStackSetNotReady(cfnStackSet, cfnv1.StackSetReadinessUpdate{SourceRevision: revision, Message: msg, Reason: cfnv1.CloudFormationApiCallFailedReason})
+			return cfnStackSet, cfnStackSet.GetRetryInterval(), err
+		}
+		msg := fmt.Sprintf("Update of stack set '%s' in progress (operation %s)", clientStackSet.Name, operationID)
+		log.Info(msg)
+		r.event(ctx, cfnStackSet, revision, eventv1.EventSeverityInfo, msg)
+		cfnStackSet = cfnv1.CloudFormationStackSetProgressing(cfnStackSet, cfnv1.StackSetReadinessUpdate{SourceRevision: revision, Message: msg, OperationID: operationID})
+		return cfnStackSet, cfnStackSet.Spec.PollInterval.Duration, nil
+	}
+
+	// Create the missing stack instances, one operation at a time
+	desired := desiredStackInstances(cfnStackSet)
+	existing := existingStackInstances(cfnStackSet, instances)
+	if targets := nextStackInstanceTargets(desired, existing, cfnStackSet.Spec.PermissionModel); targets != nil {
+		operationID, err := r.CfnClient.CreateStackInstances(ctx, clientStackSet, targets)
+		if err != nil {
+			msg := fmt.Sprintf("Failed to create stack instances for stack set '%s'", clientStackSet.Name)
+			log.Error(err, msg)
+			cfnStackSet = cfnv1.CloudFormationStackSetNotReady(cfnStackSet, cfnv1.StackSetReadinessUpdate{SourceRevision: revision, Message: msg, Reason: cfnv1.CloudFormationApiCallFailedReason})
+			return cfnStackSet, cfnStackSet.GetRetryInterval(), err
+		}
+		msg := fmt.Sprintf("Creation of stack instances for stack set '%s' in progress (operation %s)", clientStackSet.Name, operationID)
+		log.Info(msg)
+		r.event(ctx, cfnStackSet, revision, eventv1.EventSeverityInfo, msg)
+		cfnStackSet = cfnv1.CloudFormationStackSetProgressing(cfnStackSet, cfnv1.StackSetReadinessUpdate{SourceRevision: revision, Message: msg, OperationID: operationID})
+		return cfnStackSet, cfnStackSet.Spec.PollInterval.Duration, nil
+	}
+
+	// Delete the stack instances that are no longer desired, one operation at a time
+	if targets := nextStackInstanceTargets(existing, desired, cfnStackSet.Spec.PermissionModel); targets != nil {
+		operationID, err := r.CfnClient.DeleteStackInstances(ctx, clientStackSet, targets, false)
+		if err != nil {
+			msg := fmt.Sprintf("Failed to delete stack instances of stack set '%s'", clientStackSet.Name)
+			log.Error(err, msg)
+			cfnStackSet = cfnv1.CloudFormationStackSetNotReady(cfnStackSet, cfnv1.StackSetReadinessUpdate{SourceRevision: revision, Message: msg, Reason: cfnv1.CloudFormationApiCallFailedReason})
+			return cfnStackSet, cfnStackSet.GetRetryInterval(), err
+		}
+		msg := fmt.Sprintf("Deletion of stack instances of stack set '%s' in progress (operation %s)", clientStackSet.Name, operationID)
+		log.Info(msg)
+		r.event(ctx, cfnStackSet, revision, eventv1.EventSeverityInfo, msg)
+		cfnStackSet = cfnv1.CloudFormationStackSetProgressing(cfnStackSet, cfnv1.StackSetReadinessUpdate{SourceRevision: revision, Message: msg, OperationID: operationID})
+		return cfnStackSet, cfnStackSet.Spec.PollInterval.Duration, nil
+	}
+
+	// Success!
+	log.Info(fmt.Sprintf("Successfully reconciled stack set '%s' with %d stack instances", clientStackSet.Name, len(instances)))
+	cfnStackSet.Status.LastAttemptedRevision = revision
+	return cfnv1.CloudFormationStackSetReady(cfnStackSet), cfnStackSet.Spec.Interval.Duration, nil
+}
+
+// checkLastOperation checks the status of the last stack set operation started by the controller.
+// Returns done=true if there is no operation in progress, and the reconciliation can continue.
+func (r *CloudFormationStackSetReconciler) checkLastOperation(ctx context.Context, cfnStackSet cfnv1.CloudFormationStackSet, clientStackSet *types.StackSet, revision string) (cfnv1.CloudFormationStackSet, time.Duration, bool, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	operationID := cfnStackSet.Status.LastOperationID
+	if operationID == "" {
+		return cfnStackSet, 0, true, nil
+	}
+
+	op, err := r.CfnClient.DescribeStackSetOperation(ctx, clientStackSet, operationID)
+	if err != nil {
+		var notFoundErr *cloudformation.ErrStackSetOperationNotFound
+		if errors.As(err, &notFoundErr) {
+			cfnStackSet.Status.LastOperationID = ""
+			return cfnStackSet, 0, true, nil
+		}
+		msg := fmt.Sprintf("Failed to describe operation %s for stack set '%s'", operationID, clientStackSet.Name)
+		log.Error(err, msg)
+		cfnStackSet = cfnv1.CloudFormationStackSetNotReady(cfnStackSet, cfnv1.StackSetReadinessUpdate{SourceRevision: revision, Message: msg, Reason: cfnv1.CloudFormationApiCallFailedReason})
+		return cfnStackSet, cfnStackSet.GetRetryInterval(), false, err
+	}
+
+	// Keep polling if the operation is still in progress
+	if op.InProgress() {
+		msg := fmt.Sprintf("Operation %s is in progress for stack set '%s' (action '%s', status '%s')", operationID, clientStackSet.Name, op.Action, op.Status)
+		log.Info(msg)
+		cfnStackSet = cfnv1.CloudFormationStackSetProgressing(cfnStackSet, cfnv1.StackSetReadinessUpdate{SourceRevision: revision, Message: msg})
+		return cfnStackSet, cfnStackSet.Spec.PollInterval.Duration, false, nil
+	}
+
+	// The operation is complete, so the next reconciliation starts a new operation if needed
+	cfnStackSet.Status.LastOperationID = ""
+	if op.IsSuccess() {
+		return cfnStackSet, 0, true, nil
+	}
+
+	msg := fmt.Sprintf("Operation %s failed for stack set '%s': action '%s', status '%s'", operationID, clientStackSet.Name, op.Action, op.Status)
+	if op.StatusReason != nil {
+		msg = fmt.Sprintf("%s, reason '%s'", msg, *op.StatusReason)
+	}
+	log.Info(msg)
+	r.event(ctx, cfnStackSet, revision, eventv1.EventSeverityError, msg)
+	cfnStackSet = cfnv1.CloudFormationStackSetNotReady(cfnStackSet, cfnv1.StackSetReadinessUpdate{SourceRevision: revision, Message: msg, Reason: cfnv1.StackSetOperationFailedReason})
+	return cfnStackSet, cfnStackSet.GetRetryInterval(), false, nil
+}
+
+// stackSetMetricsLabels returns the labels of the metrics recorded for the stack set
+func (r *CloudFormationStackSetReconciler) stackSetMetricsLabels(cfnStackSet cfnv1.CloudFormationStackSet) metrics.StackLabels {
+	return metrics.StackLabels{
+		Kind:      cfnv1.CloudFormationStackSetKind,
+		Namespace: cfnStackSet.Namespace,
+		Name:      cfnStackSet.Name,
+		StackName: cfnStackSet.Spec.StackSetName,
+		Region:    r.Region,
+	}
+}
+
+// reconcileDelete deletes the stack instances of the CloudFormation stack set, then deletes the stack set.
+func (r *CloudFormationStackSetReconciler) reconcileDelete(ctx context.Context, cfnStackSet cfnv1.CloudFormationStackSet) (cfnv1.CloudFormationStackSet, ctrl.Result, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	if cfnStackSet.Spec.Suspend {
+		log.Info(fmt.Sprintf("Skipping CloudFormation stack set deletion for suspended stack set '%s/%s'", cfnStackSet.Namespace, cfnStackSet.Name))
+		controllerutil.RemoveFinalizer(&cfnStackSet, cfnv1.CloudFormationStackFinalizer)
+		err := r.Update(ctx, &cfnStackSet)
+		return cfnStackSet, ctrl.Result{}, err
+	}
+
+	if !cfnStackSet.Spec.DestroyStackSetOnDeletion {
+		log.Info(fmt.Sprintf("Skipping CloudFormation stack set deletion for stack set '%s/%s' (DestroyStackSetOnDeletion is false)", cfnStackSet.Namespace, cfnStackSet.Name))
+		controllerutil.RemoveFinalizer(&cfnStackSet, cfnv1.CloudFormationStackFinalizer)
+		err := r.Update(ctx, &cfnStackSet)
+		return cfnStackSet, ctrl.Result{}, err
+	}
+
+	clientStackSet := r.clientStackSet(cfnStackSet)
+	revision := cfnStackSet.Status.LastAttemptedRevision
+
+	// Let the current operation complete before deleting the stack instances
+	cfnStackSet, requeueInterval, done, err := r.checkLastOperation(ctx, cfnStackSet, clientStackSet, revision)
+	if !done || err != nil {
+		return cfnStackSet, ctrl.Result{RequeueAfter: requeueInterval}, err
+	}
+
+	instances, err := r.CfnClient.ListStackInstances(ctx, clientStackSet)
+	var notFoundErr *cloudformation.ErrStackSetNotFound
+	if err != nil && !errors.As(err, &notFoundErr) {
+		msg := fmt.Sprintf("Failed to list the stack instances of stack set '%s'", clientStackSet.Name)
+		log.Error(err, msg)
+		r.event(ctx, cfnStackSet, revision, eventv1.EventSeverityError, fmt.Sprintf("Failed to reconcile stack set: %s", err.Error()))
+		cfnStackSet = cfnv1.CloudFormationStackSetNotReady(cfnStackSet, cfnv1.StackSetReadinessUpdate{Message: msg, Reason: cfnv1.CloudFormationApiCallFailedReason})
+		return cfnStackSet, ctrl.Result{RequeueAfter: cfnStackSet.GetRetryInterval()}, err
+	}
+	cfnStackSet.Status.StackInstances = stackInstanceStatuses(instances)
+
+	// Delete the stack instances, one operation at a time
+	existing := existingStackInstances(cfnStackSet, instances)
+	if targets := nextStackInstanceTargets(existing, nil, cfnStackSet.Spec.PermissionModel); targets != nil {
+		operationID, err := r.CfnClient.DeleteStackInstances(ctx, clientStackSet, targets, false)
+		if err != nil {
+			msg := fmt.Sprintf("Failed to delete stack instances of stack set '%s'", clientStackSet.Name)
+			log.Error(err, msg)
+			r.event(ctx, cfnStackSet, revision, eventv1.EventSeverityError, fmt.Sprintf("Failed to reconcile stack set: %s", err.Error()))
+			cfnStackSet = cfnv1.CloudFormationStackSetNotReady(cfnStackSet, cfnv1.StackSetReadinessUpdate{Message: msg, Reason: cfnv1.CloudFormationApiCallFailedReason})
+			return cfnStackSet, ctrl.Result{RequeueAfter: cfnStackSet.GetRetryInterval()}, err
+		}
+		msg := fmt.Sprintf("Started deletion of stack instances of stack set '%s' (operation %s)", clientStackSet.Name, operationID)
+		log.Info(msg)
+		r.event(ctx, cfnStackSet, revision, eventv1.EventSeverityInfo, msg)
+		cfnStackSet = cfnv1.CloudFormationStackSetProgressing(cfnStackSet, cfnv1.StackSetReadinessUpdate{Message: msg, OperationID: operationID})
+		return cfnStackSet, ctrl.Result{RequeueAfter: cfnStackSet.Spec.PollInterval.Duration}, nil
+	}
+
+	// Delete the stack set once all of its stack instances are deleted
+	if err := r.CfnClient.DeleteStackSet(ctx, clientStackSet); err != nil {
+		msg := fmt.Sprintf("Failed to delete the stack set '%s'", clientStackSet.Name)
+		log.Error(err, msg)
+		r.event(ctx, cfnStackSet, revision, eventv1.EventSeverityError, fmt.Sprintf("Failed to reconcile stack set: %s", err.Error()))
+		cfnStackSet = cfnv1.CloudFormationStackSetNotReady(cfnStackSet, cfnv1.StackSetReadinessUpdate{Message: msg, Reason: cfnv1.CloudFormationApiCallFailedReason})
+		return cfnStackSet, ctrl.Result{RequeueAfter: cfnStackSet.GetRetryInterval()}, err
+	}
+
+	// The stack set is successfully deleted, no re-queue needed
+	// Remove our finalizer from the list and update it.
+	controllerutil.RemoveFinalizer(&cfnStackSet, cfnv1.CloudFormationStackFinalizer)
+	updateErr := r.Update(ctx, &cfnStackSet)
+	if updateErr != nil {
+		log.Error(updateErr, fmt.Sprintf("Failed to remove finalizer from stack set object '%s/%s'", cfnStackSet.Namespace, cfnStackSet.Name))
+	}
+	log.Info(fmt.Sprintf("Successfully deleted stack set '%s'", clientStackSet.Name))
+	return cfnv1.CloudFormationStackSetReady(cfnStackSet), ctrl.Result{}, updateErr
+}
+
+// clientStackSet converts the Flux controller stack set type into the CloudFormation client stack set type
+func (r *CloudFormationStackSetReconciler) clientStackSet(cfnStackSet cfnv1.CloudFormationStackSet) *types.StackSet {
+	spec := cfnStackSet.Spec
+	clientStackSet := &types.StackSet{
+		Name: spec.StackSetName,
+		StackConfig: &types.StackConfig{
+			TemplateBucket:        r.TemplateBucket,
+			TemplateBucketOptions: r.TemplateBucketOptions,
+		},
+		StackSetConfig: &types.StackSetConfig{
+			PermissionModel:       sdktypes.PermissionModels(spec.PermissionModel),
+			AdministrationRoleARN: spec.AdministrationRoleARN,
+			ExecutionRoleName:     spec.ExecutionRoleName,
+		},
+	}
+	if clientStackSet.PermissionModel == "" {
+		clientStackSet.PermissionModel = sdktypes.PermissionModelsSelfManaged
+	}
+
+	for _, param := range spec.StackParameters {
+		clientStackSet.Parameters = append(clientStackSet.Parameters, sdktypes.Parameter{
+			ParameterKey:   aws.String(param.Key),
+			ParameterValue: aws.String(param.Value),
+		})
+	}
+
+	clientStackSet.Tags = build.Tags(cfnStackSet.Name, cfnStackSet.Namespace, spec.StackTags, build.Options{
+		ControllerName:    r.ControllerName,
+		ControllerVersion: r.ControllerVersion,
+		StackTags:         r.StackTags,
+	})
+
+	if spec.AutoDeployment != nil {
+		clientStackSet.AutoDeployment = &sdktypes.AutoDeployment{
+			Enabled:                      aws.Bool(spec.AutoDeployment.Enabled),
+			RetainStacksOnAccountRemoval: aws.Bool(spec.AutoDeployment.RetainStacksOnAccountRemoval),
+		}
+	}
+
+	if prefs := spec.OperationPreferences; prefs != nil {
+		clientStackSet.OperationPreferences = &sdktypes.StackSetOperationPreferences{
+			MaxConcurrentCount:         prefs.MaxConcurrentCount,
+			MaxConcurrentPercentage:    prefs.MaxConcurrentPercentage,
+			FailureToleranceCount:      prefs.FailureToleranceCount,
+			FailureTolerancePercentage: prefs.FailureTolerancePercentage,
+			RegionConcurrencyType:      sdktypes.RegionConcurrencyType(prefs.RegionConcurrencyType),
+			RegionOrder:                prefs.RegionOrder,
+		}
+	}
+
+	return clientStackSet
+}
+
+// stackSetTemplateKeyName returns the name used in the template bucket object keys of the stack set's templates
+func stackSetTemplateKeyName(clientStackSet *types.StackSet) string {
+	return "stackset-" + clientStackSet.Name
+}
+
+// stackSetNeedsUpdate returns true if the stack set's template, parameters, tags or settings
+// are different from the desired configuration
+func stackSetNeedsUpdate(desc *types.StackSetDescription, clientStackSet *types.StackSet) bool {
+	if aws.ToString(desc.TemplateBody) != clientStackSet.TemplateBody {
+		return true
+	}
+
+	params := make(map[string]string, len(clientStackSet.Parameters))
+	for _, param := range clientStackSet.Parameters {
+		params[aws.ToString(param.ParameterKey)] = aws.ToString(param.ParameterValue)
+	}
+	if len(desc.Parameters) != len(params) {
+		return true
+	}
+	for _, param := range desc.Parameters {
+		if value, ok := params[aws.ToString(param.ParameterKey)]; !ok || value != aws.ToString(param.ParameterValue) {
+			return true
+		}
+	}
+
+	tags := make(map[string]string, len(clientStackSet.Tags))
+	for _, tag := range clientStackSet.Tags {
+		tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+	}
+	if len(desc.Tags) != len(tags) {
+		return true
+	}
+	for _, tag := range desc.Tags {
+		if value, ok := tags[aws.ToString(tag.Key)]; !ok || value != aws.ToString(tag.Value) {
+			return true
+		}
+	}
+
+	if desc.PermissionModel != clientStackSet.PermissionModel {
+		return true
+	}
+	if clientStackSet.AdministrationRoleARN != "" && aws.ToString(desc.AdministrationRoleARN) != clientStackSet.AdministrationRoleARN {
+		return true
+	}
+	if clientStackSet.ExecutionRoleName != "" && aws.ToString(desc.ExecutionRoleName) != clientStackSet.ExecutionRoleName {
+		return true
+	}
+	if want := clientStackSet.AutoDeployment; want != nil {
+		got := desc.AutoDeployment
+		if got == nil || aws.ToBool(got.Enabled) != aws.ToBool(want.Enabled) ||
+			aws.ToBool(got.RetainStacksOnAccountRemoval) != aws.ToBool(want.RetainStacksOnAccountRemoval) {
+			return true
+		}
+	}
+	return false
+}
+
+// stackInstanceSet maps the deployment targets of stack instances (accounts or organizational units) to their regions
+type stackInstanceSet map[string]map[string]bool
+
+func (s stackInstanceSet) add(target, region string) {
+	if s[target] == nil {
+		s[target] = map[string]bool{}
+	}
+	s[target][region] = true
+}
+
+// desiredStackInstances returns the stack instances in the stack set spec
+func desiredStackInstances(cfnStackSet cfnv1.CloudFormationStackSet) stackInstanceSet {
+	targets := cfnStackSet.Spec.StackInstances.Accounts
+	if isServiceManaged(cfnStackSet.Spec.PermissionModel) {
+		targets = cfnStackSet.Spec.StackInstances.OrganizationalUnitIDs
+	}
+
+	desired := stackInstanceSet{}
+	for _, target := range targets {
+		for _, region := range cfnStackSet.Spec.StackInstances.Regions {
+			desired.add(target, region)
+		}
+	}
+	return desired
+}
+
+// existingStackInstances returns the stack instances of the stack set, by account or by organizational unit
+func existingStackInstances(cfnStackSet cfnv1.CloudFormationStackSet, instances []*types.StackInstanceSummary) stackInstanceSet {
+	existing := stackInstanceSet{}
+	for _, instance := range instances {
+		target := aws.ToString(instance.Account)
+		if isServiceManaged(cfnStackSet.Spec.PermissionModel) {
+			target = aws.ToString(instance.OrganizationalUnitId)
+		}
+		existing.add(target, aws.ToString(instance.Region))
+	}
+	return existing
+}
+
+// nextStackInstanceTargets returns the targets of the next stack set operation to create or delete the stack instances
+// in 'from' that are not in 'to', or nil if there are none. Targets that are missing the same regions are grouped
+// into the same operation.
+func nextStackInstanceTargets(from stackInstanceSet, to stackInstanceSet, permissionModel string) *types.StackInstanceTargets {
+	missingRegions := map[string][]string{}
+	var targets []string
+	for target, regions := range from {
+		var missing []string
+		for region := range regions {
+			if !to[target][region] {
+				missing = append(missing, region)
+			}
+		}
+		if len(missing) > 0 {
+			sort.Strings(missing)
+			missingRegions[target] = missing
+			targets = append(targets, target)
+		}
+	}
+	if len(targets) == 0 {
+		return nil
+	}
+	sort.Strings(targets)
+
+	regions := missingRegions[targets[0]]
+	regionsKey := strings.Join(regions, ",")
+	next := &types.StackInstanceTargets{Regions: regions}
+	for _, target := range targets {
+		if strings.Join(missingRegions[target], ",") != regionsKey {
+			continue
+		}
+		if isServiceManaged(permissionModel) {
+			next.OrganizationalUnitIDs = append(next.OrganizationalUnitIDs, target)
+		} else {
+			next.Accounts = append(next.Accounts, target)
+		}
+	}
+	return next
+}
+
+func isServiceManaged(permissionModel string) bool {
+	return permissionModel == string(sdktypes.PermissionModelsServiceManaged)
+}
+
+// countOutdatedStackInstances returns the number of stack instances that are not up to date with the stack set
+func countOutdatedStackInstances(instances []*types.StackInstanceSummary) int {
+	count := 0
+	for _, instance := range instances {
+		if !instance.IsCurrent() {
+			count++
+		}
+	}
+	return count
+}
+
+// stackInstanceStatuses converts the stack instance summaries into the stack set's status
+func stackInstanceStatuses(instances []*types.StackInstanceSummary) []cfnv1.StackInstanceStatus {
+	var statuses []cfnv1.StackInstanceStatus
+	for _, instance := range instances {
+		status := cfnv1.StackInstanceStatus{
+			Account:              aws.ToString(instance.Account),
+			Region:               aws.ToString(instance.Region),
+			OrganizationalUnitID: aws.ToString(instance.OrganizationalUnitId),
+			Status:               string(instance.Status),
+			StatusReason:         aws.ToString(instance.StatusReason),
+		}
+		if instance.StackInstanceStatus != nil {
+			status.DetailedStatus = string(instance.StackInstanceStatus.DetailedStatus)
+		}
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		if statuses[i].Account != statuses[j].Account {
+			return statuses[i].Account < statuses[j].Account
+		}
+		return statuses[i].Region < statuses[j].Region
+	})
+	return statuses
+}
+
+func (r *CloudFormationStackSetReconciler) patchStatus(ctx context.Context, cfnStackSet *cfnv1.CloudFormationStackSet) error {
+	key := client.ObjectKeyFromObject(cfnStackSet)
+	latest := &cfnv1.CloudFormationStackSet{}
+	if err := r.Client.Get(ctx, key, latest); err != nil {
+		return err
+	}
+	patch := client.MergeFrom(latest.DeepCopy())
+	latest.Status = cfnStackSet.Status
+	return r.Client.Status().Patch(ctx, latest, patch, client.FieldOwner(r.ControllerName))
+}
+
+// event emits a Kubernetes event and forwards the event to notification controller if configured.
+func (r *CloudFormationStackSetReconciler) event(_ context.Context, cfnStackSet cfnv1.CloudFormationStackSet, revision, severity, msg string) {
+	var meta map[string]string
+	if revision != "" {
+		meta = map[string]string{cfnv1.GroupVersion.Group + "/revision": revision}
+	}
+	eventtype := "Normal"
+	if severity == eventv1.EventSeverityError {
+		eventtype = "Warning"
+	}
+	r.EventRecorder.AnnotatedEventf(&cfnStackSet, meta, eventtype, severity, msg)
+}
@@ -0,0 +1,323 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT-0
+
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	sdktypes "github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	cfnv1 "github.com/awslabs/aws-cloudformation-controller-for-flux/api/v1alpha1"
+	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/clients/cloudformation"
+	clientmocks "github.com/awslabs/aws-cloudformation-controller-for-flux/internal/clients/mocks"
+	clienttypes "github.com/awslabs/aws-cloudformation-controller-for-flux/internal/clients/types"
+	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/mocks"
+	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/template"
+	"github.com/fluxcd/pkg/apis/meta"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	mockStackSetName       = "mock-stack-set"
+	mockRealStackSetName   = "mock-real-stack-set"
+	mockStackSetOperation  = "mock-operation-id"
+	mockStackSetAccount1   = "111111111111"
+	mockStackSetAccount2   = "222222222222"
+	mockStackSetRegion1    = "us-east-1"
+	mockStackSetRegion2    = "us-west-2"
+	mockStackSetTemplate   = "mock-stack-set-template-body"
+	mockStackSetSourceRev  = "main@sha1:132f4e719209eb10b9485302f8593fc0e680f4fc"
+	mockStackSetController = "cfn-flux-controller"
+)
+
+func generateMockCfnStackSet() cfnv1.CloudFormationStackSet {
+	return cfnv1.CloudFormationStackSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       mockStackSetName,
+			Namespace:  mockNamespace,
+			Generation: mockGenerationId,
+		},
+		Spec: cfnv1.CloudFormationStackSetSpec{
+			StackSetName:    mockRealStackSetName,
+			TemplatePath:    mockTemplatePath,
+			Interval:        metav1.Duration{Duration: 5 * time.Hour},
+			PollInterval:    metav1.Duration{Duration: 30 * time.Second},
+			RetryInterval:   &metav1.Duration{Duration: 10 * time.Minute},
+			PermissionModel: string(sdktypes.PermissionModelsSelfManaged),
+			StackInstances: cfnv1.StackSetInstances{
+				Accounts: []string{mockStackSetAccount1, mockStackSetAccount2},
+				Regions:  []string{mockStackSetRegion1, mockStackSetRegion2},
+			},
+		},
+	}
+}
+
+func generateMockStackSetTags() []sdktypes.Tag {
+	return []sdktypes.Tag{
+		{Key: aws.String(mockStackSetController + "/version"), Value: aws.String("v0.0.1")},
+		{Key: aws.String(mockStackSetController + "/name"), Value: aws.String(mockStackSetName)},
+		{Key: aws.String(mockStackSetController + "/namespace"), Value: aws.String(mockNamespace)},
+	}
+}
+
+func generateMockStackInstance(account, region string, status sdktypes.StackInstanceStatus) *clienttypes.StackInstanceSummary {
+	return &clienttypes.StackInstanceSummary{
+		Account: aws.String(account),
+		Region:  aws.String(region),
+		Status:  status,
+	}
+}
+
+func generateMockStackInstances(status sdktypes.StackInstanceStatus) []*clienttypes.StackInstanceSummary {
+	return []*clienttypes.StackInstanceSummary{
+		generateMockStackInstance(mockStackSetAccount1, mockStackSetRegion1, status),
+		generateMockStackInstance(mockStackSetAccount1, mockStackSetRegion2, status),
+		generateMockStackInstance(mockStackSetAccount2, mockStackSetRegion1, status),
+		generateMockStackInstance(mockStackSetAccount2, mockStackSetRegion2, status),
+	}
+}
+
+func generateMockStackSetDescription() *clienttypes.StackSetDescription {
+	return &clienttypes.StackSetDescription{
+		StackSetName:    aws.String(mockRealStackSetName),
+		Status:          sdktypes.StackSetStatusActive,
+		TemplateBody:    aws.String(mockStackSetTemplate),
+		Tags:            generateMockStackSetTags(),
+		PermissionModel: sdktypes.PermissionModelsSelfManaged,
+	}
+}
+
+func TestCfnStackSetController_ReconcileStackSet(t *testing.T) {
+	testCases := map[string]struct {
+		lastOperationID     string
+		mockCfnClientCalls  func(cfnClient *clientmocks.MockCloudFormationStackSetClient)
+		wantedEvents        []*expectedEvent
+		wantedRequeue       time.Duration
+		wantedReason        string
+		wantedReady         metav1.ConditionStatus
+		wantedOperationID   string
+		wantedInstanceCount int
+	}{
+		"create stack set and stack instances when the stack set does not exist": {
+			mockCfnClientCalls: func(cfnClient *clientmocks.MockCloudFormationStackSetClient) {
+				cfnClient.EXPECT().DescribeStackSet(gomock.Any(), gomock.Any()).Return(nil, &cloudformation.ErrStackSetNotFound{})
+				cfnClient.EXPECT().CreateStackSet(gomock.Any(), gomock.Any()).Return(nil)
+				cfnClient.EXPECT().ListStackInstances(gomock.Any(), gomock.Any()).Return(nil, nil)
+				cfnClient.EXPECT().CreateStackInstances(gomock.Any(), gomock.Any(), &clienttypes.StackInstanceTargets{
+					Accounts: []string{mockStackSetAccount1, mockStackSetAccount2},
+					Regions:  []string{mockStackSetRegion1, mockStackSetRegion2},
+				}).Return(mockStackSetOperation, nil)
+			},
+			wantedEvents: []*expectedEvent{
+				{eventType: "Normal", severity: "info", message: "Created stack set 'mock-real-stack-set'"},
+				{eventType: "Normal", severity: "info", message: "Creation of stack instances for stack set 'mock-real-stack-set' in progress (operation mock-operation-id)"},
+			},
+			wantedRequeue:     30 * time.Second,
+			wantedReason:      meta.ProgressingReason,
+			wantedReady:       metav1.ConditionUnknown,
+			wantedOperationID: mockStackSetOperation,
+		},
+		"wait for the in-progress operation": {
+			lastOperationID: mockStackSetOperation,
+			mockCfnClientCalls: func(cfnClient *clientmocks.MockCloudFormationStackSetClient) {
+				cfnClient.EXPECT().DescribeStackSetOperation(gomock.Any(), gomock.Any(), mockStackSetOperation).Return(&clienttypes.StackSetOperationDescription{
+					Action: sdktypes.StackSetOperationActionCreate,
+					Status: sdktypes.StackSetOperationStatusRunning,
+				}, nil)
+			},
+			wantedRequeue:     30 * time.Second,
+			wantedReason:      meta.ProgressingReason,
+			wantedReady:       metav1.ConditionUnknown,
+			wantedOperationID: mockStackSetOperation,
+		},
+		"mark the stack set as failed when the operation failed": {
+			lastOperationID: mockStackSetOperation,
+			mockCfnClientCalls: func(cfnClient *clientmocks.MockCloudFormationStackSetClient) {
+				cfnClient.EXPECT().DescribeStackSetOperation(gomock.Any(), gomock.Any(), mockStackSetOperation).Return(&clienttypes.StackSetOperationDescription{
+					Action:       sdktypes.StackSetOperationActionUpdate,
+					Status:       sdktypes.StackSetOperationStatusFailed,
+					StatusReason: aws.String("oops"),
+				}, nil)
+			},
+			wantedEvents: []*expectedEvent{
+				{eventType: "Warning", severity: "error", message: "Operation mock-operation-id failed for stack set 'mock-real-stack-set': action 'UPDATE', status 'FAILED', reason 'oops'"},
+			},
+			wantedRequeue: 10 * time.Minute,
+			wantedReason:  cfnv1.StackSetOperationFailedReason,
+			wantedReady:   metav1.ConditionFalse,
+		},
+		"update the stack set when the template changed": {
+			lastOperationID: mockStackSetOperation,
+			mockCfnClientCalls: func(cfnClient *clientmocks.MockCloudFormationStackSetClient) {
+				cfnClient.EXPECT().DescribeStackSetOperation(gomock.Any(), gomock.Any(), mockStackSetOperation).Return(&clienttypes.StackSetOperationDescription{
+					Status: sdktypes.StackSetOperationStatusSucceeded,
+				}, nil)
+				descr := generateMockStackSetDescription()
+				descr.TemplateBody = aws.String("old-template-body")
+				cfnClient.EXPECT().DescribeStackSet(gomock.Any(), gomock.Any()).Return(descr, nil)
+				cfnClient.EXPECT().ListStackInstances(gomock.Any(), gomock.Any()).Return(generateMockStackInstances(sdktypes.StackInstanceStatusCurrent), nil)
+				cfnClient.EXPECT().UpdateStackSet(gomock.Any(), gomock.Any()).Return("mock-operation-id-2", nil)
+			},
+			wantedEvents: []*expectedEvent{
+				{eventType: "Normal", severity: "info", message: "Update of stack set 'mock-real-stack-set' in progress (operation mock-operation-id-2)"},
+			},
+			wantedRequeue:       30 * time.Second,
+			wantedReason:        meta.ProgressingReason,
+			wantedReady:         metav1.ConditionUnknown,
+			wantedOperationID:   "mock-operation-id-2",
+			wantedInstanceCount: 4,
+		},
+		"update the stack set when stack instances are outdated": {
+			mockCfnClientCalls: func(cfnClient *clientmocks.MockCloudFormationStackSetClient) {
+				cfnClient.EXPECT().DescribeStackSet(gomock.Any(), gomock.Any()).Return(generateMockStackSetDescription(), nil)
+				cfnClient.EXPECT().ListStackInstances(gomock.Any(), gomock.Any()).Return(generateMockStackInstances(sdktypes.StackInstanceStatusOutdated), nil)
+				cfnClient.EXPECT().UpdateStackSet(gomock.Any(), gomock.Any()).Return(mockStackSetOperation, nil)
+			},
+			wantedEvents: []*expectedEvent{
+				{eventType: "Normal", severity: "info", message: "Update of stack set 'mock-real-stack-set' in progress (operation mock-operation-id)"},
+			},
+			wantedRequeue:       30 * time.Second,
+			wantedReason:        meta.ProgressingReason,
+			wantedReady:         metav1.ConditionUnknown,
+			wantedOperationID:   mockStackSetOperation,
+			wantedInstanceCount: 4,
+		},
+		"create the stack instances in a new region": {
+			mockCfnClientCalls: func(cfnClient *clientmocks.MockCloudFormationStackSetClient) {
+				cfnClient.EXPECT().DescribeStackSet(gomock.Any(), gomock.Any()).Return(generateMockStackSetDescription(), nil)
+				cfnClient.EXPECT().ListStackInstances(gomock.Any(), gomock.Any()).Return([]*clienttypes.StackInstanceSummary{
+					generateMockStackInstance(mockStackSetAccount1, mockStackSetRegion1, sdktypes.StackInstanceStatusCurrent),
+					generateMockStackInstance(mockStackSetAccount2, mockStackSetRegion1, sdktypes.StackInstanceStatusCurrent),
+				}, nil)
+				cfnClient.EXPECT().CreateStackInstances(gomock.Any(), gomock.Any(), &clienttypes.StackInstanceTargets{
+					Accounts: []string{mockStackSetAccount1, mockStackSetAccount2},
+					Regions:  []string{mockStackSetRegion2},
+				}).Return(mockStackSetOperation, nil)
+			},
+			wantedEvents: []*expectedEvent{
+				{eventType: "Normal", severity: "info", message: "Creation of stack instances for stack set 'mock-real-stack-set' in progress (operation mock-operation-id)"},
+			},
+			wantedRequeue:       30 * time.Second,
+			wantedReason:        meta.ProgressingReason,
+			wantedReady:         metav1.ConditionUnknown,
+			wantedOperationID:   mockStackSetOperation,
+			wantedInstanceCount: 2,
+		},
+		"delete the stack instances of a removed account": {
+			mockCfnClientCalls: func(cfnClient *clientmocks.MockCloudFormationStackSetClient) {
+				cfnClient.EXPECT().DescribeStackSet(gomock.Any(), gomock.Any()).Return(generateMockStackSetDescription(), nil)
+				instances := append(generateMockStackInstances(sdktypes.StackInstanceStatusCurrent),
+					generateMockStackInstance("333333333333", mockStackSetRegion1, sdktypes.StackInstanceStatusCurrent))
+				cfnClient.EXPECT().ListStackInstances(gomock.Any(), gomock.Any()).Return(instances, nil)
+				cfnClient.EXPECT().DeleteStackInstances(gomock.Any(), gomock.Any(), &clienttypes.StackInstanceTargets{
+					Accounts: []string{"333333333333"},
+					Regions:  []string{mockStackSetRegion1},
+				}, false).Return(mockStackSetOperation, nil)
+			},
+			wantedEvents: []*expectedEvent{
+				{eventType: "Normal", severity: "info", message: "Deletion of stack instances of stack set 'mock-real-stack-set' in progress (operation mock-operation-id)"},
+			},
+			wantedRequeue:       30 * time.Second,
+			wantedReason:        meta.ProgressingReason,
+			wantedReady:         metav1.ConditionUnknown,
+			wantedOperationID:   mockStackSetOperation,
+			wantedInstanceCount: 5,
+		},
+		"mark the stack set as ready when all stack instances are current": {
+			mockCfnClientCalls: func(cfnClient *clientmocks.MockCloudFormationStackSetClient) {
+				cfnClient.EXPECT().DescribeStackSet(gomock.Any(), gomock.Any()).Return(generateMockStackSetDescription(), nil)
+				cfnClient.EXPECT().ListStackInstances(gomock.Any(), gomock.Any()).Return(generateMockStackInstances(sdktypes.StackInstanceStatusCurrent), nil)
+			},
+			wantedRequeue:       5 * time.Hour,
+			wantedReason:        meta.SucceededReason,
+			wantedReady:         metav1.ConditionTrue,
+			wantedInstanceCount: 4,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+
+			cfnClient := clientmocks.NewMockCloudFormationStackSetClient(mockCtrl)
+			s3Client := clientmocks.NewMockS3Client(mockCtrl)
+			eventRecorder := mocks.NewMockEventRecorder(mockCtrl)
+
+			if tc.mockCfnClientCalls != nil {
+				tc.mockCfnClientCalls(cfnClient)
+			}
+			var eventCalls []*gomock.Call
+			for _, e := range tc.wantedEvents {
+				eventCalls = append(eventCalls, eventRecorder.EXPECT().AnnotatedEventf(
+					gomock.Any(),
+					map[string]string{"cloudformation.contrib.fluxcd.io/revision": mockStackSetSourceRev},
+					e.eventType,
+					e.severity,
+					e.message,
+				))
+			}
+			gomock.InOrder(eventCalls...)
+
+			reconciler := &CloudFormationStackSetReconciler{
+				EventRecorder:     eventRecorder,
+				CfnClient:         cfnClient,
+				S3Client:          s3Client,
+				TemplateBucket:    mockTemplateUploadBucket,
+				ControllerName:    mockStackSetController,
+				ControllerVersion: "v0.0.1",
+			}
+
+			cfnStackSet := generateMockCfnStackSet()
+			cfnStackSet.Status.LastOperationID = tc.lastOperationID
+			tmpl := &template.Template{Path: mockTemplatePath, Body: mockStackSetTemplate}
+
+			reconciledStackSet, requeue, err := reconciler.reconcileStackSet(context.Background(), cfnStackSet, tmpl, mockStackSetSourceRev)
+
+			require.NoError(t, err)
+			require.Equal(t, tc.wantedRequeue, requeue)
+			require.Equal(t, tc.wantedOperationID, reconciledStackSet.Status.LastOperationID)
+			require.Len(t, reconciledStackSet.Status.StackInstances, tc.wantedInstanceCount)
+			readyCondition := apimeta.FindStatusCondition(reconciledStackSet.Status.Conditions, meta.ReadyCondition)
+			require.NotNil(t, readyCondition)
+			require.Equal(t, tc.wantedReady, readyCondition.Status)
+			require.Equal(t, tc.wantedReason, readyCondition.Reason)
+		})
+	}
+}
+
+func TestCfnStackSetController_NextStackInstanceTargets(t *testing.T) {
+	from := stackInstanceSet{}
+	from.add("a", "r1")
+	from.add("a", "r2")
+	from.add("b", "r2")
+	from.add("c", "r1")
+	from.add("c", "r2")
+
+	to := stackInstanceSet{}
+	to.add("b", "r1")
+
+	// Targets missing the same regions are grouped together
+	require.Equal(t, &clienttypes.StackInstanceTargets{
+		Accounts: []string{"a", "c"},
+		Regions:  []string{"r1", "r2"},
+	}, nextStackInstanceTargets(from, to, string(sdktypes.PermissionModelsSelfManaged)))
+
+	to.add("a", "r1")
+	to.add("a", "r2")
+	to.add("c", "r1")
+	to.add("c", "r2")
+	require.Equal(t, &clienttypes.StackInstanceTargets{
+		OrganizationalUnitIDs: []string{"b"},
+		Regions:               []string{"r2"},
+	}, nextStackInstanceTargets(from, to, string(sdktypes.PermissionModelsServiceManaged)))
+
+	to.add("b", "r2")
+	require.Nil(t, nextStackInstanceTargets(from, to, string(sdktypes.PermissionModelsSelfManaged)))
+}
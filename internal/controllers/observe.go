@@ -0,0 +1,125 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT-0
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	sdktypes "github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	cfnv1 "github.com/awslabs/aws-cloudformation-controller-for-flux/api/v1alpha1"
+	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/clients/cloudformation"
+	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/clients/types"
+	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/metrics"
+)
+
+// reconcileObserve mirrors the status, outputs and resources of a stack in Observe mode into the stack's status,
+// and marks the stack as ready if the stack was successfully deployed.
+// The stack is only described; it is never created, updated or deleted.
+func (r *CloudFormationStackReconciler) reconcileObserve(ctx context.Context, cfnStack cfnv1.CloudFormationStack) (cfnv1.CloudFormationStack, ctrl.Result, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	clientStack := &types.Stack{
+		Name: cfnStack.Spec.StackName,
+		// Region:         cfnStack.Spec.Region,
+	}
+
+	desc, err := r.CfnClient.DescribeStack(ctx, clientStack)
+	if err != nil {
+		var e *cloudformation.ErrStackNotFound
+		if errors.As(err, &e) {
+			metrics.DeleteStackStatus(r.stackMetricsLabels(cfnStack))
+			msg := fmt.Sprintf("Observed stack '%s' does not exist", clientStack.Name)
+			log.Info(msg)
+			cfnStack = clearObservedStack(cfnStack)
+			cfnStack.Status.StackID = ""
+			cfnStack = cfnv1.CloudFormationStackNotReady(cfnStack, cfnv1.ReadinessUpdate{Message: msg, Reason: cfnv1.StackNotFoundReason})
+			return cfnStack, ctrl.Result{RequeueAfter: cfnStack.GetRetryInterval()}, nil
+		}
+		msg := fmt.Sprintf("Failed to describe the stack '%s'", clientStack.Name)
+		log.Error(err, msg)
+		cfnStack = cfnv1.CloudFormationStackNotReady(cfnStack, cfnv1.ReadinessUpdate{Message: msg, Reason: cfnv1.CloudFormationApiCallFailedReason})
+		return cfnStack, ctrl.Result{RequeueAfter: cfnStack.GetRetryInterval()}, err
+	}
+
+	resources, err := r.CfnClient.DescribeStackResources(ctx, clientStack)
+	if err != nil {
+		msg := fmt.Sprintf("Failed to describe the resources of stack '%s'", clientStack.Name)
+		log.Error(err, msg)
+		cfnStack = cfnv1.CloudFormationStackNotReady(cfnStack, cfnv1.ReadinessUpdate{Message: msg, Reason: cfnv1.CloudFormationApiCallFailedReason})
+		return cfnStack, ctrl.Result{RequeueAfter: cfnStack.GetRetryInterval()}, err
+	}
+
+	metrics.RecordStackStatus(r.stackMetricsLabels(cfnStack), string(desc.StackStatus))
+	cfnStack.Status.StackID = aws.ToString(desc.StackId)
+	cfnStack.Status.StackStatus = string(desc.StackStatus)
+	cfnStack.Status.Outputs = stackOutputs(desc.Outputs)
+	cfnStack.Status.Resources = stackResources(resources)
+
+	if desc.InProgress() {
+		msg := fmt.Sprintf("Stack action for observed stack '%s' is in progress (status: '%s')", clientStack.Name, desc.StackStatus)
+		log.Info(msg)
+		cfnStack = cfnv1.CloudFormationStackProgressing(cfnStack, cfnv1.ReadinessUpdate{Message: msg})
+		return cfnStack, ctrl.Result{RequeueAfter: r.stackActionPollInterval(cfnStack)}, nil
+	}
+
+	if desc.IsSuccess() {
+		log.Info(fmt.Sprintf("Successfully observed stack '%s' (status: '%s')", clientStack.Name, desc.StackStatus))
+		return cfnv1.CloudFormationStackReady(cfnStack, ""), ctrl.Result{RequeueAfter: cfnStack.Spec.Interval.Duration}, nil
+	}
+
+	reason := cfnv1.UnexpectedStatusReason
+	if desc.RequiresCleanup() {
+		reason = cfnv1.UnrecoverableStackFailureReason
+	} else if desc.RequiresRollbackContinuation() {
+		reason = cfnv1.StackRollbackFailureReason
+	}
+	msg := fmt.Sprintf("Observed stack '%s' is in a failed state (status '%s'", clientStack.Name, desc.StackStatus)
+	if desc.StackStatusReason != nil {
+		msg = fmt.Sprintf("%s, reason '%s'", msg, *desc.StackStatusReason)
+	}
+	msg = fmt.Sprintf("%s)", msg)
+	log.Info(msg)
+	cfnStack = cfnv1.CloudFormationStackNotReady(cfnStack, cfnv1.ReadinessUpdate{Message: msg, Reason: reason})
+	return cfnStack, ctrl.Result{RequeueAfter: cfnStack.GetRetryInterval()}, nil
+}
+
+// clearObservedStack removes the status, outputs and resources of an observed stack from the stack's status.
+func clearObservedStack(cfnStack cfnv1.CloudFormationStack) cfnv1.CloudFormationStack {
+	cfnStack.Status.StackStatus = ""
+	cfnStack.Status.Outputs = nil
+	cfnStack.Status.Resources = nil
+	return cfnStack
+}
+
+// stackOutputs converts the outputs of a stack description into the stack's status outputs.
+func stackOutputs(outputs []sdktypes.Output) []cfnv1.StackOutput {
+	var statusOutputs []cfnv1.StackOutput
+	for _, output := range outputs {
+		statusOutputs = append(statusOutputs, cfnv1.StackOutput{
+			Key:        aws.ToString(output.OutputKey),
+			Value:      aws.ToString(output.OutputValue),
+			ExportName: aws.ToString(output.ExportName),
+		})
+	}
+	return statusOutputs
+}
+
+// stackResources converts the resources of a stack into the stack's status resources.
+func stackResources(resources []types.StackResource) []cfnv1.StackResource {
+	var statusResources []cfnv1.StackResource
+	for _, resource := range resources {
+		statusResources = append(statusResources, cfnv1.StackResource{
+			LogicalID:    aws.ToString(resource.LogicalResourceId),
+			PhysicalID:   aws.ToString(resource.PhysicalResourceId),
+			ResourceType: aws.ToString(resource.ResourceType),
+			Status:       string(resource.ResourceStatus),
+		})
+	}
+	return statusResources
+}
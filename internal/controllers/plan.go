@@ -0,0 +1,94 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT-0
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	sdktypes "github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	eventv1 "github.com/fluxcd/pkg/apis/event/v1beta1"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	cfnv1 "github.com/awslabs/aws-cloudformation-controller-for-flux/api/v1alpha1"
+	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/clients/types"
+	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/template"
+)
+
+// planOnly returns true if the stack's change sets must be planned, but not executed.
+func (r *CloudFormationStackReconciler) planOnly(cfnStack cfnv1.CloudFormationStack) bool {
+	return r.DryRun || cfnStack.Spec.Mode == cfnv1.PlanMode
+}
+
+// planChangeset records the changes of a change set that is ready for execution as the stack's plan,
+// then deletes the change set instead of executing it.
+// The change set of a stack that does not exist yet is deleted along with the stack that CloudFormation
+// created for it in the REVIEW_IN_PROGRESS status.
+func (r *CloudFormationStackReconciler) planChangeset(ctx context.Context, cfnStack cfnv1.CloudFormationStack, clientStack *types.Stack, tmpl *template.Template, revision string, desc *types.ChangeSetDescription, isCreate bool) (cfnv1.CloudFormationStack, time.Duration, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	var err error
+	if isCreate {
+		err = r.CfnClient.DeleteStack(ctx, clientStack)
+	} else {
+		err = r.CfnClient.DeleteChangeSet(ctx, clientStack)
+	}
+	if err != nil {
+		msg := fmt.Sprintf("Failed to delete a planned change set for stack '%s'", clientStack.Name)
+		log.Error(err, msg)
+		cfnStack = cfnv1.CloudFormationStackNotReady(cfnStack, cfnv1.ReadinessUpdate{
+			ChangeSetArn:   desc.Arn,
+			SourceRevision: revision,
+			Message:        msg,
+			Reason:         cfnv1.CloudFormationApiCallFailedReason,
+		})
+		return cfnStack, cfnStack.GetRetryInterval(), err
+	}
+
+	return r.planned(ctx, cfnStack, clientStack, tmpl, revision, desc.Arn, desc.Changes)
+}
+
+// planned marks the stack as planned with the given change set changes.
+func (r *CloudFormationStackReconciler) planned(ctx context.Context, cfnStack cfnv1.CloudFormationStack, clientStack *types.Stack, tmpl *template.Template, revision string, changeSetArn string, changes []sdktypes.Change) (cfnv1.CloudFormationStack, time.Duration, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	plan := stackPlan(changeSetArn, revision, changes)
+	msg := fmt.Sprintf("Planned changes for stack '%s' (change set %s): %s", clientStack.Name, changeSetArn, plan.Summary())
+	log.Info(msg)
+	r.event(ctx, cfnStack, revision, eventv1.EventSeverityInfo, msg)
+	r.cleanUpStackTemplates(ctx, &cfnStack, clientStack, tmpl)
+	return cfnv1.CloudFormationStackPlanned(cfnStack, plan), cfnStack.Spec.Interval.Duration, nil
+}
+
+// stackPlan summarizes the resource changes of a change set.
+func stackPlan(changeSetArn string, revision string, changes []sdktypes.Change) cfnv1.StackPlan {
+	plan := cfnv1.StackPlan{
+		ChangeSet: changeSetArn,
+		Revision:  revision,
+	}
+	for _, change := range changes {
+		resourceChange := change.ResourceChange
+		if resourceChange == nil {
+			continue
+		}
+		switch resourceChange.Action {
+		case sdktypes.ChangeActionAdd:
+			plan.Add++
+		case sdktypes.ChangeActionModify:
+			plan.Modify++
+		case sdktypes.ChangeActionRemove:
+			plan.Remove++
+		}
+		plan.Changes = append(plan.Changes, cfnv1.PlannedResourceChange{
+			Action:       string(resourceChange.Action),
+			LogicalID:    aws.ToString(resourceChange.LogicalResourceId),
+			PhysicalID:   aws.ToString(resourceChange.PhysicalResourceId),
+			ResourceType: aws.ToString(resourceChange.ResourceType),
+			Replacement:  string(resourceChange.Replacement),
+		})
+	}
+	return plan
+}
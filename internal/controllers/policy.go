@@ -0,0 +1,244 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT-0
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	sdktypes "github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	securejoin "github.com/cyphar/filepath-securejoin"
+	eventv1 "github.com/fluxcd/pkg/apis/event/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apitypes "k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	cfnv1 "github.com/awslabs/aws-cloudformation-controller-for-flux/api/v1alpha1"
+	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/clients/types"
+	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/policy"
+	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/template"
+)
+
+const policyFileSuffix = ".rego"
+
+// invalidPolicyError is a policy load failure that is not resolved by retrying, like policies that do not compile
+// or a policy path that does not exist in the policy source.
+type invalidPolicyError struct {
+	err error
+}
+
+func (e *invalidPolicyError) Error() string {
+	return e.err.Error()
+}
+
+func (e *invalidPolicyError) Unwrap() error {
+	return e.err
+}
+
+// loadPolicy loads and compiles the Rego policies referenced by the stack.
+// Returns nil if the stack does not reference any policies.
+func (r *CloudFormationStackReconciler) loadPolicy(ctx context.Context, cfnStack cfnv1.CloudFormationStack) (*policy.Policy, error) {
+	reference := cfnStack.Spec.PolicyRef
+	if reference == nil {
+		return nil, nil
+	}
+
+	var modules map[string]string
+	var err error
+	if reference.Kind == "ConfigMap" {
+		modules, err = r.loadConfigMapPolicies(ctx, cfnStack, *reference)
+	} else {
+		modules, err = r.loadArtifactPolicies(ctx, cfnStack, *reference)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(modules) == 0 {
+		return nil, &invalidPolicyError{fmt.Errorf("no policy files with the '%s' suffix found in %s '%s'", policyFileSuffix, reference.Kind, reference.Name)}
+	}
+
+	stackPolicy, err := policy.Compile(ctx, modules)
+	if err != nil {
+		return nil, &invalidPolicyError{err}
+	}
+	return stackPolicy, nil
+}
+
+// loadConfigMapPolicies returns the policy modules in the data entries of the referenced ConfigMap.
+func (r *CloudFormationStackReconciler) loadConfigMapPolicies(ctx context.Context, cfnStack cfnv1.CloudFormationStack, reference cfnv1.PolicyReference) (map[string]string, error) {
+	namespacedName := apitypes.NamespacedName{Namespace: cfnStack.GetNamespace(), Name: reference.Name}
+	configMap := &corev1.ConfigMap{}
+	if err := r.Get(ctx, namespacedName, configMap); err != nil {
+		return nil, fmt.Errorf("unable to get ConfigMap '%s': %w", namespacedName, err)
+	}
+
+	modules := make(map[string]string)
+	for key, value := range configMap.Data {
+		if strings.HasSuffix(key, policyFileSuffix) {
+			modules[key] = value
+		}
+	}
+	return modules, nil
+}
+
+// loadArtifactPolicies returns the policy modules in the policy file or directory of the referenced source artifact.
+func (r *CloudFormationStackReconciler) loadArtifactPolicies(ctx context.Context, cfnStack cfnv1.CloudFormationStack, reference cfnv1.PolicyReference) (map[string]string, error) {
+	sourceRef := cfnv1.SourceReference{Kind: reference.Kind, Name: reference.Name, Namespace: reference.Namespace}
+	sourceObj, err := getSourceObject(ctx, r.Client, r.NoCrossNamespaceRef, cfnStack.GetNamespace(), sourceRef)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve policy source '%s': %w", sourceRef.String(), err)
+	}
+	if sourceObj.GetArtifact() == nil {
+		return nil, fmt.Errorf("policy source '%s' is not ready, artifact not found", sourceRef.String())
+	}
+
+	cached, err := r.ArtifactCache.Get(ctx, r.httpClient, sourceObj.GetArtifact(), "")
+	if err != nil {
+		return nil, err
+	}
+	defer cached.Release()
+
+	artifactDir, err := cached.Extract(reference.Path)
+	if err != nil {
+		return nil, err
+	}
+	root, err := securejoin.SecureJoin(artifactDir, reference.Path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to join securely the artifact directory with policy path '%s'", reference.Path)
+	}
+
+	modules := make(map[string]string)
+	err = filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || !strings.HasSuffix(path, policyFileSuffix) || strings.HasSuffix(path, "_test"+policyFileSuffix) {
+			return nil
+		}
+		body, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		name, err := filepath.Rel(artifactDir, path)
+		if err != nil {
+			return err
+		}
+		modules[filepath.ToSlash(name)] = string(body)
+		return nil
+	})
+	if err != nil {
+		err = fmt.Errorf("unable to read policy files at path '%s' in policy source '%s': %w", reference.Path, sourceRef.String(), err)
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, &invalidPolicyError{err}
+		}
+		return nil, err
+	}
+	return modules, nil
+}
+
+// checkPolicy evaluates the stack's policies against the stack and the given change set changes,
+// and marks the stack as not ready if the stack violates the policies.
+// Returns true if the stack must not be deployed.
+func (r *CloudFormationStackReconciler) checkPolicy(ctx context.Context, stackPolicy *policy.Policy, cfnStack *cfnv1.CloudFormationStack, clientStack *types.Stack, tmpl *template.Template, revision string, changeSetArn string, changes []sdktypes.Change) (bool, error) {
+	if stackPolicy == nil {
+		return false, nil
+	}
+	log := ctrl.LoggerFrom(ctx)
+
+	input, err := policyInput(*cfnStack, clientStack, tmpl, changes)
+	var violations []string
+	if err == nil {
+		violations, err = stackPolicy.Evaluate(ctx, input)
+	}
+	if err != nil {
+		msg := fmt.Sprintf("Failed to evaluate policies for stack '%s'", clientStack.Name)
+		log.Error(err, msg)
+		*cfnStack = cfnv1.CloudFormationStackNotReady(*cfnStack, cfnv1.ReadinessUpdate{
+			ChangeSetArn:   changeSetArn,
+			SourceRevision: revision,
+			Message:        msg,
+			Reason:         cfnv1.PolicyFailedReason,
+		})
+		return true, err
+	}
+
+	if len(violations) == 0 {
+		return false, nil
+	}
+
+	msg := fmt.Sprintf("Stack '%s' violates policies: %s", clientStack.Name, strings.Join(violations, "; "))
+	log.Info(msg)
+	r.event(ctx, *cfnStack, revision, eventv1.EventSeverityError, msg)
+	*cfnStack = cfnv1.CloudFormationStackNotReady(*cfnStack, cfnv1.ReadinessUpdate{
+		ChangeSetArn:   changeSetArn,
+		SourceRevision: revision,
+		Message:        msg,
+		Reason:         cfnv1.PolicyViolationReason,
+		Stalled:        true,
+	})
+	return true, nil
+}
+
+// policyInput returns the input document of the stack's policies.
+func policyInput(cfnStack cfnv1.CloudFormationStack, clientStack *types.Stack, tmpl *template.Template, changes []sdktypes.Change) (*policy.Input, error) {
+	templateValue, err := tmpl.Value()
+	if err != nil {
+		return nil, err
+	}
+
+	input := &policy.Input{
+		Stack: policy.StackInput{
+			Name:      cfnStack.Name,
+			Namespace: cfnStack.Namespace,
+			StackName: clientStack.Name,
+		},
+		Template:   templateValue,
+		Parameters: make(map[string]string, len(clientStack.Parameters)),
+		Tags:       make(map[string]string, len(clientStack.Tags)),
+		Changes:    make([]interface{}, 0, len(changes)),
+	}
+	for _, param := range clientStack.Parameters {
+		input.Parameters[aws.ToString(param.ParameterKey)] = aws.ToString(param.ParameterValue)
+	}
+	for _, tag := range clientStack.Tags {
+		input.Tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+	}
+	for _, change := range changes {
+		input.Changes = append(input.Changes, change)
+	}
+	return input, nil
+}
+
+// policyLoadFailed marks the stack as not ready because its policies could not be loaded or compiled.
+// Policy sources that do not exist or policies that do not compile require a change to the policies or to
+// the stack's configuration, so the stack is marked as stalled and these failures are retried at the retry interval.
+// Other failures, like a policy source artifact that is temporarily unavailable, are returned as errors.
+func (r *CloudFormationStackReconciler) policyLoadFailed(ctx context.Context, cfnStack cfnv1.CloudFormationStack, revision string, err error) (cfnv1.CloudFormationStack, ctrl.Result, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	var invalidErr *invalidPolicyError
+	stalled := errors.As(err, &invalidErr) || apierrors.IsNotFound(err)
+
+	msg := fmt.Sprintf("Failed to load policies for stack '%s': %s", cfnStack.Spec.StackName, err.Error())
+	log.Error(err, msg)
+	r.event(ctx, cfnStack, revision, eventv1.EventSeverityError, msg)
+	cfnStack = cfnv1.CloudFormationStackNotReady(cfnStack, cfnv1.ReadinessUpdate{
+		Message:        msg,
+		Reason:         cfnv1.PolicyFailedReason,
+		SourceRevision: revision,
+		Stalled:        stalled,
+	})
+
+	if !stalled {
+		return cfnStack, ctrl.Result{RequeueAfter: cfnStack.GetRetryInterval()}, err
+	}
+	return cfnStack, ctrl.Result{RequeueAfter: cfnStack.GetRetryInterval()}, nil
+}
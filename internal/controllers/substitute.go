@@ -0,0 +1,66 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT-0
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	cfnv1 "github.com/awslabs/aws-cloudformation-controller-for-flux/api/v1alpha1"
+	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/build"
+)
+
+// loadPostBuildVariables loads the stack's post-build variables from the referenced ConfigMaps and Secrets,
+// and from the inline substitutions. Returns nil if the stack does not specify any post-build substitutions.
+func (r *CloudFormationStackReconciler) loadPostBuildVariables(ctx context.Context, cfnStack cfnv1.CloudFormationStack) (map[string]string, error) {
+	postBuild := cfnStack.Spec.PostBuild
+	if postBuild == nil {
+		return nil, nil
+	}
+
+	vars := make(map[string]string)
+	for _, reference := range postBuild.SubstituteFrom {
+		namespacedName := types.NamespacedName{Namespace: cfnStack.GetNamespace(), Name: reference.Name}
+		switch reference.Kind {
+		case "ConfigMap":
+			configMap := &corev1.ConfigMap{}
+			if err := r.Get(ctx, namespacedName, configMap); err != nil {
+				if apierrors.IsNotFound(err) && reference.Optional {
+					continue
+				}
+				return nil, fmt.Errorf("%w: unable to get ConfigMap '%s': %w", build.ErrSubstitutionFailed, namespacedName, err)
+			}
+			for name, value := range configMap.Data {
+				vars[name] = value
+			}
+		case "Secret":
+			secret := &corev1.Secret{}
+			if err := r.Get(ctx, namespacedName, secret); err != nil {
+				if apierrors.IsNotFound(err) && reference.Optional {
+					continue
+				}
+				return nil, fmt.Errorf("%w: unable to get Secret '%s': %w", build.ErrSubstitutionFailed, namespacedName, err)
+			}
+			for name, value := range secret.Data {
+				vars[name] = string(value)
+			}
+		default:
+			return nil, fmt.Errorf("%w: unsupported substitution reference kind '%s'", build.ErrSubstitutionFailed, reference.Kind)
+		}
+	}
+
+	for name, value := range postBuild.Substitute {
+		vars[name] = value
+	}
+
+	if err := build.ValidateVariables(vars); err != nil {
+		return nil, err
+	}
+
+	return vars, nil
+}
@@ -1,32 +1,37 @@
 package controllers
 
 import (
-	"bytes"
 	"context"
+	"errors"
 	"fmt"
-	"io"
-	"net/http"
-	"net/url"
-	"os"
-	"path/filepath"
+	"regexp"
+	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	cfnv1 "github.com/awslabs/aws-cloudformation-controller-for-flux/api/v1alpha1"
-	securejoin "github.com/cyphar/filepath-securejoin"
+	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/artifact"
+	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/build"
+	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/clients/cloudformation"
+	clienttypes "github.com/awslabs/aws-cloudformation-controller-for-flux/internal/clients/types"
+	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/metrics"
+	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/stackevents"
+	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/template"
+	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/tracing"
 	eventv1 "github.com/fluxcd/pkg/apis/event/v1beta1"
 	"github.com/fluxcd/pkg/apis/meta"
 	"github.com/fluxcd/pkg/runtime/acl"
 	"github.com/fluxcd/pkg/runtime/dependency"
-	"github.com/fluxcd/pkg/untar"
 	sourcev1 "github.com/fluxcd/source-controller/api/v1"
 	sourcev1b2 "github.com/fluxcd/source-controller/api/v1beta2"
 	"github.com/hashicorp/go-retryablehttp"
-	"github.com/opencontainers/go-digest"
-	_ "github.com/opencontainers/go-digest/blake3"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	apimeta "k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
@@ -94,6 +99,122 @@ func (r *CloudFormationStackReconciler) requestsForRevisionChangeOf(indexKey str
 	}
 }
 
+// indexByStackID indexes the CloudFormationStacks by their recorded stack IDs
+func indexByStackID(o client.Object) []string {
+	stackID := o.(*cfnv1.CloudFormationStack).Status.StackID
+	if stackID == "" {
+		return nil
+	}
+	return []string{stackID}
+}
+
+// indexByStackName indexes the CloudFormationStacks that have no recorded stack ID by their stack names
+func indexByStackName(o client.Object) []string {
+	cfnStack := o.(*cfnv1.CloudFormationStack)
+	if cfnStack.Status.StackID != "" {
+		return nil
+	}
+	return []string{cfnStack.Spec.StackName}
+}
+
+// EnqueueStackStatusChange enqueues the reconciliation of the CloudFormationStacks of a stack whose status changed.
+// The CloudFormationStacks are found by their recorded stack ID. CloudFormationStacks that have no recorded
+// stack ID yet, for example because their stack is being created, are found by their stack name,
+// if the stack is in the controller's account and region.
+func (r *CloudFormationStackReconciler) EnqueueStackStatusChange(ctx context.Context, change stackevents.StackStatusChange) error {
+	if r.stackEvents == nil {
+		return errors.New("the reconciliation of stacks on stack status change events is not enabled")
+	}
+
+	var list cfnv1.CloudFormationStackList
+	if err := r.List(ctx, &list, client.MatchingFields{
+		cfnv1.StackIDIndexKey: change.StackID,
+	}); err != nil {
+		return fmt.Errorf("failed to list CloudFormation stacks: %w", err)
+	}
+	if (r.Region == "" || change.Region == r.Region) && (r.Account == "" || change.Account == r.Account) {
+		var byName cfnv1.CloudFormationStackList
+		if err := r.List(ctx, &byName, client.MatchingFields{
+			cfnv1.StackNameIndexKey: change.StackName,
+		}); err != nil {
+			return fmt.Errorf("failed to list CloudFormation stacks: %w", err)
+		}
+		list.Items = append(list.Items, byName.Items...)
+	}
+	for i := range list.Items {
+		ctrl.LoggerFrom(ctx).V(1).Info(fmt.Sprintf("Status of stack '%s' changed to '%s'", change.StackName, change.Status),
+			"CloudFormationStack", client.ObjectKeyFromObject(&list.Items[i]).String())
+		select {
+		case r.stackEvents <- event.GenericEvent{Object: &list.Items[i]}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// stackActionPollInterval returns the interval at which a stack action in progress is polled.
+// When stack status change events are received, the stack is reconciled as soon as the stack action completes,
+// so the stack action is only polled at the longer stack events poll interval in case an event is lost.
+func (r *CloudFormationStackReconciler) stackActionPollInterval(cfnStack cfnv1.CloudFormationStack) time.Duration {
+	if cfnStack.Spec.PollInterval.Duration > r.stackEventsPollInterval {
+		return cfnStack.Spec.PollInterval.Duration
+	}
+	return r.stackEventsPollInterval
+}
+
+// describeStack describes the stack of a CloudFormationStack.
+// The stack of a ready CloudFormationStack is described from the stack cache, unless the cached stack
+// is in progress or was not found. The other stacks are described directly, and their descriptions are cached.
+func (r *CloudFormationStackReconciler) describeStack(ctx context.Context, cfnStack cfnv1.CloudFormationStack, clientStack *clienttypes.Stack) (*clienttypes.StackDescription, error) {
+	if r.StackCache == nil {
+		return r.CfnClient.DescribeStack(ctx, clientStack)
+	}
+
+	if apimeta.IsStatusConditionTrue(cfnStack.Status.Conditions, meta.ReadyCondition) {
+		desc, err := r.StackCache.DescribeStack(ctx, clientStack)
+		if err == nil && !desc.InProgress() {
+			return desc, nil
+		}
+		var notFoundErr *cloudformation.ErrStackNotFound
+		if err != nil && !errors.As(err, &notFoundErr) {
+			ctrl.LoggerFrom(ctx).Info(fmt.Sprintf("Failed to describe stack '%s' from the stack cache: %s", clientStack.Name, err.Error()))
+		}
+	}
+
+	desc, err := r.CfnClient.DescribeStack(ctx, clientStack)
+	var notFoundErr *cloudformation.ErrStackNotFound
+	if err == nil {
+		r.StackCache.StoreStack(clientStack, desc)
+	} else if errors.As(err, &notFoundErr) {
+		r.StackCache.StoreStack(clientStack, nil)
+	}
+	return desc, err
+}
+
+// upToDate returns true if the stack's template, parameters, tags and options have the same digest
+// as the last applied change set, and the stack was not updated since the change set was applied.
+// Stacks with the force change set annotation and planned stacks are never up to date.
+func (r *CloudFormationStackReconciler) upToDate(cfnStack cfnv1.CloudFormationStack, desc *clienttypes.StackDescription, configDigest string) bool {
+	if r.planOnly(cfnStack) || !desc.IsSuccess() || cfnStack.GetAnnotations()[cfnv1.ForceChangeSetAnnotation] == "true" {
+		return false
+	}
+	if cfnStack.Status.LastAppliedConfigDigest != configDigest || cfnStack.Status.LastAppliedStackUpdateTime == nil {
+		return false
+	}
+	return cfnStack.Status.LastAppliedStackUpdateTime.Time.Equal(stackUpdateTime(desc))
+}
+
+// stackUpdateTime returns the last update time of the stack, or its creation time if it was never updated.
+// The time is truncated to the precision of the stack update time in the CloudFormationStack's status.
+func stackUpdateTime(desc *clienttypes.StackDescription) time.Time {
+	updateTime := aws.ToTime(desc.CreationTime)
+	if desc.LastUpdatedTime != nil {
+		updateTime = *desc.LastUpdatedTime
+	}
+	return updateTime.Truncate(time.Microsecond)
+}
+
 func (r *CloudFormationStackReconciler) patchStatus(ctx context.Context, cfnStack *cfnv1.CloudFormationStack) error {
 	key := client.ObjectKeyFromObject(cfnStack)
 	latest := &cfnv1.CloudFormationStack{}
@@ -107,26 +228,34 @@ func (r *CloudFormationStackReconciler) patchStatus(ctx context.Context, cfnStac
 
 // getSource retrieves the Source object for the CloudFormation stack template
 func (r *CloudFormationStackReconciler) getSource(ctx context.Context, cfnStack cfnv1.CloudFormationStack) (sourcev1.Source, error) {
+	if cfnStack.Spec.SourceRef == nil {
+		return nil, errSourceRefMissing
+	}
+	return getSourceObject(ctx, r.Client, r.NoCrossNamespaceRef, cfnStack.GetNamespace(), *cfnStack.Spec.SourceRef)
+}
+
+// getSourceObject retrieves the Source object referenced by an object in the given namespace
+func getSourceObject(ctx context.Context, kubeClient client.Client, noCrossNamespaceRef bool, namespace string, sourceRef cfnv1.SourceReference) (sourcev1.Source, error) {
 	var sourceObj sourcev1.Source
 
-	sourceNamespace := cfnStack.GetNamespace()
-	if cfnStack.Spec.SourceRef.Namespace != "" {
-		sourceNamespace = cfnStack.Spec.SourceRef.Namespace
+	sourceNamespace := namespace
+	if sourceRef.Namespace != "" {
+		sourceNamespace = sourceRef.Namespace
 	}
 	namespacedName := types.NamespacedName{
 		Namespace: sourceNamespace,
-		Name:      cfnStack.Spec.SourceRef.Name,
+		Name:      sourceRef.Name,
 	}
 
-	if r.NoCrossNamespaceRef && sourceNamespace != cfnStack.Namespace {
+	if noCrossNamespaceRef && sourceNamespace != namespace {
 		return nil, acl.AccessDeniedError(fmt.Sprintf("can't access '%s/%s', cross-namespace references have been blocked",
-			cfnStack.Spec.SourceRef.Kind, namespacedName))
+			sourceRef.Kind, namespacedName))
 	}
 
-	switch cfnStack.Spec.SourceRef.Kind {
+	switch sourceRef.Kind {
 	case sourcev1.GitRepositoryKind:
 		var repository sourcev1.GitRepository
-		err := r.Client.Get(ctx, namespacedName, &repository)
+		err := kubeClient.Get(ctx, namespacedName, &repository)
 		if err != nil {
 			if apierrors.IsNotFound(err) {
 				return sourceObj, err
@@ -136,7 +265,7 @@ func (r *CloudFormationStackReconciler) getSource(ctx context.Context, cfnStack
 		sourceObj = &repository
 	case sourcev1b2.BucketKind:
 		var bucket sourcev1b2.Bucket
-		err := r.Client.Get(ctx, namespacedName, &bucket)
+		err := kubeClient.Get(ctx, namespacedName, &bucket)
 		if err != nil {
 			if apierrors.IsNotFound(err) {
 				return sourceObj, err
@@ -146,7 +275,7 @@ func (r *CloudFormationStackReconciler) getSource(ctx context.Context, cfnStack
 		sourceObj = &bucket
 	case sourcev1b2.OCIRepositoryKind:
 		var repository sourcev1b2.OCIRepository
-		err := r.Client.Get(ctx, namespacedName, &repository)
+		err := kubeClient.Get(ctx, namespacedName, &repository)
 		if err != nil {
 			if apierrors.IsNotFound(err) {
 				return sourceObj, err
@@ -156,7 +285,7 @@ func (r *CloudFormationStackReconciler) getSource(ctx context.Context, cfnStack
 		sourceObj = &repository
 	default:
 		return sourceObj, fmt.Errorf("source `%s` kind '%s' not supported",
-			cfnStack.Spec.SourceRef.Name, cfnStack.Spec.SourceRef.Kind)
+			sourceRef.Name, sourceRef.Kind)
 	}
 	return sourceObj, nil
 }
@@ -206,99 +335,72 @@ func (r *CloudFormationStackReconciler) checkDependencies(cfnStack cfnv1.CloudFo
 	return nil
 }
 
-// loadCloudFormationTemplate attempts to download the artifact from the provided source,
-// loads the CloudFormation template file into memory, then removes the downloaded artifact.
-// It returns the loaded template on success, or returns an error.
-func (r *CloudFormationStackReconciler) loadCloudFormationTemplate(ctx context.Context, cfnStack cfnv1.CloudFormationStack, artifact *sourcev1.Artifact) (*bytes.Buffer, error) {
-	log := ctrl.LoggerFrom(ctx)
-
-	// download the artifact targz file
-	artifactURL := artifact.URL
-	if hostname := os.Getenv("SOURCE_CONTROLLER_LOCALHOST"); hostname != "" {
-		u, err := url.Parse(artifactURL)
-		if err != nil {
-			return nil, err
-		}
-		u.Host = hostname
-		artifactURL = u.String()
-	}
-
-	req, err := retryablehttp.NewRequest(http.MethodGet, artifactURL, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create a new request: %w", err)
-	}
-
-	resp, err := r.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to download artifact, error: %w", err)
+// templateDescription describes the stack's template for messages
+func templateDescription(cfnStack cfnv1.CloudFormationStack) string {
+	if assembly := cfnStack.Spec.CDKAssembly; assembly != nil {
+		return fmt.Sprintf("CDK stack '%s' of cloud assembly '%s'", assembly.StackID, assembly.Path)
 	}
-	defer func() {
-		if err := resp.Body.Close(); err != nil {
-			log.Error(err, "Error closing artifact download")
-		}
-	}()
+	return fmt.Sprintf("template '%s'", cfnStack.Spec.TemplatePath)
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to download artifact, status code: %s", resp.Status)
-	}
+// loadCloudFormationTemplate retrieves the artifact of the provided source from the artifact cache,
+// and loads the CloudFormation template file and any nested template files it references into memory.
+// If the stack is deployed from a CDK cloud assembly, the stack's synthesized template, parameters and file assets
+// are loaded from the assembly. If transform is not nil, it is applied to the contents of each template file.
+// It returns the loaded template and the artifact it was loaded from on success, or returns an error.
+// The artifact is the artifact of the stack's last applied revision if the provided artifact could not be downloaded.
+func (r *CloudFormationStackReconciler) loadCloudFormationTemplate(ctx context.Context, cfnStack cfnv1.CloudFormationStack, sourceArtifact *sourcev1.Artifact, transform template.Transform) (*template.Template, *sourcev1.Artifact, error) {
+	ctx, span := tracing.Start(ctx, "loadCloudFormationTemplate", trace.WithAttributes(attribute.String("flux.artifact.revision", sourceArtifact.Revision)))
+	tmpl, loadedArtifact, err := loadTemplate(ctx, r.ArtifactCache, r.httpClient, cfnStack.Spec.TemplatePath, cfnStack.Spec.CDKAssembly, sourceArtifact, cfnStack.Status.LastAppliedRevision, transform)
+	tracing.End(span, err)
+	return tmpl, loadedArtifact, err
+}
 
-	// verify checksum matches origin
-	var buf bytes.Buffer
-	if err := r.copyAndVerifyArtifact(artifact, &buf, resp.Body); err != nil {
-		return nil, err
-	}
+// loadTemplate retrieves the artifact from the artifact cache, and loads the template at the given path or the stack
+// from the given CDK cloud assembly, like loadCloudFormationTemplate.
+// Only the template files, or the files of the cloud assembly, are extracted from the artifact.
+// If the artifact cannot be downloaded, the template is loaded from the cached artifact at the fallback revision,
+// and the returned artifact is that artifact.
+func loadTemplate(ctx context.Context, artifactCache *artifact.Cache, httpClient *retryablehttp.Client, templatePath string, cdkAssembly *cfnv1.CDKAssembly, sourceArtifact *sourcev1.Artifact, fallbackRevision string, transform template.Transform) (*template.Template, *sourcev1.Artifact, error) {
+	log := ctrl.LoggerFrom(ctx)
 
-	// extract artifact into temp dir
-	tmpDir, err := os.MkdirTemp("", fmt.Sprintf("%s-%s", cfnStack.GetNamespace(), cfnStack.GetName()))
+	cached, err := artifactCache.Get(ctx, httpClient, sourceArtifact, fallbackRevision)
 	if err != nil {
-		msg := fmt.Sprintf("unable to create temp dir for namespace %s, name %s", cfnStack.GetNamespace(), cfnStack.GetName())
-		log.Error(err, msg)
-		return nil, fmt.Errorf(msg)
-	}
-	defer os.RemoveAll(tmpDir)
-
-	if _, err = untar.Untar(&buf, tmpDir); err != nil {
-		msg := fmt.Sprintf("failed to untar artifact, namespace %s, name %s", cfnStack.GetNamespace(), cfnStack.GetName())
-		log.Error(err, msg)
-		return nil, fmt.Errorf(msg)
+		return nil, nil, err
 	}
+	defer cached.Release()
 
-	// load the template file
-	templateFilePath, err := securejoin.SecureJoin(tmpDir, cfnStack.Spec.TemplatePath)
-	if err != nil {
-		msg := fmt.Sprintf("unable to join securely the artifact temp directory with template path '%s'", cfnStack.Spec.TemplatePath)
-		log.Error(err, msg)
-		return nil, fmt.Errorf(msg)
+	extractPath := templatePath
+	if cdkAssembly != nil {
+		extractPath = cdkAssembly.Path
 	}
-
-	templateBytes, err := os.ReadFile(filepath.Clean(templateFilePath))
+	var tmpl *template.Template
+	err = cached.Load(func(artifactDir string) (err error) {
+		tmpl, err = build.LoadTemplate(artifactDir, templatePath, cdkAssembly, transform)
+		return err
+	}, extractPath)
 	if err != nil {
-		msg := fmt.Sprintf("unable to read template file '%s' in the artifact temp directory", cfnStack.Spec.TemplatePath)
-		log.Error(err, msg)
-		return nil, fmt.Errorf(msg)
+		log.Error(err, "unable to load template")
+		return nil, nil, &invalidTemplateError{err: err}
 	}
-
-	return bytes.NewBuffer(templateBytes), nil
+	return tmpl, &cached.Artifact, nil
 }
 
-func (r *CloudFormationStackReconciler) copyAndVerifyArtifact(artifact *sourcev1.Artifact, buf *bytes.Buffer, reader io.Reader) error {
-	dig, err := digest.Parse(artifact.Digest)
-	if err != nil {
-		return fmt.Errorf("failed to verify artifact: %w", err)
-	}
+// errInvalidTemplate matches the errors that occur when loading a template from a downloaded artifact,
+// like a template file that does not exist or cannot be parsed.
+// Unlike artifact download failures, these errors are not resolved by retrying with the same source revision.
+var errInvalidTemplate = errors.New("invalid template")
 
-	// Verify the downloaded artifact against the advertised digest.
-	verifier := dig.Verifier()
-	mw := io.MultiWriter(verifier, buf)
-	if _, err := io.Copy(mw, reader); err != nil {
-		return err
-	}
+type invalidTemplateError struct {
+	err error
+}
 
-	if !verifier.Verified() {
-		return fmt.Errorf("failed to verify artifact: computed digest doesn't match advertised '%s'", dig)
-	}
+func (e *invalidTemplateError) Error() string {
+	return e.err.Error()
+}
 
-	return nil
+func (e *invalidTemplateError) Unwrap() []error {
+	return []error{e.err, errInvalidTemplate}
 }
 
 // event emits a Kubernetes event and forwards the event to notification controller if configured.
@@ -313,3 +415,86 @@ func (r *CloudFormationStackReconciler) event(_ context.Context, cfnStack cfnv1.
 	}
 	r.EventRecorder.AnnotatedEventf(&cfnStack, meta, eventtype, severity, msg)
 }
+
+// buildOptions returns the controller settings that contribute to the deployment inputs of the stacks
+func (r *CloudFormationStackReconciler) buildOptions() build.Options {
+	return build.Options{
+		ControllerName:    r.ControllerName,
+		ControllerVersion: r.ControllerVersion,
+		StackTags:         r.StackTags,
+	}
+}
+
+// stackMetricsLabels returns the labels of the metrics recorded for the stack
+func (r *CloudFormationStackReconciler) stackMetricsLabels(cfnStack cfnv1.CloudFormationStack) metrics.StackLabels {
+	return metrics.StackLabels{
+		Kind:      cfnv1.CloudFormationStackKind,
+		Namespace: cfnStack.Namespace,
+		Name:      cfnStack.Name,
+		StackName: cfnStack.Spec.StackName,
+		Region:    r.Region,
+	}
+}
+
+// templateBucketOptions returns the settings for uploading the stack's templates to the template bucket,
+// with the stack's overrides applied to the controller's settings
+func (r *CloudFormationStackReconciler) templateBucketOptions(cfnStack cfnv1.CloudFormationStack) clienttypes.TemplateBucketOptions {
+	opts := r.TemplateBucketOptions
+	overrides := cfnStack.Spec.TemplateUpload
+	if overrides == nil {
+		return opts
+	}
+
+	if overrides.KMSKeyID != "" {
+		opts.KMSKeyID = overrides.KMSKeyID
+	}
+	if overrides.KeyPrefix != "" {
+		opts.KeyPrefix = overrides.KeyPrefix
+	}
+	if overrides.ACL != "" {
+		opts.ACL = overrides.ACL
+	}
+	if overrides.ExpectedBucketOwner != "" {
+		opts.ExpectedBucketOwner = overrides.ExpectedBucketOwner
+	}
+	if len(overrides.ObjectTags) > 0 {
+		tags := make(map[string]string, len(r.TemplateBucketOptions.ObjectTags)+len(overrides.ObjectTags))
+		for k, v := range r.TemplateBucketOptions.ObjectTags {
+			tags[k] = v
+		}
+		for _, tag := range overrides.ObjectTags {
+			tags[tag.Key] = tag.Value
+		}
+		opts.ObjectTags = tags
+	}
+	return opts
+}
+
+// templateObjectKeyPrefix returns the prefix of the template bucket object keys for the given stack
+func templateObjectKeyPrefix(clientStack *clienttypes.Stack) string {
+	return fmt.Sprintf("%sflux-%s-", clientStack.TemplateBucketOptions.KeyPrefix, clientStack.Name)
+}
+
+// templateObjectKey returns the template bucket object key for the given stack template,
+// derived from the stack name and the SHA-256 digest of the template contents
+func templateObjectKey(clientStack *clienttypes.Stack, tmpl *template.Template) string {
+	return fmt.Sprintf("%s%s.template", templateObjectKeyPrefix(clientStack), tmpl.Digest())
+}
+
+// templateObjectKeyPattern matches the template bucket object keys of a stack with the given key prefix, including
+// keys with a random UUID uploaded by previous controller versions. It does not match the keys of other stacks with
+// names that start with the given stack name.
+func templateObjectKeyPattern(prefix string) *regexp.Regexp {
+	return regexp.MustCompile("^" + regexp.QuoteMeta(prefix) +
+		"([0-9a-f]{64}|[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12})\\.template$")
+}
+
+// recordTemplateObjectKeyPrefix records in the stack status that templates were uploaded under the given key prefix
+func recordTemplateObjectKeyPrefix(cfnStack *cfnv1.CloudFormationStack, prefix string) {
+	for _, recorded := range cfnStack.Status.TemplateObjectKeyPrefixes {
+		if recorded == prefix {
+			return
+		}
+	}
+	cfnStack.Status.TemplateObjectKeyPrefixes = append(cfnStack.Status.TemplateObjectKeyPrefixes, prefix)
+}
@@ -0,0 +1,204 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT-0
+
+// Package metrics provides the Prometheus metrics of the CloudFormation stacks that the controller deploys,
+// of the AWS API calls that the controller makes, and of the controller's source artifact cache.
+// The metrics are registered with the controller-runtime metrics registry, and served on the controller's metrics endpoint.
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	crmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const namespace = "cfn_flux"
+
+// The outcomes of change set executions
+const (
+	ExecutionSucceeded = "succeeded"
+	ExecutionFailed    = "failed"
+)
+
+// The results of source artifact cache lookups
+const (
+	// ArtifactCacheHit is an artifact that was found in the cache
+	ArtifactCacheHit = "hit"
+	// ArtifactCacheMiss is an artifact that was downloaded into the cache
+	ArtifactCacheMiss = "miss"
+	// ArtifactCacheFallback is an artifact that could not be downloaded, replaced by the last verified artifact of its source
+	ArtifactCacheFallback = "fallback"
+	// ArtifactCacheFailed is an artifact that could not be downloaded or verified
+	ArtifactCacheFailed = "failed"
+)
+
+var stackLabelNames = []string{"kind", "namespace", "name", "stack_name", "region"}
+
+var (
+	stackStatus = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "stack_status",
+		Help:      "The CloudFormation status of the stack, set to 1 for the current status.",
+	}, append(stackLabelNames, "status"))
+
+	changeSetExecutions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "change_set_executions_total",
+		Help:      "The number of executed change sets of the stack, by outcome.",
+	}, append(stackLabelNames, "outcome"))
+
+	revisionApplyDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "revision_apply_duration_seconds",
+		Help:      "The time from the creation of a source revision to the stack being reconciled with that revision.",
+		Buckets:   prometheus.ExponentialBuckets(10, 2, 12),
+	}, stackLabelNames)
+
+	templateUploadBytes = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "template_upload_bytes_total",
+		Help:      "The number of bytes of templates and assets that were uploaded to the template bucket for the stack.",
+	}, stackLabelNames)
+
+	templateUploadDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "template_upload_duration_seconds",
+		Help:      "The latency of uploading templates and assets to the template bucket for the stack.",
+		Buckets:   prometheus.DefBuckets,
+	}, stackLabelNames)
+
+	apiCalls = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "aws_api_calls_total",
+		Help:      "The number of AWS API call attempts, including retries.",
+	}, []string{"service", "operation", "region"})
+
+	apiErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "aws_api_errors_total",
+		Help:      "The number of AWS API call attempts that failed, by error code.",
+	}, []string{"service", "operation", "region", "error_code"})
+
+	apiThrottles = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "aws_api_throttles_total",
+		Help:      "The number of AWS API call attempts that were throttled.",
+	}, []string{"service", "operation", "region"})
+
+	artifactCacheRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "artifact_cache_requests_total",
+		Help:      "The number of source artifact cache lookups, by result.",
+	}, []string{"result"})
+
+	artifactCacheEvictions = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "artifact_cache_evictions_total",
+		Help:      "The number of source artifacts that were evicted from the artifact cache.",
+	})
+
+	artifactCacheArtifacts = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "artifact_cache_artifacts",
+		Help:      "The number of source artifacts in the artifact cache.",
+	})
+
+	artifactCacheBytes = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "artifact_cache_bytes",
+		Help:      "The size of the archives and extracted files of the source artifacts in the artifact cache.",
+	})
+)
+
+func init() {
+	crmetrics.Registry.MustRegister(
+		stackStatus,
+		changeSetExecutions,
+		revisionApplyDuration,
+		templateUploadBytes,
+		templateUploadDuration,
+		apiCalls,
+		apiErrors,
+		apiThrottles,
+		artifactCacheRequests,
+		artifactCacheEvictions,
+		artifactCacheArtifacts,
+		artifactCacheBytes,
+	)
+}
+
+// StackLabels identifies the CloudFormationStack or CloudFormationStackSet object and the stack
+// that the metrics are recorded for.
+type StackLabels struct {
+	// Kind is the kind of the object, CloudFormationStack or CloudFormationStackSet
+	Kind      string
+	Namespace string
+	Name      string
+	StackName string
+	Region    string
+}
+
+func (l StackLabels) values(extra ...string) []string {
+	return append([]string{l.Kind, l.Namespace, l.Name, l.StackName, l.Region}, extra...)
+}
+
+// RecordStackStatus records the current CloudFormation status of the stack.
+func RecordStackStatus(labels StackLabels, status string) {
+	DeleteStackStatus(labels)
+	stackStatus.WithLabelValues(labels.values(status)...).Set(1)
+}
+
+// DeleteStackStatus removes the status of the stack, when the stack does not exist.
+func DeleteStackStatus(labels StackLabels) {
+	stackStatus.DeletePartialMatch(prometheus.Labels{
+		"kind":       labels.Kind,
+		"namespace":  labels.Namespace,
+		"name":       labels.Name,
+		"stack_name": labels.StackName,
+		"region":     labels.Region,
+	})
+}
+
+// RecordChangeSetExecution records a completed change set execution with the given outcome.
+func RecordChangeSetExecution(labels StackLabels, outcome string) {
+	changeSetExecutions.WithLabelValues(labels.values(outcome)...).Inc()
+}
+
+// RecordRevisionApplied records the time from the creation of the source revision until it was applied to the stack.
+func RecordRevisionApplied(labels StackLabels, revisionCreated time.Time) {
+	revisionApplyDuration.WithLabelValues(labels.values()...).Observe(time.Since(revisionCreated).Seconds())
+}
+
+// RecordTemplateUpload records an upload of a template or asset of the given size to the template bucket.
+func RecordTemplateUpload(labels StackLabels, size int, duration time.Duration) {
+	templateUploadBytes.WithLabelValues(labels.values()...).Add(float64(size))
+	templateUploadDuration.WithLabelValues(labels.values()...).Observe(duration.Seconds())
+}
+
+// RecordArtifactCacheRequest records a lookup of a source artifact in the artifact cache with the given result.
+func RecordArtifactCacheRequest(result string) {
+	artifactCacheRequests.WithLabelValues(result).Inc()
+}
+
+// RecordArtifactCacheEviction records the eviction of a source artifact from the artifact cache.
+func RecordArtifactCacheEviction() {
+	artifactCacheEvictions.Inc()
+}
+
+// RecordArtifactCacheSize records the number of source artifacts in the artifact cache and their size in bytes.
+func RecordArtifactCacheSize(artifacts int, size int64) {
+	artifactCacheArtifacts.Set(float64(artifacts))
+	artifactCacheBytes.Set(float64(size))
+}
+
+// DeleteStack removes all the metrics of the CloudFormationStack or CloudFormationStackSet object
+// of the given kind, after the object is deleted.
+func DeleteStack(objKind, objNamespace, objName string) {
+	labels := prometheus.Labels{"kind": objKind, "namespace": objNamespace, "name": objName}
+	stackStatus.DeletePartialMatch(labels)
+	changeSetExecutions.DeletePartialMatch(labels)
+	revisionApplyDuration.DeletePartialMatch(labels)
+	templateUploadBytes.DeletePartialMatch(labels)
+	templateUploadDuration.DeletePartialMatch(labels)
+}
@@ -0,0 +1,104 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT-0
+
+package metrics
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/smithy-go"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetrics_StackStatus(t *testing.T) {
+	labels := StackLabels{Kind: "CloudFormationStack", Namespace: "flux-system", Name: "my-stack", StackName: "my-cfn-stack", Region: "us-west-2"}
+	defer DeleteStack(labels.Kind, labels.Namespace, labels.Name)
+
+	RecordStackStatus(labels, "CREATE_IN_PROGRESS")
+	RecordStackStatus(labels, "CREATE_COMPLETE")
+
+	expected := `
+# HELP cfn_flux_stack_status The CloudFormation status of the stack, set to 1 for the current status.
+# TYPE cfn_flux_stack_status gauge
+cfn_flux_stack_status{kind="CloudFormationStack",name="my-stack",namespace="flux-system",region="us-west-2",stack_name="my-cfn-stack",status="CREATE_COMPLETE"} 1
+`
+	require.NoError(t, testutil.CollectAndCompare(stackStatus, strings.NewReader(expected)))
+
+	DeleteStackStatus(labels)
+	require.Equal(t, 0, testutil.CollectAndCount(stackStatus))
+}
+
+func TestMetrics_DeleteStack(t *testing.T) {
+	labels := StackLabels{Kind: "CloudFormationStack", Namespace: "flux-system", Name: "my-stack", StackName: "my-cfn-stack", Region: "us-west-2"}
+	otherLabels := StackLabels{Kind: "CloudFormationStack", Namespace: "flux-system", Name: "other-stack", StackName: "other-cfn-stack", Region: "us-west-2"}
+	defer DeleteStack(otherLabels.Kind, otherLabels.Namespace, otherLabels.Name)
+
+	for _, l := range []StackLabels{labels, otherLabels} {
+		RecordStackStatus(l, "UPDATE_COMPLETE")
+		RecordChangeSetExecution(l, ExecutionSucceeded)
+		RecordRevisionApplied(l, time.Now().Add(-time.Minute))
+		RecordTemplateUpload(l, 1024, time.Second)
+	}
+	RecordChangeSetExecution(labels, ExecutionFailed)
+
+	require.Equal(t, float64(1), testutil.ToFloat64(changeSetExecutions.WithLabelValues(labels.values(ExecutionFailed)...)))
+	require.Equal(t, float64(1024), testutil.ToFloat64(templateUploadBytes.WithLabelValues(labels.values()...)))
+
+	DeleteStack(labels.Kind, labels.Namespace, labels.Name)
+
+	require.Equal(t, 1, testutil.CollectAndCount(stackStatus))
+	require.Equal(t, 1, testutil.CollectAndCount(changeSetExecutions))
+	require.Equal(t, 1, testutil.CollectAndCount(revisionApplyDuration))
+	require.Equal(t, 1, testutil.CollectAndCount(templateUploadBytes))
+	require.Equal(t, 1, testutil.CollectAndCount(templateUploadDuration))
+}
+
+func TestMetrics_DeleteStackSet(t *testing.T) {
+	stackLabels := StackLabels{Kind: "CloudFormationStack", Namespace: "flux-system", Name: "my-stack", StackName: "my-cfn-stack", Region: "us-west-2"}
+	stackSetLabels := StackLabels{Kind: "CloudFormationStackSet", Namespace: "flux-system", Name: "my-stack", StackName: "my-cfn-stack-set", Region: "us-west-2"}
+	defer DeleteStack(stackLabels.Kind, stackLabels.Namespace, stackLabels.Name)
+
+	RecordStackStatus(stackLabels, "UPDATE_COMPLETE")
+	for _, l := range []StackLabels{stackLabels, stackSetLabels} {
+		RecordTemplateUpload(l, 1024, time.Second)
+	}
+
+	// Deleting a stack set does not delete the metrics of the stack with the same namespace and name
+	DeleteStack(stackSetLabels.Kind, stackSetLabels.Namespace, stackSetLabels.Name)
+
+	require.Equal(t, 1, testutil.CollectAndCount(stackStatus))
+	require.Equal(t, 1, testutil.CollectAndCount(templateUploadBytes))
+	require.Equal(t, float64(1024), testutil.ToFloat64(templateUploadBytes.WithLabelValues(stackLabels.values()...)))
+	require.Equal(t, 1, testutil.CollectAndCount(templateUploadDuration))
+}
+
+func TestMetrics_RecordAPICall(t *testing.T) {
+	RecordAPICall("CloudFormation", "DescribeStacks", "us-west-2", nil)
+	RecordAPICall("CloudFormation", "DescribeStacks", "us-west-2", &smithy.GenericAPIError{Code: "ValidationError", Message: "Stack does not exist"})
+	RecordAPICall("CloudFormation", "DescribeStacks", "us-west-2", &smithy.GenericAPIError{Code: "Throttling", Message: "Rate exceeded"})
+	RecordAPICall("CloudFormation", "DescribeStacks", "us-west-2", errors.New("connection reset"))
+
+	require.Equal(t, float64(4), testutil.ToFloat64(apiCalls.WithLabelValues("CloudFormation", "DescribeStacks", "us-west-2")))
+	require.Equal(t, float64(1), testutil.ToFloat64(apiErrors.WithLabelValues("CloudFormation", "DescribeStacks", "us-west-2", "ValidationError")))
+	require.Equal(t, float64(1), testutil.ToFloat64(apiErrors.WithLabelValues("CloudFormation", "DescribeStacks", "us-west-2", "Throttling")))
+	require.Equal(t, float64(1), testutil.ToFloat64(apiErrors.WithLabelValues("CloudFormation", "DescribeStacks", "us-west-2", "Unknown")))
+	require.Equal(t, float64(1), testutil.ToFloat64(apiThrottles.WithLabelValues("CloudFormation", "DescribeStacks", "us-west-2")))
+}
+
+func TestMetrics_ArtifactCache(t *testing.T) {
+	RecordArtifactCacheRequest(ArtifactCacheMiss)
+	RecordArtifactCacheRequest(ArtifactCacheHit)
+	RecordArtifactCacheRequest(ArtifactCacheHit)
+	RecordArtifactCacheEviction()
+	RecordArtifactCacheSize(2, 4096)
+
+	require.Equal(t, float64(1), testutil.ToFloat64(artifactCacheRequests.WithLabelValues(ArtifactCacheMiss)))
+	require.Equal(t, float64(2), testutil.ToFloat64(artifactCacheRequests.WithLabelValues(ArtifactCacheHit)))
+	require.Equal(t, float64(1), testutil.ToFloat64(artifactCacheEvictions))
+	require.Equal(t, float64(2), testutil.ToFloat64(artifactCacheArtifacts))
+	require.Equal(t, float64(4096), testutil.ToFloat64(artifactCacheBytes))
+}
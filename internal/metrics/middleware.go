@@ -0,0 +1,47 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT-0
+
+package metrics
+
+import (
+	"context"
+	"errors"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/smithy-go"
+	"github.com/aws/smithy-go/middleware"
+)
+
+// AddAPICallMetrics registers a middleware on an AWS SDK client's middleware stack that records the
+// calls, errors and throttles of the client's API calls. The middleware is added at the end of the
+// finalize step, after the retry middleware, so that each attempt of a call is recorded.
+func AddAPICallMetrics(stack *middleware.Stack) error {
+	return stack.Finalize.Add(middleware.FinalizeMiddlewareFunc("APICallMetrics", handleAPICallMetrics), middleware.After)
+}
+
+func handleAPICallMetrics(ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler) (middleware.FinalizeOutput, middleware.Metadata, error) {
+	out, metadata, err := next.HandleFinalize(ctx, in)
+	RecordAPICall(awsmiddleware.GetServiceID(ctx), awsmiddleware.GetOperationName(ctx), awsmiddleware.GetRegion(ctx), err)
+	return out, metadata, err
+}
+
+// RecordAPICall records an attempt of an AWS API call, and its error if the attempt failed.
+func RecordAPICall(service, operation, region string, err error) {
+	apiCalls.WithLabelValues(service, operation, region).Inc()
+	if err == nil {
+		return
+	}
+
+	errorCode := "Unknown"
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		errorCode = apiErr.ErrorCode()
+	}
+	apiErrors.WithLabelValues(service, operation, region, errorCode).Inc()
+
+	if retry.IsErrorThrottles(retry.DefaultThrottles).IsErrorThrottle(err) == aws.TrueTernary {
+		apiThrottles.WithLabelValues(service, operation, region).Inc()
+	}
+}
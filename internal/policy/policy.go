@@ -0,0 +1,129 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT-0
+
+// Package policy evaluates Open Policy Agent (OPA) Rego policies against CloudFormation stacks before they are deployed.
+//
+// Policies are Rego modules that add violation messages to the 'deny' set of the 'cloudformation' package:
+//
+//	package cloudformation
+//
+//	deny contains msg if {
+//		some name, resource in input.template.Resources
+//		resource.Type == "AWS::IAM::User"
+//		msg := sprintf("IAM user '%s' is not allowed", [name])
+//	}
+//
+// A violation can also be an object with a 'msg' field.
+package policy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+)
+
+// Query is the Rego query that returns the violations of the policies.
+const Query = "data.cloudformation.deny"
+
+// Policy is a set of compiled Rego policy modules.
+type Policy struct {
+	query rego.PreparedEvalQuery
+}
+
+// Input is the input document that the policies are evaluated against.
+type Input struct {
+	// Stack identifies the stack that is being deployed.
+	Stack StackInput `json:"stack"`
+
+	// Template is the parsed stack template, with YAML short form intrinsic functions in their JSON form.
+	Template interface{} `json:"template"`
+
+	// Parameters are the stack parameters, by key.
+	Parameters map[string]string `json:"parameters"`
+
+	// Tags are the stack tags, by key.
+	Tags map[string]string `json:"tags"`
+
+	// Changes are the changes of the stack's change set, as returned by the DescribeChangeSet API.
+	// Changes are empty when the policies are evaluated before the change set is created.
+	Changes []interface{} `json:"changes"`
+}
+
+// StackInput identifies the stack that is being deployed in the policy input document.
+type StackInput struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	StackName string `json:"stackName"`
+}
+
+// Compile compiles the given Rego modules, by file name.
+func Compile(ctx context.Context, modules map[string]string) (*Policy, error) {
+	names := make([]string, 0, len(modules))
+	for name := range modules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	// Policies are written in the Rego v1 syntax, without importing 'rego.v1'
+	options := []func(*rego.Rego){rego.Query(Query), rego.SetRegoVersion(ast.RegoV1)}
+	for _, name := range names {
+		options = append(options, rego.Module(name, modules[name]))
+	}
+
+	query, err := rego.New(options...).PrepareForEval(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("compile policies: %w", err)
+	}
+	return &Policy{query: query}, nil
+}
+
+// Evaluate evaluates the policies against the given input and returns the sorted violation messages.
+func (p *Policy) Evaluate(ctx context.Context, input *Input) ([]string, error) {
+	// Convert the input into a JSON document, so that the policies see the same field names as the AWS APIs
+	data, err := json.Marshal(input)
+	if err != nil {
+		return nil, fmt.Errorf("marshal policy input: %w", err)
+	}
+	var document interface{}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("unmarshal policy input: %w", err)
+	}
+
+	results, err := p.query.Eval(ctx, rego.EvalInput(document))
+	if err != nil {
+		return nil, fmt.Errorf("evaluate policies: %w", err)
+	}
+
+	var violations []string
+	for _, result := range results {
+		for _, expression := range result.Expressions {
+			values, ok := expression.Value.([]interface{})
+			if !ok {
+				return nil, fmt.Errorf("evaluate policies: %s must be a set of violations", Query)
+			}
+			for _, value := range values {
+				violations = append(violations, violationMessage(value))
+			}
+		}
+	}
+	sort.Strings(violations)
+	return violations, nil
+}
+
+// violationMessage returns the message of a violation, which is either a string or an object with a 'msg' field.
+func violationMessage(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case map[string]interface{}:
+		if msg, ok := v["msg"].(string); ok {
+			return msg
+		}
+	}
+	data, _ := json.Marshal(value)
+	return string(data)
+}
@@ -0,0 +1,128 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT-0
+
+package policy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	noIAMUsersPolicy = `package cloudformation
+
+deny contains msg if {
+	some name, resource in input.template.Resources
+	resource.Type == "AWS::IAM::User"
+	msg := sprintf("IAM user '%s' is not allowed", [name])
+}
+`
+
+	encryptedVolumesPolicy = `package cloudformation
+
+deny contains {"msg": msg} if {
+	some name, resource in input.template.Resources
+	resource.Type == "AWS::EC2::Volume"
+	not resource.Properties.Encrypted
+	msg := sprintf("volume '%s' must be encrypted", [name])
+}
+`
+
+	ownerTagPolicy = `package cloudformation
+
+deny contains "the stack must have an 'owner' tag" if {
+	not input.tags.owner
+}
+`
+
+	noReplacementPolicy = `package cloudformation
+
+deny contains msg if {
+	some change in input.changes
+	change.ResourceChange.Replacement == "True"
+	msg := sprintf("resource '%s' must not be replaced", [change.ResourceChange.LogicalResourceId])
+}
+`
+)
+
+func TestPolicy_Evaluate(t *testing.T) {
+	testCases := map[string]struct {
+		input            *Input
+		wantedViolations []string
+	}{
+		"no violations": {
+			input: &Input{
+				Template: map[string]interface{}{
+					"Resources": map[string]interface{}{
+						"Volume": map[string]interface{}{
+							"Type":       "AWS::EC2::Volume",
+							"Properties": map[string]interface{}{"Encrypted": true},
+						},
+					},
+				},
+				Tags: map[string]string{"owner": "me"},
+			},
+		},
+		"violations of several policies": {
+			input: &Input{
+				Template: map[string]interface{}{
+					"Resources": map[string]interface{}{
+						"User": map[string]interface{}{
+							"Type": "AWS::IAM::User",
+						},
+						"Volume": map[string]interface{}{
+							"Type":       "AWS::EC2::Volume",
+							"Properties": map[string]interface{}{"Size": 100},
+						},
+					},
+				},
+			},
+			wantedViolations: []string{
+				"IAM user 'User' is not allowed",
+				"the stack must have an 'owner' tag",
+				"volume 'Volume' must be encrypted",
+			},
+		},
+		"violations of change set changes": {
+			input: &Input{
+				Template: map[string]interface{}{},
+				Tags:     map[string]string{"owner": "me"},
+				Changes: []interface{}{
+					map[string]interface{}{
+						"Type": "Resource",
+						"ResourceChange": map[string]interface{}{
+							"LogicalResourceId": "Database",
+							"Replacement":       "True",
+						},
+					},
+				},
+			},
+			wantedViolations: []string{"resource 'Database' must not be replaced"},
+		},
+	}
+
+	policy, err := Compile(context.Background(), map[string]string{
+		"users.rego":       noIAMUsersPolicy,
+		"volumes.rego":     encryptedVolumesPolicy,
+		"tags.rego":        ownerTagPolicy,
+		"replacement.rego": noReplacementPolicy,
+	})
+	require.NoError(t, err)
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			violations, err := policy.Evaluate(context.Background(), tc.input)
+			require.NoError(t, err)
+			require.Equal(t, tc.wantedViolations, violations)
+		})
+	}
+}
+
+func TestPolicy_CompileError(t *testing.T) {
+	_, err := Compile(context.Background(), map[string]string{
+		"invalid.rego": "package cloudformation\n\ndeny contains msg if {\n",
+	})
+	require.ErrorContains(t, err, "compile policies")
+}
@@ -0,0 +1,139 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT-0
+
+// Package stackevents receives the CloudFormation stack status change events that Amazon EventBridge routes
+// to an Amazon SQS queue, so that the controller reconciles stacks as soon as their status changes
+// instead of frequently polling them.
+package stackevents
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	sqstypes "github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/go-logr/logr"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/clients"
+	clienttypes "github.com/awslabs/aws-cloudformation-controller-for-flux/internal/clients/types"
+)
+
+const (
+	// The maximum number of messages received at once
+	maxMessages = 10
+	// The duration of the long polling of the queue, in seconds
+	waitTimeSeconds = 20
+	// The interval between attempts to receive messages after a failure
+	defaultRetryInterval = 10 * time.Second
+)
+
+// client is the subset of the SQS API used by the consumer
+type client interface {
+	ReceiveMessage(ctx context.Context, params *sqs.ReceiveMessageInput, optFns ...func(*sqs.Options)) (*sqs.ReceiveMessageOutput, error)
+	DeleteMessageBatch(ctx context.Context, params *sqs.DeleteMessageBatchInput, optFns ...func(*sqs.Options)) (*sqs.DeleteMessageBatchOutput, error)
+}
+
+// Handler handles a stack status change.
+// When the handler returns an error, the message of the change is received again after the queue's visibility timeout.
+type Handler func(ctx context.Context, change StackStatusChange) error
+
+// Consumer receives the CloudFormation stack status change events from an SQS queue, and passes them to a handler.
+// Messages that do not contain a stack status change event are deleted from the queue without being handled.
+type Consumer struct {
+	client        client
+	queueURL      string
+	handler       Handler
+	retryInterval time.Duration
+	log           logr.Logger
+}
+
+// New creates a consumer of the stack status change events sent to the given SQS queue.
+func New(ctx context.Context, region string, queueURL string, opts clienttypes.ClientOptions, handler Handler) (*Consumer, error) {
+	cfg, err := clients.LoadConfig(ctx, region, opts)
+	if err != nil {
+		return nil, err
+	}
+	return NewWithClient(sqs.NewFromConfig(cfg), queueURL, handler), nil
+}
+
+// For passing a stand-in SQS client in tests
+func NewWithClient(client client, queueURL string, handler Handler) *Consumer {
+	return &Consumer{
+		client:        client,
+		queueURL:      queueURL,
+		handler:       handler,
+		retryInterval: defaultRetryInterval,
+		log:           ctrl.Log.WithName("stack-events"),
+	}
+}
+
+// Start receives and handles the events of the queue until the context is cancelled.
+// Implements the controller-runtime manager's Runnable interface.
+func (c *Consumer) Start(ctx context.Context) error {
+	c.log.Info("Receiving stack status change events", "queue", c.queueURL)
+	for ctx.Err() == nil {
+		if err := c.receive(ctx); err != nil && ctx.Err() == nil {
+			c.log.Error(err, "Failed to receive stack status change events", "queue", c.queueURL)
+			select {
+			case <-ctx.Done():
+			case <-time.After(c.retryInterval):
+			}
+		}
+	}
+	return nil
+}
+
+// NeedLeaderElection makes the consumer only run on the leader, like the controllers that it notifies.
+func (c *Consumer) NeedLeaderElection() bool {
+	return true
+}
+
+// receive long polls the queue for a batch of messages, handles their events and deletes the handled messages
+func (c *Consumer) receive(ctx context.Context) error {
+	out, err := c.client.ReceiveMessage(ctx, &sqs.ReceiveMessageInput{
+		QueueUrl:            aws.String(c.queueURL),
+		MaxNumberOfMessages: maxMessages,
+		WaitTimeSeconds:     waitTimeSeconds,
+	})
+	if err != nil {
+		return fmt.Errorf("unable to receive messages: %w", err)
+	}
+
+	var handled []sqstypes.DeleteMessageBatchRequestEntry
+	for i, msg := range out.Messages {
+		change, err := parseStackStatusChange(aws.ToString(msg.Body))
+		if err != nil {
+			// The message would fail to parse again, so it is deleted
+			c.log.Error(err, "Ignoring invalid stack status change event", "messageId", aws.ToString(msg.MessageId))
+		} else if change != nil {
+			if err := c.handler(ctx, *change); err != nil {
+				c.log.Error(err, "Failed to handle stack status change event", "messageId", aws.ToString(msg.MessageId), "stackId", change.StackID)
+				continue
+			}
+		}
+		handled = append(handled, sqstypes.DeleteMessageBatchRequestEntry{
+			Id:            aws.String(strconv.Itoa(i)),
+			ReceiptHandle: msg.ReceiptHandle,
+		})
+	}
+	if len(handled) == 0 {
+		return nil
+	}
+
+	deleted, err := c.client.DeleteMessageBatch(ctx, &sqs.DeleteMessageBatchInput{
+		QueueUrl: aws.String(c.queueURL),
+		Entries:  handled,
+	})
+	if err != nil {
+		return fmt.Errorf("unable to delete messages: %w", err)
+	}
+	if len(deleted.Failed) > 0 {
+		failure := deleted.Failed[0]
+		return fmt.Errorf("unable to delete %d messages: %s: %s", len(deleted.Failed), aws.ToString(failure.Code), aws.ToString(failure.Message))
+	}
+	return nil
+}
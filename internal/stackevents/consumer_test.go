@@ -0,0 +1,205 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT-0
+
+package stackevents
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/stretchr/testify/require"
+)
+
+const mockQueueURL = "https://sqs.us-west-2.amazonaws.com/123456789012/stack-events"
+
+// fakeQueue is a local stand-in for an SQS queue, that implements the ReceiveMessage and DeleteMessageBatch
+// actions of the SQS JSON protocol
+type fakeQueue struct {
+	mu       sync.Mutex
+	messages map[string]string // receipt handle to body
+	order    []string
+	receives int
+	deleted  []string
+	failing  bool
+	failures int
+}
+
+func newFakeQueue(bodies ...string) *fakeQueue {
+	q := &fakeQueue{messages: map[string]string{}}
+	for i, body := range bodies {
+		handle := fmt.Sprintf("receipt-%d", i)
+		q.messages[handle] = body
+		q.order = append(q.order, handle)
+	}
+	return q
+}
+
+func (q *fakeQueue) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var input map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil || input["QueueUrl"] != mockQueueURL {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+	if q.failing {
+		q.failures++
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"__type": "com.amazonaws.sqs#QueueDoesNotExist", "message": "The specified queue does not exist."}`)
+		return
+	}
+
+	switch r.Header.Get("X-Amz-Target") {
+	case "AmazonSQS.ReceiveMessage":
+		q.receives++
+		// All messages are received at once, and are received again until they are deleted
+		var messages []map[string]string
+		for i, handle := range q.order {
+			body, ok := q.messages[handle]
+			if !ok {
+				continue
+			}
+			sum := md5.Sum([]byte(body))
+			messages = append(messages, map[string]string{
+				"MessageId":     fmt.Sprintf("message-%d", i),
+				"ReceiptHandle": handle,
+				"Body":          body,
+				"MD5OfBody":     hex.EncodeToString(sum[:]),
+			})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"Messages": messages})
+	case "AmazonSQS.DeleteMessageBatch":
+		var successful []map[string]string
+		for _, entry := range input["Entries"].([]interface{}) {
+			entry := entry.(map[string]interface{})
+			handle := entry["ReceiptHandle"].(string)
+			delete(q.messages, handle)
+			q.deleted = append(q.deleted, handle)
+			successful = append(successful, map[string]string{"Id": entry["Id"].(string)})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"Successful": successful, "Failed": []interface{}{}})
+	default:
+		http.Error(w, "unsupported action", http.StatusBadRequest)
+	}
+}
+
+func (q *fakeQueue) receiveCount() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.receives
+}
+
+func (q *fakeQueue) failureCount() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.failures
+}
+
+func newStandInClient(t *testing.T, q *fakeQueue) *sqs.Client {
+	server := httptest.NewServer(q)
+	t.Cleanup(server.Close)
+	return sqs.New(sqs.Options{
+		Region:       "us-west-2",
+		BaseEndpoint: aws.String(server.URL),
+		Credentials:  aws.AnonymousCredentials{},
+	})
+}
+
+func TestConsumer(t *testing.T) {
+	failingStackID := "arn:aws:cloudformation:us-west-2:123456789012:stack/failing-stack/0f6b3e20-1f36-11ee-a0ae-0a1b2c3d4e5f"
+	q := newFakeQueue(
+		stackStatusChangeEvent(mockStackID, "UPDATE_IN_PROGRESS"),
+		`{"source": "aws.cloudformation", "detail-type": "CloudFormation Resource Status Change", "detail": {}}`,
+		"hello world",
+		stackStatusChangeEvent(failingStackID, "CREATE_COMPLETE"),
+		stackStatusChangeEvent(mockStackID, "UPDATE_COMPLETE"),
+	)
+
+	var mu sync.Mutex
+	var changes []StackStatusChange
+	consumer := NewWithClient(newStandInClient(t, q), mockQueueURL, func(ctx context.Context, change StackStatusChange) error {
+		mu.Lock()
+		defer mu.Unlock()
+		changes = append(changes, change)
+		if change.StackName == "failing-stack" {
+			return errors.New("some error")
+		}
+		return nil
+	})
+	require.True(t, consumer.NeedLeaderElection())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- consumer.Start(ctx)
+	}()
+	require.Eventually(t, func() bool { return q.receiveCount() >= 2 }, 5*time.Second, 10*time.Millisecond)
+	cancel()
+	require.NoError(t, <-done)
+
+	mu.Lock()
+	defer mu.Unlock()
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	// The stack status changes are handled, and all the other events are ignored
+	require.Equal(t, StackStatusChange{
+		StackID:   mockStackID,
+		StackName: "my-stack",
+		Region:    "us-west-2",
+		Account:   "123456789012",
+		Status:    "UPDATE_IN_PROGRESS",
+	}, changes[0])
+	require.Equal(t, "failing-stack", changes[1].StackName)
+	require.Equal(t, "UPDATE_COMPLETE", changes[2].Status)
+
+	// The messages are deleted once handled, except the message whose change failed to be handled
+	require.Equal(t, []string{"receipt-0", "receipt-1", "receipt-2", "receipt-4"}, q.deleted)
+	require.Equal(t, map[string]string{"receipt-3": stackStatusChangeEvent(failingStackID, "CREATE_COMPLETE")}, q.messages)
+	for _, change := range changes[3:] {
+		require.Equal(t, "failing-stack", change.StackName)
+	}
+}
+
+func TestConsumer_RetriesAfterFailure(t *testing.T) {
+	q := newFakeQueue(stackStatusChangeEvent(mockStackID, "UPDATE_COMPLETE"))
+	q.failing = true
+
+	handled := make(chan StackStatusChange, 10)
+	consumer := NewWithClient(newStandInClient(t, q), mockQueueURL, func(ctx context.Context, change StackStatusChange) error {
+		handled <- change
+		return nil
+	})
+	consumer.retryInterval = 10 * time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go consumer.Start(ctx)
+
+	// The consumer keeps trying to receive messages while the queue is failing
+	require.Eventually(t, func() bool { return q.failureCount() >= 3 }, 5*time.Second, 10*time.Millisecond)
+	require.Empty(t, handled)
+	q.mu.Lock()
+	q.failing = false
+	q.mu.Unlock()
+
+	select {
+	case change := <-handled:
+		require.Equal(t, "my-stack", change.StackName)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the stack status change was not handled after the queue recovered")
+	}
+}
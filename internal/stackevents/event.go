@@ -0,0 +1,76 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT-0
+
+package stackevents
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+)
+
+const (
+	// eventSource is the source of the EventBridge events sent by CloudFormation.
+	eventSource = "aws.cloudformation"
+	// stackStatusChangeDetailType is the detail type of the EventBridge events
+	// sent by CloudFormation when the status of a stack changes.
+	stackStatusChangeDetailType = "CloudFormation Stack Status Change"
+)
+
+// StackStatusChange is a change of the status of a CloudFormation stack.
+type StackStatusChange struct {
+	// StackID is the ARN of the stack.
+	StackID string
+	// StackName is the name of the stack.
+	StackName string
+	// Region is the AWS region of the stack.
+	Region string
+	// Account is the AWS account ID of the stack.
+	Account string
+	// Status is the new status of the stack, like UPDATE_COMPLETE.
+	Status string
+}
+
+// event is an EventBridge event, with the fields of the CloudFormation stack status change events
+type event struct {
+	Source     string `json:"source"`
+	DetailType string `json:"detail-type"`
+	Detail     struct {
+		StackID       string `json:"stack-id"`
+		StatusDetails struct {
+			Status string `json:"status"`
+		} `json:"status-details"`
+	} `json:"detail"`
+}
+
+// parseStackStatusChange parses the body of an SQS message that contains an EventBridge event.
+// Returns nil if the event is not a CloudFormation stack status change event.
+func parseStackStatusChange(body string) (*StackStatusChange, error) {
+	var e event
+	if err := json.Unmarshal([]byte(body), &e); err != nil {
+		return nil, fmt.Errorf("unable to parse EventBridge event: %w", err)
+	}
+	if e.Source != eventSource || e.DetailType != stackStatusChangeDetailType {
+		return nil, nil
+	}
+
+	stackID, err := arn.Parse(e.Detail.StackID)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse stack ID '%s': %w", e.Detail.StackID, err)
+	}
+	// The resource of a stack ID is stack/<stack name>/<unique ID>
+	resource := strings.Split(stackID.Resource, "/")
+	if len(resource) != 3 || resource[0] != "stack" || resource[1] == "" {
+		return nil, fmt.Errorf("unexpected stack ID '%s'", e.Detail.StackID)
+	}
+
+	return &StackStatusChange{
+		StackID:   e.Detail.StackID,
+		StackName: resource[1],
+		Region:    stackID.Region,
+		Account:   stackID.AccountID,
+		Status:    e.Detail.StatusDetails.Status,
+	}, nil
+}
@@ -0,0 +1,60 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT-0
+
+package stackevents
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const mockStackID = "arn:aws:cloudformation:us-west-2:123456789012:stack/my-stack/9c3a5a40-1f35-11ee-8f6d-0a1b2c3d4e5f"
+
+func stackStatusChangeEvent(stackID string, status string) string {
+	return `{
+  "version": "0",
+  "id": "6a7e8feb-b491-4cf7-a9f1-bf3703467718",
+  "detail-type": "CloudFormation Stack Status Change",
+  "source": "aws.cloudformation",
+  "account": "123456789012",
+  "time": "2023-07-11T19:54:21Z",
+  "region": "us-west-2",
+  "resources": ["` + stackID + `"],
+  "detail": {
+    "stack-id": "` + stackID + `",
+    "status-details": {
+      "status": "` + status + `",
+      "status-reason": ""
+    }
+  }
+}`
+}
+
+func TestParseStackStatusChange(t *testing.T) {
+	change, err := parseStackStatusChange(stackStatusChangeEvent(mockStackID, "UPDATE_COMPLETE"))
+	require.NoError(t, err)
+	require.Equal(t, &StackStatusChange{
+		StackID:   mockStackID,
+		StackName: "my-stack",
+		Region:    "us-west-2",
+		Account:   "123456789012",
+		Status:    "UPDATE_COMPLETE",
+	}, change)
+
+	// Other events are ignored
+	change, err = parseStackStatusChange(`{"source": "aws.cloudformation", "detail-type": "CloudFormation Resource Status Change", "detail": {}}`)
+	require.NoError(t, err)
+	require.Nil(t, change)
+	change, err = parseStackStatusChange(`{"source": "aws.s3", "detail-type": "Object Created", "detail": {}}`)
+	require.NoError(t, err)
+	require.Nil(t, change)
+
+	// Invalid events
+	_, err = parseStackStatusChange("hello world")
+	require.ErrorContains(t, err, "unable to parse EventBridge event")
+	_, err = parseStackStatusChange(stackStatusChangeEvent("my-stack", "UPDATE_COMPLETE"))
+	require.ErrorContains(t, err, "unable to parse stack ID 'my-stack'")
+	_, err = parseStackStatusChange(stackStatusChangeEvent("arn:aws:cloudformation:us-west-2:123456789012:stackset/my-stack-set:uuid", "UPDATE_COMPLETE"))
+	require.ErrorContains(t, err, "unexpected stack ID")
+}
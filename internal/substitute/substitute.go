@@ -0,0 +1,68 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT-0
+
+// Package substitute replaces ${VAR} style variable references in strings, similar to envsubst.
+//
+// CloudFormation templates use the same syntax for Fn::Sub references such as ${AWS::Region} or ${MyBucket.Arn},
+// so references to variables that are not defined are left untouched unless strict mode is enabled,
+// and references that are not valid variable names are always left untouched.
+package substitute
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	// ${VAR}, ${VAR:-default}, ${VAR:=default}, ${VAR-default}, ${VAR=default}, and the escaped form $${VAR}
+	referencePattern = regexp.MustCompile(`\$?\$\{([_a-zA-Z][_a-zA-Z0-9]*)(?:(:?[-=])([^}]*))?\}`)
+
+	variableNamePattern = regexp.MustCompile(`^[_a-zA-Z][_a-zA-Z0-9]*$`)
+)
+
+// ValidateVariableName returns an error if the given name cannot be used as a variable name.
+func ValidateVariableName(name string) error {
+	if !variableNamePattern.MatchString(name) {
+		return fmt.Errorf("'%s' is not a valid variable name, variable names must match '%s'", name, variableNamePattern.String())
+	}
+	return nil
+}
+
+// Substitute replaces the variable references in the input with the values of the given variables.
+//
+// A reference can specify a default value with ${VAR:-default} or ${VAR:=default}, which is used if the variable
+// is not defined or is empty, or with ${VAR-default} or ${VAR=default}, which is used if the variable is not defined.
+// A reference can be escaped with $${VAR}, which is replaced with the literal ${VAR}.
+//
+// If strict is true, an error is returned for references to variables that are not defined and have no default value.
+// Otherwise, these references are left untouched.
+func Substitute(input string, vars map[string]string, strict bool) (string, error) {
+	var undefined []string
+	output := referencePattern.ReplaceAllStringFunc(input, func(reference string) string {
+		if strings.HasPrefix(reference, "$$") {
+			return reference[1:]
+		}
+
+		match := referencePattern.FindStringSubmatch(reference)
+		name, operator, defaultValue := match[1], match[2], match[3]
+
+		value, ok := vars[name]
+		switch {
+		case ok && (value != "" || !strings.HasPrefix(operator, ":")):
+			return value
+		case operator != "":
+			return defaultValue
+		}
+
+		if strict {
+			undefined = append(undefined, name)
+		}
+		return reference
+	})
+
+	if len(undefined) > 0 {
+		return "", fmt.Errorf("variable '%s' is not defined", undefined[0])
+	}
+	return output, nil
+}
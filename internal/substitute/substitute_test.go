@@ -0,0 +1,77 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT-0
+
+package substitute
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubstitute(t *testing.T) {
+	vars := map[string]string{
+		"CLUSTER_NAME": "prod",
+		"region":       "us-west-2",
+		"EMPTY":        "",
+	}
+
+	testCases := map[string]struct {
+		input     string
+		strict    bool
+		wanted    string
+		wantedErr string
+	}{
+		"substitute defined variables": {
+			input:  "name: ${CLUSTER_NAME}-${region}",
+			wanted: "name: prod-us-west-2",
+		},
+		"use default values": {
+			input:  "${MISSING:-a} ${MISSING:=b} ${MISSING-c} ${MISSING=d} ${CLUSTER_NAME:-e}",
+			wanted: "a b c d prod",
+		},
+		"use default values for empty variables with colon operators": {
+			input:  "[${EMPTY:-a}] [${EMPTY:=b}] [${EMPTY-c}] [${EMPTY=d}] [${EMPTY}]",
+			wanted: "[a] [b] [] [] []",
+		},
+		"leave undefined variables untouched": {
+			input:  "!Sub 'arn:aws:s3:::${BucketName}/${CLUSTER_NAME}'",
+			wanted: "!Sub 'arn:aws:s3:::${BucketName}/prod'",
+		},
+		"leave CloudFormation pseudo parameters and attributes untouched": {
+			input:  "!Sub '${AWS::Region}-${MyBucket.Arn}-${!Literal}'",
+			strict: true,
+			wanted: "!Sub '${AWS::Region}-${MyBucket.Arn}-${!Literal}'",
+		},
+		"unescape escaped references": {
+			input:  "!Sub '$${BucketName}-${CLUSTER_NAME}'",
+			strict: true,
+			wanted: "!Sub '${BucketName}-prod'",
+		},
+		"fail on undefined variables in strict mode": {
+			input:     "${CLUSTER_NAME}-${BucketName}",
+			strict:    true,
+			wantedErr: "variable 'BucketName' is not defined",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			got, err := Substitute(tc.input, vars, tc.strict)
+			if tc.wantedErr != "" {
+				require.EqualError(t, err, tc.wantedErr)
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, tc.wanted, got)
+			}
+		})
+	}
+}
+
+func TestValidateVariableName(t *testing.T) {
+	require.NoError(t, ValidateVariableName("CLUSTER_NAME"))
+	require.NoError(t, ValidateVariableName("_private1"))
+	require.Error(t, ValidateVariableName("1st"))
+	require.Error(t, ValidateVariableName("cluster-name"))
+	require.Error(t, ValidateVariableName(""))
+}
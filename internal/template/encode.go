@@ -0,0 +1,120 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT-0
+
+package template
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// isJSON returns true if the template body is written in JSON rather than YAML
+func isJSON(body string) bool {
+	return strings.HasPrefix(strings.TrimSpace(body), "{")
+}
+
+// encode encodes the given root node of a modified copy of the template in the template's format:
+// templates written in JSON are encoded as JSON, with their keys in the same order, and other templates as YAML.
+func (t *Template) encode(root *yaml.Node) (string, error) {
+	var buf bytes.Buffer
+	if isJSON(t.Body) {
+		var compact bytes.Buffer
+		if err := writeJSON(&compact, documentContent(root)); err != nil {
+			return "", fmt.Errorf("encode template '%s': %w", t.Path, err)
+		}
+		if err := json.Indent(&buf, compact.Bytes(), "", "  "); err != nil {
+			return "", fmt.Errorf("encode template '%s': %w", t.Path, err)
+		}
+		buf.WriteString("\n")
+		return buf.String(), nil
+	}
+
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(root); err != nil {
+		return "", fmt.Errorf("encode template '%s': %w", t.Path, err)
+	}
+	if err := encoder.Close(); err != nil {
+		return "", fmt.Errorf("encode template '%s': %w", t.Path, err)
+	}
+	return buf.String(), nil
+}
+
+// writeJSON writes the node as compact JSON, keeping the order of the mapping keys.
+// Intrinsic functions in the YAML short form, for example added to a JSON template by a YAML patch,
+// are written in their JSON form.
+func writeJSON(buf *bytes.Buffer, node *yaml.Node) error {
+	if node == nil {
+		buf.WriteString("null")
+		return nil
+	}
+	if intrinsicFunctionName(node.Tag) != "" {
+		value, err := nodeValue(node)
+		if err != nil {
+			return err
+		}
+		return writeJSONValue(buf, value)
+	}
+
+	switch node.Kind {
+	case yaml.DocumentNode:
+		return writeJSON(buf, documentContent(node))
+	case yaml.AliasNode:
+		return writeJSON(buf, node.Alias)
+	case yaml.MappingNode:
+		buf.WriteString("{")
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if i > 0 {
+				buf.WriteString(",")
+			}
+			if err := writeJSONValue(buf, node.Content[i].Value); err != nil {
+				return err
+			}
+			buf.WriteString(":")
+			if err := writeJSON(buf, node.Content[i+1]); err != nil {
+				return err
+			}
+		}
+		buf.WriteString("}")
+		return nil
+	case yaml.SequenceNode:
+		buf.WriteString("[")
+		for i, child := range node.Content {
+			if i > 0 {
+				buf.WriteString(",")
+			}
+			if err := writeJSON(buf, child); err != nil {
+				return err
+			}
+		}
+		buf.WriteString("]")
+		return nil
+	default:
+		// Numbers, booleans and null keep their literal value when it is valid JSON, like in the original template
+		if node.ShortTag() != "!!str" && json.Valid([]byte(node.Value)) {
+			buf.WriteString(node.Value)
+			return nil
+		}
+		value, err := nodeValue(node)
+		if err != nil {
+			return err
+		}
+		return writeJSONValue(buf, value)
+	}
+}
+
+// writeJSONValue writes the value as compact JSON, without escaping HTML characters
+func writeJSONValue(buf *bytes.Buffer, value interface{}) error {
+	encoder := json.NewEncoder(buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return err
+	}
+	// the encoder terminates each value with a newline
+	buf.Truncate(buf.Len() - 1)
+	return nil
+}
@@ -0,0 +1,454 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT-0
+
+package template
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const patchDirectiveKey = "$patch"
+
+// Patch is a patch to apply to a template.
+type Patch struct {
+	// Patch is either a JSON6902 patch (a list of operations), or a strategic-merge-style patch (a mapping) that is
+	// merged into the patch target. Mappings are merged recursively, lists and scalar values are replaced,
+	// null values remove the key, and a mapping with '$patch: delete' removes the mapping.
+	// The patch can be written in YAML or JSON, and can contain CloudFormation short-form functions like !Ref.
+	Patch string
+
+	// LogicalID is a regular expression that selects the template resources to patch by logical ID.
+	LogicalID string
+
+	// Type is a regular expression that selects the template resources to patch by resource type.
+	Type string
+}
+
+// hasTarget returns true if the patch applies to selected resources, rather than to the whole template
+func (p *Patch) hasTarget() bool {
+	return p.LogicalID != "" || p.Type != ""
+}
+
+// ApplyPatches applies the given patches in order, and returns the patched template.
+// Patches with a target are applied to each matching resource: JSON6902 paths are relative to the resource,
+// and a strategic-merge-style patch with '$patch: delete' removes the resource from the template.
+// Patches without a target are applied to the whole template.
+// The patched template is encoded in the same format, JSON or YAML, as this template.
+// The patched template can only reference nested templates that were already loaded for this template.
+// If there are no patches, the template is returned unchanged.
+func (t *Template) ApplyPatches(patches []Patch) (*Template, error) {
+	if len(patches) == 0 {
+		return t, nil
+	}
+
+	// Work on a copy of the template, so that the original template is unchanged
+	var root yaml.Node
+	if err := yaml.Unmarshal([]byte(t.Body), &root); err != nil {
+		return nil, fmt.Errorf("parse template '%s': %w", t.Path, err)
+	}
+	doc := documentContent(&root)
+
+	for i, patch := range patches {
+		if err := applyPatch(doc, patch); err != nil {
+			return nil, fmt.Errorf("apply patch %d to template '%s': %w", i, t.Path, err)
+		}
+	}
+
+	body, err := t.encode(&root)
+	if err != nil {
+		return nil, err
+	}
+
+	patched := &Template{
+		Path:       t.Path,
+		Body:       body,
+		Parameters: t.Parameters,
+		Assets:     t.Assets,
+		root:       &root,
+	}
+
+	// Link the references in the patched template to the nested templates that were already loaded
+	loaded := make(map[string]*Template, len(t.Nested))
+	for _, ref := range t.Nested {
+		loaded[ref.Template.Path] = ref.Template
+	}
+	for _, ref := range findReferences(&root) {
+		nestedPath := path.Join(path.Dir(t.Path), filepath.ToSlash(ref.Location))
+		nested, ok := loaded[nestedPath]
+		if !ok {
+			return nil, fmt.Errorf("patched template '%s' references nested template '%s', which is not referenced by the original template", t.Path, nestedPath)
+		}
+		ref.Template = nested
+		patched.Nested = append(patched.Nested, ref)
+	}
+
+	return patched, nil
+}
+
+func applyPatch(doc *yaml.Node, patch Patch) error {
+	var patchRoot yaml.Node
+	if err := yaml.Unmarshal([]byte(patch.Patch), &patchRoot); err != nil {
+		return fmt.Errorf("parse patch: %w", err)
+	}
+	patchNode := documentContent(&patchRoot)
+	if patchNode == nil || (patchNode.Kind != yaml.SequenceNode && patchNode.Kind != yaml.MappingNode) {
+		return fmt.Errorf("patch must be a list of JSON6902 operations or a mapping to merge")
+	}
+
+	apply := func(target *yaml.Node) (*yaml.Node, error) {
+		if patchNode.Kind == yaml.SequenceNode {
+			return target, applyJSON6902(target, patchNode)
+		}
+		return mergePatch(target, patchNode), nil
+	}
+
+	if !patch.hasTarget() {
+		patched, err := apply(doc)
+		if err != nil {
+			return err
+		}
+		if patched == nil {
+			return fmt.Errorf("patch cannot delete the whole template")
+		}
+		*doc = *patched
+		return nil
+	}
+
+	logicalIDPattern, err := compileTargetPattern(patch.LogicalID)
+	if err != nil {
+		return fmt.Errorf("invalid logical ID target '%s': %w", patch.LogicalID, err)
+	}
+	typePattern, err := compileTargetPattern(patch.Type)
+	if err != nil {
+		return fmt.Errorf("invalid type target '%s': %w", patch.Type, err)
+	}
+
+	resources := mappingValue(doc, "Resources")
+	if resources == nil || resources.Kind != yaml.MappingNode {
+		return fmt.Errorf("template does not contain any resources")
+	}
+
+	matched := false
+	var content []*yaml.Node
+	for i := 0; i+1 < len(resources.Content); i += 2 {
+		key, resource := resources.Content[i], resources.Content[i+1]
+		resourceType := mappingValue(resource, "Type")
+		if !logicalIDPattern.MatchString(key.Value) ||
+			(patch.Type != "" && (resourceType == nil || !typePattern.MatchString(resourceType.Value))) {
+			content = append(content, key, resource)
+			continue
+		}
+
+		matched = true
+		patched, err := apply(resource)
+		if err != nil {
+			return fmt.Errorf("resource '%s': %w", key.Value, err)
+		}
+		if patched != nil {
+			content = append(content, key, patched)
+		}
+	}
+	if !matched {
+		return fmt.Errorf("no resources match the patch target")
+	}
+	resources.Content = content
+	return nil
+}
+
+// compileTargetPattern compiles a target regular expression that must match the whole value
+func compileTargetPattern(pattern string) (*regexp.Regexp, error) {
+	if pattern == "" {
+		return regexp.MustCompile(".*"), nil
+	}
+	return regexp.Compile("^(?:" + pattern + ")$")
+}
+
+// mergePatch merges the patch into the target node, and returns the merged node.
+// Returns nil if the patch deletes the target.
+func mergePatch(target *yaml.Node, patch *yaml.Node) *yaml.Node {
+	if patch.Kind != yaml.MappingNode {
+		return cloneNode(patch)
+	}
+	if directive := mappingValue(patch, patchDirectiveKey); directive != nil && directive.Value == "delete" {
+		return nil
+	}
+
+	if target == nil || target.Kind != yaml.MappingNode {
+		target = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	}
+	for i := 0; i+1 < len(patch.Content); i += 2 {
+		key, value := patch.Content[i], patch.Content[i+1]
+		if key.Value == patchDirectiveKey {
+			continue
+		}
+
+		existing := mappingValue(target, key.Value)
+		if value.Kind == yaml.ScalarNode && value.Tag == "!!null" {
+			removeMappingKey(target, key.Value)
+			continue
+		}
+		merged := mergePatch(existing, value)
+		if merged == nil {
+			removeMappingKey(target, key.Value)
+			continue
+		}
+		setMappingKey(target, key.Value, merged)
+	}
+	return target
+}
+
+// applyJSON6902 applies the JSON6902 operations to the target node
+func applyJSON6902(target *yaml.Node, operations *yaml.Node) error {
+	for i, operation := range operations.Content {
+		if operation.Kind != yaml.MappingNode {
+			return fmt.Errorf("operation %d is not a mapping", i)
+		}
+		op := mappingValue(operation, "op")
+		opPath := mappingValue(operation, "path")
+		if op == nil || opPath == nil {
+			return fmt.Errorf("operation %d must have an 'op' and a 'path'", i)
+		}
+		value := mappingValue(operation, "value")
+		from := mappingValue(operation, "from")
+
+		var err error
+		switch op.Value {
+		case "add", "replace", "test":
+			if value == nil {
+				return fmt.Errorf("operation %d (%s) must have a 'value'", i, op.Value)
+			}
+			switch op.Value {
+			case "add":
+				err = addNode(target, opPath.Value, cloneNode(value))
+			case "replace":
+				err = replaceNode(target, opPath.Value, cloneNode(value))
+			case "test":
+				var existing *yaml.Node
+				if existing, err = getNode(target, opPath.Value); err == nil && !nodesEqual(existing, value) {
+					err = fmt.Errorf("value at '%s' does not match", opPath.Value)
+				}
+			}
+		case "remove":
+			_, err = removeNode(target, opPath.Value)
+		case "move", "copy":
+			if from == nil {
+				return fmt.Errorf("operation %d (%s) must have a 'from'", i, op.Value)
+			}
+			var node *yaml.Node
+			if op.Value == "move" {
+				node, err = removeNode(target, from.Value)
+			} else {
+				node, err = getNode(target, from.Value)
+				node = cloneNode(node)
+			}
+			if err == nil {
+				err = addNode(target, opPath.Value, node)
+			}
+		default:
+			return fmt.Errorf("operation %d has unsupported op '%s'", i, op.Value)
+		}
+		if err != nil {
+			return fmt.Errorf("operation %d (%s %s): %w", i, op.Value, opPath.Value, err)
+		}
+	}
+	return nil
+}
+
+// parsePointer splits a JSON pointer into its unescaped reference tokens
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("path must start with '/'")
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+// getNode returns the node at the given JSON pointer
+func getNode(root *yaml.Node, pointer string) (*yaml.Node, error) {
+	tokens, err := parsePointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+	node := root
+	for _, token := range tokens {
+		if node, err = childNode(node, token); err != nil {
+			return nil, err
+		}
+	}
+	return node, nil
+}
+
+// parentNode returns the parent of the node at the given JSON pointer, and the last reference token
+func parentNode(root *yaml.Node, pointer string) (*yaml.Node, string, error) {
+	tokens, err := parsePointer(pointer)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(tokens) == 0 {
+		return nil, "", fmt.Errorf("path must not be the root of the patch target")
+	}
+	node := root
+	for _, token := range tokens[:len(tokens)-1] {
+		if node, err = childNode(node, token); err != nil {
+			return nil, "", err
+		}
+	}
+	return node, tokens[len(tokens)-1], nil
+}
+
+func childNode(node *yaml.Node, token string) (*yaml.Node, error) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		if child := mappingValue(node, token); child != nil {
+			return child, nil
+		}
+		return nil, fmt.Errorf("key '%s' not found", token)
+	case yaml.SequenceNode:
+		index, err := sequenceIndex(node, token, false)
+		if err != nil {
+			return nil, err
+		}
+		return node.Content[index], nil
+	}
+	return nil, fmt.Errorf("cannot find '%s' in a scalar value", token)
+}
+
+func sequenceIndex(node *yaml.Node, token string, allowEnd bool) (int, error) {
+	if allowEnd && token == "-" {
+		return len(node.Content), nil
+	}
+	index, err := strconv.Atoi(token)
+	if err != nil || index < 0 || index > len(node.Content) || (!allowEnd && index == len(node.Content)) {
+		return 0, fmt.Errorf("invalid list index '%s'", token)
+	}
+	return index, nil
+}
+
+func addNode(root *yaml.Node, pointer string, value *yaml.Node) error {
+	parent, token, err := parentNode(root, pointer)
+	if err != nil {
+		return err
+	}
+	switch parent.Kind {
+	case yaml.MappingNode:
+		setMappingKey(parent, token, value)
+		return nil
+	case yaml.SequenceNode:
+		index, err := sequenceIndex(parent, token, true)
+		if err != nil {
+			return err
+		}
+		parent.Content = append(parent.Content[:index], append([]*yaml.Node{value}, parent.Content[index:]...)...)
+		return nil
+	}
+	return fmt.Errorf("cannot add '%s' to a scalar value", token)
+}
+
+func replaceNode(root *yaml.Node, pointer string, value *yaml.Node) error {
+	parent, token, err := parentNode(root, pointer)
+	if err != nil {
+		return err
+	}
+	if _, err := childNode(parent, token); err != nil {
+		return err
+	}
+	if parent.Kind == yaml.SequenceNode {
+		index, _ := sequenceIndex(parent, token, false)
+		parent.Content[index] = value
+		return nil
+	}
+	setMappingKey(parent, token, value)
+	return nil
+}
+
+func removeNode(root *yaml.Node, pointer string) (*yaml.Node, error) {
+	parent, token, err := parentNode(root, pointer)
+	if err != nil {
+		return nil, err
+	}
+	switch parent.Kind {
+	case yaml.MappingNode:
+		removed := removeMappingKey(parent, token)
+		if removed == nil {
+			return nil, fmt.Errorf("key '%s' not found", token)
+		}
+		return removed, nil
+	case yaml.SequenceNode:
+		index, err := sequenceIndex(parent, token, false)
+		if err != nil {
+			return nil, err
+		}
+		removed := parent.Content[index]
+		parent.Content = append(parent.Content[:index], parent.Content[index+1:]...)
+		return removed, nil
+	}
+	return nil, fmt.Errorf("cannot remove '%s' from a scalar value", token)
+}
+
+func setMappingKey(node *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			node.Content[i+1] = value
+			return
+		}
+	}
+	node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, value)
+}
+
+func removeMappingKey(node *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			removed := node.Content[i+1]
+			node.Content = append(node.Content[:i], node.Content[i+2:]...)
+			return removed
+		}
+	}
+	return nil
+}
+
+// cloneNode returns a deep copy of the node, without comments, position information and JSON-style formatting.
+// Quotes are added back when encoding if they are needed to preserve the value type.
+func cloneNode(node *yaml.Node) *yaml.Node {
+	if node == nil {
+		return nil
+	}
+	clone := &yaml.Node{
+		Kind:  node.Kind,
+		Style: node.Style &^ (yaml.FlowStyle | yaml.DoubleQuotedStyle | yaml.SingleQuotedStyle),
+		Tag:   node.Tag,
+		Value: node.Value,
+	}
+	if node.Alias != nil {
+		return cloneNode(node.Alias)
+	}
+	for _, child := range node.Content {
+		clone.Content = append(clone.Content, cloneNode(child))
+	}
+	return clone
+}
+
+// nodesEqual returns true if the nodes have the same tags and values
+func nodesEqual(a *yaml.Node, b *yaml.Node) bool {
+	if a.Kind != b.Kind || a.ShortTag() != b.ShortTag() || a.Value != b.Value || len(a.Content) != len(b.Content) {
+		return false
+	}
+	for i := range a.Content {
+		if !nodesEqual(a.Content[i], b.Content[i]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,242 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT-0
+
+package template
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const patchTemplate = `AWSTemplateFormatVersion: "2010-09-09"
+Resources:
+  Network:
+    Type: AWS::CloudFormation::Stack
+    Properties:
+      TemplateURL: ./nested/network.yaml
+  TopicA:
+    Type: AWS::SNS::Topic
+    Properties:
+      TopicName: !Ref AWS::StackName
+      Tags:
+        - Key: team
+          Value: a
+  TopicB:
+    Type: AWS::SNS::Topic
+    Properties:
+      DisplayName: b
+  Queue:
+    Type: AWS::SQS::Queue
+    Properties:
+      DelaySeconds: 5
+`
+
+func loadPatchTemplate(t *testing.T) *Template {
+	dir := writeArtifact(t, map[string]string{
+		"template.yaml":       patchTemplate,
+		"nested/network.yaml": subnetsTemplate,
+	})
+	tmpl, err := Parse("template.yaml", []byte(patchTemplate))
+	require.NoError(t, err)
+	require.NoError(t, tmpl.LoadNested(dir, nil))
+	return tmpl
+}
+
+func TestTemplate_ApplyPatches(t *testing.T) {
+	tmpl := loadPatchTemplate(t)
+
+	patched, err := tmpl.ApplyPatches([]Patch{
+		{
+			// JSON6902 patch on every SNS topic
+			Type: "AWS::SNS::.*",
+			Patch: `
+- op: add
+  path: /Properties/KmsMasterKeyId
+  value: !Ref KmsKey
+`,
+		},
+		{
+			// Merge patch on a single resource
+			LogicalID: "TopicA",
+			Patch: `
+Properties:
+  TopicName: null
+  Tags:
+    - Key: team
+      Value: b
+`,
+		},
+		{
+			// Delete a resource
+			LogicalID: "Queue",
+			Patch:     `$patch: delete`,
+		},
+		{
+			// JSON6902 patch on the whole template
+			Patch: `[{"op": "add", "path": "/Parameters", "value": {"KmsKey": {"Type": "String"}}}]`,
+		},
+	})
+	require.NoError(t, err)
+
+	expected := `AWSTemplateFormatVersion: "2010-09-09"
+Resources:
+  Network:
+    Type: AWS::CloudFormation::Stack
+    Properties:
+      TemplateURL: ./nested/network.yaml
+  TopicA:
+    Type: AWS::SNS::Topic
+    Properties:
+      Tags:
+        - Key: team
+          Value: b
+      KmsMasterKeyId: !Ref KmsKey
+  TopicB:
+    Type: AWS::SNS::Topic
+    Properties:
+      DisplayName: b
+      KmsMasterKeyId: !Ref KmsKey
+Parameters:
+  KmsKey:
+    Type: String
+`
+	require.Equal(t, expected, patched.Body)
+	require.Len(t, patched.Nested, 1)
+	require.Same(t, tmpl.Nested[0].Template, patched.Nested[0].Template)
+	require.NotEqual(t, tmpl.Digest(), patched.Digest())
+
+	// The original template is unchanged
+	require.Equal(t, patchTemplate, tmpl.Body)
+
+	// Without patches, the template is returned as is
+	unpatched, err := tmpl.ApplyPatches(nil)
+	require.NoError(t, err)
+	require.Same(t, tmpl, unpatched)
+}
+
+func TestTemplate_ApplyPatchesJSONTemplate(t *testing.T) {
+	tmpl, err := Parse("template.json", []byte(`{
+  "Resources": {
+    "Topic": {
+      "Type": "AWS::SNS::Topic",
+      "Properties": {"DisplayName": "a", "FifoTopic": false}
+    },
+    "Queue": {
+      "Type": "AWS::SQS::Queue",
+      "Properties": {"DelaySeconds": 5}
+    }
+  }
+}`))
+	require.NoError(t, err)
+
+	patched, err := tmpl.ApplyPatches([]Patch{{
+		LogicalID: "Topic",
+		Patch: `
+Properties:
+  DisplayName: b
+  KmsMasterKeyId: !Ref KmsKey
+`,
+	}})
+	require.NoError(t, err)
+
+	// JSON templates are patched as JSON, with their keys in the same order
+	expected := `{
+  "Resources": {
+    "Topic": {
+      "Type": "AWS::SNS::Topic",
+      "Properties": {
+        "DisplayName": "b",
+        "FifoTopic": false,
+        "KmsMasterKeyId": {
+          "Ref": "KmsKey"
+        }
+      }
+    },
+    "Queue": {
+      "Type": "AWS::SQS::Queue",
+      "Properties": {
+        "DelaySeconds": 5
+      }
+    }
+  }
+}
+`
+	require.Equal(t, expected, patched.Body)
+}
+
+func TestTemplate_ApplyPatchesJSON6902Operations(t *testing.T) {
+	tmpl := loadPatchTemplate(t)
+
+	patched, err := tmpl.ApplyPatches([]Patch{{
+		LogicalID: "TopicA",
+		Patch: `
+- op: test
+  path: /Properties/TopicName
+  value: !Ref AWS::StackName
+- op: replace
+  path: /Properties/Tags/0/Value
+  value: c
+- op: copy
+  from: /Properties/Tags/0
+  path: /Properties/Tags/-
+- op: move
+  from: /Properties/TopicName
+  path: /Properties/DisplayName
+- op: remove
+  path: /Properties/Tags/1
+`,
+	}})
+	require.NoError(t, err)
+	require.Contains(t, patched.Body, `  TopicA:
+    Type: AWS::SNS::Topic
+    Properties:
+      Tags:
+        - Key: team
+          Value: c
+      DisplayName: !Ref AWS::StackName
+`)
+}
+
+func TestTemplate_ApplyPatchesFailures(t *testing.T) {
+	tmpl := loadPatchTemplate(t)
+
+	for name, tc := range map[string]struct {
+		patch   Patch
+		wantErr string
+	}{
+		"invalid patch": {
+			patch:   Patch{Patch: "just a string"},
+			wantErr: "apply patch 0 to template 'template.yaml': patch must be a list of JSON6902 operations or a mapping to merge",
+		},
+		"no matching resources": {
+			patch:   Patch{Type: "AWS::S3::Bucket", Patch: "Properties: {}"},
+			wantErr: "apply patch 0 to template 'template.yaml': no resources match the patch target",
+		},
+		"invalid target": {
+			patch:   Patch{LogicalID: "[", Patch: "Properties: {}"},
+			wantErr: "apply patch 0 to template 'template.yaml': invalid logical ID target '[': error parsing regexp: missing closing ]: `[)$`",
+		},
+		"failed test": {
+			patch:   Patch{LogicalID: "Queue", Patch: "[{op: test, path: /Properties/DelaySeconds, value: 10}]"},
+			wantErr: "apply patch 0 to template 'template.yaml': resource 'Queue': operation 0 (test /Properties/DelaySeconds): value at '/Properties/DelaySeconds' does not match",
+		},
+		"missing path": {
+			patch:   Patch{LogicalID: "Queue", Patch: "[{op: remove, path: /Properties/Missing}]"},
+			wantErr: "apply patch 0 to template 'template.yaml': resource 'Queue': operation 0 (remove /Properties/Missing): key 'Missing' not found",
+		},
+		"delete template": {
+			patch:   Patch{Patch: "$patch: delete"},
+			wantErr: "apply patch 0 to template 'template.yaml': patch cannot delete the whole template",
+		},
+		"unknown nested template": {
+			patch:   Patch{LogicalID: "Network", Patch: "Properties: {TemplateURL: ./other.yaml}"},
+			wantErr: "patched template 'template.yaml' references nested template 'other.yaml', which is not referenced by the original template",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := tmpl.ApplyPatches([]Patch{tc.patch})
+			require.EqualError(t, err, tc.wantErr)
+		})
+	}
+}
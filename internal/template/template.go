@@ -0,0 +1,256 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT-0
+
+// Package template parses CloudFormation templates and resolves the nested templates
+// that they reference by relative path from the same source artifact.
+package template
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	securejoin "github.com/cyphar/filepath-securejoin"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	nestedStackResourceType = "AWS::CloudFormation::Stack"
+	includeTransformName    = "AWS::Include"
+)
+
+// Template is a CloudFormation template loaded from a source artifact.
+type Template struct {
+	// Path of the template file, relative to the root of the source artifact.
+	Path string
+
+	// Body is the template file contents, as stored in the source artifact.
+	Body string
+
+	// Nested contains the references from this template to other template files
+	// in the source artifact, such as nested stack templates and AWS::Include snippets.
+	Nested []*Reference
+
+	// Parameters contains parameter values that were generated with the template,
+	// such as the parameters synthesized by the AWS CDK.
+	Parameters map[string]string
+
+	// Assets contains the files from the source artifact that the template expects
+	// at fixed keys in the template bucket, such as AWS CDK file assets.
+	Assets []*Asset
+
+	root *yaml.Node
+}
+
+// Asset is a file that must be uploaded to the template bucket before the template is deployed.
+type Asset struct {
+	// Keys contains the object keys that the template references the asset at.
+	Keys []string
+
+	// Body is the contents of the asset.
+	Body []byte
+}
+
+// Transform transforms the contents of a template file before it is parsed.
+type Transform func(templatePath string, body []byte) ([]byte, error)
+
+// Reference is a relative reference from a template to another template file in the same source artifact.
+type Reference struct {
+	// Location is the relative path to the referenced file, as written in the referencing template.
+	Location string
+
+	// Template is the referenced template.
+	Template *Template
+
+	// node is the scalar node in the referencing template that holds the location
+	node *yaml.Node
+}
+
+// Parse parses the given template body. It does not resolve nested template references.
+func Parse(templatePath string, body []byte) (*Template, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(body, &root); err != nil {
+		return nil, fmt.Errorf("parse template '%s': %w", templatePath, err)
+	}
+	return &Template{
+		Path: path.Clean(filepath.ToSlash(templatePath)),
+		Body: string(body),
+		root: &root,
+	}, nil
+}
+
+// LoadNested finds the nested stack templates and AWS::Include snippets that are referenced
+// by relative path from the template, and recursively loads them from the given artifact directory.
+// Relative paths are resolved from the directory of the referencing template.
+// If transform is not nil, it is applied to the contents of each nested template file before it is parsed.
+func (t *Template) LoadNested(artifactDir string, transform Transform) error {
+	return t.loadNested(artifactDir, transform, map[string]*Template{t.Path: t}, []string{t.Path})
+}
+
+func (t *Template) loadNested(artifactDir string, transform Transform, loaded map[string]*Template, ancestors []string) error {
+	for _, ref := range findReferences(t.root) {
+		nestedPath := path.Join(path.Dir(t.Path), filepath.ToSlash(ref.Location))
+		for _, ancestor := range ancestors {
+			if ancestor == nestedPath {
+				return fmt.Errorf("template '%s' references itself through '%s'", nestedPath, t.Path)
+			}
+		}
+
+		if nested, ok := loaded[nestedPath]; ok {
+			ref.Template = nested
+			t.Nested = append(t.Nested, ref)
+			continue
+		}
+
+		nestedFilePath, err := securejoin.SecureJoin(artifactDir, nestedPath)
+		if err != nil {
+			return fmt.Errorf("unable to join securely the artifact directory with nested template path '%s': %w", nestedPath, err)
+		}
+		body, err := os.ReadFile(filepath.Clean(nestedFilePath))
+		if err != nil {
+			return fmt.Errorf("unable to read nested template file '%s' referenced by template '%s': %w", nestedPath, t.Path, err)
+		}
+		if transform != nil {
+			if body, err = transform(nestedPath, body); err != nil {
+				return err
+			}
+		}
+		nested, err := Parse(nestedPath, body)
+		if err != nil {
+			return err
+		}
+		loaded[nestedPath] = nested
+		if err := nested.loadNested(artifactDir, transform, loaded, append(ancestors, nestedPath)); err != nil {
+			return err
+		}
+
+		ref.Template = nested
+		t.Nested = append(t.Nested, ref)
+	}
+	return nil
+}
+
+// Digest returns the SHA-256 digest of the template body.
+// The digest can be used as a stable, content-addressed identifier for the template.
+func (t *Template) Digest() string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(t.Body)))
+}
+
+// RewriteNested uploads all the templates referenced by this template, depth-first, using the
+// given upload function. The references in this template are then rewritten to the URLs returned
+// by the upload function, and the rewritten template is returned in the same format, JSON or YAML, as this template.
+// If the template does not reference any other template files, the template is returned unchanged.
+func (t *Template) RewriteNested(upload func(nested *Template) (string, error)) (*Template, error) {
+	if len(t.Nested) == 0 {
+		return t, nil
+	}
+
+	for _, ref := range t.Nested {
+		nested, err := ref.Template.RewriteNested(upload)
+		if err != nil {
+			return nil, err
+		}
+		url, err := upload(nested)
+		if err != nil {
+			return nil, fmt.Errorf("upload nested template '%s': %w", nested.Path, err)
+		}
+		ref.node.Value = url
+	}
+
+	body, err := t.encode(t.root)
+
+	// restore the relative locations, so that the template can be rewritten again
+	for _, ref := range t.Nested {
+		ref.node.Value = ref.Location
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &Template{
+		Path:       t.Path,
+		Body:       body,
+		Nested:     t.Nested,
+		Parameters: t.Parameters,
+		Assets:     t.Assets,
+		root:       t.root,
+	}, nil
+}
+
+// findReferences walks the template and returns the relative references to other template files
+func findReferences(root *yaml.Node) []*Reference {
+	var refs []*Reference
+
+	// Nested stacks: Resources.<logical ID>.Properties.TemplateURL
+	if resources := mappingValue(documentContent(root), "Resources"); resources != nil && resources.Kind == yaml.MappingNode {
+		for i := 1; i < len(resources.Content); i += 2 {
+			resource := resources.Content[i]
+			resourceType := mappingValue(resource, "Type")
+			if resourceType == nil || resourceType.Value != nestedStackResourceType {
+				continue
+			}
+			if ref := relativeReference(mappingValue(mappingValue(resource, "Properties"), "TemplateURL")); ref != nil {
+				refs = append(refs, ref)
+			}
+		}
+	}
+
+	// AWS::Include transforms, which can appear anywhere in the template:
+	// { "Name": "AWS::Include", "Parameters": { "Location": ... } }
+	walk(root, func(node *yaml.Node) {
+		name := mappingValue(node, "Name")
+		if name == nil || name.Value != includeTransformName {
+			return
+		}
+		if ref := relativeReference(mappingValue(mappingValue(node, "Parameters"), "Location")); ref != nil {
+			refs = append(refs, ref)
+		}
+	})
+
+	return refs
+}
+
+// relativeReference returns a reference if the given node is a string containing a relative file path
+func relativeReference(node *yaml.Node) *Reference {
+	if node == nil || node.Kind != yaml.ScalarNode || (node.Tag != "" && node.Tag != "!!str") {
+		return nil
+	}
+	location := strings.TrimSpace(node.Value)
+	if location == "" || strings.Contains(location, "://") || path.IsAbs(location) {
+		return nil
+	}
+	return &Reference{Location: node.Value, node: node}
+}
+
+func documentContent(node *yaml.Node) *yaml.Node {
+	if node != nil && node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		return node.Content[0]
+	}
+	return node
+}
+
+// mappingValue returns the value for the given key if the node is a mapping node
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func walk(node *yaml.Node, visit func(node *yaml.Node)) {
+	if node == nil {
+		return
+	}
+	visit(node)
+	for _, child := range node.Content {
+		walk(child, visit)
+	}
+}
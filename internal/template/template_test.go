@@ -0,0 +1,227 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT-0
+
+package template
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	rootTemplate = `AWSTemplateFormatVersion: "2010-09-09"
+Resources:
+  Network:
+    Type: AWS::CloudFormation::Stack
+    Properties:
+      TemplateURL: ./nested/network.yaml
+  Remote:
+    Type: AWS::CloudFormation::Stack
+    Properties:
+      TemplateURL: https://my-bucket.s3.amazonaws.com/remote.yaml
+  Topic:
+    Type: AWS::SNS::Topic
+    Properties:
+      TopicName: !Ref AWS::StackName
+  Fn::Transform:
+    Name: AWS::Include
+    Parameters:
+      Location: snippets/queue.yaml
+`
+
+	networkTemplate = `{
+  "Resources": {
+    "Subnets": {
+      "Type": "AWS::CloudFormation::Stack",
+      "Properties": {
+        "TemplateURL": "../subnets.yaml"
+      }
+    }
+  }
+}
+`
+
+	subnetsTemplate = `Resources:
+  Vpc:
+    Type: AWS::EC2::VPC
+`
+
+	queueSnippet = `Queue:
+  Type: AWS::SQS::Queue
+`
+)
+
+func writeArtifact(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, contents := range files {
+		filePath := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(filePath), 0o755))
+		require.NoError(t, os.WriteFile(filePath, []byte(contents), 0o600))
+	}
+	return dir
+}
+
+func TestTemplate_LoadNested(t *testing.T) {
+	dir := writeArtifact(t, map[string]string{
+		"template.yaml":          rootTemplate,
+		"nested/network.yaml":    networkTemplate,
+		"subnets.yaml":           subnetsTemplate,
+		"snippets/queue.yaml":    queueSnippet,
+		"unreferenced/file.yaml": subnetsTemplate,
+	})
+
+	tmpl, err := Parse("./template.yaml", []byte(rootTemplate))
+	require.NoError(t, err)
+	require.NoError(t, tmpl.LoadNested(dir, nil))
+
+	require.Equal(t, "template.yaml", tmpl.Path)
+	require.Len(t, tmpl.Nested, 2)
+	require.Equal(t, "./nested/network.yaml", tmpl.Nested[0].Location)
+	require.Equal(t, "nested/network.yaml", tmpl.Nested[0].Template.Path)
+	require.Equal(t, networkTemplate, tmpl.Nested[0].Template.Body)
+	require.Equal(t, "snippets/queue.yaml", tmpl.Nested[1].Location)
+	require.Equal(t, queueSnippet, tmpl.Nested[1].Template.Body)
+
+	network := tmpl.Nested[0].Template
+	require.Len(t, network.Nested, 1)
+	require.Equal(t, "subnets.yaml", network.Nested[0].Template.Path)
+	require.Equal(t, subnetsTemplate, network.Nested[0].Template.Body)
+}
+
+func TestTemplate_LoadNestedWithTransform(t *testing.T) {
+	dir := writeArtifact(t, map[string]string{
+		"template.yaml":       rootTemplate,
+		"nested/network.yaml": networkTemplate,
+		"subnets.yaml":        subnetsTemplate,
+		"snippets/queue.yaml": queueSnippet,
+	})
+
+	tmpl, err := Parse("template.yaml", []byte(rootTemplate))
+	require.NoError(t, err)
+
+	var transformed []string
+	err = tmpl.LoadNested(dir, func(templatePath string, body []byte) ([]byte, error) {
+		transformed = append(transformed, templatePath)
+		return append([]byte("# transformed\n"), body...), nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"nested/network.yaml", "subnets.yaml", "snippets/queue.yaml"}, transformed)
+	require.Equal(t, "# transformed\n"+queueSnippet, tmpl.Nested[1].Template.Body)
+
+	err = tmpl.LoadNested(dir, func(templatePath string, body []byte) ([]byte, error) {
+		return nil, errors.New("transform failed")
+	})
+	require.EqualError(t, err, "transform failed")
+}
+
+func TestTemplate_LoadNestedFailures(t *testing.T) {
+	testCases := map[string]struct {
+		files     map[string]string
+		wantedErr string
+	}{
+		"missing nested template": {
+			files: map[string]string{
+				"template.yaml": rootTemplate,
+			},
+			wantedErr: "unable to read nested template file 'nested/network.yaml' referenced by template 'template.yaml'",
+		},
+		"nested template outside of the artifact": {
+			files: map[string]string{
+				"template.yaml": "Resources:\n  A:\n    Type: AWS::CloudFormation::Stack\n    Properties:\n      TemplateURL: ../../../etc/passwd\n",
+			},
+			wantedErr: "unable to read nested template file '../../../etc/passwd' referenced by template 'template.yaml'",
+		},
+		"circular reference": {
+			files: map[string]string{
+				"template.yaml": "Resources:\n  A:\n    Type: AWS::CloudFormation::Stack\n    Properties:\n      TemplateURL: a.yaml\n",
+				"a.yaml":        "Resources:\n  B:\n    Type: AWS::CloudFormation::Stack\n    Properties:\n      TemplateURL: template.yaml\n",
+			},
+			wantedErr: "template 'template.yaml' references itself through 'a.yaml'",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			dir := writeArtifact(t, tc.files)
+			tmpl, err := Parse("template.yaml", []byte(tc.files["template.yaml"]))
+			require.NoError(t, err)
+			err = tmpl.LoadNested(dir, nil)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.wantedErr)
+		})
+	}
+}
+
+func TestTemplate_RewriteNested(t *testing.T) {
+	dir := writeArtifact(t, map[string]string{
+		"template.yaml":       rootTemplate,
+		"nested/network.yaml": networkTemplate,
+		"subnets.yaml":        subnetsTemplate,
+		"snippets/queue.yaml": queueSnippet,
+	})
+	tmpl, err := Parse("template.yaml", []byte(rootTemplate))
+	require.NoError(t, err)
+	require.NoError(t, tmpl.LoadNested(dir, nil))
+
+	var uploaded []string
+	upload := func(nested *Template) (string, error) {
+		uploaded = append(uploaded, nested.Path)
+		return "https://bucket.s3.amazonaws.com/" + nested.Digest(), nil
+	}
+
+	rewritten, err := tmpl.RewriteNested(upload)
+	require.NoError(t, err)
+	require.Equal(t, []string{"subnets.yaml", "nested/network.yaml", "snippets/queue.yaml"}, uploaded)
+
+	subnetsURL := "https://bucket.s3.amazonaws.com/" + tmpl.Nested[0].Template.Nested[0].Template.Digest()
+	queueURL := "https://bucket.s3.amazonaws.com/" + tmpl.Nested[1].Template.Digest()
+	require.Contains(t, rewritten.Body, "TemplateURL: https://bucket.s3.amazonaws.com/")
+	require.Contains(t, rewritten.Body, "TopicName: !Ref AWS::StackName")
+	require.Contains(t, rewritten.Body, "TemplateURL: https://my-bucket.s3.amazonaws.com/remote.yaml")
+	require.Contains(t, rewritten.Body, "Location: "+queueURL)
+	require.NotContains(t, rewritten.Body, "./nested/network.yaml")
+
+	// The nested template digest changes when its own nested templates change
+	network, err := tmpl.Nested[0].Template.RewriteNested(upload)
+	require.NoError(t, err)
+	require.Contains(t, network.Body, subnetsURL)
+	require.NotEqual(t, tmpl.Nested[0].Template.Digest(), network.Digest())
+
+	// JSON templates are rewritten as JSON, with their keys in the same order
+	require.Equal(t, strings.Replace(networkTemplate, "../subnets.yaml", subnetsURL, 1), network.Body)
+
+	// The original template is unchanged, and can be rewritten again
+	require.Equal(t, rootTemplate, tmpl.Body)
+	rewrittenAgain, err := tmpl.RewriteNested(upload)
+	require.NoError(t, err)
+	require.Equal(t, rewritten.Body, rewrittenAgain.Body)
+
+	// Upload failures are returned
+	_, err = tmpl.RewriteNested(func(nested *Template) (string, error) {
+		return "", errors.New("upload failed")
+	})
+	require.EqualError(t, err, "upload nested template 'subnets.yaml': upload failed")
+}
+
+func TestTemplate_RewriteNestedWithoutReferences(t *testing.T) {
+	tmpl, err := Parse("template.yaml", []byte(subnetsTemplate))
+	require.NoError(t, err)
+	require.NoError(t, tmpl.LoadNested(t.TempDir(), nil))
+
+	rewritten, err := tmpl.RewriteNested(func(nested *Template) (string, error) {
+		return "", errors.New("should not upload")
+	})
+	require.NoError(t, err)
+	require.Equal(t, subnetsTemplate, rewritten.Body)
+}
+
+func TestTemplate_ParseFailure(t *testing.T) {
+	_, err := Parse("template.yaml", []byte("Resources: [\n"))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "parse template 'template.yaml'")
+}
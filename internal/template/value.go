@@ -0,0 +1,97 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT-0
+
+package template
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Value returns the template as a generic JSON-compatible value, made of maps, slices, strings, numbers and booleans.
+// Intrinsic functions written in the YAML short form, like !Ref or !GetAtt, are converted to their JSON form,
+// like {"Ref": ...} or {"Fn::GetAtt": [...]}, so that the value has the same shape regardless of the template format.
+func (t *Template) Value() (interface{}, error) {
+	value, err := nodeValue(documentContent(t.root))
+	if err != nil {
+		return nil, fmt.Errorf("convert template '%s': %w", t.Path, err)
+	}
+	return value, nil
+}
+
+func nodeValue(node *yaml.Node) (interface{}, error) {
+	if node == nil {
+		return nil, nil
+	}
+
+	if intrinsic := intrinsicFunctionName(node.Tag); intrinsic != "" {
+		var arg interface{}
+		var err error
+		if node.Kind == yaml.ScalarNode {
+			arg = node.Value
+			// !GetAtt Resource.Attribute is the short form of {"Fn::GetAtt": ["Resource", "Attribute"]}
+			if intrinsic == "Fn::GetAtt" {
+				if resource, attribute, ok := strings.Cut(node.Value, "."); ok {
+					arg = []interface{}{resource, attribute}
+				}
+			}
+		} else {
+			untagged := *node
+			untagged.Tag = ""
+			if arg, err = nodeValue(&untagged); err != nil {
+				return nil, err
+			}
+		}
+		return map[string]interface{}{intrinsic: arg}, nil
+	}
+
+	switch node.Kind {
+	case yaml.DocumentNode:
+		return nodeValue(documentContent(node))
+	case yaml.AliasNode:
+		return nodeValue(node.Alias)
+	case yaml.MappingNode:
+		value := make(map[string]interface{}, len(node.Content)/2)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			item, err := nodeValue(node.Content[i+1])
+			if err != nil {
+				return nil, err
+			}
+			value[node.Content[i].Value] = item
+		}
+		return value, nil
+	case yaml.SequenceNode:
+		value := make([]interface{}, 0, len(node.Content))
+		for _, child := range node.Content {
+			item, err := nodeValue(child)
+			if err != nil {
+				return nil, err
+			}
+			value = append(value, item)
+		}
+		return value, nil
+	default:
+		var value interface{}
+		if err := node.Decode(&value); err != nil {
+			return nil, fmt.Errorf("line %d: %w", node.Line, err)
+		}
+		return value, nil
+	}
+}
+
+// intrinsicFunctionName returns the JSON name of the intrinsic function for a YAML short form tag like !Ref or !Sub,
+// or an empty string if the tag is not a short form tag.
+func intrinsicFunctionName(tag string) string {
+	if !strings.HasPrefix(tag, "!") || strings.HasPrefix(tag, "!!") {
+		return ""
+	}
+	name := strings.TrimPrefix(tag, "!")
+	switch name {
+	case "Ref", "Condition":
+		return name
+	default:
+		return "Fn::" + name
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT-0
+
+package template
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTemplate_Value(t *testing.T) {
+	testCases := map[string]struct {
+		body   string
+		wanted interface{}
+	}{
+		"YAML short form intrinsic functions": {
+			body: `Resources:
+  Bucket:
+    Type: AWS::S3::Bucket
+    Properties:
+      BucketName: !Sub "${AWS::StackName}-bucket"
+      Tags:
+        - Key: Topic
+          Value: !GetAtt Topic.TopicName
+        - Key: Owner
+          Value: !Ref Owner
+      VersioningConfiguration: !If [Versioned, {Status: Enabled}, !Ref AWS::NoValue]
+    DeletionPolicy: Retain
+  Topic:
+    Type: AWS::SNS::Topic
+    Properties:
+      FifoTopic: true
+      KmsMasterKeyId: !GetAtt [Key, Arn]
+      DisplayName: !Join
+        - "-"
+        - - topic
+          - !Ref AWS::Region
+`,
+			wanted: map[string]interface{}{
+				"Resources": map[string]interface{}{
+					"Bucket": map[string]interface{}{
+						"Type": "AWS::S3::Bucket",
+						"Properties": map[string]interface{}{
+							"BucketName": map[string]interface{}{"Fn::Sub": "${AWS::StackName}-bucket"},
+							"Tags": []interface{}{
+								map[string]interface{}{"Key": "Topic", "Value": map[string]interface{}{"Fn::GetAtt": []interface{}{"Topic", "TopicName"}}},
+								map[string]interface{}{"Key": "Owner", "Value": map[string]interface{}{"Ref": "Owner"}},
+							},
+							"VersioningConfiguration": map[string]interface{}{
+								"Fn::If": []interface{}{
+									"Versioned",
+									map[string]interface{}{"Status": "Enabled"},
+									map[string]interface{}{"Ref": "AWS::NoValue"},
+								},
+							},
+						},
+						"DeletionPolicy": "Retain",
+					},
+					"Topic": map[string]interface{}{
+						"Type": "AWS::SNS::Topic",
+						"Properties": map[string]interface{}{
+							"FifoTopic":      true,
+							"KmsMasterKeyId": map[string]interface{}{"Fn::GetAtt": []interface{}{"Key", "Arn"}},
+							"DisplayName": map[string]interface{}{
+								"Fn::Join": []interface{}{"-", []interface{}{"topic", map[string]interface{}{"Ref": "AWS::Region"}}},
+							},
+						},
+					},
+				},
+			},
+		},
+		"JSON template": {
+			body: `{"Resources": {"Volume": {"Type": "AWS::EC2::Volume", "Properties": {"Size": 100, "Encrypted": false}}}}`,
+			wanted: map[string]interface{}{
+				"Resources": map[string]interface{}{
+					"Volume": map[string]interface{}{
+						"Type":       "AWS::EC2::Volume",
+						"Properties": map[string]interface{}{"Size": 100, "Encrypted": false},
+					},
+				},
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			tmpl, err := Parse("template.yaml", []byte(tc.body))
+			require.NoError(t, err)
+
+			value, err := tmpl.Value()
+			require.NoError(t, err)
+			require.Equal(t, tc.wanted, value)
+		})
+	}
+}
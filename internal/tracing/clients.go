@@ -0,0 +1,95 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT-0
+
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/clients"
+	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/clients/types"
+)
+
+// CloudFormationClient returns a CloudFormation client that records a span for each client method call,
+// as a child of the span in the call's context.
+func CloudFormationClient(client clients.CloudFormationClient) clients.CloudFormationClient {
+	return &cloudFormationClient{client: client}
+}
+
+type cloudFormationClient struct {
+	client clients.CloudFormationClient
+}
+
+// start starts the span of a client method call
+func start(ctx context.Context, method string, stack *types.Stack) (context.Context, trace.Span) {
+	return Start(ctx, "CloudFormation."+method, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(
+		attribute.String("aws.cloudformation.stack_name", stack.Name),
+		attribute.String("aws.cloudformation.change_set_arn", stack.ChangeSetArn),
+	))
+}
+
+func (c *cloudFormationClient) CreateStack(ctx context.Context, stack *types.Stack) (string, error) {
+	ctx, span := start(ctx, "CreateStack", stack)
+	arn, err := c.client.CreateStack(ctx, stack)
+	End(span, err)
+	return arn, err
+}
+
+func (c *cloudFormationClient) UpdateStack(ctx context.Context, stack *types.Stack) (string, error) {
+	ctx, span := start(ctx, "UpdateStack", stack)
+	arn, err := c.client.UpdateStack(ctx, stack)
+	End(span, err)
+	return arn, err
+}
+
+func (c *cloudFormationClient) DescribeStack(ctx context.Context, stack *types.Stack) (*types.StackDescription, error) {
+	ctx, span := start(ctx, "DescribeStack", stack)
+	desc, err := c.client.DescribeStack(ctx, stack)
+	End(span, err)
+	return desc, err
+}
+
+func (c *cloudFormationClient) DescribeStackResources(ctx context.Context, stack *types.Stack) ([]types.StackResource, error) {
+	ctx, span := start(ctx, "DescribeStackResources", stack)
+	resources, err := c.client.DescribeStackResources(ctx, stack)
+	End(span, err)
+	return resources, err
+}
+
+func (c *cloudFormationClient) DeleteStack(ctx context.Context, stack *types.Stack) error {
+	ctx, span := start(ctx, "DeleteStack", stack)
+	err := c.client.DeleteStack(ctx, stack)
+	End(span, err)
+	return err
+}
+
+func (c *cloudFormationClient) ContinueStackRollback(ctx context.Context, stack *types.Stack) error {
+	ctx, span := start(ctx, "ContinueStackRollback", stack)
+	err := c.client.ContinueStackRollback(ctx, stack)
+	End(span, err)
+	return err
+}
+
+func (c *cloudFormationClient) ExecuteChangeSet(ctx context.Context, stack *types.Stack) error {
+	ctx, span := start(ctx, "ExecuteChangeSet", stack)
+	err := c.client.ExecuteChangeSet(ctx, stack)
+	End(span, err)
+	return err
+}
+
+func (c *cloudFormationClient) DeleteChangeSet(ctx context.Context, stack *types.Stack) error {
+	ctx, span := start(ctx, "DeleteChangeSet", stack)
+	err := c.client.DeleteChangeSet(ctx, stack)
+	End(span, err)
+	return err
+}
+
+func (c *cloudFormationClient) DescribeChangeSet(ctx context.Context, stack *types.Stack) (*types.ChangeSetDescription, error) {
+	ctx, span := start(ctx, "DescribeChangeSet", stack)
+	desc, err := c.client.DescribeChangeSet(ctx, stack)
+	End(span, err)
+	return desc, err
+}
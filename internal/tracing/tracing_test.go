@@ -0,0 +1,98 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT-0
+
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/clients/mocks"
+	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/clients/types"
+)
+
+func setupRecorder(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previous)
+	})
+	return recorder
+}
+
+func TestCloudFormationClient(t *testing.T) {
+	recorder := setupRecorder(t)
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	stack := &types.Stack{Name: "my-stack"}
+	mockClient := mocks.NewMockCloudFormationClient(mockCtrl)
+	var callSpan trace.SpanContext
+	mockClient.EXPECT().DescribeStack(gomock.Any(), stack).DoAndReturn(func(ctx context.Context, _ *types.Stack) (*types.StackDescription, error) {
+		callSpan = trace.SpanContextFromContext(ctx)
+		return &types.StackDescription{}, nil
+	})
+	mockClient.EXPECT().DeleteChangeSet(gomock.Any(), stack).Return(errors.New("some error"))
+
+	ctx, parent := Start(context.Background(), "parent")
+	client := CloudFormationClient(mockClient)
+
+	_, err := client.DescribeStack(ctx, stack)
+	require.NoError(t, err)
+	err = client.DeleteChangeSet(ctx, stack)
+	require.EqualError(t, err, "some error")
+	parent.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 3)
+
+	require.Equal(t, "CloudFormation.DescribeStack", spans[0].Name())
+	require.Equal(t, parent.SpanContext().SpanID(), spans[0].Parent().SpanID())
+	require.Equal(t, trace.SpanKindClient, spans[0].SpanKind())
+	require.Equal(t, codes.Unset, spans[0].Status().Code)
+	// The client is called with the context of the method call's span
+	require.Equal(t, spans[0].SpanContext().SpanID(), callSpan.SpanID())
+
+	require.Equal(t, "CloudFormation.DeleteChangeSet", spans[1].Name())
+	require.Equal(t, parent.SpanContext().SpanID(), spans[1].Parent().SpanID())
+	require.Equal(t, codes.Error, spans[1].Status().Code)
+	require.Equal(t, "some error", spans[1].Status().Description)
+}
+
+func TestTraceParent(t *testing.T) {
+	recorder := setupRecorder(t)
+
+	// No trace parent without a sampled span
+	require.Empty(t, TraceParent(context.Background()))
+
+	ctx, first := Start(context.Background(), "first")
+	traceParent := TraceParent(ctx)
+	first.End()
+	require.Equal(t, "00-"+first.SpanContext().TraceID().String()+"-"+first.SpanContext().SpanID().String()+"-01", traceParent)
+
+	_, second := Start(context.Background(), "second", LinkTo(traceParent))
+	second.End()
+	_, third := Start(context.Background(), "third", LinkTo("not-a-traceparent"))
+	third.End()
+	_, fourth := Start(context.Background(), "fourth", LinkTo(""))
+	fourth.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 4)
+	require.Len(t, spans[1].Links(), 1)
+	require.Equal(t, first.SpanContext().TraceID(), spans[1].Links()[0].SpanContext.TraceID())
+	require.Equal(t, first.SpanContext().SpanID(), spans[1].Links()[0].SpanContext.SpanID())
+	require.Empty(t, spans[2].Links())
+	require.Empty(t, spans[3].Links())
+}
@@ -4,11 +4,14 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	flag "github.com/spf13/pflag"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
@@ -33,9 +36,15 @@ import (
 
 	"github.com/awslabs/aws-cloudformation-controller-for-flux/api/v1alpha1"
 	cfnv1 "github.com/awslabs/aws-cloudformation-controller-for-flux/api/v1alpha1"
+	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/artifact"
+	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/clients"
 	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/clients/cloudformation"
+	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/clients/ratelimit"
 	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/clients/s3"
+	clienttypes "github.com/awslabs/aws-cloudformation-controller-for-flux/internal/clients/types"
 	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/controllers"
+	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/stackevents"
+	"github.com/awslabs/aws-cloudformation-controller-for-flux/internal/tracing"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -77,9 +86,31 @@ func main() {
 		leaderElectionOptions   leaderelection.Options
 		watchOptions            helper.WatchOptions
 		httpRetry               int
+		artifactCacheDir        string
+		artifactCacheMaxSize    int64
 		awsRegion               string
 		templateBucket          string
+		templateRetention       time.Duration
+		templateKeyPrefix       string
+		templateKMSKeyID        string
+		templateObjectTags      map[string]string
+		templateObjectACL       string
+		templateBucketOwner     string
 		stackTags               map[string]string
+		dryRun                  bool
+		enableTracing           bool
+		tracingSampleRatio      float64
+		awsRetryMode            string
+		awsRetryMaxAttempts     int
+		awsAPITimeout           time.Duration
+		awsOperationTimeouts    map[string]string
+		stackEventsQueueURL     string
+		stackEventsPollInterval time.Duration
+		stackCacheMaxAge        time.Duration
+		cfnRateLimit            float64
+		cfnRateBurst            int
+		s3RateLimit             float64
+		s3RateBurst             int
 	)
 
 	flag.StringVar(&metricsAddr, "metrics-addr", ":8080", "The address the metric endpoint binds to.")
@@ -90,10 +121,65 @@ func main() {
 	flag.DurationVar(&gracefulShutdownTimeout, "graceful-shutdown-timeout", 600*time.Second,
 		"The duration given to the reconciler to finish before forcibly stopping.")
 	flag.IntVar(&httpRetry, "http-retry", 9, "The maximum number of retries when failing to fetch artifacts over HTTP.")
+	flag.StringVar(&artifactCacheDir, "artifact-cache-dir", "",
+		"The directory in which the source artifacts are cached. Defaults to the directory for temporary files.")
+	flag.Int64Var(&artifactCacheMaxSize, "artifact-cache-max-size", 512<<20,
+		"The maximum size in bytes of the cached source artifacts and their extracted files, "+
+			"above which the least recently used artifacts are evicted.")
 	flag.StringVar(&awsRegion, "aws-region", "",
 		"The AWS region where CloudFormation stacks should be deployed. Will default to the AWS_REGION environment variable.")
+	flag.StringVar(&awsRetryMode, "aws-retry-mode", string(aws.RetryModeStandard),
+		"The AWS SDK retry mode, 'standard' or 'adaptive'. "+
+			"The adaptive retry mode slows down the controller's AWS API calls when they are throttled.")
+	flag.IntVar(&awsRetryMaxAttempts, "aws-retry-max-attempts", 0,
+		"The maximum number of attempts of an AWS API call, including the first call. Defaults to the AWS SDK's default (3).")
+	flag.DurationVar(&awsAPITimeout, "aws-api-timeout", 2*time.Minute,
+		"The timeout of each AWS API call, including its retries. Zero disables the timeout.")
+	flag.StringToStringVar(&awsOperationTimeouts, "aws-api-operation-timeouts", map[string]string{},
+		"AWS API operation name and timeout pairs that override the AWS API timeout for the given operations. "+
+			"Example: PutObject=10m,UploadPart=10m.")
+	flag.Float64Var(&cfnRateLimit, "cloudformation-rate-limit", 10,
+		"The maximum number of CloudFormation API calls per second, shared by all reconciliations, for each AWS account and region. Zero disables the rate limit.")
+	flag.IntVar(&cfnRateBurst, "cloudformation-rate-burst", 20,
+		"The maximum number of CloudFormation API calls that can be made at once above the rate limit.")
+	flag.Float64Var(&s3RateLimit, "s3-rate-limit", 50,
+		"The maximum number of S3 API calls per second, shared by all reconciliations, for each AWS account and region. Zero disables the rate limit.")
+	flag.IntVar(&s3RateBurst, "s3-rate-burst", 100,
+		"The maximum number of S3 API calls that can be made at once above the rate limit.")
 	flag.StringVar(&templateBucket, "template-bucket", "",
-		"The S3 bucket where the controller should upload CloudFormation templates for deployment. Will default to the TEMPLATE_BUCKET environment variable.")
+		"The S3 bucket where the controller should upload CloudFormation templates for deployment. Will default to the TEMPLATE_BUCKET environment variable. "+
+			"Templates smaller than 51,200 bytes without nested templates are passed to CloudFormation inline, and do not require a template bucket.")
+	flag.DurationVar(&templateRetention, "template-retention", 0,
+		"The minimum age of the templates uploaded to the template bucket before they are deleted, "+
+			"once they are no longer in use by the last applied change set of their stack. Zero disables the deletion of uploaded templates.")
+	flag.StringVar(&templateKeyPrefix, "template-key-prefix", "",
+		"The prefix for the object keys of templates uploaded to the template bucket. Example: my-cluster/.")
+	flag.StringVar(&templateKMSKeyID, "template-kms-key-id", "",
+		"The ID, ARN or alias of the AWS KMS key used to encrypt templates uploaded to the template bucket with SSE-KMS. "+
+			"Defaults to the default encryption of the template bucket.")
+	flag.StringToStringVar(&templateObjectTags, "template-object-tags", map[string]string{},
+		"Tag key and value pairs to apply to templates uploaded to the template bucket. Example: tag-name=tag-value,another-tag-name=another-tag-value.")
+	flag.StringVar(&templateObjectACL, "template-object-acl", "bucket-owner-full-control",
+		"The canned ACL to apply to templates uploaded to the template bucket, or 'none' to not apply an ACL "+
+			"for buckets that have S3 Object Ownership set to 'bucket owner enforced'.")
+	flag.StringVar(&templateBucketOwner, "template-bucket-owner", "",
+		"The ID of the AWS account that is expected to own the template bucket. Defaults to the account of the controller's AWS credentials.")
+	flag.BoolVar(&dryRun, "dry-run", false,
+		"Plan the changes of all CloudFormationStacks as if they were in Plan mode, without deploying any stack changes.")
+	flag.BoolVar(&enableTracing, "enable-tracing", false,
+		"Export OpenTelemetry traces of reconciliations and AWS API calls over OTLP gRPC. "+
+			"The exporter is configured with the standard OTEL_EXPORTER_OTLP_* environment variables.")
+	flag.Float64Var(&tracingSampleRatio, "tracing-sample-ratio", 1,
+		"The ratio of reconciliations to trace, between 0 and 1, when tracing is enabled.")
+	flag.StringVar(&stackEventsQueueURL, "stack-events-queue-url", "",
+		"The URL of an SQS queue that receives the CloudFormation stack status change events from EventBridge. "+
+			"When set, stacks are reconciled as soon as their status changes, and stack actions in progress are polled at the stack events poll interval.")
+	flag.DurationVar(&stackEventsPollInterval, "stack-events-poll-interval", 5*time.Minute,
+		"The minimum interval at which stack actions in progress are polled when stack status change events are received, in case an event is lost.")
+	flag.DurationVar(&stackCacheMaxAge, "stack-status-cache-max-age", 0,
+		"The maximum age of the shared cache of stack statuses, refreshed by describing all stacks in the region with paginated API calls. "+
+			"When set, the stacks of ready CloudFormationStacks are checked against the cache, and only stacks in progress are described directly. "+
+			"Zero disables the cache.")
 	flag.StringToStringVar(&stackTags, "stack-tags", map[string]string{},
 		"Tag key and value pairs to apply to all CloudFormation stacks, in addition to the default tags added by the controller "+
 			"(cfn-flux-controller/version, cfn-flux-controller/name, cfn-flux-controller/namespace). "+
@@ -107,6 +193,36 @@ func main() {
 	flag.Parse()
 
 	ctrl.SetLogger(logger.NewLogger(logOptions))
+
+	retryMode, err := aws.ParseRetryMode(awsRetryMode)
+	if err != nil {
+		setupLog.Error(err, "unable to configure AWS clients")
+		os.Exit(1)
+	}
+	operationTimeouts := map[string]time.Duration{}
+	for operation, value := range awsOperationTimeouts {
+		timeout, err := time.ParseDuration(value)
+		if err != nil {
+			setupLog.Error(fmt.Errorf("invalid timeout '%s' for AWS API operation %s: %w", value, operation, err), "unable to configure AWS clients")
+			os.Exit(1)
+		}
+		operationTimeouts[operation] = timeout
+	}
+	awsClientOptions := clienttypes.ClientOptions{
+		RetryMode:         retryMode,
+		RetryMaxAttempts:  awsRetryMaxAttempts,
+		Timeout:           awsAPITimeout,
+		OperationTimeouts: operationTimeouts,
+		RateLimiter: ratelimit.New(map[string]ratelimit.Limit{
+			ratelimit.ServiceCloudFormation: {Rate: cfnRateLimit, Burst: cfnRateBurst},
+			ratelimit.ServiceS3:             {Rate: s3RateLimit, Burst: s3RateBurst},
+		}),
+	}
+
+	if !isValidTemplateObjectACL(templateObjectACL) {
+		setupLog.Error(fmt.Errorf("invalid template object ACL '%s'", templateObjectACL), "unable to configure template uploads")
+		os.Exit(1)
+	}
 	setupLog.Info("Configuring manager", "version", BuildVersion, "sha", BuildSHA)
 
 	watchNamespace := ""
@@ -138,7 +254,11 @@ func main() {
 		LeaderElectionID:              leaderElectionId,
 		Logger:                        ctrl.Log,
 		Client: ctrlclient.Options{
-			Cache: &ctrlclient.CacheOptions{},
+			Cache: &ctrlclient.CacheOptions{
+				// Read the ConfigMaps and Secrets referenced for post-build substitutions directly
+				// from the API server, instead of caching all ConfigMaps and Secrets in the cluster
+				DisableFor: []ctrlclient.Object{&corev1.ConfigMap{}, &corev1.Secret{}},
+			},
 		},
 		Cache: ctrlcache.Options{
 			ByObject: map[ctrlclient.Object]ctrlcache.ByObject{
@@ -178,18 +298,39 @@ func main() {
 
 	signalHandlerContext := ctrl.SetupSignalHandler()
 
-	cfnClient, err := cloudformation.New(signalHandlerContext, awsRegion)
+	cfnClient, err := cloudformation.New(signalHandlerContext, awsRegion, awsClientOptions)
 	if err != nil {
 		setupLog.Error(err, "unable to create CloudFormation client")
 		os.Exit(1)
 	}
 
-	s3Client, err := s3.New(signalHandlerContext, awsRegion)
+	cfnStackSetClient, err := cloudformation.NewStackSetClient(signalHandlerContext, awsRegion, awsClientOptions)
+	if err != nil {
+		setupLog.Error(err, "unable to create CloudFormation stack set client")
+		os.Exit(1)
+	}
+
+	s3Client, err := s3.New(signalHandlerContext, awsRegion, awsClientOptions)
 	if err != nil {
 		setupLog.Error(err, "unable to create S3 client")
 		os.Exit(1)
 	}
 
+	var stackCache clients.CloudFormationStackCache
+	if stackCacheMaxAge > 0 {
+		stackCache, err = cloudformation.NewStackCache(signalHandlerContext, awsRegion, awsClientOptions, stackCacheMaxAge)
+		if err != nil {
+			setupLog.Error(err, "unable to create CloudFormation stack cache")
+			os.Exit(1)
+		}
+	}
+
+	artifactCache, err := artifact.NewCache(artifactCacheDir, artifactCacheMaxSize)
+	if err != nil {
+		setupLog.Error(err, "unable to create artifact cache")
+		os.Exit(1)
+	}
+
 	if templateBucket == "" {
 		templateBucket = os.Getenv("TEMPLATE_BUCKET")
 	}
@@ -199,29 +340,131 @@ func main() {
 		controllerVersion = "unknown-version"
 	}
 
+	if enableTracing {
+		shutdownTracing, err := tracing.Setup(signalHandlerContext, controllerName, controllerVersion, tracingSampleRatio)
+		if err != nil {
+			setupLog.Error(err, "unable to set up tracing")
+			os.Exit(1)
+		}
+		defer func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			if err := shutdownTracing(ctx); err != nil {
+				setupLog.Error(err, "unable to flush traces")
+			}
+		}()
+	}
+
+	// The region that the stacks are deployed to, for labelling the stack metrics
+	metricsRegion := awsRegion
+	if metricsRegion == "" {
+		metricsRegion = os.Getenv("AWS_REGION")
+	}
+
 	reconciler := &controllers.CloudFormationStackReconciler{
 		Client:              mgr.GetClient(),
 		Scheme:              mgr.GetScheme(),
 		EventRecorder:       eventRecorder,
 		Metrics:             metricsH,
 		NoCrossNamespaceRef: aclOptions.NoCrossNamespaceRefs,
-		CfnClient:           cfnClient,
+		CfnClient:           tracing.CloudFormationClient(cfnClient),
+		StackCache:          stackCache,
 		S3Client:            s3Client,
 		TemplateBucket:      templateBucket,
-		StackTags:           stackTags,
-		ControllerName:      controllerName,
-		ControllerVersion:   controllerVersion,
+		TemplateRetention:   templateRetention,
+		TemplateBucketOptions: clienttypes.TemplateBucketOptions{
+			KeyPrefix:           templateKeyPrefix,
+			KMSKeyID:            templateKMSKeyID,
+			ObjectTags:          templateObjectTags,
+			ACL:                 templateObjectACL,
+			ExpectedBucketOwner: templateBucketOwner,
+		},
+		StackTags:         stackTags,
+		ControllerName:    controllerName,
+		ControllerVersion: controllerVersion,
+		DryRun:            dryRun,
+		Region:            metricsRegion,
+		RateLimiter:       awsClientOptions.RateLimiter,
+		ArtifactCache:     artifactCache,
 	}
 
 	reconcilerOpts := controllers.CloudFormationStackReconcilerOptions{
 		HTTPRetry:                 httpRetry,
 		DependencyRequeueInterval: requeueDependency,
+		StackEvents:               stackEventsQueueURL != "",
+		StackEventsPollInterval:   stackEventsPollInterval,
 	}
 
 	if err = reconciler.SetupWithManager(signalHandlerContext, mgr, reconcilerOpts); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", cfnv1.CloudFormationStackKind)
 		os.Exit(1)
 	}
+
+	if stackEventsQueueURL != "" {
+		account, err := clients.CallerAccount(signalHandlerContext, awsRegion, awsClientOptions)
+		if err != nil {
+			setupLog.Error(err, "unable to get the AWS account of the controller")
+			os.Exit(1)
+		}
+		reconciler.Account = account
+
+		stackEventsConsumer, err := stackevents.New(signalHandlerContext, awsRegion, stackEventsQueueURL, awsClientOptions, reconciler.EnqueueStackStatusChange)
+		if err != nil {
+			setupLog.Error(err, "unable to create SQS client")
+			os.Exit(1)
+		}
+		if err := mgr.Add(stackEventsConsumer); err != nil {
+			setupLog.Error(err, "unable to receive stack status change events")
+			os.Exit(1)
+		}
+	}
+
+	stackSetReconciler := &controllers.CloudFormationStackSetReconciler{
+		Client:                mgr.GetClient(),
+		Scheme:                mgr.GetScheme(),
+		EventRecorder:         eventRecorder,
+		Metrics:               metricsH,
+		NoCrossNamespaceRef:   aclOptions.NoCrossNamespaceRefs,
+		CfnClient:             cfnStackSetClient,
+		S3Client:              s3Client,
+		TemplateBucket:        templateBucket,
+		TemplateBucketOptions: reconciler.TemplateBucketOptions,
+		StackTags:             stackTags,
+		ControllerName:        controllerName,
+		ControllerVersion:     controllerVersion,
+		Region:                metricsRegion,
+		Account:               reconciler.Account,
+		RateLimiter:           awsClientOptions.RateLimiter,
+		ArtifactCache:         artifactCache,
+	}
+
+	stackSetReconcilerOpts := controllers.CloudFormationStackSetReconcilerOptions{
+		HTTPRetry: httpRetry,
+	}
+
+	if err = stackSetReconciler.SetupWithManager(signalHandlerContext, mgr, stackSetReconcilerOpts); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", cfnv1.CloudFormationStackSetKind)
+		os.Exit(1)
+	}
+
+	generatorReconciler := &controllers.CloudFormationStackGeneratorReconciler{
+		Client:              mgr.GetClient(),
+		Scheme:              mgr.GetScheme(),
+		EventRecorder:       eventRecorder,
+		Metrics:             metricsH,
+		NoCrossNamespaceRef: aclOptions.NoCrossNamespaceRefs,
+		ControllerName:      controllerName,
+		ArtifactCache:       artifactCache,
+	}
+
+	generatorReconcilerOpts := controllers.CloudFormationStackGeneratorReconcilerOptions{
+		HTTPRetry: httpRetry,
+	}
+
+	if err = generatorReconciler.SetupWithManager(signalHandlerContext, mgr, generatorReconcilerOpts); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", cfnv1.CloudFormationStackGeneratorKind)
+		os.Exit(1)
+	}
 	//+kubebuilder:scaffold:builder
 
 	setupLog.Info("Starting manager", "version", BuildVersion, "sha", BuildSHA)
@@ -231,3 +474,12 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// isValidTemplateObjectACL returns true if the ACL is 'none' or a canned ACL that can be applied to uploaded templates
+func isValidTemplateObjectACL(templateACL string) bool {
+	switch templateACL {
+	case clienttypes.TemplateObjectACLNone, "private", "bucket-owner-full-control", "bucket-owner-read", "authenticated-read", "aws-exec-read":
+		return true
+	}
+	return false
+}